@@ -0,0 +1,277 @@
+// Package adpod chooses the combination of bids filling an ad pod.
+package adpod
+
+import (
+	"math"
+	"sort"
+
+	"github.com/prebid/openrtb/v17/adcom1"
+)
+
+// maxSearchNodes bounds the search for the best combination of bids in a pod. The first
+// combination explored is the greedy one, so the result is never worse than greedy filling.
+const maxSearchNodes = 100000
+
+// RejectionReason explains why a candidate was left out of a pod
+type RejectionReason string
+
+const (
+	RejectDurationExceedsPod  RejectionReason = "duration_exceeds_pod"
+	RejectPodDuration         RejectionReason = "pod_duration"
+	RejectMaxAds              RejectionReason = "max_ads"
+	RejectCategoryExclusion   RejectionReason = "category_exclusion"
+	RejectAdvertiserExclusion RejectionReason = "advertiser_exclusion"
+	RejectSlotTaken           RejectionReason = "slot_taken"
+	RejectNotSelected         RejectionReason = "not_selected"
+)
+
+// Candidate is a bid competing for a place in a pod
+type Candidate struct {
+	Price      float64
+	Duration   int
+	Category   string
+	Advertiser string
+	// Slot is the position the creative must play at, for bids which can't play anywhere in the pod
+	Slot adcom1.SlotPositionInPod
+	// TieBreaker orders candidates with the same price and duration, usually the seat and bid id
+	TieBreaker string
+}
+
+// Constraints limits the combination of candidates filling a pod
+type Constraints struct {
+	MaxDuration         int
+	MaxAds              int // zero means the number of ads is only limited by the duration
+	CategoryExclusion   bool
+	AdvertiserExclusion bool
+}
+
+// Result holds the indexes of the candidates filling the pod, in the order they play,
+// and the reason each of the other candidates was left out
+type Result struct {
+	Selected []int
+	Rejected map[int]RejectionReason
+}
+
+// Pack chooses the combination of candidates with the highest revenue respecting the constraints.
+// Combinations with the same revenue are ranked by number of ads, then ties are broken in favor of
+// higher prices, shorter durations and the tie breaker, so the result is deterministic.
+func Pack(candidates []Candidate, constraints Constraints) Result {
+	result := Result{Rejected: make(map[int]RejectionReason)}
+
+	order := make([]int, 0, len(candidates))
+	for i, candidate := range candidates {
+		if candidate.Duration > constraints.MaxDuration {
+			result.Rejected[i] = RejectDurationExceedsPod
+		} else {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := candidates[order[i]], candidates[order[j]]
+		if a.Price != b.Price {
+			return a.Price > b.Price
+		}
+		if a.Duration != b.Duration {
+			return a.Duration < b.Duration
+		}
+		return a.TieBreaker < b.TieBreaker
+	})
+
+	maxAds := constraints.MaxAds
+	if maxAds == 0 || maxAds > len(order) {
+		maxAds = len(order)
+	}
+	p := &packer{
+		candidates:  make([]Candidate, len(order)),
+		prices:      make([]int64, len(order)),
+		constraints: constraints,
+		maxAds:      maxAds,
+		categories:  make(map[string]int),
+		advertisers: make(map[string]int),
+	}
+	for i, index := range order {
+		p.candidates[i] = candidates[index]
+		p.prices[i] = int64(math.Round(candidates[index].Price * 1e6))
+	}
+	p.search(0, 0)
+
+	// the search backtracks to an empty combination, replay the best one to explain the rejections
+	selected := make(map[int]bool, len(p.best))
+	for _, i := range p.best {
+		selected[i] = true
+		p.add(i)
+	}
+	for i, index := range order {
+		if !selected[i] {
+			result.Rejected[index] = p.rejectionReason(p.candidates[i])
+		}
+	}
+
+	result.Selected = p.playOrder(order)
+	return result
+}
+
+// packer runs a depth-first branch and bound search over the candidates, which are sorted by price
+// in descending order, keeping the first best combination found.
+type packer struct {
+	candidates  []Candidate
+	prices      []int64 // micros, so revenue comparisons are exact
+	constraints Constraints
+	maxAds      int
+
+	current     []int
+	duration    int
+	categories  map[string]int
+	advertisers map[string]int
+	slots       [3]int // first, last, first or last
+	nodes       int
+
+	best        []int
+	bestRevenue int64
+}
+
+func (p *packer) search(start int, revenue int64) {
+	p.nodes++
+	if revenue > p.bestRevenue || (revenue == p.bestRevenue && len(p.current) > len(p.best)) {
+		p.best = append(p.best[:0], p.current...)
+		p.bestRevenue = revenue
+	}
+	if p.nodes > maxSearchNodes || len(p.current) == p.maxAds {
+		return
+	}
+
+	revenueBound, countBound := p.bound(start, revenue)
+	if revenueBound < p.bestRevenue || (revenueBound == p.bestRevenue && countBound <= len(p.best)) {
+		return
+	}
+
+	for i := start; i < len(p.candidates); i++ {
+		candidate := p.candidates[i]
+		if p.duration+candidate.Duration > p.constraints.MaxDuration || p.excluded(candidate) || !p.slotAvailable(candidate.Slot) {
+			continue
+		}
+		p.add(i)
+		p.search(i+1, revenue+p.prices[i])
+		p.remove(i)
+	}
+}
+
+// bound returns the revenue and number of ads no combination extending the current one can exceed:
+// the most expensive remaining candidates that fit on their own, up to the remaining number of ads.
+func (p *packer) bound(start int, revenue int64) (int64, int) {
+	slots := p.maxAds - len(p.current)
+	count := len(p.current)
+	for i := start; i < len(p.candidates) && slots > 0; i++ {
+		if p.duration+p.candidates[i].Duration <= p.constraints.MaxDuration {
+			revenue += p.prices[i]
+			count++
+			slots--
+		}
+	}
+	return revenue, count
+}
+
+func (p *packer) excluded(candidate Candidate) bool {
+	if p.constraints.CategoryExclusion && candidate.Category != "" && p.categories[candidate.Category] > 0 {
+		return true
+	}
+	return p.constraints.AdvertiserExclusion && candidate.Advertiser != "" && p.advertisers[candidate.Advertiser] > 0
+}
+
+// slotAvailable checks a pod has a single first and a single last position
+func (p *packer) slotAvailable(slot adcom1.SlotPositionInPod) bool {
+	first, last, firstOrLast := p.slots[0], p.slots[1], p.slots[2]
+	switch slot {
+	case adcom1.SlotPosFirst:
+		first++
+	case adcom1.SlotPosLast:
+		last++
+	case adcom1.SlotPosFirstOrLast:
+		firstOrLast++
+	default:
+		return true
+	}
+	return first <= 1 && last <= 1 && first+last+firstOrLast <= 2
+}
+
+func (p *packer) add(i int) {
+	p.current = append(p.current, i)
+	p.duration += p.candidates[i].Duration
+	p.categories[p.candidates[i].Category]++
+	p.advertisers[p.candidates[i].Advertiser]++
+	p.updateSlots(p.candidates[i].Slot, 1)
+}
+
+func (p *packer) remove(i int) {
+	p.current = p.current[:len(p.current)-1]
+	p.duration -= p.candidates[i].Duration
+	p.categories[p.candidates[i].Category]--
+	p.advertisers[p.candidates[i].Advertiser]--
+	p.updateSlots(p.candidates[i].Slot, -1)
+}
+
+func (p *packer) updateSlots(slot adcom1.SlotPositionInPod, delta int) {
+	switch slot {
+	case adcom1.SlotPosFirst:
+		p.slots[0] += delta
+	case adcom1.SlotPosLast:
+		p.slots[1] += delta
+	case adcom1.SlotPosFirstOrLast:
+		p.slots[2] += delta
+	}
+}
+
+// rejectionReason explains why a candidate isn't part of the best combination, which must be the
+// current state of the packer.
+func (p *packer) rejectionReason(candidate Candidate) RejectionReason {
+	switch {
+	case p.constraints.CategoryExclusion && candidate.Category != "" && p.categories[candidate.Category] > 0:
+		return RejectCategoryExclusion
+	case p.constraints.AdvertiserExclusion && candidate.Advertiser != "" && p.advertisers[candidate.Advertiser] > 0:
+		return RejectAdvertiserExclusion
+	case !p.slotAvailable(candidate.Slot):
+		return RejectSlotTaken
+	case len(p.best) >= p.maxAds:
+		return RejectMaxAds
+	case p.duration+candidate.Duration > p.constraints.MaxDuration:
+		return RejectPodDuration
+	}
+	return RejectNotSelected
+}
+
+// playOrder returns the original indexes of the selected candidates in the order they play: the
+// first slot, the candidates playing anywhere by decreasing price, then the last slot.
+func (p *packer) playOrder(order []int) []int {
+	var first, last []int
+	middle := make([]int, 0, len(p.best))
+	for _, i := range p.best {
+		switch p.candidates[i].Slot {
+		case adcom1.SlotPosFirst:
+			first = append(first, order[i])
+		case adcom1.SlotPosLast:
+			last = append(last, order[i])
+		case adcom1.SlotPosFirstOrLast:
+			if len(first) == 0 && !p.hasSlot(adcom1.SlotPosFirst) {
+				first = append(first, order[i])
+			} else {
+				last = append(last, order[i])
+			}
+		default:
+			middle = append(middle, order[i])
+		}
+	}
+
+	selected := make([]int, 0, len(p.best))
+	selected = append(selected, first...)
+	selected = append(selected, middle...)
+	return append(selected, last...)
+}
+
+func (p *packer) hasSlot(slot adcom1.SlotPositionInPod) bool {
+	for _, i := range p.best {
+		if p.candidates[i].Slot == slot {
+			return true
+		}
+	}
+	return false
+}
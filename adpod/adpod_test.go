@@ -0,0 +1,135 @@
+package adpod
+
+import (
+	"testing"
+
+	"github.com/prebid/openrtb/v17/adcom1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPack(t *testing.T) {
+	testCases := []struct {
+		description      string
+		candidates       []Candidate
+		constraints      Constraints
+		expectedSelected []int
+		expectedRejected map[int]RejectionReason
+	}{
+		{
+			description:      "No candidates",
+			constraints:      Constraints{MaxDuration: 30},
+			expectedSelected: []int{},
+			expectedRejected: map[int]RejectionReason{},
+		},
+		{
+			description: "Highest revenue combination wins over the most expensive bid",
+			candidates: []Candidate{
+				{Price: 10, Duration: 60},
+				{Price: 6, Duration: 30},
+				{Price: 6, Duration: 30},
+			},
+			constraints:      Constraints{MaxDuration: 60},
+			expectedSelected: []int{1, 2},
+			expectedRejected: map[int]RejectionReason{0: RejectPodDuration},
+		},
+		{
+			description: "Candidates longer than the pod",
+			candidates: []Candidate{
+				{Price: 10, Duration: 45},
+				{Price: 1, Duration: 15},
+			},
+			constraints:      Constraints{MaxDuration: 30},
+			expectedSelected: []int{1},
+			expectedRejected: map[int]RejectionReason{0: RejectDurationExceedsPod},
+		},
+		{
+			description: "Max ads",
+			candidates: []Candidate{
+				{Price: 1, Duration: 15},
+				{Price: 3, Duration: 15},
+				{Price: 2, Duration: 15},
+			},
+			constraints:      Constraints{MaxDuration: 60, MaxAds: 2},
+			expectedSelected: []int{1, 2},
+			expectedRejected: map[int]RejectionReason{0: RejectMaxAds},
+		},
+		{
+			description: "Category and advertiser exclusions",
+			candidates: []Candidate{
+				{Price: 5, Duration: 15, Category: "IAB1"},
+				{Price: 4, Duration: 15, Category: "IAB1"},
+				{Price: 3, Duration: 15, Advertiser: "brand.com"},
+				{Price: 2, Duration: 15, Advertiser: "brand.com"},
+			},
+			constraints:      Constraints{MaxDuration: 60, CategoryExclusion: true, AdvertiserExclusion: true},
+			expectedSelected: []int{0, 2},
+			expectedRejected: map[int]RejectionReason{1: RejectCategoryExclusion, 3: RejectAdvertiserExclusion},
+		},
+		{
+			description: "Slot positions play first and last",
+			candidates: []Candidate{
+				{Price: 5, Duration: 15, Slot: adcom1.SlotPosLast},
+				{Price: 4, Duration: 15},
+				{Price: 3, Duration: 15, Slot: adcom1.SlotPosFirst},
+				{Price: 2, Duration: 15},
+			},
+			constraints:      Constraints{MaxDuration: 60},
+			expectedSelected: []int{2, 1, 3, 0},
+			expectedRejected: map[int]RejectionReason{},
+		},
+		{
+			description: "A pod has a single first slot",
+			candidates: []Candidate{
+				{Price: 5, Duration: 15, Slot: adcom1.SlotPosFirst},
+				{Price: 4, Duration: 15, Slot: adcom1.SlotPosFirst},
+				{Price: 1, Duration: 15},
+			},
+			constraints:      Constraints{MaxDuration: 60},
+			expectedSelected: []int{0, 2},
+			expectedRejected: map[int]RejectionReason{1: RejectSlotTaken},
+		},
+		{
+			description: "First or last slots take the free end of the pod",
+			candidates: []Candidate{
+				{Price: 5, Duration: 15, Slot: adcom1.SlotPosFirstOrLast},
+				{Price: 4, Duration: 15, Slot: adcom1.SlotPosFirst},
+				{Price: 3, Duration: 15, Slot: adcom1.SlotPosFirstOrLast},
+				{Price: 2, Duration: 15},
+			},
+			constraints:      Constraints{MaxDuration: 60},
+			expectedSelected: []int{1, 3, 0},
+			expectedRejected: map[int]RejectionReason{2: RejectSlotTaken},
+		},
+		{
+			description: "Ties are broken by duration then tie breaker",
+			candidates: []Candidate{
+				{Price: 5, Duration: 30, TieBreaker: "a"},
+				{Price: 5, Duration: 20, TieBreaker: "c"},
+				{Price: 5, Duration: 20, TieBreaker: "b"},
+			},
+			constraints:      Constraints{MaxDuration: 30},
+			expectedSelected: []int{2},
+			expectedRejected: map[int]RejectionReason{0: RejectPodDuration, 1: RejectPodDuration},
+		},
+		{
+			description: "Equal revenue prefers more ads",
+			candidates: []Candidate{
+				{Price: 4, Duration: 30},
+				{Price: 2, Duration: 15},
+				{Price: 2, Duration: 15},
+			},
+			constraints:      Constraints{MaxDuration: 30},
+			expectedSelected: []int{1, 2},
+			expectedRejected: map[int]RejectionReason{0: RejectPodDuration},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			result := Pack(test.candidates, test.constraints)
+
+			assert.Equal(t, test.expectedSelected, result.Selected)
+			assert.Equal(t, test.expectedRejected, result.Rejected)
+		})
+	}
+}
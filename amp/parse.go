@@ -105,19 +105,19 @@ func ReadPolicy(ampParams Params, pbsConfigGDPREnabled bool) (privacy.PolicyWrit
 	return rv, warning
 }
 
-// buildGdprTCF2ConsentWriter returns a gdpr.ConsentWriter that will set regs.ext.gdpr to the value
+// buildGdprTCF2ConsentWriter returns a gdpr.ConsentWriter that will set regs.gdpr to the value
 // of 1 if gdpr_applies wasn't defined. The reason for this is that this function gets called when
 // GDPR applies, even if field gdpr_applies wasn't set in the AMP endpoint query.
 func buildGdprTCF2ConsentWriter(ampParams Params) gdpr.ConsentWriter {
 	writer := gdpr.ConsentWriter{Consent: ampParams.Consent}
 
-	// If gdpr_applies was not set, regs.ext.gdpr must equal 1
+	// If gdpr_applies was not set, regs.gdpr must equal 1
 	var gdprValue int8 = 1
 	if ampParams.GdprApplies != nil {
-		// set regs.ext.gdpr if non-nil gdpr_applies was set to true
+		// set regs.gdpr if non-nil gdpr_applies was set to true
 		gdprValue = parseGdprApplies(ampParams.GdprApplies)
 	}
-	writer.RegExtGDPR = &gdprValue
+	writer.GDPR = &gdprValue
 
 	return writer
 }
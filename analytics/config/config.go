@@ -7,15 +7,18 @@ import (
 	"github.com/prebid/prebid-server/analytics/clients"
 	"github.com/prebid/prebid-server/analytics/filesystem"
 	"github.com/prebid/prebid-server/analytics/pubstack"
+	"github.com/prebid/prebid-server/analytics/stream"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
 )
 
-// Modules that need to be logged to need to be initialized here
-func NewPBSAnalytics(analytics *config.Analytics) analytics.PBSAnalyticsModule {
+// Modules that need to be logged to need to be initialized here. Each module is wrapped with its
+// analytics policy, and the outcome of every object it receives is recorded in the metrics engine.
+func NewPBSAnalytics(analytics *config.Analytics, metricsEngine metrics.MetricsEngine) analytics.PBSAnalyticsModule {
 	modules := make(enabledAnalytics, 0)
 	if len(analytics.File.Filename) > 0 {
 		if mod, err := filesystem.NewFileLogger(analytics.File.Filename); err == nil {
-			modules = append(modules, mod)
+			modules = append(modules, newPolicyModule("file", mod, analytics.File.Policy, metricsEngine))
 		} else {
 			glog.Fatalf("Could not initialize FileLogger for file %v :%v", analytics.File.Filename, err)
 		}
@@ -32,11 +35,20 @@ func NewPBSAnalytics(analytics *config.Analytics) analytics.PBSAnalyticsModule {
 			analytics.Pubstack.Buffers.Timeout,
 			clock.New())
 		if err == nil {
-			modules = append(modules, pubstackModule)
+			modules = append(modules, newPolicyModule("pubstack", pubstackModule, analytics.Pubstack.Policy, metricsEngine))
 		} else {
 			glog.Errorf("Could not initialize PubstackModule: %v", err)
 		}
 	}
+
+	if analytics.Stream.Enabled {
+		streamModule, err := stream.NewModule(analytics.Stream, clients.GetDefaultHttpInstance(), clock.New())
+		if err == nil {
+			modules = append(modules, newPolicyModule("stream", streamModule, analytics.Stream.Policy, metricsEngine))
+		} else {
+			glog.Errorf("Could not initialize StreamModule: %v", err)
+		}
+	}
 	return modules
 }
 
@@ -78,3 +90,11 @@ func (ea enabledAnalytics) LogNotificationEventObject(ne *analytics.Notification
 		module.LogNotificationEventObject(ne)
 	}
 }
+
+func (ea enabledAnalytics) LogShadowObject(so *analytics.ShadowObject) {
+	for _, module := range ea {
+		if shadowLogger, ok := module.(analytics.ShadowLogger); ok {
+			shadowLogger.LogShadowObject(so)
+		}
+	}
+}
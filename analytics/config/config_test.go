@@ -10,6 +10,7 @@ import (
 
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 )
 
 const TEST_DIR string = "testFiles"
@@ -81,8 +82,22 @@ func initAnalytics(count *int) analytics.PBSAnalyticsModule {
 	return &modules
 }
 
+type sampleShadowModule struct {
+	sampleModule
+}
+
+func (m *sampleShadowModule) LogShadowObject(so *analytics.ShadowObject) { *m.count++ }
+
+func TestLogShadowObject(t *testing.T) {
+	count := 0
+	modules := enabledAnalytics{&sampleModule{&count}, &sampleShadowModule{sampleModule{&count}}}
+
+	modules.LogShadowObject(&analytics.ShadowObject{})
+	assert.Equal(t, 1, count, "only logged to the modules which support the shadow objects")
+}
+
 func TestNewPBSAnalytics(t *testing.T) {
-	pbsAnalytics := NewPBSAnalytics(&config.Analytics{})
+	pbsAnalytics := NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{})
 	instance := pbsAnalytics.(enabledAnalytics)
 
 	assert.Equal(t, len(instance), 0)
@@ -95,7 +110,7 @@ func TestNewPBSAnalytics_FileLogger(t *testing.T) {
 		}
 	}
 	defer os.RemoveAll(TEST_DIR)
-	mod := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}})
+	mod := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}}, &metricsConfig.NilMetricsEngine{})
 	switch modType := mod.(type) {
 	case enabledAnalytics:
 		if len(enabledAnalytics(modType)) != 1 {
@@ -105,7 +120,7 @@ func TestNewPBSAnalytics_FileLogger(t *testing.T) {
 		t.Fatalf("Failed to initialize analytics module")
 	}
 
-	pbsAnalytics := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}})
+	pbsAnalytics := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}}, &metricsConfig.NilMetricsEngine{})
 	instance := pbsAnalytics.(enabledAnalytics)
 
 	assert.Equal(t, len(instance), 1)
@@ -125,7 +140,7 @@ func TestNewPBSAnalytics_Pubstack(t *testing.T) {
 			},
 			ConfRefresh: "2h",
 		},
-	})
+	}, &metricsConfig.NilMetricsEngine{})
 	instanceWithoutError := pbsAnalyticsWithoutError.(enabledAnalytics)
 
 	assert.Equal(t, len(instanceWithoutError), 1)
@@ -134,7 +149,7 @@ func TestNewPBSAnalytics_Pubstack(t *testing.T) {
 		Pubstack: config.Pubstack{
 			Enabled: true,
 		},
-	})
+	}, &metricsConfig.NilMetricsEngine{})
 	instanceWithError := pbsAnalyticsWithError.(enabledAnalytics)
 	assert.Equal(t, len(instanceWithError), 0)
 }
@@ -0,0 +1,324 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/golang/glog"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// resolvedRequestPath is where the response ext holds the resolved request when debug is enabled.
+var resolvedRequestPath = []string{"debug", "resolvedrequest"}
+
+// policyModule applies an analytics policy to every object before passing it to the wrapped module.
+// Objects are sampled per type and filtered by account, and the configured fields are removed from
+// copies of the requests they hold so the object shared with the other modules is never modified.
+// An object which can't be redacted is dropped rather than risk logging fields the policy excludes.
+type policyModule struct {
+	name             string
+	module           analytics.PBSAnalyticsModule
+	samplingRates    map[string]float64
+	enabledAccounts  map[string]struct{}
+	disabledAccounts map[string]struct{}
+	redactedPaths    [][]string
+	metricsEngine    metrics.MetricsEngine
+	randomFloat      func() float64
+}
+
+func newPolicyModule(name string, module analytics.PBSAnalyticsModule, policy config.AnalyticsPolicy, metricsEngine metrics.MetricsEngine) *policyModule {
+	pm := &policyModule{
+		name:             name,
+		module:           module,
+		samplingRates:    make(map[string]float64, len(policy.SamplingRates)),
+		enabledAccounts:  toSet(policy.EnabledAccounts),
+		disabledAccounts: toSet(policy.DisabledAccounts),
+		redactedPaths:    make([][]string, 0, len(policy.RedactedFields)),
+		metricsEngine:    metricsEngine,
+		randomFloat:      rand.Float64,
+	}
+	for objectType, rate := range policy.SamplingRates {
+		pm.samplingRates[strings.ToLower(objectType)] = rate
+	}
+	for _, field := range policy.RedactedFields {
+		pm.redactedPaths = append(pm.redactedPaths, strings.Split(field, "."))
+	}
+	return pm
+}
+
+func (pm *policyModule) LogAuctionObject(ao *analytics.AuctionObject) {
+	accountID := publisherID(ao.Request)
+	if ao.Account != nil {
+		accountID = ao.Account.ID
+	}
+	if pm.allow(config.AnalyticsObjectAuction, accountID, true, func() (err error) {
+		ao, err = pm.redactAuctionObject(ao)
+		return err
+	}) {
+		pm.module.LogAuctionObject(ao)
+	}
+}
+
+func (pm *policyModule) LogVideoObject(vo *analytics.VideoObject) {
+	if pm.allow(config.AnalyticsObjectVideo, publisherID(vo.Request), true, func() (err error) {
+		vo, err = pm.redactVideoObject(vo)
+		return err
+	}) {
+		pm.module.LogVideoObject(vo)
+	}
+}
+
+// LogCookieSyncObject is not filtered by account since cookie syncs are not made on behalf of an account.
+func (pm *policyModule) LogCookieSyncObject(cso *analytics.CookieSyncObject) {
+	if pm.allow(config.AnalyticsObjectCookieSync, "", false, nil) {
+		pm.module.LogCookieSyncObject(cso)
+	}
+}
+
+// LogSetUIDObject is not filtered by account since setuid calls are not made on behalf of an account.
+func (pm *policyModule) LogSetUIDObject(so *analytics.SetUIDObject) {
+	if pm.allow(config.AnalyticsObjectSetUID, "", false, nil) {
+		pm.module.LogSetUIDObject(so)
+	}
+}
+
+func (pm *policyModule) LogAmpObject(ao *analytics.AmpObject) {
+	if pm.allow(config.AnalyticsObjectAmp, publisherID(ao.Request), true, func() (err error) {
+		ao, err = pm.redactAmpObject(ao)
+		return err
+	}) {
+		pm.module.LogAmpObject(ao)
+	}
+}
+
+func (pm *policyModule) LogNotificationEventObject(ne *analytics.NotificationEvent) {
+	var accountID string
+	if ne.Account != nil {
+		accountID = ne.Account.ID
+	} else if ne.Request != nil {
+		accountID = ne.Request.AccountID
+	}
+	if pm.allow(config.AnalyticsObjectNotification, accountID, true, nil) {
+		pm.module.LogNotificationEventObject(ne)
+	}
+}
+
+func (pm *policyModule) LogShadowObject(so *analytics.ShadowObject) {
+	shadowLogger, ok := pm.module.(analytics.ShadowLogger)
+	if !ok {
+		return
+	}
+	if pm.allow(config.AnalyticsObjectShadow, so.AccountID, true, nil) {
+		shadowLogger.LogShadowObject(so)
+	}
+}
+
+// allow decides whether an object should be passed to the module and records the outcome. The redact
+// function, if any, is called when fields are redacted and the object is dropped if it fails.
+func (pm *policyModule) allow(objectType, accountID string, filterAccount bool, redact func() error) bool {
+	outcome := metrics.AnalyticsLogged
+	if filterAccount && !pm.accountEnabled(accountID) {
+		outcome = metrics.AnalyticsAccountDisabled
+	} else if rate, ok := pm.samplingRates[objectType]; ok && pm.randomFloat() >= rate {
+		outcome = metrics.AnalyticsSampledOut
+	} else if redact != nil && len(pm.redactedPaths) > 0 {
+		if err := redact(); err != nil {
+			glog.Errorf("Analytics module %s: failed to redact %s object: %v", pm.name, objectType, err)
+			outcome = metrics.AnalyticsRedactionFailed
+		}
+	}
+
+	pm.metricsEngine.RecordAnalyticsEvent(metrics.AnalyticsLabels{
+		Module:     pm.name,
+		ObjectType: objectType,
+		Outcome:    outcome,
+	})
+	return outcome == metrics.AnalyticsLogged
+}
+
+func (pm *policyModule) accountEnabled(accountID string) bool {
+	if _, disabled := pm.disabledAccounts[accountID]; disabled {
+		return false
+	}
+	if len(pm.enabledAccounts) == 0 {
+		return true
+	}
+	_, enabled := pm.enabledAccounts[accountID]
+	return enabled
+}
+
+func (pm *policyModule) redactAuctionObject(ao *analytics.AuctionObject) (*analytics.AuctionObject, error) {
+	redacted := *ao
+	var err error
+	if redacted.Request, err = pm.redactRequest(ao.Request); err != nil {
+		return nil, err
+	}
+	if redacted.Response, err = pm.redactResponse(ao.Response); err != nil {
+		return nil, err
+	}
+	if redacted.HookExecutionOutcome, err = pm.redactHookOutcomes(ao.HookExecutionOutcome); err != nil {
+		return nil, err
+	}
+	return &redacted, nil
+}
+
+func (pm *policyModule) redactVideoObject(vo *analytics.VideoObject) (*analytics.VideoObject, error) {
+	redacted := *vo
+	var err error
+	if redacted.Request, err = pm.redactRequest(vo.Request); err != nil {
+		return nil, err
+	}
+	if redacted.Response, err = pm.redactResponse(vo.Response); err != nil {
+		return nil, err
+	}
+	if vo.VideoRequest != nil {
+		redacted.VideoRequest = &openrtb_ext.BidRequestVideo{}
+		if err := pm.redactCopy(vo.VideoRequest, redacted.VideoRequest); err != nil {
+			return nil, err
+		}
+	}
+	return &redacted, nil
+}
+
+func (pm *policyModule) redactAmpObject(ao *analytics.AmpObject) (*analytics.AmpObject, error) {
+	redacted := *ao
+	var err error
+	if redacted.Request, err = pm.redactRequest(ao.Request); err != nil {
+		return nil, err
+	}
+	if redacted.AuctionResponse, err = pm.redactResponse(ao.AuctionResponse); err != nil {
+		return nil, err
+	}
+	if redacted.HookExecutionOutcome, err = pm.redactHookOutcomes(ao.HookExecutionOutcome); err != nil {
+		return nil, err
+	}
+	return &redacted, nil
+}
+
+// redactRequest returns a copy of the request without the redacted fields.
+func (pm *policyModule) redactRequest(request *openrtb2.BidRequest) (*openrtb2.BidRequest, error) {
+	if request == nil {
+		return nil, nil
+	}
+	redacted := &openrtb2.BidRequest{}
+	if err := pm.redactCopy(request, redacted); err != nil {
+		return nil, err
+	}
+	return redacted, nil
+}
+
+// redactResponse returns a copy of the response without the redacted fields of the resolved request
+// returned in ext.debug.resolvedrequest.
+func (pm *policyModule) redactResponse(response *openrtb2.BidResponse) (*openrtb2.BidResponse, error) {
+	if response == nil || len(response.Ext) == 0 {
+		return response, nil
+	}
+	if _, _, _, err := jsonparser.Get(response.Ext, resolvedRequestPath...); err != nil {
+		return response, nil
+	}
+
+	ext := append([]byte(nil), response.Ext...)
+	for _, path := range pm.redactedPaths {
+		ext = jsonparser.Delete(ext, append(resolvedRequestPath[:len(resolvedRequestPath):len(resolvedRequestPath)], path...)...)
+	}
+	if !json.Valid(ext) {
+		return nil, errors.New("the response ext is not valid JSON once redacted")
+	}
+	redacted := *response
+	redacted.Ext = ext
+	return &redacted, nil
+}
+
+// redactHookOutcomes returns a copy of the hook outcomes without the redacted fields in the values of
+// the analytics tags. The debug messages are dropped since their free text can't be redacted by path.
+func (pm *policyModule) redactHookOutcomes(outcomes []hookexecution.StageOutcome) ([]hookexecution.StageOutcome, error) {
+	if outcomes == nil {
+		return nil, nil
+	}
+	redacted := make([]hookexecution.StageOutcome, len(outcomes))
+	for i, stage := range outcomes {
+		redacted[i] = stage
+		redacted[i].Groups = make([]hookexecution.GroupOutcome, len(stage.Groups))
+		for j, group := range stage.Groups {
+			redacted[i].Groups[j] = group
+			redacted[i].Groups[j].InvocationResults = make([]hookexecution.HookOutcome, len(group.InvocationResults))
+			for k, hook := range group.InvocationResults {
+				hook.DebugMessages = nil
+				activities, err := pm.redactActivities(hook.AnalyticsTags.Activities)
+				if err != nil {
+					return nil, err
+				}
+				hook.AnalyticsTags.Activities = activities
+				redacted[i].Groups[j].InvocationResults[k] = hook
+			}
+		}
+	}
+	return redacted, nil
+}
+
+func (pm *policyModule) redactActivities(activities []hookanalytics.Activity) ([]hookanalytics.Activity, error) {
+	if activities == nil {
+		return nil, nil
+	}
+	redacted := make([]hookanalytics.Activity, len(activities))
+	for i, activity := range activities {
+		redacted[i] = activity
+		if activity.Results == nil {
+			continue
+		}
+		redacted[i].Results = make([]hookanalytics.Result, len(activity.Results))
+		for j, result := range activity.Results {
+			if result.Values != nil {
+				values := make(map[string]interface{}, len(result.Values))
+				if err := pm.redactCopy(result.Values, &values); err != nil {
+					return nil, err
+				}
+				result.Values = values
+			}
+			redacted[i].Results[j] = result
+		}
+	}
+	return redacted, nil
+}
+
+// redactCopy copies the value into the redacted value through JSON, without the redacted fields.
+func (pm *policyModule) redactCopy(value interface{}, redacted interface{}) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	for _, path := range pm.redactedPaths {
+		valueJSON = jsonparser.Delete(valueJSON, path...)
+	}
+	return json.Unmarshal(valueJSON, redacted)
+}
+
+func publisherID(request *openrtb2.BidRequest) string {
+	if request == nil {
+		return ""
+	}
+	if request.Site != nil && request.Site.Publisher != nil {
+		return request.Site.Publisher.ID
+	}
+	if request.App != nil && request.App.Publisher != nil {
+		return request.App.Publisher.ID
+	}
+	return ""
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+	return set
+}
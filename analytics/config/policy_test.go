@@ -0,0 +1,232 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type recordingModule struct {
+	auctions      []*analytics.AuctionObject
+	videos        []*analytics.VideoObject
+	amps          []*analytics.AmpObject
+	cookieSyncs   int
+	notifications int
+}
+
+func (m *recordingModule) LogAuctionObject(ao *analytics.AuctionObject) {
+	m.auctions = append(m.auctions, ao)
+}
+
+func (m *recordingModule) LogVideoObject(vo *analytics.VideoObject) {
+	m.videos = append(m.videos, vo)
+}
+
+func (m *recordingModule) LogCookieSyncObject(cso *analytics.CookieSyncObject) { m.cookieSyncs++ }
+
+func (m *recordingModule) LogSetUIDObject(so *analytics.SetUIDObject) {}
+
+func (m *recordingModule) LogAmpObject(ao *analytics.AmpObject) {
+	m.amps = append(m.amps, ao)
+}
+
+func (m *recordingModule) LogNotificationEventObject(ne *analytics.NotificationEvent) {
+	m.notifications++
+}
+
+func TestPolicyModuleAccountFiltering(t *testing.T) {
+	testCases := []struct {
+		description     string
+		policy          config.AnalyticsPolicy
+		auction         *analytics.AuctionObject
+		expectedLogged  bool
+		expectedOutcome metrics.AnalyticsOutcome
+	}{
+		{
+			description:     "No policy",
+			auction:         &analytics.AuctionObject{Account: &config.Account{ID: "acc"}},
+			expectedLogged:  true,
+			expectedOutcome: metrics.AnalyticsLogged,
+		},
+		{
+			description:     "Account disabled",
+			policy:          config.AnalyticsPolicy{DisabledAccounts: []string{"acc"}},
+			auction:         &analytics.AuctionObject{Account: &config.Account{ID: "acc"}},
+			expectedLogged:  false,
+			expectedOutcome: metrics.AnalyticsAccountDisabled,
+		},
+		{
+			description:     "Account not in enabled list",
+			policy:          config.AnalyticsPolicy{EnabledAccounts: []string{"other"}},
+			auction:         &analytics.AuctionObject{Account: &config.Account{ID: "acc"}},
+			expectedLogged:  false,
+			expectedOutcome: metrics.AnalyticsAccountDisabled,
+		},
+		{
+			description:     "Disabled takes precedence over enabled",
+			policy:          config.AnalyticsPolicy{EnabledAccounts: []string{"acc"}, DisabledAccounts: []string{"acc"}},
+			auction:         &analytics.AuctionObject{Account: &config.Account{ID: "acc"}},
+			expectedLogged:  false,
+			expectedOutcome: metrics.AnalyticsAccountDisabled,
+		},
+		{
+			description: "Account taken from the publisher when not resolved",
+			policy:      config.AnalyticsPolicy{EnabledAccounts: []string{"pub"}},
+			auction: &analytics.AuctionObject{Request: &openrtb2.BidRequest{
+				App: &openrtb2.App{Publisher: &openrtb2.Publisher{ID: "pub"}},
+			}},
+			expectedLogged:  true,
+			expectedOutcome: metrics.AnalyticsLogged,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			metricsEngine := &metrics.MetricsEngineMock{}
+			metricsEngine.On("RecordAnalyticsEvent", metrics.AnalyticsLabels{
+				Module:     "test",
+				ObjectType: config.AnalyticsObjectAuction,
+				Outcome:    test.expectedOutcome,
+			}).Once()
+
+			module := &recordingModule{}
+			newPolicyModule("test", module, test.policy, metricsEngine).LogAuctionObject(test.auction)
+
+			assert.Equal(t, test.expectedLogged, len(module.auctions) == 1)
+			metricsEngine.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPolicyModuleSampling(t *testing.T) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvent", mock.Anything)
+
+	module := &recordingModule{}
+	pm := newPolicyModule("test", module, config.AnalyticsPolicy{
+		SamplingRates:   map[string]float64{config.AnalyticsObjectCookieSync: 0.25},
+		EnabledAccounts: []string{"acc"},
+	}, metricsEngine)
+
+	for _, random := range []float64{0, 0.2, 0.25, 0.9} {
+		pm.randomFloat = func() float64 { return random }
+		pm.LogCookieSyncObject(&analytics.CookieSyncObject{})
+		pm.LogNotificationEventObject(&analytics.NotificationEvent{Request: &analytics.EventRequest{AccountID: "acc"}})
+	}
+
+	assert.Equal(t, 2, module.cookieSyncs, "cookie syncs are sampled but not filtered by account")
+	assert.Equal(t, 4, module.notifications, "types without a sampling rate are always logged")
+	metricsEngine.AssertCalled(t, "RecordAnalyticsEvent", metrics.AnalyticsLabels{Module: "test", ObjectType: config.AnalyticsObjectCookieSync, Outcome: metrics.AnalyticsSampledOut})
+	metricsEngine.AssertNumberOfCalls(t, "RecordAnalyticsEvent", 8)
+}
+
+func TestPolicyModuleRedaction(t *testing.T) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvent", mock.Anything)
+
+	module := &recordingModule{}
+	pm := newPolicyModule("test", module, config.AnalyticsPolicy{
+		RedactedFields: []string{"user", "device.ip", "site.missing"},
+	}, metricsEngine)
+
+	request := &openrtb2.BidRequest{
+		ID:     "req",
+		User:   &openrtb2.User{ID: "user"},
+		Device: &openrtb2.Device{IP: "1.2.3.4", UA: "ua"},
+	}
+	auction := &analytics.AuctionObject{Request: request, Status: 200}
+	pm.LogAuctionObject(auction)
+
+	if assert.Len(t, module.auctions, 1) {
+		logged := module.auctions[0]
+		assert.Equal(t, 200, logged.Status)
+		assert.Equal(t, &openrtb2.BidRequest{ID: "req", Device: &openrtb2.Device{UA: "ua"}}, logged.Request)
+	}
+	assert.Equal(t, &openrtb2.User{ID: "user"}, auction.Request.User, "the shared request must not be modified")
+	assert.Equal(t, "1.2.3.4", auction.Request.Device.IP, "the shared request must not be modified")
+}
+
+func TestPolicyModuleRedactionOfEveryCopy(t *testing.T) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvent", mock.Anything)
+
+	module := &recordingModule{}
+	pm := newPolicyModule("test", module, config.AnalyticsPolicy{
+		RedactedFields: []string{"user", "device.ip"},
+	}, metricsEngine)
+
+	response := &openrtb2.BidResponse{
+		ID:  "resp",
+		Ext: json.RawMessage(`{"debug":{"resolvedrequest":{"id":"req","user":{"id":"user"},"device":{"ip":"1.2.3.4","ua":"ua"}}}}`),
+	}
+	outcomes := []hookexecution.StageOutcome{{
+		Entity: "auction-request",
+		Groups: []hookexecution.GroupOutcome{{InvocationResults: []hookexecution.HookOutcome{{
+			HookID: hookexecution.HookID{ModuleCode: "module", HookImplCode: "hook"},
+			AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+				Name:    "activity",
+				Results: []hookanalytics.Result{{Values: map[string]interface{}{"user": "user", "country": "FR"}}},
+			}}},
+			DebugMessages: []string{"user user"},
+		}}}},
+	}}
+	pm.LogAuctionObject(&analytics.AuctionObject{Response: response, HookExecutionOutcome: outcomes})
+	pm.LogAmpObject(&analytics.AmpObject{AuctionResponse: response, HookExecutionOutcome: outcomes})
+
+	videoRequest := &openrtb_ext.BidRequestVideo{
+		User:   &openrtb2.User{ID: "user"},
+		Device: openrtb2.Device{IP: "1.2.3.4", UA: "ua"},
+	}
+	pm.LogVideoObject(&analytics.VideoObject{VideoRequest: videoRequest, Response: response})
+
+	expectedExt := `{"debug":{"resolvedrequest":{"id":"req","device":{"ua":"ua"}}}}`
+	if assert.Len(t, module.auctions, 1) {
+		logged := module.auctions[0]
+		assert.JSONEq(t, expectedExt, string(logged.Response.Ext), "auction resolved request")
+		if assert.Len(t, logged.HookExecutionOutcome, 1) {
+			hook := logged.HookExecutionOutcome[0].Groups[0].InvocationResults[0]
+			assert.Equal(t, map[string]interface{}{"country": "FR"}, hook.AnalyticsTags.Activities[0].Results[0].Values, "hook analytics tags")
+			assert.Nil(t, hook.DebugMessages, "hook debug messages")
+			assert.Equal(t, "module", hook.HookID.ModuleCode, "hook ID")
+		}
+	}
+	if assert.Len(t, module.amps, 1) {
+		assert.JSONEq(t, expectedExt, string(module.amps[0].AuctionResponse.Ext), "amp resolved request")
+		assert.Len(t, module.amps[0].HookExecutionOutcome, 1, "amp hook outcomes")
+	}
+	if assert.Len(t, module.videos, 1) {
+		assert.Nil(t, module.videos[0].VideoRequest.User, "video request user")
+		assert.Equal(t, openrtb2.Device{UA: "ua"}, module.videos[0].VideoRequest.Device, "video request device")
+		assert.JSONEq(t, expectedExt, string(module.videos[0].Response.Ext), "video resolved request")
+	}
+
+	assert.Contains(t, string(response.Ext), `"user":{"id":"user"}`, "the shared response must not be modified")
+	assert.Equal(t, map[string]interface{}{"user": "user", "country": "FR"}, outcomes[0].Groups[0].InvocationResults[0].AnalyticsTags.Activities[0].Results[0].Values, "the shared outcomes must not be modified")
+	assert.Equal(t, []string{"user user"}, outcomes[0].Groups[0].InvocationResults[0].DebugMessages, "the shared outcomes must not be modified")
+	assert.Equal(t, "1.2.3.4", videoRequest.Device.IP, "the shared video request must not be modified")
+}
+
+func TestPolicyModuleRedactionFailure(t *testing.T) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvent", mock.Anything)
+
+	module := &recordingModule{}
+	pm := newPolicyModule("test", module, config.AnalyticsPolicy{
+		RedactedFields: []string{"user"},
+	}, metricsEngine)
+
+	pm.LogAuctionObject(&analytics.AuctionObject{Request: &openrtb2.BidRequest{ID: "req", Ext: json.RawMessage(`{malformed`)}})
+
+	assert.Empty(t, module.auctions, "an object which can't be redacted is dropped")
+	metricsEngine.AssertCalled(t, "RecordAnalyticsEvent", metrics.AnalyticsLabels{Module: "test", ObjectType: config.AnalyticsObjectAuction, Outcome: metrics.AnalyticsRedactionFailed})
+	metricsEngine.AssertNumberOfCalls(t, "RecordAnalyticsEvent", 1)
+}
@@ -26,6 +26,12 @@ type PBSAnalyticsModule interface {
 	LogNotificationEventObject(*NotificationEvent)
 }
 
+// ShadowLogger is implemented by the modules logging the requests mirrored to the shadow endpoints of the
+// bidders.
+type ShadowLogger interface {
+	LogShadowObject(*ShadowObject)
+}
+
 // Loggable object of a transaction at /openrtb2/auction endpoint
 type AuctionObject struct {
 	Status               int
@@ -93,3 +99,17 @@ type NotificationEvent struct {
 	Request *EventRequest   `json:"request"`
 	Account *config.Account `json:"account"`
 }
+
+// Loggable object of a request mirrored to the shadow endpoint of a bidder. Its bids never take part in
+// the auction.
+type ShadowObject struct {
+	Bidder    string
+	AccountID string
+	Endpoint  string
+	// Status is the HTTP status of the response, 0 if none was received
+	Status    int
+	Errors    []error
+	Bids      []*openrtb2.Bid
+	Latency   time.Duration
+	StartTime time.Time
+}
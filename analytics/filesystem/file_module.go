@@ -18,6 +18,7 @@ const (
 	SETUID             RequestType = "/set_uid"
 	AMP                RequestType = "/openrtb2/amp"
 	NOTIFICATION_EVENT RequestType = "/event"
+	SHADOW             RequestType = "shadow"
 )
 
 // Module that can perform transactional logging
@@ -85,6 +86,18 @@ func (f *FileLogger) LogNotificationEventObject(ne *analytics.NotificationEvent)
 	f.Logger.Flush()
 }
 
+// Logs ShadowObject to file
+func (f *FileLogger) LogShadowObject(so *analytics.ShadowObject) {
+	if so == nil {
+		return
+	}
+	//Code to parse the object and log in a way required
+	var b bytes.Buffer
+	b.WriteString(jsonifyShadowObject(so))
+	f.Logger.Debug(b.String())
+	f.Logger.Flush()
+}
+
 // Method to initialize the analytic module
 func NewFileLogger(filename string) (analytics.PBSAnalyticsModule, error) {
 	options := glog.LogOptions{
@@ -204,3 +217,20 @@ func jsonifyNotificationEventObject(ne *analytics.NotificationEvent) string {
 		return fmt.Sprintf("Transactional Logs Error: NotificationEvent object badly formed %v", err)
 	}
 }
+
+func jsonifyShadowObject(so *analytics.ShadowObject) string {
+	type alias analytics.ShadowObject
+	b, err := json.Marshal(&struct {
+		Type RequestType `json:"type"`
+		*alias
+	}{
+		Type:  SHADOW,
+		alias: (*alias)(so),
+	})
+
+	if err == nil {
+		return string(b)
+	} else {
+		return fmt.Sprintf("Transactional Logs Error: Shadow object badly formed %v", err)
+	}
+}
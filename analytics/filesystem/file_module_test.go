@@ -79,6 +79,17 @@ func TestLogNotificationEventObject_ToJson(t *testing.T) {
 	}
 }
 
+func TestLogShadowObject_ToJson(t *testing.T) {
+	so := &analytics.ShadowObject{
+		Bidder:   "bidder",
+		Endpoint: "https://candidate.bidder.com",
+		Status:   http.StatusNoContent,
+	}
+	if soJson := jsonifyShadowObject(so); strings.Contains(soJson, "Transactional Logs Error") {
+		t.Fatalf("ShadowObject failed to convert to json")
+	}
+}
+
 func TestFileLogger_LogObjects(t *testing.T) {
 	if _, err := os.Stat(TEST_DIR); os.IsNotExist(err) {
 		if err = os.MkdirAll(TEST_DIR, 0755); err != nil {
@@ -93,6 +104,7 @@ func TestFileLogger_LogObjects(t *testing.T) {
 		fl.LogSetUIDObject(&analytics.SetUIDObject{})
 		fl.LogCookieSyncObject(&analytics.CookieSyncObject{})
 		fl.LogNotificationEventObject(&analytics.NotificationEvent{})
+		fl.(analytics.ShadowLogger).LogShadowObject(&analytics.ShadowObject{})
 	} else {
 		t.Fatalf("Couldn't initialize file logger: %v", err)
 	}
@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/docker/go-units"
+	"github.com/golang/glog"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// fileSink appends gzipped batches to a local file and starts a new file
+// once the current one reaches the max size or the rotation interval elapses.
+// Concatenated gzip members form a valid gzip stream, so every file can be read with standard tools.
+type fileSink struct {
+	directory        string
+	prefix           string
+	maxSize          int64
+	rotationInterval time.Duration
+	clock            clock.Clock
+
+	mutex     sync.Mutex
+	file      *os.File
+	size      int64
+	openedAt  time.Time
+	fileIndex int
+}
+
+func newFileSink(cfg config.StreamFileSink, clock clock.Clock) (*fileSink, error) {
+	maxSize, err := units.FromHumanSize(cfg.MaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse analytics.stream.file.max_size: %v", err)
+	}
+	rotationInterval, err := time.ParseDuration(cfg.RotationInterval)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse analytics.stream.file.rotation_interval: %v", err)
+	}
+	if err := os.MkdirAll(cfg.Directory, 0755); err != nil {
+		return nil, err
+	}
+
+	return &fileSink{
+		directory:        cfg.Directory,
+		prefix:           cfg.Prefix,
+		maxSize:          maxSize,
+		rotationInterval: rotationInterval,
+		clock:            clock,
+	}, nil
+}
+
+func (s *fileSink) send(payload []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.shouldRotate() {
+		if err := s.rotate(); err != nil {
+			glog.Errorf("[stream] Cannot rotate analytics file: %v", err)
+			return err
+		}
+	}
+
+	n, err := s.file.Write(payload)
+	s.size += int64(n)
+	if err != nil {
+		glog.Errorf("[stream] Cannot write analytics file %s: %v", s.file.Name(), err)
+	}
+	return err
+}
+
+func (s *fileSink) shouldRotate() bool {
+	if s.file == nil {
+		return true
+	}
+	if s.maxSize > 0 && s.size >= s.maxSize {
+		return true
+	}
+	return s.rotationInterval > 0 && s.clock.Now().Sub(s.openedAt) >= s.rotationInterval
+}
+
+func (s *fileSink) rotate() error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			glog.Warningf("[stream] Cannot close analytics file %s: %v", s.file.Name(), err)
+		}
+		s.file = nil
+	}
+
+	now := s.clock.Now().UTC()
+	s.fileIndex++
+	name := fmt.Sprintf("%s-%s-%d.jsonl.gz", s.prefix, now.Format("20060102T150405Z"), s.fileIndex)
+
+	file, err := os.OpenFile(filepath.Join(s.directory, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	s.file = file
+	s.size = 0
+	s.openedAt = now
+	return nil
+}
@@ -0,0 +1,83 @@
+package stream
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	writer := gzip.NewWriter(&b)
+	writer.Write([]byte(data))
+	writer.Close()
+	return b.Bytes()
+}
+
+func readDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("cannot read dir: %v", err)
+	}
+	var contents []string
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("cannot read file: %v", err)
+		}
+		contents = append(contents, gunzip(t, data))
+	}
+	sort.Strings(contents)
+	return contents
+}
+
+func TestFileSinkRotationBySize(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := newFileSink(config.StreamFileSink{Directory: dir, Prefix: "test", MaxSize: "60B", RotationInterval: "1h"}, clock.NewMock())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// each gzipped batch is larger than 30 bytes, so only the first two fit into a single file
+	assert.NoError(t, sink.send(gzipBytes(t, "{\"a\":1}\n")))
+	assert.NoError(t, sink.send(gzipBytes(t, "{\"a\":2}\n")))
+	assert.NoError(t, sink.send(gzipBytes(t, "{\"a\":3}\n")))
+
+	assert.Equal(t, []string{"{\"a\":1}\n{\"a\":2}\n", "{\"a\":3}\n"}, readDir(t, dir))
+}
+
+func TestFileSinkRotationByTime(t *testing.T) {
+	dir := t.TempDir()
+	mockClock := clock.NewMock()
+	sink, err := newFileSink(config.StreamFileSink{Directory: dir, Prefix: "test", MaxSize: "1MB", RotationInterval: "1m"}, mockClock)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, sink.send(gzipBytes(t, "{\"a\":1}\n")))
+	mockClock.Add(30 * time.Second)
+	assert.NoError(t, sink.send(gzipBytes(t, "{\"a\":2}\n")))
+	mockClock.Add(30 * time.Second)
+	assert.NoError(t, sink.send(gzipBytes(t, "{\"a\":3}\n")))
+
+	assert.Equal(t, []string{"{\"a\":1}\n{\"a\":2}\n", "{\"a\":3}\n"}, readDir(t, dir))
+}
+
+func TestNewFileSinkInvalidConfig(t *testing.T) {
+	_, err := newFileSink(config.StreamFileSink{Directory: t.TempDir(), MaxSize: "invalid", RotationInterval: "1h"}, clock.NewMock())
+	assert.EqualError(t, err, "fail to parse analytics.stream.file.max_size: invalid size: 'invalid'")
+
+	_, err = newFileSink(config.StreamFileSink{Directory: t.TempDir(), MaxSize: "1MB", RotationInterval: "invalid"}, clock.NewMock())
+	assert.EqualError(t, err, `fail to parse analytics.stream.file.rotation_interval: time: invalid duration "invalid"`)
+}
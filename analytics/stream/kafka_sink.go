@@ -0,0 +1,250 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// Kafka wire protocol constants, see https://kafka.apache.org/protocol
+const (
+	kafkaProduceAPIKey     int16 = 0
+	kafkaProduceAPIVersion int16 = 3
+	kafkaRecordBatchMagic  int8  = 2
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// kafkaSink produces every event as a separate record to a single topic partition
+// using the Kafka wire protocol. The configured broker must be the leader of the partition.
+type kafkaSink struct {
+	broker    string
+	topic     string
+	partition int32
+	clientID  string
+	acks      int16
+	timeout   time.Duration
+
+	mutex         sync.Mutex
+	conn          net.Conn
+	correlationID int32
+}
+
+func newKafkaSink(cfg config.StreamKafkaSink) *kafkaSink {
+	return &kafkaSink{
+		broker:    cfg.Broker,
+		topic:     cfg.Topic,
+		partition: cfg.Partition,
+		clientID:  cfg.ClientID,
+		acks:      cfg.Acks,
+		timeout:   time.Duration(cfg.TimeoutMS) * time.Millisecond,
+	}
+}
+
+func (s *kafkaSink) send(payload []byte) error {
+	records, err := splitEvents(payload)
+	if err != nil {
+		glog.Errorf("[stream] Cannot read analytics batch: %v", err)
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.produce(records, time.Now()); err != nil {
+		glog.Errorf("[stream] Cannot produce analytics batch to %s: %v", s.broker, err)
+		s.closeConnection()
+		return err
+	}
+	return nil
+}
+
+func (s *kafkaSink) produce(records [][]byte, timestamp time.Time) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout("tcp", s.broker, s.timeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	s.correlationID++
+	request := encodeProduceRequest(s.correlationID, s.clientID, s.acks, s.timeout, s.topic, s.partition, encodeRecordBatch(records, timestamp))
+
+	if s.timeout > 0 {
+		s.conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+	if _, err := s.conn.Write(request); err != nil {
+		return err
+	}
+
+	// the broker doesn't send a response if no acknowledgement is required
+	if s.acks == 0 {
+		return nil
+	}
+
+	return readProduceResponse(s.conn, s.correlationID)
+}
+
+func (s *kafkaSink) closeConnection() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// splitEvents decompresses the batch produced by the event channel into single JSON events.
+func splitEvents(payload []byte) ([][]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var events [][]byte
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), len(payload)*1024+64*1024)
+	for scanner.Scan() {
+		if line := scanner.Bytes(); len(line) > 0 {
+			events = append(events, append([]byte(nil), line...))
+		}
+	}
+	return events, scanner.Err()
+}
+
+// encodeRecordBatch encodes the records as an uncompressed v2 record batch.
+func encodeRecordBatch(records [][]byte, timestamp time.Time) []byte {
+	millis := timestamp.UnixMilli()
+
+	var body []byte
+	body = binary.BigEndian.AppendUint16(body, 0) // attributes: no compression, create time
+	body = binary.BigEndian.AppendUint32(body, uint32(len(records)-1))
+	body = binary.BigEndian.AppendUint64(body, uint64(millis)) // base timestamp
+	body = binary.BigEndian.AppendUint64(body, uint64(millis)) // max timestamp
+	body = binary.BigEndian.AppendUint64(body, 0xFFFFFFFFFFFFFFFF)
+	body = binary.BigEndian.AppendUint16(body, 0xFFFF)     // producer epoch
+	body = binary.BigEndian.AppendUint32(body, 0xFFFFFFFF) // base sequence
+	body = binary.BigEndian.AppendUint32(body, uint32(len(records)))
+	for i, value := range records {
+		var record []byte
+		record = append(record, 0)              // attributes
+		record = binary.AppendVarint(record, 0) // timestamp delta
+		record = binary.AppendVarint(record, int64(i))
+		record = binary.AppendVarint(record, -1) // null key
+		record = binary.AppendVarint(record, int64(len(value)))
+		record = append(record, value...)
+		record = binary.AppendVarint(record, 0) // no headers
+
+		body = binary.AppendVarint(body, int64(len(record)))
+		body = append(body, record...)
+	}
+
+	var batch []byte
+	batch = binary.BigEndian.AppendUint64(batch, 0) // base offset
+	// batch length covers everything after this field: leader epoch, magic, crc and body
+	batch = binary.BigEndian.AppendUint32(batch, uint32(4+1+4+len(body)))
+	batch = binary.BigEndian.AppendUint32(batch, 0) // partition leader epoch
+	batch = append(batch, byte(kafkaRecordBatchMagic))
+	batch = binary.BigEndian.AppendUint32(batch, crc32.Checksum(body, castagnoliTable))
+	return append(batch, body...)
+}
+
+func encodeProduceRequest(correlationID int32, clientID string, acks int16, timeout time.Duration, topic string, partition int32, recordBatch []byte) []byte {
+	var request []byte
+	request = binary.BigEndian.AppendUint16(request, uint16(kafkaProduceAPIKey))
+	request = binary.BigEndian.AppendUint16(request, uint16(kafkaProduceAPIVersion))
+	request = binary.BigEndian.AppendUint32(request, uint32(correlationID))
+	request = appendKafkaString(request, clientID)
+	request = binary.BigEndian.AppendUint16(request, 0xFFFF) // null transactional id
+	request = binary.BigEndian.AppendUint16(request, uint16(acks))
+	request = binary.BigEndian.AppendUint32(request, uint32(timeout.Milliseconds()))
+	request = binary.BigEndian.AppendUint32(request, 1) // topics
+	request = appendKafkaString(request, topic)
+	request = binary.BigEndian.AppendUint32(request, 1) // partitions
+	request = binary.BigEndian.AppendUint32(request, uint32(partition))
+	request = binary.BigEndian.AppendUint32(request, uint32(len(recordBatch)))
+	request = append(request, recordBatch...)
+
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(request))), request...)
+}
+
+func appendKafkaString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readProduceResponse reads the v3 produce response and returns an error if the broker rejected the batch.
+func readProduceResponse(reader io.Reader, correlationID int32) error {
+	var size int32
+	if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+		return err
+	}
+	if size < 4 {
+		return fmt.Errorf("invalid produce response size %d", size)
+	}
+	response := make([]byte, size)
+	if _, err := io.ReadFull(reader, response); err != nil {
+		return err
+	}
+
+	r := bytes.NewReader(response)
+	var responseCorrelationID, topicCount int32
+	if err := binary.Read(r, binary.BigEndian, &responseCorrelationID); err != nil {
+		return err
+	}
+	if responseCorrelationID != correlationID {
+		return fmt.Errorf("unexpected correlation id %d, expected %d", responseCorrelationID, correlationID)
+	}
+	if err := binary.Read(r, binary.BigEndian, &topicCount); err != nil {
+		return err
+	}
+
+	for i := int32(0); i < topicCount; i++ {
+		var nameLength int16
+		if err := binary.Read(r, binary.BigEndian, &nameLength); err != nil {
+			return err
+		}
+		if _, err := r.Seek(int64(nameLength), io.SeekCurrent); err != nil {
+			return err
+		}
+
+		var partitionCount int32
+		if err := binary.Read(r, binary.BigEndian, &partitionCount); err != nil {
+			return err
+		}
+		for j := int32(0); j < partitionCount; j++ {
+			var partitionResponse struct {
+				Partition     int32
+				ErrorCode     int16
+				BaseOffset    int64
+				LogAppendTime int64
+			}
+			if err := binary.Read(r, binary.BigEndian, &partitionResponse); err != nil {
+				return err
+			}
+			if partitionResponse.ErrorCode != 0 {
+				return fmt.Errorf("broker rejected partition %d with error code %d", partitionResponse.Partition, partitionResponse.ErrorCode)
+			}
+		}
+	}
+
+	if topicCount == 0 {
+		return errors.New("empty produce response")
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// producedBatch holds the data received by the broker stand-in.
+type producedBatch struct {
+	apiKey     int16
+	apiVersion int16
+	clientID   string
+	topic      string
+	partition  int32
+	records    []string
+	validCRC   bool
+}
+
+// startBrokerStandIn accepts produce requests on a local port, decodes them and replies with the given error code.
+func startBrokerStandIn(t *testing.T, errorCode int16) (string, <-chan producedBatch) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot start broker stand-in: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	batches := make(chan producedBatch, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var size int32
+			if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+				return
+			}
+			request := make([]byte, size)
+			if _, err := io.ReadFull(conn, request); err != nil {
+				return
+			}
+
+			batch, correlationID := decodeTestProduceRequest(request)
+			batches <- batch
+
+			var response []byte
+			response = binary.BigEndian.AppendUint32(response, uint32(correlationID))
+			response = binary.BigEndian.AppendUint32(response, 1)
+			response = appendKafkaString(response, batch.topic)
+			response = binary.BigEndian.AppendUint32(response, 1)
+			response = binary.BigEndian.AppendUint32(response, uint32(batch.partition))
+			response = binary.BigEndian.AppendUint16(response, uint16(errorCode))
+			response = binary.BigEndian.AppendUint64(response, 0)
+			response = binary.BigEndian.AppendUint64(response, 0xFFFFFFFFFFFFFFFF)
+			response = binary.BigEndian.AppendUint32(response, 0) // throttle time
+			conn.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(response))), response...))
+		}
+	}()
+
+	return listener.Addr().String(), batches
+}
+
+func decodeTestProduceRequest(request []byte) (producedBatch, int32) {
+	r := bytes.NewReader(request)
+	readString := func() string {
+		var length int16
+		binary.Read(r, binary.BigEndian, &length)
+		if length < 0 {
+			return ""
+		}
+		s := make([]byte, length)
+		r.Read(s)
+		return string(s)
+	}
+
+	var batch producedBatch
+	var correlationID, timeout, topicCount, partitionCount, batchSize int32
+	var acks int16
+	binary.Read(r, binary.BigEndian, &batch.apiKey)
+	binary.Read(r, binary.BigEndian, &batch.apiVersion)
+	binary.Read(r, binary.BigEndian, &correlationID)
+	batch.clientID = readString()
+	readString() // transactional id
+	binary.Read(r, binary.BigEndian, &acks)
+	binary.Read(r, binary.BigEndian, &timeout)
+	binary.Read(r, binary.BigEndian, &topicCount)
+	batch.topic = readString()
+	binary.Read(r, binary.BigEndian, &partitionCount)
+	binary.Read(r, binary.BigEndian, &batch.partition)
+	binary.Read(r, binary.BigEndian, &batchSize)
+
+	recordBatch := make([]byte, batchSize)
+	r.Read(recordBatch)
+
+	// base offset (8), batch length (4), leader epoch (4), magic (1), crc (4)
+	crc := binary.BigEndian.Uint32(recordBatch[17:21])
+	body := recordBatch[21:]
+	batch.validCRC = crc == crc32.Checksum(body, crc32.MakeTable(crc32.Castagnoli))
+
+	// attributes (2), last offset delta (4), timestamps (16), producer id (8), epoch (2), sequence (4)
+	recordCount := binary.BigEndian.Uint32(body[36:40])
+	records := bytes.NewReader(body[40:])
+	for i := uint32(0); i < recordCount; i++ {
+		binary.ReadVarint(records) // length
+		records.ReadByte()         // attributes
+		binary.ReadVarint(records) // timestamp delta
+		binary.ReadVarint(records) // offset delta
+		binary.ReadVarint(records) // key length
+		valueLength, _ := binary.ReadVarint(records)
+		value := make([]byte, valueLength)
+		records.Read(value)
+		binary.ReadVarint(records) // headers
+		batch.records = append(batch.records, string(value))
+	}
+
+	return batch, correlationID
+}
+
+func TestKafkaSinkSend(t *testing.T) {
+	address, batches := startBrokerStandIn(t, 0)
+	sink := newKafkaSink(config.StreamKafkaSink{Broker: address, Topic: "pbs-analytics", Partition: 2, ClientID: "pbs", Acks: 1, TimeoutMS: 1000})
+
+	assert.NoError(t, sink.send(gzipBytes(t, "{\"a\":1}\n{\"a\":2}\n")))
+	assert.NoError(t, sink.send(gzipBytes(t, "{\"a\":3}\n")))
+
+	assert.Equal(t, producedBatch{
+		apiKey:     0,
+		apiVersion: 3,
+		clientID:   "pbs",
+		topic:      "pbs-analytics",
+		partition:  2,
+		records:    []string{`{"a":1}`, `{"a":2}`},
+		validCRC:   true,
+	}, <-batches)
+	assert.Equal(t, []string{`{"a":3}`}, (<-batches).records)
+}
+
+func TestKafkaSinkSendBrokerError(t *testing.T) {
+	address, batches := startBrokerStandIn(t, 6)
+	sink := newKafkaSink(config.StreamKafkaSink{Broker: address, Topic: "pbs-analytics", Acks: 1, TimeoutMS: 1000})
+
+	err := sink.send(gzipBytes(t, "{\"a\":1}\n"))
+	assert.EqualError(t, err, "broker rejected partition 0 with error code 6")
+	assert.Len(t, batches, 1)
+	assert.Nil(t, sink.conn)
+}
+
+func TestKafkaSinkSendUnreachableBroker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	sink := newKafkaSink(config.StreamKafkaSink{Broker: address, Topic: "pbs-analytics", Acks: 1, TimeoutMS: 1000})
+	assert.Error(t, sink.send(gzipBytes(t, "{\"a\":1}\n")))
+}
+
+func TestSplitEventsInvalidPayload(t *testing.T) {
+	_, err := splitEvents([]byte("not gzip"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,203 @@
+package stream
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// SchemaVersion is incremented on every backward incompatible change of the event schema.
+// Adding new fields is considered backward compatible.
+const SchemaVersion = 1
+
+// Event types
+const (
+	eventTypeAuction      = "auction"
+	eventTypeAmp          = "amp"
+	eventTypeVideo        = "video"
+	eventTypeCookieSync   = "cookie_sync"
+	eventTypeSetUID       = "setuid"
+	eventTypeNotification = "notification"
+	eventTypeShadow       = "shadow"
+)
+
+// event is the envelope shared by all event types, exactly one of the payload fields is set.
+type event struct {
+	SchemaVersion int       `json:"schema_version"`
+	Type          string    `json:"type"`
+	Timestamp     time.Time `json:"timestamp"`
+
+	Auction      *auctionEvent      `json:"auction,omitempty"`
+	Amp          *ampEvent          `json:"amp,omitempty"`
+	Video        *videoEvent        `json:"video,omitempty"`
+	CookieSync   *cookieSyncEvent   `json:"cookie_sync,omitempty"`
+	SetUID       *setUIDEvent       `json:"setuid,omitempty"`
+	Notification *notificationEvent `json:"notification,omitempty"`
+	Shadow       *shadowEvent       `json:"shadow,omitempty"`
+}
+
+type auctionEvent struct {
+	Status               int                          `json:"status"`
+	Errors               []string                     `json:"errors,omitempty"`
+	AccountID            string                       `json:"account_id,omitempty"`
+	Request              *openrtb2.BidRequest         `json:"request,omitempty"`
+	Response             *openrtb2.BidResponse        `json:"response,omitempty"`
+	StartTime            time.Time                    `json:"start_time"`
+	HookExecutionOutcome []hookexecution.StageOutcome `json:"hook_execution_outcome,omitempty"`
+}
+
+type ampEvent struct {
+	Status               int                          `json:"status"`
+	Errors               []string                     `json:"errors,omitempty"`
+	Request              *openrtb2.BidRequest         `json:"request,omitempty"`
+	Response             *openrtb2.BidResponse        `json:"response,omitempty"`
+	TargetingValues      map[string]string            `json:"targeting_values,omitempty"`
+	Origin               string                       `json:"origin,omitempty"`
+	StartTime            time.Time                    `json:"start_time"`
+	HookExecutionOutcome []hookexecution.StageOutcome `json:"hook_execution_outcome,omitempty"`
+}
+
+type videoEvent struct {
+	Status        int                           `json:"status"`
+	Errors        []string                      `json:"errors,omitempty"`
+	Request       *openrtb2.BidRequest          `json:"request,omitempty"`
+	Response      *openrtb2.BidResponse         `json:"response,omitempty"`
+	VideoRequest  *openrtb_ext.BidRequestVideo  `json:"video_request,omitempty"`
+	VideoResponse *openrtb_ext.BidResponseVideo `json:"video_response,omitempty"`
+	StartTime     time.Time                     `json:"start_time"`
+}
+
+type cookieSyncEvent struct {
+	Status       int                           `json:"status"`
+	Errors       []string                      `json:"errors,omitempty"`
+	BidderStatus []*analytics.CookieSyncBidder `json:"bidder_status,omitempty"`
+}
+
+type setUIDEvent struct {
+	Status  int      `json:"status"`
+	Errors  []string `json:"errors,omitempty"`
+	Bidder  string   `json:"bidder,omitempty"`
+	UID     string   `json:"uid,omitempty"`
+	Success bool     `json:"success"`
+}
+
+type notificationEvent struct {
+	Request   *analytics.EventRequest `json:"request,omitempty"`
+	AccountID string                  `json:"account_id,omitempty"`
+}
+
+func newAuctionEvent(ao *analytics.AuctionObject) *auctionEvent {
+	e := &auctionEvent{
+		Status:               ao.Status,
+		Errors:               errorsToStrings(ao.Errors),
+		Request:              ao.Request,
+		Response:             ao.Response,
+		StartTime:            ao.StartTime,
+		HookExecutionOutcome: ao.HookExecutionOutcome,
+	}
+	if ao.Account != nil {
+		e.AccountID = ao.Account.ID
+	}
+	return e
+}
+
+func newAmpEvent(ao *analytics.AmpObject) *ampEvent {
+	return &ampEvent{
+		Status:               ao.Status,
+		Errors:               errorsToStrings(ao.Errors),
+		Request:              ao.Request,
+		Response:             ao.AuctionResponse,
+		TargetingValues:      ao.AmpTargetingValues,
+		Origin:               ao.Origin,
+		StartTime:            ao.StartTime,
+		HookExecutionOutcome: ao.HookExecutionOutcome,
+	}
+}
+
+func newVideoEvent(vo *analytics.VideoObject) *videoEvent {
+	return &videoEvent{
+		Status:        vo.Status,
+		Errors:        errorsToStrings(vo.Errors),
+		Request:       vo.Request,
+		Response:      vo.Response,
+		VideoRequest:  vo.VideoRequest,
+		VideoResponse: vo.VideoResponse,
+		StartTime:     vo.StartTime,
+	}
+}
+
+func newCookieSyncEvent(cso *analytics.CookieSyncObject) *cookieSyncEvent {
+	return &cookieSyncEvent{
+		Status:       cso.Status,
+		Errors:       errorsToStrings(cso.Errors),
+		BidderStatus: cso.BidderStatus,
+	}
+}
+
+func newSetUIDEvent(so *analytics.SetUIDObject) *setUIDEvent {
+	return &setUIDEvent{
+		Status:  so.Status,
+		Errors:  errorsToStrings(so.Errors),
+		Bidder:  so.Bidder,
+		UID:     so.UID,
+		Success: so.Success,
+	}
+}
+
+func newNotificationEvent(ne *analytics.NotificationEvent) *notificationEvent {
+	e := &notificationEvent{Request: ne.Request}
+	if ne.Account != nil {
+		e.AccountID = ne.Account.ID
+	}
+	return e
+}
+
+type shadowEvent struct {
+	Bidder        string          `json:"bidder"`
+	AccountID     string          `json:"account_id,omitempty"`
+	Endpoint      string          `json:"endpoint"`
+	Status        int             `json:"status,omitempty"`
+	Errors        []string        `json:"errors,omitempty"`
+	Bids          []*openrtb2.Bid `json:"bids,omitempty"`
+	LatencyMillis int64           `json:"latency_ms"`
+	StartTime     time.Time       `json:"start_time"`
+}
+
+func newShadowEvent(so *analytics.ShadowObject) *shadowEvent {
+	return &shadowEvent{
+		Bidder:        so.Bidder,
+		AccountID:     so.AccountID,
+		Endpoint:      so.Endpoint,
+		Status:        so.Status,
+		Errors:        errorsToStrings(so.Errors),
+		Bids:          so.Bids,
+		LatencyMillis: so.Latency.Milliseconds(),
+		StartTime:     so.StartTime,
+	}
+}
+
+// serialize encodes the event as a single JSON line.
+func (e *event) serialize() ([]byte, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func errorsToStrings(errs []error) []string {
+	if len(errs) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	return messages
+}
@@ -0,0 +1,124 @@
+package stream
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/docker/go-units"
+	"github.com/golang/glog"
+
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/analytics/pubstack/eventchannel"
+	"github.com/prebid/prebid-server/config"
+)
+
+// StreamModule serializes every loggable object to a versioned JSON event and ships the events
+// in gzipped batches to the configured sink.
+type StreamModule struct {
+	channel *eventchannel.EventChannel
+	clock   clock.Clock
+}
+
+func NewModule(cfg config.StreamAnalytics, client *http.Client, clock clock.Clock) (analytics.PBSAnalyticsModule, error) {
+	sender, err := newSender(cfg, client, clock)
+	if err != nil {
+		return nil, err
+	}
+	return NewModuleWithSender(cfg.Buffers, sender, clock)
+}
+
+func NewModuleWithSender(buffers config.StreamBuffer, sender eventchannel.Sender, clock clock.Clock) (analytics.PBSAnalyticsModule, error) {
+	timeout, err := time.ParseDuration(buffers.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse analytics.stream.buffers.timeout: %v", err)
+	}
+	size, err := units.FromHumanSize(buffers.BufferSize)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse analytics.stream.buffers.size: %v", err)
+	}
+
+	return &StreamModule{
+		channel: eventchannel.NewEventChannel(sender, clock, size, int64(buffers.EventCount), timeout),
+		clock:   clock,
+	}, nil
+}
+
+func newSender(cfg config.StreamAnalytics, client *http.Client, clock clock.Clock) (eventchannel.Sender, error) {
+	switch cfg.Sink {
+	case config.StreamSinkFile:
+		sink, err := newFileSink(cfg.File, clock)
+		if err != nil {
+			return nil, err
+		}
+		return sink.send, nil
+	case config.StreamSinkHTTP:
+		return eventchannel.NewHttpSender(client, cfg.HTTP.Endpoint), nil
+	case config.StreamSinkKafka:
+		return newKafkaSink(cfg.Kafka).send, nil
+	}
+	return nil, fmt.Errorf("unknown analytics.stream.sink: %s", cfg.Sink)
+}
+
+func (m *StreamModule) LogAuctionObject(ao *analytics.AuctionObject) {
+	if ao == nil {
+		return
+	}
+	m.push(&event{Type: eventTypeAuction, Auction: newAuctionEvent(ao)})
+}
+
+func (m *StreamModule) LogVideoObject(vo *analytics.VideoObject) {
+	if vo == nil {
+		return
+	}
+	m.push(&event{Type: eventTypeVideo, Video: newVideoEvent(vo)})
+}
+
+func (m *StreamModule) LogCookieSyncObject(cso *analytics.CookieSyncObject) {
+	if cso == nil {
+		return
+	}
+	m.push(&event{Type: eventTypeCookieSync, CookieSync: newCookieSyncEvent(cso)})
+}
+
+func (m *StreamModule) LogSetUIDObject(so *analytics.SetUIDObject) {
+	if so == nil {
+		return
+	}
+	m.push(&event{Type: eventTypeSetUID, SetUID: newSetUIDEvent(so)})
+}
+
+func (m *StreamModule) LogAmpObject(ao *analytics.AmpObject) {
+	if ao == nil {
+		return
+	}
+	m.push(&event{Type: eventTypeAmp, Amp: newAmpEvent(ao)})
+}
+
+func (m *StreamModule) LogNotificationEventObject(ne *analytics.NotificationEvent) {
+	if ne == nil {
+		return
+	}
+	m.push(&event{Type: eventTypeNotification, Notification: newNotificationEvent(ne)})
+}
+
+func (m *StreamModule) LogShadowObject(so *analytics.ShadowObject) {
+	if so == nil {
+		return
+	}
+	m.push(&event{Type: eventTypeShadow, Shadow: newShadowEvent(so)})
+}
+
+func (m *StreamModule) push(e *event) {
+	e.SchemaVersion = SchemaVersion
+	e.Timestamp = m.clock.Now().UTC()
+
+	payload, err := e.serialize()
+	if err != nil {
+		glog.Warningf("[stream] Cannot serialize %s event: %v", e.Type, err)
+		return
+	}
+
+	m.channel.Push(payload)
+}
@@ -0,0 +1,150 @@
+package stream
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+)
+
+func gunzip(t *testing.T, payload []byte) string {
+	t.Helper()
+	reader, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("invalid gzip payload: %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("invalid gzip payload: %v", err)
+	}
+	return string(data)
+}
+
+func TestNewModuleWithSenderInvalidBuffers(t *testing.T) {
+	sender := func(payload []byte) error { return nil }
+
+	_, err := NewModuleWithSender(config.StreamBuffer{BufferSize: "1MB", EventCount: 1, Timeout: "invalid"}, sender, clock.NewMock())
+	assert.EqualError(t, err, `fail to parse analytics.stream.buffers.timeout: time: invalid duration "invalid"`)
+
+	_, err = NewModuleWithSender(config.StreamBuffer{BufferSize: "invalid", EventCount: 1, Timeout: "1s"}, sender, clock.NewMock())
+	assert.EqualError(t, err, "fail to parse analytics.stream.buffers.size: invalid size: 'invalid'")
+}
+
+func TestNewModuleUnknownSink(t *testing.T) {
+	_, err := NewModule(config.StreamAnalytics{Sink: "unknown"}, http.DefaultClient, clock.NewMock())
+	assert.EqualError(t, err, "unknown analytics.stream.sink: unknown")
+}
+
+func TestStreamModuleLogObjects(t *testing.T) {
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+	startTime := time.Date(2023, 1, 2, 3, 4, 0, 0, time.UTC)
+
+	testCases := []struct {
+		description   string
+		log           func(module analytics.PBSAnalyticsModule)
+		expectedEvent string
+	}{
+		{
+			description: "Auction",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogAuctionObject(&analytics.AuctionObject{
+					Status:    http.StatusOK,
+					Errors:    []error{errors.New("warning")},
+					Request:   &openrtb2.BidRequest{ID: "req"},
+					Response:  &openrtb2.BidResponse{ID: "resp"},
+					Account:   &config.Account{ID: "acc"},
+					StartTime: startTime,
+				})
+			},
+			expectedEvent: `{"schema_version":1,"type":"auction","timestamp":"2023-01-02T03:04:05Z","auction":{"status":200,"errors":["warning"],"account_id":"acc","request":{"id":"req","imp":null},"response":{"id":"resp"},"start_time":"2023-01-02T03:04:00Z"}}` + "\n",
+		},
+		{
+			description: "AMP",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogAmpObject(&analytics.AmpObject{Status: http.StatusOK, Origin: "origin", AmpTargetingValues: map[string]string{"hb_pb": "1.00"}, StartTime: startTime})
+			},
+			expectedEvent: `{"schema_version":1,"type":"amp","timestamp":"2023-01-02T03:04:05Z","amp":{"status":200,"targeting_values":{"hb_pb":"1.00"},"origin":"origin","start_time":"2023-01-02T03:04:00Z"}}` + "\n",
+		},
+		{
+			description: "Video",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogVideoObject(&analytics.VideoObject{Status: http.StatusBadRequest, StartTime: startTime})
+			},
+			expectedEvent: `{"schema_version":1,"type":"video","timestamp":"2023-01-02T03:04:05Z","video":{"status":400,"start_time":"2023-01-02T03:04:00Z"}}` + "\n",
+		},
+		{
+			description: "Cookie sync",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogCookieSyncObject(&analytics.CookieSyncObject{Status: http.StatusOK, BidderStatus: []*analytics.CookieSyncBidder{{BidderCode: "appnexus", NoCookie: true}}})
+			},
+			expectedEvent: `{"schema_version":1,"type":"cookie_sync","timestamp":"2023-01-02T03:04:05Z","cookie_sync":{"status":200,"bidder_status":[{"bidder":"appnexus","no_cookie":true}]}}` + "\n",
+		},
+		{
+			description: "SetUID",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogSetUIDObject(&analytics.SetUIDObject{Status: http.StatusOK, Bidder: "appnexus", UID: "uid", Success: true})
+			},
+			expectedEvent: `{"schema_version":1,"type":"setuid","timestamp":"2023-01-02T03:04:05Z","setuid":{"status":200,"bidder":"appnexus","uid":"uid","success":true}}` + "\n",
+		},
+		{
+			description: "Notification",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.LogNotificationEventObject(&analytics.NotificationEvent{
+					Request: &analytics.EventRequest{Type: analytics.Win, BidID: "bid"},
+					Account: &config.Account{ID: "acc"},
+				})
+			},
+			expectedEvent: `{"schema_version":1,"type":"notification","timestamp":"2023-01-02T03:04:05Z","notification":{"request":{"type":"win","bidid":"bid"},"account_id":"acc"}}` + "\n",
+		},
+		{
+			description: "Shadow",
+			log: func(module analytics.PBSAnalyticsModule) {
+				module.(analytics.ShadowLogger).LogShadowObject(&analytics.ShadowObject{
+					Bidder:    "appnexus",
+					AccountID: "acc",
+					Endpoint:  "https://candidate.appnexus.com/bid",
+					Status:    http.StatusOK,
+					Bids:      []*openrtb2.Bid{{ID: "bid", ImpID: "imp", Price: 1.5}},
+					Latency:   120 * time.Millisecond,
+					StartTime: mockClock.Now(),
+				})
+			},
+			expectedEvent: `{"schema_version":1,"type":"shadow","timestamp":"2023-01-02T03:04:05Z","shadow":{"bidder":"appnexus","account_id":"acc","endpoint":"https://candidate.appnexus.com/bid","status":200,"bids":[{"id":"bid","impid":"imp","price":1.5}],"latency_ms":120,"start_time":"2023-01-02T03:04:05Z"}}` + "\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			payloads := make(chan []byte, 1)
+			sender := func(payload []byte) error {
+				payloads <- payload
+				return nil
+			}
+
+			module, err := NewModuleWithSender(config.StreamBuffer{BufferSize: "1MB", EventCount: 1, Timeout: "1h"}, sender, mockClock)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			test.log(module)
+
+			select {
+			case payload := <-payloads:
+				assert.Equal(t, test.expectedEvent, gunzip(t, payload))
+			case <-time.After(time.Second):
+				t.Fatal("event was not sent")
+			}
+		})
+	}
+}
@@ -31,11 +31,141 @@ type Account struct {
 	DebugAllow              bool                                 `mapstructure:"debug_allow" json:"debug_allow"`
 	DefaultIntegration      string                               `mapstructure:"default_integration" json:"default_integration"`
 	CookieSync              CookieSync                           `mapstructure:"cookie_sync" json:"cookie_sync"`
-	Events                  Events                               `mapstructure:"events" json:"events"` // Don't enable this feature. It is still under developmment - https://github.com/prebid/prebid-server/issues/1725
+	Events                  Events                               `mapstructure:"events" json:"events"`
 	TruncateTargetAttribute *int                                 `mapstructure:"truncate_target_attr" json:"truncate_target_attr"`
 	AlternateBidderCodes    *openrtb_ext.ExtAlternateBidderCodes `mapstructure:"alternatebiddercodes" json:"alternatebiddercodes"`
 	Hooks                   AccountHooks                         `mapstructure:"hooks" json:"hooks"`
 	Validations             Validations                          `mapstructure:"validations" json:"validations"`
+	RateLimit               AccountRateLimit                     `mapstructure:"rate_limit" json:"rate_limit"`
+	Shadow                  AccountShadow                        `mapstructure:"shadow" json:"shadow"`
+	RequestPolicy           AccountRequestPolicy                 `mapstructure:"request_policy" json:"request_policy"`
+}
+
+// AccountRateLimit limits the auction requests of an account with a token bucket, so a traffic spike
+// of one account doesn't starve the others.
+type AccountRateLimit struct {
+	// RequestsPerSecond is the sustained rate of requests accepted. 0 disables the rate limit.
+	RequestsPerSecond float64 `mapstructure:"requests_per_second" json:"requests_per_second"`
+	// Burst is the number of requests accepted at once after the account has been idle. It defaults
+	// to one second of requests.
+	Burst int `mapstructure:"burst" json:"burst"`
+}
+
+func (rl AccountRateLimit) validate(errs []error) []error {
+	if rl.RequestsPerSecond < 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.rate_limit.requests_per_second must be >= 0. Got %f", rl.RequestsPerSecond))
+	}
+	if rl.Burst < 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.rate_limit.burst must be >= 0. Got %d", rl.Burst))
+	}
+	return errs
+}
+
+// AccountShadow overrides, per bidder, the percentage of the requests mirrored to the shadow endpoint
+// declared in the bidder info.
+type AccountShadow struct {
+	// Percent maps a bidder name to the percentage of its requests mirrored, from 0 to 100. 0 disables
+	// the shadow traffic of the bidder for the account.
+	Percent map[string]float64 `mapstructure:"percent" json:"percent"`
+}
+
+func (s AccountShadow) validate(errs []error) []error {
+	for bidder, percent := range s.Percent {
+		if percent < 0 || percent > 100 {
+			errs = append(errs, fmt.Errorf("account_defaults.shadow.percent.%s must be in the range [0, 100]. Got %g", bidder, percent))
+		}
+	}
+	return errs
+}
+
+// The actions of the rules of an account request policy. A rule without an action is off.
+const (
+	RequestPolicyReject = "reject"
+	RequestPolicyWarn   = "warn"
+	RequestPolicyFix    = "fix"
+)
+
+// AccountRequestPolicy validates the auction requests of an account beyond the OpenRTB rules. Each rule
+// rejects the requests breaking it, warns about them in ext.warnings, or fixes them. The rules on the
+// required fields and the user agents can't fix a request.
+type AccountRequestPolicy struct {
+	// SitePage requires site.page in the site requests.
+	SitePage RequestPolicyRule `mapstructure:"site_page" json:"site_page"`
+	// AppBundle requires app.bundle in the app requests.
+	AppBundle RequestPolicyRule `mapstructure:"app_bundle" json:"app_bundle"`
+	// DeviceIP requires device.ip or device.ipv6.
+	DeviceIP RequestPolicyRule `mapstructure:"device_ip" json:"device_ip"`
+	// MediaTypes allows the imps to request only the listed media types. Fixing a request drops the other
+	// media types, and the imps left without any.
+	MediaTypes RequestPolicyMediaTypes `mapstructure:"media_types" json:"media_types"`
+	// MaxImps limits the number of imps. Fixing a request keeps the first ones.
+	MaxImps RequestPolicyLimit `mapstructure:"max_imps" json:"max_imps"`
+	// MaxTmax limits tmax, in milliseconds. Fixing a request lowers tmax to the limit.
+	MaxTmax RequestPolicyLimit `mapstructure:"max_tmax" json:"max_tmax"`
+	// UserAgents blocks the requests whose device.ua contains one of the listed strings, ignoring case.
+	UserAgents RequestPolicyUserAgents `mapstructure:"user_agents" json:"user_agents"`
+}
+
+// RequestPolicyRule is a rule of an account request policy without settings.
+type RequestPolicyRule struct {
+	Action string `mapstructure:"action" json:"action"`
+}
+
+// RequestPolicyMediaTypes is the rule of an account request policy on the media types of the imps.
+type RequestPolicyMediaTypes struct {
+	Action string `mapstructure:"action" json:"action"`
+	// Allowed lists the media types allowed: banner, video, audio or native.
+	Allowed []string `mapstructure:"allowed" json:"allowed"`
+}
+
+// RequestPolicyLimit is a rule of an account request policy limiting a value of the request.
+type RequestPolicyLimit struct {
+	Action string `mapstructure:"action" json:"action"`
+	Max    int64  `mapstructure:"max" json:"max"`
+}
+
+// RequestPolicyUserAgents is the rule of an account request policy blocking user agents, like the bots.
+type RequestPolicyUserAgents struct {
+	Action  string   `mapstructure:"action" json:"action"`
+	Blocked []string `mapstructure:"blocked" json:"blocked"`
+}
+
+func (p AccountRequestPolicy) validate(errs []error) []error {
+	errs = validateRequestPolicyAction("site_page", p.SitePage.Action, false, errs)
+	errs = validateRequestPolicyAction("app_bundle", p.AppBundle.Action, false, errs)
+	errs = validateRequestPolicyAction("device_ip", p.DeviceIP.Action, false, errs)
+	errs = validateRequestPolicyAction("media_types", p.MediaTypes.Action, true, errs)
+	errs = validateRequestPolicyAction("max_imps", p.MaxImps.Action, true, errs)
+	errs = validateRequestPolicyAction("max_tmax", p.MaxTmax.Action, true, errs)
+	errs = validateRequestPolicyAction("user_agents", p.UserAgents.Action, false, errs)
+
+	for _, mediaType := range p.MediaTypes.Allowed {
+		switch mediaType {
+		case "banner", "video", "audio", "native":
+		default:
+			errs = append(errs, fmt.Errorf("account_defaults.request_policy.media_types.allowed has unknown media type %s. Must be one of banner, video, audio or native", mediaType))
+		}
+	}
+	if p.MaxImps.Action != "" && p.MaxImps.Max <= 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.request_policy.max_imps.max must be > 0. Got %d", p.MaxImps.Max))
+	}
+	if p.MaxTmax.Action != "" && p.MaxTmax.Max <= 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.request_policy.max_tmax.max must be > 0. Got %d", p.MaxTmax.Max))
+	}
+	return errs
+}
+
+func validateRequestPolicyAction(rule string, action string, canFix bool, errs []error) []error {
+	switch action {
+	case "", RequestPolicyReject, RequestPolicyWarn:
+	case RequestPolicyFix:
+		if !canFix {
+			errs = append(errs, fmt.Errorf("account_defaults.request_policy.%s.action must be %s or %s. Got %s", rule, RequestPolicyReject, RequestPolicyWarn, action))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("account_defaults.request_policy.%s.action must be one of %s, %s or %s. Got %s", rule, RequestPolicyReject, RequestPolicyWarn, RequestPolicyFix, action))
+	}
+	return errs
 }
 
 // CookieSync represents the account-level defaults for the cookie sync endpoint.
@@ -43,6 +173,22 @@ type CookieSync struct {
 	DefaultLimit    *int  `mapstructure:"default_limit" json:"default_limit"`
 	MaxLimit        *int  `mapstructure:"max_limit" json:"max_limit"`
 	DefaultCoopSync *bool `mapstructure:"default_coop_sync" json:"default_coop_sync"`
+	// Strategy selects how the bidders to sync are chosen: "standard" (random) or "prioritized".
+	Strategy string `mapstructure:"strategy" json:"strategy"`
+}
+
+const (
+	CookieSyncStrategyStandard    = "standard"
+	CookieSyncStrategyPrioritized = "prioritized"
+)
+
+func (cs CookieSync) validate(errs []error) []error {
+	switch cs.Strategy {
+	case "", CookieSyncStrategyStandard, CookieSyncStrategyPrioritized:
+	default:
+		errs = append(errs, fmt.Errorf("account_defaults.cookie_sync.strategy must be standard or prioritized. Got %s", cs.Strategy))
+	}
+	return errs
 }
 
 // AccountCCPA represents account-specific CCPA configuration
@@ -824,3 +824,148 @@ func TestModulesGetConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestCookieSyncValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      CookieSync
+		expErrors int
+	}{
+		{
+			desc:      "Default strategy",
+			data:      CookieSync{},
+			expErrors: 0,
+		},
+		{
+			desc:      "Standard strategy",
+			data:      CookieSync{Strategy: CookieSyncStrategyStandard},
+			expErrors: 0,
+		},
+		{
+			desc:      "Prioritized strategy",
+			data:      CookieSync{Strategy: CookieSyncStrategyPrioritized},
+			expErrors: 0,
+		},
+		{
+			desc:      "Unknown strategy",
+			data:      CookieSync{Strategy: "fastest"},
+			expErrors: 1,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
+
+func TestAccountRateLimitValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      AccountRateLimit
+		expErrors int
+	}{
+		{
+			desc:      "No limit",
+			data:      AccountRateLimit{},
+			expErrors: 0,
+		},
+		{
+			desc:      "Rate and burst",
+			data:      AccountRateLimit{RequestsPerSecond: 0.5, Burst: 10},
+			expErrors: 0,
+		},
+		{
+			desc:      "Negative rate and burst",
+			data:      AccountRateLimit{RequestsPerSecond: -1, Burst: -1},
+			expErrors: 2,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
+
+func TestAccountShadowValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      AccountShadow
+		expErrors int
+	}{
+		{
+			desc:      "No override",
+			data:      AccountShadow{},
+			expErrors: 0,
+		},
+		{
+			desc:      "Valid percentages",
+			data:      AccountShadow{Percent: map[string]float64{"appnexus": 0, "rubicon": 12.5, "pubmatic": 100}},
+			expErrors: 0,
+		},
+		{
+			desc:      "Out of range percentages",
+			data:      AccountShadow{Percent: map[string]float64{"appnexus": -1, "rubicon": 101}},
+			expErrors: 2,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
+
+func TestAccountRequestPolicyValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      AccountRequestPolicy
+		expErrors int
+	}{
+		{
+			desc:      "No rules",
+			data:      AccountRequestPolicy{},
+			expErrors: 0,
+		},
+		{
+			desc: "Valid rules",
+			data: AccountRequestPolicy{
+				SitePage:   RequestPolicyRule{Action: RequestPolicyReject},
+				DeviceIP:   RequestPolicyRule{Action: RequestPolicyWarn},
+				MediaTypes: RequestPolicyMediaTypes{Action: RequestPolicyFix, Allowed: []string{"banner", "video"}},
+				MaxImps:    RequestPolicyLimit{Action: RequestPolicyFix, Max: 10},
+				MaxTmax:    RequestPolicyLimit{Action: RequestPolicyWarn, Max: 1000},
+				UserAgents: RequestPolicyUserAgents{Action: RequestPolicyReject, Blocked: []string{"bot"}},
+			},
+			expErrors: 0,
+		},
+		{
+			desc:      "Unknown action",
+			data:      AccountRequestPolicy{AppBundle: RequestPolicyRule{Action: "drop"}},
+			expErrors: 1,
+		},
+		{
+			desc: "Fix on rules which can't fix a request",
+			data: AccountRequestPolicy{
+				SitePage:   RequestPolicyRule{Action: RequestPolicyFix},
+				UserAgents: RequestPolicyUserAgents{Action: RequestPolicyFix, Blocked: []string{"bot"}},
+			},
+			expErrors: 2,
+		},
+		{
+			desc:      "Unknown media type",
+			data:      AccountRequestPolicy{MediaTypes: RequestPolicyMediaTypes{Action: RequestPolicyReject, Allowed: []string{"banner", "audio", "display"}}},
+			expErrors: 1,
+		},
+		{
+			desc: "Limits not set",
+			data: AccountRequestPolicy{
+				MaxImps: RequestPolicyLimit{Action: RequestPolicyReject},
+				MaxTmax: RequestPolicyLimit{Action: RequestPolicyFix, Max: -1},
+			},
+			expErrors: 2,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/macros"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/util/compressutil"
 	"github.com/prebid/prebid-server/util/sliceutil"
 
 	validator "github.com/asaskevich/govalidator"
@@ -31,6 +33,7 @@ type BidderInfo struct {
 	Capabilities            *CapabilitiesInfo `yaml:"capabilities" mapstructure:"capabilities"`
 	ModifyingVastXmlAllowed bool              `yaml:"modifyingVastXmlAllowed" mapstructure:"modifyingVastXmlAllowed"`
 	Debug                   *DebugInfo        `yaml:"debug" mapstructure:"debug"`
+	OpenRTB                 *OpenRTBInfo      `yaml:"openrtb" mapstructure:"openrtb"`
 	GVLVendorID             uint16            `yaml:"gvlVendorID" mapstructure:"gvlVendorID"`
 
 	Syncer *Syncer `yaml:"userSync" mapstructure:"userSync"`
@@ -48,6 +51,66 @@ type BidderInfo struct {
 	AppSecret  string `yaml:"app_secret" mapstructure:"app_secret"`
 	// EndpointCompression determines, if set, the type of compression the bid request will undergo before being sent to the corresponding bid server
 	EndpointCompression string `yaml:"endpointCompression" mapstructure:"endpointCompression"`
+	// Transport declares, if set, a dedicated HTTP transport for the requests to the bidder instead of the
+	// one shared by all the bidders
+	Transport *TransportProfile `yaml:"transport" mapstructure:"transport"`
+	// Shadow mirrors, if set, a percentage of the requests to the bidder to a candidate endpoint. The
+	// responses of the shadow endpoint are measured but never take part in the auction.
+	Shadow *ShadowInfo `yaml:"shadow" mapstructure:"shadow"`
+}
+
+// ShadowInfo specifies the candidate endpoint a bidder's requests are mirrored to.
+type ShadowInfo struct {
+	// Endpoint is the absolute URL the mirrored requests are sent to.
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+	// Percent is the percentage of the requests to the bidder which are mirrored, from 0 to 100. Accounts
+	// may override it.
+	Percent float64 `yaml:"percent" mapstructure:"percent"`
+}
+
+// DefaultTransportProfile is the name of the HTTP transport shared by the bidders which don't declare one.
+const DefaultTransportProfile = "default"
+
+// TransportProfile specifies a dedicated HTTP transport for a bidder. The settings left empty are the
+// ones of the shared transport, from the http_client config.
+type TransportProfile struct {
+	// Name labels the connection metrics of the bidder. It defaults to the bidder name.
+	Name string `yaml:"name" mapstructure:"name"`
+	// HTTP2 enables HTTP/2 for the bidder endpoints supporting it over TLS.
+	HTTP2                  bool `yaml:"http2" mapstructure:"http2"`
+	MaxConnsPerHost        int  `yaml:"maxConnectionsPerHost" mapstructure:"maxConnectionsPerHost"`
+	MaxIdleConns           int  `yaml:"maxIdleConnections" mapstructure:"maxIdleConnections"`
+	MaxIdleConnsPerHost    int  `yaml:"maxIdleConnectionsPerHost" mapstructure:"maxIdleConnectionsPerHost"`
+	IdleConnTimeoutSeconds int  `yaml:"idleConnectionTimeoutSeconds" mapstructure:"idleConnectionTimeoutSeconds"`
+	// KeepAliveSeconds is the interval of the TCP keep-alive probes.
+	KeepAliveSeconds int `yaml:"keepAliveSeconds" mapstructure:"keepAliveSeconds"`
+	// DisableKeepAlives opens a new connection for each request.
+	DisableKeepAlives bool         `yaml:"disableKeepAlives" mapstructure:"disableKeepAlives"`
+	TLS               TransportTLS `yaml:"tls" mapstructure:"tls"`
+}
+
+// TransportTLS specifies the TLS settings of a dedicated HTTP transport.
+type TransportTLS struct {
+	// MinVersion is the minimum TLS version, either "1.2" or "1.3".
+	MinVersion              string `yaml:"minVersion" mapstructure:"minVersion"`
+	HandshakeTimeoutSeconds int    `yaml:"handshakeTimeoutSeconds" mapstructure:"handshakeTimeoutSeconds"`
+}
+
+// TLS versions a transport profile may require
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// OpenRTB versions a bidder may declare support for
+const (
+	OpenRTBVersion25 = "2.5"
+	OpenRTBVersion26 = "2.6"
+)
+
+// SupportsOpenRTB26 returns true if the bidder expects requests in the OpenRTB 2.6 layout
+func (bi BidderInfo) SupportsOpenRTB26() bool {
+	return bi.OpenRTB != nil && bi.OpenRTB.Version == OpenRTBVersion26
 }
 
 // BidderInfoExperiment specifies non-production ready feature config for a bidder
@@ -76,6 +139,16 @@ type PlatformInfo struct {
 	MediaTypes []openrtb_ext.BidType `yaml:"mediaTypes" mapstructure:"mediaTypes"`
 }
 
+// OpenRTBInfo specifies the OpenRTB features supported by a bidder.
+type OpenRTBInfo struct {
+	// Version is the OpenRTB version of the requests sent to the bidder, either "2.5" or "2.6". Requests
+	// are down converted to the 2.5 ext locations when empty.
+	Version string `yaml:"version" mapstructure:"version"`
+	// DynamicPodSupported is true when the bidder fills a dynamic video pod (imp.video.poddur) from a
+	// single imp. Otherwise dynamic pods are expanded into one imp per slot before calling the bidder.
+	DynamicPodSupported bool `yaml:"dynamicPodSupported" mapstructure:"dynamicPodSupported"`
+}
+
 // DebugInfo specifies the supported debug options for a bidder.
 type DebugInfo struct {
 	Allow bool `yaml:"allow" mapstructure:"allow"`
@@ -140,9 +213,17 @@ type SyncerEndpoint struct {
 	// The following macros are specific to individual requests and are resolved at runtime using the
 	// Go template engine. For more information on Go templates, see: https://golang.org/pkg/text/template/
 	//
-	//  {{.GDPR}}        - This will be replaced with the "gdpr" property sent to /cookie_sync.
-	//  {{.Consent}}     - This will be replaced with the "consent" property sent to /cookie_sync.
-	//  {{.USPrivacy}}   - This will be replaced with the "us_privacy" property sent to /cookie_sync.
+	//  {{.GDPR}}         - This will be replaced with the "gdpr" property sent to /cookie_sync.
+	//  {{.Consent}}      - This will be replaced with the "consent" property sent to /cookie_sync.
+	//  {{.USPrivacy}}    - This will be replaced with the "us_privacy" property sent to /cookie_sync.
+	//  {{.GPP}}          - This will be replaced with the "gpp" property sent to /cookie_sync.
+	//  {{.GPPSID}}       - This will be replaced with the "gpp_sid" property sent to /cookie_sync, a comma
+	//                      separated list of the applicable GPP section ids.
+	//  {{.HostCookieID}} - This will be replaced with the user id of the host cookie, if any.
+	//  {{.AccountID}}    - This will be replaced with the "account" property sent to /cookie_sync.
+	//
+	// The GPP, HostCookieID and AccountID values are url escaped. Macros not listed here fail the
+	// validation of the bidder info at startup.
 	URL string `yaml:"url" mapstructure:"url"`
 
 	// RedirectURL is an endpoint on the host server the user will be redirected to when a user sync
@@ -317,6 +398,18 @@ func validateInfo(info BidderInfo, bidderName string) error {
 	if err := validateCapabilities(info.Capabilities, bidderName); err != nil {
 		return err
 	}
+	if err := validateOpenRTB(info.OpenRTB, bidderName); err != nil {
+		return err
+	}
+	if err := validateTransport(info.Transport, bidderName); err != nil {
+		return err
+	}
+	if err := validateEndpointCompression(info.EndpointCompression, bidderName); err != nil {
+		return err
+	}
+	if err := validateShadow(info.Shadow, bidderName); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -351,6 +444,50 @@ func validateCapabilities(info *CapabilitiesInfo, bidderName string) error {
 	return nil
 }
 
+func validateOpenRTB(info *OpenRTBInfo, bidderName string) error {
+	if info == nil || info.Version == "" {
+		return nil
+	}
+	if info.Version != OpenRTBVersion25 && info.Version != OpenRTBVersion26 {
+		return fmt.Errorf("openrtb.version must be either %s or %s for adapter: %s", OpenRTBVersion25, OpenRTBVersion26, bidderName)
+	}
+	return nil
+}
+
+func validateEndpointCompression(compression string, bidderName string) error {
+	if compression == "" || sliceutil.ContainsStringIgnoreCase(compressutil.Encodings(), compression) {
+		return nil
+	}
+	return fmt.Errorf("endpointCompression must be one of GZIP, BR or ZSTD for adapter: %s", bidderName)
+}
+
+func validateTransport(info *TransportProfile, bidderName string) error {
+	if info == nil {
+		return nil
+	}
+	if info.MaxConnsPerHost < 0 || info.MaxIdleConns < 0 || info.MaxIdleConnsPerHost < 0 || info.IdleConnTimeoutSeconds < 0 ||
+		info.KeepAliveSeconds < 0 || info.TLS.HandshakeTimeoutSeconds < 0 {
+		return fmt.Errorf("transport limits and timeouts must be >= 0 for adapter: %s", bidderName)
+	}
+	if info.TLS.MinVersion != "" && info.TLS.MinVersion != TLSVersion12 && info.TLS.MinVersion != TLSVersion13 {
+		return fmt.Errorf("transport.tls.minVersion must be either %s or %s for adapter: %s", TLSVersion12, TLSVersion13, bidderName)
+	}
+	return nil
+}
+
+func validateShadow(info *ShadowInfo, bidderName string) error {
+	if info == nil {
+		return nil
+	}
+	if endpoint, err := url.Parse(info.Endpoint); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("shadow.endpoint must be an absolute http or https URL for adapter: %s", bidderName)
+	}
+	if info.Percent < 0 || info.Percent > 100 {
+		return fmt.Errorf("shadow.percent must be in the range [0, 100] for adapter: %s", bidderName)
+	}
+	return nil
+}
+
 func validatePlatformInfo(info *PlatformInfo) error {
 	if len(info.MediaTypes) == 0 {
 		return errors.New("at least one media type needs to be specified")
@@ -403,6 +540,9 @@ func applyBidderInfoConfigOverrides(configBidderInfos BidderInfos, fsBidderInfos
 			if bidderInfo.Debug == nil && fsBidderCfg.Debug != nil {
 				bidderInfo.Debug = fsBidderCfg.Debug
 			}
+			if bidderInfo.OpenRTB == nil && fsBidderCfg.OpenRTB != nil {
+				bidderInfo.OpenRTB = fsBidderCfg.OpenRTB
+			}
 			if bidderInfo.GVLVendorID == 0 && fsBidderCfg.GVLVendorID > 0 {
 				bidderInfo.GVLVendorID = fsBidderCfg.GVLVendorID
 			}
@@ -424,6 +564,12 @@ func applyBidderInfoConfigOverrides(configBidderInfos BidderInfos, fsBidderInfos
 			if bidderInfo.EndpointCompression == "" && fsBidderCfg.EndpointCompression != "" {
 				bidderInfo.EndpointCompression = fsBidderCfg.EndpointCompression
 			}
+			if bidderInfo.Transport == nil && fsBidderCfg.Transport != nil {
+				bidderInfo.Transport = fsBidderCfg.Transport
+			}
+			if bidderInfo.Shadow == nil && fsBidderCfg.Shadow != nil {
+				bidderInfo.Shadow = fsBidderCfg.Shadow
+			}
 
 			// validate and try to apply the legacy usersync_url configuration in attempt to provide
 			// an easier upgrade path. be warned, this will break if the bidder adds a second syncer
@@ -272,6 +272,151 @@ func TestBidderInfoValidationNegative(t *testing.T) {
 				errors.New("The endpoint: incorrect for bidderA is not a valid URL"),
 			},
 		},
+		{
+			"One bidder unknown openrtb version",
+			BidderInfos{
+				"bidderA": BidderInfo{
+					Endpoint: "http://bidderA.com/openrtb2",
+					Maintainer: &MaintainerInfo{
+						Email: "maintainer@bidderA.com",
+					},
+					Capabilities: &CapabilitiesInfo{
+						App: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeVideo,
+							},
+						},
+					},
+					OpenRTB: &OpenRTBInfo{
+						Version: "3.0",
+					},
+				},
+			},
+			[]error{
+				errors.New("openrtb.version must be either 2.5 or 2.6 for adapter: bidderA"),
+			},
+		},
+		{
+			"One bidder unknown transport tls version",
+			BidderInfos{
+				"bidderA": BidderInfo{
+					Endpoint: "http://bidderA.com/openrtb2",
+					Maintainer: &MaintainerInfo{
+						Email: "maintainer@bidderA.com",
+					},
+					Capabilities: &CapabilitiesInfo{
+						App: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeVideo,
+							},
+						},
+					},
+					Transport: &TransportProfile{
+						HTTP2: true,
+						TLS:   TransportTLS{MinVersion: "1.1"},
+					},
+				},
+			},
+			[]error{
+				errors.New("transport.tls.minVersion must be either 1.2 or 1.3 for adapter: bidderA"),
+			},
+		},
+		{
+			"One bidder negative transport limit",
+			BidderInfos{
+				"bidderA": BidderInfo{
+					Endpoint: "http://bidderA.com/openrtb2",
+					Maintainer: &MaintainerInfo{
+						Email: "maintainer@bidderA.com",
+					},
+					Capabilities: &CapabilitiesInfo{
+						App: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeVideo,
+							},
+						},
+					},
+					Transport: &TransportProfile{
+						MaxConnsPerHost: -1,
+					},
+				},
+			},
+			[]error{
+				errors.New("transport limits and timeouts must be >= 0 for adapter: bidderA"),
+			},
+		},
+		{
+			"One bidder unknown endpoint compression",
+			BidderInfos{
+				"bidderA": BidderInfo{
+					Endpoint: "http://bidderA.com/openrtb2",
+					Maintainer: &MaintainerInfo{
+						Email: "maintainer@bidderA.com",
+					},
+					Capabilities: &CapabilitiesInfo{
+						App: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeVideo,
+							},
+						},
+					},
+					EndpointCompression: "LZ77",
+				},
+			},
+			[]error{
+				errors.New("endpointCompression must be one of GZIP, BR or ZSTD for adapter: bidderA"),
+			},
+		},
+		{
+			"One bidder invalid shadow endpoint",
+			BidderInfos{
+				"bidderA": BidderInfo{
+					Endpoint: "http://bidderA.com/openrtb2",
+					Maintainer: &MaintainerInfo{
+						Email: "maintainer@bidderA.com",
+					},
+					Capabilities: &CapabilitiesInfo{
+						App: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeVideo,
+							},
+						},
+					},
+					Shadow: &ShadowInfo{
+						Endpoint: "shadow.bidderA.com/openrtb2",
+						Percent:  10,
+					},
+				},
+			},
+			[]error{
+				errors.New("shadow.endpoint must be an absolute http or https URL for adapter: bidderA"),
+			},
+		},
+		{
+			"One bidder shadow percent out of range",
+			BidderInfos{
+				"bidderA": BidderInfo{
+					Endpoint: "http://bidderA.com/openrtb2",
+					Maintainer: &MaintainerInfo{
+						Email: "maintainer@bidderA.com",
+					},
+					Capabilities: &CapabilitiesInfo{
+						App: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeVideo,
+							},
+						},
+					},
+					Shadow: &ShadowInfo{
+						Endpoint: "https://shadow.bidderA.com/openrtb2",
+						Percent:  150,
+					},
+				},
+			},
+			[]error{
+				errors.New("shadow.percent must be in the range [0, 100] for adapter: bidderA"),
+			},
+		},
 		{
 			"One bidder empty url",
 			BidderInfos{
@@ -857,6 +1002,18 @@ func TestApplyBidderInfoConfigOverrides(t *testing.T) {
 			givenConfigBidderInfos: BidderInfos{"a": {Debug: &DebugInfo{Allow: false}, Syncer: &Syncer{Key: "override"}}},
 			expectedBidderInfos:    BidderInfos{"a": {Debug: &DebugInfo{Allow: false}, Syncer: &Syncer{Key: "override"}}},
 		},
+		{
+			description:            "Don't override OpenRTB",
+			givenFsBidderInfos:     BidderInfos{"a": {OpenRTB: &OpenRTBInfo{DynamicPodSupported: true}}},
+			givenConfigBidderInfos: BidderInfos{"a": {Syncer: &Syncer{Key: "override"}}},
+			expectedBidderInfos:    BidderInfos{"a": {OpenRTB: &OpenRTBInfo{DynamicPodSupported: true}, Syncer: &Syncer{Key: "override"}}},
+		},
+		{
+			description:            "Override OpenRTB",
+			givenFsBidderInfos:     BidderInfos{"a": {OpenRTB: &OpenRTBInfo{DynamicPodSupported: true}}},
+			givenConfigBidderInfos: BidderInfos{"a": {OpenRTB: &OpenRTBInfo{DynamicPodSupported: false}, Syncer: &Syncer{Key: "override"}}},
+			expectedBidderInfos:    BidderInfos{"a": {OpenRTB: &OpenRTBInfo{DynamicPodSupported: false}, Syncer: &Syncer{Key: "override"}}},
+		},
 		{
 			description:            "Don't override GVLVendorID",
 			givenFsBidderInfos:     BidderInfos{"a": {GVLVendorID: 5}},
@@ -926,6 +1083,30 @@ func TestApplyBidderInfoConfigOverrides(t *testing.T) {
 			givenConfigBidderInfos: BidderInfos{"a": {EndpointCompression: "LZ77", Syncer: &Syncer{Key: "override"}}},
 			expectedBidderInfos:    BidderInfos{"a": {EndpointCompression: "LZ77", Syncer: &Syncer{Key: "override"}}},
 		},
+		{
+			description:            "Don't override Transport",
+			givenFsBidderInfos:     BidderInfos{"a": {Transport: &TransportProfile{HTTP2: true}}},
+			givenConfigBidderInfos: BidderInfos{"a": {Syncer: &Syncer{Key: "override"}}},
+			expectedBidderInfos:    BidderInfos{"a": {Transport: &TransportProfile{HTTP2: true}, Syncer: &Syncer{Key: "override"}}},
+		},
+		{
+			description:            "Override Transport",
+			givenFsBidderInfos:     BidderInfos{"a": {Transport: &TransportProfile{HTTP2: true}}},
+			givenConfigBidderInfos: BidderInfos{"a": {Transport: &TransportProfile{MaxConnsPerHost: 100}, Syncer: &Syncer{Key: "override"}}},
+			expectedBidderInfos:    BidderInfos{"a": {Transport: &TransportProfile{MaxConnsPerHost: 100}, Syncer: &Syncer{Key: "override"}}},
+		},
+		{
+			description:            "Don't override Shadow",
+			givenFsBidderInfos:     BidderInfos{"a": {Shadow: &ShadowInfo{Endpoint: "http://shadow.com", Percent: 10}}},
+			givenConfigBidderInfos: BidderInfos{"a": {Syncer: &Syncer{Key: "override"}}},
+			expectedBidderInfos:    BidderInfos{"a": {Shadow: &ShadowInfo{Endpoint: "http://shadow.com", Percent: 10}, Syncer: &Syncer{Key: "override"}}},
+		},
+		{
+			description:            "Override Shadow",
+			givenFsBidderInfos:     BidderInfos{"a": {Shadow: &ShadowInfo{Endpoint: "http://shadow.com", Percent: 10}}},
+			givenConfigBidderInfos: BidderInfos{"a": {Shadow: &ShadowInfo{Endpoint: "http://other-shadow.com", Percent: 5}, Syncer: &Syncer{Key: "override"}}},
+			expectedBidderInfos:    BidderInfos{"a": {Shadow: &ShadowInfo{Endpoint: "http://other-shadow.com", Percent: 5}, Syncer: &Syncer{Key: "override"}}},
+		},
 	}
 	for _, test := range testCases {
 		bidderInfos, resultErr := applyBidderInfoConfigOverrides(test.givenConfigBidderInfos, test.givenFsBidderInfos, mockNormalizeBidderName)
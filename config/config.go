@@ -1,11 +1,13 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +18,10 @@ import (
 
 	"github.com/prebid/go-gdpr/consentconstants"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/util/compressutil"
+	"github.com/prebid/prebid-server/util/sliceutil"
 )
 
 // Configuration specifies the static application config.
@@ -29,7 +34,12 @@ type Configuration struct {
 	Client           HTTPClient `mapstructure:"http_client"`
 	CacheClient      HTTPClient `mapstructure:"http_client_cache"`
 	AdminPort        int        `mapstructure:"admin_port"`
+	AdminAPI         AdminAPI   `mapstructure:"admin_api"`
 	EnableGzip       bool       `mapstructure:"enable_gzip"`
+	// Compression sets the content encodings of the request bodies and the responses
+	Compression Compression `mapstructure:"compression"`
+	// Logging sets the format, level and sampling of the request logs
+	Logging Logging `mapstructure:"logging"`
 	// GarbageCollectorThreshold allocates virtual memory (in bytes) which is not used by PBS but
 	// serves as a hack to trigger the garbage collector only when the heap reaches at least this size.
 	// More info: https://github.com/golang/go/issues/48409
@@ -82,6 +92,10 @@ type Configuration struct {
 	PemCertsFile string `mapstructure:"certificates_file"`
 	// Custom headers to handle request timeouts from queueing infrastructure
 	RequestTimeoutHeaders RequestTimeoutHeaders `mapstructure:"request_timeout_headers"`
+	// LoadShedding protects the server when too many auctions run at once or they become too slow
+	LoadShedding LoadShedding `mapstructure:"load_shedding"`
+	// TmaxAdjustments derives the timeout of each bidder from the request tmax and the recent latency of the bidder
+	TmaxAdjustments TmaxAdjustments `mapstructure:"tmax_adjustments"`
 	// Debug/logging flags go here
 	Debug Debug `mapstructure:"debug"`
 	// RequestValidation specifies the request validation options.
@@ -106,6 +120,30 @@ type Configuration struct {
 
 const MIN_COOKIE_SIZE_BYTES = 500
 
+// AdminAPI configures the admin server endpoints which inspect and change the live state of Prebid Server:
+// the active adapters, the bidders disabled at runtime, the stored request caches and the accounts.
+type AdminAPI struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Tokens are the accepted values of the "Authorization: Bearer <token>" header. Several tokens
+	// can be set to rotate them without downtime.
+	Tokens []string `mapstructure:"tokens"`
+}
+
+func (cfg *AdminAPI) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if len(cfg.Tokens) == 0 {
+		errs = append(errs, errors.New("admin_api.tokens must be set when admin_api.enabled is true"))
+	}
+	for i, token := range cfg.Tokens {
+		if token == "" {
+			errs = append(errs, fmt.Errorf("admin_api.tokens[%d] must not be empty", i))
+		}
+	}
+	return errs
+}
+
 type HTTPClient struct {
 	MaxConnsPerHost     int `mapstructure:"max_connections_per_host"`
 	MaxIdleConns        int `mapstructure:"max_idle_connections"`
@@ -127,19 +165,179 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 	}
 	errs = cfg.GDPR.validate(v, errs)
 	errs = cfg.CurrencyConverter.validate(errs)
+	errs = cfg.Analytics.validate(errs)
 	errs = cfg.Debug.validate(errs)
+	errs = cfg.CacheURL.validate(errs)
+	errs = cfg.AdminAPI.validate(errs)
+	errs = cfg.HostCookie.Encryption.validate(errs)
+	errs = cfg.UserSync.UIDStore.validate(errs)
+	errs = cfg.UserSync.Prioritization.validate(errs)
 	errs = cfg.ExtCacheURL.validate(errs)
 	if cfg.AccountDefaults.Disabled {
 		glog.Warning(`With account_defaults.disabled=true, host-defined accounts must exist and have "disabled":false. All other requests will be rejected.`)
 	}
-	if cfg.AccountDefaults.Events.Enabled {
-		glog.Warning(`account_defaults.events will currently not do anything as the feature is still under development. Please follow https://github.com/prebid/prebid-server/issues/1725 for more updates`)
-	}
+	errs = cfg.AccountDefaults.Events.validate(errs)
+	errs = cfg.AccountDefaults.CookieSync.validate(errs)
+	errs = cfg.AccountDefaults.RateLimit.validate(errs)
+	errs = cfg.AccountDefaults.Shadow.validate(errs)
+	errs = cfg.AccountDefaults.RequestPolicy.validate(errs)
+	errs = cfg.LoadShedding.validate(errs)
+	errs = cfg.TmaxAdjustments.validate(errs)
+	errs = cfg.Compression.validate(errs)
+	errs = cfg.Logging.validate(errs)
 	errs = cfg.Experiment.validate(errs)
 	errs = cfg.BidderInfos.validate(errs)
 	return errs
 }
 
+// LoadShedding rejects the auction requests, or reduces the number of bidders they call, while the server
+// is overloaded: when the auctions in flight or the p99 latency of the recent auctions exceed the thresholds.
+type LoadShedding struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxInFlightAuctions is the number of auctions which can run at once. 0 for no limit.
+	MaxInFlightAuctions int `mapstructure:"max_in_flight_auctions"`
+	// MaxP99LatencyMs is the p99 latency of the recent auctions above which the server is overloaded. 0 for no limit.
+	MaxP99LatencyMs int `mapstructure:"max_p99_latency_ms"`
+	// LatencyWindowSize is the number of recent auctions the p99 latency is computed on.
+	LatencyWindowSize int `mapstructure:"latency_window_size"`
+	// Mode is "reject" to reject the requests with a no-bid response, or "reduce" to call at most MaxBidders bidders.
+	Mode string `mapstructure:"mode"`
+	// MaxBidders is the number of bidders called by each auction in the "reduce" mode.
+	MaxBidders int `mapstructure:"max_bidders"`
+}
+
+const (
+	LoadSheddingModeReject = "reject"
+	LoadSheddingModeReduce = "reduce"
+)
+
+func (cfg *LoadShedding) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.MaxInFlightAuctions < 0 {
+		errs = append(errs, fmt.Errorf("load_shedding.max_in_flight_auctions must be >= 0. Got %d", cfg.MaxInFlightAuctions))
+	}
+	if cfg.MaxP99LatencyMs < 0 {
+		errs = append(errs, fmt.Errorf("load_shedding.max_p99_latency_ms must be >= 0. Got %d", cfg.MaxP99LatencyMs))
+	}
+	if cfg.MaxInFlightAuctions == 0 && cfg.MaxP99LatencyMs == 0 {
+		errs = append(errs, errors.New("load_shedding requires max_in_flight_auctions or max_p99_latency_ms when enabled"))
+	}
+	if cfg.MaxP99LatencyMs > 0 && cfg.LatencyWindowSize <= 0 {
+		errs = append(errs, fmt.Errorf("load_shedding.latency_window_size must be > 0. Got %d", cfg.LatencyWindowSize))
+	}
+	switch cfg.Mode {
+	case LoadSheddingModeReject:
+	case LoadSheddingModeReduce:
+		if cfg.MaxBidders <= 0 {
+			errs = append(errs, fmt.Errorf("load_shedding.max_bidders must be > 0 in reduce mode. Got %d", cfg.MaxBidders))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("load_shedding.mode must be reject or reduce. Got %s", cfg.Mode))
+	}
+	return errs
+}
+
+// Compression sets the content encodings Prebid Server reads in the auction request bodies and writes in
+// the responses: gzip, br and zstd.
+type Compression struct {
+	// Request lists the Content-Encodings accepted for the auction request bodies.
+	Request []string `mapstructure:"request"`
+	// Response lists the encodings offered for the responses, in order of preference, and negotiated
+	// with the Accept-Encoding header of the requests. enable_gzip adds gzip to the list.
+	Response []string `mapstructure:"response"`
+}
+
+func (cfg *Compression) validate(errs []error) []error {
+	for _, encoding := range cfg.Request {
+		if !sliceutil.ContainsStringIgnoreCase(compressutil.Encodings(), encoding) {
+			errs = append(errs, fmt.Errorf("compression.request: unknown encoding %s. Must be one of %v", encoding, compressutil.Encodings()))
+		}
+	}
+	for _, encoding := range cfg.Response {
+		if !sliceutil.ContainsStringIgnoreCase(compressutil.Encodings(), encoding) {
+			errs = append(errs, fmt.Errorf("compression.response: unknown encoding %s. Must be one of %v", encoding, compressutil.Encodings()))
+		}
+	}
+	return errs
+}
+
+// Logging sets how the entries logged while handling the requests are written. They carry the request ID,
+// the endpoint, the account and the X-Request-Id header of the request.
+type Logging struct {
+	// Format is "glog" to write the entries through glog, or "json" to write them as JSON lines to stderr.
+	Format string `mapstructure:"format"`
+	// Level is the lowest level written: debug, info, warn or error.
+	Level string `mapstructure:"level"`
+	// SampleRate is the fraction of the requests whose debug and info entries are written. Warnings and
+	// errors are always written.
+	SampleRate float64 `mapstructure:"sample_rate"`
+}
+
+func (cfg *Logging) validate(errs []error) []error {
+	if cfg.Format != "" && cfg.Format != logger.FormatGlog && cfg.Format != logger.FormatJSON {
+		errs = append(errs, fmt.Errorf("logging.format must be %s or %s. Got %s", logger.FormatGlog, logger.FormatJSON, cfg.Format))
+	}
+	if _, err := logger.ParseLevel(cfg.Level); err != nil {
+		errs = append(errs, fmt.Errorf("logging.level: %v", err))
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("logging.sample_rate must be in the range [0, 1]. Got %g", cfg.SampleRate))
+	}
+	return errs
+}
+
+// ResponseEncodings returns the encodings offered for the responses, in order of preference.
+func (cfg *Configuration) ResponseEncodings() []string {
+	encodings := cfg.Compression.Response
+	if cfg.EnableGzip && !sliceutil.ContainsStringIgnoreCase(encodings, compressutil.Gzip) {
+		encodings = append(encodings[:len(encodings):len(encodings)], compressutil.Gzip)
+	}
+	return encodings
+}
+
+// TmaxAdjustments gives each bidder a timeout derived from the time left in the auction and the latency
+// of its recent responses, so a slow bidder is cut off early instead of holding the whole auction.
+type TmaxAdjustments struct {
+	Enabled bool `mapstructure:"enabled"`
+	// BidderNetworkLatencyBufferMs is subtracted from the timeout of a bidder for the tmax sent to it, to
+	// leave time for the network round trip.
+	BidderNetworkLatencyBufferMs uint `mapstructure:"bidder_network_latency_buffer_ms"`
+	// PBSResponsePreparationDurationMs is the time kept to build the response once the bidders are done.
+	PBSResponsePreparationDurationMs uint `mapstructure:"pbs_response_preparation_duration_ms"`
+	// BidderResponseDurationMinMs is the smallest tmax a bidder is called with. Bidders which would get
+	// less aren't called. 0 for no minimum.
+	BidderResponseDurationMinMs uint `mapstructure:"bidder_response_duration_min_ms"`
+	// LatencyPercentile is the percentile of the recent response times of a bidder its timeout is based on.
+	LatencyPercentile int `mapstructure:"latency_percentile"`
+	// LatencyMultiplier is applied to the latency percentile of a bidder to get its timeout.
+	LatencyMultiplier float64 `mapstructure:"latency_multiplier"`
+	// LatencyWindowSize is the number of recent responses of each bidder the percentile is computed on.
+	LatencyWindowSize int `mapstructure:"latency_window_size"`
+	// MinLatencySamples is the number of responses needed before the latency of a bidder is used.
+	MinLatencySamples int `mapstructure:"min_latency_samples"`
+}
+
+func (cfg *TmaxAdjustments) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.LatencyPercentile < 1 || cfg.LatencyPercentile > 100 {
+		errs = append(errs, fmt.Errorf("tmax_adjustments.latency_percentile must be between 1 and 100. Got %d", cfg.LatencyPercentile))
+	}
+	if cfg.LatencyMultiplier < 1 {
+		errs = append(errs, fmt.Errorf("tmax_adjustments.latency_multiplier must be >= 1. Got %f", cfg.LatencyMultiplier))
+	}
+	if cfg.LatencyWindowSize <= 0 {
+		errs = append(errs, fmt.Errorf("tmax_adjustments.latency_window_size must be > 0. Got %d", cfg.LatencyWindowSize))
+	}
+	if cfg.MinLatencySamples <= 0 || (cfg.LatencyWindowSize > 0 && cfg.MinLatencySamples > cfg.LatencyWindowSize) {
+		errs = append(errs, fmt.Errorf("tmax_adjustments.min_latency_samples must be > 0 and <= latency_window_size. Got %d", cfg.MinLatencySamples))
+	}
+	return errs
+}
+
 type AuctionTimeouts struct {
 	// The default timeout is used if the user's request didn't define one. Use 0 if there's no default.
 	Default uint64 `mapstructure:"default"`
@@ -426,8 +624,69 @@ type LMT struct {
 }
 
 type Analytics struct {
-	File     FileLogs `mapstructure:"file"`
-	Pubstack Pubstack `mapstructure:"pubstack"`
+	File     FileLogs        `mapstructure:"file"`
+	Pubstack Pubstack        `mapstructure:"pubstack"`
+	Stream   StreamAnalytics `mapstructure:"stream"`
+}
+
+func (cfg *Analytics) validate(errs []error) []error {
+	errs = cfg.File.Policy.validate("analytics.file.policy", errs)
+	errs = cfg.Pubstack.Policy.validate("analytics.pubstack.policy", errs)
+	errs = cfg.Stream.Policy.validate("analytics.stream.policy", errs)
+	return cfg.Stream.validate(errs)
+}
+
+// Loggable object types analytics policies apply to
+const (
+	AnalyticsObjectAuction      = "auction"
+	AnalyticsObjectAmp          = "amp"
+	AnalyticsObjectVideo        = "video"
+	AnalyticsObjectCookieSync   = "cookie_sync"
+	AnalyticsObjectSetUID       = "setuid"
+	AnalyticsObjectNotification = "notification"
+	AnalyticsObjectShadow       = "shadow"
+)
+
+var analyticsObjectTypes = []string{
+	AnalyticsObjectAuction,
+	AnalyticsObjectAmp,
+	AnalyticsObjectVideo,
+	AnalyticsObjectCookieSync,
+	AnalyticsObjectSetUID,
+	AnalyticsObjectNotification,
+	AnalyticsObjectShadow,
+}
+
+// AnalyticsPolicy controls which objects are passed to an analytics module and what they contain.
+type AnalyticsPolicy struct {
+	// SamplingRates maps a loggable object type to the fraction of objects logged. Missing types are always logged.
+	SamplingRates map[string]float64 `mapstructure:"sampling_rates"`
+	// EnabledAccounts restricts logging to the listed accounts when not empty.
+	EnabledAccounts []string `mapstructure:"enabled_accounts"`
+	// DisabledAccounts are never logged, even if they are also enabled.
+	DisabledAccounts []string `mapstructure:"disabled_accounts"`
+	// RedactedFields are dot separated paths, e.g. "user" or "device.ip", removed before an object is logged from
+	// every copy of the request it holds: the bid request, the video request, the resolved request in
+	// ext.debug.resolvedrequest of the response and the values of the hook analytics tags. The hook debug
+	// messages are dropped as well, and so are the objects which can't be redacted.
+	RedactedFields []string `mapstructure:"redacted_fields"`
+}
+
+func (cfg *AnalyticsPolicy) validate(prefix string, errs []error) []error {
+	for objectType, rate := range cfg.SamplingRates {
+		if !sliceutil.ContainsStringIgnoreCase(analyticsObjectTypes, objectType) {
+			errs = append(errs, fmt.Errorf("%s.sampling_rates has unknown object type %s. Must be one of: %s", prefix, objectType, strings.Join(analyticsObjectTypes, ", ")))
+		}
+		if rate < 0 || rate > 1 {
+			errs = append(errs, fmt.Errorf("%s.sampling_rates.%s must be in the range [0, 1]. Got %g", prefix, objectType, rate))
+		}
+	}
+	for _, field := range cfg.RedactedFields {
+		if field == "" || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") || strings.Contains(field, "..") {
+			errs = append(errs, fmt.Errorf("%s.redacted_fields has invalid path %q", prefix, field))
+		}
+	}
+	return errs
 }
 
 type CurrencyConverter struct {
@@ -445,15 +704,17 @@ func (cfg *CurrencyConverter) validate(errs []error) []error {
 
 // FileLogs Corresponding config for FileLogger as a PBS Analytics Module
 type FileLogs struct {
-	Filename string `mapstructure:"filename"`
+	Filename string          `mapstructure:"filename"`
+	Policy   AnalyticsPolicy `mapstructure:"policy"`
 }
 
 type Pubstack struct {
-	Enabled     bool           `mapstructure:"enabled"`
-	ScopeId     string         `mapstructure:"scopeid"`
-	IntakeUrl   string         `mapstructure:"endpoint"`
-	Buffers     PubstackBuffer `mapstructure:"buffers"`
-	ConfRefresh string         `mapstructure:"configuration_refresh_delay"`
+	Enabled     bool            `mapstructure:"enabled"`
+	ScopeId     string          `mapstructure:"scopeid"`
+	IntakeUrl   string          `mapstructure:"endpoint"`
+	Buffers     PubstackBuffer  `mapstructure:"buffers"`
+	ConfRefresh string          `mapstructure:"configuration_refresh_delay"`
+	Policy      AnalyticsPolicy `mapstructure:"policy"`
 }
 
 type PubstackBuffer struct {
@@ -462,6 +723,79 @@ type PubstackBuffer struct {
 	Timeout    string `mapstructure:"timeout"`
 }
 
+// StreamAnalytics configures the analytics module streaming all loggable objects to a sink
+type StreamAnalytics struct {
+	Enabled bool            `mapstructure:"enabled"`
+	Sink    string          `mapstructure:"sink"`
+	Buffers StreamBuffer    `mapstructure:"buffers"`
+	File    StreamFileSink  `mapstructure:"file"`
+	HTTP    StreamHTTPSink  `mapstructure:"http"`
+	Kafka   StreamKafkaSink `mapstructure:"kafka"`
+	Policy  AnalyticsPolicy `mapstructure:"policy"`
+}
+
+type StreamBuffer struct {
+	BufferSize string `mapstructure:"size"`
+	EventCount int    `mapstructure:"count"`
+	Timeout    string `mapstructure:"timeout"`
+}
+
+// StreamFileSink writes events to local files, rotated when they reach the max size or age
+type StreamFileSink struct {
+	Directory        string `mapstructure:"directory"`
+	Prefix           string `mapstructure:"prefix"`
+	MaxSize          string `mapstructure:"max_size"`
+	RotationInterval string `mapstructure:"rotation_interval"`
+}
+
+type StreamHTTPSink struct {
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// StreamKafkaSink produces events to a single topic partition using the Kafka wire protocol
+type StreamKafkaSink struct {
+	Broker    string `mapstructure:"broker"`
+	Topic     string `mapstructure:"topic"`
+	Partition int32  `mapstructure:"partition"`
+	ClientID  string `mapstructure:"client_id"`
+	Acks      int16  `mapstructure:"acks"`
+	TimeoutMS int    `mapstructure:"timeout_ms"`
+}
+
+const (
+	StreamSinkFile  = "file"
+	StreamSinkHTTP  = "http"
+	StreamSinkKafka = "kafka"
+)
+
+func (cfg *StreamAnalytics) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+
+	switch cfg.Sink {
+	case StreamSinkFile:
+		if cfg.File.Directory == "" {
+			errs = append(errs, errors.New("analytics.stream.file.directory must be specified for the file sink"))
+		}
+	case StreamSinkHTTP:
+		if cfg.HTTP.Endpoint == "" {
+			errs = append(errs, errors.New("analytics.stream.http.endpoint must be specified for the http sink"))
+		}
+	case StreamSinkKafka:
+		if cfg.Kafka.Broker == "" || cfg.Kafka.Topic == "" {
+			errs = append(errs, errors.New("analytics.stream.kafka.broker and analytics.stream.kafka.topic must be specified for the kafka sink"))
+		}
+		if cfg.Kafka.Acks < -1 || cfg.Kafka.Acks > 1 {
+			errs = append(errs, fmt.Errorf("analytics.stream.kafka.acks must be -1, 0 or 1. Got %d", cfg.Kafka.Acks))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("analytics.stream.sink must be one of: %s, %s, %s. Got %s", StreamSinkFile, StreamSinkHTTP, StreamSinkKafka, cfg.Sink))
+	}
+
+	return errs
+}
+
 type VTrack struct {
 	TimeoutMS          int64 `mapstructure:"timeout_ms"`
 	AllowUnknownBidder bool  `mapstructure:"allow_unknown_bidder"`
@@ -482,8 +816,47 @@ type HostCookie struct {
 	OptOutCookie       Cookie `mapstructure:"optout_cookie"`
 	// Cookie timeout in days
 	TTL int64 `mapstructure:"ttl_days"`
+	// Encryption configures the encryption of the uids cookie.
+	Encryption HostCookieEncryption `mapstructure:"encryption"`
+}
+
+// HostCookieEncryption configures the encryption of the uids cookie. When keys are set, the cookie is
+// encrypted and authenticated with AES-256-GCM so users can't read or forge the UIDs sent to bidders.
+type HostCookieEncryption struct {
+	// Keys decrypt the cookie, and the first key also encrypts it. To rotate keys, add the new key first
+	// and remove the old one once the cookies it encrypted have expired.
+	Keys []HostCookieKey `mapstructure:"keys"`
+	// AllowLegacy reads the unencrypted cookies written before encryption was enabled.
+	AllowLegacy bool `mapstructure:"allow_legacy"`
+}
+
+// HostCookieKey is a key of the uids cookie encryption.
+type HostCookieKey struct {
+	// ID is written in the cookie to find the key decrypting it. It must be 1 to 8 letters or digits.
+	ID string `mapstructure:"id"`
+	// Secret is the base64 encoded 32 byte AES key.
+	Secret string `mapstructure:"secret"`
+}
+
+func (cfg *HostCookieEncryption) validate(errs []error) []error {
+	ids := make(map[string]struct{}, len(cfg.Keys))
+	for i, key := range cfg.Keys {
+		if !hostCookieKeyIDPattern.MatchString(key.ID) {
+			errs = append(errs, fmt.Errorf("host_cookie.encryption.keys[%d].id must be 1 to 8 letters or digits. Got %s", i, key.ID))
+		} else if _, ok := ids[key.ID]; ok {
+			errs = append(errs, fmt.Errorf("host_cookie.encryption.keys[%d].id %s is used by another key", i, key.ID))
+		}
+		ids[key.ID] = struct{}{}
+
+		if secret, err := base64.StdEncoding.DecodeString(key.Secret); err != nil || len(secret) != 32 {
+			errs = append(errs, fmt.Errorf("host_cookie.encryption.keys[%d].secret must be a base64 encoded 32 byte key", i))
+		}
+	}
+	return errs
 }
 
+var hostCookieKeyIDPattern = regexp.MustCompile("^[A-Za-z0-9]{1,8}$")
+
 func (cfg *HostCookie) TTLDuration() time.Duration {
 	return time.Duration(cfg.TTL) * time.Hour * 24
 }
@@ -578,6 +951,72 @@ type Cache struct {
 	ExpectedTimeMillis int `mapstructure:"expected_millis"`
 
 	DefaultTTLs DefaultTTLs `mapstructure:"default_ttl_seconds"`
+
+	// Retry configures how failed requests to Prebid Cache are retried within the auction timeout.
+	Retry CacheRetry `mapstructure:"retry"`
+	// Batch configures the coalescing of puts from concurrent auctions into a single request.
+	Batch CacheBatch `mapstructure:"batch"`
+	// Local replaces Prebid Cache with an in-process cache served by Prebid Server on GET /cache.
+	// The scheme and host must then point to this Prebid Server.
+	Local LocalCache `mapstructure:"local"`
+}
+
+// CacheRetry configures retries of failed Prebid Cache requests. A request is only retried when
+// the backoff fits in the time left for the auction.
+type CacheRetry struct {
+	MaxRetries      int `mapstructure:"max_retries"`
+	BackoffMillis   int `mapstructure:"backoff_ms"`
+	MaxJitterMillis int `mapstructure:"max_jitter_ms"`
+}
+
+// CacheBatch configures the batching of Prebid Cache puts. Puts are sent once MaxPuts values are
+// waiting or after MaxWaitMillis, whichever comes first.
+type CacheBatch struct {
+	Enabled       bool `mapstructure:"enabled"`
+	MaxPuts       int  `mapstructure:"max_puts"`
+	MaxWaitMillis int  `mapstructure:"max_wait_ms"`
+}
+
+// LocalCache configures the in-process cache.
+type LocalCache struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxEntries is the number of values held before new puts are rejected.
+	MaxEntries int `mapstructure:"max_entries"`
+	// DefaultTTLSeconds applies to puts without a TTL, MaxTTLSeconds caps the TTL of all puts.
+	DefaultTTLSeconds int `mapstructure:"default_ttl_seconds"`
+	MaxTTLSeconds     int `mapstructure:"max_ttl_seconds"`
+}
+
+func (cfg *Cache) validate(errs []error) []error {
+	if cfg.Retry.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("cache.retry.max_retries must be >= 0. Got %d", cfg.Retry.MaxRetries))
+	}
+	if cfg.Retry.BackoffMillis < 0 {
+		errs = append(errs, fmt.Errorf("cache.retry.backoff_ms must be >= 0. Got %d", cfg.Retry.BackoffMillis))
+	}
+	if cfg.Retry.MaxJitterMillis < 0 {
+		errs = append(errs, fmt.Errorf("cache.retry.max_jitter_ms must be >= 0. Got %d", cfg.Retry.MaxJitterMillis))
+	}
+	if cfg.Batch.Enabled {
+		if cfg.Batch.MaxPuts <= 0 {
+			errs = append(errs, fmt.Errorf("cache.batch.max_puts must be > 0. Got %d", cfg.Batch.MaxPuts))
+		}
+		if cfg.Batch.MaxWaitMillis <= 0 {
+			errs = append(errs, fmt.Errorf("cache.batch.max_wait_ms must be > 0. Got %d", cfg.Batch.MaxWaitMillis))
+		}
+	}
+	if cfg.Local.Enabled {
+		if cfg.Local.MaxEntries <= 0 {
+			errs = append(errs, fmt.Errorf("cache.local.max_entries must be > 0. Got %d", cfg.Local.MaxEntries))
+		}
+		if cfg.Local.DefaultTTLSeconds <= 0 {
+			errs = append(errs, fmt.Errorf("cache.local.default_ttl_seconds must be > 0. Got %d", cfg.Local.DefaultTTLSeconds))
+		}
+		if cfg.Local.MaxTTLSeconds < cfg.Local.DefaultTTLSeconds {
+			errs = append(errs, fmt.Errorf("cache.local.max_ttl_seconds must be >= cache.local.default_ttl_seconds. Got %d", cfg.Local.MaxTTLSeconds))
+		}
+	}
+	return errs
 }
 
 // Default TTLs to use to cache bids for different types of imps.
@@ -814,7 +1253,14 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("unix_socket_enable", false)              // boolean which decide if the socket-server will be started.
 	v.SetDefault("unix_socket_name", "prebid-server.sock") // path of the socket's file which must be listened.
 	v.SetDefault("admin_port", 6060)
+	v.SetDefault("admin_api.enabled", false)
+	v.SetDefault("admin_api.tokens", []string{})
 	v.SetDefault("enable_gzip", false)
+	v.SetDefault("compression.request", compressutil.Encodings())
+	v.SetDefault("compression.response", []string{})
+	v.SetDefault("logging.format", logger.FormatGlog)
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.sample_rate", 1.0)
 	v.SetDefault("garbage_collector_threshold", 0)
 	v.SetDefault("status_response", "")
 	v.SetDefault("datacenter", "")
@@ -828,6 +1274,16 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("cache.default_ttl_seconds.video", 0)
 	v.SetDefault("cache.default_ttl_seconds.native", 0)
 	v.SetDefault("cache.default_ttl_seconds.audio", 0)
+	v.SetDefault("cache.retry.max_retries", 0)
+	v.SetDefault("cache.retry.backoff_ms", 20)
+	v.SetDefault("cache.retry.max_jitter_ms", 10)
+	v.SetDefault("cache.batch.enabled", false)
+	v.SetDefault("cache.batch.max_puts", 50)
+	v.SetDefault("cache.batch.max_wait_ms", 5)
+	v.SetDefault("cache.local.enabled", false)
+	v.SetDefault("cache.local.max_entries", 100000)
+	v.SetDefault("cache.local.default_ttl_seconds", 300)
+	v.SetDefault("cache.local.max_ttl_seconds", 3600)
 	v.SetDefault("external_cache.scheme", "")
 	v.SetDefault("external_cache.host", "")
 	v.SetDefault("external_cache.path", "")
@@ -841,6 +1297,7 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("host_cookie.value", "")
 	v.SetDefault("host_cookie.ttl_days", 90)
 	v.SetDefault("host_cookie.max_cookie_size_bytes", 0)
+	v.SetDefault("host_cookie.encryption.allow_legacy", true)
 	v.SetDefault("host_schain_node", nil)
 	v.SetDefault("validations.banner_creative_max_size", ValidationSkip)
 	v.SetDefault("validations.secure_markup", ValidationSkip)
@@ -934,6 +1391,23 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	// some adapters append the user id to the end of the redirect url instead of using
 	// macro substitution. it is important for the uid to be the last query parameter.
 	v.SetDefault("user_sync.redirect_url", "{{.ExternalURL}}/setuid?bidder={{.SyncerKey}}&gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}&f={{.SyncType}}&uid={{.UserMacro}}")
+	v.SetDefault("user_sync.prioritization.expiry_weight", 1)
+	v.SetDefault("user_sync.prioritization.bid_rate_weight", 1)
+	v.SetDefault("user_sync.prioritization.priority_weight", 1)
+	v.SetDefault("user_sync.prioritization.refresh_window_hours", 72)
+	v.SetDefault("user_sync.prioritization.bid_rate_window_minutes", 60)
+	v.SetDefault("user_sync.uid_store.type", "")
+	v.SetDefault("user_sync.uid_store.memory.max_entries", 1000000)
+	v.SetDefault("user_sync.uid_store.database.connection.driver", "")
+	v.SetDefault("user_sync.uid_store.database.connection.dbname", "")
+	v.SetDefault("user_sync.uid_store.database.connection.host", "")
+	v.SetDefault("user_sync.uid_store.database.connection.port", 0)
+	v.SetDefault("user_sync.uid_store.database.connection.user", "")
+	v.SetDefault("user_sync.uid_store.database.connection.password", "")
+	v.SetDefault("user_sync.uid_store.database.timeout_ms", 50)
+	v.SetDefault("user_sync.uid_store.database.get_query", "")
+	v.SetDefault("user_sync.uid_store.database.set_query", "")
+	v.SetDefault("user_sync.uid_store.database.delete_query", "")
 
 	v.SetDefault("max_request_size", 1024*256)
 	v.SetDefault("analytics.file.filename", "")
@@ -944,6 +1418,22 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("analytics.pubstack.buffers.size", "2MB")
 	v.SetDefault("analytics.pubstack.buffers.count", 100)
 	v.SetDefault("analytics.pubstack.buffers.timeout", "900s")
+	v.SetDefault("analytics.stream.enabled", false)
+	v.SetDefault("analytics.stream.sink", "file")
+	v.SetDefault("analytics.stream.buffers.size", "2MB")
+	v.SetDefault("analytics.stream.buffers.count", 100)
+	v.SetDefault("analytics.stream.buffers.timeout", "60s")
+	v.SetDefault("analytics.stream.file.directory", "")
+	v.SetDefault("analytics.stream.file.prefix", "pbs-analytics")
+	v.SetDefault("analytics.stream.file.max_size", "100MB")
+	v.SetDefault("analytics.stream.file.rotation_interval", "1h")
+	v.SetDefault("analytics.stream.http.endpoint", "")
+	v.SetDefault("analytics.stream.kafka.broker", "")
+	v.SetDefault("analytics.stream.kafka.topic", "")
+	v.SetDefault("analytics.stream.kafka.partition", 0)
+	v.SetDefault("analytics.stream.kafka.client_id", "prebid-server")
+	v.SetDefault("analytics.stream.kafka.acks", 1)
+	v.SetDefault("analytics.stream.kafka.timeout_ms", 5000)
 	v.SetDefault("amp_timeout_adjustment_ms", 0)
 	v.BindEnv("gdpr.default_value")
 	v.SetDefault("gdpr.enabled", true)
@@ -990,6 +1480,8 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("account_required", false)
 	v.SetDefault("account_defaults.disabled", false)
 	v.SetDefault("account_defaults.debug_allow", true)
+	v.SetDefault("account_defaults.rate_limit.requests_per_second", 0)
+	v.SetDefault("account_defaults.rate_limit.burst", 0)
 	v.SetDefault("certificates_file", "")
 	v.SetDefault("auto_gen_source_tid", true)
 	v.SetDefault("generate_bid_id", false)
@@ -998,6 +1490,21 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("request_timeout_headers.request_time_in_queue", "")
 	v.SetDefault("request_timeout_headers.request_timeout_in_queue", "")
 
+	v.SetDefault("load_shedding.enabled", false)
+	v.SetDefault("load_shedding.max_in_flight_auctions", 0)
+	v.SetDefault("load_shedding.max_p99_latency_ms", 0)
+	v.SetDefault("load_shedding.latency_window_size", 1000)
+	v.SetDefault("load_shedding.mode", LoadSheddingModeReject)
+	v.SetDefault("load_shedding.max_bidders", 3)
+	v.SetDefault("tmax_adjustments.enabled", false)
+	v.SetDefault("tmax_adjustments.bidder_network_latency_buffer_ms", 0)
+	v.SetDefault("tmax_adjustments.pbs_response_preparation_duration_ms", 0)
+	v.SetDefault("tmax_adjustments.bidder_response_duration_min_ms", 0)
+	v.SetDefault("tmax_adjustments.latency_percentile", 95)
+	v.SetDefault("tmax_adjustments.latency_multiplier", 1.5)
+	v.SetDefault("tmax_adjustments.latency_window_size", 200)
+	v.SetDefault("tmax_adjustments.min_latency_samples", 50)
+
 	v.SetDefault("debug.timeout_notification.log", false)
 	v.SetDefault("debug.timeout_notification.sampling_rate", 0.0)
 	v.SetDefault("debug.timeout_notification.fail_only", false)
@@ -38,6 +38,218 @@ var bidderInfos = BidderInfos{
 	},
 }
 
+func TestCacheValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      Cache
+		expErrors int
+	}{
+		{
+			desc:      "Defaults",
+			data:      Cache{},
+			expErrors: 0,
+		},
+		{
+			desc:      "Negative retries",
+			data:      Cache{Retry: CacheRetry{MaxRetries: -1, BackoffMillis: -1, MaxJitterMillis: -1}},
+			expErrors: 3,
+		},
+		{
+			desc:      "Batching without limits",
+			data:      Cache{Batch: CacheBatch{Enabled: true}},
+			expErrors: 2,
+		},
+		{
+			desc:      "Valid batching",
+			data:      Cache{Batch: CacheBatch{Enabled: true, MaxPuts: 10, MaxWaitMillis: 5}},
+			expErrors: 0,
+		},
+		{
+			desc:      "Local cache with max TTL below the default TTL",
+			data:      Cache{Local: LocalCache{Enabled: true, MaxEntries: 10, DefaultTTLSeconds: 300, MaxTTLSeconds: 60}},
+			expErrors: 1,
+		},
+		{
+			desc:      "Local cache without entries",
+			data:      Cache{Local: LocalCache{Enabled: true, DefaultTTLSeconds: 300, MaxTTLSeconds: 300}},
+			expErrors: 1,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
+
+func TestHostCookieEncryptionValidate(t *testing.T) {
+	validSecret := "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+	testCases := []struct {
+		desc      string
+		data      HostCookieEncryption
+		expErrors int
+	}{
+		{
+			desc:      "Not encrypted",
+			data:      HostCookieEncryption{},
+			expErrors: 0,
+		},
+		{
+			desc:      "Valid keys",
+			data:      HostCookieEncryption{Keys: []HostCookieKey{{ID: "k2", Secret: validSecret}, {ID: "k1", Secret: validSecret}}},
+			expErrors: 0,
+		},
+		{
+			desc:      "Invalid ID",
+			data:      HostCookieEncryption{Keys: []HostCookieKey{{ID: "key.1", Secret: validSecret}, {ID: "", Secret: validSecret}}},
+			expErrors: 2,
+		},
+		{
+			desc:      "Duplicate ID",
+			data:      HostCookieEncryption{Keys: []HostCookieKey{{ID: "k1", Secret: validSecret}, {ID: "k1", Secret: validSecret}}},
+			expErrors: 1,
+		},
+		{
+			desc:      "Invalid secrets",
+			data:      HostCookieEncryption{Keys: []HostCookieKey{{ID: "k1", Secret: "not base64"}, {ID: "k2", Secret: "c2hvcnQ="}}},
+			expErrors: 2,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
+
+func TestAdminAPIValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      AdminAPI
+		expErrors int
+	}{
+		{
+			desc:      "Disabled",
+			data:      AdminAPI{},
+			expErrors: 0,
+		},
+		{
+			desc:      "Enabled with tokens",
+			data:      AdminAPI{Enabled: true, Tokens: []string{"new", "old"}},
+			expErrors: 0,
+		},
+		{
+			desc:      "Enabled without tokens",
+			data:      AdminAPI{Enabled: true},
+			expErrors: 1,
+		},
+		{
+			desc:      "Enabled with empty token",
+			data:      AdminAPI{Enabled: true, Tokens: []string{"token", ""}},
+			expErrors: 1,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
+
+func TestLoadSheddingValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      LoadShedding
+		expErrors int
+	}{
+		{
+			desc:      "Disabled",
+			data:      LoadShedding{MaxInFlightAuctions: -1, Mode: "unknown"},
+			expErrors: 0,
+		},
+		{
+			desc:      "Reject mode",
+			data:      LoadShedding{Enabled: true, MaxInFlightAuctions: 100, Mode: LoadSheddingModeReject},
+			expErrors: 0,
+		},
+		{
+			desc:      "Reduce mode",
+			data:      LoadShedding{Enabled: true, MaxP99LatencyMs: 500, LatencyWindowSize: 1000, Mode: LoadSheddingModeReduce, MaxBidders: 3},
+			expErrors: 0,
+		},
+		{
+			desc:      "No thresholds",
+			data:      LoadShedding{Enabled: true, Mode: LoadSheddingModeReject},
+			expErrors: 1,
+		},
+		{
+			desc:      "Negative thresholds",
+			data:      LoadShedding{Enabled: true, MaxInFlightAuctions: -1, MaxP99LatencyMs: -1, Mode: LoadSheddingModeReject},
+			expErrors: 2,
+		},
+		{
+			desc:      "Invalid latency window",
+			data:      LoadShedding{Enabled: true, MaxP99LatencyMs: 500, Mode: LoadSheddingModeReject},
+			expErrors: 1,
+		},
+		{
+			desc:      "Reduce mode without max bidders",
+			data:      LoadShedding{Enabled: true, MaxInFlightAuctions: 100, Mode: LoadSheddingModeReduce},
+			expErrors: 1,
+		},
+		{
+			desc:      "Unknown mode",
+			data:      LoadShedding{Enabled: true, MaxInFlightAuctions: 100, Mode: "drop"},
+			expErrors: 1,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
+
+func TestTmaxAdjustmentsValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      TmaxAdjustments
+		expErrors int
+	}{
+		{
+			desc:      "Disabled",
+			data:      TmaxAdjustments{LatencyPercentile: 101},
+			expErrors: 0,
+		},
+		{
+			desc:      "Valid",
+			data:      TmaxAdjustments{Enabled: true, LatencyPercentile: 95, LatencyMultiplier: 1.5, LatencyWindowSize: 200, MinLatencySamples: 50},
+			expErrors: 0,
+		},
+		{
+			desc:      "Invalid percentile",
+			data:      TmaxAdjustments{Enabled: true, LatencyPercentile: 0, LatencyMultiplier: 1.5, LatencyWindowSize: 200, MinLatencySamples: 50},
+			expErrors: 1,
+		},
+		{
+			desc:      "Invalid multiplier",
+			data:      TmaxAdjustments{Enabled: true, LatencyPercentile: 95, LatencyMultiplier: 0.5, LatencyWindowSize: 200, MinLatencySamples: 50},
+			expErrors: 1,
+		},
+		{
+			desc:      "Invalid latency window",
+			data:      TmaxAdjustments{Enabled: true, LatencyPercentile: 95, LatencyMultiplier: 1.5, MinLatencySamples: 50},
+			expErrors: 1,
+		},
+		{
+			desc:      "More samples than the window",
+			data:      TmaxAdjustments{Enabled: true, LatencyPercentile: 95, LatencyMultiplier: 1.5, LatencyWindowSize: 20, MinLatencySamples: 50},
+			expErrors: 1,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
+
 func TestExternalCacheURLValidate(t *testing.T) {
 	testCases := []struct {
 		desc      string
@@ -2791,6 +3003,201 @@ func TestValidateAccountsConfigRestrictions(t *testing.T) {
 	assert.Contains(t, errs, errors.New("accounts.database: retrieving accounts via database not available, use accounts.files"))
 }
 
+func TestValidateStreamAnalytics(t *testing.T) {
+	testCases := []struct {
+		description   string
+		stream        StreamAnalytics
+		expectedError error
+	}{
+		{
+			description: "Disabled",
+			stream:      StreamAnalytics{Enabled: false, Sink: "unknown"},
+		},
+		{
+			description: "Valid file sink",
+			stream:      StreamAnalytics{Enabled: true, Sink: StreamSinkFile, File: StreamFileSink{Directory: "/tmp"}},
+		},
+		{
+			description:   "File sink without directory",
+			stream:        StreamAnalytics{Enabled: true, Sink: StreamSinkFile},
+			expectedError: errors.New("analytics.stream.file.directory must be specified for the file sink"),
+		},
+		{
+			description:   "HTTP sink without endpoint",
+			stream:        StreamAnalytics{Enabled: true, Sink: StreamSinkHTTP},
+			expectedError: errors.New("analytics.stream.http.endpoint must be specified for the http sink"),
+		},
+		{
+			description:   "Kafka sink without topic",
+			stream:        StreamAnalytics{Enabled: true, Sink: StreamSinkKafka, Kafka: StreamKafkaSink{Broker: "localhost:9092", Acks: 1}},
+			expectedError: errors.New("analytics.stream.kafka.broker and analytics.stream.kafka.topic must be specified for the kafka sink"),
+		},
+		{
+			description:   "Kafka sink with invalid acks",
+			stream:        StreamAnalytics{Enabled: true, Sink: StreamSinkKafka, Kafka: StreamKafkaSink{Broker: "localhost:9092", Topic: "pbs", Acks: 2}},
+			expectedError: errors.New("analytics.stream.kafka.acks must be -1, 0 or 1. Got 2"),
+		},
+		{
+			description:   "Unknown sink",
+			stream:        StreamAnalytics{Enabled: true, Sink: "unknown"},
+			expectedError: errors.New("analytics.stream.sink must be one of: file, http, kafka. Got unknown"),
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.stream.validate(nil)
+		if test.expectedError == nil {
+			assert.Empty(t, errs, test.description)
+		} else {
+			assert.Equal(t, []error{test.expectedError}, errs, test.description)
+		}
+	}
+}
+
+func TestValidateAnalyticsPolicy(t *testing.T) {
+	testCases := []struct {
+		description   string
+		policy        AnalyticsPolicy
+		expectedError error
+	}{
+		{
+			description: "Empty",
+			policy:      AnalyticsPolicy{},
+		},
+		{
+			description: "Valid",
+			policy: AnalyticsPolicy{
+				SamplingRates:  map[string]float64{AnalyticsObjectAuction: 0.1, AnalyticsObjectSetUID: 0},
+				RedactedFields: []string{"user", "device.ip"},
+			},
+		},
+		{
+			description:   "Unknown object type",
+			policy:        AnalyticsPolicy{SamplingRates: map[string]float64{"bid": 0.5}},
+			expectedError: errors.New("analytics.file.policy.sampling_rates has unknown object type bid. Must be one of: auction, amp, video, cookie_sync, setuid, notification, shadow"),
+		},
+		{
+			description:   "Sampling rate out of range",
+			policy:        AnalyticsPolicy{SamplingRates: map[string]float64{AnalyticsObjectAmp: 1.5}},
+			expectedError: errors.New("analytics.file.policy.sampling_rates.amp must be in the range [0, 1]. Got 1.5"),
+		},
+		{
+			description:   "Invalid redacted field",
+			policy:        AnalyticsPolicy{RedactedFields: []string{"device..ip"}},
+			expectedError: errors.New(`analytics.file.policy.redacted_fields has invalid path "device..ip"`),
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.policy.validate("analytics.file.policy", nil)
+		if test.expectedError == nil {
+			assert.Empty(t, errs, test.description)
+		} else {
+			assert.Equal(t, []error{test.expectedError}, errs, test.description)
+		}
+	}
+}
+
+func TestValidateCompression(t *testing.T) {
+	testCases := []struct {
+		description    string
+		compression    Compression
+		expectedErrors []error
+	}{
+		{
+			description: "Empty",
+			compression: Compression{},
+		},
+		{
+			description: "Valid",
+			compression: Compression{
+				Request:  []string{"gzip", "br", "zstd"},
+				Response: []string{"ZSTD", "gzip"},
+			},
+		},
+		{
+			description: "Unknown encodings",
+			compression: Compression{
+				Request:  []string{"gzip", "deflate"},
+				Response: []string{"lz77"},
+			},
+			expectedErrors: []error{
+				errors.New("compression.request: unknown encoding deflate. Must be one of [gzip br zstd]"),
+				errors.New("compression.response: unknown encoding lz77. Must be one of [gzip br zstd]"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.compression.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateLogging(t *testing.T) {
+	testCases := []struct {
+		description    string
+		logging        Logging
+		expectedErrors []error
+	}{
+		{
+			description: "Empty",
+			logging:     Logging{},
+		},
+		{
+			description: "Valid",
+			logging:     Logging{Format: "json", Level: "warn", SampleRate: 0.1},
+		},
+		{
+			description: "Invalid",
+			logging:     Logging{Format: "text", Level: "fatal", SampleRate: 1.5},
+			expectedErrors: []error{
+				errors.New("logging.format must be glog or json. Got text"),
+				errors.New("logging.level: unknown log level fatal. Must be one of [debug info warn error]"),
+				errors.New("logging.sample_rate must be in the range [0, 1]. Got 1.5"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.logging.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestResponseEncodings(t *testing.T) {
+	testCases := []struct {
+		description string
+		cfg         Configuration
+		expected    []string
+	}{
+		{
+			description: "None",
+			cfg:         Configuration{},
+			expected:    nil,
+		},
+		{
+			description: "Enable gzip",
+			cfg:         Configuration{EnableGzip: true},
+			expected:    []string{"gzip"},
+		},
+		{
+			description: "Enable gzip after the configured encodings",
+			cfg:         Configuration{EnableGzip: true, Compression: Compression{Response: []string{"zstd", "br"}}},
+			expected:    []string{"zstd", "br", "gzip"},
+		},
+		{
+			description: "Enable gzip already configured",
+			cfg:         Configuration{EnableGzip: true, Compression: Compression{Response: []string{"gzip", "br"}}},
+			expected:    []string{"gzip", "br"},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, test.cfg.ResponseEncodings(), test.description)
+	}
+}
+
 func newDefaultConfig(t *testing.T) (*Configuration, *viper.Viper) {
 	v := viper.New()
 	SetupViper(v, "", bidderInfos)
@@ -58,8 +58,7 @@ type VASTEvent struct {
 }
 
 // Events indicates the various types of events to be captured typically for injecting tracker URLs
-// within the VAST XML
-// Don't enable this feature. It is still under developmment. Please follow https://github.com/prebid/prebid-server/issues/1725 for more updates
+// within the VAST XML of video bids
 type Events struct {
 	Enabled    bool        `mapstructure:"enabled" json:"enabled"`
 	DefaultURL string      `mapstructure:"default_url" json:"default_url"`
@@ -1,10 +1,17 @@
 package config
 
+import (
+	"fmt"
+)
+
 // UserSync specifies the static global user sync configuration.
 type UserSync struct {
 	Cooperative UserSyncCooperative `mapstructure:"coop_sync"`
 	ExternalURL string              `mapstructure:"external_url"`
 	RedirectURL string              `mapstructure:"redirect_url"`
+	UIDStore    UIDStore            `mapstructure:"uid_store"`
+	// Prioritization configures the cookie sync strategy of the accounts using "prioritized".
+	Prioritization UserSyncPrioritization `mapstructure:"prioritization"`
 }
 
 // UserSyncCooperative specifies the static global default cooperative cookie sync
@@ -12,3 +19,87 @@ type UserSyncCooperative struct {
 	EnabledByDefault bool       `mapstructure:"default"`
 	PriorityGroups   [][]string `mapstructure:"priority_groups"`
 }
+
+// UserSyncPrioritization configures how bidders are scored by the prioritized cookie sync strategy.
+// Each weight multiplies a score between 0 and 1, and the bidders with the highest total are synced first.
+type UserSyncPrioritization struct {
+	// ExpiryWeight favors bidders with no UID, or with one expiring within the refresh window.
+	ExpiryWeight float64 `mapstructure:"expiry_weight"`
+	// BidRateWeight favors bidders which recently bid most often in the auctions of the account.
+	BidRateWeight float64 `mapstructure:"bid_rate_weight"`
+	// PriorityWeight favors requested bidders, followed by the cooperative sync priority groups.
+	PriorityWeight float64 `mapstructure:"priority_weight"`
+	// RefreshWindowHours is how long before it expires a UID may be synced again.
+	RefreshWindowHours int `mapstructure:"refresh_window_hours"`
+	// BidRateWindowMinutes is the period over which the bid rates of the bidders are computed. Bid rates
+	// aren't tracked when 0.
+	BidRateWindowMinutes int `mapstructure:"bid_rate_window_minutes"`
+}
+
+func (cfg *UserSyncPrioritization) validate(errs []error) []error {
+	if cfg.ExpiryWeight < 0 {
+		errs = append(errs, fmt.Errorf("user_sync.prioritization.expiry_weight must be >= 0. Got %f", cfg.ExpiryWeight))
+	}
+	if cfg.BidRateWeight < 0 {
+		errs = append(errs, fmt.Errorf("user_sync.prioritization.bid_rate_weight must be >= 0. Got %f", cfg.BidRateWeight))
+	}
+	if cfg.PriorityWeight < 0 {
+		errs = append(errs, fmt.Errorf("user_sync.prioritization.priority_weight must be >= 0. Got %f", cfg.PriorityWeight))
+	}
+	if cfg.RefreshWindowHours < 0 {
+		errs = append(errs, fmt.Errorf("user_sync.prioritization.refresh_window_hours must be >= 0. Got %d", cfg.RefreshWindowHours))
+	}
+	if cfg.BidRateWindowMinutes < 0 {
+		errs = append(errs, fmt.Errorf("user_sync.prioritization.bid_rate_window_minutes must be >= 0. Got %d", cfg.BidRateWindowMinutes))
+	}
+	return errs
+}
+
+// UIDStore configures the server-side store of bidder UIDs. When a type is set, the uids cookie only
+// holds an identifier of the user in the store instead of the UIDs themselves.
+type UIDStore struct {
+	// Type is the store backend: "memory" or "database". The UIDs are kept in the cookie when empty.
+	Type     string           `mapstructure:"type"`
+	Memory   UIDStoreMemory   `mapstructure:"memory"`
+	Database UIDStoreDatabase `mapstructure:"database"`
+}
+
+const (
+	UIDStoreTypeMemory   = "memory"
+	UIDStoreTypeDatabase = "database"
+)
+
+// UIDStoreMemory configures the in-memory LRU store.
+type UIDStoreMemory struct {
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+// UIDStoreDatabase configures the database store. The queries may use the $ID, $UIDS, $EXPIRES and $NOW
+// parameters. Driver specific defaults using a uid_store table are used for the queries left empty.
+type UIDStoreDatabase struct {
+	ConnectionInfo DatabaseConnection `mapstructure:"connection"`
+	TimeoutMS      int                `mapstructure:"timeout_ms"`
+	GetQuery       string             `mapstructure:"get_query"`
+	SetQuery       string             `mapstructure:"set_query"`
+	DeleteQuery    string             `mapstructure:"delete_query"`
+}
+
+func (cfg *UIDStore) validate(errs []error) []error {
+	switch cfg.Type {
+	case "":
+	case UIDStoreTypeMemory:
+		if cfg.Memory.MaxEntries <= 0 {
+			errs = append(errs, fmt.Errorf("user_sync.uid_store.memory.max_entries must be > 0. Got %d", cfg.Memory.MaxEntries))
+		}
+	case UIDStoreTypeDatabase:
+		if cfg.Database.ConnectionInfo.Driver != "mysql" && cfg.Database.ConnectionInfo.Driver != "postgres" {
+			errs = append(errs, fmt.Errorf("user_sync.uid_store.database.connection.driver must be mysql or postgres. Got %s", cfg.Database.ConnectionInfo.Driver))
+		}
+		if cfg.Database.TimeoutMS <= 0 {
+			errs = append(errs, fmt.Errorf("user_sync.uid_store.database.timeout_ms must be > 0. Got %d", cfg.Database.TimeoutMS))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("user_sync.uid_store.type must be memory or database. Got %s", cfg.Type))
+	}
+	return errs
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUIDStoreValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      UIDStore
+		expErrors int
+	}{
+		{
+			desc:      "Cookie only",
+			data:      UIDStore{},
+			expErrors: 0,
+		},
+		{
+			desc:      "Unknown type",
+			data:      UIDStore{Type: "redis"},
+			expErrors: 1,
+		},
+		{
+			desc:      "Memory without entries",
+			data:      UIDStore{Type: UIDStoreTypeMemory},
+			expErrors: 1,
+		},
+		{
+			desc:      "Valid memory",
+			data:      UIDStore{Type: UIDStoreTypeMemory, Memory: UIDStoreMemory{MaxEntries: 10}},
+			expErrors: 0,
+		},
+		{
+			desc:      "Database without driver nor timeout",
+			data:      UIDStore{Type: UIDStoreTypeDatabase},
+			expErrors: 2,
+		},
+		{
+			desc:      "Valid database",
+			data:      UIDStore{Type: UIDStoreTypeDatabase, Database: UIDStoreDatabase{ConnectionInfo: DatabaseConnection{Driver: "postgres"}, TimeoutMS: 50}},
+			expErrors: 0,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
+
+func TestUserSyncPrioritizationValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		data      UserSyncPrioritization
+		expErrors int
+	}{
+		{
+			desc:      "Valid",
+			data:      UserSyncPrioritization{ExpiryWeight: 1, BidRateWeight: 0.5, PriorityWeight: 0, RefreshWindowHours: 72, BidRateWindowMinutes: 60},
+			expErrors: 0,
+		},
+		{
+			desc:      "Negative weights",
+			data:      UserSyncPrioritization{ExpiryWeight: -1, BidRateWeight: -1, PriorityWeight: -1, BidRateWindowMinutes: 60},
+			expErrors: 3,
+		},
+		{
+			desc:      "Invalid windows",
+			data:      UserSyncPrioritization{RefreshWindowHours: -1, BidRateWindowMinutes: -1},
+			expErrors: 2,
+		},
+	}
+	for _, test := range testCases {
+		errs := test.data.validate([]error{})
+		assert.Equal(t, test.expErrors, len(errs), "Test case threw unexpected number of errors. Desc: %s errMsg = %v \n", test.desc, errs)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
@@ -22,6 +23,7 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	gppPrivacy "github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
 )
@@ -32,6 +34,7 @@ var (
 	errCookieSyncGDPRConsentMissing                = errors.New("gdpr_consent is required if gdpr=1")
 	errCookieSyncGDPRConsentMissingSignalAmbiguous = errors.New("gdpr_consent is required. gdpr is not specified and is assumed to be 1 by the server. set gdpr=0 to exempt this request")
 	errCookieSyncInvalidBiddersType                = errors.New("invalid bidders type. must either be a string '*' or a string array of bidders")
+	errCookieSyncGPPSIDInvalid                     = errors.New("invalid gpp_sid. must be a comma separated list of integers")
 	errCookieSyncAccountBlocked                    = errors.New("account is disabled, please reach out to the prebid server host")
 	errCookieSyncAccountConfigMalformed            = errors.New("account config is malformed and could not be read")
 	errCookieSyncAccountInvalid                    = errors.New("account must be valid if provided, please reach out to the prebid server host")
@@ -47,7 +50,9 @@ func NewCookieSyncEndpoint(
 	metrics metrics.MetricsEngine,
 	pbsAnalytics analytics.PBSAnalyticsModule,
 	accountsFetcher stored_requests.AccountFetcher,
-	bidders map[string]openrtb_ext.BidderName) HTTPRouterHandler {
+	bidders map[string]openrtb_ext.BidderName,
+	cookies *usersync.Cookies,
+	bidRates *usersync.BidRates) HTTPRouterHandler {
 
 	bidderHashSet := make(map[string]struct{}, len(bidders))
 	for _, bidder := range bidders {
@@ -67,6 +72,8 @@ func NewCookieSyncEndpoint(
 		metrics:         metrics,
 		pbsAnalytics:    pbsAnalytics,
 		accountsFetcher: accountsFetcher,
+		cookies:         cookies,
+		bidRates:        bidRates,
 	}
 }
 
@@ -77,17 +84,19 @@ type cookieSyncEndpoint struct {
 	metrics         metrics.MetricsEngine
 	pbsAnalytics    analytics.PBSAnalyticsModule
 	accountsFetcher stored_requests.AccountFetcher
+	cookies         *usersync.Cookies
+	bidRates        *usersync.BidRates
 }
 
 func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	request, privacyPolicies, err := c.parseRequest(r)
+	request, privacyPolicies, requestInfo, err := c.parseRequest(r)
 	if err != nil {
 		c.writeParseRequestErrorMetrics(err)
 		c.handleError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	cookie := usersync.ParseCookieFromRequest(r, &c.config.HostCookie)
+	cookie := c.cookies.ParseCookieFromRequest(r, &c.config.HostCookie)
 
 	result := c.chooser.Choose(request, cookie)
 	switch result.Status {
@@ -96,24 +105,29 @@ func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ ht
 		c.handleError(w, errCookieSyncOptOut, http.StatusUnauthorized)
 	case usersync.StatusBlockedByGDPR:
 		c.metrics.RecordCookieSync(metrics.CookieSyncGDPRHostCookieBlocked)
-		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyPolicies, nil)
+		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyPolicies, requestInfo, nil)
 	case usersync.StatusOK:
 		c.metrics.RecordCookieSync(metrics.CookieSyncOK)
 		c.writeBidderMetrics(result.BiddersEvaluated)
-		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyPolicies, result.SyncersChosen)
+		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyPolicies, requestInfo, result.SyncersChosen)
 	}
 }
 
-func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, privacy.Policies, error) {
+func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, privacy.Policies, usersync.SyncRequestInfo, error) {
 	defer r.Body.Close()
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		return usersync.Request{}, privacy.Policies{}, errCookieSyncBody
+		return usersync.Request{}, privacy.Policies{}, usersync.SyncRequestInfo{}, errCookieSyncBody
 	}
 
 	request := cookieSyncRequest{}
 	if err := json.Unmarshal(body, &request); err != nil {
-		return usersync.Request{}, privacy.Policies{}, fmt.Errorf("JSON parsing failed: %s", err.Error())
+		return usersync.Request{}, privacy.Policies{}, usersync.SyncRequestInfo{}, fmt.Errorf("JSON parsing failed: %s", err.Error())
+	}
+
+	requestInfo := usersync.SyncRequestInfo{
+		AccountID:    request.Account,
+		HostCookieID: c.hostCookieID(r),
 	}
 
 	if request.Account == "" {
@@ -121,7 +135,7 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 	}
 	account, fetchErrs := accountService.GetAccount(context.Background(), c.config, c.accountsFetcher, request.Account)
 	if len(fetchErrs) > 0 {
-		return usersync.Request{}, privacy.Policies{}, combineErrors(fetchErrs)
+		return usersync.Request{}, privacy.Policies{}, usersync.SyncRequestInfo{}, combineErrors(fetchErrs)
 	}
 
 	var gdprString string
@@ -130,19 +144,23 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 	}
 	gdprSignal, err := gdpr.SignalParse(gdprString)
 	if err != nil {
-		return usersync.Request{}, privacy.Policies{}, err
+		return usersync.Request{}, privacy.Policies{}, usersync.SyncRequestInfo{}, err
 	}
 
 	if request.GDPRConsent == "" {
 		if gdprSignal == gdpr.SignalYes {
-			return usersync.Request{}, privacy.Policies{}, errCookieSyncGDPRConsentMissing
+			return usersync.Request{}, privacy.Policies{}, usersync.SyncRequestInfo{}, errCookieSyncGDPRConsentMissing
 		}
 
 		if gdprSignal == gdpr.SignalAmbiguous && gdpr.SignalNormalize(gdprSignal, c.privacyConfig.gdprConfig.DefaultValue) == gdpr.SignalYes {
-			return usersync.Request{}, privacy.Policies{}, errCookieSyncGDPRConsentMissingSignalAmbiguous
+			return usersync.Request{}, privacy.Policies{}, usersync.SyncRequestInfo{}, errCookieSyncGDPRConsentMissingSignalAmbiguous
 		}
 	}
 
+	if !gppPrivacy.ValidateSID(request.GPPSID) {
+		return usersync.Request{}, privacy.Policies{}, usersync.SyncRequestInfo{}, errCookieSyncGPPSIDInvalid
+	}
+
 	request = c.setLimit(request, account.CookieSync)
 	request = c.setCooperativeSync(request, account.CookieSync)
 
@@ -154,6 +172,10 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 		CCPA: ccpa.Policy{
 			Consent: request.USPrivacy,
 		},
+		GPP: gppPrivacy.Policy{
+			Consent: request.GPP,
+			SID:     request.GPPSID,
+		},
 	}
 
 	ccpaParsedPolicy := ccpa.ParsedPolicy{}
@@ -169,7 +191,7 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 
 	syncTypeFilter, err := parseTypeFilter(request.FilterSettings)
 	if err != nil {
-		return usersync.Request{}, privacy.Policies{}, err
+		return usersync.Request{}, privacy.Policies{}, usersync.SyncRequestInfo{}, err
 	}
 
 	gdprRequestInfo := gdpr.RequestInfo{
@@ -192,8 +214,20 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 			ccpaParsedPolicy: ccpaParsedPolicy,
 		},
 		SyncTypeFilter: syncTypeFilter,
+		Prioritization: c.prioritization(account.ID, account.CookieSync),
+	}
+	return rx, privacyPolicies, requestInfo, nil
+}
+
+// hostCookieID returns the user id of the host cookie, or an empty string if there is none.
+func (c *cookieSyncEndpoint) hostCookieID(r *http.Request) string {
+	if c.config.HostCookie.CookieName == "" {
+		return ""
+	}
+	if hostCookie, err := r.Cookie(c.config.HostCookie.CookieName); err == nil {
+		return hostCookie.Value
 	}
-	return rx, privacyPolicies, nil
+	return ""
 }
 
 func (c *cookieSyncEndpoint) writeParseRequestErrorMetrics(err error) {
@@ -231,6 +265,24 @@ func (c *cookieSyncEndpoint) setCooperativeSync(request cookieSyncRequest, cooki
 	return request
 }
 
+// prioritization returns how to order the bidders to sync for accounts using the prioritized strategy,
+// and nil for the others.
+func (c *cookieSyncEndpoint) prioritization(accountID string, cookieSyncConfig config.CookieSync) *usersync.Prioritization {
+	if cookieSyncConfig.Strategy != config.CookieSyncStrategyPrioritized {
+		return nil
+	}
+
+	cfg := c.config.UserSync.Prioritization
+	return &usersync.Prioritization{
+		Account:        accountID,
+		ExpiryWeight:   cfg.ExpiryWeight,
+		BidRateWeight:  cfg.BidRateWeight,
+		PriorityWeight: cfg.PriorityWeight,
+		RefreshWindow:  time.Duration(cfg.RefreshWindowHours) * time.Hour,
+		BidRates:       c.bidRates,
+	}
+}
+
 func parseTypeFilter(request *cookieSyncRequestFilterSettings) (usersync.SyncTypeFilter, error) {
 	syncTypeFilter := usersync.SyncTypeFilter{
 		IFrame:   cookieSyncBidderFilterAllowAll,
@@ -335,7 +387,7 @@ func (c *cookieSyncEndpoint) writeBidderMetrics(biddersEvaluated []usersync.Bidd
 	}
 }
 
-func (c *cookieSyncEndpoint) handleResponse(w http.ResponseWriter, tf usersync.SyncTypeFilter, co *usersync.Cookie, p privacy.Policies, s []usersync.SyncerChoice) {
+func (c *cookieSyncEndpoint) handleResponse(w http.ResponseWriter, tf usersync.SyncTypeFilter, co *usersync.Cookie, p privacy.Policies, ri usersync.SyncRequestInfo, s []usersync.SyncerChoice) {
 	status := "no_cookie"
 	if co.HasAnyLiveSyncs() {
 		status = "ok"
@@ -348,7 +400,7 @@ func (c *cookieSyncEndpoint) handleResponse(w http.ResponseWriter, tf usersync.S
 
 	for _, syncerChoice := range s {
 		syncTypes := tf.ForBidder(syncerChoice.Bidder)
-		sync, err := syncerChoice.Syncer.GetSync(syncTypes, p)
+		sync, err := syncerChoice.Syncer.GetSync(syncTypes, p, ri)
 		if err != nil {
 			glog.Errorf("Failed to get usersync info for %s: %v", syncerChoice.Bidder, err)
 			continue
@@ -397,6 +449,8 @@ type cookieSyncRequest struct {
 	GDPR            *int                             `json:"gdpr"`
 	GDPRConsent     string                           `json:"gdpr_consent"`
 	USPrivacy       string                           `json:"us_privacy"`
+	GPP             string                           `json:"gpp"`
+	GPPSID          string                           `json:"gpp_sid"`
 	Limit           int                              `json:"limit"`
 	CooperativeSync *bool                            `json:"coopSync"`
 	FilterSettings  *cookieSyncRequestFilterSettings `json:"filterSettings"`
@@ -21,6 +21,7 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	gppPrivacy "github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/usersync"
 
 	"github.com/stretchr/testify/assert"
@@ -60,6 +61,8 @@ func TestNewCookieSyncEndpoint(t *testing.T) {
 		&analytics,
 		&fetcher,
 		bidders,
+		nil,
+		nil,
 	)
 	result := endpoint.(*cookieSyncEndpoint)
 
@@ -101,7 +104,7 @@ func TestCookieSyncHandle(t *testing.T) {
 	syncTypeExpected := []usersync.SyncType{usersync.SyncTypeIFrame, usersync.SyncTypeRedirect}
 	sync := usersync.Sync{URL: "aURL", Type: usersync.SyncTypeRedirect, SupportCORS: true}
 	syncer := MockSyncer{}
-	syncer.On("GetSync", syncTypeExpected, privacy.Policies{}).Return(sync, nil).Maybe()
+	syncer.On("GetSync", syncTypeExpected, privacy.Policies{}, usersync.SyncRequestInfo{}).Return(sync, nil).Maybe()
 
 	cookieWithSyncs := usersync.NewCookie()
 	cookieWithSyncs.TrySync("foo", "anyID")
@@ -313,9 +316,11 @@ func TestCookieSyncParseRequest(t *testing.T) {
 		givenGDPRConfig      config.GDPR
 		givenCCPAEnabled     bool
 		givenAccountRequired bool
+		givenHostCookieID    string
 		expectedError        string
 		expectedPrivacy      privacy.Policies
 		expectedRequest      usersync.Request
+		expectedRequestInfo  usersync.SyncRequestInfo
 	}{
 		{
 			description: "Complete Request",
@@ -324,6 +329,8 @@ func TestCookieSyncParseRequest(t *testing.T) {
 				`"gdpr":1,` +
 				`"gdpr_consent":"anyGDPRConsent",` +
 				`"us_privacy":"1NYN",` +
+				`"gpp":"anyGPPConsent",` +
+				`"gpp_sid":"2,6",` +
 				`"limit":42,` +
 				`"coopSync":true,` +
 				`"filterSettings":{"iframe":{"bidders":"*","filter":"include"}, "image":{"bidders":["b"],"filter":"exclude"}}` +
@@ -344,6 +351,10 @@ func TestCookieSyncParseRequest(t *testing.T) {
 				CCPA: ccpa.Policy{
 					Consent: "1NYN",
 				},
+				GPP: gppPrivacy.Policy{
+					Consent: "anyGPPConsent",
+					SID:     "2,6",
+				},
 			},
 			expectedRequest: usersync.Request{
 				Bidders: []string{"a", "b"},
@@ -405,6 +416,33 @@ func TestCookieSyncParseRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			description:       "Host Cookie",
+			givenBody:         strings.NewReader(`{}`),
+			givenGDPRConfig:   config.GDPR{Enabled: true, DefaultValue: "0"},
+			givenCCPAEnabled:  true,
+			givenHostCookieID: "anyHostCookieID",
+			expectedPrivacy:   privacy.Policies{},
+			expectedRequest: usersync.Request{
+				Privacy: usersyncPrivacy{
+					gdprPermissions: &fakePermissions{},
+				},
+				SyncTypeFilter: usersync.SyncTypeFilter{
+					IFrame:   usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
+					Redirect: usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
+				},
+			},
+			expectedRequestInfo: usersync.SyncRequestInfo{
+				HostCookieID: "anyHostCookieID",
+			},
+		},
+		{
+			description:      "Invalid GPP SID",
+			givenBody:        strings.NewReader(`{"gpp":"anyGPPConsent","gpp_sid":"2,a"}`),
+			givenGDPRConfig:  config.GDPR{Enabled: true, DefaultValue: "0"},
+			givenCCPAEnabled: true,
+			expectedError:    "invalid gpp_sid. must be a comma separated list of integers",
+		},
 		{
 			description:      "Empty Request",
 			givenBody:        strings.NewReader(`{}`),
@@ -720,6 +758,9 @@ func TestCookieSyncParseRequest(t *testing.T) {
 					Redirect: usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
 				},
 			},
+			expectedRequestInfo: usersync.SyncRequestInfo{
+				AccountID: "TestAccount",
+			},
 		},
 		{
 			description: "Account Defaults - DefaultLimit",
@@ -751,6 +792,9 @@ func TestCookieSyncParseRequest(t *testing.T) {
 					Redirect: usersync.NewUniformBidderFilter(usersync.BidderFilterModeInclude),
 				},
 			},
+			expectedRequestInfo: usersync.SyncRequestInfo{
+				AccountID: "TestAccount",
+			},
 		},
 		{
 			description: "Account Defaults - Error",
@@ -789,6 +833,9 @@ func TestCookieSyncParseRequest(t *testing.T) {
 
 	for _, test := range testCases {
 		httpRequest := httptest.NewRequest("POST", "/cookiesync", test.givenBody)
+		if test.givenHostCookieID != "" {
+			httpRequest.AddCookie(&http.Cookie{Name: "hostCookie", Value: test.givenHostCookieID})
+		}
 
 		gdprPermsBuilder := fakePermissionsBuilder{
 			permissions: &fakePermissions{},
@@ -801,6 +848,7 @@ func TestCookieSyncParseRequest(t *testing.T) {
 			config: &config.Configuration{
 				UserSync:        test.givenConfig,
 				AccountRequired: test.givenAccountRequired,
+				HostCookie:      config.HostCookie{CookieName: "hostCookie"},
 			},
 			privacyConfig: usersyncPrivacyConfig{
 				gdprConfig:             test.givenGDPRConfig,
@@ -814,16 +862,18 @@ func TestCookieSyncParseRequest(t *testing.T) {
 			}},
 		}
 		assert.NoError(t, endpoint.config.MarshalAccountDefaults())
-		request, privacyPolicies, err := endpoint.parseRequest(httpRequest)
+		request, privacyPolicies, requestInfo, err := endpoint.parseRequest(httpRequest)
 
 		if test.expectedError == "" {
 			assert.NoError(t, err, test.description+":err")
 			assert.Equal(t, test.expectedRequest, request, test.description+":request")
 			assert.Equal(t, test.expectedPrivacy, privacyPolicies, test.description+":privacy")
+			assert.Equal(t, test.expectedRequestInfo, requestInfo, test.description+":request_info")
 		} else {
 			assert.EqualError(t, err, test.expectedError, test.description+":err")
 			assert.Empty(t, request, test.description+":request")
 			assert.Empty(t, privacyPolicies, test.description+":privacy")
+			assert.Empty(t, requestInfo, test.description+":request_info")
 		}
 	}
 }
@@ -1052,6 +1102,46 @@ func TestSetCooperativeSync(t *testing.T) {
 	}
 }
 
+func TestCookieSyncPrioritization(t *testing.T) {
+	hostConfig := config.UserSyncPrioritization{ExpiryWeight: 1, BidRateWeight: 2, PriorityWeight: 3, RefreshWindowHours: 24}
+	bidRates := usersync.NewBidRates(time.Hour)
+
+	testCases := []struct {
+		description    string
+		givenStrategy  string
+		expectedResult *usersync.Prioritization
+	}{
+		{
+			description:    "Default strategy",
+			givenStrategy:  "",
+			expectedResult: nil,
+		},
+		{
+			description:    "Standard strategy",
+			givenStrategy:  config.CookieSyncStrategyStandard,
+			expectedResult: nil,
+		},
+		{
+			description:   "Prioritized strategy",
+			givenStrategy: config.CookieSyncStrategyPrioritized,
+			expectedResult: &usersync.Prioritization{
+				Account:        "anyAccount",
+				ExpiryWeight:   1,
+				BidRateWeight:  2,
+				PriorityWeight: 3,
+				RefreshWindow:  24 * time.Hour,
+				BidRates:       bidRates,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		endpoint := cookieSyncEndpoint{config: &config.Configuration{UserSync: config.UserSync{Prioritization: hostConfig}}, bidRates: bidRates}
+		result := endpoint.prioritization("anyAccount", config.CookieSync{Strategy: test.givenStrategy})
+		assert.Equal(t, test.expectedResult, result, test.description)
+	}
+}
+
 func TestWriteParseRequestErrorMetrics(t *testing.T) {
 	err := errors.New("anyError")
 
@@ -1372,20 +1462,21 @@ func TestCookieSyncHandleResponse(t *testing.T) {
 	}
 	syncTypeExpected := []usersync.SyncType{usersync.SyncTypeRedirect}
 	privacyPolicies := privacy.Policies{CCPA: ccpa.Policy{Consent: "anyConsent"}}
+	requestInfo := usersync.SyncRequestInfo{AccountID: "anyAccount"}
 
 	// The & in the URL is necessary to test proper JSON encoding.
 	syncA := usersync.Sync{URL: "https://syncA.com/sync?a=1&b=2", Type: usersync.SyncTypeRedirect, SupportCORS: true}
 	syncerA := MockSyncer{}
-	syncerA.On("GetSync", syncTypeExpected, privacyPolicies).Return(syncA, nil).Maybe()
+	syncerA.On("GetSync", syncTypeExpected, privacyPolicies, requestInfo).Return(syncA, nil).Maybe()
 
 	// The & in the URL is necessary to test proper JSON encoding.
 	syncB := usersync.Sync{URL: "https://syncB.com/sync?a=1&b=2", Type: usersync.SyncTypeRedirect, SupportCORS: false}
 	syncerB := MockSyncer{}
-	syncerB.On("GetSync", syncTypeExpected, privacyPolicies).Return(syncB, nil).Maybe()
+	syncerB.On("GetSync", syncTypeExpected, privacyPolicies, requestInfo).Return(syncB, nil).Maybe()
 
 	syncWithError := usersync.Sync{}
 	syncerWithError := MockSyncer{}
-	syncerWithError.On("GetSync", syncTypeExpected, privacyPolicies).Return(syncWithError, errors.New("anyError")).Maybe()
+	syncerWithError.On("GetSync", syncTypeExpected, privacyPolicies, requestInfo).Return(syncWithError, errors.New("anyError")).Maybe()
 
 	testCases := []struct {
 		description         string
@@ -1483,7 +1574,7 @@ func TestCookieSyncHandleResponse(t *testing.T) {
 
 		writer := httptest.NewRecorder()
 		endpoint := cookieSyncEndpoint{pbsAnalytics: &mockAnalytics}
-		endpoint.handleResponse(writer, syncTypeFilter, cookie, privacyPolicies, test.givenSyncersChosen)
+		endpoint.handleResponse(writer, syncTypeFilter, cookie, privacyPolicies, requestInfo, test.givenSyncersChosen)
 
 		if assert.Equal(t, writer.Code, http.StatusOK, test.description+":http_status") {
 			assert.Equal(t, writer.Header().Get("Content-Type"), "application/json; charset=utf-8", test.description+":http_header")
@@ -1759,8 +1850,8 @@ func (m *MockSyncer) SupportsType(syncTypes []usersync.SyncType) bool {
 	return args.Bool(0)
 }
 
-func (m *MockSyncer) GetSync(syncTypes []usersync.SyncType, privacyPolicies privacy.Policies) (usersync.Sync, error) {
-	args := m.Called(syncTypes, privacyPolicies)
+func (m *MockSyncer) GetSync(syncTypes []usersync.SyncType, privacyPolicies privacy.Policies, requestInfo usersync.SyncRequestInfo) (usersync.Sync, error) {
+	args := m.Called(syncTypes, privacyPolicies, requestInfo)
 	return args.Get(0).(usersync.Sync), args.Error(1)
 }
 
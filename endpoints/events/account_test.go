@@ -123,7 +123,7 @@ func TestHandleAccountServiceErrors(t *testing.T) {
 			h    httprouter.Handle
 			r    *http.Request
 		}{
-			vast(t, test.cfg, test.fetcher, test.accountID),
+			vtrack(t, test.cfg, test.fetcher, test.accountID),
 			event(test.cfg, test.fetcher, test.accountID),
 		}
 
@@ -164,7 +164,7 @@ func event(cfg *config.Configuration, fetcher stored_requests.AccountFetcher, ac
 	}
 }
 
-func vast(t *testing.T, cfg *config.Configuration, fetcher stored_requests.AccountFetcher, accountID string) struct {
+func vtrack(t *testing.T, cfg *config.Configuration, fetcher stored_requests.AccountFetcher, accountID string) struct {
 	name string
 	h    httprouter.Handle
 	r    *http.Request
@@ -75,6 +75,8 @@ var mockAccountData = map[string]json.RawMessage{
 	"events_enabled":  json.RawMessage(`{"events_enabled":true}`),
 	"events_disabled": json.RawMessage(`{"events_enabled":false}`),
 	"malformed_acct":  json.RawMessage(`{"events_enabled":"invalid type"}`),
+	"vast_events_enabled": json.RawMessage(`{"events":{"enabled":true,"default_url":"https://pbs.com/event?t=##PBS-EVENTTYPE##&b=##PBS-BIDID##&a=##PBS-ACCOUNTID##&bidder=##PBS-BIDDER##",` +
+		`"vast_events":[{"create_element":"impression"}]}}`),
 }
 
 type mockAccountsFetcher struct {
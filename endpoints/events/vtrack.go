@@ -17,6 +17,7 @@ import (
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/vast"
 )
 
 const (
@@ -102,6 +103,12 @@ func (v *vtrackEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httpro
 		return
 	}
 
+	// validate the VAST and insert the trackers configured by the account VAST events
+	biddersAllowingVastUpdate := getBiddersAllowingVastUpdate(req, &v.BidderInfos, v.Cfg.VTrack.AllowUnknownBidder)
+	if errs := processVTrackVAST(req, biddersAllowingVastUpdate, account, integrationType); len(errs) > 0 {
+		glog.Warningf("Account %s sent puts with invalid VAST: %v", account.ID, errs)
+	}
+
 	// insert impression tracking if account allows events and bidder allows VAST modification
 	if v.Cache != nil {
 		cachingResponse, errs := v.handleVTrackRequest(ctx, req, account, integrationType)
@@ -247,6 +254,53 @@ func (v *vtrackEndpoint) cachePutObjects(ctx context.Context, req *BidCacheReque
 	return v.Cache.PutJson(ctx, cacheables)
 }
 
+// processVTrackVAST validates the XML puts of the bidders allowing VAST modification and injects the
+// account VAST event trackers when VAST events are enabled for the account. A put which doesn't hold
+// valid VAST is cached as it is, and its error is returned.
+func processVTrackVAST(req *BidCacheRequest, biddersAllowingVastUpdate map[string]struct{}, account *config.Account, integration string) []error {
+	if !account.Events.Enabled {
+		return nil
+	}
+
+	var errs []error
+	for i, c := range req.Puts {
+		if c.Type != prebid_cache_client.TypeXML || c.Data == nil {
+			continue
+		}
+		if _, ok := biddersAllowingVastUpdate[c.Bidder]; !ok {
+			continue
+		}
+
+		var vastXML string
+		if err := json.Unmarshal(c.Data, &vastXML); err != nil {
+			// failed to decode json, fall back to string
+			vastXML = string(c.Data)
+		}
+
+		vastXML, err := vast.Process(vastXML, account.Events, vast.MacroValues{
+			BidID:       c.BidID,
+			AccountID:   account.ID,
+			Bidder:      c.Bidder,
+			Integration: integration,
+			MediaType:   "video",
+			Timestamp:   c.Timestamp,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("puts[%d] has invalid VAST: %v", i, err))
+			continue
+		}
+
+		data, err := json.Marshal(vastXML)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("puts[%d]: %v", i, err))
+			continue
+		}
+		req.Puts[i].Data = data
+	}
+
+	return errs
+}
+
 // getBiddersAllowingVastUpdate returns a list of bidders that allow VAST XML modification
 func getBiddersAllowingVastUpdate(req *BidCacheRequest, bidderInfos *config.BidderInfos, allowUnknownBidder bool) map[string]struct{} {
 	bl := map[string]struct{}{}
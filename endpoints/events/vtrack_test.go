@@ -28,12 +28,14 @@ const (
 
 // Mock pbs cache client
 type vtrackMockCacheClient struct {
-	Fail  bool
-	Error error
-	Uuids []string
+	Fail   bool
+	Error  error
+	Uuids  []string
+	Values []prebid_cache_client.Cacheable
 }
 
 func (m *vtrackMockCacheClient) PutJson(ctx context.Context, values []prebid_cache_client.Cacheable) ([]string, []error) {
+	m.Values = values
 	if m.Fail {
 		return []string{}, []error{m.Error}
 	}
@@ -535,6 +537,96 @@ func TestShouldSendToCacheExpectedPutsAndUpdatableUnknownBiddersWhenUnknownBidde
 	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
 }
 
+func TestShouldInjectVASTEventTrackersWhenEnabledForAccount(t *testing.T) {
+	// mock pbs cache client
+	mockCacheClient := &vtrackMockCacheClient{
+		Fail:  false,
+		Uuids: []string{"uuid1", "uuid2"},
+	}
+
+	// config
+	cfg := &config.Configuration{
+		MaxRequestSize: maxSize, VTrack: config.VTrack{
+			TimeoutMS: int64(2000), AllowUnknownBidder: false,
+		},
+		AccountDefaults: config.Account{},
+	}
+	cfg.MarshalAccountDefaults()
+
+	// prepare
+	data, err := getValidVTrackRequestBody(true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/vtrack?a=vast_events_enabled", strings.NewReader(data))
+
+	recorder := httptest.NewRecorder()
+
+	e := vtrackEndpoint{
+		Cfg:         cfg,
+		BidderInfos: config.BidderInfos{"bidder": config.BidderInfo{ModifyingVastXmlAllowed: true}},
+		Cache:       mockCacheClient,
+		Accounts:    &mockAccountsFetcher{},
+	}
+
+	// execute
+	e.Handle(recorder, req, nil)
+
+	// validate
+	assert.Equal(t, 200, recorder.Result().StatusCode, "Expected 200 when the VAST is valid")
+	if assert.Len(t, mockCacheClient.Values, 2) {
+		assert.Equal(t, strings.Replace(vastXmlWithImpressionWithContent, "<Creatives>",
+			"<Impression><![CDATA[/event?t=imp&b=bidId1&a=vast_events_enabled&bidder=bidder&f=b&ts=1000]]></Impression>"+
+				"<Impression><![CDATA[https://pbs.com/event?t=impression&b=bidId1&a=vast_events_enabled&bidder=bidder]]></Impression><Creatives>", 1),
+			string(mockCacheClient.Values[0].Data))
+		assert.Equal(t, vastXmlWithImpressionWithContent, vastXMLFromPut(t, mockCacheClient.Values[1].Data), "Expected the VAST of a bidder not allowing VAST modification to be left untouched")
+	}
+}
+
+func TestShouldCacheInvalidVASTUnmodified(t *testing.T) {
+	// mock pbs cache client
+	mockCacheClient := &vtrackMockCacheClient{}
+
+	// config
+	cfg := &config.Configuration{
+		MaxRequestSize: maxSize, VTrack: config.VTrack{
+			TimeoutMS: int64(2000), AllowUnknownBidder: false,
+		},
+		AccountDefaults: config.Account{},
+	}
+	cfg.MarshalAccountDefaults()
+
+	// prepare
+	data := `{"puts":[{"type":"xml","bidid":"bidId1","bidder":"bidder","value":"<VAST version=\"3.0\"><Ad></Ad></VAST>"}]}`
+
+	req := httptest.NewRequest("POST", "/vtrack?a=vast_events_enabled", strings.NewReader(data))
+
+	recorder := httptest.NewRecorder()
+
+	e := vtrackEndpoint{
+		Cfg:         cfg,
+		BidderInfos: config.BidderInfos{"bidder": config.BidderInfo{ModifyingVastXmlAllowed: true}},
+		Cache:       mockCacheClient,
+		Accounts:    &mockAccountsFetcher{},
+	}
+
+	// execute
+	e.Handle(recorder, req, nil)
+
+	d, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// validate
+	assert.Equal(t, 200, recorder.Result().StatusCode, "Expected 200 when the VAST is invalid")
+	assert.Equal(t, `{"responses":[]}`, string(d))
+	if assert.Len(t, mockCacheClient.Values, 1) {
+		assert.Equal(t, `"<VAST version=\"3.0\"><Ad></Ad></VAST>"`, string(mockCacheClient.Values[0].Data), "Expected the invalid VAST to be cached unmodified")
+	}
+}
+
 func TestShouldReturnBadRequestWhenRequestExceedsMaxRequestSize(t *testing.T) {
 	// mock pbs cache client
 	mockCacheClient := &vtrackMockCacheClient{
@@ -639,6 +731,12 @@ func TestVastUrlShouldReturnExpectedUrl(t *testing.T) {
 	assert.Equal(t, "http://external-url/event?t=imp&b=bidId&a=accountId&bidder=bidder&f=b&int=integrationType&ts=1000", url, "Invalid vast url")
 }
 
+func vastXMLFromPut(t *testing.T, data json.RawMessage) string {
+	var vastXML string
+	assert.NoError(t, json.Unmarshal(data, &vastXML))
+	return vastXML
+}
+
 func getValidVTrackRequestBody(withImpression bool, withContent bool) (string, error) {
 	d, e := getVTrackRequestData(withImpression, withContent)
 
@@ -16,9 +16,9 @@ type userSyncs struct {
 
 // NewGetUIDsEndpoint implements the /getuid endpoint which
 // returns all the existing syncs for the user
-func NewGetUIDsEndpoint(cfg config.HostCookie) httprouter.Handle {
+func NewGetUIDsEndpoint(cfg config.HostCookie, cookies *usersync.Cookies) httprouter.Handle {
 	return httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-		pc := usersync.ParseCookieFromRequest(r, &cfg)
+		pc := cookies.ParseCookieFromRequest(r, &cfg)
 		userSyncs := new(userSyncs)
 		userSyncs.BuyerUIDs = pc.GetUIDs()
 		json.NewEncoder(w).Encode(userSyncs)
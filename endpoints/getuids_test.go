@@ -11,7 +11,7 @@ import (
 
 func TestGetUIDs(t *testing.T) {
 	req := makeRequest("/getuids", map[string]string{"adnxs": "123", "audienceNetwork": "456"})
-	endpoint := NewGetUIDsEndpoint(config.HostCookie{})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, nil)
 	res := httptest.NewRecorder()
 	endpoint(res, req, nil)
 
@@ -22,7 +22,7 @@ func TestGetUIDs(t *testing.T) {
 
 func TestGetUIDsWithNoSyncs(t *testing.T) {
 	req := makeRequest("/getuids", map[string]string{})
-	endpoint := NewGetUIDsEndpoint(config.HostCookie{})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, nil)
 	res := httptest.NewRecorder()
 	endpoint(res, req, nil)
 
@@ -32,7 +32,7 @@ func TestGetUIDsWithNoSyncs(t *testing.T) {
 
 func TestGetUIDWIthNoCookie(t *testing.T) {
 	req := httptest.NewRequest("GET", "/getuids", nil)
-	endpoint := NewGetUIDsEndpoint(config.HostCookie{})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, nil)
 	res := httptest.NewRecorder()
 	endpoint(res, req, nil)
 
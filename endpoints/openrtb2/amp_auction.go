@@ -17,6 +17,7 @@ import (
 	"github.com/prebid/openrtb/v17/openrtb2"
 	"github.com/prebid/openrtb/v17/openrtb3"
 	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/util/uuidutil"
 	jsonpatch "gopkg.in/evanphx/json-patch.v4"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/stored_responses"
+	"github.com/prebid/prebid-server/throttling"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/iputil"
 	"github.com/prebid/prebid-server/version"
@@ -66,6 +68,9 @@ func NewAmpEndpoint(
 	bidderMap map[string]openrtb_ext.BidderName,
 	storedRespFetcher stored_requests.Fetcher,
 	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
+	cookies *usersync.Cookies,
+	throttler *throttling.Throttler,
+	loggers *logger.Factory,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || metricsEngine == nil {
@@ -99,7 +104,10 @@ func NewAmpEndpoint(
 		nil,
 		ipValidator,
 		storedRespFetcher,
-		hookExecutor}).AmpAuction), nil
+		hookExecutor,
+		cookies,
+		throttler,
+		loggers}).AmpAuction), nil
 
 }
 
@@ -148,8 +156,22 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	w.Header().Set("Access-Control-Expose-Headers", "AMP-Access-Control-Allow-Source-Origin")
 	w.Header().Set("X-Prebid", version.BuildXPrebidHeader(version.Ver))
 
+	log := deps.loggers.New(hookexecution.EndpointAmp, r)
+	timeline := exchange.NewTimeline(start)
+	r = r.WithContext(exchange.NewTimelineContext(logger.NewContext(r.Context(), log), timeline))
+
 	// There is no body for AMP requests, so we pass a nil body and ignore the return value.
+	deps.hookExecutor.SetLogger(log)
 	_, rejectErr := deps.hookExecutor.ExecuteEntrypointStage(r, nilBody)
+
+	// Shed the load before the stored request and the account are fetched. The account isn't known yet.
+	admission := deps.throttler.AdmitLoad(labels.PubID)
+	defer admission.Done()
+	if admission.Rejected {
+		labels, ao = rejectThrottledAmpRequest(admission, w, deps.hookExecutor, nil, nil, labels, ao, nil)
+		return
+	}
+
 	reqWrapper, storedAuctionResponses, storedBidResponses, bidderImpReplaceImp, errL := deps.parseAmpRequest(r)
 	ao.Errors = append(ao.Errors, errL...)
 	// Process reject after parsing amp request, so we can use reqWrapper.
@@ -170,7 +192,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 
 	ao.Request = reqWrapper.BidRequest
 
-	ctx := context.Background()
+	ctx := exchange.NewTimelineContext(logger.NewContext(context.Background(), log), timeline)
 	var cancel context.CancelFunc
 	if reqWrapper.TMax > 0 {
 		ctx, cancel = context.WithDeadline(ctx, start.Add(time.Duration(reqWrapper.TMax)*time.Millisecond))
@@ -179,7 +201,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	}
 	defer cancel()
 
-	usersyncs := usersync.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
+	usersyncs := deps.cookies.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
 	if usersyncs.HasAnyLiveSyncs() {
 		labels.CookieFlag = metrics.CookieFlagYes
 	} else {
@@ -187,7 +209,9 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	}
 	labels.PubID = getAccountID(reqWrapper.Site.Publisher)
 	// Look up account now that we have resolved the pubID value
+	fetchStart := time.Now()
 	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
+	timeline.Record(exchange.TraceStepAccountFetch, "", fetchStart, accountFetchDetails(labels.PubID, acctIDErrs))
 	if len(acctIDErrs) > 0 {
 		// best attempt to rebuild the request for analytics. we're already in an error state, so ignoring a
 		// potential error from this call
@@ -218,6 +242,32 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		return
 	}
 
+	tmax := reqWrapper.TMax
+	policyErrs := deps.applyRequestPolicy(reqWrapper, account.RequestPolicy, labels.PubID)
+	errL = append(errL, policyErrs...)
+	ao.Errors = append(ao.Errors, policyErrs...)
+	if errortypes.ContainsFatalError(policyErrs) {
+		w.WriteHeader(http.StatusBadRequest)
+		for _, err := range errortypes.FatalOnly(policyErrs) {
+			w.Write([]byte(fmt.Sprintf("Invalid request: %s\n", err.Error())))
+		}
+		labels.RequestStatus = metrics.RequestStatusBadInput
+		return
+	}
+	if reqWrapper.TMax != tmax {
+		ctx, cancel = context.WithDeadline(ctx, start.Add(time.Duration(reqWrapper.TMax)*time.Millisecond))
+		defer cancel()
+	}
+
+	admission = deps.throttler.AdmitAccount(admission, labels.PubID, account.RateLimit)
+	if admission.Rejected {
+		labels, ao = rejectThrottledAmpRequest(admission, w, deps.hookExecutor, reqWrapper, account, labels, ao, errL)
+		return
+	}
+
+	log = log.WithAccount(account.ID)
+	ctx = logger.NewContext(ctx, log)
+
 	secGPC := r.Header.Get("Sec-GPC")
 
 	auctionRequest := exchange.AuctionRequest{
@@ -233,6 +283,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		BidderImpReplaceImpID:      bidderImpReplaceImp,
 		PubID:                      labels.PubID,
 		HookExecutor:               deps.hookExecutor,
+		MaxBidders:                 admission.MaxBidders,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
@@ -241,7 +292,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	if err != nil && !isRejectErr {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Critical error while running the auction: %v", err)
-		glog.Errorf("/openrtb2/amp Critical error: %v", err)
+		log.Errorf("/openrtb2/amp Critical error: %v", err)
 		ao.Status = http.StatusInternalServerError
 		ao.Errors = append(ao.Errors, err)
 		return
@@ -252,7 +303,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	if err := reqWrapper.RebuildRequest(); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Critical error while running the auction: %v", err)
-		glog.Errorf("/openrtb2/amp Critical error: %v", err)
+		log.Errorf("/openrtb2/amp Critical error: %v", err)
 		ao.Status = http.StatusInternalServerError
 		ao.Errors = append(ao.Errors, err)
 		return
@@ -283,6 +334,26 @@ func rejectAmpRequest(
 	return sendAmpResponse(w, hookExecutor, response, reqWrapper, account, labels, ao, errs)
 }
 
+func rejectThrottledAmpRequest(
+	admission throttling.Admission,
+	w http.ResponseWriter,
+	hookExecutor hookexecution.HookStageExecutor,
+	reqWrapper *openrtb_ext.RequestWrapper,
+	account *config.Account,
+	labels metrics.Labels,
+	ao analytics.AmpObject,
+	errs []error,
+) (metrics.Labels, analytics.AmpObject) {
+	response := &openrtb2.BidResponse{NBR: throttling.NoBidReason.Ptr()}
+	if reqWrapper != nil {
+		response.ID = reqWrapper.ID
+	}
+	ao.AuctionResponse = response
+	ao.Errors = append(ao.Errors, throttling.RejectedError{Reason: admission.Reason})
+
+	return sendAmpResponse(w, hookExecutor, response, reqWrapper, account, labels, ao, errs)
+}
+
 func sendAmpResponse(
 	w http.ResponseWriter,
 	hookExecutor hookexecution.HookStageExecutor,
@@ -451,10 +522,13 @@ func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req
 		return nil, nil, nil, nil, []error{err}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
+	timeline := exchange.TimelineFromContext(httpRequest.Context())
+	ctx, cancel := context.WithTimeout(exchange.NewTimelineContext(logger.NewContext(context.Background(), logger.FromContext(httpRequest.Context())), timeline), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
 	defer cancel()
 
+	fetchStart := time.Now()
 	storedRequests, _, errs := deps.storedReqFetcher.FetchRequests(ctx, []string{ampParams.StoredRequestID}, nil)
+	timeline.Record(exchange.TraceStepStoredRequestFetch, "", fetchStart, storedRequestFetchDetails(storedRequests, nil, errs))
 	if len(errs) > 0 {
 		return nil, nil, nil, nil, errs
 	}
@@ -209,12 +209,15 @@ func TestAMPPageInfo(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 	request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&curl=%s", url.QueryEscape(page)), nil)
 	recorder := httptest.NewRecorder()
@@ -239,23 +242,19 @@ func TestGDPRConsent(t *testing.T) {
 		consent         string
 		userExt         *openrtb_ext.ExtUser
 		nilUser         bool
-		expectedUserExt openrtb_ext.ExtUser
+		expectedConsent string
 	}{
 		{
-			description: "Nil User",
-			consent:     consent,
-			nilUser:     true,
-			expectedUserExt: openrtb_ext.ExtUser{
-				Consent: consent,
-			},
+			description:     "Nil User",
+			consent:         consent,
+			nilUser:         true,
+			expectedConsent: consent,
 		},
 		{
-			description: "Nil User Ext",
-			consent:     consent,
-			userExt:     nil,
-			expectedUserExt: openrtb_ext.ExtUser{
-				Consent: consent,
-			},
+			description:     "Nil User Ext",
+			consent:         consent,
+			userExt:         nil,
+			expectedConsent: consent,
 		},
 		{
 			description: "Overrides Existing Consent",
@@ -263,9 +262,7 @@ func TestGDPRConsent(t *testing.T) {
 			userExt: &openrtb_ext.ExtUser{
 				Consent: existingConsent,
 			},
-			expectedUserExt: openrtb_ext.ExtUser{
-				Consent: consent,
-			},
+			expectedConsent: consent,
 		},
 		{
 			description: "Overrides Existing Consent - With Sibling Data",
@@ -273,9 +270,7 @@ func TestGDPRConsent(t *testing.T) {
 			userExt: &openrtb_ext.ExtUser{
 				Consent: existingConsent,
 			},
-			expectedUserExt: openrtb_ext.ExtUser{
-				Consent: consent,
-			},
+			expectedConsent: consent,
 		},
 		{
 			description: "Does Not Override Existing Consent If Empty",
@@ -283,9 +278,7 @@ func TestGDPRConsent(t *testing.T) {
 			userExt: &openrtb_ext.ExtUser{
 				Consent: existingConsent,
 			},
-			expectedUserExt: openrtb_ext.ExtUser{
-				Consent: existingConsent,
-			},
+			expectedConsent: existingConsent,
 		},
 	}
 
@@ -312,12 +305,15 @@ func TestGDPRConsent(t *testing.T) {
 				GDPR:           config.GDPR{Enabled: true},
 			},
 			&metricsConfig.NilMetricsEngine{},
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
+			nil,
+			nil,
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -339,15 +335,7 @@ func TestGDPRConsent(t *testing.T) {
 		if !assert.NotNil(t, result.User, test.description+":lastRequest.User") {
 			return
 		}
-		if !assert.NotNil(t, result.User.Ext, test.description+":lastRequest.User.Ext") {
-			return
-		}
-		var ue openrtb_ext.ExtUser
-		err = json.Unmarshal(result.User.Ext, &ue)
-		if !assert.NoError(t, err, test.description+":deserialize") {
-			return
-		}
-		assert.Equal(t, test.expectedUserExt, ue, test.description)
+		assert.Equal(t, test.expectedConsent, result.User.Consent, test.description)
 		assert.Equal(t, expectedErrorsFromHoldAuction, response.ORTB2.Ext.Errors, test.description+":errors")
 		assert.Empty(t, response.ORTB2.Ext.Warnings, test.description+":warnings")
 
@@ -370,15 +358,7 @@ func TestGDPRConsent(t *testing.T) {
 		if !assert.NotNil(t, resultLegacy.User, test.description+":legacy:lastRequest.User") {
 			return
 		}
-		if !assert.NotNil(t, resultLegacy.User.Ext, test.description+":legacy:lastRequest.User.Ext") {
-			return
-		}
-		var ueLegacy openrtb_ext.ExtUser
-		err = json.Unmarshal(resultLegacy.User.Ext, &ueLegacy)
-		if !assert.NoError(t, err, test.description+":legacy:deserialize") {
-			return
-		}
-		assert.Equal(t, test.expectedUserExt, ueLegacy, test.description+":legacy")
+		assert.Equal(t, test.expectedConsent, resultLegacy.User.Consent, test.description+":legacy")
 		assert.Equal(t, expectedErrorsFromHoldAuction, responseLegacy.ORTB2.Ext.Errors, test.description+":legacy:errors")
 		assert.Empty(t, responseLegacy.ORTB2.Ext.Warnings, test.description+":legacy:warnings")
 	}
@@ -550,7 +530,7 @@ func TestOverrideWithParams(t *testing.T) {
 			},
 		},
 		{
-			desc: "bid request with malformed user.ext.prebid - amp.Params with GDPR consent values - expect policy writer to return error",
+			desc: "bid request with malformed user.ext.prebid - amp.Params with GDPR consent values - expect consent in user.consent and user.ext left as is",
 			given: testInput{
 				ampParams: amp.Params{
 					ConsentType: amp.ConsentTCF2,
@@ -564,10 +544,11 @@ func TestOverrideWithParams(t *testing.T) {
 			expected: testOutput{
 				bidRequest: &openrtb2.BidRequest{
 					Imp:  []openrtb2.Imp{{Banner: &openrtb2.Banner{Format: []openrtb2.Format{}}}},
-					User: &openrtb2.User{Ext: json.RawMessage(`{"prebid":{malformed}}`)},
+					User: &openrtb2.User{Consent: "CPdECS0PdECS0ACABBENAzCv_____3___wAAAQNd_X9cAAAAAAAA", Ext: json.RawMessage(`{"prebid":{malformed}}`)},
 					Site: &openrtb2.Site{Ext: json.RawMessage(`{"amp":1}`)},
+					Regs: &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(1)},
 				},
-				errorMsgs: []string{"invalid character 'm' looking for beginning of object key string"},
+				errorMsgs: nil,
 			},
 		},
 	}
@@ -655,17 +636,17 @@ func TestCCPAConsent(t *testing.T) {
 	var gdpr int8 = 1
 
 	testCases := []struct {
-		description    string
-		consent        string
-		regsExt        *openrtb_ext.ExtRegs
-		nilRegs        bool
-		expectedRegExt openrtb_ext.ExtRegs
+		description  string
+		consent      string
+		regsExt      *openrtb_ext.ExtRegs
+		nilRegs      bool
+		expectedRegs openrtb2.Regs
 	}{
 		{
 			description: "Nil Regs",
 			consent:     consent,
 			nilRegs:     true,
-			expectedRegExt: openrtb_ext.ExtRegs{
+			expectedRegs: openrtb2.Regs{
 				USPrivacy: consent,
 			},
 		},
@@ -673,7 +654,7 @@ func TestCCPAConsent(t *testing.T) {
 			description: "Nil Regs Ext",
 			consent:     consent,
 			regsExt:     nil,
-			expectedRegExt: openrtb_ext.ExtRegs{
+			expectedRegs: openrtb2.Regs{
 				USPrivacy: consent,
 			},
 		},
@@ -683,7 +664,7 @@ func TestCCPAConsent(t *testing.T) {
 			regsExt: &openrtb_ext.ExtRegs{
 				USPrivacy: existingConsent,
 			},
-			expectedRegExt: openrtb_ext.ExtRegs{
+			expectedRegs: openrtb2.Regs{
 				USPrivacy: consent,
 			},
 		},
@@ -694,7 +675,7 @@ func TestCCPAConsent(t *testing.T) {
 				USPrivacy: existingConsent,
 				GDPR:      &gdpr,
 			},
-			expectedRegExt: openrtb_ext.ExtRegs{
+			expectedRegs: openrtb2.Regs{
 				USPrivacy: consent,
 				GDPR:      &gdpr,
 			},
@@ -705,7 +686,7 @@ func TestCCPAConsent(t *testing.T) {
 			regsExt: &openrtb_ext.ExtRegs{
 				USPrivacy: existingConsent,
 			},
-			expectedRegExt: openrtb_ext.ExtRegs{
+			expectedRegs: openrtb2.Regs{
 				USPrivacy: existingConsent,
 			},
 		},
@@ -731,12 +712,15 @@ func TestCCPAConsent(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			&metricsConfig.NilMetricsEngine{},
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
+			nil,
+			nil,
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -758,15 +742,8 @@ func TestCCPAConsent(t *testing.T) {
 		if !assert.NotNil(t, result.Regs, test.description+":lastRequest.Regs") {
 			return
 		}
-		if !assert.NotNil(t, result.Regs.Ext, test.description+":lastRequest.Regs.Ext") {
-			return
-		}
-		var re openrtb_ext.ExtRegs
-		err = json.Unmarshal(result.Regs.Ext, &re)
-		if !assert.NoError(t, err, test.description+":deserialize") {
-			return
-		}
-		assert.Equal(t, test.expectedRegExt, re, test.description)
+		assert.Equal(t, test.expectedRegs.USPrivacy, result.Regs.USPrivacy, test.description+":us_privacy")
+		assert.Equal(t, test.expectedRegs.GDPR, result.Regs.GDPR, test.description+":gdpr")
 		assert.Equal(t, expectedErrorsFromHoldAuction, response.ORTB2.Ext.Errors)
 		assert.Empty(t, response.ORTB2.Ext.Warnings)
 	}
@@ -844,12 +821,15 @@ func TestConsentWarnings(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			&metricsConfig.NilMetricsEngine{},
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
+			nil,
+			nil,
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -899,23 +879,19 @@ func TestNewAndLegacyConsentBothProvided(t *testing.T) {
 		consent         string
 		consentLegacy   string
 		userExt         *openrtb_ext.ExtUser
-		expectedUserExt openrtb_ext.ExtUser
+		expectedConsent string
 	}{
 		{
-			description:   "New Consent Wins",
-			consent:       validConsentGDPR1,
-			consentLegacy: validConsentGDPR2,
-			expectedUserExt: openrtb_ext.ExtUser{
-				Consent: validConsentGDPR1,
-			},
+			description:     "New Consent Wins",
+			consent:         validConsentGDPR1,
+			consentLegacy:   validConsentGDPR2,
+			expectedConsent: validConsentGDPR1,
 		},
 		{
-			description:   "New Consent Wins - Reverse",
-			consent:       validConsentGDPR2,
-			consentLegacy: validConsentGDPR1,
-			expectedUserExt: openrtb_ext.ExtUser{
-				Consent: validConsentGDPR2,
-			},
+			description:     "New Consent Wins - Reverse",
+			consent:         validConsentGDPR2,
+			consentLegacy:   validConsentGDPR1,
+			expectedConsent: validConsentGDPR2,
 		},
 	}
 
@@ -942,12 +918,15 @@ func TestNewAndLegacyConsentBothProvided(t *testing.T) {
 				GDPR:           config.GDPR{Enabled: true},
 			},
 			&metricsConfig.NilMetricsEngine{},
-			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
+			nil,
+			nil,
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -969,15 +948,7 @@ func TestNewAndLegacyConsentBothProvided(t *testing.T) {
 		if !assert.NotNil(t, result.User, test.description+":lastRequest.User") {
 			return
 		}
-		if !assert.NotNil(t, result.User.Ext, test.description+":lastRequest.User.Ext") {
-			return
-		}
-		var ue openrtb_ext.ExtUser
-		err = json.Unmarshal(result.User.Ext, &ue)
-		if !assert.NoError(t, err, test.description+":deserialize") {
-			return
-		}
-		assert.Equal(t, test.expectedUserExt, ue, test.description)
+		assert.Equal(t, test.expectedConsent, result.User.Consent, test.description)
 		assert.Equal(t, expectedErrorsFromHoldAuction, response.ORTB2.Ext.Errors)
 		assert.Empty(t, response.ORTB2.Ext.Warnings)
 	}
@@ -996,12 +967,15 @@ func TestAMPSiteExt(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		nil,
 		nil,
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 	request, err := http.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
 	if !assert.NoError(t, err) {
@@ -1038,12 +1012,15 @@ func TestAmpBadRequests(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 	for requestID := range badRequests {
 		request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=%s", requestID), nil)
@@ -1071,12 +1048,15 @@ func TestAmpDebug(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 
 	for requestID := range requests {
@@ -1148,12 +1128,15 @@ func TestQueryParamOverrides(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 
 	requestID := "1"
@@ -1305,12 +1288,15 @@ func (s formatOverrideSpec) execute(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 
 	url := fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&debug=1&w=%d&h=%d&ow=%d&oh=%d&ms=%s&account=%s", s.width, s.height, s.overrideWidth, s.overrideHeight, s.multisize, s.account)
@@ -1774,6 +1760,9 @@ func ampObjectTestSetup(t *testing.T, inTagId string, inStoredRequest json.RawMe
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 	return &actualAmpObject, endpoint
 }
@@ -1820,12 +1809,15 @@ func TestAmpAuctionResponseHeaders(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 
 	for _, test := range testCases {
@@ -1855,12 +1847,15 @@ func TestRequestWithTargeting(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		nil,
 		nil,
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 	url, err := url.Parse("/openrtb2/auction/amp")
 	assert.NoError(t, err, "unexpected error received while parsing url")
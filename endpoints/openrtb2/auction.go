@@ -34,6 +34,7 @@ import (
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
 	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
@@ -43,9 +44,12 @@ import (
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/stored_responses"
+	"github.com/prebid/prebid-server/throttling"
 	"github.com/prebid/prebid-server/usersync"
+	"github.com/prebid/prebid-server/util/compressutil"
 	"github.com/prebid/prebid-server/util/httputil"
 	"github.com/prebid/prebid-server/util/iputil"
+	"github.com/prebid/prebid-server/util/sliceutil"
 	"github.com/prebid/prebid-server/util/uuidutil"
 	"github.com/prebid/prebid-server/version"
 )
@@ -85,6 +89,9 @@ func NewEndpoint(
 	bidderMap map[string]openrtb_ext.BidderName,
 	storedRespFetcher stored_requests.Fetcher,
 	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
+	cookies *usersync.Cookies,
+	throttler *throttling.Throttler,
+	loggers *logger.Factory,
 ) (httprouter.Handle, error) {
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || metricsEngine == nil {
 		return nil, errors.New("NewEndpoint requires non-nil arguments.")
@@ -117,7 +124,10 @@ func NewEndpoint(
 		nil,
 		ipValidator,
 		storedRespFetcher,
-		hookExecutor}).Auction), nil
+		hookExecutor,
+		cookies,
+		throttler,
+		loggers}).Auction), nil
 }
 
 type endpointDeps struct {
@@ -139,6 +149,9 @@ type endpointDeps struct {
 	privateNetworkIPValidator iputil.IPValidator
 	storedRespFetcher         stored_requests.Fetcher
 	hookExecutor              hookexecution.HookStageExecutor
+	cookies                   *usersync.Cookies
+	throttler                 *throttling.Throttler
+	loggers                   *logger.Factory
 }
 
 func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -171,6 +184,18 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 
 	w.Header().Set("X-Prebid", version.BuildXPrebidHeader(version.Ver))
 
+	log := deps.loggers.New(hookexecution.EndpointAuction, r)
+	timeline := exchange.NewTimeline(start)
+	r = r.WithContext(exchange.NewTimelineContext(logger.NewContext(r.Context(), log), timeline))
+
+	// Shed the load before the stored requests and the account are fetched. The account isn't known yet.
+	admission := deps.throttler.AdmitLoad(labels.PubID)
+	defer admission.Done()
+	if admission.Rejected {
+		labels, ao = rejectThrottledAuctionRequest(admission, w, deps.hookExecutor, nil, nil, labels, ao)
+		return
+	}
+
 	req, impExtInfoMap, storedAuctionResponses, storedBidResponses, bidderImpReplaceImp, account, errL := deps.parseRequest(r, &labels)
 	if errortypes.ContainsFatalError(errL) && writeError(errL, w, &labels) {
 		return
@@ -181,7 +206,14 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		return
 	}
 
-	ctx := context.Background()
+	admission = deps.throttler.AdmitAccount(admission, labels.PubID, account.RateLimit)
+	if admission.Rejected {
+		labels, ao = rejectThrottledAuctionRequest(admission, w, deps.hookExecutor, req.BidRequest, account, labels, ao)
+		return
+	}
+
+	log = log.WithAccount(account.ID)
+	ctx := exchange.NewTimelineContext(logger.NewContext(context.Background(), log), timeline)
 
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(req.TMax) * time.Millisecond)
 	if timeout > 0 {
@@ -190,7 +222,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		defer cancel()
 	}
 
-	usersyncs := usersync.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
+	usersyncs := deps.cookies.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
 	if req.Site != nil {
 		if usersyncs.HasAnyLiveSyncs() {
 			labels.CookieFlag = metrics.CookieFlagYes
@@ -225,6 +257,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		BidderImpReplaceImpID:      bidderImpReplaceImp,
 		PubID:                      labels.PubID,
 		HookExecutor:               deps.hookExecutor,
+		MaxBidders:                 admission.MaxBidders,
 	}
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
 	ao.Request = req.BidRequest
@@ -239,7 +272,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		labels.RequestStatus = metrics.RequestStatusErr
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Critical error while running the auction: %v", err)
-		glog.Errorf("/openrtb2/auction Critical error: %v", err)
+		log.Errorf("/openrtb2/auction Critical error: %v", err)
 		ao.Status = http.StatusInternalServerError
 		ao.Errors = append(ao.Errors, err)
 		return
@@ -271,6 +304,27 @@ func rejectAuctionRequest(
 	return sendAuctionResponse(w, hookExecutor, response, request, account, labels, ao)
 }
 
+func rejectThrottledAuctionRequest(
+	admission throttling.Admission,
+	w http.ResponseWriter,
+	hookExecutor hookexecution.HookStageExecutor,
+	request *openrtb2.BidRequest,
+	account *config.Account,
+	labels metrics.Labels,
+	ao analytics.AuctionObject,
+) (metrics.Labels, analytics.AuctionObject) {
+	response := &openrtb2.BidResponse{NBR: throttling.NoBidReason.Ptr()}
+	if request != nil {
+		response.ID = request.ID
+	}
+	ao.Request = request
+	ao.Response = response
+	ao.Account = account
+	ao.Errors = append(ao.Errors, throttling.RejectedError{Reason: admission.Reason})
+
+	return sendAuctionResponse(w, hookExecutor, response, request, account, labels, ao)
+}
+
 func sendAuctionResponse(
 	w http.ResponseWriter,
 	hookExecutor hookexecution.HookStageExecutor,
@@ -317,6 +371,37 @@ func sendAuctionResponse(
 	return labels, ao
 }
 
+// readRequestBody reads the body of the HTTP request, decoded with its Content-Encoding, which must be one
+// of the encodings accepted. The decoded body can't exceed maxSize bytes.
+func readRequestBody(httpRequest *http.Request, maxSize int64, encodings []string) ([]byte, error) {
+	encoding := httpRequest.Header.Get("Content-Encoding")
+	if encoding != "" && !strings.EqualFold(encoding, compressutil.Identity) && !sliceutil.ContainsStringIgnoreCase(encodings, encoding) {
+		return nil, compressutil.UnsupportedEncodingError{Encoding: encoding}
+	}
+	body, err := compressutil.NewReader(encoding, httpRequest.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	lr := &io.LimitedReader{
+		R: body,
+		N: maxSize,
+	}
+	requestJson, err := io.ReadAll(lr)
+	if err != nil {
+		return nil, err
+	}
+	// If the request size was too large, read through the rest of the request body so that the connection can be reused.
+	if lr.N <= 0 {
+		if n, err := io.ReadFull(body, make([]byte, 1)); n > 0 || err != io.EOF {
+			io.Copy(io.Discard, httpRequest.Body)
+			return nil, fmt.Errorf("Request size exceeded max size of %d bytes.", maxSize)
+		}
+	}
+	return requestJson, nil
+}
+
 // parseRequest turns the HTTP request into an OpenRTB request. This is guaranteed to return:
 //
 //   - A context which times out appropriately, given the request.
@@ -331,36 +416,28 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 	req = &openrtb_ext.RequestWrapper{}
 	req.BidRequest = &openrtb2.BidRequest{}
 	errs = nil
+	log := logger.FromContext(httpRequest.Context())
 
 	// Pull the request body into a buffer, so we have it for later usage.
-	lr := &io.LimitedReader{
-		R: httpRequest.Body,
-		N: deps.cfg.MaxRequestSize,
-	}
-	requestJson, err := io.ReadAll(lr)
+	requestJson, err := readRequestBody(httpRequest, deps.cfg.MaxRequestSize, deps.cfg.Compression.Request)
 	if err != nil {
 		errs = []error{err}
 		return
 	}
-	// If the request size was too large, read through the rest of the request body so that the connection can be reused.
-	if lr.N <= 0 {
-		if written, err := io.Copy(io.Discard, httpRequest.Body); written > 0 || err != nil {
-			errs = []error{fmt.Errorf("Request size exceeded max size of %d bytes.", deps.cfg.MaxRequestSize)}
-			return
-		}
-	}
 
+	deps.hookExecutor.SetLogger(log)
 	requestJson, rejectErr := deps.hookExecutor.ExecuteEntrypointStage(httpRequest, requestJson)
 	if rejectErr != nil {
 		errs = []error{rejectErr}
 		if err = json.Unmarshal(requestJson, req.BidRequest); err != nil {
-			glog.Errorf("Failed to unmarshal BidRequest during entrypoint rejection: %s", err)
+			log.Errorf("Failed to unmarshal BidRequest during entrypoint rejection: %s", err)
 		}
 		return
 	}
 
 	timeout := parseTimeout(requestJson, time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	timeline := exchange.TimelineFromContext(httpRequest.Context())
+	ctx, cancel := context.WithTimeout(exchange.NewTimelineContext(logger.NewContext(context.Background(), log), timeline), timeout)
 	defer cancel()
 
 	impInfo, errs := parseImpInfo(requestJson)
@@ -368,7 +445,9 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 		return nil, nil, nil, nil, nil, nil, errs
 	}
 
+	fetchStart := time.Now()
 	storedBidRequestId, hasStoredBidRequest, storedRequests, storedImps, errs := deps.getStoredRequests(ctx, requestJson, impInfo)
+	timeline.Record(exchange.TraceStepStoredRequestFetch, "", fetchStart, storedRequestFetchDetails(storedRequests, storedImps, errs))
 	if len(errs) > 0 {
 		return
 	}
@@ -388,7 +467,9 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 	}
 
 	// Look up account
+	fetchStart = time.Now()
 	account, errs = accountService.GetAccount(ctx, deps.cfg, deps.accounts, accountId)
+	timeline.Record(exchange.TraceStepAccountFetch, "", fetchStart, accountFetchDetails(accountId, errs))
 	if len(errs) > 0 {
 		return
 	}
@@ -398,7 +479,7 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 	if rejectErr != nil {
 		errs = []error{rejectErr}
 		if err = json.Unmarshal(requestJson, req.BidRequest); err != nil {
-			glog.Errorf("Failed to unmarshal BidRequest during raw auction stage rejection: %s", err)
+			log.Errorf("Failed to unmarshal BidRequest during raw auction stage rejection: %s", err)
 		}
 		return
 	}
@@ -409,7 +490,9 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 		if len(errs) > 0 {
 			return nil, nil, nil, nil, nil, nil, errs
 		}
+		fetchStart = time.Now()
 		storedBidRequestId, hasStoredBidRequest, storedRequests, storedImps, errs = deps.getStoredRequests(ctx, requestJson, impInfo)
+		timeline.Record(exchange.TraceStepStoredRequestFetch, "", fetchStart, storedRequestFetchDetails(storedRequests, storedImps, errs))
 		if len(errs) > 0 {
 			return
 		}
@@ -455,6 +538,10 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 		errs = append(errs, errL...)
 	}
 
+	if !errortypes.ContainsFatalError(errs) {
+		errs = append(errs, deps.applyRequestPolicy(req, account.RequestPolicy, labels.PubID)...)
+	}
+
 	return
 }
 
@@ -703,6 +790,12 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper, isAmp
 		return []error{err}
 	}
 
+	// the 2.5 ext locations are accepted, but the request is processed in the OpenRTB 2.6 layout
+	// and only down converted for the bidders which don't support it
+	if err := openrtb_ext.ConvertUpTo26(req); err != nil {
+		return []error{err}
+	}
+
 	if err := validateOrFillChannel(req, isAmp); err != nil {
 		return []error{err}
 	}
@@ -752,11 +845,7 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper, isAmp
 			errL = append(errL, &errortypes.Warning{
 				Message:     fmt.Sprintf("CCPA consent is invalid and will be ignored. (%v)", err),
 				WarningCode: errortypes.InvalidPrivacyConsentWarningCode})
-			regsExt, err := req.GetRegExt()
-			if err != nil {
-				return append(errL, err)
-			}
-			regsExt.SetUSPrivacy("")
+			req.Regs.USPrivacy = ""
 		} else {
 			return append(errL, err)
 		}
@@ -1569,28 +1658,28 @@ func (deps *endpointDeps) validateUser(req *openrtb_ext.RequestWrapper, aliases
 		}
 	}
 	// Check Universal User ID
-	eids := userExt.GetEid()
-	if eids != nil {
-		if len(*eids) == 0 {
-			return errors.New("request.user.ext.eids must contain at least one element or be undefined")
+	if req.User != nil && req.User.EIDs != nil {
+		eids := req.User.EIDs
+		if len(eids) == 0 {
+			return errors.New("request.user.eids must contain at least one element or be undefined")
 		}
-		uniqueSources := make(map[string]struct{}, len(*eids))
-		for eidIndex, eid := range *eids {
+		uniqueSources := make(map[string]struct{}, len(eids))
+		for eidIndex, eid := range eids {
 			if eid.Source == "" {
-				return fmt.Errorf("request.user.ext.eids[%d] missing required field: \"source\"", eidIndex)
+				return fmt.Errorf("request.user.eids[%d] missing required field: \"source\"", eidIndex)
 			}
 			if _, ok := uniqueSources[eid.Source]; ok {
-				return errors.New("request.user.ext.eids must contain unique sources")
+				return errors.New("request.user.eids must contain unique sources")
 			}
 			uniqueSources[eid.Source] = struct{}{}
 
 			if len(eid.UIDs) == 0 {
-				return fmt.Errorf("request.user.ext.eids[%d].uids must contain at least one element or be undefined", eidIndex)
+				return fmt.Errorf("request.user.eids[%d].uids must contain at least one element or be undefined", eidIndex)
 			}
 
 			for uidIndex, uid := range eid.UIDs {
 				if uid.ID == "" {
-					return fmt.Errorf("request.user.ext.eids[%d].uids[%d] missing required field: \"id\"", eidIndex, uidIndex)
+					return fmt.Errorf("request.user.eids[%d].uids[%d] missing required field: \"id\"", eidIndex, uidIndex)
 				}
 			}
 		}
@@ -1600,14 +1689,12 @@ func (deps *endpointDeps) validateUser(req *openrtb_ext.RequestWrapper, aliases
 }
 
 func validateRegs(req *openrtb_ext.RequestWrapper) error {
-	regsExt, err := req.GetRegExt()
-	if err != nil {
+	if _, err := req.GetRegExt(); err != nil {
 		return fmt.Errorf("request.regs.ext is invalid: %v", err)
 	}
 
-	gdpr := regsExt.GetGDPR()
-	if gdpr != nil && *gdpr != 0 && *gdpr != 1 {
-		return errors.New("request.regs.ext.gdpr must be either 0 or 1")
+	if req.Regs != nil && req.Regs.GDPR != nil && *req.Regs.GDPR != 0 && *req.Regs.GDPR != 1 {
+		return errors.New("request.regs.gdpr must be either 0 or 1")
 	}
 
 	return nil
@@ -1797,6 +1884,29 @@ func getJsonSyntaxError(testJSON []byte) (bool, string) {
 	return false, ""
 }
 
+// storedRequestFetchDetails returns the details of the stored_request_fetch step of the auction timeline.
+func storedRequestFetchDetails(storedRequests, storedImps map[string]json.RawMessage, errs []error) map[string]interface{} {
+	details := map[string]interface{}{
+		"requests": len(storedRequests),
+		"imps":     len(storedImps),
+	}
+	if len(errs) > 0 {
+		details["errors"] = len(errs)
+	}
+	return details
+}
+
+// accountFetchDetails returns the details of the account_fetch step of the auction timeline.
+func accountFetchDetails(accountID string, errs []error) map[string]interface{} {
+	details := map[string]interface{}{
+		"account": accountID,
+	}
+	if len(errs) > 0 {
+		details["errors"] = len(errs)
+	}
+	return details
+}
+
 func (deps *endpointDeps) getStoredRequests(ctx context.Context, requestJson []byte, impInfo []ImpExtPrebidData) (string, bool, map[string]json.RawMessage, map[string]json.RawMessage, []error) {
 	// Parse the Stored Request IDs from the BidRequest and Imps.
 	storedBidRequestId, hasStoredBidRequest, err := getStoredRequestId(requestJson)
@@ -74,7 +74,7 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 
 	nilMetrics := &metricsConfig.NilMetricsEngine{}
 
-	adapters, adaptersErr := exchange.BuildAdapters(server.Client(), &config.Configuration{}, infos, nilMetrics)
+	adapters, adaptersErr := exchange.BuildAdapters(server.Client(), &config.Configuration{}, infos, nilMetrics, nil)
 	if adaptersErr != nil {
 		b.Fatal("unable to build adapters")
 	}
@@ -98,6 +98,8 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		currency.NewRateConverter(&http.Client{}, "", time.Duration(0)),
 		empty_fetcher.EmptyFetcher{},
 		&adscert.NilSigner{},
+		nil,
+		nil,
 	)
 
 	endpoint, _ := NewEndpoint(
@@ -108,12 +110,15 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		nilMetrics,
-		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		nil,
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
+		nil,
+		nil,
+		nil,
 	)
 
 	b.ResetTimer()
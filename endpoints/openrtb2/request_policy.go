@@ -0,0 +1,243 @@
+package openrtb2
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// applyRequestPolicy evaluates the request policy of the account on a valid request. It returns the errors
+// of the rules rejecting the request, and the warnings of the rules warning about it or fixing it, which
+// are returned in ext.warnings.
+func (deps *endpointDeps) applyRequestPolicy(req *openrtb_ext.RequestWrapper, policy config.AccountRequestPolicy, pubID string) []error {
+	var errs []error
+	for _, rule := range requestPolicyRules(policy) {
+		if rule.action != config.RequestPolicyReject && rule.action != config.RequestPolicyWarn && rule.action != config.RequestPolicyFix {
+			continue
+		}
+		violation := rule.check(req)
+		if violation == "" {
+			continue
+		}
+
+		action := metrics.RequestPolicyWarned
+		switch {
+		case rule.action == config.RequestPolicyReject:
+			action = metrics.RequestPolicyRejected
+			errs = append(errs, &errortypes.BadInput{Message: fmt.Sprintf("request policy %s: %s", rule.name, violation)})
+		case rule.action == config.RequestPolicyFix && rule.fix != nil:
+			if err := rule.fix(req); err != nil {
+				action = metrics.RequestPolicyRejected
+				errs = append(errs, &errortypes.BadInput{Message: fmt.Sprintf("request policy %s: %s", rule.name, err.Error())})
+			} else {
+				action = metrics.RequestPolicyFixed
+				errs = append(errs, &errortypes.Warning{
+					WarningCode: errortypes.RequestPolicyWarningCode,
+					Message:     fmt.Sprintf("request policy %s: %s. The request was fixed", rule.name, violation),
+				})
+			}
+		default:
+			// The rules which can't fix a request warn about it.
+			errs = append(errs, &errortypes.Warning{
+				WarningCode: errortypes.RequestPolicyWarningCode,
+				Message:     fmt.Sprintf("request policy %s: %s", rule.name, violation),
+			})
+		}
+		deps.metricsEngine.RecordRequestPolicyViolation(metrics.RequestPolicyLabels{PubID: pubID, Rule: rule.name, Action: action})
+	}
+	return errs
+}
+
+// requestPolicyRule is a rule of the request policy of an account.
+type requestPolicyRule struct {
+	name   metrics.RequestPolicyRule
+	action string
+	// check returns how the request breaks the rule, or an empty string if it doesn't.
+	check func(req *openrtb_ext.RequestWrapper) string
+	// fix repairs the request breaking the rule, nil if the rule can't. It returns an error if the
+	// request can't be fixed.
+	fix func(req *openrtb_ext.RequestWrapper) error
+}
+
+// requestPolicyRules returns the rules of the policy, in the order they are evaluated. The media types
+// are fixed before the imps are counted.
+func requestPolicyRules(policy config.AccountRequestPolicy) []requestPolicyRule {
+	allowedMediaTypes := make(map[string]bool, len(policy.MediaTypes.Allowed))
+	for _, mediaType := range policy.MediaTypes.Allowed {
+		allowedMediaTypes[mediaType] = true
+	}
+
+	return []requestPolicyRule{
+		{
+			name:   metrics.RequestPolicySitePage,
+			action: policy.SitePage.Action,
+			check:  checkSitePage,
+		},
+		{
+			name:   metrics.RequestPolicyAppBundle,
+			action: policy.AppBundle.Action,
+			check:  checkAppBundle,
+		},
+		{
+			name:   metrics.RequestPolicyDeviceIP,
+			action: policy.DeviceIP.Action,
+			check:  checkDeviceIP,
+		},
+		{
+			name:   metrics.RequestPolicyUserAgents,
+			action: policy.UserAgents.Action,
+			check: func(req *openrtb_ext.RequestWrapper) string {
+				return checkUserAgent(req, policy.UserAgents.Blocked)
+			},
+		},
+		{
+			name:   metrics.RequestPolicyMediaTypes,
+			action: policy.MediaTypes.Action,
+			check: func(req *openrtb_ext.RequestWrapper) string {
+				return checkMediaTypes(req, allowedMediaTypes)
+			},
+			fix: func(req *openrtb_ext.RequestWrapper) error {
+				return fixMediaTypes(req, allowedMediaTypes)
+			},
+		},
+		{
+			name:   metrics.RequestPolicyMaxImps,
+			action: policy.MaxImps.Action,
+			check: func(req *openrtb_ext.RequestWrapper) string {
+				return checkMaxImps(req, policy.MaxImps.Max)
+			},
+			fix: func(req *openrtb_ext.RequestWrapper) error {
+				req.SetImp(req.GetImp()[:policy.MaxImps.Max])
+				return nil
+			},
+		},
+		{
+			name:   metrics.RequestPolicyMaxTmax,
+			action: policy.MaxTmax.Action,
+			check: func(req *openrtb_ext.RequestWrapper) string {
+				return checkMaxTmax(req, policy.MaxTmax.Max)
+			},
+			fix: func(req *openrtb_ext.RequestWrapper) error {
+				req.TMax = policy.MaxTmax.Max
+				return nil
+			},
+		},
+	}
+}
+
+func checkSitePage(req *openrtb_ext.RequestWrapper) string {
+	if req.Site != nil && req.Site.Page == "" {
+		return "request.site.page is required"
+	}
+	return ""
+}
+
+func checkAppBundle(req *openrtb_ext.RequestWrapper) string {
+	if req.App != nil && req.App.Bundle == "" {
+		return "request.app.bundle is required"
+	}
+	return ""
+}
+
+func checkDeviceIP(req *openrtb_ext.RequestWrapper) string {
+	if req.Device == nil || (req.Device.IP == "" && req.Device.IPv6 == "") {
+		return "request.device.ip or request.device.ipv6 is required"
+	}
+	return ""
+}
+
+func checkUserAgent(req *openrtb_ext.RequestWrapper, blocked []string) string {
+	if req.Device == nil || req.Device.UA == "" {
+		return ""
+	}
+	ua := strings.ToLower(req.Device.UA)
+	for _, agent := range blocked {
+		if agent != "" && strings.Contains(ua, strings.ToLower(agent)) {
+			return fmt.Sprintf("request.device.ua matches the blocked user agent %s", agent)
+		}
+	}
+	return ""
+}
+
+// disallowedMediaTypes returns the media types requested by the imp which aren't allowed.
+func disallowedMediaTypes(imp *openrtb_ext.ImpWrapper, allowed map[string]bool) []string {
+	var mediaTypes []string
+	if imp.Banner != nil && !allowed[string(openrtb_ext.BidTypeBanner)] {
+		mediaTypes = append(mediaTypes, string(openrtb_ext.BidTypeBanner))
+	}
+	if imp.Video != nil && !allowed[string(openrtb_ext.BidTypeVideo)] {
+		mediaTypes = append(mediaTypes, string(openrtb_ext.BidTypeVideo))
+	}
+	if imp.Audio != nil && !allowed[string(openrtb_ext.BidTypeAudio)] {
+		mediaTypes = append(mediaTypes, string(openrtb_ext.BidTypeAudio))
+	}
+	if imp.Native != nil && !allowed[string(openrtb_ext.BidTypeNative)] {
+		mediaTypes = append(mediaTypes, string(openrtb_ext.BidTypeNative))
+	}
+	return mediaTypes
+}
+
+func checkMediaTypes(req *openrtb_ext.RequestWrapper, allowed map[string]bool) string {
+	var fields []string
+	for i, imp := range req.GetImp() {
+		for _, mediaType := range disallowedMediaTypes(imp, allowed) {
+			fields = append(fields, fmt.Sprintf("request.imp[%d].%s", i, mediaType))
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s not allowed", strings.Join(fields, ", "))
+}
+
+// fixMediaTypes drops the media types which aren't allowed from the imps, and the imps left without any.
+func fixMediaTypes(req *openrtb_ext.RequestWrapper, allowed map[string]bool) error {
+	imps := make([]*openrtb_ext.ImpWrapper, 0, req.LenImp())
+	for _, imp := range req.GetImp() {
+		if (imp.Banner != nil && allowed[string(openrtb_ext.BidTypeBanner)]) ||
+			(imp.Video != nil && allowed[string(openrtb_ext.BidTypeVideo)]) ||
+			(imp.Audio != nil && allowed[string(openrtb_ext.BidTypeAudio)]) ||
+			(imp.Native != nil && allowed[string(openrtb_ext.BidTypeNative)]) {
+			imps = append(imps, imp)
+		}
+	}
+	if len(imps) == 0 {
+		return errors.New("request.imp has no allowed media type")
+	}
+
+	for _, imp := range imps {
+		if !allowed[string(openrtb_ext.BidTypeBanner)] {
+			imp.Banner = nil
+		}
+		if !allowed[string(openrtb_ext.BidTypeVideo)] {
+			imp.Video = nil
+		}
+		if !allowed[string(openrtb_ext.BidTypeAudio)] {
+			imp.Audio = nil
+		}
+		if !allowed[string(openrtb_ext.BidTypeNative)] {
+			imp.Native = nil
+		}
+	}
+	req.SetImp(imps)
+	return nil
+}
+
+func checkMaxImps(req *openrtb_ext.RequestWrapper, max int64) string {
+	if max > 0 && int64(req.LenImp()) > max {
+		return fmt.Sprintf("request.imp has %d impressions, more than the %d allowed", req.LenImp(), max)
+	}
+	return ""
+}
+
+func checkMaxTmax(req *openrtb_ext.RequestWrapper, max int64) string {
+	if max > 0 && req.TMax > max {
+		return fmt.Sprintf("request.tmax %d is more than the %d allowed", req.TMax, max)
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package openrtb2
+
+import (
+	"testing"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyRequestPolicy(t *testing.T) {
+	testCases := []struct {
+		description      string
+		policy           config.AccountRequestPolicy
+		request          *openrtb2.BidRequest
+		expectedErrors   []error
+		expectedRequest  *openrtb2.BidRequest
+		expectedRecorded []metrics.RequestPolicyLabels
+	}{
+		{
+			description:     "No Rules",
+			policy:          config.AccountRequestPolicy{},
+			request:         &openrtb2.BidRequest{Site: &openrtb2.Site{}, TMax: 5000},
+			expectedRequest: &openrtb2.BidRequest{Site: &openrtb2.Site{}, TMax: 5000},
+		},
+		{
+			description: "Rules Followed",
+			policy: config.AccountRequestPolicy{
+				SitePage: config.RequestPolicyRule{Action: config.RequestPolicyReject},
+				DeviceIP: config.RequestPolicyRule{Action: config.RequestPolicyReject},
+				MaxTmax:  config.RequestPolicyLimit{Action: config.RequestPolicyReject, Max: 1000},
+			},
+			request:         &openrtb2.BidRequest{Site: &openrtb2.Site{Page: "https://some-page.com"}, Device: &openrtb2.Device{IPv6: "2001:db8::1"}, TMax: 500},
+			expectedRequest: &openrtb2.BidRequest{Site: &openrtb2.Site{Page: "https://some-page.com"}, Device: &openrtb2.Device{IPv6: "2001:db8::1"}, TMax: 500},
+		},
+		{
+			description: "Required Fields Rejected And Warned",
+			policy: config.AccountRequestPolicy{
+				AppBundle: config.RequestPolicyRule{Action: config.RequestPolicyReject},
+				DeviceIP:  config.RequestPolicyRule{Action: config.RequestPolicyWarn},
+			},
+			request: &openrtb2.BidRequest{App: &openrtb2.App{}},
+			expectedErrors: []error{
+				&errortypes.BadInput{Message: "request policy app_bundle: request.app.bundle is required"},
+				&errortypes.Warning{WarningCode: errortypes.RequestPolicyWarningCode, Message: "request policy device_ip: request.device.ip or request.device.ipv6 is required"},
+			},
+			expectedRequest: &openrtb2.BidRequest{App: &openrtb2.App{}},
+			expectedRecorded: []metrics.RequestPolicyLabels{
+				{PubID: "some-account", Rule: metrics.RequestPolicyAppBundle, Action: metrics.RequestPolicyRejected},
+				{PubID: "some-account", Rule: metrics.RequestPolicyDeviceIP, Action: metrics.RequestPolicyWarned},
+			},
+		},
+		{
+			description: "Blocked User Agent",
+			policy: config.AccountRequestPolicy{
+				UserAgents: config.RequestPolicyUserAgents{Action: config.RequestPolicyReject, Blocked: []string{"crawler", "bot"}},
+			},
+			request: &openrtb2.BidRequest{Device: &openrtb2.Device{UA: "Mozilla/5.0 (compatible; Googlebot/2.1)"}},
+			expectedErrors: []error{
+				&errortypes.BadInput{Message: "request policy user_agents: request.device.ua matches the blocked user agent bot"},
+			},
+			expectedRequest: &openrtb2.BidRequest{Device: &openrtb2.Device{UA: "Mozilla/5.0 (compatible; Googlebot/2.1)"}},
+			expectedRecorded: []metrics.RequestPolicyLabels{
+				{PubID: "some-account", Rule: metrics.RequestPolicyUserAgents, Action: metrics.RequestPolicyRejected},
+			},
+		},
+		{
+			description: "Fix On A Rule Which Can't Fix A Request Warns",
+			policy: config.AccountRequestPolicy{
+				SitePage: config.RequestPolicyRule{Action: config.RequestPolicyFix},
+			},
+			request: &openrtb2.BidRequest{Site: &openrtb2.Site{}},
+			expectedErrors: []error{
+				&errortypes.Warning{WarningCode: errortypes.RequestPolicyWarningCode, Message: "request policy site_page: request.site.page is required"},
+			},
+			expectedRequest: &openrtb2.BidRequest{Site: &openrtb2.Site{}},
+			expectedRecorded: []metrics.RequestPolicyLabels{
+				{PubID: "some-account", Rule: metrics.RequestPolicySitePage, Action: metrics.RequestPolicyWarned},
+			},
+		},
+		{
+			description: "Media Types, Imps And Tmax Fixed",
+			policy: config.AccountRequestPolicy{
+				MediaTypes: config.RequestPolicyMediaTypes{Action: config.RequestPolicyFix, Allowed: []string{"banner", "video"}},
+				MaxImps:    config.RequestPolicyLimit{Action: config.RequestPolicyFix, Max: 1},
+				MaxTmax:    config.RequestPolicyLimit{Action: config.RequestPolicyFix, Max: 1000},
+			},
+			request: &openrtb2.BidRequest{
+				Imp: []openrtb2.Imp{
+					{ID: "native-only", Native: &openrtb2.Native{}},
+					{ID: "banner-native", Banner: &openrtb2.Banner{}, Native: &openrtb2.Native{}},
+					{ID: "video", Video: &openrtb2.Video{}},
+				},
+				TMax: 3000,
+			},
+			expectedErrors: []error{
+				&errortypes.Warning{WarningCode: errortypes.RequestPolicyWarningCode, Message: "request policy media_types: request.imp[0].native, request.imp[1].native not allowed. The request was fixed"},
+				&errortypes.Warning{WarningCode: errortypes.RequestPolicyWarningCode, Message: "request policy max_imps: request.imp has 2 impressions, more than the 1 allowed. The request was fixed"},
+				&errortypes.Warning{WarningCode: errortypes.RequestPolicyWarningCode, Message: "request policy max_tmax: request.tmax 3000 is more than the 1000 allowed. The request was fixed"},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				Imp: []openrtb2.Imp{
+					{ID: "banner-native", Banner: &openrtb2.Banner{}},
+				},
+				TMax: 1000,
+			},
+			expectedRecorded: []metrics.RequestPolicyLabels{
+				{PubID: "some-account", Rule: metrics.RequestPolicyMediaTypes, Action: metrics.RequestPolicyFixed},
+				{PubID: "some-account", Rule: metrics.RequestPolicyMaxImps, Action: metrics.RequestPolicyFixed},
+				{PubID: "some-account", Rule: metrics.RequestPolicyMaxTmax, Action: metrics.RequestPolicyFixed},
+			},
+		},
+		{
+			description: "Media Types Can't Be Fixed",
+			policy: config.AccountRequestPolicy{
+				MediaTypes: config.RequestPolicyMediaTypes{Action: config.RequestPolicyFix, Allowed: []string{"banner"}},
+			},
+			request: &openrtb2.BidRequest{
+				Imp: []openrtb2.Imp{{ID: "video", Video: &openrtb2.Video{}}},
+			},
+			expectedErrors: []error{
+				&errortypes.BadInput{Message: "request policy media_types: request.imp has no allowed media type"},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				Imp: []openrtb2.Imp{{ID: "video", Video: &openrtb2.Video{}}},
+			},
+			expectedRecorded: []metrics.RequestPolicyLabels{
+				{PubID: "some-account", Rule: metrics.RequestPolicyMediaTypes, Action: metrics.RequestPolicyRejected},
+			},
+		},
+		{
+			description: "Unknown Action Ignored",
+			policy: config.AccountRequestPolicy{
+				MaxTmax: config.RequestPolicyLimit{Action: "drop", Max: 1000},
+			},
+			request:         &openrtb2.BidRequest{TMax: 3000},
+			expectedRequest: &openrtb2.BidRequest{TMax: 3000},
+		},
+	}
+
+	for _, test := range testCases {
+		metricsMock := &metrics.MetricsEngineMock{}
+		for _, labels := range test.expectedRecorded {
+			metricsMock.On("RecordRequestPolicyViolation", labels).Once()
+		}
+		deps := &endpointDeps{metricsEngine: metricsMock}
+
+		req := &openrtb_ext.RequestWrapper{BidRequest: test.request}
+		errs := deps.applyRequestPolicy(req, test.policy, "some-account")
+		assert.NoError(t, req.RebuildRequest(), test.description+":rebuild")
+
+		assert.Equal(t, test.expectedErrors, errs, test.description+":errors")
+		assert.Equal(t, test.expectedRequest, req.BidRequest, test.description+":request")
+		metricsMock.AssertExpectations(t)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -26,6 +25,7 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
@@ -53,6 +53,8 @@ func NewVideoEndpoint(
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
 	cache prebid_cache_client.Client,
+	cookies *usersync.Cookies,
+	loggers *logger.Factory,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
@@ -86,7 +88,10 @@ func NewVideoEndpoint(
 		videoEndpointRegexp,
 		ipValidator,
 		empty_fetcher.EmptyFetcher{},
-		&hookexecution.EmptyHookExecutor{}}).VideoAuctionEndpoint), nil
+		&hookexecution.EmptyHookExecutor{},
+		cookies,
+		nil,
+		loggers}).VideoAuctionEndpoint), nil
 }
 
 /*
@@ -157,11 +162,10 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 
 	w.Header().Set("X-Prebid", version.BuildXPrebidHeader(version.Ver))
 
-	lr := &io.LimitedReader{
-		R: r.Body,
-		N: deps.cfg.MaxRequestSize,
-	}
-	requestJson, err := io.ReadAll(lr)
+	log := deps.loggers.New("/openrtb2/video", r)
+	timeline := exchange.NewTimeline(start)
+
+	requestJson, err := readRequestBody(r, deps.cfg.MaxRequestSize, deps.cfg.Compression.Request)
 	if err != nil {
 		handleError(&labels, w, []error{err}, &vo, &debugLog)
 		return
@@ -186,7 +190,9 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 			return
 		}
 	} else {
-		storedRequest, errs := deps.loadStoredVideoRequest(context.Background(), storedRequestId)
+		fetchStart := time.Now()
+		storedRequest, errs := deps.loadStoredVideoRequest(exchange.NewTimelineContext(logger.NewContext(context.Background(), log), timeline), storedRequestId)
+		timeline.Record(exchange.TraceStepStoredRequestFetch, "", fetchStart, storedRequestFetchDetails(nil, nil, errs))
 		if len(errs) > 0 {
 			handleError(&labels, w, errs, &vo, &debugLog)
 			return
@@ -259,7 +265,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	ctx := context.Background()
+	ctx := exchange.NewTimelineContext(logger.NewContext(context.Background(), log), timeline)
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(bidReqWrapper.TMax) * time.Millisecond)
 	if timeout > 0 {
 		var cancel context.CancelFunc
@@ -267,7 +273,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		defer cancel()
 	}
 
-	usersyncs := usersync.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
+	usersyncs := deps.cookies.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
 	if bidReqWrapper.App != nil {
 		labels.Source = metrics.DemandApp
 		labels.PubID = getAccountID(bidReqWrapper.App.Publisher)
@@ -282,12 +288,17 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	// Look up account now that we have resolved the pubID value
+	fetchStart := time.Now()
 	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
+	timeline.Record(exchange.TraceStepAccountFetch, "", fetchStart, accountFetchDetails(labels.PubID, acctIDErrs))
 	if len(acctIDErrs) > 0 {
 		handleError(&labels, w, acctIDErrs, &vo, &debugLog)
 		return
 	}
 
+	log = log.WithAccount(account.ID)
+	ctx = logger.NewContext(ctx, log)
+
 	secGPC := r.Header.Get("Sec-GPC")
 
 	auctionRequest := exchange.AuctionRequest{
@@ -311,6 +322,9 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	//choose the best combination of bids for every pod
+	podPackingResults := packAdPods(response, videoBidReq.PodConfig, bidReq.Imp)
+
 	//build simplified response
 	bidResp, err := buildVideoResponse(response, podErrors)
 	if err != nil {
@@ -318,8 +332,14 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		handleError(&labels, w, errL, &vo, &debugLog)
 		return
 	}
+	addRejectedPodBids(bidResp, podPackingResults)
 	if bidReq.Test == 1 {
-		bidResp.Ext = response.Ext
+		bidResp.Ext, err = addPodPackingDebug(response.Ext, podPackingResults)
+		if err != nil {
+			errL := []error{err}
+			handleError(&labels, w, errL, &vo, &debugLog)
+			return
+		}
 	}
 
 	if len(bidResp.AdPods) == 0 && debugLog.DebugEnabledOrOverridden {
@@ -773,6 +793,10 @@ func (deps *endpointDeps) validateVideoRequest(req *openrtb_ext.BidRequestVideo)
 			err := fmt.Sprintf("request missing or incorrect required field: PodConfig.Pods.ConfigId, Pod index: %d", ind)
 			podErr.ErrMsgs = append(podErr.ErrMsgs, err)
 		}
+		if pod.MaxAds < 0 {
+			err := fmt.Sprintf("request incorrect field: PodConfig.Pods.MaxAds is negative, Pod index: %d", ind)
+			podErr.ErrMsgs = append(podErr.ErrMsgs, err)
+		}
 		if len(podErr.ErrMsgs) > 0 {
 			podErr.PodId = pod.PodId
 			podErr.PodIndex = ind
@@ -0,0 +1,229 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/adpod"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// podCandidate is a cached bid competing for a place in an ad pod
+type podCandidate struct {
+	seat       string
+	bid        *openrtb2.Bid
+	price      int64 // micros, so revenue comparisons are exact
+	duration   int
+	category   string
+	advertiser string
+}
+
+// podPackingResult holds the bids chosen to fill a pod and the bids left out of it
+type podPackingResult struct {
+	podId        int
+	durationSec  int
+	selected     []podCandidate
+	rejectedBids []openrtb_ext.RejectedPodBid
+}
+
+// podPackingDebug is the summary of a packed pod added to the response debug output
+type podPackingDebug struct {
+	PodId        int                          `json:"podid"`
+	DurationSec  int                          `json:"adpoddurationsec"`
+	FilledSec    int                          `json:"filledsec"`
+	Revenue      float64                      `json:"revenue"`
+	SelectedBids []string                     `json:"selectedbids"`
+	RejectedBids []openrtb_ext.RejectedPodBid `json:"rejectedbids,omitempty"`
+}
+
+// packAdPods chooses, for every pod, the combination of bids with the highest revenue that fits in the
+// pod duration, respects the max ads limit and the category and advertiser exclusions. The bids left
+// out are removed from the bid response. Bids without a cache id are not candidates and are left in
+// place so buildVideoResponse keeps reporting caching errors.
+func packAdPods(bidresponse *openrtb2.BidResponse, podConfig openrtb_ext.PodConfig, imps []openrtb2.Imp) []podPackingResult {
+	pods := make(map[int]openrtb_ext.Pod, len(podConfig.Pods))
+	for _, pod := range podConfig.Pods {
+		pods[pod.PodId] = pod
+	}
+	impDurations := make(map[string]int, len(imps))
+	for _, imp := range imps {
+		if imp.Video != nil {
+			impDurations[imp.ID] = int(imp.Video.MaxDuration)
+		}
+	}
+
+	candidates := make(map[int][]podCandidate)
+	for i := range bidresponse.SeatBid {
+		seatBid := &bidresponse.SeatBid[i]
+		for j := range seatBid.Bid {
+			bid := &seatBid.Bid[j]
+			podId, err := strconv.Atoi(strings.Split(bid.ImpID, "_")[0])
+			if err != nil {
+				continue
+			}
+			if _, ok := pods[podId]; !ok {
+				continue
+			}
+			candidate, ok := newPodCandidate(seatBid.Seat, bid, impDurations)
+			if !ok {
+				continue
+			}
+			candidates[podId] = append(candidates[podId], candidate)
+		}
+	}
+
+	results := make([]podPackingResult, 0, len(candidates))
+	rejected := make(map[*openrtb2.Bid]bool)
+	for _, pod := range podConfig.Pods {
+		podCandidates, ok := candidates[pod.PodId]
+		if !ok {
+			continue
+		}
+		result := packAdPod(pod, podConfig.CategoryExclusion, podConfig.AdvertiserExclusion, podCandidates)
+		for _, candidate := range podCandidates {
+			rejected[candidate.bid] = true
+		}
+		for _, candidate := range result.selected {
+			delete(rejected, candidate.bid)
+		}
+		results = append(results, result)
+	}
+
+	if len(rejected) > 0 {
+		for i := range bidresponse.SeatBid {
+			seatBid := &bidresponse.SeatBid[i]
+			kept := make([]openrtb2.Bid, 0, len(seatBid.Bid))
+			for j := range seatBid.Bid {
+				if !rejected[&seatBid.Bid[j]] {
+					kept = append(kept, seatBid.Bid[j])
+				}
+			}
+			seatBid.Bid = kept
+		}
+	}
+
+	return results
+}
+
+func newPodCandidate(seat string, bid *openrtb2.Bid, impDurations map[string]int) (podCandidate, bool) {
+	var bidExt openrtb_ext.ExtBid
+	if err := json.Unmarshal(bid.Ext, &bidExt); err != nil || bidExt.Prebid == nil {
+		return podCandidate{}, false
+	}
+	if bidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbVastCacheKey, seat)] == "" {
+		return podCandidate{}, false
+	}
+
+	candidate := podCandidate{
+		seat:     seat,
+		bid:      bid,
+		price:    int64(math.Round(bid.Price * 1e6)),
+		duration: impDurations[bid.ImpID],
+	}
+	if bidExt.Prebid.Video != nil {
+		if bidExt.Prebid.Video.Duration > 0 {
+			candidate.duration = bidExt.Prebid.Video.Duration
+		}
+		candidate.category = bidExt.Prebid.Video.PrimaryCategory
+	}
+	if candidate.category == "" && len(bid.Cat) > 0 {
+		candidate.category = bid.Cat[0]
+	}
+	if len(bid.ADomain) > 0 {
+		candidate.advertiser = strings.ToLower(bid.ADomain[0])
+	}
+	return candidate, true
+}
+
+func packAdPod(pod openrtb_ext.Pod, categoryExclusion, advertiserExclusion bool, candidates []podCandidate) podPackingResult {
+	result := podPackingResult{podId: pod.PodId, durationSec: pod.AdPodDurationSec}
+
+	packCandidates := make([]adpod.Candidate, len(candidates))
+	for i, candidate := range candidates {
+		packCandidates[i] = adpod.Candidate{
+			Price:      candidate.bid.Price,
+			Duration:   candidate.duration,
+			Category:   candidate.category,
+			Advertiser: candidate.advertiser,
+			TieBreaker: candidate.seat + "\x00" + candidate.bid.ID,
+		}
+	}
+	packed := adpod.Pack(packCandidates, adpod.Constraints{
+		MaxDuration:         pod.AdPodDurationSec,
+		MaxAds:              pod.MaxAds,
+		CategoryExclusion:   categoryExclusion,
+		AdvertiserExclusion: advertiserExclusion,
+	})
+
+	for _, index := range packed.Selected {
+		result.selected = append(result.selected, candidates[index])
+	}
+	for index, candidate := range candidates {
+		if reason, ok := packed.Rejected[index]; ok {
+			result.rejectedBids = append(result.rejectedBids, newRejectedPodBid(candidate, reason))
+		}
+	}
+	return result
+}
+
+func newRejectedPodBid(candidate podCandidate, reason adpod.RejectionReason) openrtb_ext.RejectedPodBid {
+	return openrtb_ext.RejectedPodBid{
+		BidID:    candidate.bid.ID,
+		Seat:     candidate.seat,
+		Price:    candidate.bid.Price,
+		Duration: candidate.duration,
+		Reason:   string(reason),
+	}
+}
+
+// addRejectedPodBids reports the bids left out of each pod in the video response
+func addRejectedPodBids(bidResp *openrtb_ext.BidResponseVideo, results []podPackingResult) {
+	for _, result := range results {
+		if len(result.rejectedBids) == 0 {
+			continue
+		}
+		adPod := findAdPod(int64(result.podId), bidResp.AdPods)
+		if adPod == nil {
+			adPod = &openrtb_ext.AdPod{
+				PodId:     int64(result.podId),
+				Targeting: make([]openrtb_ext.VideoTargeting, 0),
+			}
+			bidResp.AdPods = append(bidResp.AdPods, adPod)
+		}
+		adPod.RejectedBids = result.rejectedBids
+	}
+}
+
+// addPodPackingDebug adds a summary of every packed pod to ext.debug.adpodpacking
+func addPodPackingDebug(ext json.RawMessage, results []podPackingResult) (json.RawMessage, error) {
+	summaries := make([]podPackingDebug, 0, len(results))
+	for _, result := range results {
+		summary := podPackingDebug{
+			PodId:        result.podId,
+			DurationSec:  result.durationSec,
+			SelectedBids: make([]string, 0, len(result.selected)),
+			RejectedBids: result.rejectedBids,
+		}
+		var revenue int64
+		for _, candidate := range result.selected {
+			summary.FilledSec += candidate.duration
+			summary.SelectedBids = append(summary.SelectedBids, candidate.bid.ID)
+			revenue += candidate.price
+		}
+		summary.Revenue = float64(revenue) / 1e6
+		summaries = append(summaries, summary)
+	}
+
+	summariesJSON, err := json.Marshal(summaries)
+	if err != nil {
+		return ext, err
+	}
+	if len(ext) == 0 {
+		ext = json.RawMessage(`{}`)
+	}
+	return jsonparser.Set(ext, summariesJSON, "debug", "adpodpacking")
+}
@@ -0,0 +1,225 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/adpod"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func podTestBid(id, impId string, price float64, duration int, category, advertiser string) openrtb2.Bid {
+	ext := fmt.Sprintf(`{"prebid":{"targeting":{"hb_uuid_appnexus":"uuid-%s"},"video":{"duration":%d,"primary_category":"%s"}}}`, id, duration, category)
+	bid := openrtb2.Bid{ID: id, ImpID: impId, Price: price, Ext: json.RawMessage(ext)}
+	if advertiser != "" {
+		bid.ADomain = []string{advertiser}
+	}
+	return bid
+}
+
+func bidIDs(bidresponse *openrtb2.BidResponse) []string {
+	ids := make([]string, 0)
+	for _, seatBid := range bidresponse.SeatBid {
+		for _, bid := range seatBid.Bid {
+			ids = append(ids, bid.ID)
+		}
+	}
+	return ids
+}
+
+func TestPackAdPods(t *testing.T) {
+	testCases := []struct {
+		description      string
+		podConfig        openrtb_ext.PodConfig
+		bids             []openrtb2.Bid
+		expectedBidIDs   []string
+		expectedRejected []openrtb_ext.RejectedPodBid
+	}{
+		{
+			description: "All bids fit",
+			podConfig:   openrtb_ext.PodConfig{Pods: []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 60}}},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 5, 30, "", ""),
+				podTestBid("b", "1_1", 4, 30, "", ""),
+			},
+			expectedBidIDs: []string{"a", "b"},
+		},
+		{
+			description: "Two cheaper bids beat the most expensive one",
+			podConfig:   openrtb_ext.PodConfig{Pods: []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 60}}},
+			bids: []openrtb2.Bid{
+				podTestBid("long", "1_0", 10, 60, "", ""),
+				podTestBid("short1", "1_1", 6, 30, "", ""),
+				podTestBid("short2", "1_2", 6, 30, "", ""),
+			},
+			expectedBidIDs: []string{"short1", "short2"},
+			expectedRejected: []openrtb_ext.RejectedPodBid{
+				{BidID: "long", Seat: "appnexus", Price: 10, Duration: 60, Reason: string(adpod.RejectPodDuration)},
+			},
+		},
+		{
+			description: "Bid longer than the pod",
+			podConfig:   openrtb_ext.PodConfig{Pods: []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 30}}},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 5, 45, "", ""),
+				podTestBid("b", "1_1", 1, 15, "", ""),
+			},
+			expectedBidIDs: []string{"b"},
+			expectedRejected: []openrtb_ext.RejectedPodBid{
+				{BidID: "a", Seat: "appnexus", Price: 5, Duration: 45, Reason: string(adpod.RejectDurationExceedsPod)},
+			},
+		},
+		{
+			description: "Max ads",
+			podConfig:   openrtb_ext.PodConfig{Pods: []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 90, MaxAds: 2}}},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 1, 15, "", ""),
+				podTestBid("b", "1_1", 3, 15, "", ""),
+				podTestBid("c", "1_2", 2, 15, "", ""),
+			},
+			expectedBidIDs: []string{"b", "c"},
+			expectedRejected: []openrtb_ext.RejectedPodBid{
+				{BidID: "a", Seat: "appnexus", Price: 1, Duration: 15, Reason: string(adpod.RejectMaxAds)},
+			},
+		},
+		{
+			description: "Category and advertiser exclusions prefer the best combination over the best bid",
+			podConfig: openrtb_ext.PodConfig{
+				CategoryExclusion:   true,
+				AdvertiserExclusion: true,
+				Pods:                []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 90}},
+			},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 5, 30, "IAB1", "brand.com"),
+				podTestBid("b", "1_1", 4, 30, "IAB1", "other.com"),
+				podTestBid("c", "1_2", 3, 30, "IAB2", "Brand.com"),
+				podTestBid("d", "1_3", 2, 30, "IAB3", "third.com"),
+			},
+			expectedBidIDs: []string{"b", "c", "d"},
+			expectedRejected: []openrtb_ext.RejectedPodBid{
+				{BidID: "a", Seat: "appnexus", Price: 5, Duration: 30, Reason: string(adpod.RejectCategoryExclusion)},
+			},
+		},
+		{
+			description: "Advertiser exclusion ignores the domain case",
+			podConfig: openrtb_ext.PodConfig{
+				AdvertiserExclusion: true,
+				Pods:                []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 60}},
+			},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "1_0", 5, 30, "", "brand.com"),
+				podTestBid("b", "1_1", 4, 30, "", "Brand.com"),
+				podTestBid("c", "1_2", 1, 30, "", ""),
+			},
+			expectedBidIDs: []string{"a", "c"},
+			expectedRejected: []openrtb_ext.RejectedPodBid{
+				{BidID: "b", Seat: "appnexus", Price: 4, Duration: 30, Reason: string(adpod.RejectAdvertiserExclusion)},
+			},
+		},
+		{
+			description: "Ties are broken by shorter duration",
+			podConfig:   openrtb_ext.PodConfig{Pods: []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 30}}},
+			bids: []openrtb2.Bid{
+				podTestBid("z", "1_0", 5, 30, "", ""),
+				podTestBid("x", "1_1", 5, 20, "", ""),
+			},
+			expectedBidIDs: []string{"x"},
+			expectedRejected: []openrtb_ext.RejectedPodBid{
+				{BidID: "z", Seat: "appnexus", Price: 5, Duration: 30, Reason: string(adpod.RejectPodDuration)},
+			},
+		},
+		{
+			description: "Ties are broken by bid id",
+			podConfig:   openrtb_ext.PodConfig{Pods: []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 30}}},
+			bids: []openrtb2.Bid{
+				podTestBid("z", "1_0", 5, 30, "", ""),
+				podTestBid("y", "1_1", 5, 30, "", ""),
+			},
+			expectedBidIDs: []string{"y"},
+			expectedRejected: []openrtb_ext.RejectedPodBid{
+				{BidID: "z", Seat: "appnexus", Price: 5, Duration: 30, Reason: string(adpod.RejectPodDuration)},
+			},
+		},
+		{
+			description: "Bids of unknown pods and uncached bids are left untouched",
+			podConfig:   openrtb_ext.PodConfig{Pods: []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 15}}},
+			bids: []openrtb2.Bid{
+				podTestBid("a", "2_0", 5, 30, "", ""),
+				{ID: "b", ImpID: "1_0", Price: 5, Ext: json.RawMessage(`{"prebid":{"targeting":{}}}`)},
+			},
+			expectedBidIDs: []string{"a", "b"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			bidresponse := &openrtb2.BidResponse{SeatBid: []openrtb2.SeatBid{{Seat: "appnexus", Bid: test.bids}}}
+
+			results := packAdPods(bidresponse, test.podConfig, nil)
+
+			assert.ElementsMatch(t, test.expectedBidIDs, bidIDs(bidresponse))
+			var rejected []openrtb_ext.RejectedPodBid
+			for _, result := range results {
+				rejected = append(rejected, result.rejectedBids...)
+			}
+			assert.ElementsMatch(t, test.expectedRejected, rejected)
+		})
+	}
+}
+
+func TestPackAdPodsDurationFromImp(t *testing.T) {
+	bids := []openrtb2.Bid{
+		{ID: "a", ImpID: "1_0", Price: 2, Ext: json.RawMessage(`{"prebid":{"targeting":{"hb_uuid_appnexus":"uuid"}}}`)},
+		{ID: "b", ImpID: "1_1", Price: 1, Ext: json.RawMessage(`{"prebid":{"targeting":{"hb_uuid_appnexus":"uuid"}}}`)},
+	}
+	imps := []openrtb2.Imp{
+		{ID: "1_0", Video: &openrtb2.Video{MaxDuration: 30}},
+		{ID: "1_1", Video: &openrtb2.Video{MaxDuration: 30}},
+	}
+	bidresponse := &openrtb2.BidResponse{SeatBid: []openrtb2.SeatBid{{Seat: "appnexus", Bid: bids}}}
+
+	results := packAdPods(bidresponse, openrtb_ext.PodConfig{Pods: []openrtb_ext.Pod{{PodId: 1, AdPodDurationSec: 45}}}, imps)
+
+	assert.Equal(t, []string{"a"}, bidIDs(bidresponse))
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, []openrtb_ext.RejectedPodBid{{BidID: "b", Seat: "appnexus", Price: 1, Duration: 30, Reason: string(adpod.RejectPodDuration)}}, results[0].rejectedBids)
+	}
+}
+
+func TestAddRejectedPodBidsAndDebug(t *testing.T) {
+	selected := openrtb2.Bid{ID: "a", Price: 1.5}
+	results := []podPackingResult{
+		{
+			podId:        1,
+			durationSec:  30,
+			selected:     []podCandidate{{bid: &selected, price: 1500000, duration: 30}},
+			rejectedBids: []openrtb_ext.RejectedPodBid{{BidID: "b", Seat: "appnexus", Price: 1, Duration: 30, Reason: string(adpod.RejectPodDuration)}},
+		},
+		{
+			podId:        2,
+			durationSec:  15,
+			rejectedBids: []openrtb_ext.RejectedPodBid{{BidID: "c", Seat: "appnexus", Price: 1, Duration: 30, Reason: string(adpod.RejectDurationExceedsPod)}},
+		},
+	}
+
+	bidResp := &openrtb_ext.BidResponseVideo{AdPods: []*openrtb_ext.AdPod{{PodId: 1}}}
+	addRejectedPodBids(bidResp, results)
+
+	if assert.Len(t, bidResp.AdPods, 2) {
+		assert.Equal(t, results[0].rejectedBids, bidResp.AdPods[0].RejectedBids)
+		assert.Equal(t, int64(2), bidResp.AdPods[1].PodId)
+		assert.Equal(t, results[1].rejectedBids, bidResp.AdPods[1].RejectedBids)
+	}
+
+	ext, err := addPodPackingDebug(json.RawMessage(`{"debug":{"resolvedrequest":{}}}`), results)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"debug":{"resolvedrequest":{},"adpodpacking":[
+		{"podid":1,"adpoddurationsec":30,"filledsec":30,"revenue":1.5,"selectedbids":["a"],
+		 "rejectedbids":[{"bidid":"b","seat":"appnexus","price":1,"duration":30,"reason":"pod_duration"}]},
+		{"podid":2,"adpoddurationsec":15,"filledsec":0,"revenue":0,"selectedbids":[],
+		 "rejectedbids":[{"bidid":"c","seat":"appnexus","price":1,"duration":30,"reason":"duration_exceeds_pod"}]}
+	]}}`, string(ext))
+}
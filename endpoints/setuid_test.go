@@ -256,7 +256,7 @@ func TestSetUIDEndpoint(t *testing.T) {
 		},
 	}
 
-	analytics := analyticsConf.NewPBSAnalytics(&config.Analytics{})
+	analytics := analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConf.NilMetricsEngine{})
 	metrics := &metricsConf.NilMetricsEngine{}
 
 	for _, test := range testCases {
@@ -566,7 +566,7 @@ func TestOptedOut(t *testing.T) {
 	cookie.SetOptOut(true)
 	addCookie(request, cookie)
 	syncersBidderNameToKey := map[string]string{"pubmatic": "pubmatic"}
-	analytics := analyticsConf.NewPBSAnalytics(&config.Analytics{})
+	analytics := analyticsConf.NewPBSAnalytics(&config.Analytics{}, &metricsConf.NilMetricsEngine{})
 	metrics := &metricsConf.NilMetricsEngine{}
 	response := doRequest(request, analytics, metrics, syncersBidderNameToKey, true, false, false, false)
 
@@ -742,7 +742,7 @@ func doRequest(req *http.Request, analytics analytics.PBSAnalyticsModule, metric
 		"invalid_json_acct": json.RawMessage(`{"}`),
 	}}
 
-	endpoint := NewSetUIDEndpoint(&cfg, syncersByBidder, gdprPermsBuilder, tcf2ConfigBuilder, analytics, fakeAccountsFetcher, metrics)
+	endpoint := NewSetUIDEndpoint(&cfg, syncersByBidder, gdprPermsBuilder, tcf2ConfigBuilder, analytics, fakeAccountsFetcher, metrics, nil)
 	response := httptest.NewRecorder()
 	endpoint(response, req, nil)
 	return response
@@ -827,6 +827,6 @@ func (s fakeSyncer) SupportsType(syncTypes []usersync.SyncType) bool {
 	return true
 }
 
-func (s fakeSyncer) GetSync(syncTypes []usersync.SyncType, privacyPolicies privacy.Policies) (usersync.Sync, error) {
+func (s fakeSyncer) GetSync(syncTypes []usersync.SyncType, privacyPolicies privacy.Policies, requestInfo usersync.SyncRequestInfo) (usersync.Sync, error) {
 	return usersync.Sync{}, nil
 }
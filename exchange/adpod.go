@@ -0,0 +1,417 @@
+package exchange
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prebid/openrtb/v17/adcom1"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/adpod"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/exchange/entities"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const (
+	// defaultPodSlotDuration is the duration assumed for the ads of a dynamic pod which sets neither
+	// maxseq, rqddurs nor minduration, to work out how many imps the pod is expanded into.
+	defaultPodSlotDuration = 15
+	// maxPodSlotImps caps the number of imps a dynamic pod is expanded into
+	maxPodSlotImps = 20
+)
+
+// adPod is a video pod of the request: a dynamic pod filled from a single imp with a pod duration, or
+// a structured pod made of the imps sharing a pod id, one imp per slot.
+type adPod struct {
+	podID   int64
+	podSeq  adcom1.PodSequence
+	dynamic bool
+	impIDs  []string // play order of the slots for structured pods
+	imps    map[string]*openrtb2.Imp
+
+	selected []podBid // play order, set by enforcePodConstraints
+}
+
+// podBid is a bid competing for a place in a pod
+type podBid struct {
+	seat     openrtb_ext.BidderName
+	bid      *entities.PbsOrtbBid
+	duration int
+}
+
+// findAdPods returns the pods of the request in the order they play in the content stream, the pod
+// sequence, then in the order they first appear in the imps
+func findAdPods(imps []openrtb2.Imp) []*adPod {
+	var pods []*adPod
+	structured := make(map[int64]*adPod)
+	for i := range imps {
+		imp := &imps[i]
+		if imp.Video == nil {
+			continue
+		}
+		if imp.Video.PodDur > 0 {
+			pods = append(pods, &adPod{
+				podID:   imp.Video.PodID,
+				podSeq:  imp.Video.PodSeq,
+				dynamic: true,
+				impIDs:  []string{imp.ID},
+				imps:    map[string]*openrtb2.Imp{imp.ID: imp},
+			})
+			continue
+		}
+		if imp.Video.PodID == 0 {
+			continue
+		}
+		pod, ok := structured[imp.Video.PodID]
+		if !ok {
+			pod = &adPod{podID: imp.Video.PodID, podSeq: imp.Video.PodSeq, imps: make(map[string]*openrtb2.Imp)}
+			structured[imp.Video.PodID] = pod
+			pods = append(pods, pod)
+		}
+		pod.impIDs = append(pod.impIDs, imp.ID)
+		pod.imps[imp.ID] = imp
+	}
+
+	for _, pod := range structured {
+		sort.SliceStable(pod.impIDs, func(i, j int) bool {
+			return slotOrder(pod.imps[pod.impIDs[i]].Video.SlotInPod) < slotOrder(pod.imps[pod.impIDs[j]].Video.SlotInPod)
+		})
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		return podSeqOrder(pods[i].podSeq) < podSeqOrder(pods[j].podSeq)
+	})
+	return pods
+}
+
+// podSeqOrder ranks pod sequences in play order
+func podSeqOrder(seq adcom1.PodSequence) int {
+	switch seq {
+	case adcom1.PodSeqFirst:
+		return 0
+	case adcom1.PodSeqLast:
+		return 2
+	}
+	return 1
+}
+
+// slotOrder ranks slot positions in play order
+func slotOrder(slot adcom1.SlotPositionInPod) int {
+	switch slot {
+	case adcom1.SlotPosFirst:
+		return 0
+	case adcom1.SlotPosLast:
+		return 3
+	case adcom1.SlotPosFirstOrLast:
+		return 2
+	}
+	return 1
+}
+
+// expandDynamicPods replaces the dynamic pod imps sent to bidders which can't fill a dynamic pod with one
+// imp per slot, forming a structured pod. It returns the ids of the expanded imps mapped to the id of the
+// dynamic pod imp they were made from.
+func expandDynamicPods(bidderRequests []BidderRequest, bidderInfos config.BidderInfos) map[string]string {
+	expandedImps := make(map[string]string)
+	for _, bidderRequest := range bidderRequests {
+		if bidderInfo, ok := bidderInfos[string(bidderRequest.BidderCoreName)]; ok && bidderInfo.OpenRTB != nil && bidderInfo.OpenRTB.DynamicPodSupported {
+			continue
+		}
+
+		request := bidderRequest.BidRequest
+		imps := make([]openrtb2.Imp, 0, len(request.Imp))
+		for _, imp := range request.Imp {
+			if imp.Video == nil || imp.Video.PodDur <= 0 || bidderRequest.BidderStoredResponses[imp.ID] != nil {
+				imps = append(imps, imp)
+				continue
+			}
+			for i, slotImp := range expandDynamicPod(imp) {
+				slotImp.ID = fmt.Sprintf("%s-pod%d", imp.ID, i+1)
+				expandedImps[slotImp.ID] = imp.ID
+				imps = append(imps, slotImp)
+			}
+		}
+		request.Imp = imps
+	}
+	return expandedImps
+}
+
+func expandDynamicPod(imp openrtb2.Imp) []openrtb2.Imp {
+	video := *imp.Video
+
+	slots := int(video.MaxSeq)
+	if slots == 0 {
+		slotDuration := video.MinDuration
+		for _, duration := range video.RqdDurs {
+			if slotDuration == 0 || duration < slotDuration {
+				slotDuration = duration
+			}
+		}
+		if slotDuration <= 0 {
+			slotDuration = defaultPodSlotDuration
+		}
+		slots = int(video.PodDur / slotDuration)
+	}
+	if slots < 1 {
+		slots = 1
+	} else if slots > maxPodSlotImps {
+		slots = maxPodSlotImps
+	}
+
+	video.PodDur = 0
+	video.MaxSeq = 0
+	video.SlotInPod = adcom1.SlotPosAny
+	if video.MaxDuration == 0 || video.MaxDuration > imp.Video.PodDur {
+		video.MaxDuration = imp.Video.PodDur
+	}
+
+	slotImps := make([]openrtb2.Imp, slots)
+	for i := range slotImps {
+		slotVideo := video
+		slotImps[i] = imp
+		slotImps[i].Video = &slotVideo
+	}
+	return slotImps
+}
+
+// restoreExpandedImpIDs points the bids made for expanded imps back to the dynamic pod imp
+func restoreExpandedImpIDs(adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, expandedImps map[string]string) {
+	if len(expandedImps) == 0 {
+		return
+	}
+	for _, seatBid := range adapterBids {
+		for _, pbsBid := range seatBid.Bids {
+			if impID, ok := expandedImps[pbsBid.Bid.ImpID]; ok {
+				pbsBid.Bid.ImpID = impID
+			}
+		}
+	}
+}
+
+// enforcePodConstraints removes the pod bids which don't respect the duration, price and slot position
+// requirements of their imp. The bids of every dynamic pod are then packed to the combination with the
+// highest revenue fitting in the pod duration and number of ads, and respecting the category and
+// advertiser exclusions of the pod config, and the other bids are removed.
+func enforcePodConstraints(pods []*adPod, adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, conversions currency.Conversions, podConfig *openrtb_ext.ExtRequestPodConfig) []string {
+	if len(pods) == 0 {
+		return nil
+	}
+	impPods := make(map[string]*adPod)
+	for _, pod := range pods {
+		for _, impID := range pod.impIDs {
+			impPods[impID] = pod
+		}
+	}
+
+	var rejections []string
+	candidates := make(map[*adPod][]podBid)
+	rejected := make(map[*entities.PbsOrtbBid]bool)
+	for seat, seatBid := range adapterBids {
+		for _, pbsBid := range seatBid.Bids {
+			pod, ok := impPods[pbsBid.Bid.ImpID]
+			if !ok {
+				continue
+			}
+			imp := pod.imps[pbsBid.Bid.ImpID]
+			duration := podBidDuration(pbsBid, imp)
+			if reason := checkPodBid(pbsBid.Bid, duration, imp, seatBid.Currency, conversions); reason != "" {
+				rejections = updateRejections(rejections, pbsBid.Bid.ID, reason)
+				rejected[pbsBid] = true
+				continue
+			}
+			candidates[pod] = append(candidates[pod], podBid{seat: seat, bid: pbsBid, duration: duration})
+		}
+	}
+
+	for _, pod := range pods {
+		if pod.dynamic {
+			rejections = append(rejections, packDynamicPod(pod, candidates[pod], rejected, podConfig)...)
+		} else {
+			pod.selected = selectStructuredPodBids(pod, candidates[pod])
+		}
+	}
+
+	if len(rejected) > 0 {
+		for _, seatBid := range adapterBids {
+			bids := make([]*entities.PbsOrtbBid, 0, len(seatBid.Bids))
+			for _, pbsBid := range seatBid.Bids {
+				if !rejected[pbsBid] {
+					bids = append(bids, pbsBid)
+				}
+			}
+			seatBid.Bids = bids
+		}
+	}
+	return rejections
+}
+
+// podBidDuration returns the duration of a bid: the 2.6 bid.dur, the duration reported by the bidder,
+// or else the longest duration allowed by the imp
+func podBidDuration(pbsBid *entities.PbsOrtbBid, imp *openrtb2.Imp) int {
+	if pbsBid.Bid.Dur > 0 {
+		return int(pbsBid.Bid.Dur)
+	}
+	if pbsBid.BidVideo != nil && pbsBid.BidVideo.Duration > 0 {
+		return pbsBid.BidVideo.Duration
+	}
+	return int(imp.Video.MaxDuration)
+}
+
+// checkPodBid returns the reason a bid doesn't respect the requirements of its pod imp, if any
+func checkPodBid(bid *openrtb2.Bid, duration int, imp *openrtb2.Imp, bidCurrency string, conversions currency.Conversions) string {
+	video := imp.Video
+	if duration <= 0 {
+		return "Bid duration is unknown"
+	}
+	if len(video.RqdDurs) > 0 {
+		allowed := false
+		for _, rqdDur := range video.RqdDurs {
+			allowed = allowed || int64(duration) == rqdDur
+		}
+		if !allowed {
+			return "Bid duration is not one of the required durations"
+		}
+	} else if (video.MinDuration > 0 && int64(duration) < video.MinDuration) || (video.MaxDuration > 0 && int64(duration) > video.MaxDuration) {
+		return "Bid duration is outside the allowed range"
+	}
+
+	if video.MinCPMPerSec > 0 {
+		floorCurrency := imp.BidFloorCur
+		if floorCurrency == "" {
+			floorCurrency = "USD"
+		}
+		if rate, err := conversions.GetRate(floorCurrency, bidCurrency); err == nil && bid.Price < video.MinCPMPerSec*float64(duration)*rate {
+			return "Bid price is below the minimum CPM per second"
+		}
+	}
+
+	if !slotsCompatible(video.SlotInPod, bid.SlotInPod) {
+		return "Bid slot in pod does not match the imp"
+	}
+	return ""
+}
+
+// slotsCompatible checks the slot position a creative must play at can be served by an imp
+func slotsCompatible(impSlot, bidSlot adcom1.SlotPositionInPod) bool {
+	if impSlot == adcom1.SlotPosAny || bidSlot == adcom1.SlotPosAny || impSlot == bidSlot {
+		return true
+	}
+	if impSlot == adcom1.SlotPosFirstOrLast {
+		return bidSlot == adcom1.SlotPosFirst || bidSlot == adcom1.SlotPosLast
+	}
+	return bidSlot == adcom1.SlotPosFirstOrLast
+}
+
+func packDynamicPod(pod *adPod, candidates []podBid, rejected map[*entities.PbsOrtbBid]bool, podConfig *openrtb_ext.ExtRequestPodConfig) []string {
+	imp := pod.imps[pod.impIDs[0]]
+
+	// bids are sorted so the packing doesn't depend on the order of the seats
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].seat != candidates[j].seat {
+			return candidates[i].seat < candidates[j].seat
+		}
+		return candidates[i].bid.Bid.ID < candidates[j].bid.Bid.ID
+	})
+	packCandidates := make([]adpod.Candidate, len(candidates))
+	for i, candidate := range candidates {
+		packCandidates[i] = adpod.Candidate{
+			Price:      candidate.bid.Bid.Price,
+			Duration:   candidate.duration,
+			Slot:       candidate.bid.Bid.SlotInPod,
+			TieBreaker: string(candidate.seat) + "\x00" + candidate.bid.Bid.ID,
+		}
+		if len(candidate.bid.Bid.Cat) > 0 {
+			packCandidates[i].Category = candidate.bid.Bid.Cat[0]
+		}
+		if len(candidate.bid.Bid.ADomain) > 0 {
+			packCandidates[i].Advertiser = strings.ToLower(candidate.bid.Bid.ADomain[0])
+		}
+	}
+
+	constraints := adpod.Constraints{
+		MaxDuration: int(imp.Video.PodDur),
+		MaxAds:      int(imp.Video.MaxSeq),
+	}
+	if podConfig != nil {
+		constraints.CategoryExclusion = podConfig.CategoryExclusion
+		constraints.AdvertiserExclusion = podConfig.AdvertiserExclusion
+	}
+	result := adpod.Pack(packCandidates, constraints)
+
+	pod.selected = make([]podBid, 0, len(result.Selected))
+	for _, index := range result.Selected {
+		pod.selected = append(pod.selected, candidates[index])
+	}
+	var rejections []string
+	for index, candidate := range candidates {
+		if reason, ok := result.Rejected[index]; ok {
+			rejected[candidate.bid] = true
+			rejections = updateRejections(rejections, candidate.bid.Bid.ID, fmt.Sprintf("Bid does not fit in the ad pod (%s)", reason))
+		}
+	}
+	return rejections
+}
+
+// selectStructuredPodBids returns the highest bid of every slot of a structured pod, in play order
+func selectStructuredPodBids(pod *adPod, candidates []podBid) []podBid {
+	best := make(map[string]podBid)
+	for _, candidate := range candidates {
+		impID := candidate.bid.Bid.ImpID
+		current, ok := best[impID]
+		if !ok || candidate.bid.Bid.Price > current.bid.Bid.Price ||
+			(candidate.bid.Bid.Price == current.bid.Bid.Price && (candidate.seat < current.seat || (candidate.seat == current.seat && candidate.bid.Bid.ID < current.bid.Bid.ID))) {
+			best[impID] = candidate
+		}
+	}
+
+	selected := make([]podBid, 0, len(best))
+	for _, impID := range pod.impIDs {
+		if candidate, ok := best[impID]; ok {
+			selected = append(selected, candidate)
+		}
+	}
+	return selected
+}
+
+// makeAdPodsResponse describes the bids filling every pod for bidresponse.ext.prebid.adpods. Bids removed
+// after the pods were filled, by the category deduplication for example, are left out.
+func makeAdPodsResponse(pods []*adPod, adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid) []openrtb_ext.ExtResponseAdPod {
+	if len(pods) == 0 {
+		return nil
+	}
+	remaining := make(map[*entities.PbsOrtbBid]bool)
+	for _, seatBid := range adapterBids {
+		for _, pbsBid := range seatBid.Bids {
+			remaining[pbsBid] = true
+		}
+	}
+
+	adPods := make([]openrtb_ext.ExtResponseAdPod, 0, len(pods))
+	for _, pod := range pods {
+		adPod := openrtb_ext.ExtResponseAdPod{
+			PodID:   pod.podID,
+			Dynamic: pod.dynamic,
+			Bids:    make([]openrtb_ext.ExtResponseAdPodBid, 0, len(pod.selected)),
+		}
+		if pod.dynamic {
+			adPod.ImpID = pod.impIDs[0]
+		}
+		for _, selected := range pod.selected {
+			if !remaining[selected.bid] {
+				continue
+			}
+			adPod.Duration += selected.duration
+			adPod.Bids = append(adPod.Bids, openrtb_ext.ExtResponseAdPodBid{
+				BidID:     selected.bid.Bid.ID,
+				ImpID:     selected.bid.Bid.ImpID,
+				Seat:      string(selected.seat),
+				Sequence:  len(adPod.Bids) + 1,
+				Duration:  selected.duration,
+				SlotInPod: selected.bid.Bid.SlotInPod,
+			})
+		}
+		adPods = append(adPods, adPod)
+	}
+	return adPods
+}
@@ -0,0 +1,270 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/prebid/openrtb/v17/adcom1"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/exchange/entities"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAdPods(t *testing.T) {
+	imps := []openrtb2.Imp{
+		{ID: "banner", Banner: &openrtb2.Banner{}},
+		{ID: "video", Video: &openrtb2.Video{}},
+		{ID: "last", Video: &openrtb2.Video{PodID: 1, SlotInPod: adcom1.SlotPosLast}},
+		{ID: "dynamic", Video: &openrtb2.Video{PodID: 2, PodDur: 60}},
+		{ID: "any", Video: &openrtb2.Video{PodID: 1}},
+		{ID: "first", Video: &openrtb2.Video{PodID: 1, SlotInPod: adcom1.SlotPosFirst}},
+	}
+
+	pods := findAdPods(imps)
+
+	if assert.Len(t, pods, 2) {
+		assert.Equal(t, int64(1), pods[0].podID)
+		assert.False(t, pods[0].dynamic)
+		assert.Equal(t, []string{"first", "any", "last"}, pods[0].impIDs)
+		assert.Equal(t, int64(2), pods[1].podID)
+		assert.True(t, pods[1].dynamic)
+		assert.Equal(t, []string{"dynamic"}, pods[1].impIDs)
+	}
+}
+
+func TestFindAdPodsInPodSequence(t *testing.T) {
+	imps := []openrtb2.Imp{
+		{ID: "last", Video: &openrtb2.Video{PodID: 1, PodDur: 60, PodSeq: adcom1.PodSeqLast}},
+		{ID: "any", Video: &openrtb2.Video{PodID: 2, PodDur: 60}},
+		{ID: "first", Video: &openrtb2.Video{PodID: 3, PodSeq: adcom1.PodSeqFirst}},
+		{ID: "other-any", Video: &openrtb2.Video{PodID: 4, PodDur: 30}},
+	}
+
+	pods := findAdPods(imps)
+
+	podIDs := make([]int64, 0, len(pods))
+	for _, pod := range pods {
+		podIDs = append(podIDs, pod.podID)
+	}
+	assert.Equal(t, []int64{3, 2, 4, 1}, podIDs)
+}
+
+func TestExpandDynamicPods(t *testing.T) {
+	testCases := []struct {
+		description     string
+		video           openrtb2.Video
+		expectedImpIDs  []string
+		expectedMaxDurs int64
+	}{
+		{
+			description:     "Number of slots from maxseq",
+			video:           openrtb2.Video{PodDur: 60, MaxSeq: 2, MaxDuration: 30},
+			expectedImpIDs:  []string{"imp-pod1", "imp-pod2"},
+			expectedMaxDurs: 30,
+		},
+		{
+			description:     "Number of slots from the shortest required duration",
+			video:           openrtb2.Video{PodDur: 60, RqdDurs: []int64{30, 20}},
+			expectedImpIDs:  []string{"imp-pod1", "imp-pod2", "imp-pod3"},
+			expectedMaxDurs: 60,
+		},
+		{
+			description:     "Number of slots from the min duration",
+			video:           openrtb2.Video{PodDur: 45, MinDuration: 30, MaxDuration: 90},
+			expectedImpIDs:  []string{"imp-pod1"},
+			expectedMaxDurs: 45,
+		},
+		{
+			description:     "Number of slots from the default duration",
+			video:           openrtb2.Video{PodDur: 30},
+			expectedImpIDs:  []string{"imp-pod1", "imp-pod2"},
+			expectedMaxDurs: 30,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			video := test.video
+			supporting := &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp", Video: &video}}}
+			expanding := &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp", Video: &video}, {ID: "other", Video: &openrtb2.Video{}}}}
+			bidderRequests := []BidderRequest{
+				{BidderCoreName: "supporting", BidRequest: supporting},
+				{BidderCoreName: "expanding", BidRequest: expanding},
+			}
+			bidderInfos := config.BidderInfos{
+				"supporting": {OpenRTB: &config.OpenRTBInfo{DynamicPodSupported: true}},
+				"expanding":  {},
+			}
+
+			expandedImps := expandDynamicPods(bidderRequests, bidderInfos)
+
+			assert.Equal(t, []openrtb2.Imp{{ID: "imp", Video: &video}}, supporting.Imp, "bidders supporting dynamic pods get the pod imp")
+			impIDs := make([]string, 0, len(expanding.Imp))
+			for _, imp := range expanding.Imp {
+				impIDs = append(impIDs, imp.ID)
+				if imp.ID == "other" {
+					continue
+				}
+				assert.Equal(t, "imp", expandedImps[imp.ID])
+				assert.Equal(t, int64(0), imp.Video.PodDur)
+				assert.Equal(t, int64(0), imp.Video.MaxSeq)
+				assert.Equal(t, test.expectedMaxDurs, imp.Video.MaxDuration)
+			}
+			assert.Equal(t, append(test.expectedImpIDs, "other"), impIDs)
+			assert.Len(t, expandedImps, len(test.expectedImpIDs))
+			assert.Equal(t, test.video, video, "the request video must not be modified")
+		})
+	}
+}
+
+func TestEnforcePodConstraints(t *testing.T) {
+	imps := []openrtb2.Imp{
+		{ID: "dynamic", Video: &openrtb2.Video{PodID: 1, PodDur: 60, MaxSeq: 3, MaxDuration: 30, MinCPMPerSec: 0.1}},
+		{ID: "first", Video: &openrtb2.Video{PodID: 2, SlotInPod: adcom1.SlotPosFirst, RqdDurs: []int64{15, 30}}},
+		{ID: "any", Video: &openrtb2.Video{PodID: 2}},
+		{ID: "plain", Video: &openrtb2.Video{}},
+	}
+	newBid := func(id, impID string, price float64, dur int64, slot adcom1.SlotPositionInPod) *entities.PbsOrtbBid {
+		return &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: id, ImpID: impID, Price: price, Dur: dur, SlotInPod: slot}, BidType: openrtb_ext.BidTypeVideo}
+	}
+	appnexusBids := []*entities.PbsOrtbBid{
+		newBid("a1", "dynamic-pod1", 5, 30, adcom1.SlotPosAny),
+		newBid("a2", "dynamic-pod2", 4, 15, adcom1.SlotPosLast),
+		newBid("a3", "dynamic-pod3", 1, 30, adcom1.SlotPosAny),
+		newBid("a4", "dynamic-pod1", 2, 15, adcom1.SlotPosAny),
+		newBid("a5", "first", 3, 20, adcom1.SlotPosAny),
+		newBid("a6", "any", 3, 15, adcom1.SlotPosAny),
+	}
+	rubiconBids := []*entities.PbsOrtbBid{
+		newBid("r1", "dynamic", 3, 15, adcom1.SlotPosFirst),
+		newBid("r2", "dynamic", 10, 45, adcom1.SlotPosAny),
+		newBid("r3", "first", 2, 30, adcom1.SlotPosFirstOrLast),
+		newBid("r4", "first", 2, 15, adcom1.SlotPosLast),
+		newBid("r5", "plain", 1, 0, adcom1.SlotPosAny),
+	}
+	adapterBids := map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+		"appnexus": {Bids: appnexusBids, Currency: "USD"},
+		"rubicon":  {Bids: rubiconBids, Currency: "USD"},
+	}
+	expandedImps := map[string]string{"dynamic-pod1": "dynamic", "dynamic-pod2": "dynamic", "dynamic-pod3": "dynamic"}
+	pods := findAdPods(imps)
+
+	restoreExpandedImpIDs(adapterBids, expandedImps)
+	rejections := enforcePodConstraints(pods, adapterBids, currency.NewConstantRates(), nil)
+
+	assert.ElementsMatch(t, []string{
+		"bid rejected [bid ID: a3] reason: Bid price is below the minimum CPM per second",
+		"bid rejected [bid ID: a4] reason: Bid does not fit in the ad pod (max_ads)",
+		"bid rejected [bid ID: a5] reason: Bid duration is not one of the required durations",
+		"bid rejected [bid ID: r2] reason: Bid duration is outside the allowed range",
+		"bid rejected [bid ID: r4] reason: Bid slot in pod does not match the imp",
+	}, rejections)
+	assert.Equal(t, []*entities.PbsOrtbBid{appnexusBids[0], appnexusBids[1], appnexusBids[5]}, adapterBids["appnexus"].Bids)
+	assert.Equal(t, []*entities.PbsOrtbBid{rubiconBids[0], rubiconBids[2], rubiconBids[4]}, adapterBids["rubicon"].Bids)
+
+	// bids removed after the pods were filled are left out of the response
+	adapterBids["appnexus"].Bids = adapterBids["appnexus"].Bids[:2]
+	assert.Equal(t, []openrtb_ext.ExtResponseAdPod{
+		{
+			PodID:    1,
+			ImpID:    "dynamic",
+			Dynamic:  true,
+			Duration: 60,
+			Bids: []openrtb_ext.ExtResponseAdPodBid{
+				{BidID: "r1", ImpID: "dynamic", Seat: "rubicon", Sequence: 1, Duration: 15, SlotInPod: adcom1.SlotPosFirst},
+				{BidID: "a1", ImpID: "dynamic", Seat: "appnexus", Sequence: 2, Duration: 30},
+				{BidID: "a2", ImpID: "dynamic", Seat: "appnexus", Sequence: 3, Duration: 15, SlotInPod: adcom1.SlotPosLast},
+			},
+		},
+		{
+			PodID:    2,
+			Duration: 30,
+			Bids: []openrtb_ext.ExtResponseAdPodBid{
+				{BidID: "r3", ImpID: "first", Seat: "rubicon", Sequence: 1, Duration: 30, SlotInPod: adcom1.SlotPosFirstOrLast},
+			},
+		},
+	}, makeAdPodsResponse(pods, adapterBids))
+}
+
+func TestEnforcePodConstraintsExclusions(t *testing.T) {
+	testCases := []struct {
+		description        string
+		podConfig          *openrtb_ext.ExtRequestPodConfig
+		expectedRejections []string
+		expectedBidIDs     []string
+	}{
+		{
+			description:    "No Pod Config",
+			expectedBidIDs: []string{"b1", "b2", "b3", "b4"},
+		},
+		{
+			description: "Category Exclusion",
+			podConfig:   &openrtb_ext.ExtRequestPodConfig{CategoryExclusion: true},
+			expectedRejections: []string{
+				"bid rejected [bid ID: b2] reason: Bid does not fit in the ad pod (category_exclusion)",
+			},
+			expectedBidIDs: []string{"b1", "b3", "b4"},
+		},
+		{
+			description: "Advertiser Exclusion",
+			podConfig:   &openrtb_ext.ExtRequestPodConfig{AdvertiserExclusion: true},
+			expectedRejections: []string{
+				"bid rejected [bid ID: b4] reason: Bid does not fit in the ad pod (advertiser_exclusion)",
+			},
+			expectedBidIDs: []string{"b1", "b2", "b3"},
+		},
+		{
+			description: "Both Exclusions",
+			podConfig:   &openrtb_ext.ExtRequestPodConfig{CategoryExclusion: true, AdvertiserExclusion: true},
+			expectedRejections: []string{
+				"bid rejected [bid ID: b2] reason: Bid does not fit in the ad pod (category_exclusion)",
+				"bid rejected [bid ID: b4] reason: Bid does not fit in the ad pod (advertiser_exclusion)",
+			},
+			expectedBidIDs: []string{"b1", "b3"},
+		},
+	}
+
+	for _, test := range testCases {
+		imps := []openrtb2.Imp{{ID: "dynamic", Video: &openrtb2.Video{PodID: 1, PodDur: 60}}}
+		bids := []*entities.PbsOrtbBid{
+			{Bid: &openrtb2.Bid{ID: "b1", ImpID: "dynamic", Price: 10, Dur: 15, Cat: []string{"IAB1"}, ADomain: []string{"brand.com"}}},
+			{Bid: &openrtb2.Bid{ID: "b2", ImpID: "dynamic", Price: 3, Dur: 15, Cat: []string{"IAB1"}, ADomain: []string{"other.com"}}},
+			{Bid: &openrtb2.Bid{ID: "b3", ImpID: "dynamic", Price: 2, Dur: 15, Cat: []string{"IAB2"}, ADomain: []string{"third.com"}}},
+			{Bid: &openrtb2.Bid{ID: "b4", ImpID: "dynamic", Price: 1, Dur: 15, Cat: []string{"IAB3"}, ADomain: []string{"Brand.com"}}},
+		}
+		adapterBids := map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+			"appnexus": {Bids: bids, Currency: "USD"},
+		}
+
+		rejections := enforcePodConstraints(findAdPods(imps), adapterBids, currency.NewConstantRates(), test.podConfig)
+
+		assert.ElementsMatch(t, test.expectedRejections, rejections, test.description)
+		bidIDs := make([]string, 0, len(adapterBids["appnexus"].Bids))
+		for _, pbsBid := range adapterBids["appnexus"].Bids {
+			bidIDs = append(bidIDs, pbsBid.Bid.ID)
+		}
+		assert.Equal(t, test.expectedBidIDs, bidIDs, test.description)
+	}
+}
+
+func TestSlotsCompatible(t *testing.T) {
+	testCases := []struct {
+		impSlot  adcom1.SlotPositionInPod
+		bidSlot  adcom1.SlotPositionInPod
+		expected bool
+	}{
+		{impSlot: adcom1.SlotPosAny, bidSlot: adcom1.SlotPosLast, expected: true},
+		{impSlot: adcom1.SlotPosFirst, bidSlot: adcom1.SlotPosAny, expected: true},
+		{impSlot: adcom1.SlotPosFirst, bidSlot: adcom1.SlotPosFirst, expected: true},
+		{impSlot: adcom1.SlotPosFirst, bidSlot: adcom1.SlotPosLast, expected: false},
+		{impSlot: adcom1.SlotPosFirst, bidSlot: adcom1.SlotPosFirstOrLast, expected: true},
+		{impSlot: adcom1.SlotPosFirstOrLast, bidSlot: adcom1.SlotPosLast, expected: true},
+		{impSlot: adcom1.SlotPosLast, bidSlot: adcom1.SlotPosFirst, expected: false},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, slotsCompatible(test.impSlot, test.bidSlot), "imp slot %d, bid slot %d", test.impSlot, test.bidSlot)
+	}
+}
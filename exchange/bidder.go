@@ -2,7 +2,6 @@ package exchange
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -13,24 +12,27 @@ import (
 	"net/http/httptrace"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/config/util"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/exchange/entities"
 	"github.com/prebid/prebid-server/experiment/adscert"
 	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/version"
 
 	nativeRequests "github.com/prebid/openrtb/v17/native1/request"
 	nativeResponse "github.com/prebid/openrtb/v17/native1/response"
 	"github.com/prebid/openrtb/v17/openrtb2"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/util/compressutil"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -65,30 +67,39 @@ type bidRequestOptions struct {
 	headerDebugAllowed  bool
 	addCallSignHeader   bool
 	bidAdjustments      map[string]float64
+	accountShadow       config.AccountShadow
 }
 
 const ImpIdReqBody = "Stored bid response for impression id: "
 
 // Possible values of compression types Prebid Server can support for bidder compression
 const (
-	Gzip string = "GZIP"
+	Gzip   string = "GZIP"
+	Brotli string = "BR"
+	Zstd   string = "ZSTD"
 )
 
 // AdaptBidder converts an adapters.Bidder into an exchange.AdaptedBidder.
 //
 // The name refers to the "Adapter" architecture pattern, and should not be confused with a Prebid "Adapter"
 // (which is being phased out and replaced by Bidder for OpenRTB auctions)
-func AdaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *config.DebugInfo, endpointCompression string) AdaptedBidder {
+//
+// If the bidder declares a dedicated transport, its requests use a client built from it instead of the
+// shared client.
+func AdaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *config.DebugInfo, endpointCompression string, transport *config.TransportProfile, shadow *config.ShadowInfo, shadowLogger analytics.ShadowLogger) AdaptedBidder {
+	bidderClient, transportProfile := bidderClient(name, transport, client)
 	return &bidderAdapter{
 		Bidder:     bidder,
 		BidderName: name,
-		Client:     client,
+		Client:     bidderClient,
 		me:         me,
+		shadow:     newShadowTraffic(shadow, shadowLogger),
 		config: bidderAdapterConfig{
 			Debug:               cfg.Debug,
 			DisableConnMetrics:  cfg.Metrics.Disabled.AdapterConnectionMetrics,
 			DebugInfo:           config.DebugInfo{Allow: parseDebugInfo(debugInfo)},
 			EndpointCompression: endpointCompression,
+			TransportProfile:    transportProfile,
 		},
 	}
 }
@@ -105,6 +116,7 @@ type bidderAdapter struct {
 	BidderName openrtb_ext.BidderName
 	Client     *http.Client
 	me         metrics.MetricsEngine
+	shadow     *shadowTraffic
 	config     bidderAdapterConfig
 }
 
@@ -113,6 +125,7 @@ type bidderAdapterConfig struct {
 	DisableConnMetrics  bool
 	DebugInfo           config.DebugInfo
 	EndpointCompression string
+	TransportProfile    string
 }
 
 func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest BidderRequest, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, alternateBidderCodes openrtb_ext.ExtAlternateBidderCodes, hookExecutor hookexecution.StageExecutor) ([]*entities.PbsOrtbSeatBid, []error) {
@@ -164,6 +177,8 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 			}
 
 		}
+		bidder.mirror(ctx, bidderRequest, reqData, bidRequestOptions.accountShadow)
+
 		// Make any HTTP requests in parallel.
 		// If the bidder only needs to make one, save some cycles by just using the current one.
 		dataLen = len(reqData) + len(bidderRequest.BidderStoredResponses)
@@ -249,12 +264,24 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 				// and use it as currency
 				var conversionRate float64
 				var err error
+				conversionStart := time.Now()
 				for _, bidReqCur := range bidderRequest.BidRequest.Cur {
 					if conversionRate, err = conversions.GetRate(bidResponse.Currency, bidReqCur); err == nil {
 						seatBidMap[bidderRequest.BidderName].Currency = bidReqCur
 						break
 					}
 				}
+				if timeline := TimelineFromContext(ctx); timeline.Enabled() {
+					details := map[string]interface{}{
+						"from": bidResponse.Currency,
+						"to":   seatBidMap[bidderRequest.BidderName].Currency,
+						"rate": conversionRate,
+					}
+					if err != nil {
+						details["error"] = err.Error()
+					}
+					timeline.Record(TraceStepCurrencyConversion, bidderRequest.BidderName, conversionStart, details)
+				}
 
 				// Only do this for request from mobile app
 				if bidderRequest.BidRequest.App != nil {
@@ -488,20 +515,11 @@ func makeExt(httpInfo *httpCallInfo) *openrtb_ext.ExtHttpCall {
 // doRequest makes a request, handles the response, and returns the data needed by the
 // Bidder interface.
 func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.RequestData) *httpCallInfo {
-	return bidder.doRequestImpl(ctx, req, glog.Warningf)
+	return bidder.doRequestImpl(ctx, req, logger.FromContext(ctx).Warnf)
 }
 
 func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg) *httpCallInfo {
-	var requestBody []byte
-
-	switch strings.ToUpper(bidder.config.EndpointCompression) {
-	case Gzip:
-		requestBody = compressToGZIP(req.Body)
-		req.Headers.Set("Content-Encoding", "gzip")
-	default:
-		requestBody = req.Body
-	}
-	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(requestBody))
+	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(bidder.requestBody(req)))
 	if err != nil {
 		return &httpCallInfo{
 			request: req,
@@ -511,11 +529,21 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	httpReq.Header = req.Headers
 
 	// If adapter connection metrics are not disabled, add the client trace
-	// to get complete connection info into our metrics
-	if !bidder.config.DisableConnMetrics {
-		ctx = bidder.addClientTrace(ctx)
+	// to get complete connection info into our metrics. The trace also times
+	// the request for the timeline of the auction, if it is recorded.
+	timeline := TimelineFromContext(ctx)
+	var timing *httpCallTiming
+	if timeline.Enabled() {
+		timing = &httpCallTiming{}
 	}
+	if !bidder.config.DisableConnMetrics || timing != nil {
+		ctx = bidder.addClientTrace(ctx, timing)
+	}
+	requestStart := time.Now()
 	httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq)
+	if timing != nil {
+		timeline.Record(TraceStepBidderRequest, bidder.BidderName, requestStart, timing.details(httpResp, err))
+	}
 	if err != nil {
 		if err == context.DeadlineExceeded {
 			err = &errortypes.Timeout{Message: err.Error()}
@@ -615,9 +643,11 @@ type httpCallInfo struct {
 
 // This function adds an httptrace.ClientTrace object to the context so, if connection with the bidder
 // endpoint is established, we can keep track of whether the connection was newly created, reused, and
-// the time from the connection request, to the connection creation.
-func (bidder *bidderAdapter) addClientTrace(ctx context.Context) context.Context {
-	var connStart, dnsStart, tlsStart time.Time
+// the time from the connection request, to the connection creation. The times are recorded in the metrics
+// unless the connection metrics are disabled, and in the timing if it isn't nil.
+func (bidder *bidderAdapter) addClientTrace(ctx context.Context, timing *httpCallTiming) context.Context {
+	var connStart, dnsStart, dialStart, tlsStart time.Time
+	recordMetrics := !bidder.config.DisableConnMetrics
 
 	trace := &httptrace.ClientTrace{
 		// GetConn is called before a connection is created or retrieved from an idle pool
@@ -628,7 +658,10 @@ func (bidder *bidderAdapter) addClientTrace(ctx context.Context) context.Context
 		GotConn: func(info httptrace.GotConnInfo) {
 			connWaitTime := time.Now().Sub(connStart)
 
-			bidder.me.RecordAdapterConnections(bidder.BidderName, info.Reused, connWaitTime)
+			if recordMetrics {
+				bidder.me.RecordAdapterConnections(bidder.BidderName, bidder.config.TransportProfile, info.Reused, connWaitTime)
+			}
+			timing.setReused(info.Reused)
 		},
 		// DNSStart is called when a DNS lookup begins.
 		DNSStart: func(info httptrace.DNSStartInfo) {
@@ -638,7 +671,18 @@ func (bidder *bidderAdapter) addClientTrace(ctx context.Context) context.Context
 		DNSDone: func(info httptrace.DNSDoneInfo) {
 			dnsLookupTime := time.Now().Sub(dnsStart)
 
-			bidder.me.RecordDNSTime(dnsLookupTime)
+			if recordMetrics {
+				bidder.me.RecordDNSTime(dnsLookupTime)
+			}
+			timing.setDNS(dnsLookupTime)
+		},
+		// ConnectStart is called when a new connection's dial begins.
+		ConnectStart: func(network, addr string) {
+			dialStart = time.Now()
+		},
+		// ConnectDone is called when a new connection's dial completes.
+		ConnectDone: func(network, addr string, err error) {
+			timing.setConnect(time.Now().Sub(dialStart))
 		},
 
 		TLSHandshakeStart: func() {
@@ -648,12 +692,66 @@ func (bidder *bidderAdapter) addClientTrace(ctx context.Context) context.Context
 		TLSHandshakeDone: func(tls.ConnectionState, error) {
 			tlsHandshakeTime := time.Now().Sub(tlsStart)
 
-			bidder.me.RecordTLSHandshakeTime(tlsHandshakeTime)
+			if recordMetrics {
+				bidder.me.RecordTLSHandshakeTime(tlsHandshakeTime)
+			}
+			timing.setTLS(tlsHandshakeTime)
+		},
+		// GotFirstResponseByte is called when the first byte of the response headers is available.
+		GotFirstResponseByte: func() {
+			timing.setTTFB(time.Now().Sub(connStart))
 		},
 	}
 	return httptrace.WithClientTrace(ctx, trace)
 }
 
+// httpCallTiming is the timing of a request to a bidder, recorded in the timeline of the auction. The
+// trace sets it from the goroutines of the transport. A nil httpCallTiming records nothing.
+type httpCallTiming struct {
+	mu      sync.Mutex
+	dns     time.Duration
+	connect time.Duration
+	tls     time.Duration
+	ttfb    time.Duration
+	reused  bool
+}
+
+func (t *httpCallTiming) set(set func()) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	set()
+}
+
+func (t *httpCallTiming) setDNS(d time.Duration)     { t.set(func() { t.dns = d }) }
+func (t *httpCallTiming) setConnect(d time.Duration) { t.set(func() { t.connect = d }) }
+func (t *httpCallTiming) setTLS(d time.Duration)     { t.set(func() { t.tls = d }) }
+func (t *httpCallTiming) setTTFB(d time.Duration)    { t.set(func() { t.ttfb = d }) }
+func (t *httpCallTiming) setReused(reused bool)      { t.set(func() { t.reused = reused }) }
+
+// details returns the details of the bidder_request step of the timeline.
+func (t *httpCallTiming) details(httpResp *http.Response, err error) map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	details := map[string]interface{}{
+		"dnsms":     millis(t.dns),
+		"connectms": millis(t.connect),
+		"tlsms":     millis(t.tls),
+		"ttfbms":    millis(t.ttfb),
+		"reused":    t.reused,
+	}
+	if httpResp != nil {
+		details["status"] = httpResp.StatusCode
+	}
+	if err != nil {
+		details["error"] = err.Error()
+	}
+	return details
+}
+
 func prepareStoredResponse(impId string, bidResp json.RawMessage) *httpCallInfo {
 	//always one element in reqData because stored response is mapped to single imp
 	body := fmt.Sprintf("%s%s", ImpIdReqBody, impId)
@@ -673,10 +771,26 @@ func prepareStoredResponse(impId string, bidResp json.RawMessage) *httpCallInfo
 	return respData
 }
 
-func compressToGZIP(requestBody []byte) []byte {
-	var b bytes.Buffer
-	w := gzip.NewWriter(&b)
-	w.Write([]byte(requestBody))
-	w.Close()
-	return b.Bytes()
+// requestBody returns the body sent to the bidder, compressed if the bidder requires it.
+func (bidder *bidderAdapter) requestBody(req *adapters.RequestData) []byte {
+	switch strings.ToUpper(bidder.config.EndpointCompression) {
+	case Gzip:
+		return compressRequestBody(compressutil.Gzip, req)
+	case Brotli:
+		return compressRequestBody(compressutil.Brotli, req)
+	case Zstd:
+		return compressRequestBody(compressutil.Zstd, req)
+	}
+	return req.Body
+}
+
+// compressRequestBody encodes the body of the request and sets its Content-Encoding header. The body is
+// sent as is if it can't be encoded.
+func compressRequestBody(encoding string, req *adapters.RequestData) []byte {
+	body, err := compressutil.Compress(encoding, req.Body)
+	if err != nil {
+		return req.Body
+	}
+	req.Headers.Set("Content-Encoding", encoding)
+	return body
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/prebid/openrtb/v17/openrtb2"
 	"github.com/prebid/prebid-server/adapters"
@@ -33,14 +34,44 @@ type validatedBidder struct {
 
 func (v *validatedBidder) requestBid(ctx context.Context, bidderRequest BidderRequest, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, alternateBidderCodes openrtb_ext.ExtAlternateBidderCodes, hookExecutor hookexecution.StageExecutor) ([]*entities.PbsOrtbSeatBid, []error) {
 	seatBids, errs := v.bidder.requestBid(ctx, bidderRequest, conversions, reqInfo, adsCertSigner, bidRequestOptions, alternateBidderCodes, hookExecutor)
+	timeline := TimelineFromContext(ctx)
 	for _, seatBid := range seatBids {
-		if validationErrors := removeInvalidBids(bidderRequest.BidRequest, seatBid); len(validationErrors) > 0 {
+		validationStart := time.Now()
+		bids := 0
+		if seatBid != nil {
+			bids = len(seatBid.Bids)
+		}
+		validationErrors := removeInvalidBids(bidderRequest.BidRequest, seatBid)
+		if len(validationErrors) > 0 {
 			errs = append(errs, validationErrors...)
 		}
+		if timeline.Enabled() && bids > 0 {
+			timeline.Record(TraceStepBidValidation, bidderRequest.BidderName, validationStart, validationDetails(bids, bids-len(seatBid.Bids), errorMessages(validationErrors)))
+		}
 	}
 	return seatBids, errs
 }
 
+// validationDetails returns the details of the bid_validation step of the timeline.
+func validationDetails(bids, dropped int, messages []string) map[string]interface{} {
+	details := map[string]interface{}{
+		"bids":    bids,
+		"dropped": dropped,
+	}
+	if len(messages) > 0 {
+		details["errors"] = messages
+	}
+	return details
+}
+
+func errorMessages(errs []error) []string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
 // validateBids will run some validation checks on the returned bids and excise any invalid bids
 func removeInvalidBids(request *openrtb2.BidRequest, seatBid *entities.PbsOrtbSeatBid) []error {
 	// Exit early if there is nothing to do.
@@ -0,0 +1,64 @@
+package exchange
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// DisabledBidders are the bidders disabled at runtime, on top of the ones disabled in the bidder infos. The
+// exchanges read them on each auction, so bidders are disabled and enabled again without being rebuilt.
+type DisabledBidders struct {
+	bidders atomic.Value // Should only hold map[string]struct{}
+}
+
+func NewDisabledBidders() *DisabledBidders {
+	d := &DisabledBidders{}
+	d.bidders.Store(map[string]struct{}{})
+	return d
+}
+
+// Set replaces the disabled bidders.
+func (d *DisabledBidders) Set(bidders []string) {
+	disabled := make(map[string]struct{}, len(bidders))
+	for _, bidder := range bidders {
+		disabled[bidder] = struct{}{}
+	}
+	d.bidders.Store(disabled)
+}
+
+// Contains is true if the bidder is disabled. Nil DisabledBidders contain no bidder.
+func (d *DisabledBidders) Contains(bidder string) bool {
+	if d == nil {
+		return false
+	}
+	_, disabled := d.bidders.Load().(map[string]struct{})[bidder]
+	return disabled
+}
+
+// removeDisabledBidders removes the requests to the disabled bidders, and their aliases, and returns a warning
+// for each of them.
+func removeDisabledBidders(bidderRequests []BidderRequest, disabledBidders *DisabledBidders) ([]BidderRequest, []error) {
+	if disabledBidders == nil {
+		return bidderRequests, nil
+	}
+
+	var warnings []error
+	enabled := bidderRequests[:0]
+	for _, bidderRequest := range bidderRequests {
+		if disabledBidders.Contains(bidderRequest.BidderCoreName.String()) {
+			warnings = append(warnings, disabledBidderWarning(bidderRequest.BidderName))
+			continue
+		}
+		enabled = append(enabled, bidderRequest)
+	}
+	return enabled, warnings
+}
+
+func disabledBidderWarning(bidder openrtb_ext.BidderName) error {
+	return &errortypes.BidderTemporarilyDisabled{
+		Message: fmt.Sprintf(`Bidder "%s" has been disabled on this instance of Prebid Server. Please work with the PBS host to enable this bidder again.`, bidder),
+	}
+}
@@ -0,0 +1,73 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveDisabledBidders(t *testing.T) {
+	disabledBidders := NewDisabledBidders()
+	disabledBidders.Set([]string{"appnexus"})
+
+	testCases := []struct {
+		description      string
+		disabledBidders  *DisabledBidders
+		expectedBidders  []openrtb_ext.BidderName
+		expectedWarnings []error
+	}{
+		{
+			description:     "Nil",
+			disabledBidders: nil,
+			expectedBidders: []openrtb_ext.BidderName{"appnexus", "alias", "rubicon"},
+		},
+		{
+			description:     "None",
+			disabledBidders: NewDisabledBidders(),
+			expectedBidders: []openrtb_ext.BidderName{"appnexus", "alias", "rubicon"},
+		},
+		{
+			description:     "Bidder And Alias",
+			disabledBidders: disabledBidders,
+			expectedBidders: []openrtb_ext.BidderName{"rubicon"},
+			expectedWarnings: []error{
+				&errortypes.BidderTemporarilyDisabled{Message: `Bidder "appnexus" has been disabled on this instance of Prebid Server. Please work with the PBS host to enable this bidder again.`},
+				&errortypes.BidderTemporarilyDisabled{Message: `Bidder "alias" has been disabled on this instance of Prebid Server. Please work with the PBS host to enable this bidder again.`},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		bidderRequests := []BidderRequest{
+			{BidderName: "appnexus", BidderCoreName: "appnexus"},
+			{BidderName: "alias", BidderCoreName: "appnexus"},
+			{BidderName: "rubicon", BidderCoreName: "rubicon"},
+		}
+
+		result, warnings := removeDisabledBidders(bidderRequests, test.disabledBidders)
+
+		assert.Equal(t, test.expectedBidders, listBidderNames(result), test.description)
+		assert.Equal(t, test.expectedWarnings, warnings, test.description)
+	}
+}
+
+func TestDisabledBiddersSet(t *testing.T) {
+	disabledBidders := NewDisabledBidders()
+	disabledBidders.Set([]string{"appnexus", "rubicon"})
+	assert.True(t, disabledBidders.Contains("appnexus"), "disabled")
+	assert.False(t, disabledBidders.Contains("pubmatic"), "not disabled")
+
+	disabledBidders.Set([]string{"rubicon"})
+	assert.False(t, disabledBidders.Contains("appnexus"), "enabled again")
+	assert.True(t, disabledBidders.Contains("rubicon"), "still disabled")
+}
+
+func listBidderNames(bidderRequests []BidderRequest) []openrtb_ext.BidderName {
+	names := make([]openrtb_ext.BidderName, 0, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		names = append(names, bidderRequest.BidderName)
+	}
+	return names
+}
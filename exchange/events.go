@@ -2,6 +2,7 @@ package exchange
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/prebid/prebid-server/exchange/entities"
@@ -11,6 +12,7 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/endpoints/events"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/vast"
 )
 
 // eventTracking has configuration fields needed for adding event tracking to an auction response
@@ -22,10 +24,17 @@ type eventTracking struct {
 	integrationType    string
 	bidderInfos        config.BidderInfos
 	externalURL        string
+	auctionID          string
+	channel            string
+	vastEvents         config.Events
 }
 
 // getEventTracking creates an eventTracking object from the different configuration sources
-func getEventTracking(requestExtPrebid *openrtb_ext.ExtRequestPrebid, ts time.Time, account *config.Account, bidderInfos config.BidderInfos, externalURL string) *eventTracking {
+func getEventTracking(requestExtPrebid *openrtb_ext.ExtRequestPrebid, ts time.Time, account *config.Account, bidderInfos config.BidderInfos, externalURL string, auctionID string) *eventTracking {
+	var channel string
+	if requestExtPrebid != nil && requestExtPrebid.Channel != nil {
+		channel = requestExtPrebid.Channel.Name
+	}
 	return &eventTracking{
 		accountID:          account.ID,
 		enabledForAccount:  account.EventsEnabled,
@@ -34,21 +43,63 @@ func getEventTracking(requestExtPrebid *openrtb_ext.ExtRequestPrebid, ts time.Ti
 		integrationType:    requestExtPrebid.Integration,
 		bidderInfos:        bidderInfos,
 		externalURL:        externalURL,
+		auctionID:          auctionID,
+		channel:            channel,
+		vastEvents:         account.Events,
 	}
 }
 
-// modifyBidsForEvents adds bidEvents and modifies VAST AdM if necessary.
-func (ev *eventTracking) modifyBidsForEvents(seatBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid) map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid {
+// modifyBidsForEvents adds bidEvents and modifies VAST AdM if necessary. Video bids are rejected
+// when VAST events are enabled for the account and their VAST can't be parsed.
+func (ev *eventTracking) modifyBidsForEvents(seatBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid) (map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, []string) {
+	var rejections []string
 	for bidderName, seatBid := range seatBids {
 		modifyingVastXMLAllowed := ev.isModifyingVASTXMLAllowed(bidderName.String())
+		bids := seatBid.Bids[:0]
 		for _, pbsBid := range seatBid.Bids {
+			if err := ev.processBidVAST(pbsBid, bidderName); err != nil {
+				rejections = append(rejections, fmt.Sprintf("bid rejected [bid ID: %s] reason: invalid VAST: %v", pbsBid.Bid.ID, err))
+				continue
+			}
 			if modifyingVastXMLAllowed {
 				ev.modifyBidVAST(pbsBid, bidderName)
 			}
 			pbsBid.BidEvents = ev.makeBidExtEvents(pbsBid, bidderName)
+			bids = append(bids, pbsBid)
 		}
+		seatBid.Bids = bids
+	}
+	return seatBids, rejections
+}
+
+// processBidVAST validates the VAST of a video bid and injects the trackers configured by the account
+// VAST events. Bids are left untouched when VAST events are disabled or the bidder doesn't allow its
+// VAST to be modified.
+func (ev *eventTracking) processBidVAST(pbsBid *entities.PbsOrtbBid, bidderName openrtb_ext.BidderName) error {
+	bid := pbsBid.Bid
+	if !ev.vastEvents.Enabled || !ev.bidderInfos[bidderName.String()].ModifyingVastXmlAllowed || pbsBid.BidType != openrtb_ext.BidTypeVideo || len(bid.AdM) == 0 && len(bid.NURL) == 0 {
+		return nil
+	}
+	bidID := bid.ID
+	if len(pbsBid.GeneratedBidID) > 0 {
+		bidID = pbsBid.GeneratedBidID
+	}
+	vastXML, err := vast.Process(makeVAST(bid), ev.vastEvents, vast.MacroValues{
+		BidID:       bidID,
+		AccountID:   ev.accountID,
+		Bidder:      bidderName.String(),
+		AuctionID:   ev.auctionID,
+		Integration: ev.integrationType,
+		Channel:     ev.channel,
+		MediaType:   string(openrtb_ext.BidTypeVideo),
+		Price:       bid.Price,
+		Timestamp:   ev.auctionTimestampMs,
+	})
+	if err != nil {
+		return err
 	}
-	return seatBids
+	bid.AdM = vastXML
+	return nil
 }
 
 // isModifyingVASTXMLAllowed returns true if this bidder config allows modifying VAST XML for event tracking
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange/entities"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
@@ -153,3 +154,41 @@ func Test_eventsData_modifyBidJSON(t *testing.T) {
 		})
 	}
 }
+
+func Test_eventsData_modifyBidsForEvents(t *testing.T) {
+	evData := &eventTracking{
+		accountID:          "123456",
+		auctionTimestampMs: 1234567890,
+		externalURL:        "http://localhost",
+		auctionID:          "auction-1",
+		bidderInfos:        config.BidderInfos{"openx": config.BidderInfo{ModifyingVastXmlAllowed: true}},
+		vastEvents: config.Events{
+			Enabled:    true,
+			DefaultURL: "http://localhost/event?t=##PBS-EVENTTYPE##&b=##PBS-BIDID##&aid=##PBS-AUCTIONID##&p=##PBS-PRICE##",
+			VASTEvents: []config.VASTEvent{{CreateElement: config.ImpressionVASTElement}},
+		},
+	}
+	banner := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "banner", AdM: "<div></div>"}, BidType: openrtb_ext.BidTypeBanner}
+	valid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "valid", Price: 2, NURL: "http://nurl"}, BidType: openrtb_ext.BidTypeVideo}
+	invalid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "invalid", AdM: "<VAST"}, BidType: openrtb_ext.BidTypeVideo}
+	unsupported := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "unsupported", AdM: `<VAST version="5.0"></VAST>`}, BidType: openrtb_ext.BidTypeVideo}
+	notModifiable := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "not-modifiable", AdM: "<VAST"}, BidType: openrtb_ext.BidTypeVideo}
+	seatBids := map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+		openrtb_ext.BidderOpenx:    {Bids: []*entities.PbsOrtbBid{banner, valid, invalid, unsupported}},
+		openrtb_ext.BidderAppnexus: {Bids: []*entities.PbsOrtbBid{notModifiable}},
+	}
+
+	seatBids, rejections := evData.modifyBidsForEvents(seatBids)
+
+	assert.Equal(t, []string{"bid rejected [bid ID: invalid] reason: invalid VAST: VAST markup is not valid XML: XML syntax error on line 1: unexpected EOF"}, rejections)
+	assert.Equal(t, []*entities.PbsOrtbBid{banner, valid, unsupported}, seatBids[openrtb_ext.BidderOpenx].Bids)
+	assert.Equal(t, []*entities.PbsOrtbBid{notModifiable}, seatBids[openrtb_ext.BidderAppnexus].Bids, "the VAST of a bidder not allowing VAST modification isn't processed")
+	assert.Equal(t, `<VAST version="5.0"></VAST>`, unsupported.Bid.AdM, "an unsupported VAST version is left untouched")
+	assert.Equal(t, "<VAST", notModifiable.Bid.AdM)
+	assert.Equal(t, "<div></div>", banner.Bid.AdM)
+	assert.Equal(t, `<VAST version="3.0"><Ad><Wrapper>`+
+		`<AdSystem>prebid.org wrapper</AdSystem>`+
+		`<VASTAdTagURI><![CDATA[http://nurl]]></VASTAdTagURI>`+
+		`<Impression></Impression><Impression><![CDATA[http://localhost/event?t=impression&b=valid&aid=auction-1&p=2]]></Impression><Creatives></Creatives>`+
+		`</Wrapper></Ad></VAST>`, valid.Bid.AdM)
+}
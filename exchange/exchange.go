@@ -23,6 +23,7 @@ import (
 	"github.com/prebid/prebid-server/firstpartydata"
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
@@ -33,7 +34,6 @@ import (
 
 	"github.com/buger/jsonparser"
 	"github.com/gofrs/uuid"
-	"github.com/golang/glog"
 	"github.com/prebid/openrtb/v17/openrtb2"
 	"github.com/prebid/openrtb/v17/openrtb3"
 )
@@ -73,6 +73,11 @@ type exchange struct {
 	adsCertSigner            adscert.Signer
 	server                   config.Server
 	bidValidationEnforcement config.Validations
+	tmaxAdjustments          *tmaxAdjustments
+	// bidRates count how often the bidders bid for the cookie sync prioritization, nil when they aren't tracked.
+	bidRates *usersync.BidRates
+	// disabledBidders are the bidders disabled at runtime, which the auctions don't call.
+	disabledBidders *DisabledBidders
 }
 
 // Container to pass out response ext data from the GetAllBids goroutines back into the main thread
@@ -83,6 +88,9 @@ type seatResponseExtra struct {
 	// httpCalls is the list of debugging info. It should only be populated if the request.test == 1.
 	// This will become response.ext.debug.httpcalls.{bidder} on the final Response.
 	HttpCalls []*openrtb_ext.ExtHttpCall
+	// Tmax is the timeout given to the bidder, if the tmax adjustments are enabled.
+	// This will become response.ext.debug.tmax.{bidder} on the final Response.
+	Tmax *openrtb_ext.ExtBidderTmax
 }
 
 type bidResponseWrapper struct {
@@ -120,7 +128,7 @@ func (randomDeduplicateBidBooleanGenerator) Generate() bool {
 	return rand.Intn(100) < 50
 }
 
-func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gdprPermsBuilder gdpr.PermissionsBuilder, tcf2CfgBuilder gdpr.TCF2ConfigBuilder, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, adsCertSigner adscert.Signer) Exchange {
+func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gdprPermsBuilder gdpr.PermissionsBuilder, tcf2CfgBuilder gdpr.TCF2ConfigBuilder, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, adsCertSigner adscert.Signer, bidRates *usersync.BidRates, disabledBidders *DisabledBidders) Exchange {
 	bidderToSyncerKey := map[string]string{}
 	for bidder, syncer := range syncersByBidder {
 		bidderToSyncerKey[bidder] = syncer.Key()
@@ -154,6 +162,28 @@ func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid
 		adsCertSigner:            adsCertSigner,
 		server:                   config.Server{ExternalUrl: cfg.ExternalURL, GvlID: cfg.GDPR.HostVendorID, DataCenter: cfg.DataCenter},
 		bidValidationEnforcement: cfg.Validations,
+		tmaxAdjustments:          newTmaxAdjustments(cfg.TmaxAdjustments),
+		bidRates:                 bidRates,
+		disabledBidders:          disabledBidders,
+	}
+}
+
+// InheritState makes the exchange carry on with the state gathered by the exchange it replaces when the
+// endpoints are rebuilt: the latency windows of the bidders which adjust their tmax. The bidder transport
+// clients are kept by their own registry, the bid rates are given to each exchange, and the shadow traffic
+// of the rebuilt bidders starts with no request in flight.
+func InheritState(ex Exchange, previous Exchange) {
+	e, ok := ex.(*exchange)
+	if !ok {
+		return
+	}
+	p, ok := previous.(*exchange)
+	if !ok || e.tmaxAdjustments == nil || p.tmaxAdjustments == nil {
+		return
+	}
+	if e.tmaxAdjustments.latencies.windowSize == p.tmaxAdjustments.latencies.windowSize &&
+		e.tmaxAdjustments.latencies.percentile == p.tmaxAdjustments.latencies.percentile {
+		e.tmaxAdjustments.latencies = p.tmaxAdjustments.latencies
 	}
 }
 
@@ -187,6 +217,8 @@ type AuctionRequest struct {
 	BidderImpReplaceImpID stored_responses.BidderImpReplaceImpID
 	PubID                 string
 	HookExecutor          hookexecution.StageExecutor
+	// MaxBidders limits the number of bidders called while the load is shed, 0 for no limit.
+	MaxBidders int
 }
 
 // BidderRequest holds the bidder specific request and all other
@@ -235,6 +267,11 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	}
 	e.me.RecordDebugRequest(responseDebugAllow || accountDebugAllow, r.PubID)
 
+	timeline := TimelineFromContext(ctx)
+	if !traceAllowed(requestExt, r.Account.DebugAllow, debugLog) {
+		timeline.disable()
+	}
+
 	if r.RequestType == metrics.ReqTypeORTB2Web || r.RequestType == metrics.ReqTypeORTB2App {
 		//Extract First party data for auction endpoint only
 		resolvedFPD, fpdErrors := firstpartydata.ExtractFPDForBidders(r.BidRequestWrapper)
@@ -273,6 +310,22 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 	e.me.RecordRequestPrivacy(privacyLabels)
 
+	bidderRequests, disabledWarnings := removeDisabledBidders(bidderRequests, e.disabledBidders)
+	r.Warnings = append(r.Warnings, disabledWarnings...)
+
+	if r.MaxBidders > 0 {
+		bidderRequests = limitBidders(bidderRequests, r.MaxBidders)
+	}
+
+	// Bidders which can't fill a dynamic video pod from a single imp get one imp per pod slot
+	adPods := findAdPods(r.BidRequestWrapper.Imp)
+	var expandedPodImps map[string]string
+	if len(adPods) > 0 {
+		expandedPodImps = expandDynamicPods(bidderRequests, e.bidderInfo)
+	}
+
+	errs = append(errs, convertBidderRequestsVersion(bidderRequests, e.bidderInfo)...)
+
 	if len(r.StoredAuctionResponses) > 0 || len(r.StoredBidResponses) > 0 {
 		e.me.RecordStoredResponse(r.PubID)
 	}
@@ -312,7 +365,14 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 			alternateBidderCodes = *r.Account.AlternateBidderCodes
 		}
 
-		adapterBids, adapterExtra, fledge, anyBidsReturned = e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions, accountDebugAllow, r.GlobalPrivacyControlHeader, debugLog.DebugOverride, alternateBidderCodes, requestExt.Prebid.Experiment, r.HookExecutor)
+		// Give each bidder a timeout based on its recent latency, so the slow ones are cut off early.
+		var bidderTimeouts map[openrtb_ext.BidderName]bidderTimeout
+		if e.tmaxAdjustments != nil {
+			bidderTimeouts = e.tmaxAdjustments.bidderTimeouts(auctionCtx, time.Now(), bidderRequests)
+		}
+
+		adapterBids, adapterExtra, fledge, anyBidsReturned = e.getAllBids(auctionCtx, bidderRequests, bidderTimeouts, bidAdjustmentFactors, conversions, accountDebugAllow, r.GlobalPrivacyControlHeader, debugLog.DebugOverride, alternateBidderCodes, requestExt.Prebid.Experiment, r.Account.Shadow, r.HookExecutor)
+		e.recordBidRates(r.Account.ID, liveAdapters, adapterBids)
 	}
 
 	var auc *auction
@@ -320,14 +380,24 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	var bidResponseExt *openrtb_ext.ExtBidResponse
 	if anyBidsReturned {
 
+		restoreExpandedImpIDs(adapterBids, expandedPodImps)
+		for _, message := range enforcePodConstraints(adPods, adapterBids, conversions, requestExt.Prebid.PodConfig) {
+			errs = append(errs, errors.New(message))
+		}
+
 		var bidCategory map[string]string
 		//If includebrandcategory is present in ext then CE feature is on.
 		if requestExt.Prebid.Targeting != nil && requestExt.Prebid.Targeting.IncludeBrandCategory != nil {
 			var rejections []string
+			categoryMappingStart := time.Now()
 			bidCategory, adapterBids, rejections, err = applyCategoryMapping(ctx, requestExt, adapterBids, e.categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 			if err != nil {
 				return nil, fmt.Errorf("Error in category mapping : %s", err.Error())
 			}
+			timeline.Record(TraceStepCategoryMapping, "", categoryMappingStart, map[string]interface{}{
+				"categories": len(bidCategory),
+				"rejected":   len(rejections),
+			})
 			for _, message := range rejections {
 				errs = append(errs, errors.New(message))
 			}
@@ -344,8 +414,12 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 			}
 		}
 
-		evTracking := getEventTracking(&requestExt.Prebid, r.StartTime, &r.Account, e.bidderInfo, e.externalURL)
-		adapterBids = evTracking.modifyBidsForEvents(adapterBids)
+		evTracking := getEventTracking(&requestExt.Prebid, r.StartTime, &r.Account, e.bidderInfo, e.externalURL, r.BidRequestWrapper.ID)
+		var vastRejections []string
+		adapterBids, vastRejections = evTracking.modifyBidsForEvents(adapterBids)
+		for _, message := range vastRejections {
+			errs = append(errs, errors.New(message))
+		}
 
 		r.HookExecutor.ExecuteAllProcessedBidResponsesStage(adapterBids)
 
@@ -369,7 +443,11 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 				}
 			}
 
+			cacheStart := time.Now()
 			cacheErrs = auc.doCache(ctx, e.cache, targData, evTracking, r.BidRequestWrapper.BidRequest, 60, &r.Account.CacheTTL, bidCategory, debugLog)
+			timeline.Record(TraceStepCacheWrite, "", cacheStart, map[string]interface{}{
+				"errors": len(cacheErrs),
+			})
 			if len(cacheErrs) > 0 {
 				errs = append(errs, cacheErrs...)
 			}
@@ -378,6 +456,13 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 		}
 		bidResponseExt = e.makeExtBidResponse(adapterBids, adapterExtra, r, responseDebugAllow, requestExt.Prebid.Passthrough, fledge, errs)
+
+		if adPodsResponse := makeAdPodsResponse(adPods, adapterBids); len(adPodsResponse) > 0 {
+			if bidResponseExt.Prebid == nil {
+				bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{}
+			}
+			bidResponseExt.Prebid.AdPods = adPodsResponse
+		}
 	} else {
 		bidResponseExt = e.makeExtBidResponse(adapterBids, adapterExtra, r, responseDebugAllow, requestExt.Prebid.Passthrough, fledge, errs)
 
@@ -516,10 +601,25 @@ func (e *exchange) makeAuctionContext(ctx context.Context, needsCache bool) (auc
 	return
 }
 
+// recordBidRates feeds the bid rates used to prioritize the cookie syncs of the account with the
+// bidders called by the auction and whether they bid.
+func (e *exchange) recordBidRates(accountID string, liveAdapters []openrtb_ext.BidderName, adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid) {
+	if e.bidRates == nil {
+		return
+	}
+	bidders := make(map[string]bool, len(liveAdapters))
+	for _, bidder := range liveAdapters {
+		seatBid, ok := adapterBids[bidder]
+		bidders[string(bidder)] = ok && seatBid != nil && len(seatBid.Bids) > 0
+	}
+	e.bidRates.Record(accountID, bidders)
+}
+
 // This piece sends all the requests to the bidder adapters and gathers the results.
 func (e *exchange) getAllBids(
 	ctx context.Context,
 	bidderRequests []BidderRequest,
+	bidderTimeouts map[openrtb_ext.BidderName]bidderTimeout,
 	bidAdjustments map[string]float64,
 	conversions currency.Conversions,
 	accountDebugAllowed bool,
@@ -527,6 +627,7 @@ func (e *exchange) getAllBids(
 	headerDebugAllowed bool,
 	alternateBidderCodes openrtb_ext.ExtAlternateBidderCodes,
 	experiment *openrtb_ext.Experiment,
+	accountShadow config.AccountShadow,
 	hookExecutor hookexecution.StageExecutor) (
 	map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid,
 	map[openrtb_ext.BidderName]*seatResponseExtra,
@@ -540,10 +641,10 @@ func (e *exchange) getAllBids(
 
 	for _, bidder := range bidderRequests {
 		// Here we actually call the adapters and collect the bids.
-		bidderRunner := e.recoverSafely(bidderRequests, func(bidderRequest BidderRequest, conversions currency.Conversions) {
+		bidderRunner := e.recoverSafely(ctx, bidderRequests, func(bidderRequest BidderRequest, conversions currency.Conversions) {
 			// Passing in aName so a doesn't change out from under the go routine
 			if bidderRequest.BidderLabels.Adapter == "" {
-				glog.Errorf("Exchange: bidlables for %s (%s) missing adapter string", bidderRequest.BidderName, bidderRequest.BidderCoreName)
+				logger.FromContext(ctx).Errorf("Exchange: bidlables for %s (%s) missing adapter string", bidderRequest.BidderName, bidderRequest.BidderCoreName)
 				bidderRequest.BidderLabels.Adapter = bidderRequest.BidderCoreName
 			}
 			brw := new(bidResponseWrapper)
@@ -564,14 +665,36 @@ func (e *exchange) getAllBids(
 				headerDebugAllowed:  headerDebugAllowed,
 				addCallSignHeader:   isAdsCertEnabled(experiment, e.bidderInfo[string(bidderRequest.BidderName)]),
 				bidAdjustments:      bidAdjustments,
+				accountShadow:       accountShadow,
+			}
+
+			var seatBids []*entities.PbsOrtbSeatBid
+			var err []error
+			timeout, adjusted := bidderTimeouts[bidderRequest.BidderName]
+			if adjusted && timeout.skipped() {
+				err = []error{errTmaxTooLow}
+			} else {
+				bidderCtx := ctx
+				if adjusted {
+					var cancel context.CancelFunc
+					bidderCtx, cancel = context.WithDeadline(ctx, timeout.deadline)
+					defer cancel()
+					bidderRequest.BidRequest.TMax = timeout.debug.Tmax
+				}
+				seatBids, err = e.adapterMap[bidderRequest.BidderCoreName].requestBid(bidderCtx, bidderRequest, conversions, &reqInfo, e.adsCertSigner, bidReqOptions, alternateBidderCodes, hookExecutor)
+				if e.tmaxAdjustments != nil {
+					e.tmaxAdjustments.latencies.record(bidderRequest.BidderName, time.Since(start))
+				}
 			}
-			seatBids, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest, conversions, &reqInfo, e.adsCertSigner, bidReqOptions, alternateBidderCodes, hookExecutor)
 
 			// Add in time reporting
 			elapsed := time.Since(start)
 			brw.adapterSeatBids = seatBids
 			// Structure to record extra tracking data generated during bidding
 			ae := new(seatResponseExtra)
+			if adjusted {
+				ae.Tmax = &timeout.debug
+			}
 			ae.ResponseTimeMillis = int(elapsed / time.Millisecond)
 			if len(seatBids) != 0 {
 				ae.HttpCalls = seatBids[0].HttpCalls
@@ -649,7 +772,7 @@ func collectFledgeFromSeatBid(fledge *openrtb_ext.Fledge, bidderName openrtb_ext
 	return fledge
 }
 
-func (e *exchange) recoverSafely(bidderRequests []BidderRequest,
+func (e *exchange) recoverSafely(ctx context.Context, bidderRequests []BidderRequest,
 	inner func(BidderRequest, currency.Conversions),
 	chBids chan *bidResponseWrapper) func(BidderRequest, currency.Conversions) {
 	return func(bidderRequest BidderRequest, conversions currency.Conversions) {
@@ -666,7 +789,7 @@ func (e *exchange) recoverSafely(bidderRequests []BidderRequest,
 					allBidders = sb.String()[:sb.Len()-1]
 				}
 
-				glog.Errorf("OpenRTB auction recovered panic from Bidder %s: %v. "+
+				logger.FromContext(ctx).Errorf("OpenRTB auction recovered panic from Bidder %s: %v. "+
 					"Account id: %s, All Bidders: %s, Stack trace is: %v",
 					bidderRequest.BidderCoreName, r, bidderRequest.BidderLabels.PubID, allBidders, string(debug.Stack()))
 				e.me.RecordAdapterPanic(bidderRequest.BidderLabels)
@@ -753,10 +876,23 @@ func (e *exchange) buildBidResponse(ctx context.Context, liveAdapters []openrtb_
 	// Create the SeatBids. We use a zero sized slice so that we can append non-zero seat bids, and not include seatBid
 	// objects for seatBids without any bids. Preallocate the max possible size to avoid reallocating the array as we go.
 	seatBids := make([]openrtb2.SeatBid, 0, len(liveAdapters))
+	timeline := TimelineFromContext(ctx)
 	for a, adapterSeatBids := range adapterSeatBids {
 		//while processing every single bib, do we need to handle categories here?
 		if adapterSeatBids != nil && len(adapterSeatBids.Bids) > 0 {
+			validationStart := time.Now()
+			var validationErrors int
+			if timeline.Enabled() {
+				validationErrors = len(bidResponseExt.Errors[a])
+			}
 			sb := e.makeSeatBid(adapterSeatBids, a, adapterExtra, auc, returnCreative, impExtInfoMap, bidResponseExt, pubID)
+			if timeline.Enabled() {
+				var messages []string
+				for _, message := range bidResponseExt.Errors[a][validationErrors:] {
+					messages = append(messages, message.Message)
+				}
+				timeline.Record(TraceStepBidValidation, a, validationStart, validationDetails(len(adapterSeatBids.Bids), len(adapterSeatBids.Bids)-len(sb.Bid), messages))
+			}
 			seatBids = append(seatBids, *sb)
 			bidResponse.Cur = adapterSeatBids.Currency
 		}
@@ -764,6 +900,7 @@ func (e *exchange) buildBidResponse(ctx context.Context, liveAdapters []openrtb_
 
 	bidResponse.SeatBid = seatBids
 
+	addTrace(ctx, bidResponseExt)
 	bidResponse.Ext, err = encodeBidResponseExt(bidResponseExt)
 
 	return bidResponse, err
@@ -1039,6 +1176,12 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*en
 		if debugInfo && len(responseExtra.HttpCalls) > 0 {
 			bidResponseExt.Debug.HttpCalls[bidderName] = responseExtra.HttpCalls
 		}
+		if debugInfo && responseExtra.Tmax != nil {
+			if bidResponseExt.Debug.Tmax == nil {
+				bidResponseExt.Debug.Tmax = make(map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderTmax)
+			}
+			bidResponseExt.Debug.Tmax[bidderName] = responseExtra.Tmax
+		}
 		if len(responseExtra.Warnings) > 0 {
 			bidResponseExt.Warnings[bidderName] = responseExtra.Warnings
 		}
@@ -1254,6 +1397,18 @@ func buildCacheURL(cache prebid_cache_client.Client, uuid string) string {
 	return strings.TrimPrefix(cacheURL.String(), "//")
 }
 
+// limitBidders keeps maxBidders of the bidder requests, chosen at random so the same bidders aren't always
+// the ones left out.
+func limitBidders(bidderRequests []BidderRequest, maxBidders int) []BidderRequest {
+	if len(bidderRequests) <= maxBidders {
+		return bidderRequests
+	}
+	rand.Shuffle(len(bidderRequests), func(i, j int) {
+		bidderRequests[i], bidderRequests[j] = bidderRequests[j], bidderRequests[i]
+	})
+	return bidderRequests[:maxBidders]
+}
+
 func listBiddersWithRequests(bidderRequests []BidderRequest) []openrtb_ext.BidderName {
 	liveAdapters := make([]openrtb_ext.BidderName, len(bidderRequests))
 	i := 0
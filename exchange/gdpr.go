@@ -1,8 +1,6 @@
 package exchange
 
 import (
-	"encoding/json"
-
 	gpplib "github.com/prebid/go-gpp"
 	gppConstants "github.com/prebid/go-gpp/constants"
 	"github.com/prebid/openrtb/v17/openrtb2"
@@ -10,50 +8,30 @@ import (
 )
 
 // ExtractGDPR will pull the gdpr flag from an openrtb request
-func extractGDPR(bidRequest *openrtb2.BidRequest) (gdpr.Signal, error) {
-	var re regsExt
-	var err error
-
+func extractGDPR(bidRequest *openrtb2.BidRequest) gdpr.Signal {
 	if bidRequest.Regs != nil && len(bidRequest.Regs.GPPSID) > 0 {
 		for _, id := range bidRequest.Regs.GPPSID {
 			if id == int8(gppConstants.SectionTCFEU2) {
-				return gdpr.SignalYes, nil
+				return gdpr.SignalYes
 			}
 		}
-		return gdpr.SignalNo, nil
+		return gdpr.SignalNo
 	}
-	if bidRequest.Regs != nil && bidRequest.Regs.Ext != nil {
-		err = json.Unmarshal(bidRequest.Regs.Ext, &re)
+	if bidRequest.Regs == nil || bidRequest.Regs.GDPR == nil {
+		return gdpr.SignalAmbiguous
 	}
-	if re.GDPR == nil || err != nil {
-		return gdpr.SignalAmbiguous, err
-	}
-	return gdpr.Signal(*re.GDPR), nil
+	return gdpr.Signal(*bidRequest.Regs.GDPR)
 }
 
 // ExtractConsent will pull the consent string from an openrtb request
-func extractConsent(bidRequest *openrtb2.BidRequest, gpp gpplib.GppContainer) (consent string, err error) {
+func extractConsent(bidRequest *openrtb2.BidRequest, gpp gpplib.GppContainer) string {
 	for i, id := range gpp.SectionTypes {
 		if id == gppConstants.SectionTCFEU2 {
-			consent = gpp.Sections[i].GetValue()
-			return
+			return gpp.Sections[i].GetValue()
 		}
 	}
-	var ue userExt
-	if bidRequest.User != nil && bidRequest.User.Ext != nil {
-		err = json.Unmarshal(bidRequest.User.Ext, &ue)
-	}
-	if err != nil {
-		return
+	if bidRequest.User != nil {
+		return bidRequest.User.Consent
 	}
-	consent = ue.Consent
-	return
-}
-
-type userExt struct {
-	Consent string `json:"consent,omitempty"`
-}
-
-type regsExt struct {
-	GDPR *int `json:"gdpr,omitempty"`
+	return ""
 }
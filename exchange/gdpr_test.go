@@ -16,21 +16,20 @@ func TestExtractGDPR(t *testing.T) {
 		description string
 		giveRegs    *openrtb2.Regs
 		wantGDPR    gdpr.Signal
-		wantError   bool
 	}{
 		{
-			description: "Regs Ext GDPR = 0",
-			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr": 0}`)},
+			description: "Regs GDPR = 0",
+			giveRegs:    &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(0)},
 			wantGDPR:    gdpr.SignalNo,
 		},
 		{
-			description: "Regs Ext GDPR = 1",
-			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr": 1}`)},
+			description: "Regs GDPR = 1",
+			giveRegs:    &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(1)},
 			wantGDPR:    gdpr.SignalYes,
 		},
 		{
-			description: "Regs Ext GDPR = null",
-			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr": null}`)},
+			description: "Regs GDPR is nil",
+			giveRegs:    &openrtb2.Regs{GDPR: nil},
 			wantGDPR:    gdpr.SignalAmbiguous,
 		},
 		{
@@ -39,38 +38,32 @@ func TestExtractGDPR(t *testing.T) {
 			wantGDPR:    gdpr.SignalAmbiguous,
 		},
 		{
-			description: "Regs Ext is nil",
-			giveRegs:    &openrtb2.Regs{Ext: nil},
-			wantGDPR:    gdpr.SignalAmbiguous,
-		},
-		{
-			description: "JSON unmarshal error",
-			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"`)},
+			description: "GDPR in the 2.5 ext location is ignored",
+			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr": 1}`)},
 			wantGDPR:    gdpr.SignalAmbiguous,
-			wantError:   true,
 		},
 		{
-			description: "Regs Ext GDPR = null, GPPSID has tcf2",
-			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr": null}`), GPPSID: []int8{2}},
+			description: "Regs GDPR = null, GPPSID has tcf2",
+			giveRegs:    &openrtb2.Regs{GPPSID: []int8{2}},
 			wantGDPR:    gdpr.SignalYes,
 		},
 		{
-			description: "Regs Ext GDPR = 1, GPPSID has uspv1",
-			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr": 1}`), GPPSID: []int8{6}},
+			description: "Regs GDPR = 1, GPPSID has uspv1",
+			giveRegs:    &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(1), GPPSID: []int8{6}},
 			wantGDPR:    gdpr.SignalNo,
 		},
 		{
-			description: "Regs Ext GDPR = 0, GPPSID has tcf2",
-			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr": 0}`), GPPSID: []int8{2}},
+			description: "Regs GDPR = 0, GPPSID has tcf2",
+			giveRegs:    &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(0), GPPSID: []int8{2}},
 			wantGDPR:    gdpr.SignalYes,
 		},
 		{
-			description: "Regs Ext is nil, GPPSID has tcf2",
+			description: "Regs GDPR is nil, GPPSID has tcf2",
 			giveRegs:    &openrtb2.Regs{GPPSID: []int8{2}},
 			wantGDPR:    gdpr.SignalYes,
 		},
 		{
-			description: "Regs Ext is nil, GPPSID has uspv1",
+			description: "Regs GDPR is nil, GPPSID has uspv1",
 			giveRegs:    &openrtb2.Regs{GPPSID: []int8{6}},
 			wantGDPR:    gdpr.SignalNo,
 		},
@@ -82,14 +75,8 @@ func TestExtractGDPR(t *testing.T) {
 				Regs: tt.giveRegs,
 			}
 
-			result, err := extractGDPR(&bidReq)
+			result := extractGDPR(&bidReq)
 			assert.Equal(t, tt.wantGDPR, result)
-
-			if tt.wantError {
-				assert.NotNil(t, err)
-			} else {
-				assert.Nil(t, err)
-			}
 		})
 	}
 }
@@ -100,21 +87,20 @@ func TestExtractConsent(t *testing.T) {
 		giveUser    *openrtb2.User
 		giveGPP     gpplib.GppContainer
 		wantConsent string
-		wantError   bool
 	}{
 		{
-			description: "User Ext Consent is not empty",
-			giveUser:    &openrtb2.User{Ext: json.RawMessage(`{"consent": "BOS2bx5OS2bx5ABABBAAABoAAAAAFA"}`)},
+			description: "User Consent is not empty",
+			giveUser:    &openrtb2.User{Consent: "BOS2bx5OS2bx5ABABBAAABoAAAAAFA"},
 			wantConsent: "BOS2bx5OS2bx5ABABBAAABoAAAAAFA",
 		},
 		{
-			description: "User Ext Consent is empty",
-			giveUser:    &openrtb2.User{Ext: json.RawMessage(`{"consent": ""}`)},
+			description: "User Consent is empty",
+			giveUser:    &openrtb2.User{Consent: ""},
 			wantConsent: "",
 		},
 		{
-			description: "User Ext is nil",
-			giveUser:    &openrtb2.User{Ext: nil},
+			description: "Consent in the 2.5 ext location is ignored",
+			giveUser:    &openrtb2.User{Ext: json.RawMessage(`{"consent": "BOS2bx5OS2bx5ABABBAAABoAAAAAFA"}`)},
 			wantConsent: "",
 		},
 		{
@@ -123,26 +109,20 @@ func TestExtractConsent(t *testing.T) {
 			wantConsent: "",
 		},
 		{
-			description: "JSON unmarshal error",
-			giveUser:    &openrtb2.User{Ext: json.RawMessage(`{`)},
-			wantConsent: "",
-			wantError:   true,
-		},
-		{
-			description: "User Ext is nil, GPP has no GDPR",
-			giveUser:    &openrtb2.User{Ext: nil},
+			description: "User Consent is empty, GPP has no GDPR",
+			giveUser:    &openrtb2.User{},
 			giveGPP:     gpplib.GppContainer{Version: 1, SectionTypes: []gppConstants.SectionID{6}, Sections: []gpplib.Section{&upsv1Section}},
 			wantConsent: "",
 		},
 		{
-			description: "User Ext is nil, GPP has GDPR",
-			giveUser:    &openrtb2.User{Ext: nil},
+			description: "User Consent is empty, GPP has GDPR",
+			giveUser:    &openrtb2.User{},
 			giveGPP:     gpplib.GppContainer{Version: 1, SectionTypes: []gppConstants.SectionID{2}, Sections: []gpplib.Section{&tcf1Section}},
 			wantConsent: "BOS2bx5OS2bx5ABABBAAABoAAAAAFA",
 		},
 		{
-			description: "User Ext has GDPR, GPP has GDPR",
-			giveUser:    &openrtb2.User{Ext: json.RawMessage(`{"consent": "BSOMECONSENT"}`)},
+			description: "User Consent is not empty, GPP has GDPR",
+			giveUser:    &openrtb2.User{Consent: "BSOMECONSENT"},
 			giveGPP:     gpplib.GppContainer{Version: 1, SectionTypes: []gppConstants.SectionID{2}, Sections: []gpplib.Section{&tcf1Section}},
 			wantConsent: "BOS2bx5OS2bx5ABABBAAABoAAAAAFA",
 		},
@@ -154,14 +134,8 @@ func TestExtractConsent(t *testing.T) {
 				User: tt.giveUser,
 			}
 
-			result, err := extractConsent(&bidReq, tt.giveGPP)
+			result := extractConsent(&bidReq, tt.giveGPP)
 			assert.Equal(t, tt.wantConsent, result, tt.description)
-
-			if tt.wantError {
-				assert.NotNil(t, err, tt.description)
-			} else {
-				assert.Nil(t, err, tt.description)
-			}
 		})
 	}
 }
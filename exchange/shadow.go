@@ -0,0 +1,198 @@
+package exchange
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/logger"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// maxShadowRequestsInFlight is the number of requests to the shadow endpoint of a bidder which can be in
+// flight. The requests sampled beyond it aren't mirrored, so a slow shadow endpoint can't pile them up.
+const maxShadowRequestsInFlight = 100
+
+// shadowTraffic mirrors a percentage of the requests to a bidder to a candidate endpoint, so it can be
+// measured against the live one. The shadow responses never take part in the auction.
+type shadowTraffic struct {
+	endpoint  *url.URL
+	percent   float64
+	randFloat func() float64
+	// logger logs the shadow requests, nil when they are only measured in the metrics.
+	logger   analytics.ShadowLogger
+	inFlight chan struct{}
+}
+
+// newShadowTraffic returns the shadow traffic of the bidder info, or nil if the bidder has none.
+func newShadowTraffic(info *config.ShadowInfo, logger analytics.ShadowLogger) *shadowTraffic {
+	if info == nil || info.Endpoint == "" {
+		return nil
+	}
+	endpoint, err := url.Parse(info.Endpoint)
+	if err != nil {
+		return nil
+	}
+	return &shadowTraffic{
+		endpoint:  endpoint,
+		percent:   info.Percent,
+		randFloat: rand.Float64,
+		logger:    logger,
+		inFlight:  make(chan struct{}, maxShadowRequestsInFlight),
+	}
+}
+
+// sampled returns true if the requests to the bidder are mirrored. The account overrides the percentage
+// of the bidder info.
+func (s *shadowTraffic) sampled(accountShadow config.AccountShadow, bidderName openrtb_ext.BidderName) bool {
+	if s == nil {
+		return false
+	}
+	percent := s.percent
+	if accountPercent, ok := accountShadow.Percent[string(bidderName)]; ok {
+		percent = accountPercent
+	}
+	if percent <= 0 {
+		return false
+	}
+	return percent >= 100 || s.randFloat()*100 < percent
+}
+
+// uri returns the URI of the request to the bidder sent to the shadow endpoint. The query of the
+// original request is kept unless the shadow endpoint declares its own.
+func (s *shadowTraffic) uri(original string) string {
+	shadowURL := *s.endpoint
+	if shadowURL.RawQuery == "" {
+		if originalURL, err := url.Parse(original); err == nil {
+			shadowURL.RawQuery = originalURL.RawQuery
+		}
+	}
+	return shadowURL.String()
+}
+
+// mirror sends a copy of the requests to the bidder to the shadow endpoint, if the bidder has one and
+// the requests are sampled. The copies are sent in the background, within the deadline of the bidder,
+// and their outcome is only recorded in the metrics and the analytics. The requests aren't mirrored
+// while maxShadowRequestsInFlight are in flight.
+func (bidder *bidderAdapter) mirror(ctx context.Context, bidderRequest BidderRequest, reqData []*adapters.RequestData, accountShadow config.AccountShadow) {
+	if !bidder.shadow.sampled(accountShadow, bidderRequest.BidderName) {
+		return
+	}
+	select {
+	case bidder.shadow.inFlight <- struct{}{}:
+	default:
+		logger.FromContext(ctx).Debugf("shadow requests of bidder %s skipped: %d in flight", bidder.BidderName, maxShadowRequestsInFlight)
+		return
+	}
+
+	// The shadow requests must not be cancelled when the auction ends before the deadline.
+	shadowCtx := logger.NewContext(context.Background(), logger.FromContext(ctx))
+	cancel := func() {}
+	if deadline, ok := ctx.Deadline(); ok {
+		shadowCtx, cancel = context.WithDeadline(shadowCtx, deadline)
+	}
+
+	bidRequest := *bidderRequest.BidRequest
+	shadowReqs := make([]*adapters.RequestData, 0, len(reqData))
+	for _, req := range reqData {
+		shadowReqs = append(shadowReqs, &adapters.RequestData{
+			Method:  req.Method,
+			Uri:     bidder.shadow.uri(req.Uri),
+			Body:    req.Body,
+			Headers: req.Headers.Clone(),
+		})
+	}
+
+	go func() {
+		defer func() { <-bidder.shadow.inFlight }()
+		defer cancel()
+		for _, req := range shadowReqs {
+			bidder.doShadowRequest(shadowCtx, &bidRequest, bidderRequest.BidderLabels.PubID, req)
+		}
+	}()
+}
+
+// doShadowRequest sends one request to the shadow endpoint and records its outcome.
+func (bidder *bidderAdapter) doShadowRequest(ctx context.Context, bidRequest *openrtb2.BidRequest, accountID string, req *adapters.RequestData) {
+	start := time.Now()
+	shadowObject := &analytics.ShadowObject{
+		Bidder:    string(bidder.BidderName),
+		AccountID: accountID,
+		Endpoint:  req.Uri,
+		StartTime: start,
+	}
+	status := metrics.ShadowError
+	bids := 0
+	defer func() {
+		if r := recover(); r != nil {
+			logger.FromContext(ctx).Errorf("shadow request to %s for bidder %s panicked: %v", req.Uri, bidder.BidderName, r)
+			status = metrics.ShadowError
+		}
+		shadowObject.Latency = time.Since(start)
+		bidder.me.RecordShadowRequest(metrics.ShadowLabels{Adapter: bidder.BidderName, Status: status}, shadowObject.Latency, bids)
+		if bidder.shadow.logger != nil {
+			bidder.shadow.logger.LogShadowObject(shadowObject)
+		}
+	}()
+
+	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(bidder.requestBody(req)))
+	if err != nil {
+		shadowObject.Errors = append(shadowObject.Errors, err)
+		return
+	}
+	httpReq.Header = req.Headers
+
+	httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq)
+	if err != nil {
+		if err == context.DeadlineExceeded {
+			status = metrics.ShadowTimeout
+		}
+		shadowObject.Errors = append(shadowObject.Errors, err)
+		return
+	}
+	defer httpResp.Body.Close()
+	shadowObject.Status = httpResp.StatusCode
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		shadowObject.Errors = append(shadowObject.Errors, err)
+		return
+	}
+	if httpResp.StatusCode == http.StatusNoContent {
+		status = metrics.ShadowNoBids
+		return
+	}
+
+	bidResponse, errs := bidder.Bidder.MakeBids(bidRequest, req, &adapters.ResponseData{
+		StatusCode: httpResp.StatusCode,
+		Body:       respBody,
+		Headers:    httpResp.Header,
+	})
+	shadowObject.Errors = append(shadowObject.Errors, errs...)
+	if bidResponse != nil {
+		for _, typedBid := range bidResponse.Bids {
+			if typedBid != nil && typedBid.Bid != nil {
+				shadowObject.Bids = append(shadowObject.Bids, typedBid.Bid)
+			}
+		}
+	}
+	bids = len(shadowObject.Bids)
+
+	switch {
+	case bids > 0:
+		status = metrics.ShadowBids
+	case httpResp.StatusCode >= 200 && httpResp.StatusCode < 400:
+		status = metrics.ShadowNoBids
+	}
+}
@@ -0,0 +1,247 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/experiment/adscert"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/metrics"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShadowTraffic(t *testing.T) {
+	assert.Nil(t, newShadowTraffic(nil, nil), "no shadow")
+	assert.Nil(t, newShadowTraffic(&config.ShadowInfo{Percent: 10}, nil), "no endpoint")
+
+	shadow := newShadowTraffic(&config.ShadowInfo{Endpoint: "https://shadow.com/openrtb2", Percent: 10}, nil)
+	if assert.NotNil(t, shadow, "shadow") {
+		assert.Equal(t, "https://shadow.com/openrtb2", shadow.endpoint.String(), "endpoint")
+		assert.Equal(t, 10.0, shadow.percent, "percent")
+	}
+}
+
+func TestShadowTrafficSampled(t *testing.T) {
+	testCases := []struct {
+		description   string
+		percent       float64
+		accountShadow config.AccountShadow
+		random        float64
+		expected      bool
+	}{
+		{
+			description: "Sampled",
+			percent:     10,
+			random:      0.05,
+			expected:    true,
+		},
+		{
+			description: "Not Sampled",
+			percent:     10,
+			random:      0.15,
+			expected:    false,
+		},
+		{
+			description: "Disabled",
+			percent:     0,
+			random:      0,
+			expected:    false,
+		},
+		{
+			description: "All Requests",
+			percent:     100,
+			random:      0.99,
+			expected:    true,
+		},
+		{
+			description:   "Account Override",
+			percent:       10,
+			accountShadow: config.AccountShadow{Percent: map[string]float64{"appnexus": 50}},
+			random:        0.3,
+			expected:      true,
+		},
+		{
+			description:   "Account Disables",
+			percent:       100,
+			accountShadow: config.AccountShadow{Percent: map[string]float64{"appnexus": 0}},
+			random:        0,
+			expected:      false,
+		},
+		{
+			description:   "Account Override Of Another Bidder",
+			percent:       10,
+			accountShadow: config.AccountShadow{Percent: map[string]float64{"rubicon": 100}},
+			random:        0.3,
+			expected:      false,
+		},
+	}
+
+	for _, test := range testCases {
+		shadow := newShadowTraffic(&config.ShadowInfo{Endpoint: "https://shadow.com", Percent: test.percent}, nil)
+		shadow.randFloat = func() float64 { return test.random }
+		assert.Equal(t, test.expected, shadow.sampled(test.accountShadow, openrtb_ext.BidderAppnexus), test.description)
+	}
+
+	var noShadow *shadowTraffic
+	assert.False(t, noShadow.sampled(config.AccountShadow{Percent: map[string]float64{"appnexus": 100}}, openrtb_ext.BidderAppnexus), "no shadow")
+}
+
+func TestShadowTrafficURI(t *testing.T) {
+	testCases := []struct {
+		description string
+		endpoint    string
+		original    string
+		expected    string
+	}{
+		{
+			description: "Original Query Kept",
+			endpoint:    "https://shadow.com/openrtb2",
+			original:    "https://bidder.com/openrtb2?member=1",
+			expected:    "https://shadow.com/openrtb2?member=1",
+		},
+		{
+			description: "Shadow Query Kept",
+			endpoint:    "https://shadow.com/openrtb2?candidate=true",
+			original:    "https://bidder.com/openrtb2?member=1",
+			expected:    "https://shadow.com/openrtb2?candidate=true",
+		},
+		{
+			description: "No Query",
+			endpoint:    "https://shadow.com/openrtb2",
+			original:    "https://bidder.com/openrtb2",
+			expected:    "https://shadow.com/openrtb2",
+		},
+	}
+
+	for _, test := range testCases {
+		shadow := newShadowTraffic(&config.ShadowInfo{Endpoint: test.endpoint}, nil)
+		assert.Equal(t, test.expected, shadow.uri(test.original), test.description)
+	}
+}
+
+func TestRequestBidShadow(t *testing.T) {
+	mainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"some-request-id","seatbid":[{"bid":[{"id":"main-bid","impid":"some-imp","price":1}]}]}`))
+	}))
+	defer mainServer.Close()
+	shadowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"some-request-id","seatbid":[{"bid":[{"id":"shadow-bid-1","impid":"some-imp","price":2},{"id":"shadow-bid-2","impid":"some-imp","price":3}]}]}`))
+	}))
+	defer shadowServer.Close()
+
+	module := &shadowCapturingModule{objects: make(chan *analytics.ShadowObject, 1)}
+
+	bidder := AdaptBidder(&shadowTestBidder{endpoint: mainServer.URL}, mainServer.Client(), &config.Configuration{}, &metricsConfig.NilMetricsEngine{},
+		openrtb_ext.BidderAppnexus, nil, "", nil, &config.ShadowInfo{Endpoint: shadowServer.URL}, module).(*bidderAdapter)
+
+	bidderReq := BidderRequest{
+		BidRequest:   &openrtb2.BidRequest{ID: "some-request-id", Imp: []openrtb2.Imp{{ID: "some-imp"}}},
+		BidderName:   openrtb_ext.BidderAppnexus,
+		BidderLabels: metrics.AdapterLabels{PubID: "some-account"},
+	}
+	bidReqOptions := bidRequestOptions{
+		accountShadow: config.AccountShadow{Percent: map[string]float64{"appnexus": 100}},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	seatBids, errs := bidder.requestBid(ctx, bidderReq, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidReqOptions, openrtb_ext.ExtAlternateBidderCodes{}, &hookexecution.EmptyHookExecutor{})
+	assert.Empty(t, errs, "errors")
+	if assert.Len(t, seatBids, 1, "seat bids") && assert.Len(t, seatBids[0].Bids, 1, "bids") {
+		assert.Equal(t, "main-bid", seatBids[0].Bids[0].Bid.ID, "shadow bids are not in the auction")
+	}
+
+	select {
+	case shadowObject := <-module.objects:
+		assert.Equal(t, "appnexus", shadowObject.Bidder, "bidder")
+		assert.Equal(t, "some-account", shadowObject.AccountID, "account")
+		assert.Equal(t, shadowServer.URL, shadowObject.Endpoint, "endpoint")
+		assert.Equal(t, http.StatusOK, shadowObject.Status, "status")
+		assert.Empty(t, shadowObject.Errors, "errors")
+		if assert.Len(t, shadowObject.Bids, 2, "shadow bids") {
+			assert.Equal(t, "shadow-bid-1", shadowObject.Bids[0].ID, "shadow bid")
+		}
+	case <-time.After(time.Second):
+		assert.Fail(t, "the shadow request wasn't logged")
+	}
+}
+
+func TestMirrorInFlightLimit(t *testing.T) {
+	shadowRequests := make(chan struct{}, 1)
+	shadowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shadowRequests <- struct{}{}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer shadowServer.Close()
+
+	bidder := AdaptBidder(&shadowTestBidder{}, shadowServer.Client(), &config.Configuration{}, &metricsConfig.NilMetricsEngine{},
+		openrtb_ext.BidderAppnexus, nil, "", nil, &config.ShadowInfo{Endpoint: shadowServer.URL, Percent: 100}, nil).(*bidderAdapter)
+	for i := 0; i < maxShadowRequestsInFlight; i++ {
+		bidder.shadow.inFlight <- struct{}{}
+	}
+
+	bidderReq := BidderRequest{BidRequest: &openrtb2.BidRequest{ID: "some-request-id"}, BidderName: openrtb_ext.BidderAppnexus}
+	reqData := []*adapters.RequestData{{Method: "POST", Uri: "https://bidder.com", Body: []byte(`{}`)}}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	bidder.mirror(ctx, bidderReq, reqData, config.AccountShadow{})
+	select {
+	case <-shadowRequests:
+		assert.Fail(t, "mirrored while the limit is reached")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-bidder.shadow.inFlight
+	bidder.mirror(ctx, bidderReq, reqData, config.AccountShadow{})
+	select {
+	case <-shadowRequests:
+	case <-time.After(time.Second):
+		assert.Fail(t, "not mirrored below the limit")
+	}
+}
+
+type shadowTestBidder struct {
+	endpoint string
+}
+
+func (b *shadowTestBidder) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, []error{err}
+	}
+	return []*adapters.RequestData{{Method: "POST", Uri: b.endpoint, Body: body}}, nil
+}
+
+func (b *shadowTestBidder) MakeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	var bidResp openrtb2.BidResponse
+	if err := json.Unmarshal(response.Body, &bidResp); err != nil {
+		return nil, []error{err}
+	}
+	bidResponse := adapters.NewBidderResponse()
+	for _, seatBid := range bidResp.SeatBid {
+		for i := range seatBid.Bid {
+			bidResponse.Bids = append(bidResponse.Bids, &adapters.TypedBid{Bid: &seatBid.Bid[i], BidType: openrtb_ext.BidTypeBanner})
+		}
+	}
+	return bidResponse, nil
+}
+
+// shadowCapturingModule captures the shadow objects.
+type shadowCapturingModule struct {
+	objects chan *analytics.ShadowObject
+}
+
+func (m *shadowCapturingModule) LogShadowObject(so *analytics.ShadowObject) {
+	m.objects <- so
+}
@@ -0,0 +1,142 @@
+package exchange
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// The steps of the auction recorded in its timeline.
+const (
+	TraceStepStoredRequestFetch = "stored_request_fetch"
+	TraceStepAccountFetch       = "account_fetch"
+	TraceStepPrivacy            = "privacy_enforcement"
+	TraceStepBidderRequest      = "bidder_request"
+	TraceStepBidValidation      = "bid_validation"
+	TraceStepCurrencyConversion = "currency_conversion"
+	TraceStepCategoryMapping    = "category_mapping"
+	TraceStepCacheWrite         = "cache_write"
+)
+
+// Timeline records the steps of an auction, returned in bidresponse.ext.debug.trace. The endpoints record
+// their steps before it is known if the trace is allowed; HoldAuction disables the timeline if it isn't.
+// A nil Timeline records nothing.
+type Timeline struct {
+	mu       sync.Mutex
+	start    time.Time
+	disabled bool
+	events   []openrtb_ext.ExtTraceEvent
+}
+
+// NewTimeline returns the timeline of the auction requested at start.
+func NewTimeline(start time.Time) *Timeline {
+	return &Timeline{start: start}
+}
+
+// Enabled returns false if the steps aren't recorded, so the callers can skip building their details.
+func (t *Timeline) Enabled() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.disabled
+}
+
+// Record adds the step started at started and ending now to the timeline. The bidder is empty for the
+// steps done for the whole auction.
+func (t *Timeline) Record(step string, bidder openrtb_ext.BidderName, started time.Time, details map[string]interface{}) {
+	if t == nil {
+		return
+	}
+	ended := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.disabled {
+		return
+	}
+	t.events = append(t.events, openrtb_ext.ExtTraceEvent{
+		Step:           step,
+		Bidder:         string(bidder),
+		StartMillis:    millis(started.Sub(t.start)),
+		DurationMillis: millis(ended.Sub(started)),
+		Details:        details,
+	})
+}
+
+// disable drops the steps recorded so far and stops recording.
+func (t *Timeline) disable() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.disabled = true
+	t.events = nil
+}
+
+// Events returns the steps recorded so far, by start time.
+func (t *Timeline) Events() []openrtb_ext.ExtTraceEvent {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.events) == 0 {
+		return nil
+	}
+	events := make([]openrtb_ext.ExtTraceEvent, len(t.events))
+	copy(events, t.events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].StartMillis < events[j].StartMillis
+	})
+	return events
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type timelineContextKey struct{}
+
+// NewTimelineContext returns a copy of the context carrying the timeline.
+func NewTimelineContext(ctx context.Context, t *Timeline) context.Context {
+	return context.WithValue(ctx, timelineContextKey{}, t)
+}
+
+// TimelineFromContext returns the timeline carried by the context, or nil if there is none.
+func TimelineFromContext(ctx context.Context) *Timeline {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(timelineContextKey{}).(*Timeline)
+	return t
+}
+
+// traceLevelVerbose is the ext.prebid.trace level returning the timeline of the auction.
+const traceLevelVerbose = "verbose"
+
+// traceAllowed returns true if the timeline of the auction is returned: the verbose trace is requested,
+// and debug is allowed for the account or overridden by the debug header.
+func traceAllowed(requestExt *openrtb_ext.ExtRequest, accountDebugFlag bool, debugLog *DebugLog) bool {
+	if requestExt == nil || requestExt.Prebid.Trace != traceLevelVerbose {
+		return false
+	}
+	return accountDebugFlag || (debugLog != nil && debugLog.DebugOverride)
+}
+
+// addTrace returns the timeline of the auction in bidresponse.ext.debug.trace.
+func addTrace(ctx context.Context, bidResponseExt *openrtb_ext.ExtBidResponse) {
+	events := TimelineFromContext(ctx).Events()
+	if len(events) == 0 || bidResponseExt == nil {
+		return
+	}
+	if bidResponseExt.Debug == nil {
+		bidResponseExt.Debug = &openrtb_ext.ExtResponseDebug{}
+	}
+	bidResponseExt.Debug.Trace = events
+}
@@ -0,0 +1,129 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimelineRecord(t *testing.T) {
+	start := time.Now().Add(-100 * time.Millisecond)
+	timeline := NewTimeline(start)
+	assert.True(t, timeline.Enabled(), "enabled")
+	assert.Nil(t, timeline.Events(), "no events")
+
+	timeline.Record(TraceStepAccountFetch, "", start.Add(20*time.Millisecond), map[string]interface{}{"account": "some-account"})
+	timeline.Record(TraceStepStoredRequestFetch, "", start.Add(10*time.Millisecond), nil)
+	timeline.Record(TraceStepPrivacy, openrtb_ext.BidderAppnexus, start.Add(30*time.Millisecond), nil)
+
+	events := timeline.Events()
+	if !assert.Len(t, events, 3) {
+		return
+	}
+	assert.Equal(t, TraceStepStoredRequestFetch, events[0].Step, "sorted by start time")
+	assert.Equal(t, 10.0, events[0].StartMillis, "start")
+	assert.GreaterOrEqual(t, events[0].DurationMillis, 90.0, "duration")
+	assert.Equal(t, TraceStepAccountFetch, events[1].Step, "sorted by start time")
+	assert.Equal(t, map[string]interface{}{"account": "some-account"}, events[1].Details, "details")
+	assert.Equal(t, TraceStepPrivacy, events[2].Step, "sorted by start time")
+	assert.Equal(t, "appnexus", events[2].Bidder, "bidder")
+}
+
+func TestTimelineDisable(t *testing.T) {
+	timeline := NewTimeline(time.Now())
+	timeline.Record(TraceStepAccountFetch, "", time.Now(), nil)
+
+	timeline.disable()
+	timeline.Record(TraceStepCacheWrite, "", time.Now(), nil)
+
+	assert.False(t, timeline.Enabled(), "enabled")
+	assert.Nil(t, timeline.Events(), "events")
+}
+
+func TestNilTimeline(t *testing.T) {
+	var timeline *Timeline
+	timeline.Record(TraceStepAccountFetch, "", time.Now(), nil)
+	timeline.disable()
+
+	assert.False(t, timeline.Enabled(), "enabled")
+	assert.Nil(t, timeline.Events(), "events")
+}
+
+func TestTimelineContext(t *testing.T) {
+	assert.Nil(t, TimelineFromContext(context.Background()), "no timeline")
+
+	timeline := NewTimeline(time.Now())
+	ctx := NewTimelineContext(context.Background(), timeline)
+	assert.Same(t, timeline, TimelineFromContext(ctx), "timeline")
+}
+
+func TestTraceAllowed(t *testing.T) {
+	verbose := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{Trace: "verbose"}}
+	basic := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{Trace: "basic"}}
+
+	testCases := []struct {
+		description      string
+		requestExt       *openrtb_ext.ExtRequest
+		accountDebugFlag bool
+		debugLog         *DebugLog
+		expected         bool
+	}{
+		{
+			description:      "Verbose Trace, Debug Allowed For The Account",
+			requestExt:       verbose,
+			accountDebugFlag: true,
+			expected:         true,
+		},
+		{
+			description: "Verbose Trace, Debug Overridden By The Header",
+			requestExt:  verbose,
+			debugLog:    &DebugLog{DebugOverride: true},
+			expected:    true,
+		},
+		{
+			description: "Verbose Trace, Debug Not Allowed",
+			requestExt:  verbose,
+			debugLog:    &DebugLog{},
+			expected:    false,
+		},
+		{
+			description:      "Basic Trace",
+			requestExt:       basic,
+			accountDebugFlag: true,
+			expected:         false,
+		},
+		{
+			description:      "No Request Ext",
+			requestExt:       nil,
+			accountDebugFlag: true,
+			expected:         false,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, traceAllowed(test.requestExt, test.accountDebugFlag, test.debugLog), test.description)
+	}
+}
+
+func TestAddTrace(t *testing.T) {
+	timeline := NewTimeline(time.Now())
+	timeline.Record(TraceStepCacheWrite, "", time.Now(), nil)
+	ctx := NewTimelineContext(context.Background(), timeline)
+
+	bidResponseExt := &openrtb_ext.ExtBidResponse{}
+	addTrace(ctx, bidResponseExt)
+	if assert.NotNil(t, bidResponseExt.Debug, "debug") {
+		assert.Equal(t, timeline.Events(), bidResponseExt.Debug.Trace, "trace")
+	}
+
+	timeline.disable()
+	bidResponseExt = &openrtb_ext.ExtBidResponse{}
+	addTrace(ctx, bidResponseExt)
+	assert.Nil(t, bidResponseExt.Debug, "disabled")
+
+	addTrace(context.Background(), bidResponseExt)
+	assert.Nil(t, bidResponseExt.Debug, "no timeline")
+}
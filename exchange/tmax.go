@@ -0,0 +1,197 @@
+package exchange
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const (
+	// tmaxReasonAuction is for the bidders given all the time left in the auction, because their latency
+	// is unknown or above it.
+	tmaxReasonAuction = "auction"
+	// tmaxReasonLatency is for the bidders cut off early, based on their recent latency.
+	tmaxReasonLatency = "latency"
+	// tmaxReasonSkipped is for the bidders not called, because too little time is left in the auction.
+	tmaxReasonSkipped = "skipped"
+)
+
+var errTmaxTooLow = &errortypes.Timeout{Message: "The bidder wasn't called: too little time is left in the auction"}
+
+// tmaxAdjustments derives the timeout of each bidder from the time left in the auction and the latency
+// of its recent responses.
+type tmaxAdjustments struct {
+	networkLatencyBuffer  time.Duration
+	responsePreparation   time.Duration
+	bidderResponseMinimum time.Duration
+	latencyMultiplier     float64
+	minLatencySamples     int
+	latencies             *bidderLatencies
+}
+
+func newTmaxAdjustments(cfg config.TmaxAdjustments) *tmaxAdjustments {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &tmaxAdjustments{
+		networkLatencyBuffer:  time.Duration(cfg.BidderNetworkLatencyBufferMs) * time.Millisecond,
+		responsePreparation:   time.Duration(cfg.PBSResponsePreparationDurationMs) * time.Millisecond,
+		bidderResponseMinimum: time.Duration(cfg.BidderResponseDurationMinMs) * time.Millisecond,
+		latencyMultiplier:     cfg.LatencyMultiplier,
+		minLatencySamples:     cfg.MinLatencySamples,
+		latencies:             newBidderLatencies(cfg.LatencyWindowSize, cfg.LatencyPercentile),
+	}
+}
+
+// bidderTimeout is the timeout given to a bidder for an auction.
+type bidderTimeout struct {
+	// deadline is when the bidder is cut off.
+	deadline time.Time
+	debug    openrtb_ext.ExtBidderTmax
+}
+
+func (t bidderTimeout) skipped() bool {
+	return t.debug.Reason == tmaxReasonSkipped
+}
+
+// bidderTimeouts returns the timeout of each bidder of the auction ending at the deadline. It returns nil
+// if the auction has no deadline.
+func (t *tmaxAdjustments) bidderTimeouts(ctx context.Context, now time.Time, bidderRequests []BidderRequest) map[openrtb_ext.BidderName]bidderTimeout {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	budget := deadline.Sub(now) - t.responsePreparation
+
+	timeouts := make(map[openrtb_ext.BidderName]bidderTimeout, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		timeouts[bidderRequest.BidderName] = t.bidderTimeout(bidderRequest.BidderName, now, budget)
+	}
+	return timeouts
+}
+
+func (t *tmaxAdjustments) bidderTimeout(bidder openrtb_ext.BidderName, now time.Time, budget time.Duration) bidderTimeout {
+	latency, samples := t.latencies.latency(bidder)
+	timeout := bidderTimeout{
+		debug: openrtb_ext.ExtBidderTmax{
+			LatencySamples: samples,
+			Reason:         tmaxReasonAuction,
+		},
+	}
+	if samples >= t.minLatencySamples {
+		timeout.debug.LatencyMillis = latency.Milliseconds()
+	} else {
+		latency = 0
+	}
+
+	minimum := t.bidderResponseMinimum + t.networkLatencyBuffer
+	if budget < minimum || budget <= t.networkLatencyBuffer {
+		timeout.debug.Reason = tmaxReasonSkipped
+		return timeout
+	}
+
+	duration := budget
+	if latency > 0 {
+		adjusted := time.Duration(float64(latency) * t.latencyMultiplier)
+		if adjusted < minimum {
+			adjusted = minimum
+		}
+		if adjusted < budget {
+			duration = adjusted
+			timeout.debug.Reason = tmaxReasonLatency
+		}
+	}
+
+	timeout.deadline = now.Add(duration)
+	timeout.debug.TimeoutMillis = duration.Milliseconds()
+	timeout.debug.Tmax = (duration - t.networkLatencyBuffer).Milliseconds()
+	return timeout
+}
+
+// bidderLatencies tracks the response times of the recent requests to each bidder.
+type bidderLatencies struct {
+	windowSize int
+	percentile int
+
+	mutex   sync.RWMutex
+	bidders map[openrtb_ext.BidderName]*latencyWindow
+}
+
+// latencyWindow is a ring buffer of the latencies of the recent requests to a bidder, with their percentile.
+type latencyWindow struct {
+	latencies []time.Duration
+	next      int
+	samples   int
+	// percentile is recomputed every recomputeEvery requests, instead of on each auction.
+	percentile     time.Duration
+	sinceRecompute int
+}
+
+func newBidderLatencies(windowSize, percentile int) *bidderLatencies {
+	return &bidderLatencies{
+		windowSize: windowSize,
+		percentile: percentile,
+		bidders:    make(map[openrtb_ext.BidderName]*latencyWindow),
+	}
+}
+
+func (l *bidderLatencies) record(bidder openrtb_ext.BidderName, latency time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	window, ok := l.bidders[bidder]
+	if !ok {
+		window = &latencyWindow{latencies: make([]time.Duration, l.windowSize)}
+		l.bidders[bidder] = window
+	}
+
+	window.latencies[window.next] = latency
+	window.next = (window.next + 1) % len(window.latencies)
+	if window.samples < len(window.latencies) {
+		window.samples++
+	}
+
+	window.sinceRecompute++
+	if window.sinceRecompute >= l.recomputeEvery() || window.samples < len(window.latencies) {
+		window.sinceRecompute = 0
+		window.percentile = l.compute(window)
+	}
+}
+
+// latency returns the latency percentile of the recent requests to the bidder, and their number.
+func (l *bidderLatencies) latency(bidder openrtb_ext.BidderName) (time.Duration, int) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	window, ok := l.bidders[bidder]
+	if !ok {
+		return 0, 0
+	}
+	return window.percentile, window.samples
+}
+
+func (l *bidderLatencies) recomputeEvery() int {
+	if every := l.windowSize / 20; every > 1 {
+		return every
+	}
+	return 1
+}
+
+// compute returns the latency percentile of the window. The caller must hold the lock.
+func (l *bidderLatencies) compute(window *latencyWindow) time.Duration {
+	sorted := make([]time.Duration, window.samples)
+	copy(sorted, window.latencies[:window.samples])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(float64(len(sorted)*l.percentile)/100)) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
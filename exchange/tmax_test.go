@@ -0,0 +1,199 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	metricsConf "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTmaxAdjustments(t *testing.T) {
+	assert.Nil(t, newTmaxAdjustments(config.TmaxAdjustments{}), "disabled")
+	assert.NotNil(t, newTmaxAdjustments(config.TmaxAdjustments{Enabled: true, LatencyPercentile: 95, LatencyMultiplier: 1.5, LatencyWindowSize: 10, MinLatencySamples: 5}), "enabled")
+}
+
+func TestBidderLatencies(t *testing.T) {
+	latencies := newBidderLatencies(10, 90)
+
+	latency, samples := latencies.latency("appnexus")
+	assert.Equal(t, time.Duration(0), latency, "unknown bidder latency")
+	assert.Equal(t, 0, samples, "unknown bidder samples")
+
+	for i := 1; i <= 10; i++ {
+		latencies.record("appnexus", time.Duration(i)*time.Millisecond)
+	}
+	latency, samples = latencies.latency("appnexus")
+	assert.Equal(t, 9*time.Millisecond, latency, "full window latency")
+	assert.Equal(t, 10, samples, "full window samples")
+
+	for i := 0; i < 10; i++ {
+		latencies.record("appnexus", 100*time.Millisecond)
+	}
+	latency, samples = latencies.latency("appnexus")
+	assert.Equal(t, 100*time.Millisecond, latency, "window rolled over latency")
+	assert.Equal(t, 10, samples, "window rolled over samples")
+
+	_, samples = latencies.latency("rubicon")
+	assert.Equal(t, 0, samples, "bidders tracked separately")
+}
+
+func TestBidderTimeouts(t *testing.T) {
+	cfg := config.TmaxAdjustments{
+		Enabled:                          true,
+		BidderNetworkLatencyBufferMs:     20,
+		PBSResponsePreparationDurationMs: 50,
+		BidderResponseDurationMinMs:      30,
+		LatencyPercentile:                100,
+		LatencyMultiplier:                2,
+		LatencyWindowSize:                10,
+		MinLatencySamples:                5,
+	}
+
+	testCases := []struct {
+		description     string
+		auctionTimeout  time.Duration
+		latency         time.Duration
+		samples         int
+		expectedTimeout openrtb_ext.ExtBidderTmax
+	}{
+		{
+			description:     "No Latency",
+			auctionTimeout:  500 * time.Millisecond,
+			expectedTimeout: openrtb_ext.ExtBidderTmax{Tmax: 430, TimeoutMillis: 450, Reason: tmaxReasonAuction},
+		},
+		{
+			description:     "Not Enough Samples",
+			auctionTimeout:  500 * time.Millisecond,
+			latency:         100 * time.Millisecond,
+			samples:         4,
+			expectedTimeout: openrtb_ext.ExtBidderTmax{Tmax: 430, TimeoutMillis: 450, LatencySamples: 4, Reason: tmaxReasonAuction},
+		},
+		{
+			description:     "Fast Bidder Cut Off Early",
+			auctionTimeout:  500 * time.Millisecond,
+			latency:         100 * time.Millisecond,
+			samples:         5,
+			expectedTimeout: openrtb_ext.ExtBidderTmax{Tmax: 180, TimeoutMillis: 200, LatencyMillis: 100, LatencySamples: 5, Reason: tmaxReasonLatency},
+		},
+		{
+			description:     "Very Fast Bidder Gets The Minimum",
+			auctionTimeout:  500 * time.Millisecond,
+			latency:         5 * time.Millisecond,
+			samples:         5,
+			expectedTimeout: openrtb_ext.ExtBidderTmax{Tmax: 30, TimeoutMillis: 50, LatencyMillis: 5, LatencySamples: 5, Reason: tmaxReasonLatency},
+		},
+		{
+			description:     "Slow Bidder Gets The Auction Timeout",
+			auctionTimeout:  500 * time.Millisecond,
+			latency:         300 * time.Millisecond,
+			samples:         5,
+			expectedTimeout: openrtb_ext.ExtBidderTmax{Tmax: 430, TimeoutMillis: 450, LatencyMillis: 300, LatencySamples: 5, Reason: tmaxReasonAuction},
+		},
+		{
+			description:     "Too Little Time Left",
+			auctionTimeout:  90 * time.Millisecond,
+			expectedTimeout: openrtb_ext.ExtBidderTmax{Reason: tmaxReasonSkipped},
+		},
+	}
+
+	for _, test := range testCases {
+		adjustments := newTmaxAdjustments(cfg)
+		for i := 0; i < test.samples; i++ {
+			adjustments.latencies.record("appnexus", test.latency)
+		}
+
+		now := time.Now()
+		ctx, cancel := context.WithDeadline(context.Background(), now.Add(test.auctionTimeout))
+		timeouts := adjustments.bidderTimeouts(ctx, now, []BidderRequest{{BidderName: "appnexus"}})
+		cancel()
+
+		if assert.Contains(t, timeouts, openrtb_ext.BidderName("appnexus"), test.description) {
+			timeout := timeouts["appnexus"]
+			assert.Equal(t, test.expectedTimeout, timeout.debug, test.description)
+			assert.Equal(t, test.expectedTimeout.Reason == tmaxReasonSkipped, timeout.skipped(), test.description+":skipped")
+			if !timeout.skipped() {
+				assert.Equal(t, now.Add(time.Duration(test.expectedTimeout.TimeoutMillis)*time.Millisecond), timeout.deadline, test.description+":deadline")
+			}
+		}
+	}
+}
+
+func TestBidderTimeoutsWithoutDeadline(t *testing.T) {
+	adjustments := newTmaxAdjustments(config.TmaxAdjustments{Enabled: true, LatencyPercentile: 95, LatencyMultiplier: 1.5, LatencyWindowSize: 10, MinLatencySamples: 5})
+
+	timeouts := adjustments.bidderTimeouts(context.Background(), time.Now(), []BidderRequest{{BidderName: "appnexus"}})
+	assert.Nil(t, timeouts)
+}
+
+func TestGetAllBidsWithBidderTimeouts(t *testing.T) {
+	appnexus := &capturingRequestBidder{}
+	rubicon := &capturingRequestBidder{}
+	e := &exchange{
+		adapterMap: map[openrtb_ext.BidderName]AdaptedBidder{
+			openrtb_ext.BidderAppnexus: appnexus,
+			openrtb_ext.BidderRubicon:  rubicon,
+		},
+		me:              &metricsConf.NilMetricsEngine{},
+		tmaxAdjustments: newTmaxAdjustments(config.TmaxAdjustments{Enabled: true, LatencyPercentile: 95, LatencyMultiplier: 1.5, LatencyWindowSize: 10, MinLatencySamples: 5}),
+	}
+	bidderRequests := []BidderRequest{
+		{BidderName: openrtb_ext.BidderAppnexus, BidderCoreName: openrtb_ext.BidderAppnexus, BidRequest: &openrtb2.BidRequest{ID: "appnexus", TMax: 500}},
+		{BidderName: openrtb_ext.BidderRubicon, BidderCoreName: openrtb_ext.BidderRubicon, BidRequest: &openrtb2.BidRequest{ID: "rubicon", TMax: 500}},
+	}
+	bidderTimeouts := map[openrtb_ext.BidderName]bidderTimeout{
+		openrtb_ext.BidderAppnexus: {
+			deadline: time.Now().Add(time.Second),
+			debug:    openrtb_ext.ExtBidderTmax{Tmax: 180, TimeoutMillis: 200, Reason: tmaxReasonLatency},
+		},
+		openrtb_ext.BidderRubicon: {
+			debug: openrtb_ext.ExtBidderTmax{Reason: tmaxReasonSkipped},
+		},
+	}
+
+	_, adapterExtra, _, _ := e.getAllBids(context.Background(), bidderRequests, bidderTimeouts, nil, nil, false, "", false, openrtb_ext.ExtAlternateBidderCodes{}, nil, config.AccountShadow{}, &hookexecution.EmptyHookExecutor{})
+
+	if assert.NotNil(t, appnexus.req, "appnexus called") {
+		assert.Equal(t, int64(180), appnexus.req.TMax, "appnexus tmax")
+	}
+	assert.Nil(t, rubicon.req, "rubicon not called")
+
+	_, samples := e.tmaxAdjustments.latencies.latency(openrtb_ext.BidderAppnexus)
+	assert.Equal(t, 1, samples, "appnexus latency recorded")
+	_, samples = e.tmaxAdjustments.latencies.latency(openrtb_ext.BidderRubicon)
+	assert.Equal(t, 0, samples, "rubicon latency not recorded")
+
+	assert.Equal(t, &openrtb_ext.ExtBidderTmax{Tmax: 180, TimeoutMillis: 200, Reason: tmaxReasonLatency}, adapterExtra[openrtb_ext.BidderAppnexus].Tmax, "appnexus debug")
+	assert.Equal(t, &openrtb_ext.ExtBidderTmax{Reason: tmaxReasonSkipped}, adapterExtra[openrtb_ext.BidderRubicon].Tmax, "rubicon debug")
+	assert.Equal(t, []openrtb_ext.ExtBidderMessage{{Code: errortypes.TimeoutErrorCode, Message: errTmaxTooLow.Error()}}, adapterExtra[openrtb_ext.BidderRubicon].Errors, "rubicon errors")
+}
+
+func TestInheritState(t *testing.T) {
+	cfg := &config.Configuration{TmaxAdjustments: config.TmaxAdjustments{Enabled: true, LatencyPercentile: 95, LatencyMultiplier: 1.5, LatencyWindowSize: 10, MinLatencySamples: 5}}
+	newExchange := func(cfg *config.Configuration) *exchange {
+		return NewExchange(nil, nil, cfg, nil, &metricsConf.NilMetricsEngine{}, nil, nil, nil, nil, nil, nil, nil, nil).(*exchange)
+	}
+
+	previous := newExchange(cfg)
+	previous.tmaxAdjustments.latencies.record(openrtb_ext.BidderAppnexus, 100*time.Millisecond)
+
+	rebuilt := newExchange(cfg)
+	InheritState(rebuilt, previous)
+	assert.Same(t, previous.tmaxAdjustments.latencies, rebuilt.tmaxAdjustments.latencies, "the latency windows are kept")
+
+	resized := *cfg
+	resized.TmaxAdjustments.LatencyWindowSize = 20
+	rebuiltResized := newExchange(&resized)
+	InheritState(rebuiltResized, previous)
+	assert.NotSame(t, previous.tmaxAdjustments.latencies, rebuiltResized.tmaxAdjustments.latencies, "the latency windows of another size are not kept")
+
+	disabled := newExchange(&config.Configuration{})
+	InheritState(disabled, previous)
+	assert.Nil(t, disabled.tmaxAdjustments, "tmax adjustments disabled")
+}
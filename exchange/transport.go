@@ -0,0 +1,134 @@
+package exchange
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// transportClients keeps the clients of the bidders declaring a dedicated transport across the rebuilds
+// of the adapters on reload, so their connection pools survive them.
+var transportClients = struct {
+	sync.Mutex
+	clients map[openrtb_ext.BidderName]transportClient
+}{clients: make(map[openrtb_ext.BidderName]transportClient)}
+
+type transportClient struct {
+	profile config.TransportProfile
+	shared  *http.Client
+	client  *http.Client
+}
+
+// bidderClient returns the client of the bidder and the name of its transport profile: the shared client,
+// or one built from the dedicated transport declared by the bidder.
+func bidderClient(name openrtb_ext.BidderName, profile *config.TransportProfile, shared *http.Client) (*http.Client, string) {
+	if profile == nil {
+		return shared, config.DefaultTransportProfile
+	}
+	profileName := profile.Name
+	if profileName == "" {
+		profileName = string(name)
+	}
+
+	transportClients.Lock()
+	defer transportClients.Unlock()
+
+	if existing, ok := transportClients.clients[name]; ok {
+		if existing.profile == *profile && existing.shared == shared {
+			return existing.client, profileName
+		}
+		existing.client.CloseIdleConnections()
+	}
+
+	client := newTransportClient(*profile, shared)
+	transportClients.clients[name] = transportClient{
+		profile: *profile,
+		shared:  shared,
+		client:  client,
+	}
+	return client, profileName
+}
+
+// newTransportClient builds a client from the transport profile. The settings left empty in the profile
+// are the ones of the shared client.
+func newTransportClient(profile config.TransportProfile, shared *http.Client) *http.Client {
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if sharedTransport, ok := shared.Transport.(*http.Transport); ok {
+		transport = sharedTransport.Clone()
+	}
+
+	if profile.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = profile.MaxConnsPerHost
+	}
+	if profile.MaxIdleConns > 0 {
+		transport.MaxIdleConns = profile.MaxIdleConns
+	}
+	if profile.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = profile.MaxIdleConnsPerHost
+	}
+	if profile.IdleConnTimeoutSeconds > 0 {
+		transport.IdleConnTimeout = time.Duration(profile.IdleConnTimeoutSeconds) * time.Second
+	}
+	if profile.KeepAliveSeconds > 0 {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: time.Duration(profile.KeepAliveSeconds) * time.Second,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+	if profile.DisableKeepAlives {
+		transport.DisableKeepAlives = true
+	}
+
+	if profile.TLS.HandshakeTimeoutSeconds > 0 {
+		transport.TLSHandshakeTimeout = time.Duration(profile.TLS.HandshakeTimeoutSeconds) * time.Second
+	}
+	if profile.TLS.MinVersion != "" {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.MinVersion = tlsVersion(profile.TLS.MinVersion)
+	}
+
+	// A transport with a custom TLS config or dialer only attempts HTTP/2 when forced to, with the
+	// default protocols.
+	transport.ForceAttemptHTTP2 = profile.HTTP2
+	if profile.HTTP2 {
+		transport.TLSNextProto = nil
+	} else {
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+		if transport.TLSClientConfig != nil {
+			transport.TLSClientConfig.NextProtos = withoutHTTP2(transport.TLSClientConfig.NextProtos)
+		}
+	}
+
+	return &http.Client{
+		Transport:     transport,
+		CheckRedirect: shared.CheckRedirect,
+		Jar:           shared.Jar,
+		Timeout:       shared.Timeout,
+	}
+}
+
+func tlsVersion(version string) uint16 {
+	if version == config.TLSVersion13 {
+		return tls.VersionTLS13
+	}
+	return tls.VersionTLS12
+}
+
+// withoutHTTP2 removes HTTP/2 from the protocols negotiated with TLS, which the shared transport may offer.
+func withoutHTTP2(protos []string) []string {
+	var result []string
+	for _, proto := range protos {
+		if proto != "h2" {
+			result = append(result, proto)
+		}
+	}
+	return result
+}
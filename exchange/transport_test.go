@@ -0,0 +1,151 @@
+package exchange
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBidderClient(t *testing.T) {
+	shared := &http.Client{Transport: &http.Transport{MaxConnsPerHost: 10}}
+
+	client, profile := bidderClient("appnexus", nil, shared)
+	assert.Same(t, shared, client, "no transport client")
+	assert.Equal(t, config.DefaultTransportProfile, profile, "no transport profile")
+
+	client, profile = bidderClient("appnexus", &config.TransportProfile{MaxConnsPerHost: 100}, shared)
+	assert.NotSame(t, shared, client, "transport client")
+	assert.Equal(t, "appnexus", profile, "transport profile defaults to the bidder name")
+
+	reused, _ := bidderClient("appnexus", &config.TransportProfile{MaxConnsPerHost: 100}, shared)
+	assert.Same(t, client, reused, "client reused while the profile is unchanged")
+
+	changed, profile := bidderClient("appnexus", &config.TransportProfile{Name: "high-qps", MaxConnsPerHost: 200}, shared)
+	assert.NotSame(t, client, changed, "client rebuilt when the profile changes")
+	assert.Equal(t, "high-qps", profile, "transport profile name")
+
+	other, _ := bidderClient("rubicon", &config.TransportProfile{Name: "high-qps", MaxConnsPerHost: 200}, shared)
+	assert.NotSame(t, changed, other, "bidders have their own clients")
+}
+
+func TestNewTransportClient(t *testing.T) {
+	rootCAs := x509.NewCertPool()
+	shared := &http.Client{
+		Transport: &http.Transport{
+			MaxConnsPerHost:     10,
+			MaxIdleConns:        20,
+			MaxIdleConnsPerHost: 5,
+			IdleConnTimeout:     30 * time.Second,
+			TLSClientConfig:     &tls.Config{RootCAs: rootCAs},
+		},
+		Timeout: time.Second,
+	}
+
+	testCases := []struct {
+		description                 string
+		profile                     config.TransportProfile
+		expectedMaxConnsPerHost     int
+		expectedMaxIdleConns        int
+		expectedMaxIdleConnsPerHost int
+		expectedIdleConnTimeout     time.Duration
+		expectedTLSMinVersion       uint16
+		expectedHTTP2               bool
+	}{
+		{
+			description:                 "Shared Settings",
+			profile:                     config.TransportProfile{},
+			expectedMaxConnsPerHost:     10,
+			expectedMaxIdleConns:        20,
+			expectedMaxIdleConnsPerHost: 5,
+			expectedIdleConnTimeout:     30 * time.Second,
+		},
+		{
+			description: "Dedicated Settings",
+			profile: config.TransportProfile{
+				HTTP2:                  true,
+				MaxConnsPerHost:        100,
+				MaxIdleConns:           200,
+				MaxIdleConnsPerHost:    50,
+				IdleConnTimeoutSeconds: 90,
+				TLS:                    config.TransportTLS{MinVersion: config.TLSVersion13},
+			},
+			expectedMaxConnsPerHost:     100,
+			expectedMaxIdleConns:        200,
+			expectedMaxIdleConnsPerHost: 50,
+			expectedIdleConnTimeout:     90 * time.Second,
+			expectedTLSMinVersion:       tls.VersionTLS13,
+			expectedHTTP2:               true,
+		},
+	}
+
+	for _, test := range testCases {
+		client := newTransportClient(test.profile, shared)
+		transport, ok := client.Transport.(*http.Transport)
+		if !assert.True(t, ok, test.description) {
+			continue
+		}
+
+		assert.Equal(t, time.Second, client.Timeout, test.description+":timeout")
+		assert.Equal(t, test.expectedMaxConnsPerHost, transport.MaxConnsPerHost, test.description+":max_conns_per_host")
+		assert.Equal(t, test.expectedMaxIdleConns, transport.MaxIdleConns, test.description+":max_idle_conns")
+		assert.Equal(t, test.expectedMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost, test.description+":max_idle_conns_per_host")
+		assert.Equal(t, test.expectedIdleConnTimeout, transport.IdleConnTimeout, test.description+":idle_conn_timeout")
+		assert.Same(t, rootCAs, transport.TLSClientConfig.RootCAs, test.description+":root_cas")
+		assert.Equal(t, test.expectedTLSMinVersion, transport.TLSClientConfig.MinVersion, test.description+":tls_min_version")
+		assert.Equal(t, test.expectedHTTP2, transport.ForceAttemptHTTP2, test.description+":http2")
+	}
+
+	assert.Equal(t, 10, shared.Transport.(*http.Transport).MaxConnsPerHost, "shared transport unchanged")
+}
+
+func TestTransportClientHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	testCases := []struct {
+		description   string
+		profile       config.TransportProfile
+		expectedProto string
+	}{
+		{
+			description:   "HTTP/2",
+			profile:       config.TransportProfile{HTTP2: true},
+			expectedProto: "HTTP/2.0",
+		},
+		{
+			description:   "HTTP/1.1",
+			profile:       config.TransportProfile{},
+			expectedProto: "HTTP/1.1",
+		},
+	}
+
+	for _, test := range testCases {
+		client := newTransportClient(test.profile, server.Client())
+		resp, err := client.Get(server.URL)
+		if assert.NoError(t, err, test.description) {
+			assert.Equal(t, test.expectedProto, resp.Proto, test.description)
+			resp.Body.Close()
+		}
+	}
+}
+
+func TestAdaptBidderTransport(t *testing.T) {
+	shared := &http.Client{Transport: &http.Transport{}}
+
+	bidder := AdaptBidder(&goodSingleBidder{}, shared, &config.Configuration{}, nil, openrtb_ext.BidderName("fastBidder"), nil, "", &config.TransportProfile{Name: "fast", HTTP2: true}, nil, nil)
+	adapter := bidder.(*bidderAdapter)
+
+	assert.NotSame(t, shared, adapter.Client, "client")
+	assert.Equal(t, "fast", adapter.config.TransportProfile, "transport profile")
+}
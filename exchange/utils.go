@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/buger/jsonparser"
 	"github.com/prebid/go-gdpr/vendorconsent"
@@ -87,15 +88,8 @@ func cleanOpenRTBRequests(ctx context.Context,
 		}
 	}
 
-	gdprSignal, err := extractGDPR(req.BidRequest)
-	if err != nil {
-		errs = append(errs, err)
-	}
-
-	consent, err := extractConsent(req.BidRequest, gpp)
-	if err != nil {
-		errs = append(errs, err)
-	}
+	gdprSignal := extractGDPR(req.BidRequest)
+	consent := extractConsent(req.BidRequest, gpp)
 	gdprApplies := gdprSignal == gdpr.SignalYes || (gdprSignal == gdpr.SignalAmbiguous && gdprDefaultValue == gdpr.SignalYes)
 
 	ccpaEnforcer, err := extractCCPA(req.BidRequest, privacyConfig, &auctionReq.Account, aliases, channelTypeMap[auctionReq.LegacyLabels.RType], gpp)
@@ -143,8 +137,10 @@ func cleanOpenRTBRequests(ctx context.Context,
 	}
 
 	// bidder level privacy policies
+	timeline := TimelineFromContext(ctx)
 	for _, bidderRequest := range allBidderRequests {
 		bidRequestAllowed := true
+		privacyStart := time.Now()
 
 		// CCPA
 		privacyEnforcement.CCPA = ccpaEnforcer.ShouldEnforce(bidderRequest.BidderName.String())
@@ -175,6 +171,18 @@ func cleanOpenRTBRequests(ctx context.Context,
 			privacyEnforcement.Apply(bidderRequest.BidRequest)
 			allowedBidderRequests = append(allowedBidderRequests, bidderRequest)
 		}
+
+		if timeline.Enabled() {
+			timeline.Record(TraceStepPrivacy, bidderRequest.BidderName, privacyStart, map[string]interface{}{
+				"allowed": bidRequestAllowed,
+				"gdpr":    gdprEnforced,
+				"gdprgeo": gdprEnforced && privacyEnforcement.GDPRGeo,
+				"gdprid":  gdprEnforced && privacyEnforcement.GDPRID,
+				"ccpa":    privacyEnforcement.CCPA,
+				"coppa":   privacyEnforcement.COPPA,
+				"lmt":     privacyEnforcement.LMT,
+			})
+		}
 	}
 
 	return
@@ -252,10 +260,7 @@ func getAuctionBidderRequests(auctionRequest AuctionRequest,
 			return nil, []error{err}
 		}
 
-		if err := removeUnpermissionedEids(&reqCopy, bidder, requestExt); err != nil {
-			errs = append(errs, fmt.Errorf("unable to enforce request.ext.prebid.data.eidpermissions because %v", err))
-			continue
-		}
+		removeUnpermissionedEids(&reqCopy, bidder, requestExt)
 
 		bidderRequest := BidderRequest{
 			BidderName:     openrtb_ext.BidderName(bidder),
@@ -527,37 +532,16 @@ func copyWithBuyerUID(user *openrtb2.User, buyerUID string) *openrtb2.User {
 	return user
 }
 
-// removeUnpermissionedEids modifies the request to remove any request.user.ext.eids not permissions for the specific bidder
-func removeUnpermissionedEids(request *openrtb2.BidRequest, bidder string, requestExt *openrtb_ext.ExtRequest) error {
-	// ensure request might have eids (as much as we can check before unmarshalling)
-	if request.User == nil || len(request.User.Ext) == 0 {
-		return nil
+// removeUnpermissionedEids modifies the request to remove any request.user.eids not permissions for the specific bidder
+func removeUnpermissionedEids(request *openrtb2.BidRequest, bidder string, requestExt *openrtb_ext.ExtRequest) {
+	// exit early if there are no eids
+	if request.User == nil || len(request.User.EIDs) == 0 {
+		return
 	}
 
 	// ensure request has eid permissions to enforce
 	if requestExt == nil || requestExt.Prebid.Data == nil || len(requestExt.Prebid.Data.EidPermissions) == 0 {
-		return nil
-	}
-
-	// low level unmarshal to preserve other request.user.ext values. prebid server is non-destructive.
-	var userExt map[string]json.RawMessage
-	if err := json.Unmarshal(request.User.Ext, &userExt); err != nil {
-		return err
-	}
-
-	eidsJSON, eidsSpecified := userExt["eids"]
-	if !eidsSpecified {
-		return nil
-	}
-
-	var eids []openrtb2.EID
-	if err := json.Unmarshal(eidsJSON, &eids); err != nil {
-		return err
-	}
-
-	// exit early if there are no eids (empty array)
-	if len(eids) == 0 {
-		return nil
+		return
 	}
 
 	// translate eid permissions to a map for quick lookup
@@ -566,6 +550,7 @@ func removeUnpermissionedEids(request *openrtb2.BidRequest, bidder string, reque
 		eidRules[p.Source] = p.Bidders
 	}
 
+	eids := request.User.EIDs
 	eidsAllowed := make([]openrtb2.EID, 0, len(eids))
 	for _, eid := range eids {
 		allowed := false
@@ -587,37 +572,15 @@ func removeUnpermissionedEids(request *openrtb2.BidRequest, bidder string, reque
 
 	// exit early if all eids are allowed and nothing needs to be removed
 	if len(eids) == len(eidsAllowed) {
-		return nil
+		return
 	}
 
-	// marshal eidsAllowed back to userExt
+	userCopy := *request.User
 	if len(eidsAllowed) == 0 {
-		delete(userExt, "eids")
+		userCopy.EIDs = nil
 	} else {
-		eidsRaw, err := json.Marshal(eidsAllowed)
-		if err != nil {
-			return err
-		}
-		userExt["eids"] = eidsRaw
+		userCopy.EIDs = eidsAllowed
 	}
-
-	// exit early if userExt is empty
-	if len(userExt) == 0 {
-		setUserExtWithCopy(request, nil)
-		return nil
-	}
-
-	userExtJSON, err := json.Marshal(userExt)
-	if err != nil {
-		return err
-	}
-	setUserExtWithCopy(request, userExtJSON)
-	return nil
-}
-
-func setUserExtWithCopy(request *openrtb2.BidRequest, userExtJSON json.RawMessage) {
-	userCopy := *request.User
-	userCopy.Ext = userExtJSON
 	request.User = &userCopy
 }
 
@@ -843,6 +806,44 @@ func mergeBidderRequests(allBidderRequests []BidderRequest, bidderNameToBidderRe
 	return allBidderRequests
 }
 
+// convertBidderRequestsVersion renders each bidder request in the OpenRTB version declared by the bidder.
+// Requests are normalized to OpenRTB 2.6 during validation, so only bidders without 2.6 support are
+// converted down to the OpenRTB 2.5 extension locations.
+func convertBidderRequestsVersion(bidderRequests []BidderRequest, bidderInfos config.BidderInfos) []error {
+	var errs []error
+	for _, bidderRequest := range bidderRequests {
+		if bidderInfos[string(bidderRequest.BidderCoreName)].SupportsOpenRTB26() {
+			continue
+		}
+
+		req := bidderRequest.BidRequest
+
+		// regs, user and source may be shared with other bidder requests
+		if req.Regs != nil {
+			regsCopy := *req.Regs
+			req.Regs = &regsCopy
+		}
+		if req.User != nil {
+			userCopy := *req.User
+			req.User = &userCopy
+		}
+		if req.Source != nil {
+			sourceCopy := *req.Source
+			req.Source = &sourceCopy
+		}
+
+		reqWrapper := &openrtb_ext.RequestWrapper{BidRequest: req}
+		if err := openrtb_ext.ConvertDownTo25(reqWrapper); err != nil {
+			errs = append(errs, fmt.Errorf("unable to convert request to OpenRTB 2.5 for bidder %s: %v", bidderRequest.BidderName, err))
+			continue
+		}
+		if err := reqWrapper.RebuildRequest(); err != nil {
+			errs = append(errs, fmt.Errorf("unable to convert request to OpenRTB 2.5 for bidder %s: %v", bidderRequest.BidderName, err))
+		}
+	}
+	return errs
+}
+
 func WrapJSONInData(data []byte) []byte {
 	res := make([]byte, 0, len(data))
 	res = append(res, []byte(`{"data":`)...)
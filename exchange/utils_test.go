@@ -1146,58 +1146,50 @@ func TestCleanOpenRTBRequestsCOPPA(t *testing.T) {
 func TestCleanOpenRTBRequestsSChain(t *testing.T) {
 	const seller1SChain string = `"schain":{"complete":1,"nodes":[{"asi":"directseller1.com","sid":"00001","rid":"BidRequest1","hp":1}],"ver":"1.0"}`
 	const seller2SChain string = `"schain":{"complete":2,"nodes":[{"asi":"directseller2.com","sid":"00002","rid":"BidRequest2","hp":2}],"ver":"2.0"}`
+	seller1 := &openrtb2.SupplyChain{Complete: 1, Nodes: []openrtb2.SupplyChainNode{{ASI: "directseller1.com", SID: "00001", RID: "BidRequest1", HP: openrtb2.Int8Ptr(1)}}, Ver: "1.0"}
 
 	testCases := []struct {
-		description   string
-		inExt         json.RawMessage
-		inSourceExt   json.RawMessage
-		outRequestExt json.RawMessage
-		outSourceExt  json.RawMessage
-		hasError      bool
+		description    string
+		inExt          json.RawMessage
+		inSourceSChain *openrtb2.SupplyChain
+		outRequestExt  json.RawMessage
+		outSChain      *openrtb2.SupplyChain
+		hasError       bool
 	}{
 		{
-			description:   "source.ext is nil",
-			inExt:         json.RawMessage{},
-			inSourceExt:   nil,
-			outRequestExt: json.RawMessage{},
-			outSourceExt:  nil,
-		},
-		{
-			description:   "source.ext is defined with length 0",
-			inExt:         json.RawMessage{},
-			inSourceExt:   json.RawMessage{},
-			outRequestExt: json.RawMessage{},
-			outSourceExt:  json.RawMessage{},
+			description:    "source.schain is nil",
+			inExt:          json.RawMessage{},
+			inSourceSChain: nil,
+			outRequestExt:  json.RawMessage{},
+			outSChain:      nil,
 		},
 		{
-			description:   "ORTB 2.5 chain at source.ext.schain",
-			inExt:         json.RawMessage{},
-			inSourceExt:   json.RawMessage(`{` + seller1SChain + `}`),
-			outRequestExt: json.RawMessage{},
-			outSourceExt:  json.RawMessage(`{` + seller1SChain + `}`),
+			description:    "ORTB 2.6 schain at source.schain",
+			inExt:          json.RawMessage{},
+			inSourceSChain: seller1,
+			outRequestExt:  json.RawMessage{},
+			outSChain:      seller1,
 		},
 		{
-			description:   "ORTB 2.5 schain at request.ext.prebid.schains",
-			inExt:         json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `}]}}`),
-			inSourceExt:   json.RawMessage{},
-			outRequestExt: json.RawMessage(`{"prebid":{}}`),
-			outSourceExt:  json.RawMessage(`{` + seller1SChain + `}`),
+			description:    "ORTB 2.5 schain at request.ext.prebid.schains",
+			inExt:          json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `}]}}`),
+			inSourceSChain: nil,
+			outRequestExt:  json.RawMessage(`{"prebid":{}}`),
+			outSChain:      seller1,
 		},
 		{
-			description:   "schainwriter instantation error -- multiple bidder schains in ext.prebid.schains.",
-			inExt:         json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `},{"bidders":["appnexus"],` + seller2SChain + `}]}}`),
-			inSourceExt:   json.RawMessage(`{` + seller1SChain + `}`),
-			outRequestExt: nil,
-			outSourceExt:  nil,
-			hasError:      true,
+			description:    "schainwriter instantation error -- multiple bidder schains in ext.prebid.schains.",
+			inExt:          json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `},{"bidders":["appnexus"],` + seller2SChain + `}]}}`),
+			inSourceSChain: seller1,
+			outRequestExt:  nil,
+			outSChain:      nil,
+			hasError:       true,
 		},
 	}
 
 	for _, test := range testCases {
 		req := newBidRequest(t)
-		if test.inSourceExt != nil {
-			req.Source.Ext = test.inSourceExt
-		}
+		req.Source.SChain = test.inSourceSChain
 
 		var extRequest *openrtb_ext.ExtRequest
 		if test.inExt != nil {
@@ -1230,7 +1222,7 @@ func TestCleanOpenRTBRequestsSChain(t *testing.T) {
 		} else {
 			result := bidderRequests[0]
 			assert.Nil(t, errs)
-			assert.Equal(t, test.outSourceExt, result.BidRequest.Source.Ext, test.description+":Source.Ext")
+			assert.Equal(t, test.outSChain, result.BidRequest.Source.SChain, test.description+":Source.SChain")
 			assert.Equal(t, test.outRequestExt, result.BidRequest.Ext, test.description+":Ext")
 		}
 	}
@@ -2003,19 +1995,18 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 		description         string
 		gdprAccountEnabled  *bool
 		gdprHostEnabled     bool
-		gdpr                string
+		gdpr                *int8
 		gdprConsent         string
 		gdprScrub           bool
 		permissionsError    error
 		gdprDefaultValue    string
 		expectPrivacyLabels metrics.PrivacyLabels
-		expectError         bool
 	}{
 		{
 			description:        "Enforce - TCF Invalid",
 			gdprAccountEnabled: &trueValue,
 			gdprHostEnabled:    true,
-			gdpr:               "1",
+			gdpr:               openrtb2.Int8Ptr(1),
 			gdprConsent:        "malformed",
 			gdprScrub:          false,
 			gdprDefaultValue:   "1",
@@ -2028,7 +2019,7 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			description:        "Enforce",
 			gdprAccountEnabled: &trueValue,
 			gdprHostEnabled:    true,
-			gdpr:               "1",
+			gdpr:               openrtb2.Int8Ptr(1),
 			gdprConsent:        tcf2Consent,
 			gdprScrub:          true,
 			gdprDefaultValue:   "1",
@@ -2041,7 +2032,7 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			description:        "Not Enforce",
 			gdprAccountEnabled: &trueValue,
 			gdprHostEnabled:    true,
-			gdpr:               "0",
+			gdpr:               openrtb2.Int8Ptr(0),
 			gdprConsent:        tcf2Consent,
 			gdprScrub:          false,
 			gdprDefaultValue:   "1",
@@ -2050,25 +2041,11 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 				GDPRTCFVersion: "",
 			},
 		},
-		{
-			description:        "Enforce; GDPR signal extraction error",
-			gdprAccountEnabled: &trueValue,
-			gdprHostEnabled:    true,
-			gdpr:               "0{",
-			gdprConsent:        tcf2Consent,
-			gdprScrub:          true,
-			gdprDefaultValue:   "1",
-			expectPrivacyLabels: metrics.PrivacyLabels{
-				GDPREnforced:   true,
-				GDPRTCFVersion: metrics.TCFVersionV2,
-			},
-			expectError: true,
-		},
 		{
 			description:        "Enforce; account GDPR enabled, host GDPR setting disregarded",
 			gdprAccountEnabled: &trueValue,
 			gdprHostEnabled:    false,
-			gdpr:               "1",
+			gdpr:               openrtb2.Int8Ptr(1),
 			gdprConsent:        tcf2Consent,
 			gdprScrub:          true,
 			gdprDefaultValue:   "1",
@@ -2081,7 +2058,7 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			description:        "Not Enforce; account GDPR disabled, host GDPR setting disregarded",
 			gdprAccountEnabled: &falseValue,
 			gdprHostEnabled:    true,
-			gdpr:               "1",
+			gdpr:               openrtb2.Int8Ptr(1),
 			gdprConsent:        tcf2Consent,
 			gdprScrub:          false,
 			gdprDefaultValue:   "1",
@@ -2094,7 +2071,7 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			description:        "Enforce; account GDPR not specified, host GDPR enabled",
 			gdprAccountEnabled: nil,
 			gdprHostEnabled:    true,
-			gdpr:               "1",
+			gdpr:               openrtb2.Int8Ptr(1),
 			gdprConsent:        tcf2Consent,
 			gdprScrub:          true,
 			gdprDefaultValue:   "1",
@@ -2107,7 +2084,7 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			description:        "Not Enforce; account GDPR not specified, host GDPR disabled",
 			gdprAccountEnabled: nil,
 			gdprHostEnabled:    false,
-			gdpr:               "1",
+			gdpr:               openrtb2.Int8Ptr(1),
 			gdprConsent:        tcf2Consent,
 			gdprScrub:          false,
 			gdprDefaultValue:   "1",
@@ -2120,7 +2097,7 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			description:        "Enforce - Ambiguous signal, don't sync user if ambiguous",
 			gdprAccountEnabled: nil,
 			gdprHostEnabled:    true,
-			gdpr:               "null",
+			gdpr:               nil,
 			gdprConsent:        tcf2Consent,
 			gdprScrub:          true,
 			gdprDefaultValue:   "1",
@@ -2133,7 +2110,7 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			description:        "Not Enforce - Ambiguous signal, sync user if ambiguous",
 			gdprAccountEnabled: nil,
 			gdprHostEnabled:    true,
-			gdpr:               "null",
+			gdpr:               nil,
 			gdprConsent:        tcf2Consent,
 			gdprScrub:          false,
 			gdprDefaultValue:   "0",
@@ -2146,7 +2123,7 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			description:        "Enforce - error while checking if personal info is allowed",
 			gdprAccountEnabled: nil,
 			gdprHostEnabled:    true,
-			gdpr:               "1",
+			gdpr:               openrtb2.Int8Ptr(1),
 			gdprConsent:        tcf2Consent,
 			gdprScrub:          true,
 			permissionsError:   errors.New("Some error"),
@@ -2160,9 +2137,9 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 
 	for _, test := range testCases {
 		req := newBidRequest(t)
-		req.User.Ext = json.RawMessage(`{"consent":"` + test.gdprConsent + `"}`)
+		req.User.Consent = test.gdprConsent
 		req.Regs = &openrtb2.Regs{
-			Ext: json.RawMessage(`{"gdpr":` + test.gdpr + `}`),
+			GDPR: test.gdpr,
 		}
 
 		privacyConfig := config.Privacy{
@@ -2221,11 +2198,7 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			nil)
 		result := results[0]
 
-		if test.expectError {
-			assert.NotNil(t, errs)
-		} else {
-			assert.Nil(t, errs)
-		}
+		assert.Nil(t, errs)
 
 		if test.gdprScrub {
 			assert.Equal(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
@@ -2272,7 +2245,7 @@ func TestCleanOpenRTBRequestsGDPRBlockBidRequest(t *testing.T) {
 	for _, test := range testCases {
 		req := newBidRequest(t)
 		req.Regs = &openrtb2.Regs{
-			Ext: json.RawMessage(`{"gdpr":1}`),
+			GDPR: openrtb2.Int8Ptr(1),
 		}
 		req.Imp[0].Ext = json.RawMessage(`{"prebid":{"bidder":{"appnexus": {"placementId": 1}, "rubicon": {}}}}`)
 
@@ -2508,10 +2481,10 @@ func newAdapterAliasBidRequest(t *testing.T) *openrtb2.BidRequest {
 		User: &openrtb2.User{
 			ID:       "our-id",
 			BuyerUID: "their-id",
-			Ext:      json.RawMessage(`{"consent":"BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw"}`),
+			Consent:  "BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw",
 		},
 		Regs: &openrtb2.Regs{
-			Ext: json.RawMessage(`{"gdpr":1}`),
+			GDPR: openrtb2.Int8Ptr(1),
 		},
 		Imp: []openrtb2.Imp{{
 			ID: "some-imp-id",
@@ -2651,128 +2624,90 @@ func TestRandomizeList(t *testing.T) {
 
 func TestRemoveUnpermissionedEids(t *testing.T) {
 	bidder := "bidderA"
+	eid1 := openrtb2.EID{Source: "source1", UIDs: []openrtb2.UID{{ID: "anyID1"}}}
+	eid2 := openrtb2.EID{Source: "source2", UIDs: []openrtb2.UID{{ID: "anyID2"}}}
+	eid3 := openrtb2.EID{Source: "source3", UIDs: []openrtb2.UID{{ID: "anyID3"}}}
 
 	testCases := []struct {
-		description     string
-		userExt         json.RawMessage
-		eidPermissions  []openrtb_ext.ExtRequestPrebidDataEidPermission
-		expectedUserExt json.RawMessage
+		description    string
+		userEIDs       []openrtb2.EID
+		eidPermissions []openrtb_ext.ExtRequestPrebidDataEidPermission
+		expectedEIDs   []openrtb2.EID
 	}{
 		{
-			description: "Extension Nil",
-			userExt:     nil,
+			description: "Eids Nil",
+			userEIDs:    nil,
 			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
 				{Source: "source1", Bidders: []string{"bidderA"}},
 			},
-			expectedUserExt: nil,
-		},
-		{
-			description: "Extension Empty",
-			userExt:     json.RawMessage(`{}`),
-			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
-				{Source: "source1", Bidders: []string{"bidderA"}},
-			},
-			expectedUserExt: json.RawMessage(`{}`),
-		},
-		{
-			description: "Extension Empty - Keep Other Data",
-			userExt:     json.RawMessage(`{"other":42}`),
-			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
-				{Source: "source1", Bidders: []string{"bidderA"}},
-			},
-			expectedUserExt: json.RawMessage(`{"other":42}`),
+			expectedEIDs: nil,
 		},
 		{
 			description: "Eids Empty",
-			userExt:     json.RawMessage(`{"eids":[]}`),
+			userEIDs:    []openrtb2.EID{},
 			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
 				{Source: "source1", Bidders: []string{"bidderA"}},
 			},
-			expectedUserExt: json.RawMessage(`{"eids":[]}`),
+			expectedEIDs: []openrtb2.EID{},
 		},
 		{
-			description: "Eids Empty - Keep Other Data",
-			userExt:     json.RawMessage(`{"eids":[],"other":42}`),
-			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
-				{Source: "source1", Bidders: []string{"bidderA"}},
-			},
-			expectedUserExt: json.RawMessage(`{"eids":[],"other":42}`),
-		},
-		{
-			description:     "Allowed By Nil Permissions",
-			userExt:         json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
-			eidPermissions:  nil,
-			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
+			description:    "Allowed By Nil Permissions",
+			userEIDs:       []openrtb2.EID{eid1},
+			eidPermissions: nil,
+			expectedEIDs:   []openrtb2.EID{eid1},
 		},
 		{
-			description:     "Allowed By Empty Permissions",
-			userExt:         json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
-			eidPermissions:  []openrtb_ext.ExtRequestPrebidDataEidPermission{},
-			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
+			description:    "Allowed By Empty Permissions",
+			userEIDs:       []openrtb2.EID{eid1},
+			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{},
+			expectedEIDs:   []openrtb2.EID{eid1},
 		},
 		{
 			description: "Allowed By Specific Bidder",
-			userExt:     json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
+			userEIDs:    []openrtb2.EID{eid1},
 			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
 				{Source: "source1", Bidders: []string{"bidderA"}},
 			},
-			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
+			expectedEIDs: []openrtb2.EID{eid1},
 		},
 		{
 			description: "Allowed By All Bidders",
-			userExt:     json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
+			userEIDs:    []openrtb2.EID{eid1},
 			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
 				{Source: "source1", Bidders: []string{"*"}},
 			},
-			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
+			expectedEIDs: []openrtb2.EID{eid1},
 		},
 		{
 			description: "Allowed By Lack Of Matching Source",
-			userExt:     json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
+			userEIDs:    []openrtb2.EID{eid1},
 			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
 				{Source: "source2", Bidders: []string{"otherBidder"}},
 			},
-			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
-		},
-		{
-			description: "Allowed - Keep Other Data",
-			userExt:     json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}],"other":42}`),
-			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
-				{Source: "source1", Bidders: []string{"bidderA"}},
-			},
-			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}],"other":42}`),
+			expectedEIDs: []openrtb2.EID{eid1},
 		},
 		{
 			description: "Denied",
-			userExt:     json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`),
+			userEIDs:    []openrtb2.EID{eid1},
 			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
 				{Source: "source1", Bidders: []string{"otherBidder"}},
 			},
-			expectedUserExt: nil,
+			expectedEIDs: nil,
 		},
 		{
-			description: "Denied - Keep Other Data",
-			userExt:     json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID"}]}],"otherdata":42}`),
-			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
-				{Source: "source1", Bidders: []string{"otherBidder"}},
-			},
-			expectedUserExt: json.RawMessage(`{"otherdata":42}`),
-		},
-		{
-			description: "Mix Of Allowed By Specific Bidder, Allowed By Lack Of Matching Source, Denied, Keep Other Data",
-			userExt:     json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID1"}]},{"source":"source2","uids":[{"id":"anyID2"}]},{"source":"source3","uids":[{"id":"anyID3"}]}],"other":42}`),
+			description: "Mix Of Allowed By Specific Bidder, Allowed By Lack Of Matching Source, Denied",
+			userEIDs:    []openrtb2.EID{eid1, eid2, eid3},
 			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
 				{Source: "source1", Bidders: []string{"bidderA"}},
 				{Source: "source3", Bidders: []string{"otherBidder"}},
 			},
-			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","uids":[{"id":"anyID1"}]},{"source":"source2","uids":[{"id":"anyID2"}]}],"other":42}`),
+			expectedEIDs: []openrtb2.EID{eid1, eid2},
 		},
 	}
 
 	for _, test := range testCases {
-		request := &openrtb2.BidRequest{
-			User: &openrtb2.User{Ext: test.userExt},
-		}
+		user := &openrtb2.User{EIDs: test.userEIDs, Ext: json.RawMessage(`{"other":42}`)}
+		request := &openrtb2.BidRequest{User: user}
 
 		requestExt := &openrtb_ext.ExtRequest{
 			Prebid: openrtb_ext.ExtRequestPrebid{
@@ -2783,55 +2718,12 @@ func TestRemoveUnpermissionedEids(t *testing.T) {
 		}
 
 		expectedRequest := &openrtb2.BidRequest{
-			User: &openrtb2.User{Ext: test.expectedUserExt},
+			User: &openrtb2.User{EIDs: test.expectedEIDs, Ext: json.RawMessage(`{"other":42}`)},
 		}
 
-		resultErr := removeUnpermissionedEids(request, bidder, requestExt)
-		assert.NoError(t, resultErr, test.description)
+		removeUnpermissionedEids(request, bidder, requestExt)
 		assert.Equal(t, expectedRequest, request, test.description)
-	}
-}
-
-func TestRemoveUnpermissionedEidsUnmarshalErrors(t *testing.T) {
-	testCases := []struct {
-		description string
-		userExt     json.RawMessage
-		expectedErr string
-	}{
-		{
-			description: "Malformed Ext",
-			userExt:     json.RawMessage(`malformed`),
-			expectedErr: "invalid character 'm' looking for beginning of value",
-		},
-		{
-			description: "Malformed Eid Array Type",
-			userExt:     json.RawMessage(`{"eids":[42]}`),
-			expectedErr: "json: cannot unmarshal number into Go value of type openrtb2.EID",
-		},
-		{
-			description: "Malformed Eid Item Type",
-			userExt:     json.RawMessage(`{"eids":[{"source":42,"id":"anyID"}]}`),
-			expectedErr: "json: cannot unmarshal number into Go struct field EID.source of type string",
-		},
-	}
-
-	for _, test := range testCases {
-		request := &openrtb2.BidRequest{
-			User: &openrtb2.User{Ext: test.userExt},
-		}
-
-		requestExt := &openrtb_ext.ExtRequest{
-			Prebid: openrtb_ext.ExtRequestPrebid{
-				Data: &openrtb_ext.ExtRequestPrebidData{
-					EidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
-						{Source: "source1", Bidders: []string{"*"}},
-					},
-				},
-			},
-		}
-
-		resultErr := removeUnpermissionedEids(request, "bidderA", requestExt)
-		assert.EqualError(t, resultErr, test.expectedErr, test.description)
+		assert.Equal(t, test.userEIDs, user.EIDs, test.description+":shared user must not be modified")
 	}
 }
 
@@ -2986,14 +2878,14 @@ func TestRemoveUnpermissionedEidsEmptyValidations(t *testing.T) {
 		{
 			description: "Nil Ext",
 			request: &openrtb2.BidRequest{
-				User: &openrtb2.User{Ext: json.RawMessage(`{"eids":[{"source":"source1","id":"anyID"}]}`)},
+				User: &openrtb2.User{EIDs: []openrtb2.EID{{Source: "source1", UIDs: []openrtb2.UID{{ID: "anyID"}}}}},
 			},
 			requestExt: nil,
 		},
 		{
 			description: "Nil Prebid Data",
 			request: &openrtb2.BidRequest{
-				User: &openrtb2.User{Ext: json.RawMessage(`{"eids":[{"source":"source1","id":"anyID"}]}`)},
+				User: &openrtb2.User{EIDs: []openrtb2.EID{{Source: "source1", UIDs: []openrtb2.UID{{ID: "anyID"}}}}},
 			},
 			requestExt: &openrtb_ext.ExtRequest{
 				Prebid: openrtb_ext.ExtRequestPrebid{
@@ -3006,8 +2898,7 @@ func TestRemoveUnpermissionedEidsEmptyValidations(t *testing.T) {
 	for _, test := range testCases {
 		requestExpected := *test.request
 
-		resultErr := removeUnpermissionedEids(test.request, "bidderA", test.requestExt)
-		assert.NoError(t, resultErr, test.description+":err")
+		removeUnpermissionedEids(test.request, "bidderA", test.requestExt)
 		assert.Equal(t, &requestExpected, test.request, test.description+":request")
 	}
 }
@@ -3052,15 +2943,15 @@ func TestCleanOpenRTBRequestsSChainMultipleBidders(t *testing.T) {
 	assert.Nil(t, errs)
 	assert.Len(t, bidderRequests, 2, "Bid request count is not 2")
 
-	bidRequestSourceExts := map[openrtb_ext.BidderName]json.RawMessage{}
+	bidRequestSChains := map[openrtb_ext.BidderName]*openrtb2.SupplyChain{}
 	for _, bidderRequest := range bidderRequests {
-		bidRequestSourceExts[bidderRequest.BidderName] = bidderRequest.BidRequest.Source.Ext
+		bidRequestSChains[bidderRequest.BidderName] = bidderRequest.BidRequest.Source.SChain
 	}
 
-	appnexusPrebidSchainsSchain := json.RawMessage(`{"schain":{"complete":1,"nodes":[{"asi":"directseller1.com","sid":"00001","rid":"BidRequest1","hp":1}],"ver":"1.0"}}`)
-	axonixPrebidSchainsSchain := json.RawMessage(`{"schain":{"complete":1,"nodes":[{"asi":"directseller2.com","sid":"00002","rid":"BidRequest2","hp":1}],"ver":"1.0"}}`)
-	assert.Equal(t, appnexusPrebidSchainsSchain, bidRequestSourceExts["appnexus"], "Incorrect appnexus bid request schain in source.ext")
-	assert.Equal(t, axonixPrebidSchainsSchain, bidRequestSourceExts["axonix"], "Incorrect axonix bid request schain in source.ext")
+	appnexusPrebidSchainsSchain := &openrtb2.SupplyChain{Complete: 1, Nodes: []openrtb2.SupplyChainNode{{ASI: "directseller1.com", SID: "00001", RID: "BidRequest1", HP: openrtb2.Int8Ptr(1)}}, Ver: "1.0"}
+	axonixPrebidSchainsSchain := &openrtb2.SupplyChain{Complete: 1, Nodes: []openrtb2.SupplyChainNode{{ASI: "directseller2.com", SID: "00002", RID: "BidRequest2", HP: openrtb2.Int8Ptr(1)}}, Ver: "1.0"}
+	assert.Equal(t, appnexusPrebidSchainsSchain, bidRequestSChains["appnexus"], "Incorrect appnexus bid request schain in source.schain")
+	assert.Equal(t, axonixPrebidSchainsSchain, bidRequestSChains["axonix"], "Incorrect axonix bid request schain in source.schain")
 }
 
 func TestApplyFPD(t *testing.T) {
@@ -3408,3 +3299,39 @@ func TestCleanOpenRTBRequestsFilterBidderRequestExt(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertBidderRequestsVersion(t *testing.T) {
+	regs := &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(1), USPrivacy: "1YYY"}
+	user := &openrtb2.User{Consent: "anyConsent", EIDs: []openrtb2.EID{{Source: "source1", UIDs: []openrtb2.UID{{ID: "anyID"}}}}}
+	source := &openrtb2.Source{SChain: &openrtb2.SupplyChain{Complete: 1, Ver: "1.0"}}
+
+	newRequest := func() *openrtb2.BidRequest {
+		return &openrtb2.BidRequest{ID: "anyID", Regs: regs, User: user, Source: source}
+	}
+	bidderRequests := []BidderRequest{
+		{BidderName: "ortb25", BidderCoreName: "ortb25", BidRequest: newRequest()},
+		{BidderName: "ortb26", BidderCoreName: "ortb26", BidRequest: newRequest()},
+	}
+	bidderInfos := config.BidderInfos{
+		"ortb25": {},
+		"ortb26": {OpenRTB: &config.OpenRTBInfo{Version: config.OpenRTBVersion26}},
+	}
+
+	errs := convertBidderRequestsVersion(bidderRequests, bidderInfos)
+	assert.Empty(t, errs)
+
+	ortb25 := bidderRequests[0].BidRequest
+	assert.Nil(t, ortb25.Regs.GDPR)
+	assert.Empty(t, ortb25.Regs.USPrivacy)
+	assert.JSONEq(t, `{"gdpr":1,"us_privacy":"1YYY"}`, string(ortb25.Regs.Ext))
+	assert.Empty(t, ortb25.User.Consent)
+	assert.Nil(t, ortb25.User.EIDs)
+	assert.JSONEq(t, `{"consent":"anyConsent","eids":[{"source":"source1","uids":[{"id":"anyID"}]}]}`, string(ortb25.User.Ext))
+	assert.Nil(t, ortb25.Source.SChain)
+	assert.JSONEq(t, `{"schain":{"complete":1,"nodes":null,"ver":"1.0"}}`, string(ortb25.Source.Ext))
+
+	assert.Equal(t, newRequest(), bidderRequests[1].BidRequest, "bidders supporting 2.6 get the request as is")
+	assert.Equal(t, int8(1), *regs.GDPR, "shared regs must not be modified")
+	assert.Equal(t, "anyConsent", user.Consent, "shared user must not be modified")
+	assert.NotNil(t, source.SChain, "shared source must not be modified")
+}
@@ -5,7 +5,7 @@ go 1.19
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.0
 	github.com/IABTechLab/adscert v0.34.0
-	github.com/NYTimes/gziphandler v1.1.1
+	github.com/andybalholm/brotli v1.0.5
 	github.com/asaskevich/govalidator v0.0.0-20210307081110-f21760c49a8d
 	github.com/benbjohnson/clock v1.3.0
 	github.com/buger/jsonparser v1.1.1
@@ -16,8 +16,10 @@ require (
 	github.com/gofrs/uuid v4.2.0+incompatible
 	github.com/golang/glog v1.0.0
 	github.com/julienschmidt/httprouter v1.3.0
+	github.com/klauspost/compress v1.15.15
 	github.com/lib/pq v1.10.4
 	github.com/mitchellh/copystructure v1.2.0
+	github.com/oschwald/maxminddb-golang v1.10.0
 	github.com/prebid/go-gdpr v1.11.0
 	github.com/prebid/go-gpp v0.1.1
 	github.com/prebid/openrtb/v17 v17.1.0
@@ -3,9 +3,9 @@ package hookexecution
 import (
 	"sync"
 
-	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/logger"
 )
 
 // executionContext holds information passed to module's hook during hook execution.
@@ -15,10 +15,11 @@ type executionContext struct {
 	accountId      string
 	account        *config.Account
 	moduleContexts *moduleContexts
+	logger         *logger.Logger
 }
 
 func (ctx executionContext) getModuleContext(moduleName string) hookstage.ModuleInvocationContext {
-	moduleInvocationCtx := hookstage.ModuleInvocationContext{Endpoint: ctx.endpoint}
+	moduleInvocationCtx := hookstage.ModuleInvocationContext{AccountID: ctx.accountId, Endpoint: ctx.endpoint}
 	if ctx.moduleContexts != nil {
 		if mc, ok := ctx.moduleContexts.get(moduleName); ok {
 			moduleInvocationCtx.ModuleContext = mc
@@ -28,10 +29,11 @@ func (ctx executionContext) getModuleContext(moduleName string) hookstage.Module
 	if ctx.account != nil {
 		cfg, err := ctx.account.Hooks.Modules.ModuleConfig(moduleName)
 		if err != nil {
-			glog.Warningf("Failed to get account config for %s module: %s", moduleName, err)
+			ctx.logger.Warnf("Failed to get account config for %s module: %s", moduleName, err)
 		}
 
 		moduleInvocationCtx.AccountConfig = cfg
+		moduleInvocationCtx.AccountGDPR = ctx.account.GDPR
 	}
 
 	return moduleInvocationCtx
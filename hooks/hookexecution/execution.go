@@ -9,6 +9,7 @@ import (
 
 	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/metrics"
 )
 
@@ -63,13 +64,15 @@ func executeGroup[H any, P any](
 	var wg sync.WaitGroup
 	rejected := make(chan struct{})
 	resp := make(chan hookResponse[P])
+	// The hooks log with the fields of the request through the logger carried by their context.
+	ctx := logger.NewContext(context.Background(), executionCtx.logger)
 
 	for _, hook := range group.Hooks {
 		mCtx := executionCtx.getModuleContext(hook.Module)
 		wg.Add(1)
 		go func(hw hooks.HookWrapper[H], moduleCtx hookstage.ModuleInvocationContext) {
 			defer wg.Done()
-			executeHook(moduleCtx, hw, payload, hookHandler, group.Timeout, resp, rejected)
+			executeHook(ctx, moduleCtx, hw, payload, hookHandler, group.Timeout, resp, rejected)
 		}(hook, mCtx)
 	}
 
@@ -84,6 +87,7 @@ func executeGroup[H any, P any](
 }
 
 func executeHook[H any, P any](
+	parent context.Context,
 	moduleCtx hookstage.ModuleInvocationContext,
 	hw hooks.HookWrapper[H],
 	payload P,
@@ -97,7 +101,7 @@ func executeHook[H any, P any](
 	hookId := HookID{ModuleCode: hw.Module, HookImplCode: hw.Code}
 
 	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		ctx, cancel := context.WithTimeout(parent, timeout)
 		defer cancel()
 		result, err := hookHandler(ctx, moduleCtx, hw.Hook, payload)
 		hookRespCh <- hookResponse[P]{
@@ -11,6 +11,7 @@ import (
 	"github.com/prebid/prebid-server/exchange/entities"
 	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
@@ -43,6 +44,7 @@ type StageExecutor interface {
 type HookStageExecutor interface {
 	StageExecutor
 	SetAccount(account *config.Account)
+	SetLogger(l *logger.Logger)
 	GetOutcomes() []StageOutcome
 }
 
@@ -54,6 +56,7 @@ type hookExecutor struct {
 	stageOutcomes  []StageOutcome
 	moduleContexts *moduleContexts
 	metricEngine   metrics.MetricsEngine
+	logger         *logger.Logger
 	// Mutex needed for BidderRequest and RawBidderResponse Stages as they are run in several goroutines
 	sync.Mutex
 }
@@ -77,6 +80,11 @@ func (e *hookExecutor) SetAccount(account *config.Account) {
 	e.accountID = account.ID
 }
 
+// SetLogger sets the logger of the request, given to the hooks through their context.
+func (e *hookExecutor) SetLogger(l *logger.Logger) {
+	e.logger = l
+}
+
 func (e *hookExecutor) GetOutcomes() []StageOutcome {
 	return e.stageOutcomes
 }
@@ -286,7 +294,16 @@ func (e *hookExecutor) newContext(stage string) executionContext {
 		endpoint:       e.endpoint,
 		moduleContexts: e.moduleContexts,
 		stage:          stage,
+		logger:         e.stageLogger(),
+	}
+}
+
+// stageLogger returns the logger of the request, with the account once it is known.
+func (e *hookExecutor) stageLogger() *logger.Logger {
+	if e.accountID == "" {
+		return e.logger
 	}
+	return e.logger.WithAccount(e.accountID)
 }
 
 func (e *hookExecutor) saveModuleContexts(ctxs stageModuleContext) {
@@ -307,6 +324,8 @@ type EmptyHookExecutor struct{}
 
 func (executor *EmptyHookExecutor) SetAccount(_ *config.Account) {}
 
+func (executor *EmptyHookExecutor) SetLogger(_ *logger.Logger) {}
+
 func (executor *EmptyHookExecutor) GetOutcomes() []StageOutcome {
 	return []StageOutcome{}
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/hooks/hookanalytics"
 	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
@@ -1935,6 +1936,25 @@ func TestExecuteAuctionResponseStage(t *testing.T) {
 	}
 }
 
+func TestHooksGetRequestLogger(t *testing.T) {
+	fields := make(chan logger.Fields, 1)
+	exec := NewHookExecutor(TestWithLoggerPlanBuilder{fields: fields}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	req, err := http.NewRequest(http.MethodPost, "https://prebid.com/openrtb2/auction", nil)
+	assert.NoError(t, err)
+
+	l := logger.New(EndpointAuction, req)
+	exec.SetLogger(l)
+
+	_, reject := exec.ExecuteEntrypointStage(req, []byte(`{}`))
+	assert.Nil(t, reject, "Unexpected reject from entrypoint stage.")
+	assert.Equal(t, l.Fields(), <-fields, "Wrong logger fields at entrypoint stage.")
+
+	exec.SetAccount(&config.Account{ID: "some-account"})
+	_, reject = exec.ExecuteRawAuctionStage([]byte(`{}`))
+	assert.Nil(t, reject, "Unexpected reject from raw-auction stage.")
+	assert.Equal(t, l.WithAccount("some-account").Fields(), <-fields, "Wrong logger fields at raw-auction stage.")
+}
+
 func TestInterStageContextCommunication(t *testing.T) {
 	body := []byte(`{"foo": "bar"}`)
 	reader := bytes.NewReader(body)
@@ -2440,6 +2460,33 @@ func (e TestWithTimeoutPlanBuilder) PlanForAuctionResponseStage(_ string, _ *con
 	}
 }
 
+type TestWithLoggerPlanBuilder struct {
+	hooks.EmptyPlanBuilder
+	fields chan<- logger.Fields
+}
+
+func (e TestWithLoggerPlanBuilder) PlanForEntrypointStage(_ string) hooks.Plan[hookstage.Entrypoint] {
+	return hooks.Plan[hookstage.Entrypoint]{
+		hooks.Group[hookstage.Entrypoint]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.Entrypoint]{
+				{Module: "module-1", Code: "foo", Hook: mockLoggerHook{fields: e.fields}},
+			},
+		},
+	}
+}
+
+func (e TestWithLoggerPlanBuilder) PlanForRawAuctionStage(_ string, _ *config.Account) hooks.Plan[hookstage.RawAuctionRequest] {
+	return hooks.Plan[hookstage.RawAuctionRequest]{
+		hooks.Group[hookstage.RawAuctionRequest]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.RawAuctionRequest]{
+				{Module: "module-1", Code: "foo", Hook: mockLoggerHook{fields: e.fields}},
+			},
+		},
+	}
+}
+
 type TestWithModuleContextsPlanBuilder struct {
 	hooks.EmptyPlanBuilder
 }
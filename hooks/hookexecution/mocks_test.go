@@ -6,9 +6,25 @@ import (
 	"time"
 
 	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
+// mockLoggerHook sends the fields of the logger given to the hook through its context.
+type mockLoggerHook struct {
+	fields chan<- logger.Fields
+}
+
+func (e mockLoggerHook) HandleEntrypointHook(ctx context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.EntrypointPayload) (hookstage.HookResult[hookstage.EntrypointPayload], error) {
+	e.fields <- logger.FromContext(ctx).Fields()
+	return hookstage.HookResult[hookstage.EntrypointPayload]{}, nil
+}
+
+func (e mockLoggerHook) HandleRawAuctionHook(ctx context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.RawAuctionRequestPayload) (hookstage.HookResult[hookstage.RawAuctionRequestPayload], error) {
+	e.fields <- logger.FromContext(ctx).Fields()
+	return hookstage.HookResult[hookstage.RawAuctionRequestPayload]{}, nil
+}
+
 type mockUpdateHeaderEntrypointHook struct{}
 
 func (e mockUpdateHeaderEntrypointHook) HandleEntrypointHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.EntrypointPayload) (hookstage.HookResult[hookstage.EntrypointPayload], error) {
@@ -3,6 +3,7 @@ package hookstage
 import (
 	"encoding/json"
 
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/hooks/hookanalytics"
 )
 
@@ -21,8 +22,12 @@ type HookResult[T any] struct {
 
 // ModuleInvocationContext holds data passed to the module hook during invocation.
 type ModuleInvocationContext struct {
+	// AccountID is the ID of the account the request belongs to, empty if not yet known.
+	AccountID string
 	// AccountConfig represents module config rewritten at the account-level.
 	AccountConfig json.RawMessage
+	// AccountGDPR holds the GDPR config of the account, for the modules checking the consent of the user.
+	AccountGDPR config.AccountGDPR
 	// Endpoint represents the path of the current endpoint.
 	Endpoint string
 	// ModuleContext holds values that the module passes to itself from the previous stages.
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// The formats of the log entries.
+const (
+	FormatGlog = "glog"
+	FormatJSON = "json"
+)
+
+// NewBackend returns the backend writing the entries in the format: through glog, or as JSON lines to w.
+func NewBackend(format string, w io.Writer) (Backend, error) {
+	switch format {
+	case FormatGlog, "":
+		return glogBackend{}, nil
+	case FormatJSON:
+		return &jsonBackend{w: w}, nil
+	}
+	return nil, fmt.Errorf("unknown log format %s. Must be one of %s or %s", format, FormatGlog, FormatJSON)
+}
+
+// glogDepth skips the frames of the logger, so glog reports the file and line of the caller.
+const glogDepth = 3
+
+// glogBackend writes the entries through glog, with the request fields appended to the message.
+type glogBackend struct{}
+
+func (glogBackend) Write(entry Entry) {
+	msg := entry.Message
+	if fields := formatFields(entry.Fields); fields != "" {
+		msg += " " + fields
+	}
+
+	switch entry.Level {
+	case LevelError:
+		glog.ErrorDepth(glogDepth, msg)
+	case LevelWarn:
+		glog.WarningDepth(glogDepth, msg)
+	default:
+		glog.InfoDepth(glogDepth, msg)
+	}
+}
+
+func formatFields(fields Fields) string {
+	var parts []string
+	if fields.RequestID != "" {
+		parts = append(parts, "request_id="+fields.RequestID)
+	}
+	if fields.IncomingRequestID != "" {
+		parts = append(parts, "incoming_request_id="+fields.IncomingRequestID)
+	}
+	if fields.Endpoint != "" {
+		parts = append(parts, "endpoint="+fields.Endpoint)
+	}
+	if fields.Account != "" {
+		parts = append(parts, "account="+fields.Account)
+	}
+	return strings.Join(parts, " ")
+}
+
+// jsonBackend writes each entry as a line of JSON.
+type jsonBackend struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+type jsonEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"msg"`
+	Fields
+}
+
+func (b *jsonBackend) Write(entry Entry) {
+	line, err := json.Marshal(jsonEntry{
+		Time:    entry.Time.UTC().Format(time.RFC3339Nano),
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Fields:  entry.Fields,
+	})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.w.Write(line)
+}
@@ -0,0 +1,200 @@
+// Package logger writes the application logs with the fields of the request being served, so the entries
+// logged while handling one auction can be tied together.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-server/util/uuidutil"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level named debug, info, warn or error. An empty name is info.
+func ParseLevel(name string) (Level, error) {
+	if name == "" {
+		return LevelInfo, nil
+	}
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %s. Must be one of %v", name, levelNames)
+}
+
+// RequestIDHeader is the header carrying the ID given to a request by the caller, logged along with the
+// ID Prebid Server gives it.
+const RequestIDHeader = "X-Request-Id"
+
+// Fields identify the request a log entry was written for.
+type Fields struct {
+	RequestID         string `json:"request_id,omitempty"`
+	IncomingRequestID string `json:"incoming_request_id,omitempty"`
+	Endpoint          string `json:"endpoint,omitempty"`
+	Account           string `json:"account,omitempty"`
+}
+
+// Entry is a log entry written by a backend.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  Fields
+}
+
+// Backend writes the log entries.
+type Backend interface {
+	Write(entry Entry)
+}
+
+// Factory creates the loggers of the requests. It sets the backend the entries are written to, the lowest
+// level written and the fraction of the requests whose debug and info entries are written. Warnings and
+// errors are always written.
+type Factory struct {
+	backend    Backend
+	minLevel   Level
+	sampleRate float64
+	randFloat  func() float64
+	uuidGen    uuidutil.UUIDGenerator
+}
+
+func NewFactory(b Backend, level Level, rate float64) *Factory {
+	return &Factory{
+		backend:    b,
+		minLevel:   level,
+		sampleRate: rate,
+		randFloat:  rand.Float64,
+		uuidGen:    uuidutil.UUIDRandomGenerator{},
+	}
+}
+
+// defaultFactory writes the entries of info and above through glog. It's used by the nil Factory and the
+// nil Logger.
+var defaultFactory = NewFactory(glogBackend{}, LevelInfo, 1)
+
+// New returns the logger of a request to the endpoint, with a new request ID, written through glog.
+func New(endpoint string, r *http.Request) *Logger {
+	return defaultFactory.New(endpoint, r)
+}
+
+// Logger writes the log entries of a request. A nil Logger writes entries without request fields
+// through glog.
+type Logger struct {
+	factory *Factory
+	fields  Fields
+	sampled bool
+}
+
+// New returns the logger of a request to the endpoint, with a new request ID. The nil Factory returns
+// loggers writing through glog.
+func (f *Factory) New(endpoint string, r *http.Request) *Logger {
+	if f == nil {
+		f = defaultFactory
+	}
+	requestID, _ := f.uuidGen.Generate()
+	l := &Logger{
+		factory: f,
+		fields: Fields{
+			RequestID: requestID,
+			Endpoint:  endpoint,
+		},
+		sampled: f.sampleRate >= 1 || f.randFloat() < f.sampleRate,
+	}
+	if r != nil {
+		l.fields.IncomingRequestID = r.Header.Get(RequestIDHeader)
+	}
+	return l
+}
+
+// WithAccount returns a copy of the logger with the account of the request.
+func (l *Logger) WithAccount(account string) *Logger {
+	if l == nil {
+		return &Logger{factory: defaultFactory, fields: Fields{Account: account}, sampled: true}
+	}
+	withAccount := *l
+	withAccount.fields.Account = account
+	return &withAccount
+}
+
+// Fields returns the request fields added to the entries.
+func (l *Logger) Fields() Fields {
+	if l == nil {
+		return Fields{}
+	}
+	return l.fields
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.log(LevelDebug, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log(LevelInfo, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.log(LevelWarn, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log(LevelError, format, args...)
+}
+
+func (l *Logger) log(level Level, format string, args ...interface{}) {
+	factory := defaultFactory
+	if l != nil {
+		factory = l.factory
+	}
+	if level < factory.minLevel {
+		return
+	}
+	if level < LevelWarn && l != nil && !l.sampled {
+		return
+	}
+	factory.backend.Write(Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+		Fields:  l.Fields(),
+	})
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of the context carrying the logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by the context, or a nil Logger writing entries without request
+// fields if there is none.
+func FromContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return nil
+	}
+	l, _ := ctx.Value(contextKey{}).(*Logger)
+	return l
+}
@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type capturingBackend struct {
+	entries []Entry
+}
+
+func (b *capturingBackend) Write(entry Entry) {
+	b.entries = append(b.entries, entry)
+}
+
+type fakeUUIDGenerator struct {
+	id  string
+	err error
+}
+
+func (f fakeUUIDGenerator) Generate() (string, error) {
+	return f.id, f.err
+}
+
+// newFactoryForTest returns a factory with a fixed request ID and random number.
+func newFactoryForTest(b Backend, level Level, rate float64, random float64) *Factory {
+	f := NewFactory(b, level, rate)
+	f.randFloat = func() float64 { return random }
+	f.uuidGen = fakeUUIDGenerator{id: "some-request-id"}
+	return f
+}
+
+func TestParseLevel(t *testing.T) {
+	testCases := []struct {
+		name          string
+		expectedLevel Level
+		expectedError error
+	}{
+		{name: "", expectedLevel: LevelInfo},
+		{name: "debug", expectedLevel: LevelDebug},
+		{name: "info", expectedLevel: LevelInfo},
+		{name: "WARN", expectedLevel: LevelWarn},
+		{name: "error", expectedLevel: LevelError},
+		{name: "fatal", expectedLevel: LevelInfo, expectedError: errors.New("unknown log level fatal. Must be one of [debug info warn error]")},
+	}
+
+	for _, test := range testCases {
+		level, err := ParseLevel(test.name)
+		assert.Equal(t, test.expectedLevel, level, test.name)
+		assert.Equal(t, test.expectedError, err, test.name)
+	}
+}
+
+func TestNew(t *testing.T) {
+	f := newFactoryForTest(&capturingBackend{}, LevelInfo, 1, 0)
+
+	req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+	req.Header.Set(RequestIDHeader, "some-incoming-id")
+
+	l := f.New("/openrtb2/auction", req)
+	assert.Equal(t, Fields{RequestID: "some-request-id", IncomingRequestID: "some-incoming-id", Endpoint: "/openrtb2/auction"}, l.Fields(), "fields")
+
+	withAccount := l.WithAccount("some-account")
+	assert.Equal(t, Fields{RequestID: "some-request-id", IncomingRequestID: "some-incoming-id", Endpoint: "/openrtb2/auction", Account: "some-account"}, withAccount.Fields(), "fields with account")
+	assert.Equal(t, "", l.Fields().Account, "original logger unchanged")
+
+	var nilLogger *Logger
+	assert.Equal(t, Fields{}, nilLogger.Fields(), "nil logger fields")
+	assert.Equal(t, Fields{Account: "some-account"}, nilLogger.WithAccount("some-account").Fields(), "nil logger with account")
+
+	var nilFactory *Factory
+	assert.Equal(t, "/openrtb2/auction", nilFactory.New("/openrtb2/auction", req).Fields().Endpoint, "nil factory")
+	assert.Same(t, defaultFactory, nilFactory.New("/openrtb2/auction", req).factory, "nil factory default")
+}
+
+func TestLevelsAndSampling(t *testing.T) {
+	testCases := []struct {
+		description    string
+		level          Level
+		sampleRate     float64
+		random         float64
+		expectedLevels []Level
+	}{
+		{
+			description:    "All Levels",
+			level:          LevelDebug,
+			sampleRate:     1,
+			expectedLevels: []Level{LevelDebug, LevelInfo, LevelWarn, LevelError},
+		},
+		{
+			description:    "Warnings And Errors",
+			level:          LevelWarn,
+			sampleRate:     1,
+			expectedLevels: []Level{LevelWarn, LevelError},
+		},
+		{
+			description:    "Request Sampled",
+			level:          LevelDebug,
+			sampleRate:     0.5,
+			random:         0.2,
+			expectedLevels: []Level{LevelDebug, LevelInfo, LevelWarn, LevelError},
+		},
+		{
+			description:    "Request Not Sampled",
+			level:          LevelDebug,
+			sampleRate:     0.5,
+			random:         0.7,
+			expectedLevels: []Level{LevelWarn, LevelError},
+		},
+	}
+
+	for _, test := range testCases {
+		b := &capturingBackend{}
+		f := newFactoryForTest(b, test.level, test.sampleRate, test.random)
+
+		l := f.New("/openrtb2/auction", nil)
+		l.Debugf("debug %d", 1)
+		l.Infof("info %d", 2)
+		l.Warnf("warn %d", 3)
+		l.Errorf("error %d", 4)
+
+		var levels []Level
+		for _, entry := range b.entries {
+			levels = append(levels, entry.Level)
+			assert.Equal(t, l.Fields(), entry.Fields, test.description+":fields")
+		}
+		assert.Equal(t, test.expectedLevels, levels, test.description)
+	}
+}
+
+func TestContext(t *testing.T) {
+	b := &capturingBackend{}
+	f := newFactoryForTest(b, LevelInfo, 1, 0)
+
+	assert.Nil(t, FromContext(context.Background()), "no logger")
+
+	l := f.New("/openrtb2/amp", nil).WithAccount("some-account")
+	ctx := NewContext(context.Background(), l)
+	assert.Same(t, l, FromContext(ctx), "logger")
+
+	FromContext(ctx).Errorf("request")
+
+	if assert.Len(t, b.entries, 1) {
+		assert.Equal(t, l.Fields(), b.entries[0].Fields, "request fields")
+		assert.Equal(t, "request", b.entries[0].Message, "request message")
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	b, err := NewBackend("", nil)
+	assert.NoError(t, err, "default")
+	assert.Equal(t, glogBackend{}, b, "default")
+
+	b, err = NewBackend(FormatGlog, nil)
+	assert.NoError(t, err, "glog")
+	assert.Equal(t, glogBackend{}, b, "glog")
+
+	b, err = NewBackend(FormatJSON, &bytes.Buffer{})
+	assert.NoError(t, err, "json")
+	assert.IsType(t, &jsonBackend{}, b, "json")
+
+	_, err = NewBackend("text", nil)
+	assert.EqualError(t, err, "unknown log format text. Must be one of glog or json", "unknown")
+}
+
+func TestJSONBackend(t *testing.T) {
+	var out bytes.Buffer
+	b, _ := NewBackend(FormatJSON, &out)
+
+	b.Write(Entry{
+		Time:    time.Date(2023, 3, 1, 10, 30, 0, 0, time.UTC),
+		Level:   LevelWarn,
+		Message: "something \"quoted\" happened",
+		Fields:  Fields{RequestID: "some-request-id", Endpoint: "/openrtb2/auction", Account: "some-account"},
+	})
+	b.Write(Entry{
+		Time:    time.Date(2023, 3, 1, 10, 30, 1, 0, time.UTC),
+		Level:   LevelError,
+		Message: "no request",
+	})
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+	assert.JSONEq(t, `{"time":"2023-03-01T10:30:00Z","level":"warn","msg":"something \"quoted\" happened","request_id":"some-request-id","endpoint":"/openrtb2/auction","account":"some-account"}`, string(lines[0]))
+	assert.JSONEq(t, `{"time":"2023-03-01T10:30:01Z","level":"error","msg":"no request"}`, string(lines[1]))
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(lines[0], &entry), "each line is a JSON object")
+}
+
+func TestFormatFields(t *testing.T) {
+	assert.Equal(t, "", formatFields(Fields{}), "no fields")
+	assert.Equal(t, "request_id=some-request-id incoming_request_id=some-incoming-id endpoint=/openrtb2/auction account=some-account",
+		formatFields(Fields{RequestID: "some-request-id", IncomingRequestID: "some-incoming-id", Endpoint: "/openrtb2/auction", Account: "some-account"}))
+}
@@ -17,9 +17,13 @@ type EndpointTemplateParams struct {
 
 // UserSyncTemplateParams specifies params for an user sync URL template
 type UserSyncTemplateParams struct {
-	GDPR        string
-	GDPRConsent string
-	USPrivacy   string
+	GDPR         string
+	GDPRConsent  string
+	USPrivacy    string
+	GPP          string
+	GPPSID       string
+	HostCookieID string
+	AccountID    string
 }
 
 // ResolveMacros resolves macros in the given template with the provided params
@@ -4,8 +4,11 @@ import (
 	"flag"
 	"math/rand"
 	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/prebid/prebid-server/config"
@@ -48,7 +51,13 @@ func main() {
 	garbageCollectionThreshold := make([]byte, cfg.GarbageCollectorThreshold)
 	defer runtime.KeepAlive(garbageCollectionThreshold)
 
-	err = serve(cfg)
+	err = serve(cfg, func() (*config.Configuration, error) {
+		bidderInfos, err := config.LoadBidderInfoFromDisk(bidderInfoPath)
+		if err != nil {
+			return nil, err
+		}
+		return loadConfig(bidderInfos)
+	})
 	if err != nil {
 		glog.Exitf("prebid-server failed: %v", err)
 	}
@@ -63,7 +72,7 @@ func loadConfig(bidderInfos config.BidderInfos) (*config.Configuration, error) {
 	return config.New(v, bidderInfos, openrtb_ext.NormalizeBidderName)
 }
 
-func serve(cfg *config.Configuration) error {
+func serve(cfg *config.Configuration, load router.ConfigLoader) error {
 	fetchingInterval := time.Duration(cfg.CurrencyConverter.FetchIntervalSeconds) * time.Second
 	staleRatesThreshold := time.Duration(cfg.CurrencyConverter.StaleRatesSeconds) * time.Second
 	currencyConverter := currency.NewRateConverter(&http.Client{}, cfg.CurrencyConverter.FetchURL, staleRatesThreshold)
@@ -76,8 +85,21 @@ func serve(cfg *config.Configuration) error {
 		return err
 	}
 
+	// reload the adapters, account defaults and auction timeouts on SIGHUP, and from the admin API if enabled
+	configReloader := router.NewConfigReloader(r, load)
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	go configReloader.ReloadOnSignal(reloadSignals)
+
+	var adminAPI *router.AdminAPI
+	if cfg.AdminAPI.Enabled {
+		adminAPI = router.NewAdminAPI(r, configReloader, cfg.AdminAPI)
+	}
+
 	corsRouter := router.SupportCORS(r)
-	server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(currencyConverter, fetchingInterval), r.MetricsEngine)
+	server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(currencyConverter, fetchingInterval, adminAPI), r.MetricsEngine)
+	signal.Stop(reloadSignals)
+	close(reloadSignals)
 
 	r.Shutdown()
 	return nil
@@ -130,9 +130,9 @@ func (me *MultiMetricsEngine) RecordAdapterRequest(labels metrics.AdapterLabels)
 
 // Keeps track of created and reused connections to adapter bidders and the time from the
 // connection request, to the connection creation, or reuse from the pool across all engines
-func (me *MultiMetricsEngine) RecordAdapterConnections(bidderName openrtb_ext.BidderName, connWasReused bool, connWaitTime time.Duration) {
+func (me *MultiMetricsEngine) RecordAdapterConnections(bidderName openrtb_ext.BidderName, transportProfile string, connWasReused bool, connWaitTime time.Duration) {
 	for _, thisME := range *me {
-		thisME.RecordAdapterConnections(bidderName, connWasReused, connWaitTime)
+		thisME.RecordAdapterConnections(bidderName, transportProfile, connWasReused, connWaitTime)
 	}
 }
 
@@ -198,6 +198,13 @@ func (me *MultiMetricsEngine) RecordSyncerSet(key string, status metrics.SyncerS
 	}
 }
 
+// RecordInvalidUIDCookie across all engines
+func (me *MultiMetricsEngine) RecordInvalidUIDCookie(reason metrics.InvalidUIDCookieReason) {
+	for _, thisME := range *me {
+		thisME.RecordInvalidUIDCookie(reason)
+	}
+}
+
 // RecordStoredReqCacheResult across all engines
 func (me *MultiMetricsEngine) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
 	for _, thisME := range *me {
@@ -345,6 +352,30 @@ func (me *MultiMetricsEngine) RecordModuleTimeout(labels metrics.ModuleLabels) {
 	}
 }
 
+func (me *MultiMetricsEngine) RecordAnalyticsEvent(labels metrics.AnalyticsLabels) {
+	for _, thisME := range *me {
+		thisME.RecordAnalyticsEvent(labels)
+	}
+}
+
+func (me *MultiMetricsEngine) RecordRequestThrottled(labels metrics.ThrottleLabels) {
+	for _, thisME := range *me {
+		thisME.RecordRequestThrottled(labels)
+	}
+}
+
+func (me *MultiMetricsEngine) RecordShadowRequest(labels metrics.ShadowLabels, length time.Duration, bids int) {
+	for _, thisME := range *me {
+		thisME.RecordShadowRequest(labels, length, bids)
+	}
+}
+
+func (me *MultiMetricsEngine) RecordRequestPolicyViolation(labels metrics.RequestPolicyLabels) {
+	for _, thisME := range *me {
+		thisME.RecordRequestPolicyViolation(labels)
+	}
+}
+
 // NilMetricsEngine implements the MetricsEngine interface where no metrics are actually captured. This is
 // used if no metric backend is configured and also for tests.
 type NilMetricsEngine struct{}
@@ -386,7 +417,7 @@ func (me *NilMetricsEngine) RecordAdapterRequest(labels metrics.AdapterLabels) {
 }
 
 // RecordAdapterConnections as a noop
-func (me *NilMetricsEngine) RecordAdapterConnections(bidderName openrtb_ext.BidderName, connWasReused bool, connWaitTime time.Duration) {
+func (me *NilMetricsEngine) RecordAdapterConnections(bidderName openrtb_ext.BidderName, transportProfile string, connWasReused bool, connWaitTime time.Duration) {
 }
 
 // RecordDNSTime as a noop
@@ -425,6 +456,10 @@ func (me *NilMetricsEngine) RecordSetUid(status metrics.SetUidStatus) {
 func (me *NilMetricsEngine) RecordSyncerSet(key string, status metrics.SyncerSetUidStatus) {
 }
 
+// RecordInvalidUIDCookie as a noop
+func (me *NilMetricsEngine) RecordInvalidUIDCookie(reason metrics.InvalidUIDCookieReason) {
+}
+
 // RecordStoredReqCacheResult as a noop
 func (me *NilMetricsEngine) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
 }
@@ -504,3 +539,19 @@ func (me *NilMetricsEngine) RecordModuleExecutionError(labels metrics.ModuleLabe
 
 func (me *NilMetricsEngine) RecordModuleTimeout(labels metrics.ModuleLabels) {
 }
+
+// RecordAnalyticsEvent as a noop
+func (me *NilMetricsEngine) RecordAnalyticsEvent(labels metrics.AnalyticsLabels) {
+}
+
+// RecordRequestThrottled as a noop
+func (me *NilMetricsEngine) RecordRequestThrottled(labels metrics.ThrottleLabels) {
+}
+
+// RecordShadowRequest as a noop
+func (me *NilMetricsEngine) RecordShadowRequest(labels metrics.ShadowLabels, length time.Duration, bids int) {
+}
+
+// RecordRequestPolicyViolation as a noop
+func (me *NilMetricsEngine) RecordRequestPolicyViolation(labels metrics.RequestPolicyLabels) {
+}
@@ -44,6 +44,7 @@ type Metrics struct {
 	SetUidMeter           metrics.Meter
 	SetUidStatusMeter     map[SetUidStatus]metrics.Meter
 	SyncerSetsMeter       map[string]map[SyncerSetUidStatus]metrics.Meter
+	InvalidUIDCookieMeter map[InvalidUIDCookieReason]metrics.Meter
 
 	// Media types found in the "imp" JSON object
 	ImpsTypeBanner metrics.Meter
@@ -176,6 +177,7 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderNa
 		SetUidMeter:                    blankMeter,
 		SetUidStatusMeter:              make(map[SetUidStatus]metrics.Meter),
 		SyncerSetsMeter:                make(map[string]map[SyncerSetUidStatus]metrics.Meter),
+		InvalidUIDCookieMeter:          make(map[InvalidUIDCookieReason]metrics.Meter),
 		StoredResponsesMeter:           blankMeter,
 
 		ImpsTypeBanner: blankMeter,
@@ -312,6 +314,10 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 		newMetrics.SetUidStatusMeter[s] = metrics.GetOrRegisterMeter(fmt.Sprintf("setuid_requests.%s", s), registry)
 	}
 
+	for _, r := range InvalidUIDCookieReasons() {
+		newMetrics.InvalidUIDCookieMeter[r] = metrics.GetOrRegisterMeter(fmt.Sprintf("uid_cookie_invalid.%s", r), registry)
+	}
+
 	for _, syncerKey := range syncerKeys {
 		newMetrics.SyncerRequestsMeter[syncerKey] = make(map[SyncerCookieSyncStatus]metrics.Meter)
 		for _, status := range SyncerRequestStatuses() {
@@ -684,6 +690,7 @@ func (me *Metrics) RecordAdapterRequest(labels AdapterLabels) {
 // Keeps track of created and reused connections to adapter bidders and the time from the
 // connection request, to the connection creation, or reuse from the pool across all engines
 func (me *Metrics) RecordAdapterConnections(adapterName openrtb_ext.BidderName,
+	transportProfile string,
 	connWasReused bool,
 	connWaitTime time.Duration) {
 
@@ -691,6 +698,13 @@ func (me *Metrics) RecordAdapterConnections(adapterName openrtb_ext.BidderName,
 		return
 	}
 
+	if connWasReused {
+		metrics.GetOrRegisterCounter(fmt.Sprintf("transport_profile.%s.connections_reused", transportProfile), me.MetricsRegistry).Inc(1)
+	} else {
+		metrics.GetOrRegisterCounter(fmt.Sprintf("transport_profile.%s.connections_created", transportProfile), me.MetricsRegistry).Inc(1)
+	}
+	metrics.GetOrRegisterTimer(fmt.Sprintf("transport_profile.%s.connection_wait_time", transportProfile), me.MetricsRegistry).Update(connWaitTime)
+
 	am, ok := me.AdapterMetrics[adapterName]
 	if !ok {
 		glog.Errorf("Trying to log adapter connection metrics for %s: adapter not found", string(adapterName))
@@ -804,6 +818,13 @@ func (me *Metrics) RecordSyncerSet(key string, status SyncerSetUidStatus) {
 	}
 }
 
+// RecordInvalidUIDCookie implements a part of the MetricsEngine interface. Records a uids cookie which couldn't be read
+func (me *Metrics) RecordInvalidUIDCookie(reason InvalidUIDCookieReason) {
+	if meter, exists := me.InvalidUIDCookieMeter[reason]; exists {
+		meter.Mark(1)
+	}
+}
+
 // RecordStoredReqCacheResult implements a part of the MetricsEngine interface. Records the
 // cache hits and misses when looking up stored requests
 func (me *Metrics) RecordStoredReqCacheResult(cacheResult CacheResult, inc int) {
@@ -1075,6 +1096,36 @@ func (me *Metrics) RecordModuleTimeout(labels ModuleLabels) {
 	}
 }
 
+// RecordAnalyticsEvent registers the meter on first use since the set of analytics modules is only known to the analytics config.
+func (me *Metrics) RecordAnalyticsEvent(labels AnalyticsLabels) {
+	meterName := fmt.Sprintf("analytics.%s.%s.%s", labels.Module, labels.ObjectType, labels.Outcome)
+	metrics.GetOrRegisterMeter(meterName, me.MetricsRegistry).Mark(1)
+}
+
+// RecordRequestThrottled registers the meters on first use, like the account metrics.
+func (me *Metrics) RecordRequestThrottled(labels ThrottleLabels) {
+	metrics.GetOrRegisterMeter(fmt.Sprintf("requests.throttled.%s.%s", labels.Action, labels.Reason), me.MetricsRegistry).Mark(1)
+	if labels.PubID != PublisherUnknown {
+		metrics.GetOrRegisterMeter(fmt.Sprintf("account.%s.requests.throttled.%s.%s", labels.PubID, labels.Action, labels.Reason), me.MetricsRegistry).Mark(1)
+	}
+}
+
+// RecordShadowRequest registers the metrics on first use, like the account metrics, since few bidders
+// have a shadow endpoint.
+func (me *Metrics) RecordShadowRequest(labels ShadowLabels, length time.Duration, bids int) {
+	metrics.GetOrRegisterMeter(fmt.Sprintf("adapter.%s.shadow.requests.%s", labels.Adapter, labels.Status), me.MetricsRegistry).Mark(1)
+	metrics.GetOrRegisterTimer(fmt.Sprintf("adapter.%s.shadow.request_time", labels.Adapter), me.MetricsRegistry).Update(length)
+	metrics.GetOrRegisterMeter(fmt.Sprintf("adapter.%s.shadow.bids", labels.Adapter), me.MetricsRegistry).Mark(int64(bids))
+}
+
+// RecordRequestPolicyViolation registers the meters on first use, like the account metrics.
+func (me *Metrics) RecordRequestPolicyViolation(labels RequestPolicyLabels) {
+	metrics.GetOrRegisterMeter(fmt.Sprintf("requests.policy.%s.%s", labels.Rule, labels.Action), me.MetricsRegistry).Mark(1)
+	if labels.PubID != PublisherUnknown {
+		metrics.GetOrRegisterMeter(fmt.Sprintf("account.%s.requests.policy.%s.%s", labels.PubID, labels.Rule, labels.Action), me.MetricsRegistry).Mark(1)
+	}
+}
+
 func (me *Metrics) getModuleMetric(labels ModuleLabels) (*ModuleMetrics, error) {
 	mm, ok := me.ModuleMetrics[labels.Module][labels.Stage]
 	if !ok {
@@ -278,6 +278,43 @@ func TestRecordDebugRequest(t *testing.T) {
 	}
 }
 
+func TestRecordRequestThrottled(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordRequestThrottled(ThrottleLabels{PubID: "acct-id", Reason: ThrottleLatency, Action: ThrottleReduced})
+	m.RecordRequestThrottled(ThrottleLabels{PubID: PublisherUnknown, Reason: ThrottleLatency, Action: ThrottleReduced})
+
+	assert.Equal(t, int64(2), registry.Get("requests.throttled.reduced.latency").(metrics.Meter).Count(), "total")
+	assert.Equal(t, int64(1), registry.Get("account.acct-id.requests.throttled.reduced.latency").(metrics.Meter).Count(), "account")
+	assert.Nil(t, registry.Get("account.unknown.requests.throttled.reduced.latency"), "unknown account")
+}
+
+func TestRecordShadowRequest(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordShadowRequest(ShadowLabels{Adapter: openrtb_ext.BidderAppnexus, Status: ShadowBids}, 10*time.Millisecond, 2)
+	m.RecordShadowRequest(ShadowLabels{Adapter: openrtb_ext.BidderAppnexus, Status: ShadowError}, 20*time.Millisecond, 0)
+
+	assert.Equal(t, int64(1), registry.Get("adapter.appnexus.shadow.requests.bids").(metrics.Meter).Count(), "bids status")
+	assert.Equal(t, int64(1), registry.Get("adapter.appnexus.shadow.requests.error").(metrics.Meter).Count(), "error status")
+	assert.Equal(t, int64(2), registry.Get("adapter.appnexus.shadow.request_time").(metrics.Timer).Count(), "request time")
+	assert.Equal(t, int64(2), registry.Get("adapter.appnexus.shadow.bids").(metrics.Meter).Count(), "bids")
+}
+
+func TestRecordRequestPolicyViolation(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordRequestPolicyViolation(RequestPolicyLabels{PubID: "acct-id", Rule: RequestPolicyMaxImps, Action: RequestPolicyFixed})
+	m.RecordRequestPolicyViolation(RequestPolicyLabels{PubID: PublisherUnknown, Rule: RequestPolicyMaxImps, Action: RequestPolicyFixed})
+
+	assert.Equal(t, int64(2), registry.Get("requests.policy.max_imps.fixed").(metrics.Meter).Count(), "total")
+	assert.Equal(t, int64(1), registry.Get("account.acct-id.requests.policy.max_imps.fixed").(metrics.Meter).Count(), "account")
+	assert.Nil(t, registry.Get("account.unknown.requests.policy.max_imps.fixed"), "unknown account")
+}
+
 func TestRecordBidValidationCreativeSize(t *testing.T) {
 	testCases := []struct {
 		description          string
@@ -515,9 +552,17 @@ func TestRecordAdapterConnections(t *testing.T) {
 		registry := metrics.NewRegistry()
 		m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{AdapterConnectionMetrics: test.in.connMetricsDisabled}, nil, nil)
 
-		m.RecordAdapterConnections(test.in.adapterName, test.in.connWasReused, test.in.connWait)
+		m.RecordAdapterConnections(test.in.adapterName, "dedicated", test.in.connWasReused, test.in.connWait)
 
 		assert.Equal(t, test.out.expectedConnReusedCount, m.AdapterMetrics[openrtb_ext.BidderAppnexus].ConnReused.Count(), "Test [%d] incorrect number of reused connections to adapter", i)
+		if !test.in.connMetricsDisabled {
+			var expectedProfileReused, expectedProfileCreated int64 = 0, 1
+			if test.in.connWasReused {
+				expectedProfileReused, expectedProfileCreated = 1, 0
+			}
+			assert.Equal(t, expectedProfileReused, metrics.GetOrRegisterCounter("transport_profile.dedicated.connections_reused", registry).Count(), "Test [%d] incorrect number of reused connections of the transport profile", i)
+			assert.Equal(t, expectedProfileCreated, metrics.GetOrRegisterCounter("transport_profile.dedicated.connections_created", registry).Count(), "Test [%d] incorrect number of new connections of the transport profile", i)
+		}
 		assert.Equal(t, test.out.expectedConnCreatedCount, m.AdapterMetrics[openrtb_ext.BidderAppnexus].ConnCreated.Count(), "Test [%d] incorrect number of new connections to adapter created", i)
 		assert.Equal(t, test.out.expectedConnWaitTime.Nanoseconds(), m.AdapterMetrics[openrtb_ext.BidderAppnexus].ConnWaitTime.Sum(), "Test [%d] incorrect wait time in connection to adapter", i)
 	}
@@ -836,6 +881,22 @@ func TestRecordSetUid(t *testing.T) {
 	assert.Equal(t, m.SetUidStatusMeter[SetUidSyncerUnknown].Count(), int64(0))
 }
 
+func TestRecordInvalidUIDCookie(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon}, config.DisabledMetrics{}, nil, nil)
+
+	// Known
+	m.RecordInvalidUIDCookie(InvalidUIDCookieUnknownKey)
+
+	// Unknown
+	m.RecordInvalidUIDCookie(InvalidUIDCookieReason("unknown reason"))
+
+	assert.Equal(t, m.InvalidUIDCookieMeter[InvalidUIDCookieMalformed].Count(), int64(0))
+	assert.Equal(t, m.InvalidUIDCookieMeter[InvalidUIDCookieUnknownKey].Count(), int64(1))
+	assert.Equal(t, m.InvalidUIDCookieMeter[InvalidUIDCookieTampered].Count(), int64(0))
+	assert.Equal(t, m.InvalidUIDCookieMeter[InvalidUIDCookieLegacy].Count(), int64(0))
+}
+
 func TestRecordSyncerSet(t *testing.T) {
 	registry := metrics.NewRegistry()
 	syncerKeys := []string{"foo"}
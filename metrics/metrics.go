@@ -55,6 +55,153 @@ type ModuleLabels struct {
 	AccountID string
 }
 
+// AnalyticsLabels defines metrics describing what happened to an object sent to an analytics module.
+type AnalyticsLabels struct {
+	Module     string
+	ObjectType string
+	Outcome    AnalyticsOutcome
+}
+
+// AnalyticsOutcome describes whether an object was passed to an analytics module or the reason it was dropped.
+type AnalyticsOutcome string
+
+const (
+	AnalyticsLogged          AnalyticsOutcome = "logged"
+	AnalyticsSampledOut      AnalyticsOutcome = "sampled_out"
+	AnalyticsAccountDisabled AnalyticsOutcome = "account_disabled"
+	AnalyticsRedactionFailed AnalyticsOutcome = "redaction_failed"
+)
+
+// AnalyticsOutcomes returns possible analytics outcomes.
+func AnalyticsOutcomes() []AnalyticsOutcome {
+	return []AnalyticsOutcome{
+		AnalyticsLogged,
+		AnalyticsSampledOut,
+		AnalyticsAccountDisabled,
+		AnalyticsRedactionFailed,
+	}
+}
+
+// ThrottleLabels defines metrics describing an auction request throttled by the rate limits or the load shedding.
+type ThrottleLabels struct {
+	PubID  string
+	Reason ThrottleReason
+	Action ThrottleAction
+}
+
+// ThrottleReason describes why an auction request was throttled.
+type ThrottleReason string
+
+const (
+	ThrottleRateLimited ThrottleReason = "rate_limited"
+	ThrottleInFlight    ThrottleReason = "in_flight"
+	ThrottleLatency     ThrottleReason = "latency"
+)
+
+// ThrottleReasons returns possible throttle reasons.
+func ThrottleReasons() []ThrottleReason {
+	return []ThrottleReason{
+		ThrottleRateLimited,
+		ThrottleInFlight,
+		ThrottleLatency,
+	}
+}
+
+// ThrottleAction describes whether a throttled auction request was rejected or called fewer bidders.
+type ThrottleAction string
+
+const (
+	ThrottleRejected ThrottleAction = "rejected"
+	ThrottleReduced  ThrottleAction = "reduced"
+)
+
+// ThrottleActions returns possible throttle actions.
+func ThrottleActions() []ThrottleAction {
+	return []ThrottleAction{
+		ThrottleRejected,
+		ThrottleReduced,
+	}
+}
+
+// ShadowLabels defines metrics describing a request mirrored to the shadow endpoint of a bidder.
+type ShadowLabels struct {
+	Adapter openrtb_ext.BidderName
+	Status  ShadowStatus
+}
+
+// ShadowStatus describes the response to a request mirrored to the shadow endpoint of a bidder.
+type ShadowStatus string
+
+const (
+	ShadowBids    ShadowStatus = "bids"
+	ShadowNoBids  ShadowStatus = "no_bids"
+	ShadowError   ShadowStatus = "error"
+	ShadowTimeout ShadowStatus = "timeout"
+)
+
+// ShadowStatuses returns possible shadow request statuses.
+func ShadowStatuses() []ShadowStatus {
+	return []ShadowStatus{
+		ShadowBids,
+		ShadowNoBids,
+		ShadowError,
+		ShadowTimeout,
+	}
+}
+
+// RequestPolicyLabels defines metrics describing an auction request breaking a rule of the request policy
+// of its account.
+type RequestPolicyLabels struct {
+	PubID  string
+	Rule   RequestPolicyRule
+	Action RequestPolicyAction
+}
+
+// RequestPolicyRule is a rule of the request policy of an account.
+type RequestPolicyRule string
+
+const (
+	RequestPolicySitePage   RequestPolicyRule = "site_page"
+	RequestPolicyAppBundle  RequestPolicyRule = "app_bundle"
+	RequestPolicyDeviceIP   RequestPolicyRule = "device_ip"
+	RequestPolicyMediaTypes RequestPolicyRule = "media_types"
+	RequestPolicyMaxImps    RequestPolicyRule = "max_imps"
+	RequestPolicyMaxTmax    RequestPolicyRule = "max_tmax"
+	RequestPolicyUserAgents RequestPolicyRule = "user_agents"
+)
+
+// RequestPolicyRules returns possible request policy rules.
+func RequestPolicyRules() []RequestPolicyRule {
+	return []RequestPolicyRule{
+		RequestPolicySitePage,
+		RequestPolicyAppBundle,
+		RequestPolicyDeviceIP,
+		RequestPolicyMediaTypes,
+		RequestPolicyMaxImps,
+		RequestPolicyMaxTmax,
+		RequestPolicyUserAgents,
+	}
+}
+
+// RequestPolicyAction describes whether a request breaking a rule of the request policy was rejected,
+// warned about or fixed.
+type RequestPolicyAction string
+
+const (
+	RequestPolicyRejected RequestPolicyAction = "rejected"
+	RequestPolicyWarned   RequestPolicyAction = "warned"
+	RequestPolicyFixed    RequestPolicyAction = "fixed"
+)
+
+// RequestPolicyActions returns possible request policy actions.
+func RequestPolicyActions() []RequestPolicyAction {
+	return []RequestPolicyAction{
+		RequestPolicyRejected,
+		RequestPolicyWarned,
+		RequestPolicyFixed,
+	}
+}
+
 type StoredDataType string
 
 const (
@@ -395,6 +542,30 @@ func SyncerSetUidStatuses() []SyncerSetUidStatus {
 	}
 }
 
+// InvalidUIDCookieReason is the reason why the uids cookie of a request couldn't be read.
+type InvalidUIDCookieReason string
+
+const (
+	// InvalidUIDCookieMalformed is a cookie which isn't in any known format.
+	InvalidUIDCookieMalformed InvalidUIDCookieReason = "malformed"
+	// InvalidUIDCookieUnknownKey is an encrypted cookie with a key which isn't configured anymore.
+	InvalidUIDCookieUnknownKey InvalidUIDCookieReason = "unknown_key"
+	// InvalidUIDCookieTampered is an encrypted cookie which fails authentication.
+	InvalidUIDCookieTampered InvalidUIDCookieReason = "tampered"
+	// InvalidUIDCookieLegacy is an unencrypted cookie while only encrypted cookies are accepted.
+	InvalidUIDCookieLegacy InvalidUIDCookieReason = "legacy"
+)
+
+// InvalidUIDCookieReasons returns possible invalid uids cookie reasons.
+func InvalidUIDCookieReasons() []InvalidUIDCookieReason {
+	return []InvalidUIDCookieReason{
+		InvalidUIDCookieMalformed,
+		InvalidUIDCookieUnknownKey,
+		InvalidUIDCookieTampered,
+		InvalidUIDCookieLegacy,
+	}
+}
+
 // MetricsEngine is a generic interface to record PBS metrics into the desired backend
 // The first three metrics function fire off once per incoming request, so total metrics
 // will equal the total number of incoming requests. The remaining 5 fire off per outgoing
@@ -408,7 +579,7 @@ type MetricsEngine interface {
 	RecordImps(labels ImpLabels)                           // RecordImps across openRTB2 engines that support the 'Native' Imp Type
 	RecordRequestTime(labels Labels, length time.Duration) // ignores adapter. only statusOk and statusErr fom status
 	RecordAdapterRequest(labels AdapterLabels)
-	RecordAdapterConnections(adapterName openrtb_ext.BidderName, connWasReused bool, connWaitTime time.Duration)
+	RecordAdapterConnections(adapterName openrtb_ext.BidderName, transportProfile string, connWasReused bool, connWaitTime time.Duration)
 	RecordDNSTime(dnsLookupTime time.Duration)
 	RecordTLSHandshakeTime(tlsHandshakeTime time.Duration)
 	RecordAdapterPanic(labels AdapterLabels)
@@ -421,6 +592,7 @@ type MetricsEngine interface {
 	RecordSyncerRequest(key string, status SyncerCookieSyncStatus)
 	RecordSetUid(status SetUidStatus)
 	RecordSyncerSet(key string, status SyncerSetUidStatus)
+	RecordInvalidUIDCookie(reason InvalidUIDCookieReason)
 	RecordStoredReqCacheResult(cacheResult CacheResult, inc int)
 	RecordStoredImpCacheResult(cacheResult CacheResult, inc int)
 	RecordAccountCacheResult(cacheResult CacheResult, inc int)
@@ -446,4 +618,8 @@ type MetricsEngine interface {
 	RecordModuleSuccessRejected(labels ModuleLabels)
 	RecordModuleExecutionError(labels ModuleLabels)
 	RecordModuleTimeout(labels ModuleLabels)
+	RecordAnalyticsEvent(labels AnalyticsLabels)
+	RecordRequestThrottled(labels ThrottleLabels)
+	RecordShadowRequest(labels ShadowLabels, length time.Duration, bids int)
+	RecordRequestPolicyViolation(labels RequestPolicyLabels)
 }
@@ -58,8 +58,8 @@ func (me *MetricsEngineMock) RecordAdapterRequest(labels AdapterLabels) {
 }
 
 // RecordAdapterConnections mock
-func (me *MetricsEngineMock) RecordAdapterConnections(bidderName openrtb_ext.BidderName, connWasReused bool, connWaitTime time.Duration) {
-	me.Called(bidderName, connWasReused, connWaitTime)
+func (me *MetricsEngineMock) RecordAdapterConnections(bidderName openrtb_ext.BidderName, transportProfile string, connWasReused bool, connWaitTime time.Duration) {
+	me.Called(bidderName, transportProfile, connWasReused, connWaitTime)
 }
 
 // RecordDNSTime mock
@@ -106,6 +106,11 @@ func (me *MetricsEngineMock) RecordSyncerSet(key string, status SyncerSetUidStat
 	me.Called(key, status)
 }
 
+// RecordInvalidUIDCookie mock
+func (me *MetricsEngineMock) RecordInvalidUIDCookie(reason InvalidUIDCookieReason) {
+	me.Called(reason)
+}
+
 // RecordStoredReqCacheResult mock
 func (me *MetricsEngineMock) RecordStoredReqCacheResult(cacheResult CacheResult, inc int) {
 	me.Called(cacheResult, inc)
@@ -206,3 +211,19 @@ func (me *MetricsEngineMock) RecordModuleExecutionError(labels ModuleLabels) {
 func (me *MetricsEngineMock) RecordModuleTimeout(labels ModuleLabels) {
 	me.Called(labels)
 }
+
+func (me *MetricsEngineMock) RecordAnalyticsEvent(labels AnalyticsLabels) {
+	me.Called(labels)
+}
+
+func (me *MetricsEngineMock) RecordRequestThrottled(labels ThrottleLabels) {
+	me.Called(labels)
+}
+
+func (me *MetricsEngineMock) RecordShadowRequest(labels ShadowLabels, length time.Duration, bids int) {
+	me.Called(labels, length, bids)
+}
+
+func (me *MetricsEngineMock) RecordRequestPolicyViolation(labels RequestPolicyLabels) {
+	me.Called(labels)
+}
@@ -1,6 +1,7 @@
 package prometheusmetrics
 
 import (
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -8,6 +9,7 @@ import (
 func preloadLabelValues(m *Metrics, syncerKeys []string, moduleStageNames map[string][]string) {
 	var (
 		setUidStatusValues        = setUidStatusesAsString()
+		invalidUIDCookieValues    = invalidUIDCookieReasonsAsString()
 		adapterErrorValues        = adapterErrorsAsString()
 		adapterValues             = adaptersAsString()
 		bidTypeValues             = []string{markupDeliveryAdm, markupDeliveryNurl}
@@ -37,6 +39,10 @@ func preloadLabelValues(m *Metrics, syncerKeys []string, moduleStageNames map[st
 		statusLabel: setUidStatusValues,
 	})
 
+	preloadLabelValuesForCounter(m.invalidUIDCookie, map[string][]string{
+		reasonLabel: invalidUIDCookieValues,
+	})
+
 	preloadLabelValuesForCounter(m.impressions, map[string][]string{
 		isBannerLabel: boolValues,
 		isVideoLabel:  boolValues,
@@ -171,15 +177,18 @@ func preloadLabelValues(m *Metrics, syncerKeys []string, moduleStageNames map[st
 
 	if !m.metricsDisabled.AdapterConnectionMetrics {
 		preloadLabelValuesForCounter(m.adapterCreatedConnections, map[string][]string{
-			adapterLabel: adapterValues,
+			adapterLabel:          adapterValues,
+			transportProfileLabel: {config.DefaultTransportProfile},
 		})
 
 		preloadLabelValuesForCounter(m.adapterReusedConnections, map[string][]string{
-			adapterLabel: adapterValues,
+			adapterLabel:          adapterValues,
+			transportProfileLabel: {config.DefaultTransportProfile},
 		})
 
 		preloadLabelValuesForHistogram(m.adapterConnectionWaitTime, map[string][]string{
-			adapterLabel: adapterValues,
+			adapterLabel:          adapterValues,
+			transportProfileLabel: {config.DefaultTransportProfile},
 		})
 	}
 
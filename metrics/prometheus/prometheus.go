@@ -23,6 +23,7 @@ type Metrics struct {
 	connectionsOpened            prometheus.Counter
 	cookieSync                   *prometheus.CounterVec
 	setUid                       *prometheus.CounterVec
+	invalidUIDCookie             *prometheus.CounterVec
 	impressions                  *prometheus.CounterVec
 	impressionsLegacy            prometheus.Counter
 	prebidCacheWriteTimer        *prometheus.HistogramVec
@@ -56,6 +57,12 @@ type Metrics struct {
 	storedResponsesErrors        *prometheus.CounterVec
 	adsCertRequests              *prometheus.CounterVec
 	adsCertSignTimer             prometheus.Histogram
+	analyticsEvents              *prometheus.CounterVec
+	requestsThrottled            *prometheus.CounterVec
+	adapterShadowRequests        *prometheus.CounterVec
+	adapterShadowRequestsTimer   *prometheus.HistogramVec
+	adapterShadowBids            *prometheus.CounterVec
+	requestPolicyViolations      *prometheus.CounterVec
 
 	// Adapter Metrics
 	adapterBids                           *prometheus.CounterVec
@@ -81,6 +88,8 @@ type Metrics struct {
 	accountRequests                       *prometheus.CounterVec
 	accountDebugRequests                  *prometheus.CounterVec
 	accountStoredResponses                *prometheus.CounterVec
+	accountRequestsThrottled              *prometheus.CounterVec
+	accountRequestPolicyViolations        *prometheus.CounterVec
 	accountBidResponseValidationSizeError *prometheus.CounterVec
 	accountBidResponseValidationSizeWarn  *prometheus.CounterVec
 
@@ -101,29 +110,31 @@ type Metrics struct {
 }
 
 const (
-	accountLabel         = "account"
-	actionLabel          = "action"
-	adapterErrorLabel    = "adapter_error"
-	adapterLabel         = "adapter"
-	bidTypeLabel         = "bid_type"
-	cacheResultLabel     = "cache_result"
-	connectionErrorLabel = "connection_error"
-	cookieLabel          = "cookie"
-	hasBidsLabel         = "has_bids"
-	isAudioLabel         = "audio"
-	isBannerLabel        = "banner"
-	isNativeLabel        = "native"
-	isVideoLabel         = "video"
-	markupDeliveryLabel  = "delivery"
-	optOutLabel          = "opt_out"
-	privacyBlockedLabel  = "privacy_blocked"
-	requestStatusLabel   = "request_status"
-	requestTypeLabel     = "request_type"
-	stageLabel           = "stage"
-	statusLabel          = "status"
-	successLabel         = "success"
-	syncerLabel          = "syncer"
-	versionLabel         = "version"
+	accountLabel          = "account"
+	actionLabel           = "action"
+	adapterErrorLabel     = "adapter_error"
+	adapterLabel          = "adapter"
+	transportProfileLabel = "transport_profile"
+	bidTypeLabel          = "bid_type"
+	cacheResultLabel      = "cache_result"
+	connectionErrorLabel  = "connection_error"
+	cookieLabel           = "cookie"
+	hasBidsLabel          = "has_bids"
+	isAudioLabel          = "audio"
+	isBannerLabel         = "banner"
+	isNativeLabel         = "native"
+	isVideoLabel          = "video"
+	markupDeliveryLabel   = "delivery"
+	optOutLabel           = "opt_out"
+	privacyBlockedLabel   = "privacy_blocked"
+	reasonLabel           = "reason"
+	requestStatusLabel    = "request_status"
+	requestTypeLabel      = "request_type"
+	stageLabel            = "stage"
+	statusLabel           = "status"
+	successLabel          = "success"
+	syncerLabel           = "syncer"
+	versionLabel          = "version"
 )
 
 const (
@@ -156,6 +167,22 @@ const (
 	storedDataErrorLabel     = "stored_data_error"
 )
 
+const (
+	throttleReasonLabel = "reason"
+	throttleActionLabel = "action"
+)
+
+const (
+	requestPolicyRuleLabel   = "rule"
+	requestPolicyActionLabel = "action"
+)
+
+const (
+	analyticsModuleLabel     = "module"
+	analyticsObjectTypeLabel = "object_type"
+	analyticsOutcomeLabel    = "outcome"
+)
+
 // NewMetrics initializes a new Prometheus metrics instance with preloaded label values.
 func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMetrics, syncerKeys []string, moduleStageNames map[string][]string) *Metrics {
 	standardTimeBuckets := []float64{0.05, 0.1, 0.15, 0.20, 0.25, 0.3, 0.4, 0.5, 0.75, 1}
@@ -190,6 +217,11 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of set uid requests to Prebid Server.",
 		[]string{statusLabel})
 
+	metrics.invalidUIDCookie = newCounter(cfg, reg,
+		"uid_cookie_invalid",
+		"Count of uids cookies which couldn't be read, by reason.",
+		[]string{reasonLabel})
+
 	metrics.impressions = newCounter(cfg, reg,
 		"impressions_requests",
 		"Count of requested impressions to Prebid Server labeled by type.",
@@ -382,17 +414,17 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		metrics.adapterCreatedConnections = newCounter(cfg, reg,
 			"adapter_connection_created",
 			"Count that keeps track of new connections when contacting adapter bidder endpoints.",
-			[]string{adapterLabel})
+			[]string{adapterLabel, transportProfileLabel})
 
 		metrics.adapterReusedConnections = newCounter(cfg, reg,
 			"adapter_connection_reused",
 			"Count that keeps track of reused connections when contacting adapter bidder endpoints.",
-			[]string{adapterLabel})
+			[]string{adapterLabel, transportProfileLabel})
 
 		metrics.adapterConnectionWaitTime = newHistogramVec(cfg, reg,
 			"adapter_connection_wait",
 			"Seconds from when the connection was requested until it is either created or reused",
-			[]string{adapterLabel},
+			[]string{adapterLabel, transportProfileLabel},
 			standardTimeBuckets)
 	}
 
@@ -483,6 +515,47 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of AdsCert request, and if they were successfully sent.",
 		[]string{successLabel})
 
+	metrics.analyticsEvents = newCounter(cfg, reg,
+		"analytics_events",
+		"Count of objects sent to analytics modules labeled by module, object type and whether they were logged or dropped.",
+		[]string{analyticsModuleLabel, analyticsObjectTypeLabel, analyticsOutcomeLabel})
+
+	metrics.requestsThrottled = newCounter(cfg, reg,
+		"requests_throttled",
+		"Count of auction requests rejected or calling fewer bidders because of the account rate limits or the load shedding, labeled by action and reason.",
+		[]string{throttleActionLabel, throttleReasonLabel})
+
+	metrics.accountRequestsThrottled = newCounter(cfg, reg,
+		"account_requests_throttled",
+		"Count of auction requests rejected or calling fewer bidders because of the account rate limits or the load shedding, labeled by account, action and reason.",
+		[]string{accountLabel, throttleActionLabel, throttleReasonLabel})
+
+	metrics.adapterShadowRequests = newCounter(cfg, reg,
+		"adapter_shadow_requests",
+		"Count of requests mirrored to the shadow endpoint of a bidder labeled by adapter and status.",
+		[]string{adapterLabel, statusLabel})
+
+	metrics.adapterShadowRequestsTimer = newHistogramVec(cfg, reg,
+		"adapter_shadow_request_time_seconds",
+		"Seconds to resolve each request mirrored to the shadow endpoint of a bidder labeled by adapter.",
+		[]string{adapterLabel},
+		standardTimeBuckets)
+
+	metrics.adapterShadowBids = newCounter(cfg, reg,
+		"adapter_shadow_bids",
+		"Count of bids returned by the shadow endpoint of a bidder labeled by adapter.",
+		[]string{adapterLabel})
+
+	metrics.requestPolicyViolations = newCounter(cfg, reg,
+		"request_policy_violations",
+		"Count of auction requests breaking a rule of the request policy of their account, labeled by rule and action.",
+		[]string{requestPolicyRuleLabel, requestPolicyActionLabel})
+
+	metrics.accountRequestPolicyViolations = newCounter(cfg, reg,
+		"account_request_policy_violations",
+		"Count of auction requests breaking a rule of the request policy of their account, labeled by account, rule and action.",
+		[]string{accountLabel, requestPolicyRuleLabel, requestPolicyActionLabel})
+
 	createModulesMetrics(cfg, reg, &metrics, moduleStageNames, standardTimeBuckets)
 
 	metrics.Gatherer = reg
@@ -760,24 +833,22 @@ func (m *Metrics) RecordAdapterRequest(labels metrics.AdapterLabels) {
 
 // Keeps track of created and reused connections to adapter bidders and the time from the
 // connection request, to the connection creation, or reuse from the pool across all engines
-func (m *Metrics) RecordAdapterConnections(adapterName openrtb_ext.BidderName, connWasReused bool, connWaitTime time.Duration) {
+func (m *Metrics) RecordAdapterConnections(adapterName openrtb_ext.BidderName, transportProfile string, connWasReused bool, connWaitTime time.Duration) {
 	if m.metricsDisabled.AdapterConnectionMetrics {
 		return
 	}
 
+	labels := prometheus.Labels{
+		adapterLabel:          string(adapterName),
+		transportProfileLabel: transportProfile,
+	}
 	if connWasReused {
-		m.adapterReusedConnections.With(prometheus.Labels{
-			adapterLabel: string(adapterName),
-		}).Inc()
+		m.adapterReusedConnections.With(labels).Inc()
 	} else {
-		m.adapterCreatedConnections.With(prometheus.Labels{
-			adapterLabel: string(adapterName),
-		}).Inc()
+		m.adapterCreatedConnections.With(labels).Inc()
 	}
 
-	m.adapterConnectionWaitTime.With(prometheus.Labels{
-		adapterLabel: string(adapterName),
-	}).Observe(connWaitTime.Seconds())
+	m.adapterConnectionWaitTime.With(labels).Observe(connWaitTime.Seconds())
 }
 
 func (m *Metrics) RecordDNSTime(dnsLookupTime time.Duration) {
@@ -846,6 +917,12 @@ func (m *Metrics) RecordSyncerSet(key string, status metrics.SyncerSetUidStatus)
 	}).Inc()
 }
 
+func (m *Metrics) RecordInvalidUIDCookie(reason metrics.InvalidUIDCookieReason) {
+	m.invalidUIDCookie.With(prometheus.Labels{
+		reasonLabel: string(reason),
+	}).Inc()
+}
+
 func (m *Metrics) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
 	m.storedRequestCacheResult.With(prometheus.Labels{
 		cacheResultLabel: string(cacheResult),
@@ -1039,3 +1116,56 @@ func (m *Metrics) RecordModuleTimeout(labels metrics.ModuleLabels) {
 		stageLabel: labels.Stage,
 	}).Inc()
 }
+
+func (m *Metrics) RecordAnalyticsEvent(labels metrics.AnalyticsLabels) {
+	m.analyticsEvents.With(prometheus.Labels{
+		analyticsModuleLabel:     labels.Module,
+		analyticsObjectTypeLabel: labels.ObjectType,
+		analyticsOutcomeLabel:    string(labels.Outcome),
+	}).Inc()
+}
+
+func (m *Metrics) RecordRequestThrottled(labels metrics.ThrottleLabels) {
+	m.requestsThrottled.With(prometheus.Labels{
+		throttleActionLabel: string(labels.Action),
+		throttleReasonLabel: string(labels.Reason),
+	}).Inc()
+
+	if labels.PubID != metrics.PublisherUnknown {
+		m.accountRequestsThrottled.With(prometheus.Labels{
+			accountLabel:        labels.PubID,
+			throttleActionLabel: string(labels.Action),
+			throttleReasonLabel: string(labels.Reason),
+		}).Inc()
+	}
+}
+
+func (m *Metrics) RecordShadowRequest(labels metrics.ShadowLabels, length time.Duration, bids int) {
+	m.adapterShadowRequests.With(prometheus.Labels{
+		adapterLabel: string(labels.Adapter),
+		statusLabel:  string(labels.Status),
+	}).Inc()
+
+	m.adapterShadowRequestsTimer.With(prometheus.Labels{
+		adapterLabel: string(labels.Adapter),
+	}).Observe(length.Seconds())
+
+	m.adapterShadowBids.With(prometheus.Labels{
+		adapterLabel: string(labels.Adapter),
+	}).Add(float64(bids))
+}
+
+func (m *Metrics) RecordRequestPolicyViolation(labels metrics.RequestPolicyLabels) {
+	m.requestPolicyViolations.With(prometheus.Labels{
+		requestPolicyRuleLabel:   string(labels.Rule),
+		requestPolicyActionLabel: string(labels.Action),
+	}).Inc()
+
+	if labels.PubID != metrics.PublisherUnknown {
+		m.accountRequestPolicyViolations.With(prometheus.Labels{
+			accountLabel:             labels.PubID,
+			requestPolicyRuleLabel:   string(labels.Rule),
+			requestPolicyActionLabel: string(labels.Action),
+		}).Inc()
+	}
+}
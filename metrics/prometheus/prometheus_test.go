@@ -1241,6 +1241,23 @@ func TestSetUidMetric(t *testing.T) {
 	}
 }
 
+func TestInvalidUIDCookieMetric(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordInvalidUIDCookie(metrics.InvalidUIDCookieTampered)
+
+	assertCounterVecValue(t, "", "uid_cookie_invalid:tampered", m.invalidUIDCookie,
+		float64(1),
+		prometheus.Labels{
+			reasonLabel: string(metrics.InvalidUIDCookieTampered),
+		})
+	assertCounterVecValue(t, "", "uid_cookie_invalid:malformed", m.invalidUIDCookie,
+		float64(0),
+		prometheus.Labels{
+			reasonLabel: string(metrics.InvalidUIDCookieMalformed),
+		})
+}
+
 func TestRecordSyncerSetMetric(t *testing.T) {
 	key := "anyKey"
 
@@ -1516,7 +1533,7 @@ func TestRecordAdapterConnections(t *testing.T) {
 			fmt.Sprintf("[%d] Metric: adapterWaitConnectionTime; Desc: %s", i+1, test.description),
 		}
 
-		m.RecordAdapterConnections(test.in.adapterName, test.in.connWasReused, test.in.connWait)
+		m.RecordAdapterConnections(test.in.adapterName, "dedicated", test.in.connWasReused, test.in.connWait)
 
 		// Assert number of reused connections
 		assertCounterVecValue(t,
@@ -1524,7 +1541,7 @@ func TestRecordAdapterConnections(t *testing.T) {
 			"adapter_connection_reused",
 			m.adapterReusedConnections,
 			float64(test.out.expectedConnReusedCount),
-			prometheus.Labels{adapterLabel: string(test.in.adapterName)})
+			prometheus.Labels{adapterLabel: string(test.in.adapterName), transportProfileLabel: "dedicated"})
 
 		// Assert number of new created connections
 		assertCounterVecValue(t,
@@ -1532,10 +1549,10 @@ func TestRecordAdapterConnections(t *testing.T) {
 			"adapter_connection_created",
 			m.adapterCreatedConnections,
 			float64(test.out.expectedConnCreatedCount),
-			prometheus.Labels{adapterLabel: string(test.in.adapterName)})
+			prometheus.Labels{adapterLabel: string(test.in.adapterName), transportProfileLabel: "dedicated"})
 
 		// Assert connection wait time
-		histogram := getHistogramFromHistogramVec(m.adapterConnectionWaitTime, adapterLabel, string(test.in.adapterName))
+		histogram := getHistogramFromHistogramVecByTwoKeys(m.adapterConnectionWaitTime, adapterLabel, string(test.in.adapterName), transportProfileLabel, "dedicated")
 		assert.Equal(t, test.out.expectedConnWaitCount, histogram.GetSampleCount(), assertDesciptions[2])
 		assert.Equal(t, test.out.expectedConnWaitTime, histogram.GetSampleSum(), assertDesciptions[3])
 	}
@@ -1765,6 +1782,95 @@ func TestStoredResponsesMetric(t *testing.T) {
 	}
 }
 
+func TestRecordRequestThrottled(t *testing.T) {
+	testCases := []struct {
+		description          string
+		labels               metrics.ThrottleLabels
+		expectedAccountCount float64
+	}{
+		{
+			description:          "Known account, both counters should be incremented",
+			labels:               metrics.ThrottleLabels{PubID: "acct-id", Reason: metrics.ThrottleRateLimited, Action: metrics.ThrottleRejected},
+			expectedAccountCount: 1,
+		},
+		{
+			description:          "Unknown account, only the total counter should be incremented",
+			labels:               metrics.ThrottleLabels{PubID: metrics.PublisherUnknown, Reason: metrics.ThrottleRateLimited, Action: metrics.ThrottleRejected},
+			expectedAccountCount: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		m := createMetricsForTesting()
+		m.RecordRequestThrottled(test.labels)
+
+		assertCounterVecValue(t, test.description, "requests throttled", m.requestsThrottled, 1, prometheus.Labels{
+			throttleActionLabel: string(metrics.ThrottleRejected),
+			throttleReasonLabel: string(metrics.ThrottleRateLimited),
+		})
+		assertCounterVecValue(t, test.description, "account requests throttled", m.accountRequestsThrottled, test.expectedAccountCount, prometheus.Labels{
+			accountLabel:        "acct-id",
+			throttleActionLabel: string(metrics.ThrottleRejected),
+			throttleReasonLabel: string(metrics.ThrottleRateLimited),
+		})
+	}
+}
+
+func TestRecordRequestPolicyViolation(t *testing.T) {
+	testCases := []struct {
+		description          string
+		labels               metrics.RequestPolicyLabels
+		expectedAccountCount float64
+	}{
+		{
+			description:          "Known account, both counters should be incremented",
+			labels:               metrics.RequestPolicyLabels{PubID: "acct-id", Rule: metrics.RequestPolicyDeviceIP, Action: metrics.RequestPolicyRejected},
+			expectedAccountCount: 1,
+		},
+		{
+			description:          "Unknown account, only the total counter should be incremented",
+			labels:               metrics.RequestPolicyLabels{PubID: metrics.PublisherUnknown, Rule: metrics.RequestPolicyDeviceIP, Action: metrics.RequestPolicyRejected},
+			expectedAccountCount: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		m := createMetricsForTesting()
+		m.RecordRequestPolicyViolation(test.labels)
+
+		assertCounterVecValue(t, test.description, "request policy violations", m.requestPolicyViolations, 1, prometheus.Labels{
+			requestPolicyRuleLabel:   string(metrics.RequestPolicyDeviceIP),
+			requestPolicyActionLabel: string(metrics.RequestPolicyRejected),
+		})
+		assertCounterVecValue(t, test.description, "account request policy violations", m.accountRequestPolicyViolations, test.expectedAccountCount, prometheus.Labels{
+			accountLabel:             "acct-id",
+			requestPolicyRuleLabel:   string(metrics.RequestPolicyDeviceIP),
+			requestPolicyActionLabel: string(metrics.RequestPolicyRejected),
+		})
+	}
+}
+
+func TestRecordShadowRequest(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordShadowRequest(metrics.ShadowLabels{Adapter: openrtb_ext.BidderAppnexus, Status: metrics.ShadowBids}, 500*time.Millisecond, 2)
+	m.RecordShadowRequest(metrics.ShadowLabels{Adapter: openrtb_ext.BidderAppnexus, Status: metrics.ShadowTimeout}, 250*time.Millisecond, 0)
+
+	assertCounterVecValue(t, "", "shadow requests with bids", m.adapterShadowRequests, 1, prometheus.Labels{
+		adapterLabel: string(openrtb_ext.BidderAppnexus),
+		statusLabel:  string(metrics.ShadowBids),
+	})
+	assertCounterVecValue(t, "", "shadow requests timed out", m.adapterShadowRequests, 1, prometheus.Labels{
+		adapterLabel: string(openrtb_ext.BidderAppnexus),
+		statusLabel:  string(metrics.ShadowTimeout),
+	})
+	assertCounterVecValue(t, "", "shadow bids", m.adapterShadowBids, 2, prometheus.Labels{
+		adapterLabel: string(openrtb_ext.BidderAppnexus),
+	})
+	result := getHistogramFromHistogramVec(m.adapterShadowRequestsTimer, adapterLabel, string(openrtb_ext.BidderAppnexus))
+	assertHistogram(t, "shadow request time", result, 2, 0.75)
+}
+
 func TestRecordAdsCertReqMetric(t *testing.T) {
 	testCases := []struct {
 		description                  string
@@ -104,6 +104,15 @@ func setUidStatusesAsString() []string {
 	return valuesAsString
 }
 
+func invalidUIDCookieReasonsAsString() []string {
+	values := metrics.InvalidUIDCookieReasons()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
 func storedDataFetchTypesAsString() []string {
 	values := metrics.StoredDataFetchTypes()
 	valuesAsString := make([]string, len(values))
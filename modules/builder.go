@@ -1,7 +1,11 @@
 package modules
 
 import (
+	prebidCreativescanner "github.com/prebid/prebid-server/modules/prebid/creativescanner"
+	prebidEidresolution "github.com/prebid/prebid-server/modules/prebid/eidresolution"
+	prebidGeoenrichment "github.com/prebid/prebid-server/modules/prebid/geoenrichment"
 	prebidOrtb2blocking "github.com/prebid/prebid-server/modules/prebid/ortb2blocking"
+	prebidRulesengine "github.com/prebid/prebid-server/modules/prebid/rulesengine"
 )
 
 // builders returns mapping between module name and its builder
@@ -9,7 +13,11 @@ import (
 func builders() ModuleBuilders {
 	return ModuleBuilders{
 		"prebid": {
-			"ortb2blocking": prebidOrtb2blocking.Builder,
+			"creativescanner": prebidCreativescanner.Builder,
+			"eidresolution":   prebidEidresolution.Builder,
+			"geoenrichment":   prebidGeoenrichment.Builder,
+			"ortb2blocking":   prebidOrtb2blocking.Builder,
+			"rulesengine":     prebidRulesengine.Builder,
 		},
 	}
 }
@@ -26,14 +26,15 @@ type Module struct {
 }
 
 func main() {
-	var modules []Module
+	// modules grouped by vendor, so that each vendor has exactly one entry in the builders map
+	modules := make(map[string][]Module)
 
 	filepath.WalkDir("./", func(path string, d fs.DirEntry, err error) error {
 		if !r.MatchString(path) {
 			return nil
 		}
 		match := r.FindStringSubmatch(path)
-		modules = append(modules, Module{
+		modules[match[1]] = append(modules[match[1]], Module{
 			Vendor: match[1],
 			Module: match[2],
 		})
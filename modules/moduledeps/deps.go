@@ -1,9 +1,22 @@
 package moduledeps
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/gdpr"
+)
 
 // ModuleDeps provides dependencies that custom modules may need for hooks execution.
 // Additional dependencies can be added here if modules need something more.
 type ModuleDeps struct {
 	HTTPClient *http.Client
+	// GDPRPermissionsBuilder builds the GDPR permissions of a request, to check the consent of the user
+	// before a module processes their personal data.
+	GDPRPermissionsBuilder gdpr.PermissionsBuilder
+	// TCF2ConfigBuilder merges the host TCF2 configuration with the GDPR configuration of the account
+	// to build the GDPR permissions with.
+	TCF2ConfigBuilder gdpr.TCF2ConfigBuilder
+	// HostTCF2Config is the host TCF2 configuration.
+	HostTCF2Config config.TCF2
 }
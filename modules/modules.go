@@ -14,7 +14,7 @@ import (
 
 // NewBuilder returns a new module builder.
 func NewBuilder() Builder {
-	return &builder{builders()}
+	return &builder{builders: builders()}
 }
 
 // Builder is the interfaces intended for building modules
@@ -25,6 +25,14 @@ type Builder interface {
 	// and a map of modules to a list of stage names for which module provides hooks
 	// or an error encountered during module initialization.
 	Build(cfg config.Modules, client moduledeps.ModuleDeps) (hooks.HookRepository, map[string][]string, error)
+	// Shutdown stops the background tasks of the built modules implementing Shutdowner.
+	Shutdown()
+}
+
+// Shutdowner is implemented by the modules running background tasks,
+// which must be stopped when the server shuts down.
+type Shutdowner interface {
+	Shutdown()
 }
 
 type (
@@ -35,7 +43,8 @@ type (
 )
 
 type builder struct {
-	builders ModuleBuilders
+	builders    ModuleBuilders
+	shutdowners []Shutdowner
 }
 
 // Build walks over the list of registered modules and initializes them.
@@ -80,6 +89,9 @@ func (m *builder) Build(
 			}
 
 			modules[id] = module
+			if shutdowner, ok := module.(Shutdowner); ok {
+				m.shutdowners = append(m.shutdowners, shutdowner)
+			}
 		}
 	}
 
@@ -92,3 +104,10 @@ func (m *builder) Build(
 
 	return repo, collection, err
 }
+
+// Shutdown stops the background tasks of the built modules.
+func (m *builder) Shutdown() {
+	for _, shutdowner := range m.shutdowners {
+		shutdowner.Shutdown()
+	}
+}
@@ -0,0 +1,42 @@
+package creativescanner
+
+import (
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+)
+
+const scanCreativesTag = "scan_creatives"
+
+const violationsAnalyticKey = "violations"
+
+func newScanCreativesTags() hookanalytics.Analytics {
+	return hookanalytics.Analytics{
+		Activities: []hookanalytics.Activity{
+			{
+				Name:   scanCreativesTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+			},
+		},
+	}
+}
+
+// addScanResult records the outcome of scanning a single bid. Flagged bids are allowed
+// but carry the list of violations, so they can be reviewed by the host.
+func addScanResult(analytics *hookanalytics.Analytics, bidder string, bidID string, impID string, violations []violation) {
+	result := hookanalytics.Result{
+		Status: hookanalytics.ResultStatusAllow,
+		AppliedTo: hookanalytics.AppliedTo{
+			Bidder: bidder,
+			BidIds: []string{bidID},
+			ImpIds: []string{impID},
+		},
+	}
+
+	if len(violations) > 0 {
+		result.Values = map[string]interface{}{violationsAnalyticKey: violations}
+	}
+	if shouldReject(violations) {
+		result.Status = hookanalytics.ResultStatusBlock
+	}
+
+	analytics.Activities[0].Results = append(analytics.Activities[0].Results, result)
+}
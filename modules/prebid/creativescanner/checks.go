@@ -0,0 +1,122 @@
+package creativescanner
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	checkBlockedDomains     = "blocked_domains"
+	checkBlockedURLs        = "blocked_urls"
+	checkAutoRedirect       = "auto_redirect"
+	checkForbiddenJSAPIs    = "forbidden_js_apis"
+	checkNonSecureResources = "non_secure_resources"
+	checkMaxSize            = "max_size"
+)
+
+// violation describes a single check hit for the creative.
+type violation struct {
+	Check  string `json:"check"`
+	Match  string `json:"match"`
+	Action string `json:"action"`
+}
+
+// scan runs the enabled checks against the creative markup and returns all violations found.
+func scan(cfg config, adm string) []violation {
+	var violations []violation
+	add := func(check string, action string, match string) {
+		for _, v := range violations {
+			if v.Check == check && v.Match == match {
+				return
+			}
+		}
+		violations = append(violations, violation{Check: check, Match: match, Action: action})
+	}
+
+	if cfg.MaxSize.Action != "" && len(adm) > cfg.MaxSize.MaxBytes {
+		add(checkMaxSize, cfg.MaxSize.Action, strconv.Itoa(len(adm)))
+	}
+
+	content := creativeContent(adm)
+	lowerContent := strings.ToLower(content)
+
+	for _, rawURL := range extractURLs(content) {
+		host := hostOf(rawURL)
+		if matchesDomain(cfg.AllowedDomains, host) != "" {
+			continue
+		}
+
+		if cfg.BlockedDomains.Action != "" {
+			if domain := matchesDomain(cfg.BlockedDomains.Patterns, host); domain != "" {
+				add(checkBlockedDomains, cfg.BlockedDomains.Action, domain)
+			}
+		}
+
+		if cfg.BlockedURLs.Action != "" {
+			lowerURL := strings.ToLower(rawURL)
+			for _, pattern := range cfg.BlockedURLs.Patterns {
+				if strings.Contains(lowerURL, strings.ToLower(pattern)) {
+					add(checkBlockedURLs, cfg.BlockedURLs.Action, pattern)
+				}
+			}
+		}
+
+		if cfg.NonSecureResources.Action != "" && len(rawURL) > 7 && strings.EqualFold(rawURL[:7], "http://") {
+			add(checkNonSecureResources, cfg.NonSecureResources.Action, rawURL)
+		}
+	}
+
+	for _, c := range []struct {
+		name  string
+		check check
+	}{
+		{checkAutoRedirect, cfg.AutoRedirect},
+		{checkForbiddenJSAPIs, cfg.ForbiddenJSAPIs},
+	} {
+		if c.check.Action == "" {
+			continue
+		}
+		for _, pattern := range c.check.Patterns {
+			if strings.Contains(lowerContent, strings.ToLower(pattern)) {
+				add(c.name, c.check.Action, pattern)
+			}
+		}
+	}
+
+	return violations
+}
+
+func hostOf(rawURL string) string {
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "https:" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// matchesDomain returns the domain the host is equal to or is a subdomain of, or an empty string if none matched.
+func matchesDomain(domains []string, host string) string {
+	if host == "" {
+		return ""
+	}
+	for _, domain := range domains {
+		d := strings.ToLower(domain)
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return domain
+		}
+	}
+	return ""
+}
+
+func shouldReject(violations []violation) bool {
+	for _, v := range violations {
+		if v.Action == actionReject {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package creativescanner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prebid/prebid-server/util/sliceutil"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+)
+
+const (
+	actionReject = "reject"
+	actionFlag   = "flag"
+)
+
+// Default auto-redirect patterns, matched case-insensitively.
+var defaultAutoRedirectPatterns = []string{
+	"top.location",
+	"window.location",
+	"document.location",
+	"location.href=",
+	"location.replace(",
+	"location.assign(",
+	`http-equiv="refresh"`,
+}
+
+// Default JavaScript APIs creatives are not allowed to call, matched case-insensitively.
+var defaultForbiddenJSAPIs = []string{
+	"document.cookie",
+	"localstorage.",
+	"navigator.geolocation",
+	"window.open(",
+}
+
+// newConfig parses the host-level module config and applies the account-level config on top of it
+// as a JSON merge patch, so accounts can change check actions. The allowed domains of the account
+// extend the host ones, instead of replacing them as the merge patch would.
+func newConfig(hostConfig json.RawMessage, accountConfig json.RawMessage) (config, error) {
+	cfg := config{
+		AutoRedirect:    check{Patterns: defaultAutoRedirectPatterns},
+		ForbiddenJSAPIs: check{Patterns: defaultForbiddenJSAPIs},
+	}
+
+	var hostAllowedDomains []string
+	data := hostConfig
+	if len(accountConfig) > 0 {
+		if len(data) == 0 {
+			data = accountConfig
+		} else {
+			var host config
+			if err := json.Unmarshal(hostConfig, &host); err != nil {
+				return cfg, fmt.Errorf("failed to parse config: %s", err)
+			}
+			hostAllowedDomains = host.AllowedDomains
+
+			var err error
+			if data, err = jsonpatch.MergePatch(data, accountConfig); err != nil {
+				return cfg, fmt.Errorf("failed to merge account config: %s", err)
+			}
+		}
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("failed to parse config: %s", err)
+		}
+	}
+	cfg.AllowedDomains = unionDomains(hostAllowedDomains, cfg.AllowedDomains)
+
+	return cfg, cfg.validate()
+}
+
+// unionDomains returns the host domains followed by the account domains which aren't host ones.
+func unionDomains(hostDomains, accountDomains []string) []string {
+	if len(hostDomains) == 0 {
+		return accountDomains
+	}
+
+	domains := hostDomains
+	for _, domain := range accountDomains {
+		if !sliceutil.ContainsStringIgnoreCase(hostDomains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+type config struct {
+	// AllowedDomains lists domains, including their subdomains, that never trigger a URL based check.
+	AllowedDomains     []string     `json:"allowed_domains"`
+	BlockedDomains     check        `json:"blocked_domains"`
+	BlockedURLs        check        `json:"blocked_urls"`
+	AutoRedirect       check        `json:"auto_redirect"`
+	ForbiddenJSAPIs    check        `json:"forbidden_js_apis"`
+	NonSecureResources check        `json:"non_secure_resources"`
+	MaxSize            maxSizeCheck `json:"max_size"`
+}
+
+// check configures a single scanning rule. The check is skipped if the action is empty.
+type check struct {
+	Action   string   `json:"action"`
+	Patterns []string `json:"patterns"`
+}
+
+type maxSizeCheck struct {
+	Action   string `json:"action"`
+	MaxBytes int    `json:"max_bytes"`
+}
+
+func (cfg config) validate() error {
+	checks := []struct {
+		name   string
+		action string
+	}{
+		{checkBlockedDomains, cfg.BlockedDomains.Action},
+		{checkBlockedURLs, cfg.BlockedURLs.Action},
+		{checkAutoRedirect, cfg.AutoRedirect.Action},
+		{checkForbiddenJSAPIs, cfg.ForbiddenJSAPIs.Action},
+		{checkNonSecureResources, cfg.NonSecureResources.Action},
+		{checkMaxSize, cfg.MaxSize.Action},
+	}
+	for _, c := range checks {
+		if c.action != "" && c.action != actionReject && c.action != actionFlag {
+			return fmt.Errorf("%s: unknown action %q", c.name, c.action)
+		}
+	}
+
+	if cfg.MaxSize.Action != "" && cfg.MaxSize.MaxBytes <= 0 {
+		return fmt.Errorf("%s: max_bytes must be positive", checkMaxSize)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package creativescanner
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var urlRegex = regexp.MustCompile(`(?i)(?:https?:)?//[^\s"'<>()\\]+`)
+
+// creativeContent returns the text of the creative markup to be scanned.
+// Native JSON and VAST XML are decoded first, so that escaped URLs and
+// HTML embedded in CDATA sections are scanned in their actual form.
+func creativeContent(adm string) string {
+	trimmed := strings.TrimSpace(adm)
+	switch {
+	case strings.HasPrefix(trimmed, "{"):
+		if content, ok := jsonContent(trimmed); ok {
+			return content
+		}
+	case strings.HasPrefix(trimmed, "<?xml") || len(trimmed) >= 5 && strings.EqualFold(trimmed[:5], "<vast"):
+		if content, ok := xmlContent(trimmed); ok {
+			return content
+		}
+	}
+	return adm
+}
+
+func jsonContent(adm string) (string, bool) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(adm), &value); err != nil {
+		return "", false
+	}
+
+	var values []string
+	collectJSONStrings(value, &values)
+	return strings.Join(values, "\n"), true
+}
+
+func collectJSONStrings(value interface{}, values *[]string) {
+	switch v := value.(type) {
+	case string:
+		*values = append(*values, v)
+	case []interface{}:
+		for _, item := range v {
+			collectJSONStrings(item, values)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			collectJSONStrings(item, values)
+		}
+	}
+}
+
+func xmlContent(adm string) (string, bool) {
+	decoder := xml.NewDecoder(strings.NewReader(adm))
+	decoder.Strict = false
+
+	var values []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			for _, attr := range t.Attr {
+				values = append(values, attr.Value)
+			}
+		case xml.CharData:
+			if text := strings.TrimSpace(string(t)); text != "" {
+				values = append(values, text)
+			}
+		}
+	}
+	return strings.Join(values, "\n"), true
+}
+
+// extractURLs returns the absolute and protocol-relative URLs found in the content.
+func extractURLs(content string) []string {
+	return urlRegex.FindAllString(content, -1)
+}
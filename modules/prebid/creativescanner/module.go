@@ -0,0 +1,103 @@
+package creativescanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/modules/moduledeps"
+)
+
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Module{hostConfig: rawConfig, cfg: cfg, accountConfigs: &sync.Map{}}, nil
+}
+
+type Module struct {
+	hostConfig json.RawMessage
+	cfg        config
+	// accountConfigs caches the host config merged with the config of each account, by account ID.
+	accountConfigs *sync.Map
+}
+
+// accountConfig is the host config merged with the raw config of an account.
+type accountConfig struct {
+	raw json.RawMessage
+	cfg config
+}
+
+// config returns the host config merged with the account config. The config of each account is merged once
+// and reused by the following bidder responses, until the account config changes and replaces it.
+func (m Module) config(miCtx hookstage.ModuleInvocationContext) (config, error) {
+	if len(miCtx.AccountConfig) == 0 {
+		return m.cfg, nil
+	}
+
+	if cached, ok := m.accountConfigs.Load(miCtx.AccountID); ok && bytes.Equal(cached.(accountConfig).raw, miCtx.AccountConfig) {
+		return cached.(accountConfig).cfg, nil
+	}
+
+	cfg, err := newConfig(m.hostConfig, miCtx.AccountConfig)
+	if err != nil {
+		return cfg, err
+	}
+	m.accountConfigs.Store(miCtx.AccountID, accountConfig{raw: miCtx.AccountConfig, cfg: cfg})
+	return cfg, nil
+}
+
+// HandleRawBidderResponseHook scans the markup of each bid and rejects bids
+// violating checks configured with the reject action.
+func (m Module) HandleRawBidderResponseHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.RawBidderResponsePayload,
+) (hookstage.HookResult[hookstage.RawBidderResponsePayload], error) {
+	result := hookstage.HookResult[hookstage.RawBidderResponsePayload]{}
+
+	cfg, err := m.config(miCtx)
+	if err != nil {
+		return result, hookexecution.NewFailure("%s", err)
+	}
+
+	result.AnalyticsTags = newScanCreativesTags()
+
+	allowedBids := make([]*adapters.TypedBid, 0, len(payload.Bids))
+	for _, bid := range payload.Bids {
+		if bid == nil || bid.Bid == nil {
+			continue
+		}
+
+		violations := scan(cfg, bid.Bid.AdM)
+		addScanResult(&result.AnalyticsTags, payload.Bidder, bid.Bid.ID, bid.Bid.ImpID, violations)
+
+		if len(violations) > 0 {
+			result.DebugMessages = append(result.DebugMessages, debugMessage(payload.Bidder, bid.Bid.ID, violations))
+		}
+		if !shouldReject(violations) {
+			allowedBids = append(allowedBids, bid)
+		}
+	}
+
+	if len(allowedBids) != len(payload.Bids) {
+		result.ChangeSet.RawBidderResponse().Bids().Update(allowedBids)
+	}
+
+	return result, nil
+}
+
+func debugMessage(bidder string, bidID string, violations []violation) string {
+	hits := make([]string, 0, len(violations))
+	for _, v := range violations {
+		hits = append(hits, fmt.Sprintf("%s: %s (%s)", v.Check, v.Match, v.Action))
+	}
+	return fmt.Sprintf("Bid %s from bidder %s has creative violations: %s", bidID, bidder, strings.Join(hits, ", "))
+}
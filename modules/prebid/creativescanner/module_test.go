@@ -0,0 +1,290 @@
+package creativescanner
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/modules/moduledeps"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = json.RawMessage(`
+{
+  "enabled": true,
+  "allowed_domains": ["trusted.com"],
+  "blocked_domains": {"action": "reject", "patterns": ["malware.com"]},
+  "blocked_urls": {"action": "flag", "patterns": ["/popunder"]},
+  "auto_redirect": {"action": "reject"},
+  "forbidden_js_apis": {"action": "flag", "patterns": ["document.cookie"]},
+  "non_secure_resources": {"action": "flag"},
+  "max_size": {"action": "reject", "max_bytes": 200}
+}`)
+
+func TestBuilder(t *testing.T) {
+	module, err := Builder(testConfig, moduledeps.ModuleDeps{})
+	assert.NoError(t, err)
+	if assert.IsType(t, Module{}, module) {
+		assert.Equal(t, testConfig, module.(Module).hostConfig)
+		assert.Equal(t, []string{"trusted.com"}, module.(Module).cfg.AllowedDomains)
+	}
+
+	module, err = Builder(json.RawMessage(`{"max_size": {"action": "reject"}}`), moduledeps.ModuleDeps{})
+	assert.EqualError(t, err, "max_size: max_bytes must be positive")
+	assert.Nil(t, module)
+
+	module, err = Builder(json.RawMessage(`{"blocked_urls": {"action": "drop"}}`), moduledeps.ModuleDeps{})
+	assert.EqualError(t, err, `blocked_urls: unknown action "drop"`)
+	assert.Nil(t, module)
+}
+
+func TestNewConfigAllowedDomains(t *testing.T) {
+	testCases := []struct {
+		description     string
+		hostConfig      json.RawMessage
+		accountConfig   json.RawMessage
+		expectedDomains []string
+	}{
+		{
+			description:     "Host allow list",
+			hostConfig:      json.RawMessage(`{"allowed_domains": ["trusted.com"]}`),
+			expectedDomains: []string{"trusted.com"},
+		},
+		{
+			description:     "Account allow list without host allow list",
+			hostConfig:      json.RawMessage(`{"max_size": {"action": "reject", "max_bytes": 200}}`),
+			accountConfig:   json.RawMessage(`{"allowed_domains": ["example.com"]}`),
+			expectedDomains: []string{"example.com"},
+		},
+		{
+			description:     "Account allow list extends the host one",
+			hostConfig:      json.RawMessage(`{"allowed_domains": ["trusted.com", "cdn.com"]}`),
+			accountConfig:   json.RawMessage(`{"allowed_domains": ["example.com", "Trusted.com"]}`),
+			expectedDomains: []string{"trusted.com", "cdn.com", "example.com"},
+		},
+		{
+			description:     "Account config without allow list",
+			hostConfig:      json.RawMessage(`{"allowed_domains": ["trusted.com"]}`),
+			accountConfig:   json.RawMessage(`{"max_size": {"action": "reject", "max_bytes": 200}}`),
+			expectedDomains: []string{"trusted.com"},
+		},
+		{
+			description:     "Account can't remove the host allow list",
+			hostConfig:      json.RawMessage(`{"allowed_domains": ["trusted.com"]}`),
+			accountConfig:   json.RawMessage(`{"allowed_domains": null}`),
+			expectedDomains: []string{"trusted.com"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg, err := newConfig(test.hostConfig, test.accountConfig)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedDomains, cfg.AllowedDomains)
+		})
+	}
+}
+
+func TestConfigCachedByAccount(t *testing.T) {
+	module := newTestModule(t)
+	rawConfig := json.RawMessage(`{"allowed_domains": ["example.com"]}`)
+
+	cfg, err := module.config(hookstage.ModuleInvocationContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, module.cfg, cfg, "bidder responses without account config use the host config")
+
+	cfg, err = module.config(hookstage.ModuleInvocationContext{AccountID: "acc", AccountConfig: rawConfig})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"trusted.com", "example.com"}, cfg.AllowedDomains)
+	cached, ok := module.accountConfigs.Load("acc")
+	assert.True(t, ok, "the merged config must be cached by account ID")
+	assert.Equal(t, accountConfig{raw: rawConfig, cfg: cfg}, cached)
+
+	updatedConfig := json.RawMessage(`{"allowed_domains": ["example.org"]}`)
+	cfg, err = module.config(hookstage.ModuleInvocationContext{AccountID: "acc", AccountConfig: updatedConfig})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"trusted.com", "example.org"}, cfg.AllowedDomains)
+	cached, _ = module.accountConfigs.Load("acc")
+	assert.Equal(t, accountConfig{raw: updatedConfig, cfg: cfg}, cached, "a changed account config must replace the cached one")
+
+	_, err = module.config(hookstage.ModuleInvocationContext{AccountID: "invalid", AccountConfig: json.RawMessage(`{"max_size": {"max_bytes": 0}}`)})
+	assert.EqualError(t, err, "max_size: max_bytes must be positive")
+	_, ok = module.accountConfigs.Load("invalid")
+	assert.False(t, ok, "invalid account configs must not be cached")
+}
+
+func newTestModule(t *testing.T) Module {
+	t.Helper()
+	module, err := Builder(testConfig, moduledeps.ModuleDeps{})
+	if err != nil {
+		t.Fatalf("failed to build the test module: %v", err)
+	}
+	return module.(Module)
+}
+
+func TestScan(t *testing.T) {
+	cfg, err := newConfig(testConfig, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testCases := []struct {
+		description        string
+		adm                string
+		expectedViolations []violation
+	}{
+		{
+			description:        "Clean HTML creative",
+			adm:                `<div><img src="https://cdn.example.com/ad.png"></div>`,
+			expectedViolations: nil,
+		},
+		{
+			description: "HTML creative with blocked subdomain and non-secure resource",
+			adm:         `<script src="http://static.malware.com/x.js"></script>`,
+			expectedViolations: []violation{
+				{Check: checkBlockedDomains, Match: "malware.com", Action: actionReject},
+				{Check: checkNonSecureResources, Match: "http://static.malware.com/x.js", Action: actionFlag},
+			},
+		},
+		{
+			description:        "Allowed domain is not checked",
+			adm:                `<img src="http://img.trusted.com/popunder.png">`,
+			expectedViolations: nil,
+		},
+		{
+			description: "Auto redirect and forbidden API",
+			adm:         `<script>var c = document.cookie; top.location = "https://example.com";</script>`,
+			expectedViolations: []violation{
+				{Check: checkAutoRedirect, Match: "top.location", Action: actionReject},
+				{Check: checkForbiddenJSAPIs, Match: "document.cookie", Action: actionFlag},
+			},
+		},
+		{
+			description: "VAST with HTML in CDATA",
+			adm:         `<VAST version="3.0"><Ad><InLine><Impression><![CDATA[https://malware.com/imp?a=1&amp;b=2]]></Impression></InLine></Ad></VAST>`,
+			expectedViolations: []violation{
+				{Check: checkBlockedDomains, Match: "malware.com", Action: actionReject},
+			},
+		},
+		{
+			description: "Native with escaped URLs",
+			adm:         `{"native":{"link":{"url":"http:\/\/ads.example.com\/popunder"}}}`,
+			expectedViolations: []violation{
+				{Check: checkBlockedURLs, Match: "/popunder", Action: actionFlag},
+				{Check: checkNonSecureResources, Match: "http://ads.example.com/popunder", Action: actionFlag},
+			},
+		},
+		{
+			description: "Oversized creative",
+			adm:         `<div>` + string(make([]byte, 200)) + `</div>`,
+			expectedViolations: []violation{
+				{Check: checkMaxSize, Match: "211", Action: actionReject},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expectedViolations, scan(cfg, test.adm))
+		})
+	}
+}
+
+func TestHandleRawBidderResponseHook(t *testing.T) {
+	cleanBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp1", AdM: `<img src="https://cdn.example.com/ad.png">`}, BidType: openrtb_ext.BidTypeBanner}
+	flaggedBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "2", ImpID: "imp2", AdM: `<img src="http://cdn.example.com/ad.png">`}, BidType: openrtb_ext.BidTypeBanner}
+	rejectedBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "3", ImpID: "imp3", AdM: `<img src="https://malware.com/ad.png">`}, BidType: openrtb_ext.BidTypeBanner}
+
+	testCases := []struct {
+		description       string
+		accountConfig     json.RawMessage
+		bids              []*adapters.TypedBid
+		expectedBids      []*adapters.TypedBid
+		expectedAnalytics hookanalytics.Analytics
+		expectedDebug     []string
+		expectedError     error
+	}{
+		{
+			description:  "Violating bid rejected, flagged bid kept",
+			bids:         []*adapters.TypedBid{cleanBid, flaggedBid, rejectedBid},
+			expectedBids: []*adapters.TypedBid{cleanBid, flaggedBid},
+			expectedAnalytics: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+				Name:   scanCreativesTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+				Results: []hookanalytics.Result{
+					{
+						Status:    hookanalytics.ResultStatusAllow,
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"1"}, ImpIds: []string{"imp1"}},
+					},
+					{
+						Status:    hookanalytics.ResultStatusAllow,
+						Values:    map[string]interface{}{"violations": []violation{{Check: checkNonSecureResources, Match: "http://cdn.example.com/ad.png", Action: actionFlag}}},
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"2"}, ImpIds: []string{"imp2"}},
+					},
+					{
+						Status:    hookanalytics.ResultStatusBlock,
+						Values:    map[string]interface{}{"violations": []violation{{Check: checkBlockedDomains, Match: "malware.com", Action: actionReject}}},
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"3"}, ImpIds: []string{"imp3"}},
+					},
+				},
+			}}},
+			expectedDebug: []string{
+				"Bid 2 from bidder appnexus has creative violations: non_secure_resources: http://cdn.example.com/ad.png (flag)",
+				"Bid 3 from bidder appnexus has creative violations: blocked_domains: malware.com (reject)",
+			},
+		},
+		{
+			description:   "Account allow list",
+			accountConfig: json.RawMessage(`{"allowed_domains": ["malware.com", "example.com"]}`),
+			bids:          []*adapters.TypedBid{flaggedBid, rejectedBid},
+			expectedBids:  []*adapters.TypedBid{flaggedBid, rejectedBid},
+			expectedAnalytics: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+				Name:   scanCreativesTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+				Results: []hookanalytics.Result{
+					{
+						Status:    hookanalytics.ResultStatusAllow,
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"2"}, ImpIds: []string{"imp2"}},
+					},
+					{
+						Status:    hookanalytics.ResultStatusAllow,
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"3"}, ImpIds: []string{"imp3"}},
+					},
+				},
+			}}},
+		},
+		{
+			description:   "Invalid account config",
+			accountConfig: json.RawMessage(`{"max_size": {"max_bytes": 0}}`),
+			bids:          []*adapters.TypedBid{cleanBid},
+			expectedError: hookexecution.NewFailure("max_size: max_bytes must be positive"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			module := newTestModule(t)
+			payload := hookstage.RawBidderResponsePayload{Bids: test.bids, Bidder: "appnexus"}
+
+			result, err := module.HandleRawBidderResponseHook(context.Background(), hookstage.ModuleInvocationContext{AccountConfig: test.accountConfig}, payload)
+			assert.Equal(t, test.expectedError, err)
+			if test.expectedError != nil {
+				return
+			}
+			assert.Equal(t, test.expectedAnalytics, result.AnalyticsTags)
+			assert.Equal(t, test.expectedDebug, result.DebugMessages)
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedBids, payload.Bids)
+		})
+	}
+}
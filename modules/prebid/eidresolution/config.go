@@ -0,0 +1,114 @@
+package eidresolution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+func newConfig(data json.RawMessage) (config, error) {
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+
+	if len(cfg.Email.Sources) == 0 && cfg.HostCookie == nil {
+		return cfg, errors.New("email.sources or host_cookie must be provided")
+	}
+
+	for i, source := range cfg.Email.Sources {
+		if source.Source == "" {
+			return cfg, fmt.Errorf("email.sources[%d].source must be provided", i)
+		}
+		if _, ok := emailHashers[source.Format]; !ok {
+			return cfg, fmt.Errorf("email.sources[%d].format %q is not supported", i, source.Format)
+		}
+	}
+
+	if hc := cfg.HostCookie; hc != nil {
+		if hc.CookieName == "" {
+			return cfg, errors.New("host_cookie.cookie_name must be provided")
+		}
+		if hc.Source == "" {
+			return cfg, errors.New("host_cookie.source must be provided")
+		}
+		if (hc.MappingFile == nil) == (hc.MappingDatabase == nil) {
+			return cfg, errors.New("exactly one of host_cookie.mapping_file or host_cookie.mapping_database must be provided")
+		}
+		if hc.MappingFile != nil {
+			if hc.MappingFile.Path == "" {
+				return cfg, errors.New("host_cookie.mapping_file.path must be provided")
+			}
+			if hc.MappingFile.RefreshRateSeconds < 0 {
+				return cfg, errors.New("host_cookie.mapping_file.refresh_rate_seconds must be positive")
+			}
+		}
+		if db := hc.MappingDatabase; db != nil {
+			if db.Driver != "mysql" && db.Driver != "postgres" {
+				return cfg, fmt.Errorf("host_cookie.mapping_database.driver must be mysql or postgres. Got %s", db.Driver)
+			}
+			if db.Query == "" {
+				return cfg, errors.New("host_cookie.mapping_database.query must be provided")
+			}
+			if db.TimeoutMS <= 0 {
+				return cfg, errors.New("host_cookie.mapping_database.timeout_ms must be positive")
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+type config struct {
+	Email      emailConfig       `json:"email"`
+	HostCookie *hostCookieConfig `json:"host_cookie"`
+}
+
+// emailConfig derives EIDs from the email supplied in user.ext.email.
+type emailConfig struct {
+	Sources []emailSource `json:"sources"`
+}
+
+// emailSource is an EID source built by hashing the normalized email.
+type emailSource struct {
+	// Source is the eids[].source of the resolved ID.
+	Source string `json:"source"`
+	// Format is the hash of the email: sha256, sha256_base64, sha1 or md5.
+	Format string `json:"format"`
+	// AType is the eids[].uids[].atype of the resolved ID. Defaults to 3 (person-based).
+	AType int64 `json:"atype"`
+	// NormalizeGmail removes the dots and the "+" suffix from the local part of gmail.com addresses.
+	NormalizeGmail bool `json:"normalize_gmail"`
+}
+
+// hostCookieConfig derives an EID by looking up the UID of the host cookie in a mapping.
+type hostCookieConfig struct {
+	// CookieName is the name of the host cookie holding the UID to look up.
+	CookieName string `json:"cookie_name"`
+	// Source is the eids[].source of the resolved ID.
+	Source string `json:"source"`
+	// AType is the eids[].uids[].atype of the resolved ID. Defaults to 1 (device-based).
+	AType           int64                  `json:"atype"`
+	MappingFile     *mappingFileConfig     `json:"mapping_file"`
+	MappingDatabase *mappingDatabaseConfig `json:"mapping_database"`
+}
+
+// mappingFileConfig is a CSV file with a host UID and the resolved ID on each line.
+type mappingFileConfig struct {
+	Path string `json:"path"`
+	// RefreshRateSeconds is how often the file is checked for changes.
+	// The file is loaded only once at startup if set to 0.
+	RefreshRateSeconds int `json:"refresh_rate_seconds"`
+}
+
+// mappingDatabaseConfig is a database queried for the ID of the host UID with the $UID parameter.
+type mappingDatabaseConfig struct {
+	Driver    string `json:"driver"`
+	Database  string `json:"dbname"`
+	Host      string `json:"host"`
+	Port      int    `json:"port"`
+	Username  string `json:"user"`
+	Password  string `json:"password"`
+	Query     string `json:"query"`
+	TimeoutMS int    `json:"timeout_ms"`
+}
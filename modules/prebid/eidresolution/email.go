@@ -0,0 +1,50 @@
+package eidresolution
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// emailHashers are the supported email ID formats. The hex formats are the usual hashed email IDs,
+// while sha256_base64 is the hashed email format of Unified ID 2.0.
+var emailHashers = map[string]func(email string) string{
+	"sha256": func(email string) string {
+		sum := sha256.Sum256([]byte(email))
+		return hex.EncodeToString(sum[:])
+	},
+	"sha256_base64": func(email string) string {
+		sum := sha256.Sum256([]byte(email))
+		return base64.StdEncoding.EncodeToString(sum[:])
+	},
+	"sha1": func(email string) string {
+		sum := sha1.Sum([]byte(email))
+		return hex.EncodeToString(sum[:])
+	},
+	"md5": func(email string) string {
+		sum := md5.Sum([]byte(email))
+		return hex.EncodeToString(sum[:])
+	},
+}
+
+// normalizeEmail trims and lowercases the email, and removes the dots and the "+" suffix of the local
+// part of gmail.com addresses if requested. It returns an empty string for invalid emails.
+func normalizeEmail(email string, normalizeGmail bool) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" || domain == "" || strings.ContainsAny(domain, "@ ") || strings.Contains(local, " ") {
+		return ""
+	}
+
+	if normalizeGmail && domain == "gmail.com" {
+		local, _, _ = strings.Cut(local, "+")
+		local = strings.ReplaceAll(local, ".", "")
+		if local == "" {
+			return ""
+		}
+	}
+	return local + "@" + domain
+}
@@ -0,0 +1,154 @@
+package eidresolution
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	pbsconfig "github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests/backends/db_provider"
+)
+
+// idMapping resolves the UID of the host cookie to the ID of the EID source.
+type idMapping interface {
+	// lookup returns the ID mapped to the host UID, or an empty string if there is none.
+	lookup(ctx context.Context, hostUID string) (string, error)
+}
+
+// fileMapping holds the mapping of a CSV file, with the host UID in the first column and the ID in the
+// second one, and swaps it for a new one whenever the file on disk is modified.
+type fileMapping struct {
+	path string
+
+	mutex   sync.RWMutex
+	ids     map[string]string
+	modTime time.Time
+}
+
+func newFileMapping(path string) (*fileMapping, error) {
+	m := &fileMapping{path: path}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *fileMapping) lookup(_ context.Context, hostUID string) (string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.ids[hostUID], nil
+}
+
+// Run reloads the mapping if the file was modified since it was last loaded.
+// The previously loaded mapping stays in use if the new file cannot be read.
+// It implements the task.Runner interface, so it can be scheduled with a task.TickerTask.
+func (m *fileMapping) Run() error {
+	if err := m.load(); err != nil {
+		glog.Errorf("EID resolution mapping file reload failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (m *fileMapping) load() error {
+	info, err := os.Stat(m.path)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.RLock()
+	unchanged := m.ids != nil && info.ModTime().Equal(m.modTime)
+	m.mutex.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	file, err := os.Open(m.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ids, err := readMapping(file)
+	if err != nil {
+		return fmt.Errorf("%s: %v", m.path, err)
+	}
+
+	m.mutex.Lock()
+	m.ids = ids
+	m.modTime = info.ModTime()
+	m.mutex.Unlock()
+	return nil
+}
+
+func readMapping(r io.Reader) (map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	ids := make(map[string]string)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record[0] != "" && record[1] != "" {
+			ids[record[0]] = record[1]
+		}
+	}
+}
+
+// databaseMapping queries a MySQL or Postgres database for the ID of the host UID.
+type databaseMapping struct {
+	provider db_provider.DbProvider
+	query    string
+	timeout  time.Duration
+}
+
+func newDatabaseMapping(cfg mappingDatabaseConfig) *databaseMapping {
+	provider := db_provider.NewDbProvider(pbsconfig.DataType("EID Resolution"), pbsconfig.DatabaseConnection{
+		Driver:   cfg.Driver,
+		Database: cfg.Database,
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.Username,
+		Password: cfg.Password,
+	})
+	return &databaseMapping{
+		provider: provider,
+		query:    cfg.Query,
+		timeout:  time.Duration(cfg.TimeoutMS) * time.Millisecond,
+	}
+}
+
+func (m *databaseMapping) lookup(ctx context.Context, hostUID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	rows, err := m.provider.QueryContext(ctx, m.query, db_provider.QueryParam{Name: "UID", Value: hostUID})
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return "", rows.Err()
+	}
+	var id sql.NullString
+	if err := rows.Scan(&id); err != nil {
+		return "", err
+	}
+	if !id.Valid {
+		return "", nil
+	}
+	return id.String, nil
+}
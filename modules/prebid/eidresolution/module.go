@@ -0,0 +1,300 @@
+package eidresolution
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	gpplib "github.com/prebid/go-gpp"
+	gppConstants "github.com/prebid/go-gpp/constants"
+	"github.com/prebid/openrtb/v17/adcom1"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	pbsconfig "github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/modules/moduledeps"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/util/task"
+)
+
+// hostUIDContextKey is the module context key of the host cookie UID read at the entrypoint stage.
+const hostUIDContextKey = "host_uid"
+
+const (
+	defaultEmailAType      = adcom1.AgentType(3)
+	defaultHostCookieAType = adcom1.AgentType(1)
+)
+
+func Builder(rawConfig json.RawMessage, deps moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if deps.GDPRPermissionsBuilder == nil || deps.TCF2ConfigBuilder == nil {
+		return nil, errors.New("the GDPR permissions builder is required")
+	}
+
+	module := Module{
+		cfg:              cfg,
+		permissionsBuild: deps.GDPRPermissionsBuilder,
+		tcf2CfgBuild:     deps.TCF2ConfigBuilder,
+		hostTCF2Config:   deps.HostTCF2Config,
+	}
+
+	if hc := cfg.HostCookie; hc != nil {
+		if hc.MappingFile != nil {
+			fm, err := newFileMapping(hc.MappingFile.Path)
+			if err != nil {
+				return nil, err
+			}
+			if hc.MappingFile.RefreshRateSeconds > 0 {
+				module.refreshTask = task.NewTickerTask(time.Duration(hc.MappingFile.RefreshRateSeconds)*time.Second, fm)
+				module.refreshTask.Start()
+			}
+			module.mapping = fm
+		} else {
+			module.mapping = newDatabaseMapping(*hc.MappingDatabase)
+		}
+	}
+
+	return module, nil
+}
+
+type Module struct {
+	cfg              config
+	mapping          idMapping
+	refreshTask      *task.TickerTask
+	permissionsBuild gdpr.PermissionsBuilder
+	tcf2CfgBuild     gdpr.TCF2ConfigBuilder
+	hostTCF2Config   pbsconfig.TCF2
+}
+
+// Shutdown stops refreshing the mapping file.
+func (m Module) Shutdown() {
+	if m.refreshTask != nil {
+		m.refreshTask.Stop()
+	}
+}
+
+// HandleEntrypointHook reads the UID of the host cookie, which isn't available at later stages,
+// and passes it to the processed auction request hook in the module context.
+func (m Module) HandleEntrypointHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	payload hookstage.EntrypointPayload,
+) (hookstage.HookResult[hookstage.EntrypointPayload], error) {
+	result := hookstage.HookResult[hookstage.EntrypointPayload]{}
+	if m.cfg.HostCookie == nil || payload.Request == nil {
+		return result, nil
+	}
+
+	if cookie, err := payload.Request.Cookie(m.cfg.HostCookie.CookieName); err == nil && cookie.Value != "" {
+		result.ModuleContext = hookstage.ModuleContext{hostUIDContextKey: cookie.Value}
+	}
+	return result, nil
+}
+
+// HandleProcessedAuctionHook derives EIDs from the email in user.ext.email and from the host cookie UID,
+// and adds them to user.eids. The email is always removed from the request, so it is never sent in clear
+// to the bidders. IDs are only derived if the user consents to the host processing their data, and the
+// added EIDs go through the same per bidder GDPR enforcement and eidpermissions rules as client-provided ones.
+func (m Module) HandleProcessedAuctionHook(
+	ctx context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	result := hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}
+	if payload.BidRequest == nil {
+		return result, nil
+	}
+
+	email, userExt, err := extractEmail(payload.BidRequest.User)
+	if err != nil {
+		result.Warnings = append(result.Warnings, "invalid user.ext: "+err.Error())
+		return result, nil
+	}
+	if userExt != nil {
+		result.ChangeSet.AddMutation(func(payload hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+			payload.BidRequest.User.Ext = userExt
+			return payload, nil
+		}, hookstage.MutationDelete, "bidrequest", "user", "ext", "email")
+	}
+
+	hostUID, _ := miCtx.ModuleContext[hostUIDContextKey].(string)
+	if email == "" && hostUID == "" {
+		return result, nil
+	}
+
+	allowed, err := m.permissions(payload.BidRequest, miCtx.AccountGDPR).HostCookiesAllowed(ctx)
+	if err != nil {
+		result.Warnings = append(result.Warnings, "EIDs not resolved: "+err.Error())
+		return result, nil
+	}
+	if !allowed {
+		result.DebugMessages = append(result.DebugMessages, "EIDs not resolved: the user doesn't consent to the host processing their data")
+		return result, nil
+	}
+
+	excluded := excludedSources(payload.BidRequest)
+	var eids []openrtb2.EID
+	if email != "" {
+		eids = append(eids, m.emailEIDs(email, excluded)...)
+	}
+	if hostUID != "" && m.mapping != nil && !excluded[m.cfg.HostCookie.Source] {
+		id, err := m.mapping.lookup(ctx, hostUID)
+		if err != nil {
+			result.Warnings = append(result.Warnings, "host cookie mapping lookup failed: "+err.Error())
+		} else if id != "" {
+			eids = append(eids, newEID(m.cfg.HostCookie.Source, id, m.cfg.HostCookie.AType, defaultHostCookieAType))
+		}
+	}
+	if len(eids) == 0 {
+		return result, nil
+	}
+
+	result.ChangeSet.AddMutation(func(payload hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+		if payload.BidRequest.User == nil {
+			payload.BidRequest.User = &openrtb2.User{}
+		}
+		payload.BidRequest.User.EIDs = append(payload.BidRequest.User.EIDs, eids...)
+		return payload, nil
+	}, hookstage.MutationUpdate, "bidrequest", "user", "eids")
+
+	return result, nil
+}
+
+func (m Module) emailEIDs(email string, excluded map[string]bool) []openrtb2.EID {
+	var eids []openrtb2.EID
+	for _, source := range m.cfg.Email.Sources {
+		if excluded[source.Source] {
+			continue
+		}
+		normalized := normalizeEmail(email, source.NormalizeGmail)
+		if normalized == "" {
+			continue
+		}
+		excluded[source.Source] = true
+		eids = append(eids, newEID(source.Source, emailHashers[source.Format](normalized), source.AType, defaultEmailAType))
+	}
+	return eids
+}
+
+// permissions builds the GDPR permissions of the request with the host TCF2 configuration
+// merged with the GDPR configuration of the account.
+func (m Module) permissions(request *openrtb2.BidRequest, accountGDPR pbsconfig.AccountGDPR) gdpr.Permissions {
+	var gpp gpplib.GppContainer
+	if request.Regs != nil && len(request.Regs.GPP) > 0 {
+		gpp, _ = gpplib.Parse(request.Regs.GPP)
+	}
+
+	requestInfo := gdpr.RequestInfo{
+		Consent:     extractConsent(request, gpp),
+		GDPRSignal:  extractGDPR(request),
+		PublisherID: publisherID(request),
+	}
+	return m.permissionsBuild(m.tcf2CfgBuild(m.hostTCF2Config, accountGDPR), requestInfo)
+}
+
+// extractEmail returns the email of user.ext.email and the user ext without it, which is nil
+// if there is no email to remove.
+func extractEmail(user *openrtb2.User) (string, json.RawMessage, error) {
+	if user == nil || len(user.Ext) == 0 {
+		return "", nil, nil
+	}
+
+	var ext map[string]json.RawMessage
+	if err := json.Unmarshal(user.Ext, &ext); err != nil {
+		return "", nil, err
+	}
+	rawEmail, ok := ext["email"]
+	if !ok {
+		return "", nil, nil
+	}
+
+	// an email which isn't a string can't be resolved, but is still removed
+	var email string
+	if err := json.Unmarshal(rawEmail, &email); err != nil {
+		email = ""
+	}
+
+	delete(ext, "email")
+	if len(ext) == 0 {
+		return email, json.RawMessage{}, nil
+	}
+	userExt, err := json.Marshal(ext)
+	if err != nil {
+		return "", nil, err
+	}
+	return email, userExt, nil
+}
+
+// excludedSources returns the EID sources which mustn't be added: those already in user.eids, and those
+// the eidpermissions rules of the request don't allow any bidder to receive.
+func excludedSources(request *openrtb2.BidRequest) map[string]bool {
+	excluded := make(map[string]bool)
+	if request.User != nil {
+		for _, eid := range request.User.EIDs {
+			excluded[eid.Source] = true
+		}
+	}
+
+	var requestExt openrtb_ext.ExtRequest
+	if len(request.Ext) > 0 && json.Unmarshal(request.Ext, &requestExt) == nil && requestExt.Prebid.Data != nil {
+		for _, permission := range requestExt.Prebid.Data.EidPermissions {
+			if len(permission.Bidders) == 0 {
+				excluded[permission.Source] = true
+			}
+		}
+	}
+	return excluded
+}
+
+func newEID(source, id string, atype int64, defaultAType adcom1.AgentType) openrtb2.EID {
+	agentType := adcom1.AgentType(atype)
+	if agentType == 0 {
+		agentType = defaultAType
+	}
+	return openrtb2.EID{
+		Source: source,
+		UIDs:   []openrtb2.UID{{ID: id, AType: agentType}},
+	}
+}
+
+func extractGDPR(request *openrtb2.BidRequest) gdpr.Signal {
+	if request.Regs != nil && len(request.Regs.GPPSID) > 0 {
+		for _, id := range request.Regs.GPPSID {
+			if id == int8(gppConstants.SectionTCFEU2) {
+				return gdpr.SignalYes
+			}
+		}
+		return gdpr.SignalNo
+	}
+	if request.Regs == nil || request.Regs.GDPR == nil {
+		return gdpr.SignalAmbiguous
+	}
+	return gdpr.Signal(*request.Regs.GDPR)
+}
+
+func extractConsent(request *openrtb2.BidRequest, gpp gpplib.GppContainer) string {
+	for i, id := range gpp.SectionTypes {
+		if id == gppConstants.SectionTCFEU2 {
+			return gpp.Sections[i].GetValue()
+		}
+	}
+	if request.User != nil {
+		return request.User.Consent
+	}
+	return ""
+}
+
+func publisherID(request *openrtb2.BidRequest) string {
+	if request.Site != nil && request.Site.Publisher != nil {
+		return request.Site.Publisher.ID
+	}
+	if request.App != nil && request.App.Publisher != nil {
+		return request.App.Publisher.ID
+	}
+	return ""
+}
@@ -0,0 +1,417 @@
+package eidresolution
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	pbsconfig "github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/modules/moduledeps"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/stored_requests/backends/db_provider"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePermissions struct {
+	hostAllowed bool
+	err         error
+}
+
+func (p fakePermissions) HostCookiesAllowed(ctx context.Context) (bool, error) {
+	return p.hostAllowed, p.err
+}
+
+func (p fakePermissions) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName) (bool, error) {
+	return true, nil
+}
+
+func (p fakePermissions) AuctionActivitiesAllowed(ctx context.Context, bidderCoreName openrtb_ext.BidderName, bidder openrtb_ext.BidderName) (gdpr.AuctionPermissions, error) {
+	return gdpr.AuctionPermissions{}, nil
+}
+
+func fakePermissionsBuilder(permissions gdpr.Permissions, requestInfo *gdpr.RequestInfo, tcf2Config *gdpr.TCF2ConfigReader) gdpr.PermissionsBuilder {
+	return func(cfg gdpr.TCF2ConfigReader, info gdpr.RequestInfo) gdpr.Permissions {
+		if requestInfo != nil {
+			*requestInfo = info
+		}
+		if tcf2Config != nil {
+			*tcf2Config = cfg
+		}
+		return permissions
+	}
+}
+
+type fakeMapping map[string]string
+
+func (m fakeMapping) lookup(_ context.Context, hostUID string) (string, error) {
+	if hostUID == "error" {
+		return "", errors.New("lookup error")
+	}
+	return m[hostUID], nil
+}
+
+func writeTestMapping(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mapping.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test mapping: %v", err)
+	}
+	return path
+}
+
+func TestBuilder(t *testing.T) {
+	path := writeTestMapping(t, "host-1,id-1\n")
+	deps := moduledeps.ModuleDeps{
+		GDPRPermissionsBuilder: fakePermissionsBuilder(fakePermissions{}, nil, nil),
+		TCF2ConfigBuilder:      gdpr.NewTCF2Config,
+	}
+
+	testCases := []struct {
+		description   string
+		config        json.RawMessage
+		deps          moduledeps.ModuleDeps
+		expectedError string
+	}{
+		{
+			description: "Valid email config",
+			config:      json.RawMessage(`{"enabled": true, "email": {"sources": [{"source": "uidapi.com", "format": "sha256_base64"}]}}`),
+			deps:        deps,
+		},
+		{
+			description: "Valid host cookie config",
+			config:      json.RawMessage(`{"host_cookie": {"cookie_name": "uid", "source": "host.com", "mapping_file": {"path": "` + path + `"}}}`),
+			deps:        deps,
+		},
+		{
+			description:   "Nothing to resolve",
+			config:        json.RawMessage(`{"enabled": true}`),
+			deps:          deps,
+			expectedError: "email.sources or host_cookie must be provided",
+		},
+		{
+			description:   "Unsupported email format",
+			config:        json.RawMessage(`{"email": {"sources": [{"source": "uidapi.com", "format": "sha512"}]}}`),
+			deps:          deps,
+			expectedError: `email.sources[0].format "sha512" is not supported`,
+		},
+		{
+			description:   "Email source without name",
+			config:        json.RawMessage(`{"email": {"sources": [{"format": "md5"}]}}`),
+			deps:          deps,
+			expectedError: "email.sources[0].source must be provided",
+		},
+		{
+			description:   "Host cookie without mapping",
+			config:        json.RawMessage(`{"host_cookie": {"cookie_name": "uid", "source": "host.com"}}`),
+			deps:          deps,
+			expectedError: "exactly one of host_cookie.mapping_file or host_cookie.mapping_database must be provided",
+		},
+		{
+			description:   "Host cookie with an invalid database",
+			config:        json.RawMessage(`{"host_cookie": {"cookie_name": "uid", "source": "host.com", "mapping_database": {"driver": "oracle"}}}`),
+			deps:          deps,
+			expectedError: "host_cookie.mapping_database.driver must be mysql or postgres. Got oracle",
+		},
+		{
+			description:   "Missing mapping file",
+			config:        json.RawMessage(`{"host_cookie": {"cookie_name": "uid", "source": "host.com", "mapping_file": {"path": "` + path + `.missing"}}}`),
+			deps:          deps,
+			expectedError: "stat " + path + ".missing: no such file or directory",
+		},
+		{
+			description:   "Malformed config",
+			config:        json.RawMessage(`{"email": 1}`),
+			deps:          deps,
+			expectedError: "failed to parse config: json: cannot unmarshal number into Go struct field config.email of type eidresolution.emailConfig",
+		},
+		{
+			description:   "Missing GDPR permissions",
+			config:        json.RawMessage(`{"email": {"sources": [{"source": "uidapi.com", "format": "sha256"}]}}`),
+			deps:          moduledeps.ModuleDeps{},
+			expectedError: "the GDPR permissions builder is required",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			module, err := Builder(test.config, test.deps)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Nil(t, module)
+			} else {
+				assert.NoError(t, err)
+				assert.IsType(t, Module{}, module)
+			}
+		})
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	path := writeTestMapping(t, "host-1,id-1\n")
+	deps := moduledeps.ModuleDeps{
+		GDPRPermissionsBuilder: fakePermissionsBuilder(fakePermissions{}, nil, nil),
+		TCF2ConfigBuilder:      gdpr.NewTCF2Config,
+	}
+
+	module, err := Builder(json.RawMessage(`{"host_cookie": {"cookie_name": "uid", "source": "host.com", "mapping_file": {"path": "`+path+`", "refresh_rate_seconds": 60}}}`), deps)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotNil(t, module.(Module).refreshTask)
+	assert.NotPanics(t, module.(Module).Shutdown)
+
+	module, err = Builder(json.RawMessage(`{"email": {"sources": [{"source": "uidapi.com", "format": "sha256"}]}}`), deps)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotPanics(t, module.(Module).Shutdown, "a module without a mapping file has nothing to stop")
+}
+
+func TestHandleEntrypointHook(t *testing.T) {
+	module := Module{cfg: config{HostCookie: &hostCookieConfig{CookieName: "uid"}}}
+
+	request := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	request.AddCookie(&http.Cookie{Name: "uid", Value: "host-1"})
+	result, err := module.HandleEntrypointHook(context.Background(), hookstage.ModuleInvocationContext{}, hookstage.EntrypointPayload{Request: request})
+	assert.NoError(t, err)
+	assert.Equal(t, hookstage.ModuleContext{hostUIDContextKey: "host-1"}, result.ModuleContext)
+
+	request = httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	result, err = module.HandleEntrypointHook(context.Background(), hookstage.ModuleInvocationContext{}, hookstage.EntrypointPayload{Request: request})
+	assert.NoError(t, err)
+	assert.Nil(t, result.ModuleContext)
+}
+
+func TestHandleProcessedAuctionHook(t *testing.T) {
+	cfg := config{
+		Email: emailConfig{Sources: []emailSource{
+			{Source: "uidapi.com", Format: "sha256_base64", NormalizeGmail: true},
+			{Source: "hashed.com", Format: "md5", AType: 2},
+		}},
+		HostCookie: &hostCookieConfig{CookieName: "uid", Source: "host.com"},
+	}
+	mapping := fakeMapping{"host-1": "id-1"}
+	gdprApplies := int8(1)
+	accountGDPREnabled := false
+	accountGDPR := pbsconfig.AccountGDPR{Enabled: &accountGDPREnabled}
+	hostTCF2Config := pbsconfig.TCF2{Enabled: true}
+
+	uid2 := newEID("uidapi.com", "kQeJgDkI2auKV7KP8K96uBuWBzVq728Y2EmesUie2Ks=", 0, defaultEmailAType)
+	md5 := newEID("hashed.com", "a84e5e815f1e0c20bb59d596eb53a1e3", 2, defaultEmailAType)
+	host := newEID("host.com", "id-1", 0, defaultHostCookieAType)
+
+	testCases := []struct {
+		description         string
+		request             *openrtb2.BidRequest
+		hostUID             string
+		permissions         fakePermissions
+		expectedUser        *openrtb2.User
+		expectedWarnings    []string
+		expectedDebug       []string
+		expectedConsent     string
+		expectedGDPRSignal  gdpr.Signal
+		expectedPublisherID string
+	}{
+		{
+			description:  "Nothing to resolve",
+			request:      &openrtb2.BidRequest{User: &openrtb2.User{ID: "user"}},
+			permissions:  fakePermissions{hostAllowed: true},
+			expectedUser: &openrtb2.User{ID: "user"},
+		},
+		{
+			description:  "Email",
+			request:      &openrtb2.BidRequest{User: &openrtb2.User{Ext: json.RawMessage(`{"email":" J.Doe+ads@Gmail.com ","data":1}`)}},
+			permissions:  fakePermissions{hostAllowed: true},
+			expectedUser: &openrtb2.User{Ext: json.RawMessage(`{"data":1}`), EIDs: []openrtb2.EID{uid2, md5}},
+		},
+		{
+			description:  "Host cookie",
+			request:      &openrtb2.BidRequest{},
+			hostUID:      "host-1",
+			permissions:  fakePermissions{hostAllowed: true},
+			expectedUser: &openrtb2.User{EIDs: []openrtb2.EID{host}},
+		},
+		{
+			description:  "Unknown host cookie",
+			request:      &openrtb2.BidRequest{},
+			hostUID:      "host-2",
+			permissions:  fakePermissions{hostAllowed: true},
+			expectedUser: nil,
+		},
+		{
+			description:      "Host cookie lookup error",
+			request:          &openrtb2.BidRequest{},
+			hostUID:          "error",
+			permissions:      fakePermissions{hostAllowed: true},
+			expectedUser:     nil,
+			expectedWarnings: []string{"host cookie mapping lookup failed: lookup error"},
+		},
+		{
+			description: "Client provided and unpermitted sources are kept out",
+			request: &openrtb2.BidRequest{
+				User: &openrtb2.User{Ext: json.RawMessage(`{"email":"j.doe@gmail.com"}`), EIDs: []openrtb2.EID{{Source: "uidapi.com"}}},
+				Ext:  json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"host.com","bidders":[]}]}}}`),
+			},
+			hostUID:      "host-1",
+			permissions:  fakePermissions{hostAllowed: true},
+			expectedUser: &openrtb2.User{Ext: json.RawMessage{}, EIDs: []openrtb2.EID{{Source: "uidapi.com"}, newEID("hashed.com", "8115b7da7fff37aeaec18779411a1042", 2, defaultEmailAType)}},
+		},
+		{
+			description: "No GDPR consent",
+			request: &openrtb2.BidRequest{
+				User: &openrtb2.User{Ext: json.RawMessage(`{"email":"j.doe@gmail.com"}`), Consent: "consent"},
+				Regs: &openrtb2.Regs{GDPR: &gdprApplies},
+				Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: "pub"}},
+			},
+			hostUID:             "host-1",
+			permissions:         fakePermissions{hostAllowed: false},
+			expectedUser:        &openrtb2.User{Ext: json.RawMessage{}, Consent: "consent"},
+			expectedDebug:       []string{"EIDs not resolved: the user doesn't consent to the host processing their data"},
+			expectedConsent:     "consent",
+			expectedGDPRSignal:  gdpr.SignalYes,
+			expectedPublisherID: "pub",
+		},
+		{
+			description:        "GDPR error",
+			request:            &openrtb2.BidRequest{},
+			hostUID:            "host-1",
+			permissions:        fakePermissions{err: errors.New("malformed consent")},
+			expectedUser:       nil,
+			expectedWarnings:   []string{"EIDs not resolved: malformed consent"},
+			expectedGDPRSignal: gdpr.SignalAmbiguous,
+		},
+		{
+			description:      "Invalid user ext",
+			request:          &openrtb2.BidRequest{User: &openrtb2.User{Ext: json.RawMessage(`[]`)}},
+			permissions:      fakePermissions{hostAllowed: true},
+			expectedUser:     &openrtb2.User{Ext: json.RawMessage(`[]`)},
+			expectedWarnings: []string{"invalid user.ext: json: cannot unmarshal array into Go value of type map[string]jsontext.Value"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			var requestInfo gdpr.RequestInfo
+			var tcf2Config gdpr.TCF2ConfigReader
+			module := Module{
+				cfg:              cfg,
+				mapping:          mapping,
+				permissionsBuild: fakePermissionsBuilder(test.permissions, &requestInfo, &tcf2Config),
+				tcf2CfgBuild:     gdpr.NewTCF2Config,
+				hostTCF2Config:   hostTCF2Config,
+			}
+			miCtx := hookstage.ModuleInvocationContext{AccountGDPR: accountGDPR}
+			if test.hostUID != "" {
+				miCtx.ModuleContext = hookstage.ModuleContext{hostUIDContextKey: test.hostUID}
+			}
+			payload := hookstage.ProcessedAuctionRequestPayload{BidRequest: test.request}
+
+			result, err := module.HandleProcessedAuctionHook(context.Background(), miCtx, payload)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedWarnings, result.Warnings)
+			assert.Equal(t, test.expectedDebug, result.DebugMessages)
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedUser, payload.BidRequest.User)
+			if tcf2Config != nil {
+				assert.Equal(t, gdpr.NewTCF2Config(hostTCF2Config, accountGDPR), tcf2Config, "the TCF2 config must merge the account GDPR config")
+			}
+			if test.expectedGDPRSignal != 0 || test.expectedConsent != "" {
+				assert.Equal(t, test.expectedConsent, requestInfo.Consent)
+				assert.Equal(t, test.expectedGDPRSignal, requestInfo.GDPRSignal)
+				assert.Equal(t, test.expectedPublisherID, requestInfo.PublisherID)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	testCases := []struct {
+		description    string
+		email          string
+		normalizeGmail bool
+		expected       string
+	}{
+		{description: "Trimmed and lowercased", email: " John.Doe@Example.COM ", expected: "john.doe@example.com"},
+		{description: "Gmail kept", email: "j.doe+ads@gmail.com", expected: "j.doe+ads@gmail.com"},
+		{description: "Gmail normalized", email: "j.doe+ads@gmail.com", normalizeGmail: true, expected: "jdoe@gmail.com"},
+		{description: "Other domains not normalized", email: "j.doe+ads@example.com", normalizeGmail: true, expected: "j.doe+ads@example.com"},
+		{description: "No domain", email: "jdoe@", expected: ""},
+		{description: "Not an email", email: "jdoe", expected: ""},
+		{description: "Spaces", email: "j doe@example.com", expected: ""},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, normalizeEmail(test.email, test.normalizeGmail), test.description)
+	}
+}
+
+func TestFileMapping(t *testing.T) {
+	path := writeTestMapping(t, "host-1,id-1\nhost-2, id-2\n")
+	mapping, err := newFileMapping(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	id, err := mapping.lookup(context.Background(), "host-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "id-2", id)
+
+	// unreadable file keeps the previous mapping
+	assert.NoError(t, os.WriteFile(path, []byte("host-1,id-1,extra\n"), 0644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	assert.Error(t, mapping.Run())
+
+	id, _ = mapping.lookup(context.Background(), "host-1")
+	assert.Equal(t, "id-1", id)
+
+	// modified file replaces the mapping
+	assert.NoError(t, os.WriteFile(path, []byte("host-1,id-3\n"), 0644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	assert.NoError(t, mapping.Run())
+
+	id, _ = mapping.lookup(context.Background(), "host-1")
+	assert.Equal(t, "id-3", id)
+	id, _ = mapping.lookup(context.Background(), "host-2")
+	assert.Equal(t, "", id)
+}
+
+func TestDatabaseMapping(t *testing.T) {
+	provider, dbMock, err := db_provider.NewDbProviderMock()
+	if !assert.NoError(t, err) {
+		return
+	}
+	mapping := &databaseMapping{provider: provider, query: "SELECT id FROM mapping WHERE uid = $UID", timeout: time.Second}
+
+	dbMock.ExpectQuery(`SELECT id FROM mapping WHERE uid = \$UID`).WithArgs("host-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("id-1"))
+	id, err := mapping.lookup(context.Background(), "host-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "id-1", id)
+
+	dbMock.ExpectQuery(`SELECT id FROM mapping WHERE uid = \$UID`).WithArgs("host-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	id, err = mapping.lookup(context.Background(), "host-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "", id)
+
+	dbMock.ExpectQuery(`SELECT id FROM mapping WHERE uid = \$UID`).WithArgs("host-3").
+		WillReturnError(errors.New("db error"))
+	_, err = mapping.lookup(context.Background(), "host-3")
+	assert.EqualError(t, err, "db error")
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,31 @@
+package geoenrichment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+func newConfig(data json.RawMessage) (config, error) {
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+
+	if cfg.DatabasePath == "" {
+		return cfg, errors.New("database_path must be provided")
+	}
+	if cfg.RefreshRateSeconds < 0 {
+		return cfg, errors.New("refresh_rate_seconds must be positive")
+	}
+
+	return cfg, nil
+}
+
+type config struct {
+	// DatabasePath is the location of the MMDB-format geo database on the local filesystem.
+	DatabasePath string `json:"database_path"`
+	// RefreshRateSeconds is how often the database file is checked for changes.
+	// The database is loaded only once at startup if set to 0.
+	RefreshRateSeconds int `json:"refresh_rate_seconds"`
+}
@@ -0,0 +1,86 @@
+package geoenrichment
+
+import (
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// database holds the currently loaded geo database and swaps it
+// for a new one whenever the file on disk is modified.
+type database struct {
+	path string
+
+	mutex   sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+}
+
+func newDatabase(path string) (*database, error) {
+	db := &database{path: path}
+	if err := db.load(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Run reloads the database if the file was modified since it was last loaded.
+// The previously loaded database stays in use if the new file cannot be read.
+// It implements the task.Runner interface, so it can be scheduled with a task.TickerTask.
+func (db *database) Run() error {
+	if err := db.load(); err != nil {
+		glog.Errorf("Geo enrichment database reload failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (db *database) load() error {
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return err
+	}
+
+	db.mutex.RLock()
+	unchanged := db.reader != nil && info.ModTime().Equal(db.modTime)
+	db.mutex.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	// the file is read in memory rather than mapped, so it can be replaced while lookups are in progress
+	buffer, err := os.ReadFile(db.path)
+	if err != nil {
+		return err
+	}
+	reader, err := maxminddb.FromBytes(buffer)
+	if err != nil {
+		return err
+	}
+
+	db.mutex.Lock()
+	db.reader = reader
+	db.modTime = info.ModTime()
+	db.mutex.Unlock()
+
+	return nil
+}
+
+// lookup resolves the IP address to a geo location, returning nil if the address is unknown.
+func (db *database) lookup(ip net.IP) (*location, error) {
+	db.mutex.RLock()
+	reader := db.reader
+	db.mutex.RUnlock()
+
+	var record cityRecord
+	_, found, err := reader.LookupNetwork(ip, &record)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return newLocation(record), nil
+}
@@ -0,0 +1,237 @@
+package geoenrichment
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// metadataStartMarker separates the search tree and data section from the database metadata.
+var metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparatorSize is the number of zero bytes between the search tree and the data section.
+const dataSectionSeparatorSize = 16
+
+// Data types of the MMDB data section used by the test databases.
+const (
+	typeExtended = 0
+	typeString   = 2
+	typeDouble   = 3
+	typeUint16   = 5
+	typeUint32   = 6
+	typeMap      = 7
+	typeArray    = 11
+	typeBool     = 14
+)
+
+// testNetwork describes a network stored in the test database.
+type testNetwork struct {
+	cidr   string
+	record map[string]interface{}
+}
+
+// buildTestMMDB writes a minimal IPv6 MMDB database with 24 bit records.
+// IPv4 networks are stored in the ::/96 subnet, the same way as in the GeoIP2 databases.
+func buildTestMMDB(t *testing.T, networks []testNetwork) []byte {
+	t.Helper()
+
+	type node struct {
+		children [2]int // 0 means empty, positive values are node indexes, negative values are data indexes
+	}
+	nodes := []node{{}}
+	var data []byte
+	var dataOffsets []int
+
+	for i, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.cidr)
+		if err != nil {
+			t.Fatalf("invalid test network %s: %v", network.cidr, err)
+		}
+		ones, bits := ipNet.Mask.Size()
+		address := ipNet.IP.To16()
+		if bits == 32 {
+			address = append(make(net.IP, 12), ipNet.IP.To4()...)
+			ones += 96
+		}
+
+		dataOffsets = append(dataOffsets, len(data))
+		data = append(data, encodeTestValue(network.record)...)
+
+		current := 0
+		for depth := 0; depth < ones; depth++ {
+			bit := (address[depth/8] >> (7 - depth%8)) & 1
+			if depth == ones-1 {
+				nodes[current].children[bit] = -(i + 1)
+				break
+			}
+			if nodes[current].children[bit] <= 0 {
+				nodes = append(nodes, node{})
+				nodes[current].children[bit] = len(nodes) - 1
+			}
+			current = nodes[current].children[bit]
+		}
+	}
+
+	nodeCount := len(nodes)
+	var buffer []byte
+	for _, n := range nodes {
+		for _, child := range n.children {
+			var record int
+			switch {
+			case child == 0:
+				record = nodeCount
+			case child > 0:
+				record = child
+			default:
+				record = nodeCount + dataSectionSeparatorSize + dataOffsets[-child-1]
+			}
+			buffer = append(buffer, byte(record>>16), byte(record>>8), byte(record))
+		}
+	}
+	buffer = append(buffer, make([]byte, dataSectionSeparatorSize)...)
+	buffer = append(buffer, data...)
+	buffer = append(buffer, metadataStartMarker...)
+	buffer = append(buffer, encodeTestValue(map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(6),
+		"database_type":               "Test-City",
+	})...)
+
+	return buffer
+}
+
+func encodeTestValue(value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		return append(encodeTestControl(typeString, len(v)), v...)
+	case uint16:
+		return append(encodeTestControl(typeUint16, 2), byte(v>>8), byte(v))
+	case uint32:
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, v)
+		return append(encodeTestControl(typeUint32, 4), b...)
+	case float64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, math.Float64bits(v))
+		return append(encodeTestControl(typeDouble, 8), b...)
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		return encodeTestControl(typeBool, size)
+	case []interface{}:
+		encoded := encodeTestControl(typeArray, len(v))
+		for _, item := range v {
+			encoded = append(encoded, encodeTestValue(item)...)
+		}
+		return encoded
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		encoded := encodeTestControl(typeMap, len(v))
+		for _, key := range keys {
+			encoded = append(encoded, encodeTestValue(key)...)
+			encoded = append(encoded, encodeTestValue(v[key])...)
+		}
+		return encoded
+	}
+	panic("unsupported test value type")
+}
+
+func encodeTestControl(dataType int, size int) []byte {
+	var extended []byte
+	typeBits := dataType
+	if dataType > 7 {
+		extended = []byte{byte(dataType - 7)}
+		typeBits = typeExtended
+	}
+
+	var sizeBits int
+	var sizeBytes []byte
+	switch {
+	case size < 29:
+		sizeBits = size
+	case size < 285:
+		sizeBits = 29
+		sizeBytes = []byte{byte(size - 29)}
+	default:
+		sizeBits = 30
+		sizeBytes = []byte{byte((size - 285) >> 8), byte(size - 285)}
+	}
+
+	encoded := []byte{byte(typeBits<<5 | sizeBits)}
+	encoded = append(encoded, extended...)
+	return append(encoded, sizeBytes...)
+}
+
+func TestDatabaseLookup(t *testing.T) {
+	db, err := newDatabase(writeTestDatabase(t, []testNetwork{
+		{cidr: "81.2.69.0/24", record: map[string]interface{}{
+			"country":      map[string]interface{}{"iso_code": "GB"},
+			"location":     map[string]interface{}{"metro_code": uint16(501)},
+			"subdivisions": []interface{}{map[string]interface{}{"iso_code": "ENG"}, map[string]interface{}{"iso_code": "WSM"}},
+		}},
+		{cidr: "2001:db8::/32", record: map[string]interface{}{"country": map[string]interface{}{"iso_code": "DE"}, "flag": true}},
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testCases := []struct {
+		description      string
+		ip               string
+		expectedLocation *location
+	}{
+		{
+			description:      "IPv4 address inside the network",
+			ip:               "81.2.69.160",
+			expectedLocation: &location{country: "GBR", region: "ENG", metro: "501"},
+		},
+		{
+			description:      "IPv4 address outside of any network",
+			ip:               "81.2.70.1",
+			expectedLocation: nil,
+		},
+		{
+			description:      "IPv6 address inside the network",
+			ip:               "2001:db8::1",
+			expectedLocation: &location{country: "DEU"},
+		},
+		{
+			description:      "IPv6 address outside of any network",
+			ip:               "2001:db9::1",
+			expectedLocation: nil,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			location, err := db.lookup(net.ParseIP(test.ip))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedLocation, location)
+		})
+	}
+}
+
+func TestDatabaseInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geo.mmdb")
+	if err := os.WriteFile(path, []byte("not a database"), 0644); err != nil {
+		t.Fatalf("failed to write test database: %v", err)
+	}
+
+	_, err := newDatabase(path)
+	assert.EqualError(t, err, "error opening database: invalid MaxMind DB file")
+}
@@ -0,0 +1,83 @@
+package geoenrichment
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+)
+
+// cityRecord is the subset of the GeoIP2/GeoLite2 City record read from the database.
+type cityRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Location struct {
+		MetroCode uint   `maxminddb:"metro_code"`
+		TimeZone  string `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+	Postal struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"postal"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+// location holds the subset of the GeoIP2/GeoLite2 City record used to fill the openrtb2.Geo object.
+type location struct {
+	country  string
+	region   string
+	metro    string
+	city     string
+	zip      string
+	timeZone string
+}
+
+func newLocation(record cityRecord) *location {
+	l := &location{
+		country:  countryAlpha2ToAlpha3[record.Country.ISOCode],
+		city:     record.City.Names["en"],
+		zip:      record.Postal.Code,
+		timeZone: record.Location.TimeZone,
+	}
+	if len(record.Subdivisions) > 0 {
+		l.region = record.Subdivisions[0].ISOCode
+	}
+	if record.Location.MetroCode > 0 {
+		l.metro = strconv.FormatUint(uint64(record.Location.MetroCode), 10)
+	}
+	return l
+}
+
+// enrich fills the geo fields missing from the request. Values provided by the client are never overwritten.
+func (l *location) enrich(geo *openrtb2.Geo, now time.Time) {
+	if geo.Country == "" {
+		geo.Country = l.country
+	}
+	if geo.Region == "" {
+		geo.Region = l.region
+	}
+	if geo.Metro == "" {
+		geo.Metro = l.metro
+	}
+	if geo.City == "" {
+		geo.City = l.city
+	}
+	if geo.ZIP == "" {
+		geo.ZIP = l.zip
+	}
+	if geo.UTCOffset == 0 && l.timeZone != "" {
+		if tz, err := time.LoadLocation(l.timeZone); err == nil {
+			_, offset := now.In(tz).Zone()
+			geo.UTCOffset = int64(offset / 60)
+		}
+	}
+}
+
+func (l *location) empty() bool {
+	return *l == location{}
+}
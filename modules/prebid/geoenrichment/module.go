@@ -0,0 +1,97 @@
+package geoenrichment
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/modules/moduledeps"
+	"github.com/prebid/prebid-server/util/iputil"
+	"github.com/prebid/prebid-server/util/task"
+)
+
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := newDatabase(cfg.DatabasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	module := Module{db: db, now: time.Now}
+	if cfg.RefreshRateSeconds > 0 {
+		module.refreshTask = task.NewTickerTask(time.Duration(cfg.RefreshRateSeconds)*time.Second, db)
+		module.refreshTask.Start()
+	}
+
+	return module, nil
+}
+
+type Module struct {
+	db          *database
+	refreshTask *task.TickerTask
+	now         func() time.Time
+}
+
+// Shutdown stops reloading the geo database.
+func (m Module) Shutdown() {
+	if m.refreshTask != nil {
+		m.refreshTask.Stop()
+	}
+}
+
+// HandleProcessedAuctionHook resolves device.ip (or device.ipv6 if the former is absent)
+// against the geo database and fills the missing device.geo fields.
+// It runs before the request is split per bidder, so the values are available
+// for GDPR defaulting and are subject to the same privacy scrubbing as client-provided ones.
+func (m Module) HandleProcessedAuctionHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	result := hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}
+	if payload.BidRequest == nil || payload.BidRequest.Device == nil {
+		return result, nil
+	}
+
+	device := payload.BidRequest.Device
+	ipAddress := device.IP
+	if ipAddress == "" {
+		ipAddress = device.IPv6
+	}
+	if ipAddress == "" {
+		return result, nil
+	}
+
+	ip, _ := iputil.ParseIP(ipAddress)
+	if ip == nil {
+		result.Warnings = append(result.Warnings, "invalid device IP address: "+ipAddress)
+		return result, nil
+	}
+
+	loc, err := m.db.lookup(ip)
+	if err != nil {
+		result.Warnings = append(result.Warnings, "geo lookup failed: "+err.Error())
+		return result, nil
+	}
+	if loc == nil || loc.empty() {
+		return result, nil
+	}
+
+	now := m.now()
+	result.ChangeSet.AddMutation(func(payload hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+		device := payload.BidRequest.Device
+		if device.Geo == nil {
+			device.Geo = &openrtb2.Geo{}
+		}
+		loc.enrich(device.Geo, now)
+		return payload, nil
+	}, hookstage.MutationUpdate, "bidrequest", "device", "geo")
+
+	return result, nil
+}
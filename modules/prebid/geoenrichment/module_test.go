@@ -0,0 +1,215 @@
+package geoenrichment
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/modules/moduledeps"
+	"github.com/stretchr/testify/assert"
+)
+
+var testNetworks = []testNetwork{
+	{
+		cidr: "81.2.69.0/24",
+		record: map[string]interface{}{
+			"city":         map[string]interface{}{"names": map[string]interface{}{"en": "London"}},
+			"country":      map[string]interface{}{"iso_code": "GB"},
+			"location":     map[string]interface{}{"time_zone": "UTC"},
+			"postal":       map[string]interface{}{"code": "SW1A"},
+			"subdivisions": []interface{}{map[string]interface{}{"iso_code": "ENG"}},
+		},
+	},
+	{
+		cidr: "2001:db8::/32",
+		record: map[string]interface{}{
+			"country":  map[string]interface{}{"iso_code": "US"},
+			"location": map[string]interface{}{"metro_code": uint16(501)},
+		},
+	},
+}
+
+func writeTestDatabase(t *testing.T, networks []testNetwork) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "geo.mmdb")
+	if err := os.WriteFile(path, buildTestMMDB(t, networks), 0644); err != nil {
+		t.Fatalf("failed to write test database: %v", err)
+	}
+	return path
+}
+
+func TestBuilder(t *testing.T) {
+	path := writeTestDatabase(t, testNetworks)
+
+	testCases := []struct {
+		description   string
+		config        json.RawMessage
+		expectedError string
+	}{
+		{
+			description: "Valid config",
+			config:      json.RawMessage(`{"enabled": true, "database_path": "` + path + `"}`),
+		},
+		{
+			description:   "Missing database path",
+			config:        json.RawMessage(`{"enabled": true}`),
+			expectedError: "database_path must be provided",
+		},
+		{
+			description:   "Negative refresh rate",
+			config:        json.RawMessage(`{"database_path": "` + path + `", "refresh_rate_seconds": -1}`),
+			expectedError: "refresh_rate_seconds must be positive",
+		},
+		{
+			description:   "Malformed config",
+			config:        json.RawMessage(`{"database_path": 1}`),
+			expectedError: "failed to parse config: json: cannot unmarshal number into Go struct field config.database_path of type string",
+		},
+		{
+			description:   "Missing database file",
+			config:        json.RawMessage(`{"database_path": "` + path + `.missing"}`),
+			expectedError: "stat " + path + ".missing: no such file or directory",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			module, err := Builder(test.config, moduledeps.ModuleDeps{})
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Nil(t, module)
+			} else {
+				assert.NoError(t, err)
+				assert.IsType(t, Module{}, module)
+			}
+		})
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	path := writeTestDatabase(t, testNetworks)
+
+	module, err := Builder(json.RawMessage(`{"database_path": "`+path+`", "refresh_rate_seconds": 60}`), moduledeps.ModuleDeps{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotNil(t, module.(Module).refreshTask)
+	assert.NotPanics(t, module.(Module).Shutdown)
+
+	module, err = Builder(json.RawMessage(`{"database_path": "`+path+`"}`), moduledeps.ModuleDeps{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotPanics(t, module.(Module).Shutdown, "a module without reloads has nothing to stop")
+}
+
+func TestHandleProcessedAuctionHook(t *testing.T) {
+	path := writeTestDatabase(t, testNetworks)
+	db, err := newDatabase(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	module := Module{db: db, now: func() time.Time { return time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC) }}
+
+	testCases := []struct {
+		description      string
+		device           *openrtb2.Device
+		expectedDevice   *openrtb2.Device
+		expectedWarnings []string
+	}{
+		{
+			description:    "No device",
+			device:         nil,
+			expectedDevice: nil,
+		},
+		{
+			description:    "No IP address",
+			device:         &openrtb2.Device{UA: "ua"},
+			expectedDevice: &openrtb2.Device{UA: "ua"},
+		},
+		{
+			description: "IPv4 address found",
+			device:      &openrtb2.Device{IP: "81.2.69.160"},
+			expectedDevice: &openrtb2.Device{
+				IP:  "81.2.69.160",
+				Geo: &openrtb2.Geo{Country: "GBR", Region: "ENG", City: "London", ZIP: "SW1A"},
+			},
+		},
+		{
+			description: "IPv6 address found",
+			device:      &openrtb2.Device{IPv6: "2001:db8::1"},
+			expectedDevice: &openrtb2.Device{
+				IPv6: "2001:db8::1",
+				Geo:  &openrtb2.Geo{Country: "USA", Metro: "501"},
+			},
+		},
+		{
+			description: "Client provided values are kept",
+			device:      &openrtb2.Device{IP: "81.2.69.160", Geo: &openrtb2.Geo{Country: "IRL", UTCOffset: 60}},
+			expectedDevice: &openrtb2.Device{
+				IP:  "81.2.69.160",
+				Geo: &openrtb2.Geo{Country: "IRL", Region: "ENG", City: "London", ZIP: "SW1A", UTCOffset: 60},
+			},
+		},
+		{
+			description:    "IP address not found",
+			device:         &openrtb2.Device{IP: "10.0.0.1"},
+			expectedDevice: &openrtb2.Device{IP: "10.0.0.1"},
+		},
+		{
+			description:      "Invalid IP address",
+			device:           &openrtb2.Device{IP: "invalid"},
+			expectedDevice:   &openrtb2.Device{IP: "invalid"},
+			expectedWarnings: []string{"invalid device IP address: invalid"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			payload := hookstage.ProcessedAuctionRequestPayload{BidRequest: &openrtb2.BidRequest{Device: test.device}}
+
+			result, err := module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedWarnings, result.Warnings)
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedDevice, payload.BidRequest.Device)
+		})
+	}
+}
+
+func TestDatabaseReload(t *testing.T) {
+	path := writeTestDatabase(t, testNetworks)
+	db, err := newDatabase(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// unreadable file keeps the previous database
+	assert.NoError(t, os.WriteFile(path, []byte("corrupted"), 0644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	assert.Error(t, db.Run())
+
+	loc, err := db.lookup([]byte{81, 2, 69, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "GBR", loc.country)
+
+	// modified file replaces the database
+	assert.NoError(t, os.WriteFile(path, buildTestMMDB(t, []testNetwork{
+		{cidr: "81.2.69.0/24", record: map[string]interface{}{"country": map[string]interface{}{"iso_code": "FR"}}},
+	}), 0644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	assert.NoError(t, db.Run())
+
+	loc, err = db.lookup([]byte{81, 2, 69, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "FRA", loc.country)
+}
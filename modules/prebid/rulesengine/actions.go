@@ -0,0 +1,111 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+)
+
+// applyAction modifies the request according to the action.
+// The exclude_bidders action doesn't modify the request and is handled by the bidder_request hook.
+func applyAction(request *openrtb2.BidRequest, a action) error {
+	switch a.Type {
+	case actionSet, actionMerge:
+		return applyFieldAction(request, a)
+	case actionAddBlocking:
+		request.BAdv = appendUnique(request.BAdv, a.BAdv)
+		request.BApp = appendUnique(request.BApp, a.BApp)
+		request.BCat = appendUnique(request.BCat, a.BCat)
+	}
+	return nil
+}
+
+// applyFieldAction sets or merges the value at the dot-separated field path, e.g. "site.ext.data".
+// Paths starting with "imp." are applied to every impression.
+// Objects are merged following the JSON merge patch rules, other values are replaced.
+func applyFieldAction(request *openrtb2.BidRequest, a action) error {
+	path := strings.Split(a.Field, ".")
+	if path[0] == "imp" && len(path) > 1 {
+		for i := range request.Imp {
+			if err := applyToField(reflect.ValueOf(&request.Imp[i]).Elem(), path[1:], a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return applyToField(reflect.ValueOf(request).Elem(), path, a)
+}
+
+// applyToField applies the action to the field of the struct named by the first key of the path.
+// Only this field is encoded and decoded again, rather than the whole request.
+func applyToField(s reflect.Value, path []string, a action) error {
+	field, ok := fieldByJSONName(s, path[0])
+	if !ok {
+		return fmt.Errorf("failed to set %s: unknown field %s", a.Field, path[0])
+	}
+
+	data, err := json.Marshal(field.Interface())
+	if err != nil {
+		return err
+	}
+	keys := path[1:]
+	if len(keys) > 0 && string(data) == "null" {
+		data = []byte("{}")
+	}
+
+	value := a.Value
+	if a.Type == actionMerge {
+		if existing, dataType, _, err := jsonparser.Get(data, keys...); err == nil && dataType == jsonparser.Object {
+			if value, err = jsonpatch.MergePatch(existing, a.Value); err != nil {
+				return fmt.Errorf("failed to merge %s: %s", a.Field, err)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		data = value
+	} else if data, err = jsonparser.Set(data, value, keys...); err != nil {
+		return fmt.Errorf("failed to set %s: %s", a.Field, err)
+	}
+
+	updated := reflect.New(field.Type())
+	if err := json.Unmarshal(data, updated.Interface()); err != nil {
+		return fmt.Errorf("failed to apply %s: %s", a.Field, err)
+	}
+	field.Set(updated.Elem())
+
+	return nil
+}
+
+// fieldByJSONName returns the field of the struct with the JSON name.
+func fieldByJSONName(s reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < s.NumField(); i++ {
+		tag, _, _ := strings.Cut(s.Type().Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return s.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func appendUnique(values []string, additions []string) []string {
+	for _, addition := range additions {
+		found := false
+		for _, v := range values {
+			if v == addition {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, addition)
+		}
+	}
+	return values
+}
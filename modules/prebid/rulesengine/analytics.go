@@ -0,0 +1,35 @@
+package rulesengine
+
+import (
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+)
+
+const ruleHitTag = "rule_hit"
+
+const (
+	ruleAnalyticKey    = "rule"
+	actionsAnalyticKey = "actions"
+)
+
+func newRuleHitTags() hookanalytics.Analytics {
+	return hookanalytics.Analytics{
+		Activities: []hookanalytics.Activity{
+			{
+				Name:   ruleHitTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+			},
+		},
+	}
+}
+
+// addRuleHitResult records the matched rule with the list of applied action types.
+func addRuleHitResult(analytics *hookanalytics.Analytics, r rule, status hookanalytics.ResultStatus, appliedTo hookanalytics.AppliedTo, actionTypes []string) {
+	analytics.Activities[0].Results = append(analytics.Activities[0].Results, hookanalytics.Result{
+		Status: status,
+		Values: map[string]interface{}{
+			ruleAnalyticKey:    r.Name,
+			actionsAnalyticKey: actionTypes,
+		},
+		AppliedTo: appliedTo,
+	})
+}
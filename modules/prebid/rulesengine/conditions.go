@@ -0,0 +1,134 @@
+package rulesengine
+
+import (
+	"strings"
+
+	"github.com/prebid/openrtb/v17/adcom1"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/prebid-server/util/sliceutil"
+)
+
+const (
+	channelSite = "site"
+	channelApp  = "app"
+	channelDOOH = "dooh"
+)
+
+const (
+	mediaTypeBanner = "banner"
+	mediaTypeVideo  = "video"
+	mediaTypeAudio  = "audio"
+	mediaTypeNative = "native"
+)
+
+// matches checks whether the request meets the conditions.
+// The bidder condition is ignored if bidder is empty, which is the case at the request-level stages.
+func (c conditions) matches(request *openrtb2.BidRequest, bidder string) bool {
+	if bidder != "" && len(c.Bidders) > 0 && !sliceutil.ContainsStringIgnoreCase(c.Bidders, bidder) {
+		return false
+	}
+
+	if len(c.Channels) > 0 && !sliceutil.ContainsStringIgnoreCase(c.Channels, channelOf(request)) {
+		return false
+	}
+
+	if len(c.Domains) > 0 && !matchesAnyDomain(c.Domains, domainsOf(request)) {
+		return false
+	}
+
+	if len(c.Bundles) > 0 && (request.App == nil || !sliceutil.ContainsStringIgnoreCase(c.Bundles, request.App.Bundle)) {
+		return false
+	}
+
+	if len(c.Countries) > 0 && !sliceutil.ContainsStringIgnoreCase(c.Countries, countryOf(request)) {
+		return false
+	}
+
+	if len(c.DeviceTypes) > 0 && (request.Device == nil || !containsDeviceType(c.DeviceTypes, request.Device.DeviceType)) {
+		return false
+	}
+
+	if len(c.MediaTypes) > 0 && !containsAnyMediaType(c.MediaTypes, request.Imp) {
+		return false
+	}
+
+	return true
+}
+
+func channelOf(request *openrtb2.BidRequest) string {
+	switch {
+	case request.Site != nil:
+		return channelSite
+	case request.App != nil:
+		return channelApp
+	case request.DOOH != nil:
+		return channelDOOH
+	}
+	return ""
+}
+
+func domainsOf(request *openrtb2.BidRequest) []string {
+	var domains []string
+	if request.Site != nil {
+		domains = append(domains, request.Site.Domain)
+		if request.Site.Publisher != nil {
+			domains = append(domains, request.Site.Publisher.Domain)
+		}
+	}
+	if request.App != nil {
+		domains = append(domains, request.App.Domain)
+		if request.App.Publisher != nil {
+			domains = append(domains, request.App.Publisher.Domain)
+		}
+	}
+	return domains
+}
+
+// matchesAnyDomain checks whether any of the request domains is equal to
+// or is a subdomain of one of the configured domains.
+func matchesAnyDomain(configured []string, domains []string) bool {
+	for _, domain := range domains {
+		if domain == "" {
+			continue
+		}
+		domain = strings.ToLower(domain)
+		for _, c := range configured {
+			c = strings.ToLower(c)
+			if domain == c || strings.HasSuffix(domain, "."+c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func countryOf(request *openrtb2.BidRequest) string {
+	if request.Device != nil && request.Device.Geo != nil && request.Device.Geo.Country != "" {
+		return request.Device.Geo.Country
+	}
+	if request.User != nil && request.User.Geo != nil {
+		return request.User.Geo.Country
+	}
+	return ""
+}
+
+func containsDeviceType(deviceTypes []int, deviceType adcom1.DeviceType) bool {
+	for _, t := range deviceTypes {
+		if adcom1.DeviceType(t) == deviceType {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAnyMediaType(mediaTypes []string, imps []openrtb2.Imp) bool {
+	for _, imp := range imps {
+		if imp.Banner != nil && sliceutil.ContainsStringIgnoreCase(mediaTypes, mediaTypeBanner) ||
+			imp.Video != nil && sliceutil.ContainsStringIgnoreCase(mediaTypes, mediaTypeVideo) ||
+			imp.Audio != nil && sliceutil.ContainsStringIgnoreCase(mediaTypes, mediaTypeAudio) ||
+			imp.Native != nil && sliceutil.ContainsStringIgnoreCase(mediaTypes, mediaTypeNative) {
+			return true
+		}
+	}
+	return false
+}
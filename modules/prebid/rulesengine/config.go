@@ -0,0 +1,126 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+)
+
+const (
+	actionExcludeBidders = "exclude_bidders"
+	actionSet            = "set"
+	actionMerge          = "merge"
+	actionAddBlocking    = "add_blocking"
+)
+
+// newConfig parses the host-level module config and applies the account-level config on top of it
+// as a JSON merge patch, so accounts can replace the rules or disable them by providing an empty list.
+func newConfig(hostConfig json.RawMessage, accountConfig json.RawMessage) (config, error) {
+	var cfg config
+
+	data := hostConfig
+	if len(accountConfig) > 0 {
+		if len(data) == 0 {
+			data = accountConfig
+		} else {
+			var err error
+			if data, err = jsonpatch.MergePatch(data, accountConfig); err != nil {
+				return cfg, fmt.Errorf("failed to merge account config: %s", err)
+			}
+		}
+	}
+
+	if len(data) == 0 {
+		return cfg, nil
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+
+	return cfg, cfg.validate()
+}
+
+type config struct {
+	Rules []rule `json:"rules"`
+}
+
+// rule applies its actions to the request when all of its conditions are met.
+type rule struct {
+	Name       string     `json:"name"`
+	Conditions conditions `json:"conditions"`
+	Actions    []action   `json:"actions"`
+}
+
+// conditions lists the criteria the request must meet. Each non-empty criterion must match
+// at least one of its values and an empty criterion matches any request.
+type conditions struct {
+	Bidders     []string `json:"bidders"`
+	Channels    []string `json:"channels"`
+	Domains     []string `json:"domains"`
+	Bundles     []string `json:"bundles"`
+	Countries   []string `json:"countries"`
+	DeviceTypes []int    `json:"device_types"`
+	MediaTypes  []string `json:"media_types"`
+}
+
+type action struct {
+	Type    string          `json:"type"`
+	Bidders []string        `json:"bidders"`
+	Field   string          `json:"field"`
+	Value   json.RawMessage `json:"value"`
+	BAdv    []string        `json:"badv"`
+	BApp    []string        `json:"bapp"`
+	BCat    []string        `json:"bcat"`
+}
+
+func (cfg config) validate() error {
+	for i, r := range cfg.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("rules[%d]: name is required", i)
+		}
+		if len(r.Actions) == 0 {
+			return fmt.Errorf("rule %s: at least one action is required", r.Name)
+		}
+		for j, a := range r.Actions {
+			if err := a.validate(); err != nil {
+				return fmt.Errorf("rule %s: actions[%d]: %s", r.Name, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (a action) validate() error {
+	switch a.Type {
+	case actionExcludeBidders:
+		if len(a.Bidders) == 0 {
+			return errors.New("bidders are required")
+		}
+	case actionSet, actionMerge:
+		if a.Field == "" {
+			return errors.New("field is required")
+		}
+		if len(a.Value) == 0 {
+			return errors.New("value is required")
+		}
+		if !json.Valid(a.Value) {
+			return errors.New("value must be valid JSON")
+		}
+	case actionAddBlocking:
+		if len(a.BAdv) == 0 && len(a.BApp) == 0 && len(a.BCat) == 0 {
+			return errors.New("at least one of badv, bapp or bcat is required")
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+	return nil
+}
+
+// requestLevel indicates the rule is applied once to the whole request,
+// rather than to the request of each bidder.
+func (r rule) requestLevel() bool {
+	return len(r.Conditions.Bidders) == 0
+}
@@ -0,0 +1,130 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	testCases := []struct {
+		description    string
+		hostConfig     json.RawMessage
+		accountConfig  json.RawMessage
+		expectedConfig config
+		expectedError  string
+	}{
+		{
+			description:    "No config",
+			expectedConfig: config{},
+		},
+		{
+			description: "Host config only",
+			hostConfig:  json.RawMessage(`{"enabled": true, "rules": [{"name": "r1", "actions": [{"type": "set", "field": "tmax", "value": 500}]}]}`),
+			expectedConfig: config{Rules: []rule{
+				{Name: "r1", Actions: []action{{Type: actionSet, Field: "tmax", Value: json.RawMessage(`500`)}}},
+			}},
+		},
+		{
+			description:   "Account config replaces host rules",
+			hostConfig:    json.RawMessage(`{"enabled": true, "rules": [{"name": "r1", "actions": [{"type": "set", "field": "tmax", "value": 500}]}]}`),
+			accountConfig: json.RawMessage(`{"rules": [{"name": "r2", "actions": [{"type": "exclude_bidders", "bidders": ["appnexus"]}]}]}`),
+			expectedConfig: config{Rules: []rule{
+				{Name: "r2", Actions: []action{{Type: actionExcludeBidders, Bidders: []string{"appnexus"}}}},
+			}},
+		},
+		{
+			description:    "Account config disables host rules",
+			hostConfig:     json.RawMessage(`{"enabled": true, "rules": [{"name": "r1", "actions": [{"type": "set", "field": "tmax", "value": 500}]}]}`),
+			accountConfig:  json.RawMessage(`{"rules": []}`),
+			expectedConfig: config{Rules: []rule{}},
+		},
+		{
+			description:   "Malformed config",
+			hostConfig:    json.RawMessage(`{"rules": {}}`),
+			expectedError: "failed to parse config: json: cannot unmarshal object into Go struct field config.rules of type []rulesengine.rule",
+		},
+		{
+			description:   "Rule without name",
+			hostConfig:    json.RawMessage(`{"rules": [{"actions": [{"type": "set", "field": "tmax", "value": 500}]}]}`),
+			expectedError: "rules[0]: name is required",
+		},
+		{
+			description:   "Rule without actions",
+			hostConfig:    json.RawMessage(`{"rules": [{"name": "r1"}]}`),
+			expectedError: "rule r1: at least one action is required",
+		},
+		{
+			description:   "Unknown action",
+			hostConfig:    json.RawMessage(`{"rules": [{"name": "r1", "actions": [{"type": "unknown"}]}]}`),
+			expectedError: `rule r1: actions[0]: unknown action type "unknown"`,
+		},
+		{
+			description:   "Exclude action without bidders",
+			hostConfig:    json.RawMessage(`{"rules": [{"name": "r1", "actions": [{"type": "exclude_bidders"}]}]}`),
+			expectedError: "rule r1: actions[0]: bidders are required",
+		},
+		{
+			description:   "Set action without field",
+			hostConfig:    json.RawMessage(`{"rules": [{"name": "r1", "actions": [{"type": "set", "value": 1}]}]}`),
+			expectedError: "rule r1: actions[0]: field is required",
+		},
+		{
+			description:   "Merge action without value",
+			hostConfig:    json.RawMessage(`{"rules": [{"name": "r1", "actions": [{"type": "merge", "field": "site.ext"}]}]}`),
+			expectedError: "rule r1: actions[0]: value is required",
+		},
+		{
+			description:   "Blocking action without attributes",
+			hostConfig:    json.RawMessage(`{"rules": [{"name": "r1", "actions": [{"type": "add_blocking"}]}]}`),
+			expectedError: "rule r1: actions[0]: at least one of badv, bapp or bcat is required",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg, err := newConfig(test.hostConfig, test.accountConfig)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedConfig, cfg)
+			}
+		})
+	}
+}
+
+func TestActionValidateValue(t *testing.T) {
+	testCases := []struct {
+		description   string
+		action        action
+		expectedError string
+	}{
+		{
+			description: "Valid set value",
+			action:      action{Type: actionSet, Field: "tmax", Value: json.RawMessage(`500`)},
+		},
+		{
+			description:   "Invalid set value",
+			action:        action{Type: actionSet, Field: "tmax", Value: json.RawMessage(`{"a":`)},
+			expectedError: "value must be valid JSON",
+		},
+		{
+			description:   "Invalid merge value",
+			action:        action{Type: actionMerge, Field: "site.ext", Value: json.RawMessage(`{"a":`)},
+			expectedError: "value must be valid JSON",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			err := test.action.validate()
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package rulesengine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/prebid/openrtb/v17/openrtb3"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/modules/moduledeps"
+	"github.com/prebid/prebid-server/util/sliceutil"
+)
+
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Module{hostConfig: rawConfig, cfg: cfg, accountConfigs: &sync.Map{}}, nil
+}
+
+type Module struct {
+	hostConfig json.RawMessage
+	cfg        config
+	// accountConfigs caches the host config merged with the config of each account, by account ID.
+	accountConfigs *sync.Map
+}
+
+// accountConfig is the host config merged with the raw config of an account.
+type accountConfig struct {
+	raw json.RawMessage
+	cfg config
+}
+
+// config returns the host config merged with the account config. The config of each account is merged once
+// and reused by the following requests, until the account config changes and replaces it.
+func (m Module) config(miCtx hookstage.ModuleInvocationContext) (config, error) {
+	if len(miCtx.AccountConfig) == 0 {
+		return m.cfg, nil
+	}
+
+	if cached, ok := m.accountConfigs.Load(miCtx.AccountID); ok && bytes.Equal(cached.(accountConfig).raw, miCtx.AccountConfig) {
+		return cached.(accountConfig).cfg, nil
+	}
+
+	cfg, err := newConfig(m.hostConfig, miCtx.AccountConfig)
+	if err != nil {
+		return cfg, err
+	}
+	m.accountConfigs.Store(miCtx.AccountID, accountConfig{raw: miCtx.AccountConfig, cfg: cfg})
+	return cfg, nil
+}
+
+// HandleProcessedAuctionHook applies the actions of the matched rules without bidder conditions to the whole request.
+func (m Module) HandleProcessedAuctionHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	result := hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}
+	if payload.BidRequest == nil {
+		return result, hookexecution.NewFailure("empty BidRequest provided")
+	}
+
+	cfg, err := m.config(miCtx)
+	if err != nil {
+		return result, hookexecution.NewFailure("%s", err)
+	}
+
+	result.AnalyticsTags = newRuleHitTags()
+	for _, r := range cfg.Rules {
+		if !r.requestLevel() || !r.Conditions.matches(payload.BidRequest, "") {
+			continue
+		}
+
+		actions := requestActions(r)
+		if len(actions) == 0 {
+			continue
+		}
+
+		result.ChangeSet.AddMutation(func(payload hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+			for _, a := range actions {
+				if err := applyAction(payload.BidRequest, a); err != nil {
+					return payload, err
+				}
+			}
+			return payload, nil
+		}, hookstage.MutationUpdate, "bidrequest")
+
+		addRuleHitResult(&result.AnalyticsTags, r, hookanalytics.ResultStatusModify, hookanalytics.AppliedTo{Request: true}, actionTypes(actions))
+	}
+
+	return result, nil
+}
+
+// HandleBidderRequestHook rejects the bidder request if any matched rule excludes the bidder,
+// otherwise it applies the actions of the matched rules with bidder conditions to the bidder request.
+func (m Module) HandleBidderRequestHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.BidderRequestPayload,
+) (hookstage.HookResult[hookstage.BidderRequestPayload], error) {
+	result := hookstage.HookResult[hookstage.BidderRequestPayload]{}
+	if payload.BidRequest == nil {
+		return result, hookexecution.NewFailure("empty BidRequest provided")
+	}
+
+	cfg, err := m.config(miCtx)
+	if err != nil {
+		return result, hookexecution.NewFailure("%s", err)
+	}
+
+	result.AnalyticsTags = newRuleHitTags()
+	appliedTo := hookanalytics.AppliedTo{Bidder: payload.Bidder}
+	for _, r := range cfg.Rules {
+		if !r.Conditions.matches(payload.BidRequest, payload.Bidder) {
+			continue
+		}
+
+		if excludesBidder(r, payload.Bidder) {
+			addRuleHitResult(&result.AnalyticsTags, r, hookanalytics.ResultStatusBlock, appliedTo, []string{actionExcludeBidders})
+			result.Reject = true
+			result.NbrCode = int(openrtb3.NoBidBlockedPublisher)
+			result.ChangeSet = hookstage.ChangeSet[hookstage.BidderRequestPayload]{}
+			result.DebugMessages = append(result.DebugMessages, "Bidder "+payload.Bidder+" excluded by rule "+r.Name)
+			return result, nil
+		}
+
+		if r.requestLevel() {
+			continue
+		}
+
+		actions := requestActions(r)
+		if len(actions) == 0 {
+			continue
+		}
+
+		result.ChangeSet.AddMutation(func(payload hookstage.BidderRequestPayload) (hookstage.BidderRequestPayload, error) {
+			for _, a := range actions {
+				if err := applyAction(payload.BidRequest, a); err != nil {
+					return payload, err
+				}
+			}
+			return payload, nil
+		}, hookstage.MutationUpdate, "bidrequest")
+
+		addRuleHitResult(&result.AnalyticsTags, r, hookanalytics.ResultStatusModify, appliedTo, actionTypes(actions))
+	}
+
+	return result, nil
+}
+
+// requestActions returns the rule actions modifying the request.
+func requestActions(r rule) []action {
+	var actions []action
+	for _, a := range r.Actions {
+		if a.Type != actionExcludeBidders {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+func excludesBidder(r rule, bidder string) bool {
+	for _, a := range r.Actions {
+		if a.Type == actionExcludeBidders && sliceutil.ContainsStringIgnoreCase(a.Bidders, bidder) {
+			return true
+		}
+	}
+	return false
+}
+
+func actionTypes(actions []action) []string {
+	types := make([]string, 0, len(actions))
+	for _, a := range actions {
+		types = append(types, a.Type)
+	}
+	return types
+}
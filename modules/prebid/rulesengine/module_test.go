@@ -0,0 +1,361 @@
+package rulesengine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v17/adcom1"
+	"github.com/prebid/openrtb/v17/openrtb2"
+	"github.com/prebid/openrtb/v17/openrtb3"
+	"github.com/prebid/prebid-server/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/hooks/hookexecution"
+	"github.com/prebid/prebid-server/hooks/hookstage"
+	"github.com/prebid/prebid-server/modules/moduledeps"
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = json.RawMessage(`
+{
+  "enabled": true,
+  "rules": [
+    {
+      "name": "no-appnexus-for-german-apps",
+      "conditions": {"channels": ["app"], "countries": ["DEU"]},
+      "actions": [{"type": "exclude_bidders", "bidders": ["appnexus"]}]
+    },
+    {
+      "name": "sports-section",
+      "conditions": {"channels": ["site"], "domains": ["sports.example.com"]},
+      "actions": [
+        {"type": "add_blocking", "bcat": ["IAB7-39"]},
+        {"type": "merge", "field": "site.ext", "value": {"data": {"section": "sports"}}}
+      ]
+    },
+    {
+      "name": "ctv-tmax",
+      "conditions": {"device_types": [3], "media_types": ["video"]},
+      "actions": [{"type": "set", "field": "tmax", "value": 1500}]
+    },
+    {
+      "name": "rubicon-video-floor",
+      "conditions": {"bidders": ["rubicon"], "media_types": ["video"]},
+      "actions": [{"type": "set", "field": "imp.bidfloor", "value": 2.5}]
+    }
+  ]
+}`)
+
+func TestBuilder(t *testing.T) {
+	module, err := Builder(testConfig, moduledeps.ModuleDeps{})
+	assert.NoError(t, err)
+	if assert.IsType(t, Module{}, module) {
+		assert.Equal(t, testConfig, module.(Module).hostConfig)
+		assert.Len(t, module.(Module).cfg.Rules, 4)
+	}
+
+	module, err = Builder(json.RawMessage(`{"rules": [{"name": "r1"}]}`), moduledeps.ModuleDeps{})
+	assert.EqualError(t, err, "rule r1: at least one action is required")
+	assert.Nil(t, module)
+}
+
+func TestHandleProcessedAuctionHook(t *testing.T) {
+	testCases := []struct {
+		description       string
+		accountConfig     json.RawMessage
+		bidRequest        *openrtb2.BidRequest
+		expectedRequest   *openrtb2.BidRequest
+		expectedAnalytics hookanalytics.Analytics
+		expectedError     error
+	}{
+		{
+			description:   "Empty request",
+			bidRequest:    nil,
+			expectedError: hookexecution.NewFailure("empty BidRequest provided"),
+		},
+		{
+			description: "No rule matched",
+			bidRequest: &openrtb2.BidRequest{
+				Site: &openrtb2.Site{Domain: "news.example.com"},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				Site: &openrtb2.Site{Domain: "news.example.com"},
+			},
+			expectedAnalytics: newRuleHitTags(),
+		},
+		{
+			description: "Request level rule matched",
+			bidRequest: &openrtb2.BidRequest{
+				BCat: []string{"IAB25"},
+				Site: &openrtb2.Site{Domain: "www.sports.example.com", Ext: json.RawMessage(`{"amp":0}`)},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				BCat: []string{"IAB25", "IAB7-39"},
+				Site: &openrtb2.Site{Domain: "www.sports.example.com", Ext: json.RawMessage(`{"amp":0,"data":{"section":"sports"}}`)},
+			},
+			expectedAnalytics: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+				Name:   ruleHitTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+				Results: []hookanalytics.Result{{
+					Status:    hookanalytics.ResultStatusModify,
+					Values:    map[string]interface{}{"rule": "sports-section", "actions": []string{"add_blocking", "merge"}},
+					AppliedTo: hookanalytics.AppliedTo{Request: true},
+				}},
+			}}},
+		},
+		{
+			description: "Bidder level rules are skipped",
+			bidRequest: &openrtb2.BidRequest{
+				Device: &openrtb2.Device{DeviceType: adcom1.DeviceTV},
+				Imp:    []openrtb2.Imp{{ID: "1", Video: &openrtb2.Video{}}},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				TMax:   1500,
+				Device: &openrtb2.Device{DeviceType: adcom1.DeviceTV},
+				Imp:    []openrtb2.Imp{{ID: "1", Video: &openrtb2.Video{}}},
+			},
+			expectedAnalytics: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+				Name:   ruleHitTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+				Results: []hookanalytics.Result{{
+					Status:    hookanalytics.ResultStatusModify,
+					Values:    map[string]interface{}{"rule": "ctv-tmax", "actions": []string{"set"}},
+					AppliedTo: hookanalytics.AppliedTo{Request: true},
+				}},
+			}}},
+		},
+		{
+			description:   "Account config overrides rules",
+			accountConfig: json.RawMessage(`{"rules": []}`),
+			bidRequest: &openrtb2.BidRequest{
+				Site: &openrtb2.Site{Domain: "sports.example.com"},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				Site: &openrtb2.Site{Domain: "sports.example.com"},
+			},
+			expectedAnalytics: newRuleHitTags(),
+		},
+		{
+			description:   "Invalid account config",
+			accountConfig: json.RawMessage(`{"rules": [{"name": "r1"}]}`),
+			bidRequest:    &openrtb2.BidRequest{},
+			expectedError: hookexecution.NewFailure("rule r1: at least one action is required"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			module := newTestModule(t)
+			payload := hookstage.ProcessedAuctionRequestPayload{BidRequest: test.bidRequest}
+
+			result, err := module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{AccountConfig: test.accountConfig}, payload)
+			assert.Equal(t, test.expectedError, err)
+			if test.expectedError != nil {
+				return
+			}
+			assert.Equal(t, test.expectedAnalytics, result.AnalyticsTags)
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedRequest, payload.BidRequest)
+		})
+	}
+}
+
+func TestHandleBidderRequestHook(t *testing.T) {
+	testCases := []struct {
+		description     string
+		bidder          string
+		bidRequest      *openrtb2.BidRequest
+		expectedRequest *openrtb2.BidRequest
+		expectedResult  hookstage.HookResult[hookstage.BidderRequestPayload]
+	}{
+		{
+			description: "Bidder excluded",
+			bidder:      "appnexus",
+			bidRequest: &openrtb2.BidRequest{
+				App:    &openrtb2.App{Bundle: "com.example"},
+				Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "DEU"}},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				App:    &openrtb2.App{Bundle: "com.example"},
+				Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "DEU"}},
+			},
+			expectedResult: hookstage.HookResult[hookstage.BidderRequestPayload]{
+				Reject:        true,
+				NbrCode:       int(openrtb3.NoBidBlockedPublisher),
+				DebugMessages: []string{"Bidder appnexus excluded by rule no-appnexus-for-german-apps"},
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   ruleHitTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status:    hookanalytics.ResultStatusBlock,
+						Values:    map[string]interface{}{"rule": "no-appnexus-for-german-apps", "actions": []string{"exclude_bidders"}},
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus"},
+					}},
+				}}},
+			},
+		},
+		{
+			description: "Other bidder not excluded",
+			bidder:      "openx",
+			bidRequest: &openrtb2.BidRequest{
+				App:    &openrtb2.App{Bundle: "com.example"},
+				Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "DEU"}},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				App:    &openrtb2.App{Bundle: "com.example"},
+				Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "DEU"}},
+			},
+			expectedResult: hookstage.HookResult[hookstage.BidderRequestPayload]{
+				AnalyticsTags: newRuleHitTags(),
+			},
+		},
+		{
+			description: "Bidder level rule applied to every imp",
+			bidder:      "rubicon",
+			bidRequest: &openrtb2.BidRequest{
+				Imp: []openrtb2.Imp{{ID: "1", Video: &openrtb2.Video{}}, {ID: "2", Banner: &openrtb2.Banner{}, BidFloor: 1}},
+			},
+			expectedRequest: &openrtb2.BidRequest{
+				Imp: []openrtb2.Imp{{ID: "1", Video: &openrtb2.Video{}, BidFloor: 2.5}, {ID: "2", Banner: &openrtb2.Banner{}, BidFloor: 2.5}},
+			},
+			expectedResult: hookstage.HookResult[hookstage.BidderRequestPayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   ruleHitTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status:    hookanalytics.ResultStatusModify,
+						Values:    map[string]interface{}{"rule": "rubicon-video-floor", "actions": []string{"set"}},
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "rubicon"},
+					}},
+				}}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			module := newTestModule(t)
+			payload := hookstage.BidderRequestPayload{BidRequest: test.bidRequest, Bidder: test.bidder}
+
+			result, err := module.HandleBidderRequestHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+			assert.NoError(t, err)
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedRequest, payload.BidRequest)
+
+			result.ChangeSet = hookstage.ChangeSet[hookstage.BidderRequestPayload]{}
+			assert.Equal(t, test.expectedResult, result)
+		})
+	}
+}
+
+func TestConfigCachedByAccount(t *testing.T) {
+	module := newTestModule(t)
+	rawConfig := json.RawMessage(`{"rules": [{"name": "r1", "actions": [{"type": "set", "field": "tmax", "value": 500}]}]}`)
+	r1 := rule{Name: "r1", Actions: []action{{Type: actionSet, Field: "tmax", Value: json.RawMessage(`500`)}}}
+
+	cfg, err := module.config(hookstage.ModuleInvocationContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, module.cfg, cfg, "requests without account config use the host config")
+
+	cfg, err = module.config(hookstage.ModuleInvocationContext{AccountID: "acc", AccountConfig: rawConfig})
+	assert.NoError(t, err)
+	assert.Equal(t, []rule{r1}, cfg.Rules)
+	cached, ok := module.accountConfigs.Load("acc")
+	assert.True(t, ok, "the merged config must be cached by account ID")
+	assert.Equal(t, accountConfig{raw: rawConfig, cfg: cfg}, cached)
+
+	updatedConfig := json.RawMessage(`{"rules": []}`)
+	cfg, err = module.config(hookstage.ModuleInvocationContext{AccountID: "acc", AccountConfig: updatedConfig})
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Rules)
+	cached, _ = module.accountConfigs.Load("acc")
+	assert.Equal(t, accountConfig{raw: updatedConfig, cfg: cfg}, cached, "a changed account config must replace the cached one")
+
+	_, err = module.config(hookstage.ModuleInvocationContext{AccountID: "invalid", AccountConfig: json.RawMessage(`{"rules": [{"name": "r1"}]}`)})
+	assert.EqualError(t, err, "rule r1: at least one action is required")
+	_, ok = module.accountConfigs.Load("invalid")
+	assert.False(t, ok, "invalid account configs must not be cached")
+}
+
+func newTestModule(t *testing.T) Module {
+	t.Helper()
+	module, err := Builder(testConfig, moduledeps.ModuleDeps{})
+	if err != nil {
+		t.Fatalf("failed to build the test module: %v", err)
+	}
+	return module.(Module)
+}
+
+func TestApplyFieldAction(t *testing.T) {
+	testCases := []struct {
+		description     string
+		request         *openrtb2.BidRequest
+		action          action
+		expectedRequest *openrtb2.BidRequest
+		expectedError   string
+	}{
+		{
+			description:     "Set top level value",
+			request:         &openrtb2.BidRequest{ID: "req", TMax: 1000},
+			action:          action{Type: actionSet, Field: "tmax", Value: json.RawMessage(`500`)},
+			expectedRequest: &openrtb2.BidRequest{ID: "req", TMax: 500},
+		},
+		{
+			description:     "Set nested value of missing object",
+			request:         &openrtb2.BidRequest{ID: "req"},
+			action:          action{Type: actionSet, Field: "site.ext.data", Value: json.RawMessage(`{"section":"sports"}`)},
+			expectedRequest: &openrtb2.BidRequest{ID: "req", Site: &openrtb2.Site{Ext: json.RawMessage(`{"data":{"section":"sports"}}`)}},
+		},
+		{
+			description:     "Merge object",
+			request:         &openrtb2.BidRequest{Site: &openrtb2.Site{Domain: "example.com", Ext: json.RawMessage(`{"amp":0,"data":{"a":1}}`)}},
+			action:          action{Type: actionMerge, Field: "site.ext.data", Value: json.RawMessage(`{"b":2}`)},
+			expectedRequest: &openrtb2.BidRequest{Site: &openrtb2.Site{Domain: "example.com", Ext: json.RawMessage(`{"amp":0,"data":{"a":1,"b":2}}`)}},
+		},
+		{
+			description:     "Merge top level object",
+			request:         &openrtb2.BidRequest{Ext: json.RawMessage(`{"a":1}`)},
+			action:          action{Type: actionMerge, Field: "ext", Value: json.RawMessage(`{"b":2}`)},
+			expectedRequest: &openrtb2.BidRequest{Ext: json.RawMessage(`{"a":1,"b":2}`)},
+		},
+		{
+			description:     "Set value of every imp",
+			request:         &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "1"}, {ID: "2", BidFloor: 1}}},
+			action:          action{Type: actionSet, Field: "imp.bidfloor", Value: json.RawMessage(`2.5`)},
+			expectedRequest: &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "1", BidFloor: 2.5}, {ID: "2", BidFloor: 2.5}}},
+		},
+		{
+			description:     "Unknown field",
+			request:         &openrtb2.BidRequest{ID: "req"},
+			action:          action{Type: actionSet, Field: "unknown.value", Value: json.RawMessage(`1`)},
+			expectedRequest: &openrtb2.BidRequest{ID: "req"},
+			expectedError:   "failed to set unknown.value: unknown field unknown",
+		},
+		{
+			description:     "Value of another type",
+			request:         &openrtb2.BidRequest{TMax: 1000},
+			action:          action{Type: actionSet, Field: "tmax", Value: json.RawMessage(`"500"`)},
+			expectedRequest: &openrtb2.BidRequest{TMax: 1000},
+			expectedError:   "failed to apply tmax: json: cannot unmarshal string into Go value of type int64",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			err := applyFieldAction(test.request, test.action)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedRequest, test.request)
+		})
+	}
+}
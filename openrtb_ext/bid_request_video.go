@@ -167,6 +167,20 @@ type PodConfig struct {
 	//  Flag indicating exact ad duration requirement. Default is false.
 	RequireExactDuration bool `json:"requireexactduration,omitempty"`
 
+	// Attribute:
+	//   categoryexclusion
+	// Type:
+	//   boolean, optional
+	//  Flag indicating a pod can't contain two ads with the same primary category. Default is false.
+	CategoryExclusion bool `json:"categoryexclusion,omitempty"`
+
+	// Attribute:
+	//   advertiserexclusion
+	// Type:
+	//   boolean, optional
+	//  Flag indicating a pod can't contain two ads from the same advertiser domain. Default is false.
+	AdvertiserExclusion bool `json:"advertiserexclusion,omitempty"`
+
 	// Attribute:
 	//   pods
 	// Type:
@@ -190,6 +204,13 @@ type Pod struct {
 	//  Duration of the adPod
 	AdPodDurationSec int `json:"adpoddurationsec"`
 
+	// Attribute:
+	//   maxads
+	// Type:
+	//   integer; optional
+	//  Maximum number of ads in the adPod. Zero means the number of ads is only limited by the duration
+	MaxAds int `json:"maxads,omitempty"`
+
 	// Attribute:
 	//   configid
 	// Type:
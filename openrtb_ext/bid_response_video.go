@@ -8,9 +8,19 @@ type BidResponseVideo struct {
 }
 
 type AdPod struct {
-	PodId     int64            `json:"podid"`
-	Targeting []VideoTargeting `json:"targeting"`
-	Errors    []string         `json:"errors"`
+	PodId        int64            `json:"podid"`
+	Targeting    []VideoTargeting `json:"targeting"`
+	Errors       []string         `json:"errors"`
+	RejectedBids []RejectedPodBid `json:"rejectedbids,omitempty"`
+}
+
+// RejectedPodBid describes a bid left out of the ad pod when choosing the combination of bids filling it
+type RejectedPodBid struct {
+	BidID    string  `json:"bidid"`
+	Seat     string  `json:"seat"`
+	Price    float64 `json:"price"`
+	Duration int     `json:"duration"`
+	Reason   string  `json:"reason"`
 }
 
 type VideoTargeting struct {
@@ -52,6 +52,7 @@ type ExtRequestPrebid struct {
 	Experiment           *Experiment               `json:"experiment,omitempty"`
 	Integration          string                    `json:"integration,omitempty"`
 	Passthrough          json.RawMessage           `json:"passthrough,omitempty"`
+	PodConfig            *ExtRequestPodConfig      `json:"podconfig,omitempty"`
 	SChains              []*ExtRequestPrebidSChain `json:"schains,omitempty"`
 	Server               *ExtRequestPrebidServer   `json:"server,omitempty"`
 	StoredRequest        *ExtStoredRequest         `json:"storedrequest,omitempty"`
@@ -71,9 +72,20 @@ type ExtRequestPrebid struct {
 	// - verbose: sets maximum level of output information
 	// - basic: excludes debugmessages and analytic_tags from output
 	// any other value or an empty string disables trace output at all.
+	// verbose also returns the timeline of the auction in bidresponse.ext.debug.trace, if debug is allowed
+	// for the account or overridden by the debug header.
 	Trace string `json:"trace,omitempty"`
 }
 
+// ExtRequestPodConfig defines the contract for bidrequest.ext.prebid.podconfig, the constraints applied to
+// the OpenRTB 2.6 dynamic video pods of the request
+type ExtRequestPodConfig struct {
+	// CategoryExclusion indicates a pod can't contain two ads with the same primary category
+	CategoryExclusion bool `json:"categoryexclusion,omitempty"`
+	// AdvertiserExclusion indicates a pod can't contain two ads from the same advertiser domain
+	AdvertiserExclusion bool `json:"advertiserexclusion,omitempty"`
+}
+
 // Experiment defines if experimental features are available for the request
 type Experiment struct {
 	AdsCert *AdsCert `json:"adscert,omitempty"`
@@ -2,6 +2,8 @@ package openrtb_ext
 
 import (
 	"encoding/json"
+
+	"github.com/prebid/openrtb/v17/adcom1"
 )
 
 // ExtBidResponse defines the contract for bidresponse.ext
@@ -28,6 +30,39 @@ type ExtResponseDebug struct {
 	HttpCalls map[BidderName][]*ExtHttpCall `json:"httpcalls,omitempty"`
 	// Request after resolution of stored requests and debug overrides
 	ResolvedRequest json.RawMessage `json:"resolvedrequest,omitempty"`
+	// Tmax defines the contract for bidresponse.ext.debug.tmax
+	Tmax map[BidderName]*ExtBidderTmax `json:"tmax,omitempty"`
+	// Trace defines the contract for bidresponse.ext.debug.trace, the timeline of the auction
+	Trace []ExtTraceEvent `json:"trace,omitempty"`
+}
+
+// ExtTraceEvent defines the contract for an entry of bidresponse.ext.debug.trace, a step of the auction
+type ExtTraceEvent struct {
+	// Step is what was done: "stored_request_fetch", "account_fetch", "privacy_enforcement", "bidder_request",
+	// "bid_validation", "currency_conversion", "category_mapping" or "cache_write"
+	Step string `json:"step"`
+	// Bidder is the bidder the step was done for, empty if it was done for the whole auction
+	Bidder string `json:"bidder,omitempty"`
+	// StartMillis is the time the step started at, since the request was received
+	StartMillis float64 `json:"startms"`
+	// DurationMillis is the time the step took
+	DurationMillis float64 `json:"durationms"`
+	// Details describe the outcome of the step
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// ExtBidderTmax defines the contract for bidresponse.ext.debug.tmax.{bidder}, the timeout given to a bidder
+type ExtBidderTmax struct {
+	// Tmax is the tmax sent to the bidder, 0 if it wasn't called
+	Tmax int64 `json:"tmax"`
+	// TimeoutMillis is the time after which the bidder was cut off
+	TimeoutMillis int64 `json:"timeoutms"`
+	// LatencyMillis is the latency percentile of the recent responses of the bidder, 0 if there aren't enough of them
+	LatencyMillis int64 `json:"latencyms,omitempty"`
+	// LatencySamples is the number of recent responses of the bidder
+	LatencySamples int `json:"latencysamples"`
+	// Reason is what the timeout was derived from: "auction", "latency" or "skipped"
+	Reason string `json:"reason"`
 }
 
 // ExtResponseSyncData defines the contract for bidresponse.ext.usersync.{bidder}
@@ -39,10 +74,32 @@ type ExtResponseSyncData struct {
 
 // ExtResponsePrebid defines the contract for bidresponse.ext.prebid
 type ExtResponsePrebid struct {
-	AuctionTimestamp int64           `json:"auctiontimestamp,omitempty"`
-	Passthrough      json.RawMessage `json:"passthrough,omitempty"`
-	Modules          json.RawMessage `json:"modules,omitempty"`
-	Fledge           *Fledge         `json:"fledge,omitempty"`
+	AuctionTimestamp int64              `json:"auctiontimestamp,omitempty"`
+	Passthrough      json.RawMessage    `json:"passthrough,omitempty"`
+	Modules          json.RawMessage    `json:"modules,omitempty"`
+	Fledge           *Fledge            `json:"fledge,omitempty"`
+	AdPods           []ExtResponseAdPod `json:"adpods,omitempty"`
+}
+
+// ExtResponseAdPod defines the contract for bidresponse.ext.prebid.adpods[]
+type ExtResponseAdPod struct {
+	PodID int64 `json:"podid,omitempty"`
+	// ImpID is set for dynamic pods, which are filled from a single imp
+	ImpID    string `json:"impid,omitempty"`
+	Dynamic  bool   `json:"dynamic,omitempty"`
+	Duration int    `json:"duration"`
+	// Bids are listed in the order they play
+	Bids []ExtResponseAdPodBid `json:"bids"`
+}
+
+// ExtResponseAdPodBid defines the contract for bidresponse.ext.prebid.adpods[].bids[]
+type ExtResponseAdPodBid struct {
+	BidID     string                   `json:"bidid"`
+	ImpID     string                   `json:"impid"`
+	Seat      string                   `json:"seat"`
+	Sequence  int                      `json:"sequence"`
+	Duration  int                      `json:"duration"`
+	SlotInPod adcom1.SlotPositionInPod `json:"slotinpod,omitempty"`
 }
 
 // FledgeResponse defines the contract for bidresponse.ext.fledge
@@ -0,0 +1,151 @@
+package prebid_cache_client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// batcher coalesces the puts of concurrent auctions into batched requests to Prebid Cache.
+type batcher struct {
+	maxPuts int
+	maxWait time.Duration
+	puts    chan *pendingPut
+	send    func(ctx context.Context, values []Cacheable) ([]string, []putError)
+	done    chan struct{}
+}
+
+// pendingPut is a PutJson call waiting for its batch to be sent.
+type pendingPut struct {
+	ctx    context.Context
+	values []Cacheable
+	result chan putResult
+}
+
+type putResult struct {
+	uuids []string
+	errs  []error
+}
+
+func newBatcher(cfg config.CacheBatch, send func(ctx context.Context, values []Cacheable) ([]string, []putError)) *batcher {
+	b := &batcher{
+		maxPuts: cfg.MaxPuts,
+		maxWait: time.Duration(cfg.MaxWaitMillis) * time.Millisecond,
+		puts:    make(chan *pendingPut),
+		send:    send,
+		done:    make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// put queues the values for the next batch and waits for the batch to be sent.
+func (b *batcher) put(ctx context.Context, values []Cacheable) ([]string, []error) {
+	p := &pendingPut{ctx: ctx, values: values, result: make(chan putResult, 1)}
+
+	select {
+	case b.puts <- p:
+	case <-b.done:
+		b.flush([]*pendingPut{p})
+	case <-ctx.Done():
+		return make([]string, len(values)), []error{canceledPutError(ctx)}
+	}
+
+	select {
+	case r := <-p.result:
+		return r.uuids, r.errs
+	case <-ctx.Done():
+		return make([]string, len(values)), []error{canceledPutError(ctx)}
+	}
+}
+
+// shutdown stops batching. The puts still waiting are sent, and the following puts are sent on their own.
+func (b *batcher) shutdown() {
+	close(b.done)
+}
+
+// run collects puts until the batch is full or the oldest put waited for maxWait, then sends the batch.
+func (b *batcher) run() {
+	var pending []*pendingPut
+	var timeout <-chan time.Time
+	count := 0
+
+	for {
+		select {
+		case <-b.done:
+			if len(pending) > 0 {
+				go b.flush(pending)
+			}
+			return
+		case p := <-b.puts:
+			pending = append(pending, p)
+			count += len(p.values)
+			if len(pending) == 1 {
+				timeout = time.After(b.maxWait)
+			}
+			if count < b.maxPuts {
+				continue
+			}
+		case <-timeout:
+		}
+
+		go b.flush(pending)
+		pending, timeout, count = nil, nil, 0
+	}
+}
+
+// flush sends the values of all the puts still waiting in a single request, with the deadline of the
+// put which can wait the longest. A put with an earlier deadline stops waiting for the batch when it
+// expires. Each put gets back the uuids of its own values, and the errors concerning them.
+func (b *batcher) flush(pending []*pendingPut) {
+	var values []Cacheable
+	var deadline time.Time
+	hasDeadline := true
+	waiting := pending[:0]
+	for _, p := range pending {
+		if p.ctx.Err() != nil {
+			continue
+		}
+		if d, ok := p.ctx.Deadline(); !ok {
+			hasDeadline = false
+		} else if d.After(deadline) {
+			deadline = d
+		}
+		values = append(values, p.values...)
+		waiting = append(waiting, p)
+	}
+	if len(waiting) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	if hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	uuids, putErrs := b.send(ctx, values)
+
+	offset := 0
+	for _, p := range waiting {
+		end := offset + len(p.values)
+		var errs []error
+		for _, putErr := range putErrs {
+			if putErr.index < 0 || (putErr.index >= offset && putErr.index < end) {
+				errs = append(errs, putErr.err)
+			}
+		}
+		p.result <- putResult{uuids: uuids[offset:end], errs: errs}
+		offset = end
+	}
+}
+
+func canceledPutError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.New("Prebid Cache put timed out before the batch was sent")
+	}
+	return errors.New("Prebid Cache put was canceled before the batch was sent")
+}
@@ -0,0 +1,143 @@
+package prebid_cache_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatcherCoalescesPuts(t *testing.T) {
+	var mutex sync.Mutex
+	var batches [][]Cacheable
+	send := func(ctx context.Context, values []Cacheable) ([]string, []putError) {
+		mutex.Lock()
+		batches = append(batches, values)
+		mutex.Unlock()
+		uuids := make([]string, len(values))
+		var errs []putError
+		for i, value := range values {
+			uuids[i] = string(value.Data)
+			if string(value.Data) == "3" {
+				errs = append(errs, putError{index: i, err: errors.New("value error")})
+			}
+		}
+		return uuids, append(errs, putError{index: -1, err: errors.New("batch error")})
+	}
+	b := newBatcher(config.CacheBatch{Enabled: true, MaxPuts: 3, MaxWaitMillis: 1000}, send)
+
+	var wg sync.WaitGroup
+	results := make([][]string, 2)
+	resultErrs := make([][]error, 2)
+	putValues := [][]Cacheable{
+		{{Type: TypeJSON, Data: json.RawMessage("1")}},
+		{{Type: TypeJSON, Data: json.RawMessage("2")}, {Type: TypeJSON, Data: json.RawMessage("3")}},
+	}
+	for i := range putValues {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], resultErrs[i] = b.put(context.Background(), putValues[i])
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, batches, 1, "the puts must be sent in a single batch once max_puts is reached")
+	assert.Len(t, batches[0], 3)
+	assert.Equal(t, []string{"1"}, results[0])
+	assert.Equal(t, []string{"2", "3"}, results[1])
+	assert.Equal(t, []error{errors.New("batch error")}, resultErrs[0], "a put gets the errors of the batch")
+	assert.Equal(t, []error{errors.New("value error"), errors.New("batch error")}, resultErrs[1], "a put gets the errors of its own values")
+}
+
+func TestBatcherSendsAfterMaxWait(t *testing.T) {
+	send := func(ctx context.Context, values []Cacheable) ([]string, []putError) {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline, "the batch must use the deadline of its puts")
+		return []string{"uuid"}, nil
+	}
+	b := newBatcher(config.CacheBatch{Enabled: true, MaxPuts: 10, MaxWaitMillis: 1}, send)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	uuids, errs := b.put(ctx, []Cacheable{{Type: TypeJSON, Data: json.RawMessage("true")}})
+
+	assert.Equal(t, []string{"uuid"}, uuids)
+	assert.Empty(t, errs)
+}
+
+func TestBatcherShutdown(t *testing.T) {
+	var mutex sync.Mutex
+	var batches [][]Cacheable
+	send := func(ctx context.Context, values []Cacheable) ([]string, []putError) {
+		mutex.Lock()
+		batches = append(batches, values)
+		mutex.Unlock()
+		return make([]string, len(values)), nil
+	}
+	b := newBatcher(config.CacheBatch{Enabled: true, MaxPuts: 10, MaxWaitMillis: 60000}, send)
+
+	result := make(chan []string, 1)
+	go func() {
+		uuids, _ := b.put(context.Background(), []Cacheable{{Type: TypeJSON, Data: json.RawMessage("1")}})
+		result <- uuids
+	}()
+	b.shutdown()
+	select {
+	case uuids := <-result:
+		assert.Len(t, uuids, 1, "the batched puts must be sent on shutdown")
+	case <-time.After(time.Second):
+		t.Fatal("the batched put wasn't sent on shutdown")
+	}
+
+	uuids, errs := b.put(context.Background(), []Cacheable{{Type: TypeJSON, Data: json.RawMessage("2")}, {Type: TypeJSON, Data: json.RawMessage("3")}})
+	assert.Len(t, uuids, 2, "the puts after shutdown must be sent on their own")
+	assert.Empty(t, errs)
+	mutex.Lock()
+	assert.Len(t, batches, 2)
+	mutex.Unlock()
+}
+
+func TestBatcherUsesLatestDeadline(t *testing.T) {
+	deadlines := make(chan time.Time, 1)
+	send := func(ctx context.Context, values []Cacheable) ([]string, []putError) {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		return make([]string, len(values)), nil
+	}
+	b := newBatcher(config.CacheBatch{Enabled: true, MaxPuts: 2, MaxWaitMillis: 1000}, send)
+
+	early, cancelEarly := context.WithTimeout(context.Background(), time.Second)
+	defer cancelEarly()
+	late, cancelLate := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelLate()
+	lateDeadline, _ := late.Deadline()
+
+	var wg sync.WaitGroup
+	for _, ctx := range []context.Context{early, late} {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			b.put(ctx, []Cacheable{{Type: TypeJSON, Data: json.RawMessage("true")}})
+		}(ctx)
+	}
+	wg.Wait()
+
+	assert.Equal(t, lateDeadline, <-deadlines, "the batch must wait for the put which can wait the longest")
+}
+
+func TestBatcherCanceledPut(t *testing.T) {
+	b := &batcher{puts: make(chan *pendingPut)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	uuids, errs := b.put(ctx, []Cacheable{{Type: TypeJSON, Data: json.RawMessage("true")}})
+
+	assert.Equal(t, []string{""}, uuids)
+	assert.Equal(t, []error{errors.New("Prebid Cache put was canceled before the batch was sent")}, errs)
+}
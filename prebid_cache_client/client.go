@@ -7,16 +7,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/metrics"
 
 	"github.com/buger/jsonparser"
-	"github.com/golang/glog"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -52,13 +53,32 @@ type Cacheable struct {
 }
 
 func NewClient(httpClient *http.Client, conf *config.Cache, extCache *config.ExternalCache, metrics metrics.MetricsEngine) Client {
-	return &clientImpl{
+	c := &clientImpl{
 		httpClient:          httpClient,
 		putUrl:              conf.GetBaseURL() + "/cache",
 		externalCacheScheme: extCache.Scheme,
 		externalCacheHost:   extCache.Host,
 		externalCachePath:   extCache.Path,
 		metrics:             metrics,
+		retry:               conf.Retry,
+		jitter:              randomJitter,
+	}
+	if conf.Batch.Enabled {
+		c.batcher = newBatcher(conf.Batch, c.send)
+	}
+	return c
+}
+
+// Shutdowner is implemented by the clients running background work,
+// which must be stopped when the server shuts down.
+type Shutdowner interface {
+	Shutdown()
+}
+
+// Shutdown stops batching the puts, after sending the ones already batched.
+func (c *clientImpl) Shutdown() {
+	if c.batcher != nil {
+		c.batcher.shutdown()
 	}
 }
 
@@ -69,10 +89,17 @@ type clientImpl struct {
 	externalCacheHost   string
 	externalCachePath   string
 	metrics             metrics.MetricsEngine
+	retry               config.CacheRetry
+	jitter              func(max time.Duration) time.Duration
+	batcher             *batcher
 }
 
 func (c *clientImpl) GetExtCacheData() (string, string, string) {
-	path := c.externalCachePath
+	return c.externalCacheScheme, c.externalCacheHost, normalizeExtCachePath(c.externalCachePath)
+}
+
+// normalizeExtCachePath makes sure the external cache path is either empty or starts with a slash
+func normalizeExtCachePath(path string) string {
 	if path == "/" {
 		// Only the slash for the path, remove it to empty
 		path = ""
@@ -80,28 +107,96 @@ func (c *clientImpl) GetExtCacheData() (string, string, string) {
 		// Path defined but does not start with "/", prepend it
 		path = "/" + path
 	}
-
-	return c.externalCacheScheme, c.externalCacheHost, path
+	return path
 }
 
 func (c *clientImpl) PutJson(ctx context.Context, values []Cacheable) (uuids []string, errs []error) {
-	errs = make([]error, 0, 1)
 	if len(values) < 1 {
-		return nil, errs
+		return nil, make([]error, 0, 1)
+	}
+	if c.batcher != nil {
+		return c.batcher.put(ctx, values)
+	}
+	return c.put(ctx, values)
+}
+
+// put sends the values to Prebid Cache in a single request, retrying failures while the context allows it
+func (c *clientImpl) put(ctx context.Context, values []Cacheable) (uuids []string, errs []error) {
+	uuids, putErrs := c.send(ctx, values)
+	errs = make([]error, 0, len(putErrs))
+	for _, putErr := range putErrs {
+		errs = append(errs, putErr.err)
 	}
+	return uuids, errs
+}
+
+// putError is an error of a request to Prebid Cache. Index is the position of the value it concerns,
+// or -1 if it concerns all the values of the request.
+type putError struct {
+	index int
+	err   error
+}
 
+// send does the work of put, keeping the position of the value each error concerns.
+func (c *clientImpl) send(ctx context.Context, values []Cacheable) (uuids []string, errs []putError) {
 	uuidsToReturn := make([]string, len(values))
 
 	postBody, err := encodeValues(values)
 	if err != nil {
-		logError(&errs, "Error creating JSON for prebid cache: %v", err)
+		logPutError(ctx, &errs, -1, "Error creating JSON for prebid cache: %v", err)
 		return uuidsToReturn, errs
 	}
 
+	var responseBody []byte
+	for attempt := 0; ; attempt++ {
+		var retryable bool
+		responseBody, retryable, err = c.post(ctx, postBody, len(values))
+		if err == nil {
+			break
+		}
+		if !retryable || attempt >= c.retry.MaxRetries || !c.waitToRetry(ctx) {
+			logPutError(ctx, &errs, -1, "%v", err)
+			return uuidsToReturn, errs
+		}
+		logger.FromContext(ctx).Warnf("Retrying Prebid Cache request after error: %v", err)
+	}
+
+	currentIndex := 0
+	processResponse := func(uuidObj []byte, _ jsonparser.ValueType, _ int, err error) {
+		if currentIndex >= len(uuidsToReturn) {
+			if currentIndex == len(uuidsToReturn) {
+				logPutError(ctx, &errs, -1, "Prebid Cache returned more responses than the %d values sent. Response body was: %s", len(values), string(responseBody))
+			}
+			currentIndex++
+			return
+		}
+		if uuid, valueType, _, err := jsonparser.Get(uuidObj, "uuid"); err != nil {
+			logPutError(ctx, &errs, currentIndex, "Prebid Cache returned a bad value at index %d. Error was: %v. Response body was: %s", currentIndex, err, string(responseBody))
+		} else if valueType != jsonparser.String {
+			logPutError(ctx, &errs, currentIndex, "Prebid Cache returned a %v at index %d in: %v", valueType, currentIndex, string(responseBody))
+		} else {
+			if uuidsToReturn[currentIndex], err = jsonparser.ParseString(uuid); err != nil {
+				logPutError(ctx, &errs, currentIndex, "Prebid Cache response index %d could not be parsed as string: %v", currentIndex, err)
+				uuidsToReturn[currentIndex] = ""
+			}
+		}
+		currentIndex++
+	}
+
+	if _, err := jsonparser.ArrayEach(responseBody, processResponse, "responses"); err != nil {
+		logPutError(ctx, &errs, -1, "Error interpreting Prebid Cache response: %v\nResponse was: %s", err, string(responseBody))
+		return uuidsToReturn, errs
+	}
+
+	return uuidsToReturn, errs
+}
+
+// post sends one request to Prebid Cache and returns the response body. Network errors and server
+// errors are flagged as retryable.
+func (c *clientImpl) post(ctx context.Context, postBody []byte, items int) (responseBody []byte, retryable bool, err error) {
 	httpReq, err := http.NewRequest("POST", c.putUrl, bytes.NewReader(postBody))
 	if err != nil {
-		logError(&errs, "Error creating POST request to prebid cache: %v", err)
-		return uuidsToReturn, errs
+		return nil, false, fmt.Errorf("Error creating POST request to prebid cache: %v", err)
 	}
 
 	httpReq.Header.Add("Content-Type", "application/json;charset=utf-8")
@@ -112,47 +207,55 @@ func (c *clientImpl) PutJson(ctx context.Context, values []Cacheable) (uuids []s
 	elapsedTime := time.Since(startTime)
 	if err != nil {
 		c.metrics.RecordPrebidCacheRequestTime(false, elapsedTime)
-		logError(&errs, "Error sending the request to Prebid Cache: %v; Duration=%v, Items=%v, Payload Size=%v", err, elapsedTime, len(values), len(postBody))
-		return uuidsToReturn, errs
+		return nil, ctx.Err() == nil, fmt.Errorf("Error sending the request to Prebid Cache: %v; Duration=%v, Items=%v, Payload Size=%v", err, elapsedTime, items, len(postBody))
 	}
 	defer anResp.Body.Close()
 	c.metrics.RecordPrebidCacheRequestTime(true, elapsedTime)
 
-	responseBody, err := io.ReadAll(anResp.Body)
+	responseBody, err = io.ReadAll(anResp.Body)
 	if anResp.StatusCode != 200 {
-		logError(&errs, "Prebid Cache call to %s returned %d: %s", c.putUrl, anResp.StatusCode, responseBody)
-		return uuidsToReturn, errs
+		return nil, anResp.StatusCode >= 500, fmt.Errorf("Prebid Cache call to %s returned %d: %s", c.putUrl, anResp.StatusCode, responseBody)
 	}
+	return responseBody, false, nil
+}
 
-	currentIndex := 0
-	processResponse := func(uuidObj []byte, _ jsonparser.ValueType, _ int, err error) {
-		if uuid, valueType, _, err := jsonparser.Get(uuidObj, "uuid"); err != nil {
-			logError(&errs, "Prebid Cache returned a bad value at index %d. Error was: %v. Response body was: %s", currentIndex, err, string(responseBody))
-		} else if valueType != jsonparser.String {
-			logError(&errs, "Prebid Cache returned a %v at index %d in: %v", valueType, currentIndex, string(responseBody))
-		} else {
-			if uuidsToReturn[currentIndex], err = jsonparser.ParseString(uuid); err != nil {
-				logError(&errs, "Prebid Cache response index %d could not be parsed as string: %v", currentIndex, err)
-				uuidsToReturn[currentIndex] = ""
-			}
-		}
-		currentIndex++
+// waitToRetry sleeps for the retry backoff. It returns false without waiting if the backoff
+// doesn't fit in the time left before the context deadline.
+func (c *clientImpl) waitToRetry(ctx context.Context) bool {
+	delay := time.Duration(c.retry.BackoffMillis) * time.Millisecond
+	if c.retry.MaxJitterMillis > 0 {
+		delay += c.jitter(time.Duration(c.retry.MaxJitterMillis) * time.Millisecond)
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+		return false
 	}
 
-	if _, err := jsonparser.ArrayEach(responseBody, processResponse, "responses"); err != nil {
-		logError(&errs, "Error interpreting Prebid Cache response: %v\nResponse was: %s", err, string(responseBody))
-		return uuidsToReturn, errs
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
+}
 
-	return uuidsToReturn, errs
+func randomJitter(max time.Duration) time.Duration {
+	return time.Duration(rand.Int63n(int64(max) + 1))
 }
 
-func logError(errs *[]error, format string, a ...interface{}) {
+func logError(ctx context.Context, errs *[]error, format string, a ...interface{}) {
 	msg := fmt.Sprintf(format, a...)
-	glog.Error(msg)
+	logger.FromContext(ctx).Errorf("%s", msg)
 	*errs = append(*errs, errors.New(msg))
 }
 
+func logPutError(ctx context.Context, errs *[]putError, index int, format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	logger.FromContext(ctx).Errorf("%s", msg)
+	*errs = append(*errs, putError{index: index, err: errors.New(msg)})
+}
+
 func encodeValues(values []Cacheable) ([]byte, error) {
 	var buf bytes.Buffer
 	buf.WriteString(`{"puts":[`)
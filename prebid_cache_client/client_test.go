@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/metrics"
@@ -71,6 +73,30 @@ func TestBadResponse(t *testing.T) {
 	metricsMock.AssertExpectations(t)
 }
 
+func TestTooManyResponses(t *testing.T) {
+	server := httptest.NewServer(newHandler(3))
+	defer server.Close()
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordPrebidCacheRequestTime", true, mock.Anything).Once()
+
+	client := &clientImpl{
+		httpClient: server.Client(),
+		putUrl:     server.URL,
+		metrics:    metricsMock,
+	}
+	ids, errs := client.PutJson(context.Background(), []Cacheable{
+		{Type: TypeJSON, Data: json.RawMessage("true")},
+		{Type: TypeJSON, Data: json.RawMessage("false")},
+	})
+
+	assert.Equal(t, []string{"0", "1"}, ids)
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "Prebid Cache returned more responses than the 2 values sent")
+	}
+	metricsMock.AssertExpectations(t)
+}
+
 func TestCancelledContext(t *testing.T) {
 	testCases := []struct {
 		description         string
@@ -168,6 +194,89 @@ func TestSuccessfulPut(t *testing.T) {
 	metricsMock.AssertExpectations(t)
 }
 
+func TestRetries(t *testing.T) {
+	testCases := []struct {
+		description       string
+		statuses          []int
+		maxRetries        int
+		expectedRequests  int
+		expectedIDs       []string
+		expectedErrPrefix string
+	}{
+		{
+			description:      "Success after server errors",
+			statuses:         []int{500, 503, 200},
+			maxRetries:       2,
+			expectedRequests: 3,
+			expectedIDs:      []string{"0"},
+		},
+		{
+			description:       "Retries exhausted",
+			statuses:          []int{500, 500, 200},
+			maxRetries:        1,
+			expectedRequests:  2,
+			expectedIDs:       []string{""},
+			expectedErrPrefix: "Prebid Cache call to",
+		},
+		{
+			description:       "Client errors are not retried",
+			statuses:          []int{400, 200},
+			maxRetries:        2,
+			expectedRequests:  1,
+			expectedIDs:       []string{""},
+			expectedErrPrefix: "Prebid Cache call to",
+		},
+	}
+
+	for _, test := range testCases {
+		requests := 0
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := test.statuses[requests]
+			requests++
+			if status != 200 {
+				w.WriteHeader(status)
+				return
+			}
+			newHandler(1)(w, r)
+		})
+		server := httptest.NewServer(handler)
+
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordPrebidCacheRequestTime", true, mock.Anything)
+
+		client := &clientImpl{
+			httpClient: server.Client(),
+			putUrl:     server.URL,
+			metrics:    metricsMock,
+			retry:      config.CacheRetry{MaxRetries: test.maxRetries, BackoffMillis: 1, MaxJitterMillis: 1},
+			jitter:     func(max time.Duration) time.Duration { return max },
+		}
+
+		ids, errs := client.PutJson(context.Background(), []Cacheable{{Type: TypeJSON, Data: json.RawMessage("true")}})
+		server.Close()
+
+		assert.Equal(t, test.expectedRequests, requests, test.description+":requests")
+		assert.Equal(t, test.expectedIDs, ids, test.description+":ids")
+		if test.expectedErrPrefix == "" {
+			assert.Empty(t, errs, test.description+":errors")
+		} else if assert.Len(t, errs, 1, test.description+":errors") {
+			assert.True(t, strings.HasPrefix(errs[0].Error(), test.expectedErrPrefix), test.description+":error")
+		}
+	}
+}
+
+func TestNoRetryPastDeadline(t *testing.T) {
+	client := &clientImpl{
+		retry:  config.CacheRetry{MaxRetries: 1, BackoffMillis: 50},
+		jitter: randomJitter,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.False(t, client.waitToRetry(ctx), "the backoff doesn't fit before the deadline")
+}
+
 func TestEncodeValueToBuffer(t *testing.T) {
 	buf := new(bytes.Buffer)
 	testCache := Cacheable{
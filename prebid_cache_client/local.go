@@ -0,0 +1,174 @@
+package prebid_cache_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/util/timeutil"
+	"github.com/prebid/prebid-server/util/uuidutil"
+)
+
+// localCacheSweepInterval is how often expired values are evicted from the local cache
+const localCacheSweepInterval = time.Minute
+
+// LocalCache is an in-process replacement for Prebid Cache. Values are kept in memory until their
+// TTL expires and are served by Prebid Server itself with the Handle endpoint.
+type LocalCache struct {
+	mutex      sync.Mutex
+	entries    map[string]localEntry
+	lastSweep  time.Time
+	maxEntries int
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+
+	externalCacheScheme string
+	externalCacheHost   string
+	externalCachePath   string
+	metrics             metrics.MetricsEngine
+	uuidGenerator       uuidutil.UUIDGenerator
+	time                timeutil.Time
+}
+
+type localEntry struct {
+	payloadType PayloadType
+	data        []byte
+	expiration  time.Time
+}
+
+func NewLocalCache(conf *config.Cache, extCache *config.ExternalCache, metrics metrics.MetricsEngine) *LocalCache {
+	return &LocalCache{
+		entries:             make(map[string]localEntry),
+		maxEntries:          conf.Local.MaxEntries,
+		defaultTTL:          time.Duration(conf.Local.DefaultTTLSeconds) * time.Second,
+		maxTTL:              time.Duration(conf.Local.MaxTTLSeconds) * time.Second,
+		externalCacheScheme: extCache.Scheme,
+		externalCacheHost:   extCache.Host,
+		externalCachePath:   extCache.Path,
+		metrics:             metrics,
+		uuidGenerator:       uuidutil.UUIDRandomGenerator{},
+		time:                &timeutil.RealTime{},
+	}
+}
+
+func (c *LocalCache) GetExtCacheData() (string, string, string) {
+	return c.externalCacheScheme, c.externalCacheHost, normalizeExtCachePath(c.externalCachePath)
+}
+
+func (c *LocalCache) PutJson(ctx context.Context, values []Cacheable) (uuids []string, errs []error) {
+	errs = make([]error, 0, 1)
+	if len(values) < 1 {
+		return nil, errs
+	}
+
+	startTime := time.Now()
+	uuids = make([]string, len(values))
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.time.Now()
+	if now.Sub(c.lastSweep) >= localCacheSweepInterval || len(c.entries) >= c.maxEntries {
+		c.sweep(now)
+	}
+
+	for i, value := range values {
+		uuid, err := c.store(value, now)
+		if err != nil {
+			logError(ctx, &errs, "Local cache could not store the value at index %d: %v", i, err)
+			continue
+		}
+		uuids[i] = uuid
+	}
+
+	c.metrics.RecordPrebidCacheRequestTime(true, time.Since(startTime))
+	return uuids, errs
+}
+
+// store saves one value and returns its key. The caller must hold the lock.
+func (c *LocalCache) store(value Cacheable, now time.Time) (string, error) {
+	if len(c.entries) >= c.maxEntries {
+		return "", errors.New("the cache is full")
+	}
+
+	data := []byte(value.Data)
+	switch value.Type {
+	case TypeXML:
+		var xml string
+		if err := json.Unmarshal(value.Data, &xml); err != nil {
+			return "", errors.New("xml values must be JSON strings")
+		}
+		data = []byte(xml)
+	case TypeJSON:
+		if !json.Valid(value.Data) {
+			return "", errors.New("json values must be valid JSON")
+		}
+	default:
+		return "", fmt.Errorf("type %q is not supported", value.Type)
+	}
+
+	key := value.Key
+	if key == "" {
+		var err error
+		if key, err = c.uuidGenerator.Generate(); err != nil {
+			return "", err
+		}
+	} else if entry, ok := c.entries[key]; ok && now.Before(entry.expiration) {
+		return "", fmt.Errorf("key %s is already in use", key)
+	}
+
+	ttl := c.defaultTTL
+	if value.TTLSeconds > 0 {
+		ttl = time.Duration(value.TTLSeconds) * time.Second
+	}
+	if ttl > c.maxTTL {
+		ttl = c.maxTTL
+	}
+
+	c.entries[key] = localEntry{payloadType: value.Type, data: data, expiration: now.Add(ttl)}
+	return key, nil
+}
+
+// sweep evicts the expired values. The caller must hold the lock.
+func (c *LocalCache) sweep(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiration) {
+			delete(c.entries, key)
+		}
+	}
+	c.lastSweep = now
+}
+
+// Handle serves GET /cache?uuid=<key> the same way Prebid Cache does.
+func (c *LocalCache) Handle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	uuid := r.URL.Query().Get("uuid")
+	if uuid == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Missing required parameter uuid"))
+		return
+	}
+
+	c.mutex.Lock()
+	entry, ok := c.entries[uuid]
+	c.mutex.Unlock()
+
+	if !ok || !c.time.Now().Before(entry.expiration) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("No content stored for uuid=" + uuid))
+		return
+	}
+
+	if entry.payloadType == TypeXML {
+		w.Header().Set("Content-Type", "application/xml")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Write(entry.data)
+}
@@ -0,0 +1,154 @@
+package prebid_cache_client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (f *fakeTime) Now() time.Time {
+	return f.time
+}
+
+type fakeUUIDGenerator struct {
+	next int
+}
+
+func (g *fakeUUIDGenerator) Generate() (string, error) {
+	g.next++
+	return "uuid" + strconv.Itoa(g.next), nil
+}
+
+func newTestLocalCache(maxEntries int, clock *fakeTime) *LocalCache {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordPrebidCacheRequestTime", true, mock.Anything)
+
+	cache := NewLocalCache(&config.Cache{Local: config.LocalCache{Enabled: true, MaxEntries: maxEntries, DefaultTTLSeconds: 60, MaxTTLSeconds: 120}},
+		&config.ExternalCache{Scheme: "https", Host: "pbs.com", Path: "cache"}, metricsMock)
+	cache.time = clock
+	cache.uuidGenerator = &fakeUUIDGenerator{}
+	return cache
+}
+
+func TestLocalCachePutAndGet(t *testing.T) {
+	clock := &fakeTime{time: time.Unix(1000, 0)}
+	cache := newTestLocalCache(10, clock)
+
+	uuids, errs := cache.PutJson(context.Background(), []Cacheable{
+		{Type: TypeXML, Data: json.RawMessage(`"<VAST></VAST>"`)},
+		{Type: TypeJSON, Data: json.RawMessage(`{"adm":"<div></div>"}`), TTLSeconds: 600, Key: "custom"},
+		{Type: TypeXML, Data: json.RawMessage(`<VAST></VAST>`)},
+		{Type: TypeJSON, Data: json.RawMessage(`true`), Key: "custom"},
+	})
+
+	assert.Equal(t, []string{"uuid1", "custom", "", ""}, uuids)
+	assert.Len(t, errs, 2)
+
+	testCases := []struct {
+		description         string
+		query               string
+		at                  time.Time
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			description:         "XML",
+			query:               "uuid=uuid1",
+			at:                  clock.time,
+			expectedStatus:      200,
+			expectedContentType: "application/xml",
+			expectedBody:        "<VAST></VAST>",
+		},
+		{
+			description:         "JSON",
+			query:               "uuid=custom",
+			at:                  clock.time,
+			expectedStatus:      200,
+			expectedContentType: "application/json",
+			expectedBody:        `{"adm":"<div></div>"}`,
+		},
+		{
+			description:    "Missing uuid",
+			query:          "",
+			at:             clock.time,
+			expectedStatus: 400,
+			expectedBody:   "Missing required parameter uuid",
+		},
+		{
+			description:    "Expired after the default TTL",
+			query:          "uuid=uuid1",
+			at:             clock.time.Add(60 * time.Second),
+			expectedStatus: 404,
+			expectedBody:   "No content stored for uuid=uuid1",
+		},
+		{
+			description:         "TTL capped by the max TTL",
+			query:               "uuid=custom",
+			at:                  clock.time.Add(119 * time.Second),
+			expectedStatus:      200,
+			expectedContentType: "application/json",
+			expectedBody:        `{"adm":"<div></div>"}`,
+		},
+		{
+			description:    "Expired after the max TTL",
+			query:          "uuid=custom",
+			at:             clock.time.Add(120 * time.Second),
+			expectedStatus: 404,
+			expectedBody:   "No content stored for uuid=custom",
+		},
+	}
+
+	for _, test := range testCases {
+		clock.time = test.at
+		recorder := httptest.NewRecorder()
+
+		cache.Handle(recorder, httptest.NewRequest("GET", "/cache?"+test.query, nil), nil)
+
+		body, _ := io.ReadAll(recorder.Result().Body)
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.description)
+		assert.Equal(t, test.expectedContentType, recorder.Header().Get("Content-Type"), test.description)
+		assert.Equal(t, test.expectedBody, string(body), test.description)
+	}
+}
+
+func TestLocalCacheEviction(t *testing.T) {
+	clock := &fakeTime{time: time.Unix(1000, 0)}
+	cache := newTestLocalCache(1, clock)
+
+	uuids, errs := cache.PutJson(context.Background(), []Cacheable{
+		{Type: TypeJSON, Data: json.RawMessage(`1`)},
+		{Type: TypeJSON, Data: json.RawMessage(`2`)},
+	})
+	assert.Equal(t, []string{"uuid1", ""}, uuids)
+	assert.Len(t, errs, 1, "the cache is full")
+
+	clock.time = clock.time.Add(time.Minute)
+	uuids, errs = cache.PutJson(context.Background(), []Cacheable{{Type: TypeJSON, Data: json.RawMessage(`3`)}})
+	assert.Equal(t, []string{"uuid2"}, uuids)
+	assert.Empty(t, errs)
+	assert.Len(t, cache.entries, 1, "expired values must be evicted")
+}
+
+func TestLocalCacheGetExtCacheData(t *testing.T) {
+	cache := newTestLocalCache(1, &fakeTime{})
+
+	scheme, host, path := cache.GetExtCacheData()
+
+	assert.Equal(t, "https", scheme)
+	assert.Equal(t, "pbs.com", host)
+	assert.Equal(t, "/cache", path)
+}
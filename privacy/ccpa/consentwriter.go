@@ -2,7 +2,6 @@ package ccpa
 
 import (
 	"github.com/prebid/openrtb/v17/openrtb2"
-	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
 // ConsentWriter implements the old PolicyWriter interface for CCPA.
@@ -16,16 +15,14 @@ func (c ConsentWriter) Write(req *openrtb2.BidRequest) error {
 	if req == nil {
 		return nil
 	}
-	reqWrap := &openrtb_ext.RequestWrapper{BidRequest: req}
 
 	// Set consent string in USPrivacy
 	if c.Consent != "" {
-		if regsExt, err := reqWrap.GetRegExt(); err == nil {
-			regsExt.SetUSPrivacy(c.Consent)
-		} else {
-			return err
+		if req.Regs == nil {
+			req.Regs = &openrtb2.Regs{}
 		}
+		req.Regs.USPrivacy = c.Consent
 	}
 
-	return reqWrap.RebuildRequest()
+	return nil
 }
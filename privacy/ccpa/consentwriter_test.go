@@ -75,17 +75,16 @@ func TestConsentWriterLegacy(t *testing.T) {
 			description: "Success",
 			request:     &openrtb2.BidRequest{},
 			expected: &openrtb2.BidRequest{
-				Regs: &openrtb2.Regs{Ext: json.RawMessage(`{"us_privacy":"anyConsent"}`)},
+				Regs: &openrtb2.Regs{USPrivacy: "anyConsent"},
 			},
 		},
 		{
-			description: "Error With Regs.Ext - Does Not Mutate",
+			description: "Malformed Regs.Ext - Leaves Ext As Is",
 			request: &openrtb2.BidRequest{
 				Regs: &openrtb2.Regs{Ext: json.RawMessage(`malformed}`)},
 			},
-			expectedError: true,
 			expected: &openrtb2.BidRequest{
-				Regs: &openrtb2.Regs{Ext: json.RawMessage(`malformed}`)},
+				Regs: &openrtb2.Regs{USPrivacy: "anyConsent", Ext: json.RawMessage(`malformed}`)},
 			},
 		},
 	}
@@ -36,13 +36,18 @@ func ReadFromRequestWrapper(req *openrtb_ext.RequestWrapper, gpp gpplib.GppConta
 			return Policy{}, nil
 		}
 
-		// Read consent from request.regs.ext
-		regsExt, err := req.GetRegExt()
-		if err != nil {
-			return Policy{}, fmt.Errorf("error reading request.regs.ext: %s", err)
+		// Read consent from request.regs, falling back to request.regs.ext for OpenRTB 2.5 bidder requests
+		if req.BidRequest != nil && req.BidRequest.Regs != nil {
+			consent = req.BidRequest.Regs.USPrivacy
 		}
-		if regsExt != nil {
-			consent = regsExt.GetUSPrivacy()
+		if consent == "" {
+			regsExt, err := req.GetRegExt()
+			if err != nil {
+				return Policy{}, fmt.Errorf("error reading request.regs.ext: %s", err)
+			}
+			if regsExt != nil {
+				consent = regsExt.GetUSPrivacy()
+			}
 		}
 	}
 	// Read no sale bidders from request.ext.prebid
@@ -69,21 +74,24 @@ func ReadFromRequest(req *openrtb2.BidRequest) (Policy, error) {
 
 // Write mutates an OpenRTB bid request with the CCPA regulatory information.
 func (p Policy) Write(req *openrtb_ext.RequestWrapper) error {
-	if req == nil {
+	if req == nil || req.BidRequest == nil {
 		return nil
 	}
 
-	regsExt, err := req.GetRegExt()
-	if err != nil {
-		return err
-	}
-
 	reqExt, err := req.GetRequestExt()
 	if err != nil {
 		return err
 	}
 
-	regsExt.SetUSPrivacy(p.Consent)
+	if req.Regs == nil {
+		if p.Consent != "" {
+			req.Regs = &openrtb2.Regs{USPrivacy: p.Consent}
+		}
+	} else {
+		regsCopy := *req.Regs
+		regsCopy.USPrivacy = p.Consent
+		req.Regs = &regsCopy
+	}
 	setPrebidNoSale(p.NoSaleBidders, reqExt)
 	return nil
 }
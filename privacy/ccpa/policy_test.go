@@ -224,6 +224,17 @@ func TestReadFromRequest(t *testing.T) {
 				NoSaleBidders: []string{"a", "b"},
 			},
 		},
+		{
+			description: "Success - OpenRTB 2.6 Location Preferred",
+			request: &openrtb2.BidRequest{
+				Regs: &openrtb2.Regs{USPrivacy: "DEF", Ext: json.RawMessage(`{"us_privacy":"ABC"}`)},
+				Ext:  json.RawMessage(`{"prebid":{"nosale":["a", "b"]}}`),
+			},
+			expectedPolicy: Policy{
+				Consent:       "DEF",
+				NoSaleBidders: []string{"a", "b"},
+			},
+		},
 		{
 			description: "Nil Request",
 			request:     nil,
@@ -325,7 +336,7 @@ func TestWrite(t *testing.T) {
 			policy:      Policy{Consent: "anyConsent", NoSaleBidders: []string{"a", "b"}},
 			request:     &openrtb2.BidRequest{},
 			expected: &openrtb2.BidRequest{
-				Regs: &openrtb2.Regs{Ext: json.RawMessage(`{"us_privacy":"anyConsent"}`)},
+				Regs: &openrtb2.Regs{USPrivacy: "anyConsent"},
 				Ext:  json.RawMessage(`{"prebid":{"nosale":["a","b"]}}`),
 			},
 		},
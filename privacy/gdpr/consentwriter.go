@@ -2,13 +2,12 @@ package gdpr
 
 import (
 	"github.com/prebid/openrtb/v17/openrtb2"
-	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
 // ConsentWriter implements the PolicyWriter interface for GDPR TCF.
 type ConsentWriter struct {
-	Consent    string
-	RegExtGDPR *int8
+	Consent string
+	GDPR    *int8
 }
 
 // Write mutates an OpenRTB bid request with the GDPR TCF consent.
@@ -16,26 +15,19 @@ func (c ConsentWriter) Write(req *openrtb2.BidRequest) error {
 	if req == nil {
 		return nil
 	}
-	reqWrap := &openrtb_ext.RequestWrapper{BidRequest: req}
 
-	if c.RegExtGDPR != nil {
-		if regsExt, err := reqWrap.GetRegExt(); err == nil {
-			regsExt.SetGDPR(c.RegExtGDPR)
-		} else {
-			return err
+	if c.GDPR != nil {
+		if req.Regs == nil {
+			req.Regs = &openrtb2.Regs{}
 		}
+		req.Regs.GDPR = c.GDPR
 	}
 
 	if c.Consent != "" {
-		if userExt, err := reqWrap.GetUserExt(); err == nil {
-			userExt.SetConsent(&c.Consent)
-		} else {
-			return err
+		if req.User == nil {
+			req.User = &openrtb2.User{}
 		}
-	}
-
-	if err := reqWrap.RebuildRequest(); err != nil {
-		return err
+		req.User.Consent = c.Consent
 	}
 
 	return nil
@@ -10,11 +10,11 @@ import (
 
 func TestConsentWriter(t *testing.T) {
 	testCases := []struct {
-		description   string
-		consent       string
-		request       *openrtb2.BidRequest
-		expected      *openrtb2.BidRequest
-		expectedError bool
+		description string
+		consent     string
+		gdpr        *int8
+		request     *openrtb2.BidRequest
+		expected    *openrtb2.BidRequest
 	}{
 		{
 			description: "Empty",
@@ -26,76 +26,50 @@ func TestConsentWriter(t *testing.T) {
 			description: "Enabled With Nil Request User Object",
 			consent:     "anyConsent",
 			request:     &openrtb2.BidRequest{},
-			expected: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"consent":"anyConsent"}`)}},
-		},
-		{
-			description: "Enabled With Nil Request User Ext Object",
-			consent:     "anyConsent",
-			request:     &openrtb2.BidRequest{User: &openrtb2.User{}},
-			expected: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"consent":"anyConsent"}`)}},
+			expected:    &openrtb2.BidRequest{User: &openrtb2.User{Consent: "anyConsent"}},
 		},
 		{
-			description: "Enabled With Existing Request User Ext Object - Doesn't Overwrite",
+			description: "Enabled With Existing Request User Object - Overwrites",
 			consent:     "anyConsent",
-			request: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"existing":"any"}`)}},
-			expected: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"consent":"anyConsent","existing":"any"}`)}},
-		},
-		{
-			description: "Enabled With Existing Request User Ext Object - Overwrites",
-			consent:     "anyConsent",
-			request: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"existing":"any","consent":"toBeOverwritten"}`)}},
-			expected: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"consent":"anyConsent","existing":"any"}`)}},
+			request:     &openrtb2.BidRequest{User: &openrtb2.User{ID: "anyID", Consent: "toBeOverwritten"}},
+			expected:    &openrtb2.BidRequest{User: &openrtb2.User{ID: "anyID", Consent: "anyConsent"}},
 		},
 		{
-			description: "Enabled With Existing Malformed Request User Ext Object",
+			description: "Enabled With Existing Request User Ext Object - Leaves Ext As Is",
 			consent:     "anyConsent",
 			request: &openrtb2.BidRequest{User: &openrtb2.User{
 				Ext: json.RawMessage(`malformed`)}},
-			expectedError: true,
+			expected: &openrtb2.BidRequest{User: &openrtb2.User{
+				Consent: "anyConsent",
+				Ext:     json.RawMessage(`malformed`)}},
 		},
 		{
-			description: "Injection Attack With Nil Request User Object",
-			consent:     "BONV8oqONXwgmADACHENAO7pqzAAppY\"},\"oops\":\"malicious\",\"p\":{\"p\":\"",
+			description: "GDPR Signal With Nil Request Regs Object",
+			gdpr:        openrtb2.Int8Ptr(1),
 			request:     &openrtb2.BidRequest{},
-			expected: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"consent":"BONV8oqONXwgmADACHENAO7pqzAAppY\"},\"oops\":\"malicious\",\"p\":{\"p\":\""}`),
-			}},
+			expected:    &openrtb2.BidRequest{Regs: &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(1)}},
 		},
 		{
-			description: "Injection Attack With Nil Request User Ext Object",
-			consent:     "BONV8oqONXwgmADACHENAO7pqzAAppY\"},\"oops\":\"malicious\",\"p\":{\"p\":\"",
-			request:     &openrtb2.BidRequest{User: &openrtb2.User{}},
-			expected: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"consent":"BONV8oqONXwgmADACHENAO7pqzAAppY\"},\"oops\":\"malicious\",\"p\":{\"p\":\""}`),
-			}},
+			description: "GDPR Signal With Existing Request Regs Object - Overwrites",
+			gdpr:        openrtb2.Int8Ptr(0),
+			request:     &openrtb2.BidRequest{Regs: &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(1), USPrivacy: "1YYY"}},
+			expected:    &openrtb2.BidRequest{Regs: &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(0), USPrivacy: "1YYY"}},
 		},
 		{
-			description: "Injection Attack With Existing Request User Ext Object",
+			description: "Injection Attack Is Stored As Is",
 			consent:     "BONV8oqONXwgmADACHENAO7pqzAAppY\"},\"oops\":\"malicious\",\"p\":{\"p\":\"",
-			request: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"existing":"any"}`),
-			}},
+			request:     &openrtb2.BidRequest{},
 			expected: &openrtb2.BidRequest{User: &openrtb2.User{
-				Ext: json.RawMessage(`{"consent":"BONV8oqONXwgmADACHENAO7pqzAAppY\"},\"oops\":\"malicious\",\"p\":{\"p\":\"","existing":"any"}`),
+				Consent: "BONV8oqONXwgmADACHENAO7pqzAAppY\"},\"oops\":\"malicious\",\"p\":{\"p\":\"",
 			}},
 		},
 	}
 
 	for _, test := range testCases {
-		writer := ConsentWriter{test.consent, nil}
+		writer := ConsentWriter{test.consent, test.gdpr}
 		err := writer.Write(test.request)
 
-		if test.expectedError {
-			assert.Error(t, err, test.description)
-		} else {
-			assert.NoError(t, err, test.description)
-			assert.Equal(t, test.expected, test.request, test.description)
-		}
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expected, test.request, test.description)
 	}
 }
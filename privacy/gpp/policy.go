@@ -0,0 +1,27 @@
+package gpp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Policy represents the GPP regulation for a user sync request.
+type Policy struct {
+	Consent string
+	// SID is the comma separated list of the GPP section IDs applicable to the request.
+	SID string
+}
+
+// ValidateSID returns true if the section IDs are empty or a comma separated list of valid section IDs,
+// which are between 0 and 127.
+func ValidateSID(sid string) bool {
+	if sid == "" {
+		return true
+	}
+	for _, id := range strings.Split(sid, ",") {
+		if _, err := strconv.ParseUint(strings.TrimSpace(id), 10, 7); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSID(t *testing.T) {
+	testCases := []struct {
+		description string
+		sid         string
+		expected    bool
+	}{
+		{description: "Empty", sid: "", expected: true},
+		{description: "One", sid: "2", expected: true},
+		{description: "Many", sid: "2,6, 7", expected: true},
+		{description: "Zero", sid: "0", expected: true},
+		{description: "Max", sid: "127", expected: true},
+		{description: "Negative", sid: "-1", expected: false},
+		{description: "Negative Among Others", sid: "2,-6", expected: false},
+		{description: "Plus Sign", sid: "+2", expected: false},
+		{description: "Not A Number", sid: "2,a", expected: false},
+		{description: "Empty Element", sid: "2,,6", expected: false},
+		{description: "Out Of Range", sid: "128", expected: false},
+		{description: "Injection", sid: "2&redirect=x", expected: false},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, ValidateSID(test.sid), test.description)
+	}
+}
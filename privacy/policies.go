@@ -3,6 +3,7 @@ package privacy
 import (
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	"github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/privacy/lmt"
 )
 
@@ -10,5 +11,6 @@ import (
 type Policies struct {
 	CCPA ccpa.Policy
 	GDPR gdpr.Policy
+	GPP  gpp.Policy
 	LMT  lmt.Policy
 }
@@ -0,0 +1,350 @@
+package router
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/account"
+	"github.com/prebid/prebid-server/config"
+)
+
+var errUnknownBidder = errors.New("unknown bidder")
+
+// DisableBidder disables the bidder for all accounts, until it is enabled again or, if the expiry is not zero,
+// until the expiry. The auctions stop calling the bidder and its aliases, and warn about it like about the
+// bidders disabled in the configuration. The endpoints are not rebuilt.
+func (r *Router) DisableBidder(bidder string, until time.Time) error {
+	r.reloadMutex.Lock()
+	defer r.reloadMutex.Unlock()
+
+	if _, ok := r.cfg.BidderInfos[bidder]; !ok {
+		return errUnknownBidder
+	}
+
+	disabledBidders := copyDisabledBidders(r.disabledBidders)
+	disabledBidders[bidder] = until
+	r.updateDisabledBidders(disabledBidders)
+
+	if !until.IsZero() {
+		time.AfterFunc(time.Until(until), func() { r.expireDisabledBidder(bidder, until) })
+	}
+	return nil
+}
+
+// EnableBidder enables again a bidder disabled with DisableBidder. Bidders disabled in the configuration
+// stay disabled.
+func (r *Router) EnableBidder(bidder string) error {
+	r.reloadMutex.Lock()
+	defer r.reloadMutex.Unlock()
+
+	if _, ok := r.cfg.BidderInfos[bidder]; !ok {
+		return errUnknownBidder
+	}
+	if _, disabled := r.disabledBidders[bidder]; !disabled {
+		return nil
+	}
+
+	disabledBidders := copyDisabledBidders(r.disabledBidders)
+	delete(disabledBidders, bidder)
+	r.updateDisabledBidders(disabledBidders)
+	return nil
+}
+
+// expireDisabledBidder enables the bidder when its expiry is reached, unless it has been enabled or
+// disabled again in the meantime.
+func (r *Router) expireDisabledBidder(bidder string, until time.Time) {
+	r.reloadMutex.Lock()
+	defer r.reloadMutex.Unlock()
+
+	if current, disabled := r.disabledBidders[bidder]; !disabled || !current.Equal(until) {
+		return
+	}
+
+	disabledBidders := copyDisabledBidders(r.disabledBidders)
+	delete(disabledBidders, bidder)
+	r.updateDisabledBidders(disabledBidders)
+	glog.Infof("Bidder %s enabled after its expiry", bidder)
+}
+
+// updateDisabledBidders replaces the disabled bidders read by the exchanges. It must be called with the reload
+// mutex held.
+func (r *Router) updateDisabledBidders(disabledBidders map[string]time.Time) {
+	r.disabledBidders = disabledBidders
+	bidders := make([]string, 0, len(disabledBidders))
+	for bidder := range disabledBidders {
+		bidders = append(bidders, bidder)
+	}
+	r.deps.disabledBidders.Set(bidders)
+}
+
+func copyDisabledBidders(disabledBidders map[string]time.Time) map[string]time.Time {
+	copied := make(map[string]time.Time, len(disabledBidders)+1)
+	for bidder, until := range disabledBidders {
+		copied[bidder] = until
+	}
+	return copied
+}
+
+// AdminAPI serves the admin server endpoints which inspect and change the live state of the router,
+// and reload its configuration. All the endpoints require one of the configured bearer tokens.
+type AdminAPI struct {
+	router   *Router
+	reloader *ConfigReloader
+	tokens   []string
+}
+
+func NewAdminAPI(router *Router, reloader *ConfigReloader, cfg config.AdminAPI) *AdminAPI {
+	return &AdminAPI{
+		router:   router,
+		reloader: reloader,
+		tokens:   cfg.Tokens,
+	}
+}
+
+// Register adds the admin API endpoints to the admin server.
+func (a *AdminAPI) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/adapters", a.authenticated(http.MethodGet, a.handleAdapters))
+	mux.HandleFunc("/admin/adapters/disable", a.authenticated(http.MethodPost, a.handleDisableAdapter))
+	mux.HandleFunc("/admin/adapters/enable", a.authenticated(http.MethodPost, a.handleEnableAdapter))
+	mux.HandleFunc("/admin/stored_requests/caches", a.authenticated(http.MethodGet, a.handleStoredRequestCaches))
+	mux.HandleFunc("/admin/accounts", a.authenticated(http.MethodGet, a.handleAccount))
+	if a.reloader != nil {
+		mux.HandleFunc("/config/reload", a.authenticated(http.MethodPost, a.reloader.Handle))
+	}
+}
+
+func (a *AdminAPI) authenticated(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="prebid-server-admin"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (a *AdminAPI) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+
+	token := []byte(strings.TrimPrefix(header, prefix))
+	authorized := false
+	for _, t := range a.tokens {
+		// compare all the tokens, so the response time doesn't tell which one matched
+		if t != "" && subtle.ConstantTimeCompare(token, []byte(t)) == 1 {
+			authorized = true
+		}
+	}
+	return authorized
+}
+
+// adapterStatus is the effective configuration of an adapter.
+type adapterStatus struct {
+	Name                string              `json:"name"`
+	Status              string              `json:"status"`
+	DisabledAtRuntime   bool                `json:"disabledAtRuntime"`
+	DisabledUntil       *time.Time          `json:"disabledUntil,omitempty"`
+	Endpoint            string              `json:"endpoint"`
+	Capabilities        map[string][]string `json:"capabilities,omitempty"`
+	EndpointCompression string              `json:"endpointCompression,omitempty"`
+	DebugAllowed        bool                `json:"debugAllowed"`
+	OpenRTBVersion      string              `json:"openrtbVersion,omitempty"`
+	SyncerKey           string              `json:"syncerKey,omitempty"`
+}
+
+func (a *AdminAPI) handleAdapters(w http.ResponseWriter, r *http.Request) {
+	a.router.reloadMutex.Lock()
+	disabledBidders := a.router.disabledBidders
+	a.router.reloadMutex.Unlock()
+
+	bidderInfos := a.router.currentEndpoints().cfg.BidderInfos
+	adapters := make([]adapterStatus, 0, len(bidderInfos))
+	for name, info := range bidderInfos {
+		adapter := adapterStatus{
+			Name:                name,
+			Status:              "ACTIVE",
+			Endpoint:            info.Endpoint,
+			Capabilities:        adapterCapabilities(info.Capabilities),
+			EndpointCompression: info.EndpointCompression,
+			DebugAllowed:        info.Debug != nil && info.Debug.Allow,
+		}
+		if info.Disabled {
+			adapter.Status = "DISABLED"
+		}
+		if until, disabled := disabledBidders[name]; disabled {
+			adapter.Status = "DISABLED"
+			adapter.DisabledAtRuntime = true
+			if !until.IsZero() {
+				adapter.DisabledUntil = &until
+			}
+		}
+		if info.OpenRTB != nil {
+			adapter.OpenRTBVersion = info.OpenRTB.Version
+		}
+		if info.Syncer != nil {
+			adapter.SyncerKey = info.Syncer.Key
+		}
+		adapters = append(adapters, adapter)
+	}
+	sort.Slice(adapters, func(i, j int) bool { return adapters[i].Name < adapters[j].Name })
+
+	writeAdminJSON(w, adapters)
+}
+
+func adapterCapabilities(info *config.CapabilitiesInfo) map[string][]string {
+	if info == nil {
+		return nil
+	}
+
+	capabilities := make(map[string][]string, 2)
+	for platform, platformInfo := range map[string]*config.PlatformInfo{"app": info.App, "site": info.Site} {
+		if platformInfo == nil {
+			continue
+		}
+		mediaTypes := make([]string, 0, len(platformInfo.MediaTypes))
+		for _, mediaType := range platformInfo.MediaTypes {
+			mediaTypes = append(mediaTypes, string(mediaType))
+		}
+		capabilities[platform] = mediaTypes
+	}
+	return capabilities
+}
+
+func (a *AdminAPI) handleDisableAdapter(w http.ResponseWriter, r *http.Request) {
+	var until time.Time
+	if duration := r.URL.Query().Get("duration"); duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil || d <= 0 {
+			http.Error(w, fmt.Sprintf("invalid duration %s. must be a positive duration, e.g. 30m", duration), http.StatusBadRequest)
+			return
+		}
+		until = time.Now().Add(d)
+	}
+
+	bidder := r.URL.Query().Get("bidder")
+	if err := a.router.DisableBidder(bidder, until); err != nil {
+		writeBidderError(w, bidder, err)
+		return
+	}
+
+	if until.IsZero() {
+		glog.Infof("Bidder %s disabled from the admin API", bidder)
+	} else {
+		glog.Infof("Bidder %s disabled from the admin API until %s", bidder, until.Format(time.RFC3339))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *AdminAPI) handleEnableAdapter(w http.ResponseWriter, r *http.Request) {
+	bidder := r.URL.Query().Get("bidder")
+	if err := a.router.EnableBidder(bidder); err != nil {
+		writeBidderError(w, bidder, err)
+		return
+	}
+
+	glog.Infof("Bidder %s enabled from the admin API", bidder)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeBidderError(w http.ResponseWriter, bidder string, err error) {
+	if err == errUnknownBidder {
+		http.Error(w, fmt.Sprintf("unknown bidder %s", bidder), http.StatusNotFound)
+		return
+	}
+	glog.Errorf("Bidder %s could not be updated from the admin API: %v", bidder, err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// storedRequestCache describes the contents of an in-memory stored request cache.
+type storedRequestCache struct {
+	Entries   int                        `json:"entries"`
+	SizeBytes int                        `json:"sizeBytes"`
+	Data      map[string]json.RawMessage `json:"data,omitempty"`
+}
+
+// handleStoredRequestCaches returns the number of entries and the size of each in-memory cache, and the data
+// of the cache given in the name parameter.
+func (a *AdminAPI) handleStoredRequestCaches(w http.ResponseWriter, r *http.Request) {
+	caches := a.router.deps.storedCaches
+
+	if name := r.URL.Query().Get("name"); name != "" {
+		cache, ok := caches[name]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown cache %s", name), http.StatusNotFound)
+			return
+		}
+		entries := cache.Entries()
+		summary := newStoredRequestCache(entries)
+		summary.Data = entries
+		writeAdminJSON(w, summary)
+		return
+	}
+
+	summaries := make(map[string]storedRequestCache, len(caches))
+	for name, cache := range caches {
+		summaries[name] = newStoredRequestCache(cache.Entries())
+	}
+	writeAdminJSON(w, summaries)
+}
+
+func newStoredRequestCache(entries map[string]json.RawMessage) storedRequestCache {
+	summary := storedRequestCache{Entries: len(entries)}
+	for id, data := range entries {
+		summary.SizeBytes += len(id) + len(data)
+	}
+	return summary
+}
+
+// handleAccount returns the configuration of the account given in the id parameter, merged with the
+// account defaults the same way as for the auction requests.
+func (a *AdminAPI) handleAccount(w http.ResponseWriter, r *http.Request) {
+	accountID := r.URL.Query().Get("id")
+	if accountID == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	acct, errs := account.GetAccount(r.Context(), a.router.currentEndpoints().cfg, a.router.deps.accounts, accountID)
+	if len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		status := http.StatusInternalServerError
+		if acct == nil {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, strings.Join(messages, "\n"), status)
+		return
+	}
+
+	writeAdminJSON(w, acct)
+}
+
+func writeAdminJSON(w http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		glog.Errorf("Admin API response could not be marshaled: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
@@ -0,0 +1,270 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/caches/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAdminAPI(t *testing.T, r *Router) *http.ServeMux {
+	mux := http.NewServeMux()
+	NewAdminAPI(r, nil, config.AdminAPI{Enabled: true, Tokens: []string{"new-token", "old-token"}}).Register(mux)
+	return mux
+}
+
+func callAdminAPI(mux *http.ServeMux, method, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer old-token")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminAPIAuthentication(t *testing.T) {
+	testCases := []struct {
+		description        string
+		method             string
+		authorization      string
+		expectedStatusCode int
+	}{
+		{
+			description:        "Authorized",
+			method:             http.MethodGet,
+			authorization:      "Bearer new-token",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			description:        "Authorized - Any Token",
+			method:             http.MethodGet,
+			authorization:      "Bearer old-token",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			description:        "Wrong Token",
+			method:             http.MethodGet,
+			authorization:      "Bearer other-token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			description:        "Wrong Scheme",
+			method:             http.MethodGet,
+			authorization:      "Basic new-token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			description:        "Empty Token",
+			method:             http.MethodGet,
+			authorization:      "Bearer ",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			description:        "Missing",
+			method:             http.MethodGet,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			description:        "Wrong Method",
+			method:             http.MethodPost,
+			authorization:      "Bearer new-token",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	mux := newTestAdminAPI(t, newTestRouter(t, newTestConfig(t, testBidderInfos(false), 200)))
+	for _, test := range testCases {
+		req := httptest.NewRequest(test.method, "/admin/adapters", nil)
+		if test.authorization != "" {
+			req.Header.Set("Authorization", test.authorization)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		assert.Equal(t, test.expectedStatusCode, w.Code, test.description)
+	}
+}
+
+func TestAdminAPIConfigReload(t *testing.T) {
+	r := newTestRouter(t, newTestConfig(t, testBidderInfos(false), 200))
+	reloader := NewConfigReloader(r, func() (*config.Configuration, error) {
+		return newTestConfig(t, testBidderInfos(true), 300), nil
+	})
+	mux := http.NewServeMux()
+	NewAdminAPI(r, reloader, config.AdminAPI{Enabled: true, Tokens: []string{"old-token"}}).Register(mux)
+	before := r.currentEndpoints()
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/config/reload", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "unauthorized")
+	assert.Same(t, before, r.currentEndpoints(), "unauthorized reload")
+
+	w = callAdminAPI(mux, http.MethodGet, "/config/reload")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code, "wrong method")
+	assert.Same(t, before, r.currentEndpoints(), "reload with the wrong method")
+
+	w = callAdminAPI(mux, http.MethodPost, "/config/reload")
+	assert.Equal(t, http.StatusNoContent, w.Code, "authorized")
+	assert.NotSame(t, before, r.currentEndpoints(), "authorized reload")
+}
+
+func TestAdminAPIDisableEnableAdapter(t *testing.T) {
+	r := newTestRouter(t, newTestConfig(t, testBidderInfos(false), 200))
+	mux := newTestAdminAPI(t, r)
+	before := r.currentEndpoints()
+
+	w := callAdminAPI(mux, http.MethodPost, "/admin/adapters/disable?bidder=appnexus")
+	assert.Equal(t, http.StatusNoContent, w.Code, "disable")
+	assert.True(t, r.deps.disabledBidders.Contains("appnexus"), "disabled")
+	assert.Same(t, before, r.currentEndpoints(), "the endpoints are not rebuilt")
+
+	assert.NoError(t, r.Reload(newTestConfig(t, testBidderInfos(false), 300)))
+	assert.True(t, r.deps.disabledBidders.Contains("appnexus"), "disabled after reload")
+	assert.False(t, r.cfg.BidderInfos["appnexus"].Disabled, "configuration is not modified")
+
+	w = callAdminAPI(mux, http.MethodPost, "/admin/adapters/enable?bidder=appnexus")
+	assert.Equal(t, http.StatusNoContent, w.Code, "enable")
+	assert.False(t, r.deps.disabledBidders.Contains("appnexus"), "enabled")
+
+	w = callAdminAPI(mux, http.MethodPost, "/admin/adapters/disable?bidder=unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code, "unknown bidder")
+	assert.Equal(t, "unknown bidder unknown\n", w.Body.String(), "unknown bidder")
+
+	w = callAdminAPI(mux, http.MethodPost, "/admin/adapters/disable?bidder=appnexus&duration=soon")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "invalid duration")
+	assert.Equal(t, "invalid duration soon. must be a positive duration, e.g. 30m\n", w.Body.String(), "invalid duration")
+	assert.False(t, r.deps.disabledBidders.Contains("appnexus"), "not disabled with invalid duration")
+}
+
+func TestAdminAPIDisableAdapterExpiry(t *testing.T) {
+	r := newTestRouter(t, newTestConfig(t, testBidderInfos(false), 200))
+	mux := newTestAdminAPI(t, r)
+
+	w := callAdminAPI(mux, http.MethodPost, "/admin/adapters/disable?bidder=appnexus&duration=50ms")
+	assert.Equal(t, http.StatusNoContent, w.Code, "disable")
+	assert.True(t, r.deps.disabledBidders.Contains("appnexus"), "disabled")
+
+	assert.Eventually(t, func() bool {
+		return !r.deps.disabledBidders.Contains("appnexus")
+	}, time.Second, 10*time.Millisecond, "enabled after expiry")
+}
+
+func TestAdminAPIDisableAdapterExpiryOverridden(t *testing.T) {
+	r := newTestRouter(t, newTestConfig(t, testBidderInfos(false), 200))
+
+	until := time.Now().Add(time.Hour)
+	assert.NoError(t, r.DisableBidder("appnexus", until))
+	assert.NoError(t, r.DisableBidder("appnexus", time.Time{}))
+
+	r.expireDisabledBidder("appnexus", until)
+	assert.True(t, r.deps.disabledBidders.Contains("appnexus"), "disabled without expiry")
+}
+
+func TestAdminAPIAdapters(t *testing.T) {
+	bidderInfos := testBidderInfos(false)
+	appnexus := bidderInfos["appnexus"]
+	appnexus.Debug = &config.DebugInfo{Allow: true}
+	appnexus.EndpointCompression = "GZIP"
+	appnexus.Capabilities = &config.CapabilitiesInfo{Site: &config.PlatformInfo{MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner, openrtb_ext.BidTypeVideo}}}
+	bidderInfos["appnexus"] = appnexus
+
+	r := newTestRouter(t, newTestConfig(t, bidderInfos, 200))
+	mux := newTestAdminAPI(t, r)
+	assert.NoError(t, r.DisableBidder("rubicon", time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	w := callAdminAPI(mux, http.MethodGet, "/admin/adapters")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `[
+		{
+			"name": "appnexus",
+			"status": "ACTIVE",
+			"disabledAtRuntime": false,
+			"endpoint": "http://ib.adnxs.com/openrtb2",
+			"capabilities": {"site": ["banner", "video"]},
+			"endpointCompression": "GZIP",
+			"debugAllowed": true,
+			"syncerKey": "adnxs"
+		},
+		{
+			"name": "rubicon",
+			"status": "DISABLED",
+			"disabledAtRuntime": true,
+			"disabledUntil": "2030-01-02T03:04:05Z",
+			"endpoint": "http://exapi-us-east.rubiconproject.com/a/api/exchange.json",
+			"debugAllowed": false
+		}
+	]`, w.Body.String())
+}
+
+func TestAdminAPIStoredRequestCaches(t *testing.T) {
+	r := newTestRouter(t, newTestConfig(t, testBidderInfos(false), 200))
+	requests := memory.NewCache(0, -1, "Requests")
+	requests.Save(context.Background(), map[string]json.RawMessage{"req1": json.RawMessage(`{"id":"req1"}`)})
+	r.deps.storedCaches = map[string]stored_requests.CacheInspector{
+		"stored_requests.requests": requests.(stored_requests.CacheInspector),
+		"stored_requests.imps":     memory.NewCache(0, -1, "Imps").(stored_requests.CacheInspector),
+	}
+	mux := newTestAdminAPI(t, r)
+
+	testCases := []struct {
+		description        string
+		target             string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			description:        "All Caches",
+			target:             "/admin/stored_requests/caches",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"stored_requests.requests":{"entries":1,"sizeBytes":17},"stored_requests.imps":{"entries":0,"sizeBytes":0}}`,
+		},
+		{
+			description:        "One Cache",
+			target:             "/admin/stored_requests/caches?name=stored_requests.requests",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"entries":1,"sizeBytes":17,"data":{"req1":{"id":"req1"}}}`,
+		},
+		{
+			description:        "Unknown Cache",
+			target:             "/admin/stored_requests/caches?name=unknown",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		w := callAdminAPI(mux, http.MethodGet, test.target)
+		assert.Equal(t, test.expectedStatusCode, w.Code, test.description+":status")
+		if test.expectedBody != "" {
+			assert.JSONEq(t, test.expectedBody, w.Body.String(), test.description+":body")
+		}
+	}
+}
+
+func TestAdminAPIAccount(t *testing.T) {
+	cfg := newTestConfig(t, testBidderInfos(false), 200)
+	cfg.AccountDefaults.DebugAllow = true
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+	cfg.BlacklistedAcctMap = map[string]bool{"blocked": true}
+	mux := newTestAdminAPI(t, newTestRouter(t, cfg))
+
+	w := callAdminAPI(mux, http.MethodGet, "/admin/accounts?id=acct")
+	assert.Equal(t, http.StatusOK, w.Code, "account")
+	var account config.Account
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &account), "account")
+	assert.Equal(t, "acct", account.ID, "account id")
+	assert.True(t, account.DebugAllow, "account defaults")
+
+	w = callAdminAPI(mux, http.MethodGet, "/admin/accounts?id=blocked")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "blacklisted account")
+
+	w = callAdminAPI(mux, http.MethodGet, "/admin/accounts")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "missing id")
+}
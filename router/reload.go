@@ -0,0 +1,216 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/experiment/adscert"
+	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/logger"
+	metricsConf "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	pbc "github.com/prebid/prebid-server/prebid_cache_client"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/throttling"
+	"github.com/prebid/prebid-server/usersync"
+)
+
+// endpointDeps are the components built once at startup, which the reloadable endpoints are built on.
+type endpointDeps struct {
+	httpClient        *http.Client
+	metricsEngine     *metricsConf.DetailedMetricsEngine
+	rateConvertor     *currency.RateConverter
+	vendorListFetcher gdpr.VendorListFetcher
+	tcf2CfgBuilder    gdpr.TCF2ConfigBuilder
+	cacheClient       pbc.Client
+	fetcher           stored_requests.Fetcher
+	ampFetcher        stored_requests.Fetcher
+	accounts          stored_requests.AccountFetcher
+	categoriesFetcher stored_requests.CategoryFetcher
+	videoFetcher      stored_requests.Fetcher
+	storedRespFetcher stored_requests.Fetcher
+	pbsAnalytics      analytics.PBSAnalyticsModule
+	paramsValidator   openrtb_ext.BidderParamValidator
+	adsCertSigner     adscert.Signer
+	planBuilder       hooks.ExecutionPlanBuilder
+	defaultAliases    map[string]string
+	defReqJSON        []byte
+	storedCaches      map[string]stored_requests.CacheInspector
+	cookies           *usersync.Cookies
+	bidRates          *usersync.BidRates
+	disabledBidders   *exchange.DisabledBidders
+	throttler         *throttling.Throttler
+	loggers           *logger.Factory
+}
+
+// reloadableEndpoints are the endpoints depending on the reloadable parts of the configuration. They are
+// rebuilt on each reload, so the requests in flight finish on the endpoints they started on. The new
+// exchange inherits the state of the previous one.
+type reloadableEndpoints struct {
+	cfg              *config.Configuration
+	exchange         exchange.Exchange
+	gdprPermsBuilder gdpr.PermissionsBuilder
+	auction          httprouter.Handle
+	amp              httprouter.Handle
+	video            httprouter.Handle
+	infoBidders      httprouter.Handle
+	infoBidderDetail httprouter.Handle
+	cookieSync       httprouter.Handle
+	setUID           httprouter.Handle
+	vtrack           httprouter.Handle
+	event            httprouter.Handle
+}
+
+// reloadable returns a handle delegating each request to the endpoint of the current configuration.
+func (r *Router) reloadable(endpoint func(e *reloadableEndpoints) httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		endpoint(r.currentEndpoints())(w, req, ps)
+	}
+}
+
+func (r *Router) currentEndpoints() *reloadableEndpoints {
+	return r.endpoints.Load().(*reloadableEndpoints)
+}
+
+// gdprPermissions builds the GDPR permissions with the GVL vendor IDs of the current bidder infos.
+func (r *Router) gdprPermissions(cfg gdpr.TCF2ConfigReader, requestInfo gdpr.RequestInfo) gdpr.Permissions {
+	return r.currentEndpoints().gdprPermsBuilder(cfg, requestInfo)
+}
+
+// Reload rebuilds the endpoints with the reloadable parts of the configuration: the bidder infos, which hold
+// the adapter endpoints and disabled flags and the user syncs, the account defaults and the auction timeouts.
+// The other settings keep the values they had at startup, and the bidders disabled from the admin API stay
+// disabled. The hook modules aren't rebuilt and keep their startup configuration, but the GDPR permissions
+// they check use the reloaded bidder infos. The current endpoints are kept if the new ones can't be built.
+func (r *Router) Reload(newCfg *config.Configuration) error {
+	r.reloadMutex.Lock()
+	defer r.reloadMutex.Unlock()
+
+	cfg, err := reloadableConfig(r.cfg, newCfg)
+	if err != nil {
+		return err
+	}
+	if err := r.rebuild(cfg); err != nil {
+		return err
+	}
+
+	r.cfg = cfg
+	return nil
+}
+
+// rebuild builds the endpoints of the configuration and swaps them with the current ones. It must be called
+// with the reload mutex held.
+func (r *Router) rebuild(cfg *config.Configuration) error {
+	syncersByBidder, err := buildSyncers(cfg)
+	if err != nil {
+		return err
+	}
+	reloaded, err := buildEndpoints(cfg, r.deps, syncersByBidder, r.currentEndpoints())
+	if err != nil {
+		return err
+	}
+
+	r.endpoints.Store(reloaded)
+	return nil
+}
+
+// reloadableConfig returns a copy of the current configuration with the reloadable parts of the new one.
+func reloadableConfig(current, newCfg *config.Configuration) (*config.Configuration, error) {
+	cfg := *current
+	cfg.BidderInfos = newCfg.BidderInfos
+	cfg.AccountDefaults = newCfg.AccountDefaults
+	cfg.AuctionTimeouts = newCfg.AuctionTimeouts
+	cfg.AMPTimeoutAdjustment = newCfg.AMPTimeoutAdjustment
+	if err := cfg.MarshalAccountDefaults(); err != nil {
+		return nil, err
+	}
+
+	if changed := changedReloadableFields(current, &cfg); len(changed) > 0 {
+		glog.Infof("Configuration reload applies the changes to: %s", strings.Join(changed, ", "))
+	} else {
+		glog.Info("Configuration reload found no change to adapters, account_defaults, auction_timeouts_ms or amp_timeout_adjustment_ms. Other changes require a restart")
+	}
+	return &cfg, nil
+}
+
+// changedReloadableFields returns the reloadable parts of the configuration which differ between the two.
+func changedReloadableFields(current, reloaded *config.Configuration) []string {
+	var changed []string
+	if !reflect.DeepEqual(current.BidderInfos, reloaded.BidderInfos) {
+		changed = append(changed, "adapters")
+	}
+	if !bytes.Equal(current.AccountDefaultsJSON(), reloaded.AccountDefaultsJSON()) {
+		changed = append(changed, "account_defaults")
+	}
+	if current.AuctionTimeouts != reloaded.AuctionTimeouts {
+		changed = append(changed, "auction_timeouts_ms")
+	}
+	if current.AMPTimeoutAdjustment != reloaded.AMPTimeoutAdjustment {
+		changed = append(changed, "amp_timeout_adjustment_ms")
+	}
+	return changed
+}
+
+// ConfigLoader reads and validates the host configuration and the bidder infos.
+type ConfigLoader func() (*config.Configuration, error)
+
+// ConfigReloader reloads the configuration of the router when the host sends a SIGHUP signal, which always
+// works, or calls the /config/reload endpoint, which is only served when the admin API is enabled.
+type ConfigReloader struct {
+	router *Router
+	load   ConfigLoader
+}
+
+func NewConfigReloader(router *Router, load ConfigLoader) *ConfigReloader {
+	return &ConfigReloader{
+		router: router,
+		load:   load,
+	}
+}
+
+// Reload loads the configuration and applies it to the router. Nothing is applied if the configuration
+// is invalid.
+func (c *ConfigReloader) Reload() error {
+	cfg, err := c.load()
+	if err != nil {
+		return fmt.Errorf("configuration could not be loaded or did not pass validation: %v", err)
+	}
+	if err := c.router.Reload(cfg); err != nil {
+		return fmt.Errorf("configuration could not be applied: %v", err)
+	}
+	return nil
+}
+
+// ReloadOnSignal reloads the configuration each time a signal is received, until the channel is closed.
+func (c *ConfigReloader) ReloadOnSignal(signals <-chan os.Signal) {
+	for range signals {
+		if err := c.Reload(); err != nil {
+			glog.Errorf("Configuration reload failed: %v", err)
+			continue
+		}
+		glog.Info("Configuration reloaded")
+	}
+}
+
+// Handle reloads the configuration on requests to the admin endpoint, which the admin API serves to the
+// authenticated POST requests.
+func (c *ConfigReloader) Handle(w http.ResponseWriter, r *http.Request) {
+	if err := c.Reload(); err != nil {
+		glog.Errorf("Configuration reload failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	glog.Info("Configuration reloaded")
+	w.WriteHeader(http.StatusNoContent)
+}
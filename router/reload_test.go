@@ -0,0 +1,237 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/experiment/adscert"
+	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks"
+	metricsConf "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	pbc "github.com/prebid/prebid-server/prebid_cache_client"
+	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/stretchr/testify/assert"
+)
+
+type nilAnalytics struct{}
+
+func (nilAnalytics) LogAuctionObject(*analytics.AuctionObject)               {}
+func (nilAnalytics) LogVideoObject(*analytics.VideoObject)                   {}
+func (nilAnalytics) LogCookieSyncObject(*analytics.CookieSyncObject)         {}
+func (nilAnalytics) LogSetUIDObject(*analytics.SetUIDObject)                 {}
+func (nilAnalytics) LogAmpObject(*analytics.AmpObject)                       {}
+func (nilAnalytics) LogNotificationEventObject(*analytics.NotificationEvent) {}
+
+func testBidderInfos(appnexusDisabled bool) config.BidderInfos {
+	return config.BidderInfos{
+		"appnexus": config.BidderInfo{
+			Disabled: appnexusDisabled,
+			Endpoint: "http://ib.adnxs.com/openrtb2",
+			Syncer: &config.Syncer{
+				Key:      "adnxs",
+				Redirect: &config.SyncerEndpoint{URL: "https://ib.adnxs.com/getuid?{{.RedirectURL}}"},
+			},
+		},
+		"rubicon": config.BidderInfo{
+			Endpoint: "http://exapi-us-east.rubiconproject.com/a/api/exchange.json",
+		},
+	}
+}
+
+func newTestConfig(t *testing.T, bidderInfos config.BidderInfos, defaultTimeout uint64) *config.Configuration {
+	t.Helper()
+	cfg := &config.Configuration{
+		ExternalURL:     "http://prebid.com",
+		BidderInfos:     bidderInfos,
+		AuctionTimeouts: config.AuctionTimeouts{Default: defaultTimeout, Max: 1000},
+	}
+	if err := cfg.MarshalAccountDefaults(); err != nil {
+		t.Fatalf("failed to marshal account defaults: %v", err)
+	}
+	return cfg
+}
+
+func newTestRouter(t *testing.T, cfg *config.Configuration) *Router {
+	t.Helper()
+	metricsEngine := metricsConf.NewMetricsEngine(cfg, openrtb_ext.CoreBidderNames(), nil, nil)
+	r := &Router{
+		Router:        httprouter.New(),
+		MetricsEngine: metricsEngine,
+		deps: &endpointDeps{
+			httpClient:        http.DefaultClient,
+			metricsEngine:     metricsEngine,
+			cacheClient:       pbc.NewClient(http.DefaultClient, &cfg.CacheURL, &cfg.ExtCacheURL, metricsEngine),
+			fetcher:           empty_fetcher.EmptyFetcher{},
+			ampFetcher:        empty_fetcher.EmptyFetcher{},
+			accounts:          empty_fetcher.EmptyFetcher{},
+			categoriesFetcher: empty_fetcher.EmptyFetcher{},
+			videoFetcher:      empty_fetcher.EmptyFetcher{},
+			storedRespFetcher: empty_fetcher.EmptyFetcher{},
+			pbsAnalytics:      nilAnalytics{},
+			paramsValidator:   &testValidator{},
+			adsCertSigner:     &adscert.NilSigner{},
+			planBuilder:       hooks.EmptyPlanBuilder{},
+			disabledBidders:   exchange.NewDisabledBidders(),
+		},
+	}
+
+	syncersByBidder, err := buildSyncers(cfg)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	endpoints, err := buildEndpoints(cfg, r.deps, syncersByBidder, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	r.endpoints.Store(endpoints)
+	r.cfg = cfg
+	r.GET("/info/bidders", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.infoBidders }))
+	return r
+}
+
+func getEnabledBidders(r *Router) string {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/info/bidders?enabledonly=true", nil))
+	return w.Body.String()
+}
+
+func TestRouterReload(t *testing.T) {
+	r := newTestRouter(t, newTestConfig(t, testBidderInfos(false), 200))
+	assert.JSONEq(t, `["appnexus","rubicon"]`, getEnabledBidders(r))
+
+	before := r.currentEndpoints()
+	err := r.Reload(newTestConfig(t, testBidderInfos(true), 300))
+	assert.NoError(t, err)
+
+	after := r.currentEndpoints()
+	assert.NotSame(t, before, after, "endpoints")
+	assert.JSONEq(t, `["rubicon"]`, getEnabledBidders(r))
+	assert.Equal(t, uint64(300), after.cfg.AuctionTimeouts.Default, "auction timeouts")
+	assert.Equal(t, uint64(200), before.cfg.AuctionTimeouts.Default, "previous auction timeouts")
+}
+
+func TestRouterGDPRPermissions(t *testing.T) {
+	var builders []string
+	permissionsBuilder := func(name string) gdpr.PermissionsBuilder {
+		return func(gdpr.TCF2ConfigReader, gdpr.RequestInfo) gdpr.Permissions {
+			builders = append(builders, name)
+			return nil
+		}
+	}
+
+	r := &Router{}
+	r.endpoints.Store(&reloadableEndpoints{gdprPermsBuilder: permissionsBuilder("startup")})
+	r.gdprPermissions(nil, gdpr.RequestInfo{})
+	r.endpoints.Store(&reloadableEndpoints{gdprPermsBuilder: permissionsBuilder("reloaded")})
+	r.gdprPermissions(nil, gdpr.RequestInfo{})
+
+	assert.Equal(t, []string{"startup", "reloaded"}, builders, "the permissions are built by the current endpoints")
+}
+
+func TestRouterReloadError(t *testing.T) {
+	r := newTestRouter(t, newTestConfig(t, testBidderInfos(false), 200))
+	before := r.currentEndpoints()
+
+	invalidInfos := testBidderInfos(false)
+	invalidInfos["appnexus"].Syncer.Supports = []string{"unknown"}
+	err := r.Reload(newTestConfig(t, invalidInfos, 300))
+	assert.EqualError(t, err, "failed to load bidder info for appnexus, user sync supported endpoint 'unknown' is unrecognized")
+
+	unknownInfos := testBidderInfos(false)
+	unknownInfos["unknownBidder"] = config.BidderInfo{Endpoint: "http://unknown.com"}
+	err = r.Reload(newTestConfig(t, unknownInfos, 300))
+	assert.Error(t, err)
+
+	assert.Same(t, before, r.currentEndpoints(), "endpoints")
+	assert.JSONEq(t, `["appnexus","rubicon"]`, getEnabledBidders(r))
+}
+
+func TestReloadableConfig(t *testing.T) {
+	current := newTestConfig(t, testBidderInfos(false), 200)
+	current.Port = 8000
+	current.AccountDefaults.DebugAllow = true
+	assert.NoError(t, current.MarshalAccountDefaults())
+
+	newCfg := newTestConfig(t, testBidderInfos(true), 300)
+	newCfg.Port = 9000
+	newCfg.AMPTimeoutAdjustment = 50
+
+	cfg, err := reloadableConfig(current, newCfg)
+	assert.NoError(t, err)
+	assert.Equal(t, newCfg.BidderInfos, cfg.BidderInfos, "bidder infos")
+	assert.Equal(t, newCfg.AuctionTimeouts, cfg.AuctionTimeouts, "auction timeouts")
+	assert.Equal(t, int64(50), cfg.AMPTimeoutAdjustment, "amp timeout adjustment")
+	assert.False(t, cfg.AccountDefaults.DebugAllow, "account defaults")
+	assert.JSONEq(t, string(newCfg.AccountDefaultsJSON()), string(cfg.AccountDefaultsJSON()), "account defaults json")
+	assert.Equal(t, 8000, cfg.Port, "port is not reloadable")
+	assert.Equal(t, 8000, current.Port, "current config is not modified")
+	assert.Equal(t, uint64(200), current.AuctionTimeouts.Default, "current config is not modified")
+}
+
+func TestChangedReloadableFields(t *testing.T) {
+	current := newTestConfig(t, testBidderInfos(false), 200)
+
+	unchanged := newTestConfig(t, testBidderInfos(false), 200)
+	unchanged.Port = 9000
+	assert.Empty(t, changedReloadableFields(current, unchanged), "only non-reloadable fields changed")
+
+	changed := newTestConfig(t, testBidderInfos(true), 300)
+	changed.AccountDefaults.DebugAllow = true
+	assert.NoError(t, changed.MarshalAccountDefaults())
+	changed.AMPTimeoutAdjustment = 50
+	assert.Equal(t, []string{"adapters", "account_defaults", "auction_timeouts_ms", "amp_timeout_adjustment_ms"}, changedReloadableFields(current, changed))
+}
+
+func TestConfigReloaderHandle(t *testing.T) {
+	testCases := []struct {
+		description        string
+		method             string
+		loadErr            error
+		expectedStatusCode int
+		expectedBody       string
+		expectedReload     bool
+	}{
+		{
+			description:        "Success",
+			method:             http.MethodPost,
+			expectedStatusCode: http.StatusNoContent,
+			expectedReload:     true,
+		},
+		{
+			description:        "Invalid Configuration",
+			method:             http.MethodPost,
+			loadErr:            errors.New("invalid"),
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       "configuration could not be loaded or did not pass validation: invalid\n",
+		},
+	}
+
+	for _, test := range testCases {
+		r := newTestRouter(t, newTestConfig(t, testBidderInfos(false), 200))
+		before := r.currentEndpoints()
+		reloader := NewConfigReloader(r, func() (*config.Configuration, error) {
+			if test.loadErr != nil {
+				return nil, test.loadErr
+			}
+			return newTestConfig(t, testBidderInfos(true), 300), nil
+		})
+
+		w := httptest.NewRecorder()
+		reloader.Handle(w, httptest.NewRequest(test.method, "/config/reload", nil))
+
+		assert.Equal(t, test.expectedStatusCode, w.Code, test.description+":status")
+		assert.Equal(t, test.expectedBody, w.Body.String(), test.description+":body")
+		if test.expectedReload {
+			assert.NotSame(t, before, r.currentEndpoints(), test.description+":endpoints")
+		} else {
+			assert.Same(t, before, r.currentEndpoints(), test.description+":endpoints")
+		}
+	}
+}
@@ -8,8 +8,11 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/prebid/prebid-server/analytics"
 	analyticsConf "github.com/prebid/prebid-server/analytics/config"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
@@ -22,6 +25,7 @@ import (
 	"github.com/prebid/prebid-server/experiment/adscert"
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/modules"
@@ -32,7 +36,9 @@ import (
 	"github.com/prebid/prebid-server/router/aspects"
 	"github.com/prebid/prebid-server/server/ssl"
 	storedRequestsConf "github.com/prebid/prebid-server/stored_requests/config"
+	"github.com/prebid/prebid-server/throttling"
 	"github.com/prebid/prebid-server/usersync"
+	"github.com/prebid/prebid-server/usersync/uidstore"
 	"github.com/prebid/prebid-server/util/uuidutil"
 	"github.com/prebid/prebid-server/version"
 
@@ -112,6 +118,15 @@ type Router struct {
 	MetricsEngine   *metricsConf.DetailedMetricsEngine
 	ParamsValidator openrtb_ext.BidderParamValidator
 	Shutdown        func()
+
+	deps        *endpointDeps
+	endpoints   atomic.Value // Should only hold *reloadableEndpoints
+	reloadMutex sync.Mutex
+	// cfg is the reloadable configuration, without the bidders disabled from the admin API.
+	cfg *config.Configuration
+	// disabledBidders holds the bidders disabled from the admin API, and when they are enabled again.
+	// A zero time means they stay disabled until they are enabled from the admin API.
+	disabledBidders map[string]time.Time
 }
 
 func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *Router, err error) {
@@ -121,6 +136,16 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		Router: httprouter.New(),
 	}
 
+	logBackend, err := logger.NewBackend(cfg.Logging.Format, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	logLevel, err := logger.ParseLevel(cfg.Logging.Level)
+	if err != nil {
+		return nil, err
+	}
+	loggers := logger.NewFactory(logBackend, logLevel, cfg.Logging.SampleRate)
+
 	// For bid processing, we need both the hardcoded certificates and the certificates found in container's
 	// local file system
 	certPool := ssl.GetRootCAPool()
@@ -151,13 +176,13 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		},
 	}
 
-	if err := checkSupportedUserSyncEndpoints(cfg.BidderInfos); err != nil {
+	syncersByBidder, err := buildSyncers(cfg)
+	if err != nil {
 		return nil, err
 	}
-
-	syncersByBidder, errs := usersync.BuildSyncers(cfg, cfg.BidderInfos)
-	if len(errs) > 0 {
-		return nil, errortypes.NewAggregateError("user sync", errs)
+	var bidRates *usersync.BidRates
+	if cfg.UserSync.Prioritization.BidRateWindowMinutes > 0 {
+		bidRates = usersync.NewBidRates(time.Duration(cfg.UserSync.Prioritization.BidRateWindowMinutes) * time.Minute)
 	}
 
 	syncerKeys := make([]string, 0, len(syncersByBidder))
@@ -169,80 +194,109 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		syncerKeys = append(syncerKeys, k)
 	}
 
-	moduleDeps := moduledeps.ModuleDeps{HTTPClient: generalHttpClient}
-	repo, moduleStageNames, err := modules.NewBuilder().Build(cfg.Hooks.Modules, moduleDeps)
+	vendorListFetcher := gdpr.NewVendorListFetcher(context.Background(), cfg.GDPR, generalHttpClient, gdpr.VendorListURLMaker)
+	tcf2CfgBuilder := gdpr.NewTCF2Config
+
+	// the hook modules are built once, so they check the permissions with the bidder infos of the current
+	// endpoints
+	moduleDeps := moduledeps.ModuleDeps{
+		HTTPClient:             generalHttpClient,
+		GDPRPermissionsBuilder: r.gdprPermissions,
+		TCF2ConfigBuilder:      tcf2CfgBuilder,
+		HostTCF2Config:         cfg.GDPR.TCF2,
+	}
+	moduleBuilder := modules.NewBuilder()
+	repo, moduleStageNames, err := moduleBuilder.Build(cfg.Hooks.Modules, moduleDeps)
 	if err != nil {
 		glog.Fatalf("Failed to init hook modules: %v", err)
 	}
 
 	// Metrics engine
 	r.MetricsEngine = metricsConf.NewMetricsEngine(cfg, openrtb_ext.CoreBidderNames(), syncerKeys, moduleStageNames)
-	shutdown, fetcher, ampFetcher, accounts, categoriesFetcher, videoFetcher, storedRespFetcher := storedRequestsConf.NewStoredRequests(cfg, r.MetricsEngine, generalHttpClient, r.Router)
+	cookies, err := usersync.NewCookies(uidstore.NewUIDStore(cfg.UserSync.UIDStore), cfg.HostCookie.Encryption, r.MetricsEngine)
+	if err != nil {
+		return nil, err
+	}
+	shutdown, fetcher, ampFetcher, accounts, categoriesFetcher, videoFetcher, storedRespFetcher, storedCaches := storedRequestsConf.NewStoredRequests(cfg, r.MetricsEngine, generalHttpClient, r.Router)
 	// todo(zachbadgett): better shutdown
-	r.Shutdown = shutdown
+	r.Shutdown = func() {
+		shutdown()
+		moduleBuilder.Shutdown()
+	}
 
-	pbsAnalytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics)
+	pbsAnalytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics, r.MetricsEngine)
 
 	paramsValidator, err := openrtb_ext.NewBidderParamsValidator(schemaDirectory)
 	if err != nil {
 		glog.Fatalf("Failed to create the bidder params validator. %v", err)
 	}
 
-	activeBidders := exchange.GetActiveBidders(cfg.BidderInfos)
-	disabledBidders := exchange.GetDisabledBiddersErrorMessages(cfg.BidderInfos)
-
 	defaultAliases, defReqJSON := readDefaultRequest(cfg.DefReqConfig)
 	if err := validateDefaultAliases(defaultAliases); err != nil {
 		return nil, err
 	}
 
-	gvlVendorIDs := cfg.BidderInfos.ToGVLVendorIDMap()
-	vendorListFetcher := gdpr.NewVendorListFetcher(context.Background(), cfg.GDPR, generalHttpClient, gdpr.VendorListURLMaker)
-	gdprPermsBuilder := gdpr.NewPermissionsBuilder(cfg.GDPR, gvlVendorIDs, vendorListFetcher)
-	tcf2CfgBuilder := gdpr.NewTCF2Config
-
-	cacheClient := pbc.NewClient(cacheHttpClient, &cfg.CacheURL, &cfg.ExtCacheURL, r.MetricsEngine)
-
-	adapters, adaptersErrs := exchange.BuildAdapters(generalHttpClient, cfg, cfg.BidderInfos, r.MetricsEngine)
-	if len(adaptersErrs) > 0 {
-		errs := errortypes.NewAggregateError("Failed to initialize adapters", adaptersErrs)
-		return nil, errs
+	var cacheClient pbc.Client
+	var localCache *pbc.LocalCache
+	if cfg.CacheURL.Local.Enabled {
+		localCache = pbc.NewLocalCache(&cfg.CacheURL, &cfg.ExtCacheURL, r.MetricsEngine)
+		cacheClient = localCache
+	} else {
+		cacheClient = pbc.NewClient(cacheHttpClient, &cfg.CacheURL, &cfg.ExtCacheURL, r.MetricsEngine)
 	}
-	adsCertSigner, err := adscert.NewAdCertsSigner(cfg.Experiment.AdCerts)
-	if err != nil {
-		glog.Fatalf("Failed to create ads cert signer: %v", err)
+	if shutdowner, ok := cacheClient.(pbc.Shutdowner); ok {
+		shutdown := r.Shutdown
+		r.Shutdown = func() {
+			shutdown()
+			shutdowner.Shutdown()
+		}
 	}
 
-	planBuilder := hooks.NewExecutionPlanBuilder(cfg.Hooks, repo)
-	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, syncersByBidder, r.MetricsEngine, cfg.BidderInfos, gdprPermsBuilder, tcf2CfgBuilder, rateConvertor, categoriesFetcher, adsCertSigner)
-	var uuidGenerator uuidutil.UUIDRandomGenerator
-	openrtbEndpoint, err := openrtb2.NewEndpoint(uuidGenerator, theExchange, paramsValidator, fetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder)
+	adsCertSigner, err := adscert.NewAdCertsSigner(cfg.Experiment.AdCerts)
 	if err != nil {
-		glog.Fatalf("Failed to create the openrtb2 endpoint handler. %v", err)
+		glog.Fatalf("Failed to create ads cert signer: %v", err)
 	}
 
-	ampEndpoint, err := openrtb2.NewAmpEndpoint(uuidGenerator, theExchange, paramsValidator, ampFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder)
-	if err != nil {
-		glog.Fatalf("Failed to create the amp endpoint handler. %v", err)
+	r.deps = &endpointDeps{
+		httpClient:        generalHttpClient,
+		metricsEngine:     r.MetricsEngine,
+		rateConvertor:     rateConvertor,
+		vendorListFetcher: vendorListFetcher,
+		tcf2CfgBuilder:    tcf2CfgBuilder,
+		cacheClient:       cacheClient,
+		fetcher:           fetcher,
+		ampFetcher:        ampFetcher,
+		accounts:          accounts,
+		categoriesFetcher: categoriesFetcher,
+		videoFetcher:      videoFetcher,
+		storedRespFetcher: storedRespFetcher,
+		pbsAnalytics:      pbsAnalytics,
+		paramsValidator:   paramsValidator,
+		adsCertSigner:     adsCertSigner,
+		planBuilder:       hooks.NewExecutionPlanBuilder(cfg.Hooks, repo),
+		defaultAliases:    defaultAliases,
+		defReqJSON:        defReqJSON,
+		storedCaches:      storedCaches,
+		cookies:           cookies,
+		bidRates:          bidRates,
+		disabledBidders:   exchange.NewDisabledBidders(),
+		throttler:         throttling.NewThrottler(cfg.LoadShedding, r.MetricsEngine),
+		loggers:           loggers,
 	}
-
-	videoEndpoint, err := openrtb2.NewVideoEndpoint(uuidGenerator, theExchange, paramsValidator, fetcher, videoFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, cacheClient)
+	reloadable, err := buildEndpoints(cfg, r.deps, syncersByBidder, nil)
 	if err != nil {
-		glog.Fatalf("Failed to create the video endpoint handler. %v", err)
-	}
-
-	requestTimeoutHeaders := config.RequestTimeoutHeaders{}
-	if cfg.RequestTimeoutHeaders != requestTimeoutHeaders {
-		videoEndpoint = aspects.QueuedRequestTimeout(videoEndpoint, cfg.RequestTimeoutHeaders, r.MetricsEngine, metrics.ReqTypeVideo)
+		return nil, err
 	}
-
-	r.POST("/openrtb2/auction", openrtbEndpoint)
-	r.POST("/openrtb2/video", videoEndpoint)
-	r.GET("/openrtb2/amp", ampEndpoint)
-	r.GET("/info/bidders", infoEndpoints.NewBiddersEndpoint(cfg.BidderInfos, defaultAliases))
-	r.GET("/info/bidders/:bidderName", infoEndpoints.NewBiddersDetailEndpoint(cfg.BidderInfos, defaultAliases))
+	r.endpoints.Store(reloadable)
+	r.cfg = cfg
+
+	r.POST("/openrtb2/auction", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.auction }))
+	r.POST("/openrtb2/video", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.video }))
+	r.GET("/openrtb2/amp", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.amp }))
+	r.GET("/info/bidders", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.infoBidders }))
+	r.GET("/info/bidders/:bidderName", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.infoBidderDetail }))
 	r.GET("/bidders/params", NewJsonDirectoryServer(schemaDirectory, paramsValidator, defaultAliases))
-	r.POST("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPermsBuilder, tcf2CfgBuilder, r.MetricsEngine, pbsAnalytics, accounts, activeBidders).Handle)
+	r.POST("/cookie_sync", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.cookieSync }))
 	r.GET("/status", endpoints.NewStatusEndpoint(cfg.StatusResponse))
 	r.GET("/", serveIndex)
 	r.Handler("GET", "/version", endpoints.NewVersionEndpoint(version.Ver, version.Rev))
@@ -250,28 +304,102 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 
 	// vtrack endpoint
 	if cfg.VTrack.Enabled {
-		vtrackEndpoint := events.NewVTrackEndpoint(cfg, accounts, cacheClient, cfg.BidderInfos)
-		r.POST("/vtrack", vtrackEndpoint)
+		r.POST("/vtrack", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.vtrack }))
+	}
+
+	// in-process cache endpoint, replacing Prebid Cache
+	if localCache != nil {
+		r.GET("/cache", localCache.Handle)
 	}
 
 	// event endpoint
-	eventEndpoint := events.NewEventEndpoint(cfg, accounts, pbsAnalytics)
-	r.GET("/event", eventEndpoint)
+	r.GET("/event", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.event }))
 
 	userSyncDeps := &pbs.UserSyncDeps{
 		HostCookieConfig: &(cfg.HostCookie),
 		ExternalUrl:      cfg.ExternalURL,
 		RecaptchaSecret:  cfg.RecaptchaSecret,
+		Cookies:          cookies,
 	}
 
-	r.GET("/setuid", endpoints.NewSetUIDEndpoint(cfg, syncersByBidder, gdprPermsBuilder, tcf2CfgBuilder, pbsAnalytics, accounts, r.MetricsEngine))
-	r.GET("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie))
+	r.GET("/setuid", r.reloadable(func(e *reloadableEndpoints) httprouter.Handle { return e.setUID }))
+	r.GET("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie, cookies))
 	r.POST("/optout", userSyncDeps.OptOut)
 	r.GET("/optout", userSyncDeps.OptOut)
 
 	return r, nil
 }
 
+// buildSyncers builds the user syncers of the bidder infos.
+func buildSyncers(cfg *config.Configuration) (map[string]usersync.Syncer, error) {
+	if err := checkSupportedUserSyncEndpoints(cfg.BidderInfos); err != nil {
+		return nil, err
+	}
+
+	syncersByBidder, errs := usersync.BuildSyncers(cfg, cfg.BidderInfos)
+	if len(errs) > 0 {
+		return nil, errortypes.NewAggregateError("user sync", errs)
+	}
+	return syncersByBidder, nil
+}
+
+// buildEndpoints builds the endpoints depending on the reloadable parts of the configuration. The exchange
+// inherits the state of the exchange of the previous endpoints, if any.
+func buildEndpoints(cfg *config.Configuration, deps *endpointDeps, syncersByBidder map[string]usersync.Syncer, previous *reloadableEndpoints) (*reloadableEndpoints, error) {
+	activeBidders := exchange.GetActiveBidders(cfg.BidderInfos)
+	disabledBidders := exchange.GetDisabledBiddersErrorMessages(cfg.BidderInfos)
+
+	// the analytics modules log the shadow requests if they support them
+	shadowLogger, _ := deps.pbsAnalytics.(analytics.ShadowLogger)
+	adapters, adaptersErrs := exchange.BuildAdapters(deps.httpClient, cfg, cfg.BidderInfos, deps.metricsEngine, shadowLogger)
+	if len(adaptersErrs) > 0 {
+		return nil, errortypes.NewAggregateError("Failed to initialize adapters", adaptersErrs)
+	}
+
+	// the GVL vendor ids of the permissions depend on the bidder infos
+	gdprPermsBuilder := gdpr.NewPermissionsBuilder(cfg.GDPR, cfg.BidderInfos.ToGVLVendorIDMap(), deps.vendorListFetcher)
+
+	theExchange := exchange.NewExchange(adapters, deps.cacheClient, cfg, syncersByBidder, deps.metricsEngine, cfg.BidderInfos, gdprPermsBuilder, deps.tcf2CfgBuilder, deps.rateConvertor, deps.categoriesFetcher, deps.adsCertSigner, deps.bidRates, deps.disabledBidders)
+	if previous != nil {
+		exchange.InheritState(theExchange, previous.exchange)
+	}
+	var uuidGenerator uuidutil.UUIDRandomGenerator
+	openrtbEndpoint, err := openrtb2.NewEndpoint(uuidGenerator, theExchange, deps.paramsValidator, deps.fetcher, deps.accounts, cfg, deps.metricsEngine, deps.pbsAnalytics, disabledBidders, deps.defReqJSON, activeBidders, deps.storedRespFetcher, deps.planBuilder, deps.cookies, deps.throttler, deps.loggers)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the openrtb2 endpoint handler. %v", err)
+	}
+
+	ampEndpoint, err := openrtb2.NewAmpEndpoint(uuidGenerator, theExchange, deps.paramsValidator, deps.ampFetcher, deps.accounts, cfg, deps.metricsEngine, deps.pbsAnalytics, disabledBidders, deps.defReqJSON, activeBidders, deps.storedRespFetcher, deps.planBuilder, deps.cookies, deps.throttler, deps.loggers)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the amp endpoint handler. %v", err)
+	}
+
+	videoEndpoint, err := openrtb2.NewVideoEndpoint(uuidGenerator, theExchange, deps.paramsValidator, deps.fetcher, deps.videoFetcher, deps.accounts, cfg, deps.metricsEngine, deps.pbsAnalytics, disabledBidders, deps.defReqJSON, activeBidders, deps.cacheClient, deps.cookies, deps.loggers)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the video endpoint handler. %v", err)
+	}
+
+	requestTimeoutHeaders := config.RequestTimeoutHeaders{}
+	if cfg.RequestTimeoutHeaders != requestTimeoutHeaders {
+		videoEndpoint = aspects.QueuedRequestTimeout(videoEndpoint, cfg.RequestTimeoutHeaders, deps.metricsEngine, metrics.ReqTypeVideo)
+	}
+
+	return &reloadableEndpoints{
+		cfg:              cfg,
+		exchange:         theExchange,
+		gdprPermsBuilder: gdprPermsBuilder,
+		auction:          openrtbEndpoint,
+		amp:              ampEndpoint,
+		video:            videoEndpoint,
+		infoBidders:      infoEndpoints.NewBiddersEndpoint(cfg.BidderInfos, deps.defaultAliases),
+		infoBidderDetail: infoEndpoints.NewBiddersDetailEndpoint(cfg.BidderInfos, deps.defaultAliases),
+		cookieSync:       endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPermsBuilder, deps.tcf2CfgBuilder, deps.metricsEngine, deps.pbsAnalytics, deps.accounts, activeBidders, deps.cookies, deps.bidRates).Handle,
+		setUID:           endpoints.NewSetUIDEndpoint(cfg, syncersByBidder, gdprPermsBuilder, deps.tcf2CfgBuilder, deps.pbsAnalytics, deps.accounts, deps.metricsEngine, deps.cookies),
+		vtrack:           events.NewVTrackEndpoint(cfg, deps.accounts, deps.cacheClient, cfg.BidderInfos),
+		event:            events.NewEventEndpoint(cfg, deps.accounts, deps.pbsAnalytics),
+	}, nil
+}
+
 func checkSupportedUserSyncEndpoints(bidderInfos config.BidderInfos) error {
 	for name, info := range bidderInfos {
 		if info.Syncer == nil {
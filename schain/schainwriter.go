@@ -1,8 +1,6 @@
 package schain
 
 import (
-	"encoding/json"
-
 	"github.com/prebid/openrtb/v17/openrtb2"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
@@ -26,14 +24,14 @@ func NewSChainWriter(reqExt *openrtb_ext.ExtRequest, hostSChainNode *openrtb2.Su
 }
 
 // SChainWriter is used to write the appropriate schain for a particular bidder defined in the ORTB 2.5 multi-schain
-// location (req.ext.prebid.schain) to the ORTB 2.5 location (req.source.ext)
+// location (req.ext.prebid.schain) to the ORTB 2.6 location (req.source.schain)
 type SChainWriter struct {
 	sChainsByBidder map[string]*openrtb2.SupplyChain
 	hostSChainNode  *openrtb2.SupplyChainNode
 }
 
 // Write selects an schain from the multi-schain ORTB 2.5 location (req.ext.prebid.schains) for the specified bidder
-// and copies it to the ORTB 2.5 location (req.source.ext). If no schain exists for the bidder in the multi-schain
+// and copies it to the ORTB 2.6 location (req.source.schain). If no schain exists for the bidder in the multi-schain
 // location and no wildcard schain exists, the request is not modified.
 func (w SChainWriter) Write(req *openrtb2.BidRequest, bidder string) {
 	const sChainWildCard = "*"
@@ -55,9 +53,7 @@ func (w SChainWriter) Write(req *openrtb2.BidRequest, bidder string) {
 		selectedSChain = wildCardSChain
 	}
 
-	schain := openrtb_ext.ExtRequestPrebidSChain{
-		SChain: *selectedSChain,
-	}
+	schain := *selectedSChain
 
 	if req.Source == nil {
 		req.Source = &openrtb2.Source{}
@@ -67,14 +63,10 @@ func (w SChainWriter) Write(req *openrtb2.BidRequest, bidder string) {
 	}
 
 	if w.hostSChainNode != nil {
-		schain.SChain.Nodes = append(schain.SChain.Nodes, *w.hostSChainNode)
-	}
-
-	sourceExt, err := json.Marshal(schain)
-	if err == nil {
-		req.Source.Ext = sourceExt
+		schain.Nodes = append(append(make([]openrtb2.SupplyChainNode, 0, len(schain.Nodes)+1), schain.Nodes...), *w.hostSChainNode)
 	}
 
+	req.Source.SChain = &schain
 }
 
 // extPrebidSChainExists checks if an schain exists in the ORTB 2.5 req.ext.prebid.schain location
@@ -44,14 +44,14 @@ func TestSChainWriter(t *testing.T) {
 			giveRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{` + seller2SChain + `}`),
+					SChain: mustSChain(t, seller2SChain),
 				},
 			},
 			giveBidder: "appnexus",
 			wantRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{` + seller2SChain + `}`),
+					SChain: mustSChain(t, seller2SChain),
 				},
 			},
 		},
@@ -60,26 +60,26 @@ func TestSChainWriter(t *testing.T) {
 			giveRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `}]}}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{` + seller2SChain + `}`),
+					SChain: mustSChain(t, seller2SChain),
 				},
 			},
 			giveBidder: "rubicon",
 			wantRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `}]}}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{` + seller2SChain + `}`),
+					SChain: mustSChain(t, seller2SChain),
 				},
 			},
 		},
 		{
-			description: "Use schain for bidder in ext.prebid.schains; ensure other ext.source field values are retained.",
+			description: "Use schain for bidder in ext.prebid.schains; ensure other source field values are retained.",
 			giveRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `}]}}`),
 				Source: &openrtb2.Source{
 					FD:     1,
 					TID:    "tid data",
 					PChain: "pchain data",
-					Ext:    json.RawMessage(`{` + seller2SChain + `}`),
+					SChain: mustSChain(t, seller2SChain),
 				},
 			},
 			giveBidder: "appnexus",
@@ -89,7 +89,7 @@ func TestSChainWriter(t *testing.T) {
 					FD:     1,
 					TID:    "tid data",
 					PChain: "pchain data",
-					Ext:    json.RawMessage(`{` + seller1SChain + `}`),
+					SChain: mustSChain(t, seller1SChain),
 				},
 			},
 		},
@@ -103,7 +103,7 @@ func TestSChainWriter(t *testing.T) {
 			wantRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `}]}}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{` + seller1SChain + `}`),
+					SChain: mustSChain(t, seller1SChain),
 				},
 			},
 		},
@@ -112,14 +112,14 @@ func TestSChainWriter(t *testing.T) {
 			giveRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["*"],` + sellerWildCardSChain + `}]}}`),
 				Source: &openrtb2.Source{
-					Ext: nil,
+					SChain: nil,
 				},
 			},
 			giveBidder: "appnexus",
 			wantRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["*"],` + sellerWildCardSChain + `}]}}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{` + sellerWildCardSChain + `}`),
+					SChain: mustSChain(t, sellerWildCardSChain),
 				},
 			},
 		},
@@ -128,14 +128,14 @@ func TestSChainWriter(t *testing.T) {
 			giveRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `},{"bidders":["*"],` + sellerWildCardSChain + `}]}}`),
 				Source: &openrtb2.Source{
-					Ext: nil,
+					SChain: nil,
 				},
 			},
 			giveBidder: "appnexus",
 			wantRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `},{"bidders":["*"],` + sellerWildCardSChain + `}]}}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{` + seller1SChain + `}`),
+					SChain: mustSChain(t, seller1SChain),
 				},
 			},
 		},
@@ -144,20 +144,20 @@ func TestSChainWriter(t *testing.T) {
 			giveRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `},{"bidders":["appnexus"],` + seller2SChain + `}]}}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{` + seller3SChain + `}`),
+					SChain: mustSChain(t, seller3SChain),
 				},
 			},
 			giveBidder: "appnexus",
 			wantRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["appnexus"],` + seller1SChain + `},{"bidders":["appnexus"],` + seller2SChain + `}]}}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{` + seller3SChain + `}`),
+					SChain: mustSChain(t, seller3SChain),
 				},
 			},
 			wantError: true,
 		},
 		{
-			description: "Schain in request, host schain defined, source.ext for bidder request should update with appended host schain",
+			description: "Schain in request, host schain defined, source.schain for bidder request should update with appended host schain",
 			giveRequest: openrtb2.BidRequest{
 				Ext:    json.RawMessage(`{"prebid":{"schains":[{"bidders":["testbidder"],"schain":{"complete":1,"nodes":[` + seller1Node + `],"ver":"1.0"}}]}}`),
 				Source: nil,
@@ -169,12 +169,12 @@ func TestSChainWriter(t *testing.T) {
 			wantRequest: openrtb2.BidRequest{
 				Ext: json.RawMessage(`{"prebid":{"schains":[{"bidders":["testbidder"],"schain":{"complete":1,"nodes":[` + seller1Node + `],"ver":"1.0"}}]}}`),
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{"schain":{"complete":1,"nodes":[` + seller1Node + `,` + hostNode + `],"ver":"1.0"}}`),
+					SChain: mustSChain(t, `"schain":{"complete":1,"nodes":[`+seller1Node+`,`+hostNode+`],"ver":"1.0"}`),
 				},
 			},
 		},
 		{
-			description: "No Schain in request, host schain defined, source.ext for bidder request should have just the host schain",
+			description: "No Schain in request, host schain defined, source.schain for bidder request should have just the host schain",
 			giveRequest: openrtb2.BidRequest{
 				Ext:    nil,
 				Source: nil,
@@ -186,7 +186,7 @@ func TestSChainWriter(t *testing.T) {
 			wantRequest: openrtb2.BidRequest{
 				Ext: nil,
 				Source: &openrtb2.Source{
-					Ext: json.RawMessage(`{"schain":{"complete":0,"nodes":[` + hostNode + `],"ver":"1.0"}}`),
+					SChain: mustSChain(t, `"schain":{"complete":0,"nodes":[`+hostNode+`],"ver":"1.0"}`),
 				},
 			},
 		},
@@ -218,3 +218,11 @@ func TestSChainWriter(t *testing.T) {
 		}
 	}
 }
+
+func mustSChain(t *testing.T, schain string) *openrtb2.SupplyChain {
+	var ext openrtb_ext.ExtRequestPrebidSChain
+	if err := json.Unmarshal([]byte(`{`+schain+`}`), &ext); err != nil {
+		t.Fatalf("Unable to unmarshal schain: %v", err)
+	}
+	return &ext.SChain
+}
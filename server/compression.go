@@ -0,0 +1,171 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/prebid/prebid-server/util/compressutil"
+)
+
+// minCompressedSize is the size under which the responses aren't compressed: compressing them saves too
+// few bytes to be worth the CPU.
+const minCompressedSize = 1024
+
+// newCompressionHandler compresses the responses of the handler with the first of the encodings accepted
+// by the client. It returns the handler as is if no encoding is offered.
+func newCompressionHandler(encodings []string, handler http.Handler) http.Handler {
+	if len(encodings) == 0 {
+		return handler
+	}
+	return &compressionHandler{
+		encodings: encodings,
+		handler:   handler,
+	}
+}
+
+type compressionHandler struct {
+	encodings []string
+	handler   http.Handler
+}
+
+func (h *compressionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	encoding := compressutil.Negotiate(r.Header.Get("Accept-Encoding"), h.encodings)
+	if encoding == "" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding}
+	defer cw.Close()
+	h.handler.ServeHTTP(cw, r)
+}
+
+// compressResponseWriter compresses the response body. The body is buffered until it reaches
+// minCompressedSize, so the headers are only sent then, and the responses without a body, smaller than
+// minCompressedSize, or already encoded by the handler, aren't compressed.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding  string
+	status    int
+	committed bool
+	buffer    []byte
+	writer    io.WriteCloser
+}
+
+var (
+	_ http.Flusher  = (*compressResponseWriter)(nil)
+	_ http.Hijacker = (*compressResponseWriter)(nil)
+)
+
+func (w *compressResponseWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	if !bodyAllowed(status) || w.Header().Get("Content-Encoding") != "" {
+		w.commit(false)
+	}
+}
+
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.committed {
+		if len(w.buffer) == 0 && len(b) > 0 && w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		if w.Header().Get("Content-Encoding") == "" && len(w.buffer)+len(b) < minCompressedSize {
+			w.buffer = append(w.buffer, b...)
+			return len(b), nil
+		}
+		if err := w.commitBuffer(w.Header().Get("Content-Encoding") == ""); err != nil {
+			return 0, err
+		}
+	}
+	return w.write(b)
+}
+
+func (w *compressResponseWriter) write(b []byte) (int, error) {
+	if w.writer != nil {
+		return w.writer.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the body compressed so far to the client.
+func (w *compressResponseWriter) Flush() {
+	if !w.committed {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.commitBuffer(bodyAllowed(w.status) && w.Header().Get("Content-Encoding") == "")
+	}
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, if the underlying response writer supports it.
+func (w *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer doesn't support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		// the handler writes to the connection, nothing must be written on Close
+		w.committed = true
+	}
+	return conn, rw, err
+}
+
+// Close finishes the compressed body, or sends the headers and the buffered body of a response which
+// isn't compressed.
+func (w *compressResponseWriter) Close() error {
+	if !w.committed {
+		if w.status != 0 {
+			return w.commitBuffer(false)
+		}
+		return nil
+	}
+	if w.writer != nil {
+		return w.writer.Close()
+	}
+	return nil
+}
+
+// commitBuffer sends the headers, then the body buffered so far.
+func (w *compressResponseWriter) commitBuffer(compress bool) error {
+	w.commit(compress)
+	if len(w.buffer) == 0 {
+		return nil
+	}
+	_, err := w.write(w.buffer)
+	w.buffer = nil
+	return err
+}
+
+func (w *compressResponseWriter) commit(compress bool) {
+	w.committed = true
+	if compress {
+		if writer, err := compressutil.NewWriter(w.encoding, w.ResponseWriter); err == nil {
+			w.Header().Set("Content-Encoding", w.encoding)
+			w.Header().Del("Content-Length")
+			w.writer = writer
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
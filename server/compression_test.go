@@ -0,0 +1,240 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prebid/prebid-server/util/compressutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionHandler(t *testing.T) {
+	body := []byte(`{"id":"some-request-id","seatbid":[` + strings.Repeat(`{"seat":"some-bidder","bid":[]},`, 50) + `]}`)
+	smallBody := []byte(`{"id":"some-request-id","seatbid":[]}`)
+
+	testCases := []struct {
+		description      string
+		encodings        []string
+		acceptEncoding   string
+		handler          http.HandlerFunc
+		expectedStatus   int
+		expectedEncoding string
+		expectedBody     []byte
+	}{
+		{
+			description:    "Compression Disabled",
+			encodings:      nil,
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(body)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   body,
+		},
+		{
+			description:    "Not Accepted By The Client",
+			encodings:      []string{compressutil.Gzip},
+			acceptEncoding: "",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(body)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   body,
+		},
+		{
+			description:    "Gzip",
+			encodings:      []string{compressutil.Gzip},
+			acceptEncoding: "gzip, deflate",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(body)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedEncoding: compressutil.Gzip,
+			expectedBody:     body,
+		},
+		{
+			description:    "Brotli",
+			encodings:      []string{compressutil.Brotli, compressutil.Gzip},
+			acceptEncoding: "gzip, br",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write(body)
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectedEncoding: compressutil.Brotli,
+			expectedBody:     body,
+		},
+		{
+			description:    "Zstd",
+			encodings:      []string{compressutil.Zstd},
+			acceptEncoding: "zstd",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(body)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedEncoding: compressutil.Zstd,
+			expectedBody:     body,
+		},
+		{
+			description:    "Below The Minimum Size",
+			encodings:      []string{compressutil.Gzip},
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(smallBody)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   smallBody,
+		},
+		{
+			description:    "Minimum Size Reached In Several Writes",
+			encodings:      []string{compressutil.Gzip},
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(body[:10])
+				w.Write(body[10:])
+			},
+			expectedStatus:   http.StatusOK,
+			expectedEncoding: compressutil.Gzip,
+			expectedBody:     body,
+		},
+		{
+			description:    "Flushed Below The Minimum Size",
+			encodings:      []string{compressutil.Gzip},
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write(smallBody)
+				w.(http.Flusher).Flush()
+			},
+			expectedStatus:   http.StatusOK,
+			expectedEncoding: compressutil.Gzip,
+			expectedBody:     smallBody,
+		},
+		{
+			description:    "No Body",
+			encodings:      []string{compressutil.Gzip},
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			expectedStatus: http.StatusNoContent,
+			expectedBody:   []byte{},
+		},
+		{
+			description:    "Already Encoded By The Handler",
+			encodings:      []string{compressutil.Gzip},
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "custom")
+				w.Write(body)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedEncoding: "custom",
+			expectedBody:     body,
+		},
+	}
+
+	for _, test := range testCases {
+		handler := newCompressionHandler(test.encodings, test.handler)
+
+		req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+		req.Header.Set("Accept-Encoding", test.acceptEncoding)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.description+":status")
+		assert.Equal(t, test.expectedEncoding, recorder.Header().Get("Content-Encoding"), test.description+":encoding")
+
+		respBody := recorder.Body.Bytes()
+		if test.expectedEncoding != "" && test.expectedEncoding != "custom" {
+			reader, err := compressutil.NewReader(test.expectedEncoding, bytes.NewReader(respBody))
+			if !assert.NoError(t, err, test.description) {
+				continue
+			}
+			respBody, err = io.ReadAll(reader)
+			assert.NoError(t, err, test.description)
+		}
+		assert.Equal(t, string(test.expectedBody), string(respBody), test.description+":body")
+
+		if len(test.encodings) > 0 {
+			assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"), test.description+":vary")
+		}
+	}
+}
+
+func TestCompressionHandlerFlush(t *testing.T) {
+	var flushedBody []byte
+	handler := newCompressionHandler([]string{compressutil.Gzip}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("some-event"))
+		w.(http.Flusher).Flush()
+		flushedBody = w.(*compressResponseWriter).ResponseWriter.(*httptest.ResponseRecorder).Body.Bytes()
+	}))
+
+	req := httptest.NewRequest("GET", "/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.True(t, recorder.Flushed, "flushed")
+	reader, err := compressutil.NewReader(compressutil.Gzip, bytes.NewReader(flushedBody))
+	if assert.NoError(t, err) {
+		event := make([]byte, len("some-event"))
+		_, err = io.ReadFull(reader, event)
+		assert.NoError(t, err)
+		assert.Equal(t, "some-event", string(event), "sent before the handler returns")
+	}
+}
+
+func TestCompressionHandlerHijack(t *testing.T) {
+	testCases := []struct {
+		description    string
+		responseWriter http.ResponseWriter
+		expectedErr    bool
+	}{
+		{
+			description:    "Supported",
+			responseWriter: &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()},
+		},
+		{
+			description:    "Not Supported",
+			responseWriter: httptest.NewRecorder(),
+			expectedErr:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		var hijackErr error
+		handler := newCompressionHandler([]string{compressutil.Gzip}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _, hijackErr = w.(http.Hijacker).Hijack()
+		}))
+
+		req := httptest.NewRequest("GET", "/socket", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		handler.ServeHTTP(test.responseWriter, req)
+
+		if test.expectedErr {
+			assert.Error(t, hijackErr, test.description)
+			continue
+		}
+		assert.NoError(t, hijackErr, test.description)
+		recorder := test.responseWriter.(*hijackableRecorder)
+		assert.True(t, recorder.hijacked, test.description+":hijacked")
+		assert.Zero(t, recorder.Body.Len(), test.description+":nothing written after the hijack")
+		assert.Empty(t, recorder.Header().Get("Content-Encoding"), test.description+":encoding")
+	}
+}
+
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/lib/pq"
 
 	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/logger"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/db_provider"
 )
@@ -62,7 +63,7 @@ func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string
 	rows, err := fetcher.provider.QueryContext(ctx, fetcher.queryTemplate, params...)
 	if err != nil {
 		if err != context.DeadlineExceeded && !isBadInput(err) {
-			glog.Errorf("Error reading from Stored Request DB: %s", err.Error())
+			logger.FromContext(ctx).Errorf("Error reading from Stored Request DB: %s", err.Error())
 			errs := appendErrors("Request", requestIDs, nil, nil)
 			errs = appendErrors("Imp", impIDs, nil, errs)
 			return nil, nil, errs
@@ -71,7 +72,7 @@ func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
-			glog.Errorf("error closing DB connection: %v", err)
+			logger.FromContext(ctx).Errorf("error closing DB connection: %v", err)
 		}
 	}()
 
@@ -93,7 +94,7 @@ func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string
 		case "imp":
 			storedImpData[id] = data
 		default:
-			glog.Errorf("Database result set with id=%s has invalid type: %s. This will be ignored.", id, dataType)
+			logger.FromContext(ctx).Errorf("Database result set with id=%s has invalid type: %s. This will be ignored.", id, dataType)
 		}
 	}
 
@@ -127,7 +128,7 @@ func (fetcher *dbFetcher) FetchResponses(ctx context.Context, ids []string) (dat
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
-			glog.Errorf("error closing DB connection: %v", err)
+			logger.FromContext(ctx).Errorf("error closing DB connection: %v", err)
 		}
 	}()
 
@@ -106,6 +106,11 @@ func (provider *PostgresDbProvider) QueryContext(ctx context.Context, template s
 	return provider.db.QueryContext(ctx, query, args...)
 }
 
+func (provider *PostgresDbProvider) ExecContext(ctx context.Context, template string, params ...QueryParam) (sql.Result, error) {
+	query, args := provider.PrepareQuery(template, params...)
+	return provider.db.ExecContext(ctx, query, args...)
+}
+
 func (provider *PostgresDbProvider) createIdList(numSoFar int, numArgs int) string {
 	// Any empty list like "()" is illegal in Postgres. A (NULL) is the next best thing,
 	// though, since `id IN (NULL)` is valid for all "id" column types, and evaluates to an empty set.
@@ -65,3 +65,8 @@ func (c *cache) Invalidate(ctx context.Context, ids []string) {
 		c.cache.Delete(id)
 	}
 }
+
+// Entries returns a copy of the data currently held in the cache.
+func (c *cache) Entries() map[string]json.RawMessage {
+	return c.cache.Entries()
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/caches/cachestest"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestLRURobustness(t *testing.T) {
@@ -23,6 +24,33 @@ func TestUnboundedRobustness(t *testing.T) {
 	})
 }
 
+func TestEntries(t *testing.T) {
+	testCases := []struct {
+		description string
+		cache       stored_requests.CacheJSON
+	}{
+		{
+			description: "LRU",
+			cache:       NewCache(256*1024, -1, "TestData"),
+		},
+		{
+			description: "Unbounded",
+			cache:       NewCache(0, -1, "TestData"),
+		},
+	}
+
+	for _, test := range testCases {
+		test.cache.Save(context.Background(), map[string]json.RawMessage{"one": json.RawMessage(`{"id":1}`), "two": json.RawMessage(`{"id":2}`)})
+		test.cache.Invalidate(context.Background(), []string{"two"})
+
+		inspector, ok := test.cache.(stored_requests.CacheInspector)
+		if !assert.True(t, ok, test.description+":inspector") {
+			continue
+		}
+		assert.Equal(t, map[string]json.RawMessage{"one": json.RawMessage(`{"id":1}`)}, inspector.Entries(), test.description+":entries")
+	}
+}
+
 func TestRaceLRUConcurrency(t *testing.T) {
 	cache := NewCache(256*1024, -1, "TestData")
 	doRaceTest(t, cache)
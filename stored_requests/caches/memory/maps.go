@@ -16,6 +16,7 @@ type mapLike interface {
 	Get(id string) (json.RawMessage, bool)
 	Set(id string, value json.RawMessage)
 	Delete(id string)
+	Entries() map[string]json.RawMessage
 }
 
 // sync.Map wrapper which implements the interface
@@ -40,6 +41,15 @@ func (m *pbsSyncMap) Delete(id string) {
 	m.Map.Delete(id)
 }
 
+func (m *pbsSyncMap) Entries() map[string]json.RawMessage {
+	entries := make(map[string]json.RawMessage)
+	m.Map.Range(func(key, value interface{}) bool {
+		entries[key.(string)] = value.(json.RawMessage)
+		return true
+	})
+	return entries
+}
+
 // lruCache wrapper which implements the interface
 type pbsLRUCache struct {
 	*freecache.Cache
@@ -66,3 +76,12 @@ func (m *pbsLRUCache) Set(id string, value json.RawMessage) {
 func (m *pbsLRUCache) Delete(id string) {
 	m.Cache.Del([]byte(id))
 }
+
+func (m *pbsLRUCache) Entries() map[string]json.RawMessage {
+	entries := make(map[string]json.RawMessage, m.Cache.EntryCount())
+	it := m.Cache.NewIterator()
+	for entry := it.Next(); entry != nil; entry = it.Next() {
+		entries[string(entry.Key)] = entry.Value
+	}
+	return entries
+}
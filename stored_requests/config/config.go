@@ -29,13 +29,14 @@ import (
 //
 // 1. A Fetcher which can be used to get Stored Requests
 // 2. A function which should be called on shutdown for graceful cleanups.
+// 3. The in-memory caches in front of the Fetcher, by name, so they can be inspected from the admin server.
 //
 // If any errors occur, the program will exit with an error message.
 // It probably means you have a bad config or networking issue.
 //
 // As a side-effect, it will add some endpoints to the router if the config calls for it.
 // In the future we should look for ways to simplify this so that it's not doing two things.
-func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, provider db_provider.DbProvider) (fetcher stored_requests.AllFetcher, shutdown func()) {
+func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, provider db_provider.DbProvider) (fetcher stored_requests.AllFetcher, shutdown func(), caches map[string]stored_requests.CacheInspector) {
 	// Create database connection if given options for one
 	if cfg.Database.ConnectionInfo.Database != "" {
 		if provider == nil {
@@ -64,6 +65,7 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 		cache := newCache(cfg)
 		fetcher = stored_requests.WithCache(fetcher, cache, metricsEngine)
 		shutdown1 = addListeners(cache, eventProducers)
+		caches = inspectableCaches(cfg, cache)
 	}
 
 	shutdown = func() {
@@ -91,6 +93,8 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 // 4. A Fetcher which can be used to get Account data
 // 5. A Fetcher which can be used to get Category Mapping data
 // 6. A Fetcher which can be used to get Stored Requests for /openrtb2/video
+// 7. A Fetcher which can be used to get Stored Responses
+// 8. The in-memory caches of all the Fetchers, by name, so they can be inspected from the admin server
 //
 // If any errors occur, the program will exit with an error message.
 // It probably means you have a bad config or networking issue.
@@ -103,16 +107,17 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 	accountsFetcher stored_requests.AccountFetcher,
 	categoriesFetcher stored_requests.CategoryFetcher,
 	videoFetcher stored_requests.Fetcher,
-	storedRespFetcher stored_requests.Fetcher) {
+	storedRespFetcher stored_requests.Fetcher,
+	caches map[string]stored_requests.CacheInspector) {
 
 	var provider db_provider.DbProvider
 
-	fetcher1, shutdown1 := CreateStoredRequests(&cfg.StoredRequests, metricsEngine, client, router, provider)
-	fetcher2, shutdown2 := CreateStoredRequests(&cfg.StoredRequestsAMP, metricsEngine, client, router, provider)
-	fetcher3, shutdown3 := CreateStoredRequests(&cfg.CategoryMapping, metricsEngine, client, router, provider)
-	fetcher4, shutdown4 := CreateStoredRequests(&cfg.StoredVideo, metricsEngine, client, router, provider)
-	fetcher5, shutdown5 := CreateStoredRequests(&cfg.Accounts, metricsEngine, client, router, provider)
-	fetcher6, shutdown6 := CreateStoredRequests(&cfg.StoredResponses, metricsEngine, client, router, provider)
+	fetcher1, shutdown1, caches1 := CreateStoredRequests(&cfg.StoredRequests, metricsEngine, client, router, provider)
+	fetcher2, shutdown2, caches2 := CreateStoredRequests(&cfg.StoredRequestsAMP, metricsEngine, client, router, provider)
+	fetcher3, shutdown3, caches3 := CreateStoredRequests(&cfg.CategoryMapping, metricsEngine, client, router, provider)
+	fetcher4, shutdown4, caches4 := CreateStoredRequests(&cfg.StoredVideo, metricsEngine, client, router, provider)
+	fetcher5, shutdown5, caches5 := CreateStoredRequests(&cfg.Accounts, metricsEngine, client, router, provider)
+	fetcher6, shutdown6, caches6 := CreateStoredRequests(&cfg.StoredResponses, metricsEngine, client, router, provider)
 
 	fetcher = fetcher1.(stored_requests.Fetcher)
 	ampFetcher = fetcher2.(stored_requests.Fetcher)
@@ -130,6 +135,13 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 		shutdown6()
 	}
 
+	caches = make(map[string]stored_requests.CacheInspector)
+	for _, c := range []map[string]stored_requests.CacheInspector{caches1, caches2, caches3, caches4, caches5, caches6} {
+		for name, cache := range c {
+			caches[name] = cache
+		}
+	}
+
 	return
 }
 
@@ -193,6 +205,23 @@ func newCache(cfg *config.StoredRequests) stored_requests.Cache {
 	return cache
 }
 
+// inspectableCaches returns the caches which can list their contents, named after the config section
+// and the type of data they hold, e.g. "stored_requests.imps".
+func inspectableCaches(cfg *config.StoredRequests, cache stored_requests.Cache) map[string]stored_requests.CacheInspector {
+	caches := make(map[string]stored_requests.CacheInspector)
+	for dataType, c := range map[string]stored_requests.CacheJSON{
+		"requests":  cache.Requests,
+		"imps":      cache.Imps,
+		"responses": cache.Responses,
+		"accounts":  cache.Accounts,
+	} {
+		if inspector, ok := c.(stored_requests.CacheInspector); ok {
+			caches[cfg.Section()+"."+dataType] = inspector
+		}
+	}
+	return caches
+}
+
 func newEventProducers(cfg *config.StoredRequests, client *http.Client, provider db_provider.DbProvider, metricsEngine metrics.MetricsEngine, router *httprouter.Router) (eventProducers []events.EventProducer) {
 	if cfg.CacheEvents.Enabled {
 		eventProducers = append(eventProducers, newEventsAPI(router, cfg.CacheEvents.Endpoint))
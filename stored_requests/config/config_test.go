@@ -199,6 +199,25 @@ func TestNewInMemoryAccountCache(t *testing.T) {
 	assert.True(t, isEmptyCacheType(cache.Responses), "The newCache method should return an empty Responses cache for Accounts config")
 }
 
+func TestInspectableCaches(t *testing.T) {
+	cfg := typedConfig(config.RequestDataType, &config.StoredRequests{
+		InMemoryCache: config.InMemoryCache{
+			TTL:              60,
+			RequestCacheSize: 100,
+			ImpCacheSize:     100,
+			RespCacheSize:    100,
+		},
+	})
+	caches := inspectableCaches(cfg, newCache(cfg))
+	assert.Len(t, caches, 3, "in-memory caches")
+	assert.Contains(t, caches, "stored_requests.requests")
+	assert.Contains(t, caches, "stored_requests.imps")
+	assert.Contains(t, caches, "stored_requests.responses")
+
+	emptyCfg := typedConfig(config.AccountDataType, &config.StoredRequests{InMemoryCache: config.InMemoryCache{Type: "none"}})
+	assert.Empty(t, inspectableCaches(emptyCfg, newCache(emptyCfg)), "empty caches")
+}
+
 func TestNewDatabaseEventProducers(t *testing.T) {
 	metricsMock := &metrics.MetricsEngineMock{}
 	metricsMock.Mock.On("RecordStoredDataFetchTime", mock.Anything, mock.Anything).Return()
@@ -90,6 +90,13 @@ type CacheJSON interface {
 	Save(ctx context.Context, data map[string]json.RawMessage)
 }
 
+// CacheInspector is implemented by the caches which can list their contents, so they can be inspected
+// from the admin server.
+type CacheInspector interface {
+	// Entries returns a copy of the data currently held in the cache.
+	Entries() map[string]json.RawMessage
+}
+
 // ComposedCache creates an interface to treat a slice of caches as a single cache
 type ComposedCache []CacheJSON
 
@@ -0,0 +1,139 @@
+package throttling
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+// minLatencySamples is the number of auctions needed before the p99 latency is trusted, so a few slow
+// auctions after startup don't shed the load.
+const minLatencySamples = 100
+
+// latencySampleMaxAge is how long the latency of an auction counts in the p99. While the load is shed
+// few auctions run, so the samples of the slow auctions expire and the server takes traffic again.
+const latencySampleMaxAge = 30 * time.Second
+
+// latencyRecomputeInterval is how often the p99 is recomputed while no auction is recorded.
+const latencyRecomputeInterval = time.Second
+
+// LoadShedder tracks the auctions in flight and the latency of the recent auctions, to tell when the
+// server is overloaded.
+type LoadShedder struct {
+	maxInFlight int64
+	maxP99      time.Duration
+	inFlight    int64
+
+	mutex sync.Mutex
+	// latencies is a ring buffer of the latencies of the recent auctions.
+	latencies []latencySample
+	next      int
+	samples   int
+	// p99 is recomputed every recomputeEvery auctions, or every latencyRecomputeInterval, instead of on
+	// each request.
+	p99            time.Duration
+	recomputeEvery int
+	sinceRecompute int
+	recomputedAt   time.Time
+	now            func() time.Time
+}
+
+type latencySample struct {
+	latency time.Duration
+	end     time.Time
+}
+
+func NewLoadShedder(cfg config.LoadShedding) *LoadShedder {
+	shedder := &LoadShedder{
+		maxInFlight: int64(cfg.MaxInFlightAuctions),
+		maxP99:      time.Duration(cfg.MaxP99LatencyMs) * time.Millisecond,
+		now:         time.Now,
+	}
+	if shedder.maxP99 > 0 {
+		shedder.latencies = make([]latencySample, cfg.LatencyWindowSize)
+		shedder.recomputeEvery = cfg.LatencyWindowSize / 20
+		if shedder.recomputeEvery < 1 {
+			shedder.recomputeEvery = 1
+		}
+	}
+	return shedder
+}
+
+// Overloaded returns the reason the server is overloaded, if it is.
+func (s *LoadShedder) Overloaded() (metrics.ThrottleReason, bool) {
+	if s.maxInFlight > 0 && atomic.LoadInt64(&s.inFlight) >= s.maxInFlight {
+		return metrics.ThrottleInFlight, true
+	}
+	if s.maxP99 > 0 {
+		s.mutex.Lock()
+		if now := s.now(); now.Sub(s.recomputedAt) >= latencyRecomputeInterval {
+			s.recompute(now)
+		}
+		p99 := s.p99
+		s.mutex.Unlock()
+		if p99 > s.maxP99 {
+			return metrics.ThrottleLatency, true
+		}
+	}
+	return "", false
+}
+
+// Start counts an auction in flight. The returned function must be called when the auction is over.
+func (s *LoadShedder) Start() (done func()) {
+	start := time.Now()
+	atomic.AddInt64(&s.inFlight, 1)
+	return func() {
+		atomic.AddInt64(&s.inFlight, -1)
+		s.record(time.Since(start))
+	}
+}
+
+func (s *LoadShedder) record(latency time.Duration) {
+	if s.maxP99 <= 0 {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	s.latencies[s.next] = latencySample{latency: latency, end: now}
+	s.next = (s.next + 1) % len(s.latencies)
+	if s.samples < len(s.latencies) {
+		s.samples++
+	}
+
+	s.sinceRecompute++
+	if s.sinceRecompute >= s.recomputeEvery {
+		s.recompute(now)
+	}
+}
+
+// recompute updates the p99. The caller must hold the lock.
+func (s *LoadShedder) recompute(now time.Time) {
+	s.sinceRecompute = 0
+	s.recomputedAt = now
+	s.p99 = s.percentile99(now)
+}
+
+// percentile99 returns the p99 of the latencies recorded within latencySampleMaxAge, or 0 if there aren't
+// enough of them. The caller must hold the lock.
+func (s *LoadShedder) percentile99(now time.Time) time.Duration {
+	oldest := now.Add(-latencySampleMaxAge)
+	sorted := make([]time.Duration, 0, s.samples)
+	for _, sample := range s.latencies[:s.samples] {
+		if sample.end.After(oldest) {
+			sorted = append(sorted, sample.latency)
+		}
+	}
+	if len(sorted) == 0 || len(sorted) < minLatencySamples && len(sorted) < len(s.latencies) {
+		return 0
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)*99-1)/100]
+}
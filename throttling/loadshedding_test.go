@@ -0,0 +1,107 @@
+package throttling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadShedderInFlight(t *testing.T) {
+	shedder := NewLoadShedder(config.LoadShedding{Enabled: true, MaxInFlightAuctions: 2})
+
+	done1 := shedder.Start()
+	_, overloaded := shedder.Overloaded()
+	assert.False(t, overloaded, "one auction in flight")
+
+	done2 := shedder.Start()
+	reason, overloaded := shedder.Overloaded()
+	assert.True(t, overloaded, "two auctions in flight")
+	assert.Equal(t, metrics.ThrottleInFlight, reason, "reason")
+
+	done1()
+	_, overloaded = shedder.Overloaded()
+	assert.False(t, overloaded, "one auction over")
+	done2()
+}
+
+func TestLoadShedderLatency(t *testing.T) {
+	testCases := []struct {
+		description        string
+		fastAuctions       int
+		slowAuctions       int
+		expectedOverloaded bool
+	}{
+		{
+			description:        "Not Enough Samples",
+			slowAuctions:       minLatencySamples - 1,
+			expectedOverloaded: false,
+		},
+		{
+			description:        "Below p99",
+			fastAuctions:       990,
+			slowAuctions:       10,
+			expectedOverloaded: false,
+		},
+		{
+			description:        "Above p99",
+			fastAuctions:       980,
+			slowAuctions:       20,
+			expectedOverloaded: true,
+		},
+		{
+			description:        "Slow Auctions Left The Window",
+			slowAuctions:       20,
+			fastAuctions:       1030,
+			expectedOverloaded: false,
+		},
+	}
+
+	for _, test := range testCases {
+		shedder := NewLoadShedder(config.LoadShedding{Enabled: true, MaxP99LatencyMs: 100, LatencyWindowSize: 1000})
+		for i := 0; i < test.slowAuctions; i++ {
+			shedder.record(time.Second)
+		}
+		for i := 0; i < test.fastAuctions; i++ {
+			shedder.record(time.Millisecond)
+		}
+
+		reason, overloaded := shedder.Overloaded()
+		assert.Equal(t, test.expectedOverloaded, overloaded, test.description)
+		if test.expectedOverloaded {
+			assert.Equal(t, metrics.ThrottleLatency, reason, test.description+":reason")
+		}
+	}
+}
+
+func TestLoadShedderSmallWindow(t *testing.T) {
+	shedder := NewLoadShedder(config.LoadShedding{Enabled: true, MaxP99LatencyMs: 100, LatencyWindowSize: 10})
+	for i := 0; i < 10; i++ {
+		shedder.record(time.Second)
+	}
+
+	_, overloaded := shedder.Overloaded()
+	assert.True(t, overloaded, "a full window is enough samples")
+}
+
+func TestLoadShedderLatencyRecovery(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	shedder := NewLoadShedder(config.LoadShedding{Enabled: true, MaxP99LatencyMs: 100, LatencyWindowSize: 1000})
+	shedder.now = func() time.Time { return now }
+	for i := 0; i < 1000; i++ {
+		shedder.record(time.Second)
+	}
+
+	_, overloaded := shedder.Overloaded()
+	assert.True(t, overloaded, "slow auctions")
+
+	now = now.Add(latencySampleMaxAge / 2)
+	_, overloaded = shedder.Overloaded()
+	assert.True(t, overloaded, "no auction recorded, the slow auctions still count")
+
+	now = now.Add(latencySampleMaxAge / 2)
+	_, overloaded = shedder.Overloaded()
+	assert.False(t, overloaded, "no auction recorded, the slow auctions expired")
+}
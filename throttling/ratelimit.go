@@ -0,0 +1,86 @@
+package throttling
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// rateLimitSweepInterval is how often the buckets of the idle accounts are forgotten.
+const rateLimitSweepInterval = time.Minute
+
+// AccountRateLimiter limits the auction requests of each account with a token bucket. The bucket holds
+// up to burst tokens, refills at the rate of the account, and each request takes a token.
+type AccountRateLimiter struct {
+	mutex     sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewAccountRateLimiter() *AccountRateLimiter {
+	return &AccountRateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of the account, and returns false if it's empty. The bucket is
+// reset when the rate limit of the account changes.
+func (l *AccountRateLimiter) Allow(account string, limit config.AccountRateLimit) bool {
+	if limit.RequestsPerSecond <= 0 {
+		return true
+	}
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = math.Ceil(limit.RequestsPerSecond)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	bucket, ok := l.buckets[account]
+	if !ok || bucket.rate != limit.RequestsPerSecond || bucket.burst != burst {
+		bucket = &tokenBucket{rate: limit.RequestsPerSecond, burst: burst, tokens: burst, last: now}
+		l.buckets[account] = bucket
+	}
+
+	bucket.refill(now)
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// sweep forgets the buckets which are full again, since they behave like new ones. The caller must hold the lock.
+func (l *AccountRateLimiter) sweep(now time.Time) {
+	for account, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= bucket.burst {
+			delete(l.buckets, account)
+		}
+	}
+	l.lastSweep = now
+}
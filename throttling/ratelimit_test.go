@@ -0,0 +1,118 @@
+package throttling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestRateLimiter() (*AccountRateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewAccountRateLimiter()
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func allowed(limiter *AccountRateLimiter, account string, limit config.AccountRateLimit, requests int) int {
+	count := 0
+	for i := 0; i < requests; i++ {
+		if limiter.Allow(account, limit) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestAccountRateLimiterAllow(t *testing.T) {
+	testCases := []struct {
+		description     string
+		limit           config.AccountRateLimit
+		elapsed         time.Duration
+		expectedAllowed int
+		expectedRefill  int
+	}{
+		{
+			description:     "No Limit",
+			limit:           config.AccountRateLimit{},
+			elapsed:         0,
+			expectedAllowed: 20,
+			expectedRefill:  20,
+		},
+		{
+			description:     "Default Burst",
+			limit:           config.AccountRateLimit{RequestsPerSecond: 5},
+			elapsed:         time.Second,
+			expectedAllowed: 5,
+			expectedRefill:  5,
+		},
+		{
+			description:     "Default Burst Rounded Up",
+			limit:           config.AccountRateLimit{RequestsPerSecond: 0.5},
+			elapsed:         time.Second,
+			expectedAllowed: 1,
+			expectedRefill:  0,
+		},
+		{
+			description:     "Burst",
+			limit:           config.AccountRateLimit{RequestsPerSecond: 2, Burst: 10},
+			elapsed:         time.Second,
+			expectedAllowed: 10,
+			expectedRefill:  2,
+		},
+		{
+			description:     "Refill Capped To Burst",
+			limit:           config.AccountRateLimit{RequestsPerSecond: 2, Burst: 10},
+			elapsed:         time.Hour,
+			expectedAllowed: 10,
+			expectedRefill:  10,
+		},
+	}
+
+	for _, test := range testCases {
+		limiter, clock := newTestRateLimiter()
+		assert.Equal(t, test.expectedAllowed, allowed(limiter, "acct", test.limit, 20), test.description+":burst")
+
+		clock.Advance(test.elapsed)
+		assert.Equal(t, test.expectedRefill, allowed(limiter, "acct", test.limit, 20), test.description+":refill")
+	}
+}
+
+func TestAccountRateLimiterAccounts(t *testing.T) {
+	limiter, _ := newTestRateLimiter()
+	limit := config.AccountRateLimit{RequestsPerSecond: 1, Burst: 2}
+
+	assert.Equal(t, 2, allowed(limiter, "acct1", limit, 5), "acct1")
+	assert.Equal(t, 2, allowed(limiter, "acct2", limit, 5), "acct2 has its own bucket")
+
+	higherLimit := config.AccountRateLimit{RequestsPerSecond: 1, Burst: 4}
+	assert.Equal(t, 4, allowed(limiter, "acct1", higherLimit, 5), "bucket reset on limit change")
+}
+
+func TestAccountRateLimiterSweep(t *testing.T) {
+	limiter, clock := newTestRateLimiter()
+	limit := config.AccountRateLimit{RequestsPerSecond: 1, Burst: 100}
+
+	limiter.Allow("idle", limit)
+	clock.Advance(rateLimitSweepInterval)
+	allowed(limiter, "busy", limit, 100)
+	assert.NotContains(t, limiter.buckets, "idle", "idle account swept")
+	assert.Contains(t, limiter.buckets, "busy", "busy account")
+
+	clock.Advance(2 * rateLimitSweepInterval)
+	limiter.Allow("other", limit)
+	assert.NotContains(t, limiter.buckets, "busy", "refilled account swept")
+}
@@ -0,0 +1,117 @@
+// Package throttling protects Prebid Server from traffic spikes: it limits the auction requests of each
+// account, and rejects them or reduces the number of bidders they call while the server is overloaded.
+package throttling
+
+import (
+	"fmt"
+
+	"github.com/prebid/openrtb/v17/openrtb3"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+// NoBidReason is the reason of the no-bid responses sent to the rejected requests.
+const NoBidReason = openrtb3.NoBidTechnicalError
+
+// Throttler decides whether the auction requests run, combining the account rate limits and the load shedding.
+type Throttler struct {
+	rateLimiter   *AccountRateLimiter
+	shedder       *LoadShedder
+	cfg           config.LoadShedding
+	metricsEngine metrics.MetricsEngine
+}
+
+// NewThrottler returns a throttler enforcing the rate limits of the accounts, and shedding the load if
+// it's enabled.
+func NewThrottler(cfg config.LoadShedding, metricsEngine metrics.MetricsEngine) *Throttler {
+	throttler := &Throttler{
+		rateLimiter:   NewAccountRateLimiter(),
+		cfg:           cfg,
+		metricsEngine: metricsEngine,
+	}
+	if cfg.Enabled {
+		throttler.shedder = NewLoadShedder(cfg)
+	}
+	return throttler
+}
+
+// RejectedError describes why a request was rejected, for the analytics.
+type RejectedError struct {
+	Reason metrics.ThrottleReason
+}
+
+func (e RejectedError) Error() string {
+	return fmt.Sprintf("Request rejected by the throttling: %s", e.Reason)
+}
+
+// Admission is the decision of the throttler for an auction request.
+type Admission struct {
+	// Rejected is true if the request must get a no-bid response without running the auction.
+	Rejected bool
+	// Reason is why the request was rejected, or why it calls fewer bidders.
+	Reason metrics.ThrottleReason
+	// MaxBidders is the number of bidders the auction can call, 0 for no limit.
+	MaxBidders int
+	done       func()
+}
+
+// Done must be called when the auction of an admitted request is over.
+func (a Admission) Done() {
+	if a.done != nil {
+		a.done()
+	}
+}
+
+// Admit decides whether the auction request of the account runs, and how many bidders it calls.
+func (t *Throttler) Admit(account string, rateLimit config.AccountRateLimit) Admission {
+	admission := t.AdmitAccount(t.AdmitLoad(account), account, rateLimit)
+	if admission.Rejected {
+		admission.Done()
+	}
+	return admission
+}
+
+// AdmitLoad decides whether the server can run one more auction, and how many bidders it calls. It doesn't
+// need the account config, so it's called before the stored requests and the account are fetched. Nil
+// throttlers admit all the requests.
+func (t *Throttler) AdmitLoad(account string) Admission {
+	if t == nil || t.shedder == nil {
+		return Admission{}
+	}
+
+	var admission Admission
+	if reason, overloaded := t.shedder.Overloaded(); overloaded {
+		if t.cfg.Mode != config.LoadSheddingModeReduce {
+			t.record(account, reason, metrics.ThrottleRejected)
+			return Admission{Rejected: true, Reason: reason}
+		}
+		t.record(account, reason, metrics.ThrottleReduced)
+		admission.Reason = reason
+		admission.MaxBidders = t.cfg.MaxBidders
+	}
+	admission.done = t.shedder.Start()
+	return admission
+}
+
+// AdmitAccount rejects the request admitted by AdmitLoad if the account exceeds its rate limit. The
+// admission must still be done, whether or not it's rejected.
+func (t *Throttler) AdmitAccount(admission Admission, account string, rateLimit config.AccountRateLimit) Admission {
+	if t == nil || admission.Rejected {
+		return admission
+	}
+	if !t.rateLimiter.Allow(account, rateLimit) {
+		t.record(account, metrics.ThrottleRateLimited, metrics.ThrottleRejected)
+		admission.Rejected = true
+		admission.Reason = metrics.ThrottleRateLimited
+		admission.MaxBidders = 0
+	}
+	return admission
+}
+
+func (t *Throttler) record(account string, reason metrics.ThrottleReason, action metrics.ThrottleAction) {
+	t.metricsEngine.RecordRequestThrottled(metrics.ThrottleLabels{
+		PubID:  account,
+		Reason: reason,
+		Action: action,
+	})
+}
@@ -0,0 +1,149 @@
+package throttling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottlerAdmit(t *testing.T) {
+	testCases := []struct {
+		description       string
+		cfg               config.LoadShedding
+		rateLimit         config.AccountRateLimit
+		inFlight          int
+		expectedAdmission Admission
+		expectedMetrics   []metrics.ThrottleLabels
+	}{
+		{
+			description:       "Admitted",
+			cfg:               config.LoadShedding{Enabled: true, MaxInFlightAuctions: 2, Mode: config.LoadSheddingModeReject},
+			rateLimit:         config.AccountRateLimit{RequestsPerSecond: 1},
+			inFlight:          1,
+			expectedAdmission: Admission{},
+		},
+		{
+			description:       "Rate Limited",
+			cfg:               config.LoadShedding{Enabled: true, MaxInFlightAuctions: 2, Mode: config.LoadSheddingModeReject},
+			rateLimit:         config.AccountRateLimit{RequestsPerSecond: 0.1, Burst: 1},
+			inFlight:          1,
+			expectedAdmission: Admission{Rejected: true, Reason: metrics.ThrottleRateLimited},
+			expectedMetrics: []metrics.ThrottleLabels{
+				{PubID: "acct", Reason: metrics.ThrottleRateLimited, Action: metrics.ThrottleRejected},
+			},
+		},
+		{
+			description:       "Rate Limited Without Load Shedding",
+			rateLimit:         config.AccountRateLimit{RequestsPerSecond: 0.1, Burst: 1},
+			expectedAdmission: Admission{Rejected: true, Reason: metrics.ThrottleRateLimited},
+			expectedMetrics: []metrics.ThrottleLabels{
+				{PubID: "acct", Reason: metrics.ThrottleRateLimited, Action: metrics.ThrottleRejected},
+			},
+		},
+		{
+			description:       "Overloaded - Reject",
+			cfg:               config.LoadShedding{Enabled: true, MaxInFlightAuctions: 2, Mode: config.LoadSheddingModeReject},
+			inFlight:          2,
+			expectedAdmission: Admission{Rejected: true, Reason: metrics.ThrottleInFlight},
+			expectedMetrics: []metrics.ThrottleLabels{
+				{PubID: "acct", Reason: metrics.ThrottleInFlight, Action: metrics.ThrottleRejected},
+			},
+		},
+		{
+			description:       "Overloaded - Reduce",
+			cfg:               config.LoadShedding{Enabled: true, MaxInFlightAuctions: 2, Mode: config.LoadSheddingModeReduce, MaxBidders: 3},
+			inFlight:          2,
+			expectedAdmission: Admission{Reason: metrics.ThrottleInFlight, MaxBidders: 3},
+			expectedMetrics: []metrics.ThrottleLabels{
+				{PubID: "acct", Reason: metrics.ThrottleInFlight, Action: metrics.ThrottleReduced},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		metricsEngine := &metrics.MetricsEngineMock{}
+		for _, labels := range test.expectedMetrics {
+			metricsEngine.On("RecordRequestThrottled", labels).Return().Once()
+		}
+		throttler := NewThrottler(test.cfg, metricsEngine)
+		if test.rateLimit.Burst > 0 {
+			// use the only token of the bucket
+			throttler.rateLimiter.Allow("acct", test.rateLimit)
+		}
+		for i := 0; i < test.inFlight; i++ {
+			defer throttler.shedder.Start()()
+		}
+
+		admission := throttler.Admit("acct", test.rateLimit)
+		admission.Done()
+		admission.done = nil
+
+		assert.Equal(t, test.expectedAdmission, admission, test.description)
+		metricsEngine.AssertExpectations(t)
+	}
+}
+
+func TestThrottlerAdmitDone(t *testing.T) {
+	throttler := NewThrottler(config.LoadShedding{Enabled: true, MaxInFlightAuctions: 1, Mode: config.LoadSheddingModeReject}, &metrics.MetricsEngineMock{})
+
+	admission := throttler.Admit("acct", config.AccountRateLimit{})
+	_, overloaded := throttler.shedder.Overloaded()
+	assert.True(t, overloaded, "auction in flight")
+
+	admission.Done()
+	_, overloaded = throttler.shedder.Overloaded()
+	assert.False(t, overloaded, "auction over")
+}
+
+func TestThrottlerAdmitRecovery(t *testing.T) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordRequestThrottled", metrics.ThrottleLabels{PubID: "acct", Reason: metrics.ThrottleLatency, Action: metrics.ThrottleRejected}).Return().Once()
+	throttler := NewThrottler(config.LoadShedding{Enabled: true, MaxP99LatencyMs: 100, LatencyWindowSize: 100, Mode: config.LoadSheddingModeReject}, metricsEngine)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	throttler.shedder.now = func() time.Time { return now }
+	for i := 0; i < 100; i++ {
+		throttler.shedder.record(time.Second)
+	}
+
+	admission := throttler.Admit("acct", config.AccountRateLimit{})
+	assert.True(t, admission.Rejected, "rejected while the auctions are slow")
+
+	now = now.Add(latencySampleMaxAge)
+	admission = throttler.Admit("acct", config.AccountRateLimit{})
+	assert.False(t, admission.Rejected, "admitted once the slow auctions expired")
+	admission.Done()
+	metricsEngine.AssertExpectations(t)
+}
+
+func TestThrottlerNil(t *testing.T) {
+	var throttler *Throttler
+
+	admission := throttler.AdmitLoad("acct")
+	assert.Equal(t, Admission{}, admission, "load")
+
+	admission = throttler.AdmitAccount(admission, "acct", config.AccountRateLimit{RequestsPerSecond: 0.1, Burst: 1})
+	assert.Equal(t, Admission{}, admission, "account")
+	admission.Done()
+}
+
+func TestThrottlerAdmitAccountAfterLoad(t *testing.T) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordRequestThrottled", metrics.ThrottleLabels{PubID: "acct", Reason: metrics.ThrottleRateLimited, Action: metrics.ThrottleRejected}).Return().Once()
+	throttler := NewThrottler(config.LoadShedding{Enabled: true, MaxInFlightAuctions: 1, Mode: config.LoadSheddingModeReject}, metricsEngine)
+	rateLimit := config.AccountRateLimit{RequestsPerSecond: 0.1, Burst: 1}
+	throttler.rateLimiter.Allow("acct", rateLimit)
+
+	admission := throttler.AdmitLoad("acct")
+	assert.False(t, admission.Rejected, "load")
+
+	admission = throttler.AdmitAccount(admission, "acct", rateLimit)
+	assert.True(t, admission.Rejected, "account")
+	assert.Equal(t, metrics.ThrottleRateLimited, admission.Reason, "account reason")
+
+	admission.Done()
+	assert.False(t, throttler.AdmitLoad("acct").Rejected, "the rejected admission is done")
+	metricsEngine.AssertExpectations(t)
+}
@@ -0,0 +1,137 @@
+package usersync
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bidRateBuckets is the number of buckets the bid rate window is divided into. Auctions leave the
+// window one bucket at a time.
+const bidRateBuckets = 10
+
+// BidRates is a rolling count, per account, of how often each bidder bid in the auctions it was
+// called in. The prioritized cookie sync strategy uses it to sync the bidders which matter most for
+// the publisher first. Each account has its own lock, so the auctions of different accounts don't
+// wait on each other.
+type BidRates struct {
+	window   time.Duration
+	bucket   time.Duration
+	accounts sync.Map // account ID -> *accountBidRates
+	// lastSweep is the time, in Unix nanoseconds, the accounts without recent auctions were last swept.
+	lastSweep atomic.Int64
+	now       func() time.Time
+}
+
+type accountBidRates struct {
+	mutex   sync.Mutex
+	buckets [bidRateBuckets]bidRateBucket
+	// swept is true once the account is removed from the bid rates, so auctions must be counted in
+	// a new one.
+	swept bool
+}
+
+type bidRateBucket struct {
+	start    time.Time
+	auctions map[string]int
+	bids     map[string]int
+}
+
+// NewBidRates returns bid rates computed over the auctions of the window.
+func NewBidRates(window time.Duration) *BidRates {
+	return &BidRates{
+		window: window,
+		bucket: window / bidRateBuckets,
+		now:    time.Now,
+	}
+}
+
+// Record counts an auction of the account. The keys of the bidders are the bidders called, and the
+// values tell whether they returned a bid.
+func (r *BidRates) Record(account string, bidders map[string]bool) {
+	if len(bidders) == 0 {
+		return
+	}
+
+	now := r.now()
+	if lastSweep := r.lastSweep.Load(); now.UnixNano()-lastSweep >= int64(r.window) && r.lastSweep.CompareAndSwap(lastSweep, now.UnixNano()) {
+		r.sweep(now)
+	}
+
+	for {
+		value, _ := r.accounts.LoadOrStore(account, &accountBidRates{})
+		rates := value.(*accountBidRates)
+		rates.mutex.Lock()
+		if rates.swept {
+			rates.mutex.Unlock()
+			continue
+		}
+		rates.record(now, r.bucket, bidders)
+		rates.mutex.Unlock()
+		return
+	}
+}
+
+func (a *accountBidRates) record(now time.Time, bucketDuration time.Duration, bidders map[string]bool) {
+	start := now.Truncate(bucketDuration)
+	bucket := &a.buckets[(start.UnixNano()/int64(bucketDuration))%bidRateBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = bidRateBucket{start: start, auctions: make(map[string]int), bids: make(map[string]int)}
+	}
+	for bidder, bid := range bidders {
+		bucket.auctions[bidder]++
+		if bid {
+			bucket.bids[bidder]++
+		}
+	}
+}
+
+// live is true if the account had auctions after the oldest time. The caller must hold the lock.
+func (a *accountBidRates) live(oldest time.Time) bool {
+	for i := range a.buckets {
+		if a.buckets[i].start.After(oldest) {
+			return true
+		}
+	}
+	return false
+}
+
+// BidRate returns the share, between 0 and 1, of the recent auctions of the account in which the bidder
+// bid. It is 0 if the bidder wasn't called.
+func (r *BidRates) BidRate(account, bidder string) float64 {
+	value, ok := r.accounts.Load(account)
+	if !ok {
+		return 0
+	}
+	rates := value.(*accountBidRates)
+	rates.mutex.Lock()
+	defer rates.mutex.Unlock()
+
+	auctions, bids := 0, 0
+	oldest := r.now().Add(-r.window)
+	for i := range rates.buckets {
+		if rates.buckets[i].start.After(oldest) {
+			auctions += rates.buckets[i].auctions[bidder]
+			bids += rates.buckets[i].bids[bidder]
+		}
+	}
+	if auctions == 0 {
+		return 0
+	}
+	return float64(bids) / float64(auctions)
+}
+
+// sweep forgets the accounts without auctions in the window.
+func (r *BidRates) sweep(now time.Time) {
+	oldest := now.Add(-r.window)
+	r.accounts.Range(func(account, value interface{}) bool {
+		rates := value.(*accountBidRates)
+		rates.mutex.Lock()
+		if !rates.live(oldest) {
+			rates.swept = true
+			r.accounts.Delete(account)
+		}
+		rates.mutex.Unlock()
+		return true
+	})
+}
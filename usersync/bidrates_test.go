@@ -0,0 +1,83 @@
+package usersync
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBidRates(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	rates := NewBidRates(10 * time.Minute)
+	rates.now = func() time.Time { return now }
+
+	rates.Record("account", map[string]bool{"a": true, "b": false})
+	rates.Record("account", map[string]bool{"a": false, "b": false})
+	rates.Record("other", map[string]bool{"b": true})
+
+	assert.Equal(t, 0.5, rates.BidRate("account", "a"), "bid in half of the auctions")
+	assert.Equal(t, 0.0, rates.BidRate("account", "b"), "never bid")
+	assert.Equal(t, 0.0, rates.BidRate("account", "c"), "never called")
+	assert.Equal(t, 1.0, rates.BidRate("other", "b"), "separate account")
+	assert.Equal(t, 0.0, rates.BidRate("unknown", "a"), "unknown account")
+
+	now = now.Add(5 * time.Minute)
+	rates.Record("account", map[string]bool{"a": true})
+	assert.Equal(t, 2.0/3.0, rates.BidRate("account", "a"), "auctions of previous buckets still count")
+
+	now = now.Add(6 * time.Minute)
+	assert.Equal(t, 1.0, rates.BidRate("account", "a"), "auctions older than the window don't count")
+
+	now = now.Add(10 * time.Minute)
+	rates.Record("account", map[string]bool{"a": false})
+	assert.Equal(t, 0.0, rates.BidRate("account", "a"), "a reused bucket is reset")
+	_, ok := rates.accounts.Load("other")
+	assert.False(t, ok, "accounts without recent auctions are swept")
+}
+
+func TestBidRatesBucketsWraparound(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	rates := NewBidRates(10 * time.Minute)
+	rates.now = func() time.Time { return now }
+
+	// a first window where the bidder always bids, then a second one where it never does
+	for i := 0; i < bidRateBuckets; i++ {
+		rates.Record("account", map[string]bool{"a": true})
+		now = now.Add(time.Minute)
+	}
+	assert.Equal(t, 1.0, rates.BidRate("account", "a"), "all the buckets of the first window")
+
+	for i := 0; i < bidRateBuckets; i++ {
+		rates.Record("account", map[string]bool{"a": false})
+		assert.Equal(t, float64(bidRateBuckets-i-1)/float64(bidRateBuckets), rates.BidRate("account", "a"), "bucket %d of the second window", i)
+		now = now.Add(time.Minute)
+	}
+	assert.Equal(t, 0.0, rates.BidRate("account", "a"), "the second window replaced the first one")
+
+	now = now.Add(2 * time.Minute)
+	rates.Record("account", map[string]bool{"a": true})
+	assert.Equal(t, 1.0/8.0, rates.BidRate("account", "a"), "the skipped buckets left the window")
+}
+
+func TestBidRatesConcurrentAccounts(t *testing.T) {
+	rates := NewBidRates(time.Minute)
+
+	var wg sync.WaitGroup
+	for _, account := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func(account string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				rates.Record(account, map[string]bool{"bidder": i%2 == 0})
+				rates.BidRate(account, "bidder")
+			}
+		}(account)
+	}
+	wg.Wait()
+
+	for _, account := range []string{"a", "b", "c", "d"} {
+		assert.Equal(t, 0.5, rates.BidRate(account, "bidder"), account)
+	}
+}
@@ -28,6 +28,9 @@ type Request struct {
 	Limit          int
 	Privacy        Privacy
 	SyncTypeFilter SyncTypeFilter
+	// Prioritization orders the bidders by the value of their sync, when set. Otherwise they are
+	// chosen randomly.
+	Prioritization *Prioritization
 }
 
 // Cooperative specifies the settings for cooperative syncing for a given request, where bidders
@@ -101,8 +104,8 @@ type standardChooser struct {
 	bidderChooser      bidderChooser
 }
 
-// Choose randomly selects user syncers which are permitted by the user's privacy settings and
-// which don't already have a valid user sync.
+// Choose randomly, or by priority when requested, selects user syncers which are permitted by the
+// user's privacy settings and which don't already have a valid user sync.
 func (c standardChooser) Choose(request Request, cookie *Cookie) Result {
 	if !cookie.AllowSyncs() {
 		return Result{Status: StatusBlockedByUserOptOut}
@@ -119,8 +122,11 @@ func (c standardChooser) Choose(request Request, cookie *Cookie) Result {
 	syncersChosen := make([]SyncerChoice, 0)
 
 	bidders := c.bidderChooser.choose(request.Bidders, c.biddersAvailable, request.Cooperative)
+	if request.Prioritization != nil {
+		bidders = request.Prioritization.order(bidders, request, c.bidderSyncerLookup, cookie)
+	}
 	for i := 0; i < len(bidders) && (limitDisabled || len(syncersChosen) < request.Limit); i++ {
-		syncer, evaluation := c.evaluate(bidders[i], syncersSeen, request.SyncTypeFilter, request.Privacy, cookie)
+		syncer, evaluation := c.evaluate(bidders[i], syncersSeen, request.SyncTypeFilter, request.Privacy, request.Prioritization, cookie)
 
 		biddersEvaluated = append(biddersEvaluated, evaluation)
 		if evaluation.Status == StatusOK {
@@ -131,7 +137,7 @@ func (c standardChooser) Choose(request Request, cookie *Cookie) Result {
 	return Result{Status: StatusOK, BiddersEvaluated: biddersEvaluated, SyncersChosen: syncersChosen}
 }
 
-func (c standardChooser) evaluate(bidder string, syncersSeen map[string]struct{}, syncTypeFilter SyncTypeFilter, privacy Privacy, cookie *Cookie) (Syncer, BidderEvaluation) {
+func (c standardChooser) evaluate(bidder string, syncersSeen map[string]struct{}, syncTypeFilter SyncTypeFilter, privacy Privacy, prioritization *Prioritization, cookie *Cookie) (Syncer, BidderEvaluation) {
 	syncer, exists := c.bidderSyncerLookup[bidder]
 	if !exists {
 		return nil, BidderEvaluation{Bidder: bidder, Status: StatusUnknownBidder}
@@ -147,7 +153,7 @@ func (c standardChooser) evaluate(bidder string, syncersSeen map[string]struct{}
 		return nil, BidderEvaluation{Bidder: bidder, Status: StatusTypeNotSupported}
 	}
 
-	if cookie.HasLiveSync(syncer.Key()) {
+	if cookie.HasLiveSync(syncer.Key()) && !prioritization.needsRefresh(cookie, syncer.Key()) {
 		return nil, BidderEvaluation{Bidder: bidder, Status: StatusAlreadySynced}
 	}
 
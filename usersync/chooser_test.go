@@ -242,14 +242,15 @@ func TestChooserEvaluate(t *testing.T) {
 	cookieAlreadyHasSyncForB := Cookie{uids: map[string]uidWithExpiry{"keyB": {Expires: time.Now().Add(time.Duration(24) * time.Hour)}}}
 
 	testCases := []struct {
-		description      string
-		givenBidder      string
-		givenSyncersSeen map[string]struct{}
-		givenPrivacy     Privacy
-		givenCookie      Cookie
-		expectedSyncer   Syncer
-		expectedBidder   string
-		expectedStatus   Status
+		description         string
+		givenBidder         string
+		givenSyncersSeen    map[string]struct{}
+		givenPrivacy        Privacy
+		givenCookie         Cookie
+		givenPrioritization *Prioritization
+		expectedSyncer      Syncer
+		expectedBidder      string
+		expectedStatus      Status
 	}{
 		{
 			description:      "Valid",
@@ -301,6 +302,28 @@ func TestChooserEvaluate(t *testing.T) {
 			expectedBidder:   "a",
 			expectedStatus:   StatusAlreadySynced,
 		},
+		{
+			description:         "Already Synced - Outside Refresh Window",
+			givenBidder:         "a",
+			givenSyncersSeen:    map[string]struct{}{},
+			givenPrivacy:        fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenCookie:         cookieAlreadyHasSyncForA,
+			givenPrioritization: &Prioritization{RefreshWindow: time.Hour},
+			expectedSyncer:      nil,
+			expectedBidder:      "a",
+			expectedStatus:      StatusAlreadySynced,
+		},
+		{
+			description:         "Already Synced - Inside Refresh Window",
+			givenBidder:         "a",
+			givenSyncersSeen:    map[string]struct{}{},
+			givenPrivacy:        fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenCookie:         cookieAlreadyHasSyncForA,
+			givenPrioritization: &Prioritization{RefreshWindow: 48 * time.Hour},
+			expectedSyncer:      fakeSyncerA,
+			expectedBidder:      "a",
+			expectedStatus:      StatusOK,
+		},
 		{
 			description:      "Different Bidder Already Synced",
 			givenBidder:      "a",
@@ -335,7 +358,7 @@ func TestChooserEvaluate(t *testing.T) {
 
 	for _, test := range testCases {
 		chooser, _ := NewChooser(bidderSyncerLookup).(standardChooser)
-		sync, evaluation := chooser.evaluate(test.givenBidder, test.givenSyncersSeen, syncTypeFilter, test.givenPrivacy, &test.givenCookie)
+		sync, evaluation := chooser.evaluate(test.givenBidder, test.givenSyncersSeen, syncTypeFilter, test.givenPrivacy, test.givenPrioritization, &test.givenCookie)
 
 		assert.Equal(t, test.expectedSyncer, sync, test.description+":syncer")
 
@@ -379,7 +402,7 @@ func (s fakeSyncer) SupportsType(syncTypes []SyncType) bool {
 	return false
 }
 
-func (fakeSyncer) GetSync(syncTypes []SyncType, privacyPolicies privacy.Policies) (Sync, error) {
+func (fakeSyncer) GetSync(syncTypes []SyncType, privacyPolicies privacy.Policies, requestInfo SyncRequestInfo) (Sync, error) {
 	return Sync{}, nil
 }
 
@@ -1,7 +1,7 @@
 package usersync
 
 import (
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"errors"
 	"math"
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
@@ -26,6 +27,11 @@ type Cookie struct {
 	uids     map[string]uidWithExpiry
 	optOut   bool
 	birthday *time.Time
+	// storeID identifies the user in the UID store, if one is used.
+	storeID string
+	// storeLoadFailed is true when the UIDs couldn't be read from the UID store. The cookie then only holds
+	// part of the UIDs, which must not replace the stored ones.
+	storeLoadFailed bool
 }
 
 // uidWithExpiry bundles the UID with an Expiration date.
@@ -37,8 +43,36 @@ type uidWithExpiry struct {
 	Expires time.Time `json:"expires"`
 }
 
-// ParseCookieFromRequest parses the UserSyncMap from an HTTP Request.
+// Cookies reads and writes the uids cookies, keeping the UIDs in the UID store when there is one. Nil
+// Cookies write the UIDs in the cookie, in the legacy unencrypted format.
+type Cookies struct {
+	store UIDStore
+	codec *cookieCodec
+}
+
+// NewCookies returns the cookies keeping the UIDs in the store and encrypting the cookie with the configured
+// keys. A nil store leaves the UIDs in the cookie. The cookies which can't be read are recorded in the metrics.
+func NewCookies(store UIDStore, encryption config.HostCookieEncryption, metricsEngine metrics.MetricsEngine) (*Cookies, error) {
+	codec, err := newCookieCodec(encryption, metricsEngine)
+	if err != nil {
+		return nil, err
+	}
+	return &Cookies{store: store, codec: codec}, nil
+}
+
+// defaultCookies write the UIDs in the cookie, in the legacy unencrypted format.
+var defaultCookies = &Cookies{codec: &cookieCodec{allowLegacy: true}}
+
+// ParseCookieFromRequest parses the UserSyncMap from an HTTP Request. The UIDs are read from the cookie only.
 func ParseCookieFromRequest(r *http.Request, cookie *config.HostCookie) *Cookie {
+	return defaultCookies.ParseCookieFromRequest(r, cookie)
+}
+
+// ParseCookieFromRequest parses the UserSyncMap from an HTTP Request, with the UIDs saved in the store.
+func (c *Cookies) ParseCookieFromRequest(r *http.Request, cookie *config.HostCookie) *Cookie {
+	if c == nil {
+		c = defaultCookies
+	}
 	if cookie.OptOutCookie.Name != "" {
 		optOutCookie, err1 := r.Cookie(cookie.OptOutCookie.Name)
 		if err1 == nil && optOutCookie.Value == cookie.OptOutCookie.Value {
@@ -50,10 +84,11 @@ func ParseCookieFromRequest(r *http.Request, cookie *config.HostCookie) *Cookie
 	var parsed *Cookie
 	uidCookie, err2 := r.Cookie(uidCookieName)
 	if err2 == nil {
-		parsed = ParseCookie(uidCookie)
+		parsed = c.ParseCookie(uidCookie)
 	} else {
 		parsed = NewCookie()
 	}
+	c.loadStoredUIDs(r.Context(), parsed)
 	// Fixes #582
 	if uid, _, _ := parsed.GetUID(cookie.Family); uid == "" && cookie.CookieName != "" {
 		if hostCookie, err := r.Cookie(cookie.CookieName); err == nil {
@@ -63,17 +98,27 @@ func ParseCookieFromRequest(r *http.Request, cookie *config.HostCookie) *Cookie
 	return parsed
 }
 
-// ParseCookie parses the UserSync cookie from a raw HTTP cookie.
+// ParseCookie parses the UserSync cookie from a raw HTTP cookie in the legacy base64 format.
 func ParseCookie(httpCookie *http.Cookie) *Cookie {
-	jsonValue, err := base64.URLEncoding.DecodeString(httpCookie.Value)
+	return defaultCookies.ParseCookie(httpCookie)
+}
+
+// ParseCookie parses the UserSync cookie from a raw HTTP cookie. Both the encrypted and, if allowed, the
+// legacy base64 formats are read.
+func (c *Cookies) ParseCookie(httpCookie *http.Cookie) *Cookie {
+	if c == nil {
+		c = defaultCookies
+	}
+	jsonValue, err := c.codec.decode(httpCookie.Value)
 	if err != nil {
-		// corrupted cookie; we should reset
+		// corrupted, tampered or unreadable cookie; we should reset
 		return NewCookie()
 	}
 
 	var cookie Cookie
 	if err = json.Unmarshal(jsonValue, &cookie); err != nil {
 		// corrupted cookie; we should reset
+		c.codec.invalid(metrics.InvalidUIDCookieMalformed, err)
 		return NewCookie()
 	}
 
@@ -104,12 +149,16 @@ func (cookie *Cookie) SetOptOut(optOut bool) {
 
 // Gets an HTTP cookie containing all the data from this UserSyncMap. This is a snapshot--not a live view.
 func (cookie *Cookie) ToHTTPCookie(ttl time.Duration) *http.Cookie {
+	return cookie.toHTTPCookie(defaultCookies.codec, ttl)
+}
+
+// toHTTPCookie gets an HTTP cookie with the value encoded by the codec.
+func (cookie *Cookie) toHTTPCookie(codec *cookieCodec, ttl time.Duration) *http.Cookie {
 	j, _ := json.Marshal(cookie)
-	b64 := base64.URLEncoding.EncodeToString(j)
 
 	return &http.Cookie{
 		Name:    uidCookieName,
-		Value:   b64,
+		Value:   codec.encode(j),
 		Expires: time.Now().Add(ttl),
 		Path:    "/",
 	}
@@ -144,7 +193,24 @@ func (cookie *Cookie) GetUIDs() map[string]string {
 
 // SetCookieOnResponse is a shortcut for "ToHTTPCookie(); cookie.setDomain(domain); setCookie(w, cookie)"
 func (cookie *Cookie) SetCookieOnResponse(w http.ResponseWriter, setSiteCookie bool, cfg *config.HostCookie, ttl time.Duration) {
-	httpCookie := cookie.ToHTTPCookie(ttl)
+	defaultCookies.SetCookieOnResponse(context.Background(), w, cookie, setSiteCookie, cfg, ttl)
+}
+
+// SetCookieOnResponse writes the cookie on the response. The UIDs are saved in the store and left out of
+// the cookie, unless they can't be saved.
+func (c *Cookies) SetCookieOnResponse(ctx context.Context, w http.ResponseWriter, cookie *Cookie, setSiteCookie bool, cfg *config.HostCookie, ttl time.Duration) {
+	if c == nil {
+		c = defaultCookies
+	}
+	if c.saveStoredUIDs(ctx, cookie, ttl) {
+		cookie = &Cookie{
+			optOut:   cookie.optOut,
+			birthday: cookie.birthday,
+			storeID:  cookie.storeID,
+		}
+	}
+
+	httpCookie := cookie.toHTTPCookie(c.codec, ttl)
 	var domain string = cfg.Domain
 
 	if domain != "" {
@@ -163,7 +229,7 @@ func (cookie *Cookie) SetCookieOnResponse(w http.ResponseWriter, setSiteCookie b
 			}
 		}
 		delete(cookie.uids, oldestElem)
-		httpCookie = cookie.ToHTTPCookie(ttl)
+		httpCookie = cookie.toHTTPCookie(c.codec, ttl)
 		if domain != "" {
 			httpCookie.Domain = domain
 		}
@@ -230,6 +296,7 @@ type cookieJson struct {
 	UIDs       map[string]uidWithExpiry `json:"tempUIDs,omitempty"`
 	OptOut     bool                     `json:"optout,omitempty"`
 	Birthday   *time.Time               `json:"bday,omitempty"`
+	StoreID    string                   `json:"sid,omitempty"`
 }
 
 func (cookie *Cookie) MarshalJSON() ([]byte, error) {
@@ -237,6 +304,7 @@ func (cookie *Cookie) MarshalJSON() ([]byte, error) {
 		UIDs:     cookie.uids,
 		OptOut:   cookie.optOut,
 		Birthday: cookie.birthday,
+		StoreID:  cookie.storeID,
 	})
 }
 
@@ -254,6 +322,7 @@ func (cookie *Cookie) UnmarshalJSON(b []byte) error {
 	if err == nil {
 		cookie.optOut = cookieContract.OptOut
 		cookie.birthday = cookieContract.Birthday
+		cookie.storeID = cookieContract.StoreID
 
 		if cookie.optOut {
 			cookie.uids = make(map[string]uidWithExpiry)
@@ -0,0 +1,128 @@
+package usersync
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+// encryptedCookiePrefix starts the values of the version 1 encrypted uids cookies, which are formatted as
+// "v1.<key id>.<base64 nonce and ciphertext>". The dots can't appear in the base64 legacy values.
+const encryptedCookiePrefix = "v1."
+
+// cookieCodec converts the JSON of the uids cookie to and from the cookie value.
+type cookieCodec struct {
+	// encryptionKeyID is the key encrypting new cookies, empty when they aren't encrypted.
+	encryptionKeyID string
+	keys            map[string]cipher.AEAD
+	allowLegacy     bool
+	metrics         metrics.MetricsEngine
+}
+
+func newCookieCodec(cfg config.HostCookieEncryption, metricsEngine metrics.MetricsEngine) (*cookieCodec, error) {
+	c := &cookieCodec{
+		keys:        make(map[string]cipher.AEAD, len(cfg.Keys)),
+		allowLegacy: cfg.AllowLegacy || len(cfg.Keys) == 0,
+		metrics:     metricsEngine,
+	}
+
+	for i, key := range cfg.Keys {
+		secret, err := base64.StdEncoding.DecodeString(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("uids cookie key %s is not base64 encoded: %v", key.ID, err)
+		}
+		block, err := aes.NewCipher(secret)
+		if err != nil {
+			return nil, fmt.Errorf("uids cookie key %s is invalid: %v", key.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("uids cookie key %s is invalid: %v", key.ID, err)
+		}
+		if i == 0 {
+			c.encryptionKeyID = key.ID
+		}
+		c.keys[key.ID] = aead
+	}
+	return c, nil
+}
+
+// encode returns the cookie value of the JSON, encrypted with the first key if there are keys.
+func (c *cookieCodec) encode(data []byte) string {
+	if c.encryptionKeyID == "" {
+		return base64.URLEncoding.EncodeToString(data)
+	}
+
+	aead := c.keys[c.encryptionKeyID]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		glog.Errorf("Failed to create a nonce for the uids cookie: %v", err)
+		return ""
+	}
+	sealed := aead.Seal(nonce, nonce, data, c.additionalData(c.encryptionKeyID))
+	return encryptedCookiePrefix + c.encryptionKeyID + "." + base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+// decode returns the JSON of the cookie value. Cookies which can't be read are recorded in the metrics.
+func (c *cookieCodec) decode(value string) ([]byte, error) {
+	if strings.HasPrefix(value, encryptedCookiePrefix) {
+		return c.decrypt(strings.TrimPrefix(value, encryptedCookiePrefix))
+	}
+
+	if !c.allowLegacy {
+		return nil, c.invalid(metrics.InvalidUIDCookieLegacy, errors.New("the uids cookie is not encrypted"))
+	}
+	data, err := base64.URLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, c.invalid(metrics.InvalidUIDCookieMalformed, err)
+	}
+	return data, nil
+}
+
+func (c *cookieCodec) decrypt(value string) ([]byte, error) {
+	keyID, encoded, found := strings.Cut(value, ".")
+	if !found {
+		return nil, c.invalid(metrics.InvalidUIDCookieMalformed, errors.New("the uids cookie has no key ID"))
+	}
+
+	aead, ok := c.keys[keyID]
+	if !ok {
+		return nil, c.invalid(metrics.InvalidUIDCookieUnknownKey, fmt.Errorf("the uids cookie key %s is unknown", keyID))
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, c.invalid(metrics.InvalidUIDCookieMalformed, err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, c.invalid(metrics.InvalidUIDCookieMalformed, errors.New("the uids cookie is too short"))
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, ciphertext, c.additionalData(keyID))
+	if err != nil {
+		return nil, c.invalid(metrics.InvalidUIDCookieTampered, err)
+	}
+	return data, nil
+}
+
+// additionalData binds the ciphertext to the cookie name, format version and key.
+func (c *cookieCodec) additionalData(keyID string) []byte {
+	return []byte(uidCookieName + "." + encryptedCookiePrefix + keyID)
+}
+
+// invalid records a cookie which can't be read and returns the error.
+func (c *cookieCodec) invalid(reason metrics.InvalidUIDCookieReason, err error) error {
+	if c.metrics != nil {
+		c.metrics.RecordInvalidUIDCookie(reason)
+	}
+	return err
+}
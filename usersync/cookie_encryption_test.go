@@ -0,0 +1,194 @@
+package usersync
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	cookieKey1 = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	cookieKey2 = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+)
+
+func TestNewCookieCodec(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    config.HostCookieEncryption
+		expectedKeyID  string
+		expectedLegacy bool
+		expectedError  string
+	}{
+		{
+			description:    "Not encrypted",
+			givenConfig:    config.HostCookieEncryption{},
+			expectedLegacy: true,
+		},
+		{
+			description:    "First key encrypts",
+			givenConfig:    config.HostCookieEncryption{Keys: []config.HostCookieKey{{ID: "k2", Secret: cookieKey2}, {ID: "k1", Secret: cookieKey1}}},
+			expectedKeyID:  "k2",
+			expectedLegacy: false,
+		},
+		{
+			description:    "Legacy allowed",
+			givenConfig:    config.HostCookieEncryption{Keys: []config.HostCookieKey{{ID: "k1", Secret: cookieKey1}}, AllowLegacy: true},
+			expectedKeyID:  "k1",
+			expectedLegacy: true,
+		},
+		{
+			description:   "Invalid secret",
+			givenConfig:   config.HostCookieEncryption{Keys: []config.HostCookieKey{{ID: "k1", Secret: "c2hvcnQ="}}},
+			expectedError: "uids cookie key k1 is invalid: crypto/aes: invalid key size 5",
+		},
+	}
+
+	for _, test := range testCases {
+		c, err := newCookieCodec(test.givenConfig, nil)
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+			continue
+		}
+		if assert.NoError(t, err, test.description) {
+			assert.Equal(t, test.expectedKeyID, c.encryptionKeyID, test.description)
+			assert.Equal(t, test.expectedLegacy, c.allowLegacy, test.description)
+		}
+	}
+}
+
+func TestCookieCodecDecode(t *testing.T) {
+	data := []byte(`{"tempUIDs":{"adnxs":{"uid":"123"}}}`)
+
+	oldCodec, _ := newCookieCodec(config.HostCookieEncryption{Keys: []config.HostCookieKey{{ID: "k1", Secret: cookieKey1}}}, nil)
+	rotatedCodec, _ := newCookieCodec(config.HostCookieEncryption{Keys: []config.HostCookieKey{{ID: "k2", Secret: cookieKey2}, {ID: "k1", Secret: cookieKey1}}}, nil)
+	plainCodec, _ := newCookieCodec(config.HostCookieEncryption{}, nil)
+
+	encrypted := oldCodec.encode(data)
+	keyID, sealed, _ := strings.Cut(strings.TrimPrefix(encrypted, encryptedCookiePrefix), ".")
+	tamperedBytes, _ := base64.RawURLEncoding.DecodeString(sealed)
+	tamperedBytes[len(tamperedBytes)-1] ^= 1
+	tampered := base64.RawURLEncoding.EncodeToString(tamperedBytes)
+	legacy := base64.URLEncoding.EncodeToString(data)
+
+	testCases := []struct {
+		description    string
+		givenCodec     *cookieCodec
+		givenValue     string
+		expectedData   []byte
+		expectedReason metrics.InvalidUIDCookieReason
+	}{
+		{
+			description:  "Encrypted",
+			givenCodec:   oldCodec,
+			givenValue:   encrypted,
+			expectedData: data,
+		},
+		{
+			description:  "Encrypted With A Rotated Key",
+			givenCodec:   rotatedCodec,
+			givenValue:   encrypted,
+			expectedData: data,
+		},
+		{
+			description:  "Legacy - Not Encrypted",
+			givenCodec:   plainCodec,
+			givenValue:   legacy,
+			expectedData: data,
+		},
+		{
+			description:    "Legacy - Encrypted",
+			givenCodec:     oldCodec,
+			givenValue:     legacy,
+			expectedReason: metrics.InvalidUIDCookieLegacy,
+		},
+		{
+			description:    "Malformed Legacy",
+			givenCodec:     plainCodec,
+			givenValue:     "malformed%",
+			expectedReason: metrics.InvalidUIDCookieMalformed,
+		},
+		{
+			description:    "Malformed - No Key ID",
+			givenCodec:     oldCodec,
+			givenValue:     encryptedCookiePrefix + sealed,
+			expectedReason: metrics.InvalidUIDCookieMalformed,
+		},
+		{
+			description:    "Malformed - Too Short",
+			givenCodec:     oldCodec,
+			givenValue:     encryptedCookiePrefix + keyID + ".AAAA",
+			expectedReason: metrics.InvalidUIDCookieMalformed,
+		},
+		{
+			description:    "Unknown Key",
+			givenCodec:     plainCodec,
+			givenValue:     encrypted,
+			expectedReason: metrics.InvalidUIDCookieUnknownKey,
+		},
+		{
+			description:    "Tampered",
+			givenCodec:     oldCodec,
+			givenValue:     encryptedCookiePrefix + keyID + "." + tampered,
+			expectedReason: metrics.InvalidUIDCookieTampered,
+		},
+		{
+			description:    "Tampered - Different Key ID",
+			givenCodec:     rotatedCodec,
+			givenValue:     encryptedCookiePrefix + "k2." + sealed,
+			expectedReason: metrics.InvalidUIDCookieTampered,
+		},
+	}
+
+	for _, test := range testCases {
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordInvalidUIDCookie", mock.Anything)
+		test.givenCodec.metrics = metricsMock
+
+		result, err := test.givenCodec.decode(test.givenValue)
+
+		if test.expectedReason == "" {
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, test.expectedData, result, test.description)
+			metricsMock.AssertNotCalled(t, "RecordInvalidUIDCookie", mock.Anything)
+		} else {
+			assert.Error(t, err, test.description)
+			metricsMock.AssertCalled(t, "RecordInvalidUIDCookie", test.expectedReason)
+		}
+	}
+}
+
+func TestEncryptedCookieRoundTrip(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordInvalidUIDCookie", mock.Anything)
+	cookies, err := NewCookies(nil, config.HostCookieEncryption{Keys: []config.HostCookieKey{{ID: "k1", Secret: cookieKey1}}}, metricsMock)
+	assert.NoError(t, err)
+
+	cookie := NewCookie()
+	cookie.TrySync("adnxs", "123")
+
+	w := httptest.NewRecorder()
+	cookies.SetCookieOnResponse(context.Background(), w, cookie, false, &config.HostCookie{}, 90*24*time.Hour)
+	httpCookie := w.Result().Cookies()[0]
+	assert.True(t, strings.HasPrefix(httpCookie.Value, "v1.k1."), "the cookie is encrypted")
+	assert.NotContains(t, httpCookie.Value, "adnxs")
+
+	parsed := cookies.ParseCookie(httpCookie)
+	uid, _, _ := parsed.GetUID("adnxs")
+	assert.Equal(t, "123", uid)
+
+	legacy := ParseCookie(httpCookie)
+	assert.False(t, legacy.HasAnyLiveSyncs(), "the default cookies can't read encrypted cookies")
+
+	forged := cookies.ParseCookie(&http.Cookie{Name: uidCookieName, Value: base64.URLEncoding.EncodeToString([]byte(`{"tempUIDs":{"adnxs":{"uid":"forged"}}}`))})
+	assert.False(t, forged.HasAnyLiveSyncs(), "unencrypted cookies are rejected")
+	metricsMock.AssertCalled(t, "RecordInvalidUIDCookie", metrics.InvalidUIDCookieLegacy)
+}
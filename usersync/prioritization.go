@@ -0,0 +1,90 @@
+package usersync
+
+import (
+	"sort"
+	"time"
+)
+
+// Prioritization orders the bidders to sync by the value of their sync instead of randomly. Each
+// weight multiplies a score between 0 and 1:
+//   - expiry is 1 for bidders without a live UID, and grows from 0 to 1 as a UID nears its expiry
+//     within the refresh window.
+//   - bid rate is the share of the recent auctions of the account in which the bidder bid.
+//   - priority is 1 for the requested bidders and decreases with each cooperative priority group.
+type Prioritization struct {
+	Account        string
+	ExpiryWeight   float64
+	BidRateWeight  float64
+	PriorityWeight float64
+	// RefreshWindow is how long before it expires a UID may be synced again.
+	RefreshWindow time.Duration
+	// BidRates are the bid rates of the bidders, nil when they aren't tracked.
+	BidRates *BidRates
+}
+
+// order sorts the bidders by decreasing score. Bidders with the same score keep their order, so the
+// randomness of the bidder chooser still breaks the ties.
+func (p Prioritization) order(bidders []string, request Request, bidderSyncerLookup map[string]Syncer, cookie *Cookie) []string {
+	priorities := p.priorities(request)
+	now := time.Now()
+
+	scores := make(map[string]float64, len(bidders))
+	for _, bidder := range bidders {
+		if _, ok := scores[bidder]; ok {
+			continue
+		}
+		score := p.PriorityWeight * priorities[bidder]
+		if syncer, ok := bidderSyncerLookup[bidder]; ok {
+			score += p.ExpiryWeight * p.expiryScore(cookie, syncer.Key(), now)
+		}
+		if p.BidRates != nil && p.BidRateWeight != 0 {
+			score += p.BidRateWeight * p.BidRates.BidRate(p.Account, bidder)
+		}
+		scores[bidder] = score
+	}
+
+	ordered := make([]string, len(bidders))
+	copy(ordered, bidders)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return scores[ordered[i]] > scores[ordered[j]]
+	})
+	return ordered
+}
+
+// priorities scores the requested bidders 1 and the bidders of the priority groups between 1 and 0,
+// with the first group closest to 1.
+func (p Prioritization) priorities(request Request) map[string]float64 {
+	groups := request.Cooperative.PriorityGroups
+	priorities := make(map[string]float64)
+	for i := len(groups) - 1; i >= 0; i-- {
+		for _, bidder := range groups[i] {
+			priorities[bidder] = 1 - float64(i+1)/float64(len(groups)+1)
+		}
+	}
+	for _, bidder := range request.Bidders {
+		priorities[bidder] = 1
+	}
+	return priorities
+}
+
+func (p Prioritization) expiryScore(cookie *Cookie, key string, now time.Time) float64 {
+	uid, ok := cookie.uids[key]
+	if !ok || !uid.Expires.After(now) {
+		return 1
+	}
+	remaining := uid.Expires.Sub(now)
+	if remaining >= p.RefreshWindow {
+		return 0
+	}
+	return 1 - float64(remaining)/float64(p.RefreshWindow)
+}
+
+// needsRefresh is true if the cookie has a live UID for the syncer key which expires within the
+// refresh window, so it may be synced again.
+func (p *Prioritization) needsRefresh(cookie *Cookie, key string) bool {
+	if p == nil || p.RefreshWindow <= 0 {
+		return false
+	}
+	uid, ok := cookie.uids[key]
+	return ok && uid.Expires.Before(time.Now().Add(p.RefreshWindow))
+}
@@ -0,0 +1,100 @@
+package usersync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrioritizationOrder(t *testing.T) {
+	bidderSyncerLookup := map[string]Syncer{
+		"a": fakeSyncer{key: "keyA"},
+		"b": fakeSyncer{key: "keyB"},
+		"c": fakeSyncer{key: "keyC"},
+		"d": fakeSyncer{key: "keyD"},
+	}
+	cookie := &Cookie{uids: map[string]uidWithExpiry{
+		"keyA": {Expires: time.Now().Add(30 * 24 * time.Hour)},
+		"keyB": {Expires: time.Now().Add(12 * time.Hour)},
+		"keyC": {Expires: time.Now().Add(-time.Hour)},
+	}}
+
+	rates := NewBidRates(time.Hour)
+	rates.Record("account", map[string]bool{"a": true, "b": false, "d": false})
+
+	testCases := []struct {
+		description    string
+		prioritization Prioritization
+		request        Request
+		givenBidders   []string
+		expected       []string
+	}{
+		{
+			description:    "Expiry",
+			prioritization: Prioritization{Account: "account", ExpiryWeight: 1, RefreshWindow: 24 * time.Hour},
+			givenBidders:   []string{"a", "b", "c", "d"},
+			expected:       []string{"c", "d", "b", "a"},
+		},
+		{
+			description:    "Bid Rate",
+			prioritization: Prioritization{Account: "account", BidRateWeight: 1, BidRates: rates},
+			givenBidders:   []string{"d", "c", "b", "a"},
+			expected:       []string{"a", "d", "c", "b"},
+		},
+		{
+			description:    "Priority",
+			prioritization: Prioritization{Account: "account", PriorityWeight: 1},
+			request:        Request{Bidders: []string{"d"}, Cooperative: Cooperative{PriorityGroups: [][]string{{"b"}, {"c", "d"}}}},
+			givenBidders:   []string{"a", "b", "c", "d"},
+			expected:       []string{"d", "b", "c", "a"},
+		},
+		{
+			description:    "Combined - Unknown Bidders And Duplicates",
+			prioritization: Prioritization{Account: "account", ExpiryWeight: 1, BidRateWeight: 2, PriorityWeight: 1, RefreshWindow: 24 * time.Hour, BidRates: rates},
+			request:        Request{Cooperative: Cooperative{PriorityGroups: [][]string{{"b"}}}},
+			givenBidders:   []string{"unknown", "d", "c", "a", "b", "a"},
+			expected:       []string{"a", "a", "b", "d", "c", "unknown"},
+		},
+	}
+
+	for _, test := range testCases {
+		result := test.prioritization.order(test.givenBidders, test.request, bidderSyncerLookup, cookie)
+		assert.Equal(t, test.expected, result, test.description)
+	}
+}
+
+func TestChooserChoosePrioritized(t *testing.T) {
+	fakeSyncerA := fakeSyncer{key: "keyA", supportsIFrame: true}
+	fakeSyncerB := fakeSyncer{key: "keyB", supportsIFrame: true}
+	fakeSyncerC := fakeSyncer{key: "keyC", supportsIFrame: true}
+	chooser := standardChooser{
+		bidderSyncerLookup: map[string]Syncer{"a": fakeSyncerA, "b": fakeSyncerB, "c": fakeSyncerC},
+		biddersAvailable:   []string{"a", "b", "c"},
+		bidderChooser:      standardBidderChooser{shuffler: reverseShuffler{}},
+	}
+	cookie := &Cookie{uids: map[string]uidWithExpiry{
+		"keyA": {Expires: time.Now().Add(time.Hour)},
+		"keyC": {Expires: time.Now().Add(30 * 24 * time.Hour)},
+	}}
+	request := Request{
+		Limit:   2,
+		Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+		SyncTypeFilter: SyncTypeFilter{
+			IFrame:   NewUniformBidderFilter(BidderFilterModeInclude),
+			Redirect: NewUniformBidderFilter(BidderFilterModeExclude),
+		},
+		Prioritization: &Prioritization{ExpiryWeight: 1, RefreshWindow: 24 * time.Hour},
+	}
+
+	result := chooser.Choose(request, cookie)
+
+	assert.Equal(t, Result{
+		Status: StatusOK,
+		BiddersEvaluated: []BidderEvaluation{
+			{Bidder: "b", Status: StatusOK},
+			{Bidder: "a", Status: StatusOK},
+		},
+		SyncersChosen: []SyncerChoice{{Bidder: "b", Syncer: fakeSyncerB}, {Bidder: "a", Syncer: fakeSyncerA}},
+	}, result)
+}
@@ -35,7 +35,17 @@ type Syncer interface {
 
 	// GetSync returns a user sync for the user's device to perform, or an error if the none of the
 	// sync types are supported or if macro substitution fails.
-	GetSync(syncTypes []SyncType, privacyPolicies privacy.Policies) (Sync, error)
+	GetSync(syncTypes []SyncType, privacyPolicies privacy.Policies, requestInfo SyncRequestInfo) (Sync, error)
+}
+
+// SyncRequestInfo holds the values of the user sync request available to the sync url macros,
+// other than the privacy policies.
+type SyncRequestInfo struct {
+	// AccountID is the account of the cookie sync request.
+	AccountID string
+
+	// HostCookieID is the user id of the host cookie, if the host cookie is configured.
+	HostCookieID string
 }
 
 // Sync represents a user sync to be performed by the user's device.
@@ -169,9 +179,13 @@ func escapeTemplate(x string) string {
 }
 
 var templateTestValues = macros.UserSyncTemplateParams{
-	GDPR:        "anyGDPR",
-	GDPRConsent: "anyGDPRConsent",
-	USPrivacy:   "anyCCPAConsent",
+	GDPR:         "anyGDPR",
+	GDPRConsent:  "anyGDPRConsent",
+	USPrivacy:    "anyCCPAConsent",
+	GPP:          "anyGPPConsent",
+	GPPSID:       "anyGPPSID",
+	HostCookieID: "anyHostCookieID",
+	AccountID:    "anyAccountID",
 }
 
 func validateTemplate(template *template.Template) error {
@@ -217,7 +231,7 @@ func (s standardSyncer) filterSupportedSyncTypes(syncTypes []SyncType) []SyncTyp
 	return supported
 }
 
-func (s standardSyncer) GetSync(syncTypes []SyncType, privacyPolicies privacy.Policies) (Sync, error) {
+func (s standardSyncer) GetSync(syncTypes []SyncType, privacyPolicies privacy.Policies, requestInfo SyncRequestInfo) (Sync, error) {
 	syncType, err := s.chooseSyncType(syncTypes)
 	if err != nil {
 		return Sync{}, err
@@ -225,17 +239,22 @@ func (s standardSyncer) GetSync(syncTypes []SyncType, privacyPolicies privacy.Po
 
 	syncTemplate := s.chooseTemplate(syncType)
 
-	url, err := macros.ResolveMacros(syncTemplate, macros.UserSyncTemplateParams{
-		GDPR:        privacyPolicies.GDPR.Signal,
-		GDPRConsent: privacyPolicies.GDPR.Consent,
-		USPrivacy:   privacyPolicies.CCPA.Consent,
+	// the values not validated by the cookie sync endpoint are escaped, so they can't alter the rest of the url
+	syncURL, err := macros.ResolveMacros(syncTemplate, macros.UserSyncTemplateParams{
+		GDPR:         privacyPolicies.GDPR.Signal,
+		GDPRConsent:  privacyPolicies.GDPR.Consent,
+		USPrivacy:    privacyPolicies.CCPA.Consent,
+		GPP:          url.QueryEscape(privacyPolicies.GPP.Consent),
+		GPPSID:       privacyPolicies.GPP.SID,
+		HostCookieID: url.QueryEscape(requestInfo.HostCookieID),
+		AccountID:    url.QueryEscape(requestInfo.AccountID),
 	})
 	if err != nil {
 		return Sync{}, err
 	}
 
 	sync := Sync{
-		URL:         url,
+		URL:         syncURL,
 		Type:        syncType,
 		SupportCORS: s.supportCORS,
 	}
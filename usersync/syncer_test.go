@@ -9,6 +9,7 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	"github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -444,6 +445,11 @@ func TestValidateTemplate(t *testing.T) {
 			given:         template.Must(template.New("test").Parse("http://server.com/sync?gdpr={{.GDPR}}&gdprconsent={{.GDPRConsent}}&ccpa={{.USPrivacy}}")),
 			expectedError: "",
 		},
+		{
+			description:   "Valid - GPP, Host Cookie And Account Macros",
+			given:         template.Must(template.New("test").Parse("http://server.com/sync?gpp={{.GPP}}&gpp_sid={{.GPPSID}}&hostid={{.HostCookieID}}&account={{.AccountID}}")),
+			expectedError: "",
+		},
 	}
 
 	for _, test := range testCases {
@@ -614,6 +620,7 @@ func TestSyncerGetSync(t *testing.T) {
 		iframeTemplate    = template.Must(template.New("test").Parse("iframe,gdpr:{{.GDPR}},gdprconsent:{{.GDPRConsent}},ccpa:{{.USPrivacy}}"))
 		redirectTemplate  = template.Must(template.New("test").Parse("redirect,gdpr:{{.GDPR}},gdprconsent:{{.GDPRConsent}},ccpa:{{.USPrivacy}}"))
 		malformedTemplate = template.Must(template.New("test").Parse("malformed,invalid:{{.DoesNotExist}}"))
+		requestTemplate   = template.Must(template.New("test").Parse("request,gpp:{{.GPP}},gppsid:{{.GPPSID}},hostid:{{.HostCookieID}},account:{{.AccountID}}"))
 	)
 
 	testCases := []struct {
@@ -621,6 +628,7 @@ func TestSyncerGetSync(t *testing.T) {
 		givenSyncer          standardSyncer
 		givenSyncTypes       []SyncType
 		givenPrivacyPolicies privacy.Policies
+		givenRequestInfo     SyncRequestInfo
 		expectedError        string
 		expectedSync         Sync
 	}{
@@ -645,6 +653,22 @@ func TestSyncerGetSync(t *testing.T) {
 			givenPrivacyPolicies: privacy.Policies{GDPR: gdpr.Policy{Signal: "A", Consent: "B"}, CCPA: ccpa.Policy{Consent: "C"}},
 			expectedSync:         Sync{URL: "redirect,gdpr:A,gdprconsent:B,ccpa:C", Type: SyncTypeRedirect, SupportCORS: false},
 		},
+		{
+			description:          "GPP, Host Cookie And Account",
+			givenSyncer:          standardSyncer{redirect: requestTemplate},
+			givenSyncTypes:       []SyncType{SyncTypeRedirect},
+			givenPrivacyPolicies: privacy.Policies{GPP: gpp.Policy{Consent: "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA", SID: "2,6"}},
+			givenRequestInfo:     SyncRequestInfo{AccountID: "D", HostCookieID: "E"},
+			expectedSync:         Sync{URL: "request,gpp:DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA,gppsid:2,6,hostid:E,account:D", Type: SyncTypeRedirect, SupportCORS: false},
+		},
+		{
+			description:          "GPP, Host Cookie And Account - Escaped",
+			givenSyncer:          standardSyncer{redirect: requestTemplate},
+			givenSyncTypes:       []SyncType{SyncTypeRedirect},
+			givenPrivacyPolicies: privacy.Policies{GPP: gpp.Policy{Consent: "a&b"}},
+			givenRequestInfo:     SyncRequestInfo{AccountID: "d?e", HostCookieID: "f=g"},
+			expectedSync:         Sync{URL: "request,gpp:a%26b,gppsid:,hostid:f%3Dg,account:d%3Fe", Type: SyncTypeRedirect, SupportCORS: false},
+		},
 		{
 			description:          "Macro Error",
 			givenSyncer:          standardSyncer{iframe: malformedTemplate},
@@ -655,7 +679,7 @@ func TestSyncerGetSync(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		result, err := test.givenSyncer.GetSync(test.givenSyncTypes, test.givenPrivacyPolicies)
+		result, err := test.givenSyncer.GetSync(test.givenSyncTypes, test.givenPrivacyPolicies, test.givenRequestInfo)
 
 		if test.expectedError == "" {
 			assert.NoError(t, err, test.description+":err")
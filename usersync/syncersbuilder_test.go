@@ -23,10 +23,14 @@ func TestBuildSyncers(t *testing.T) {
 		hostConfig              = config.Configuration{ExternalURL: "http://host.com", UserSync: config.UserSync{RedirectURL: "{{.ExternalURL}}/{{.SyncerKey}}/host"}}
 		iframeConfig            = &config.SyncerEndpoint{URL: "https://bidder.com/iframe?redirect={{.RedirectURL}}"}
 		iframeConfigError       = &config.SyncerEndpoint{URL: "https://bidder.com/iframe?redirect={{xRedirectURL}}"} // Error caused by invalid macro
+		iframeConfigRequest     = &config.SyncerEndpoint{URL: "https://bidder.com/iframe?gpp={{.GPP}}&gpp_sid={{.GPPSID}}&host={{.HostCookieID}}&account={{.AccountID}}&redirect={{.RedirectURL}}"}
+		iframeConfigUnknown     = &config.SyncerEndpoint{URL: "https://bidder.com/iframe?publisher={{.PublisherID}}&redirect={{.RedirectURL}}"} // Error caused by unknown macro
 		infoKeyAPopulated       = config.BidderInfo{Disabled: false, Syncer: &config.Syncer{Key: "a", IFrame: iframeConfig}}
 		infoKeyADisabled        = config.BidderInfo{Disabled: true, Syncer: &config.Syncer{Key: "a", IFrame: iframeConfig}}
 		infoKeyAEmpty           = config.BidderInfo{Disabled: false, Syncer: &config.Syncer{Key: "a"}}
 		infoKeyAError           = config.BidderInfo{Disabled: false, Syncer: &config.Syncer{Key: "a", IFrame: iframeConfigError}}
+		infoKeyARequest         = config.BidderInfo{Disabled: false, Syncer: &config.Syncer{Key: "a", IFrame: iframeConfigRequest}}
+		infoKeyAUnknown         = config.BidderInfo{Disabled: false, Syncer: &config.Syncer{Key: "a", IFrame: iframeConfigUnknown}}
 		infoKeyASupportsOnly    = config.BidderInfo{Disabled: false, Syncer: &config.Syncer{Supports: []string{"iframe"}}}
 		infoKeyBPopulated       = config.BidderInfo{Disabled: false, Syncer: &config.Syncer{Key: "b", IFrame: iframeConfig}}
 		infoKeyBEmpty           = config.BidderInfo{Disabled: false, Syncer: &config.Syncer{Key: "b"}}
@@ -67,6 +71,22 @@ func TestBuildSyncers(t *testing.T) {
 				"cannot create syncer for bidder bidder1 with key a: iframe template: a_usersync_url:1: function \"xRedirectURL\" not defined",
 			},
 		},
+		{
+			description:      "One - Request Macros",
+			givenConfig:      hostConfig,
+			givenBidderInfos: map[string]config.BidderInfo{"bidder1": infoKeyARequest},
+			expectedIFramesURLs: map[string]string{
+				"bidder1": "https://bidder.com/iframe?gpp=&gpp_sid=&host=&account=&redirect=http%3A%2F%2Fhost.com%2Fa%2Fhost",
+			},
+		},
+		{
+			description:      "One - Unknown Macro",
+			givenConfig:      hostConfig,
+			givenBidderInfos: map[string]config.BidderInfo{"bidder1": infoKeyAUnknown},
+			expectedErrors: []string{
+				"cannot create syncer for bidder bidder1 with key a: iframe template: a_usersync_url:1:38: executing \"a_usersync_url\" at <.PublisherID>: can't evaluate field PublisherID in type macros.UserSyncTemplateParams",
+			},
+		},
 		{
 			description:      "Many - Different Syncers",
 			givenConfig:      hostConfig,
@@ -159,7 +179,7 @@ func TestBuildSyncers(t *testing.T) {
 			assert.Empty(t, errs, test.description+":err")
 			resultRenderedIFrameURLS := map[string]string{}
 			for k, v := range result {
-				iframeRendered, err := v.GetSync([]SyncType{SyncTypeIFrame}, privacy.Policies{})
+				iframeRendered, err := v.GetSync([]SyncType{SyncTypeIFrame}, privacy.Policies{}, SyncRequestInfo{})
 				if assert.NoError(t, err, test.description+"key:%s,:iframe_render", k) {
 					resultRenderedIFrameURLS[k] = iframeRendered.URL
 				}
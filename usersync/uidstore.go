@@ -0,0 +1,110 @@
+package usersync
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// uidStoreTimeout bounds the store calls made while writing the cookie, so a slow store doesn't hold the
+// response.
+const uidStoreTimeout = 100 * time.Millisecond
+
+// UIDStore keeps the bidder UIDs of users on the server. The uids cookie then only holds the store ID
+// of the user, so the UIDs are not limited by the size of the cookie.
+type UIDStore interface {
+	// Get returns the UIDs saved for the store ID, or nil if there are none.
+	Get(ctx context.Context, id string) ([]byte, error)
+	// Set saves the UIDs for the store ID until the TTL expires.
+	Set(ctx context.Context, id string, uids []byte, ttl time.Duration) error
+	// Delete removes the UIDs saved for the store ID.
+	Delete(ctx context.Context, id string) error
+}
+
+// loadStoredUIDs adds the UIDs saved in the store for the cookie store ID. UIDs already in the cookie,
+// such as the ones written before the store was enabled, are kept. A failed read is recorded on the cookie,
+// so the stored UIDs aren't overwritten with the ones of the cookie.
+func (c *Cookies) loadStoredUIDs(ctx context.Context, cookie *Cookie) {
+	if c.store == nil || cookie.storeID == "" || cookie.optOut {
+		return
+	}
+
+	data, err := c.store.Get(ctx, cookie.storeID)
+	if err != nil {
+		glog.Warningf("Failed to read the UIDs of %s from the UID store: %v", cookie.storeID, err)
+		cookie.storeLoadFailed = true
+		return
+	}
+	if data == nil {
+		return
+	}
+
+	var uids map[string]uidWithExpiry
+	if err := json.Unmarshal(data, &uids); err != nil {
+		glog.Warningf("Failed to parse the UIDs of %s from the UID store: %v", cookie.storeID, err)
+		cookie.storeLoadFailed = true
+		return
+	}
+	for key, uid := range uids {
+		if _, ok := cookie.uids[key]; !ok {
+			cookie.uids[key] = uid
+		}
+	}
+}
+
+// saveStoredUIDs writes the cookie UIDs to the store, creating a store ID for new users. It returns false
+// if the UIDs couldn't be saved, in which case they must be written in the cookie. The UIDs aren't saved
+// if the stored ones couldn't be read, and the cookie keeps its store ID so they are read again next time.
+func (c *Cookies) saveStoredUIDs(ctx context.Context, cookie *Cookie, ttl time.Duration) bool {
+	if c.store == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uidStoreTimeout)
+	defer cancel()
+
+	if cookie.optOut {
+		if cookie.storeID != "" {
+			if err := c.store.Delete(ctx, cookie.storeID); err != nil {
+				glog.Warningf("Failed to delete the UIDs of %s from the UID store: %v", cookie.storeID, err)
+			}
+			cookie.storeID = ""
+		}
+		return true
+	}
+	if cookie.storeLoadFailed {
+		return false
+	}
+
+	if cookie.storeID == "" {
+		id, err := newStoreID()
+		if err != nil {
+			glog.Warningf("Failed to create a UID store ID: %v", err)
+			return false
+		}
+		cookie.storeID = id
+	}
+
+	data, err := json.Marshal(cookie.uids)
+	if err != nil {
+		return false
+	}
+	if err := c.store.Set(ctx, cookie.storeID, data, ttl); err != nil {
+		glog.Warningf("Failed to save the UIDs of %s to the UID store: %v", cookie.storeID, err)
+		return false
+	}
+	return true
+}
+
+// newStoreID creates a random URL safe identifier of 22 characters.
+func newStoreID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,100 @@
+package uidstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests/backends/db_provider"
+)
+
+// Default queries, expecting a uid_store table with an id primary key, a uids text column
+// and an expires timestamp column.
+const (
+	defaultGetQuery      = "SELECT uids FROM uid_store WHERE id = $ID AND expires > $NOW"
+	defaultDeleteQuery   = "DELETE FROM uid_store WHERE id = $ID"
+	defaultMySQLSetQuery = "INSERT INTO uid_store (id, uids, expires) VALUES ($ID, $UIDS, $EXPIRES) " +
+		"ON DUPLICATE KEY UPDATE uids = VALUES(uids), expires = VALUES(expires)"
+	defaultPostgresSetQuery = "INSERT INTO uid_store (id, uids, expires) VALUES ($ID, $UIDS, $EXPIRES) " +
+		"ON CONFLICT (id) DO UPDATE SET uids = EXCLUDED.uids, expires = EXCLUDED.expires"
+)
+
+// DatabaseStore is a UID store backed by a MySQL or Postgres database. Expired rows are ignored
+// but not deleted, so hosts should prune them periodically.
+type DatabaseStore struct {
+	provider    db_provider.DbProvider
+	timeout     time.Duration
+	getQuery    string
+	setQuery    string
+	deleteQuery string
+}
+
+func NewDatabaseStore(provider db_provider.DbProvider, cfg config.UIDStoreDatabase) *DatabaseStore {
+	store := &DatabaseStore{
+		provider:    provider,
+		timeout:     time.Duration(cfg.TimeoutMS) * time.Millisecond,
+		getQuery:    cfg.GetQuery,
+		setQuery:    cfg.SetQuery,
+		deleteQuery: cfg.DeleteQuery,
+	}
+	if store.getQuery == "" {
+		store.getQuery = defaultGetQuery
+	}
+	if store.deleteQuery == "" {
+		store.deleteQuery = defaultDeleteQuery
+	}
+	if store.setQuery == "" {
+		store.setQuery = defaultPostgresSetQuery
+		if cfg.ConnectionInfo.Driver == "mysql" {
+			store.setQuery = defaultMySQLSetQuery
+		}
+	}
+	return store
+}
+
+func (s *DatabaseStore) Get(ctx context.Context, id string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	rows, err := s.provider.QueryContext(ctx, s.getQuery,
+		db_provider.QueryParam{Name: "ID", Value: id},
+		db_provider.QueryParam{Name: "NOW", Value: time.Now().UTC()},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	var uids sql.NullString
+	if err := rows.Scan(&uids); err != nil {
+		return nil, err
+	}
+	if !uids.Valid {
+		return nil, nil
+	}
+	return []byte(uids.String), nil
+}
+
+func (s *DatabaseStore) Set(ctx context.Context, id string, uids []byte, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	_, err := s.provider.ExecContext(ctx, s.setQuery,
+		db_provider.QueryParam{Name: "ID", Value: id},
+		db_provider.QueryParam{Name: "UIDS", Value: string(uids)},
+		db_provider.QueryParam{Name: "EXPIRES", Value: time.Now().Add(ttl).UTC()},
+	)
+	return err
+}
+
+func (s *DatabaseStore) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	_, err := s.provider.ExecContext(ctx, s.deleteQuery, db_provider.QueryParam{Name: "ID", Value: id})
+	return err
+}
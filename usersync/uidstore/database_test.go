@@ -0,0 +1,86 @@
+package uidstore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests/backends/db_provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabaseStoreGet(t *testing.T) {
+	testCases := []struct {
+		description  string
+		rows         *sqlmock.Rows
+		err          error
+		expectedUIDs []byte
+		expectedErr  bool
+	}{
+		{
+			description:  "Found",
+			rows:         sqlmock.NewRows([]string{"uids"}).AddRow(`{"adnxs":{}}`),
+			expectedUIDs: []byte(`{"adnxs":{}}`),
+		},
+		{
+			description: "Not found",
+			rows:        sqlmock.NewRows([]string{"uids"}),
+		},
+		{
+			description: "Query error",
+			err:         errors.New("db down"),
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		provider, mock, _ := db_provider.NewDbProviderMock()
+		query := mock.ExpectQuery(regexp.QuoteMeta(defaultGetQuery)).WithArgs("id", sqlmock.AnyArg())
+		if test.err != nil {
+			query.WillReturnError(test.err)
+		} else {
+			query.WillReturnRows(test.rows)
+		}
+		store := NewDatabaseStore(provider, config.UIDStoreDatabase{TimeoutMS: 50})
+
+		uids, err := store.Get(context.Background(), "id")
+
+		assert.Equal(t, test.expectedUIDs, uids, test.description)
+		assert.Equal(t, test.expectedErr, err != nil, test.description)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.description)
+	}
+}
+
+func TestDatabaseStoreSetAndDelete(t *testing.T) {
+	provider, mock, _ := db_provider.NewDbProviderMock()
+	mock.ExpectExec(regexp.QuoteMeta(defaultMySQLSetQuery)).WithArgs("id", `{"adnxs":{}}`, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(defaultDeleteQuery)).WithArgs("id").WillReturnResult(sqlmock.NewResult(0, 1))
+	store := NewDatabaseStore(provider, config.UIDStoreDatabase{
+		ConnectionInfo: config.DatabaseConnection{Driver: "mysql"},
+		TimeoutMS:      50,
+	})
+
+	assert.NoError(t, store.Set(context.Background(), "id", []byte(`{"adnxs":{}}`), time.Hour))
+	assert.NoError(t, store.Delete(context.Background(), "id"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewDatabaseStoreQueries(t *testing.T) {
+	store := NewDatabaseStore(nil, config.UIDStoreDatabase{
+		ConnectionInfo: config.DatabaseConnection{Driver: "postgres"},
+		GetQuery:       "SELECT data FROM uids WHERE id = $ID",
+	})
+
+	assert.Equal(t, "SELECT data FROM uids WHERE id = $ID", store.getQuery)
+	assert.Equal(t, defaultPostgresSetQuery, store.setQuery)
+	assert.Equal(t, defaultDeleteQuery, store.deleteQuery)
+}
+
+func TestNewUIDStore(t *testing.T) {
+	assert.Nil(t, NewUIDStore(config.UIDStore{}))
+	assert.IsType(t, &MemoryStore{}, NewUIDStore(config.UIDStore{Type: config.UIDStoreTypeMemory, Memory: config.UIDStoreMemory{MaxEntries: 1}}))
+}
@@ -0,0 +1,88 @@
+package uidstore
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/util/timeutil"
+)
+
+// MemoryStore is a UID store holding the UIDs of the most recently seen users in memory. The least
+// recently used entry is evicted when the store is full.
+type MemoryStore struct {
+	mutex      sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	recency    *list.List
+	time       timeutil.Time
+}
+
+type memoryEntry struct {
+	id         string
+	uids       []byte
+	expiration time.Time
+}
+
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		recency:    list.New(),
+		time:       &timeutil.RealTime{},
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.entries[id]
+	if !ok {
+		return nil, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if !s.time.Now().Before(entry.expiration) {
+		s.remove(element)
+		return nil, nil
+	}
+	s.recency.MoveToFront(element)
+	return entry.uids, nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, id string, uids []byte, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expiration := s.time.Now().Add(ttl)
+	if element, ok := s.entries[id]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.uids = uids
+		entry.expiration = expiration
+		s.recency.MoveToFront(element)
+		return nil
+	}
+
+	for len(s.entries) >= s.maxEntries {
+		s.remove(s.recency.Back())
+	}
+	s.entries[id] = s.recency.PushFront(&memoryEntry{id: id, uids: uids, expiration: expiration})
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.entries[id]; ok {
+		s.remove(element)
+	}
+	return nil
+}
+
+// remove drops an entry. The caller must hold the lock.
+func (s *MemoryStore) remove(element *list.Element) {
+	s.recency.Remove(element)
+	delete(s.entries, element.Value.(*memoryEntry).id)
+}
@@ -0,0 +1,62 @@
+package uidstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (f *fakeTime) Now() time.Time {
+	return f.time
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryStore(2)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Hour))
+	assert.NoError(t, store.Set(ctx, "b", []byte("2"), time.Hour))
+	uids, _ := store.Get(ctx, "a")
+	assert.Equal(t, []byte("1"), uids)
+	assert.NoError(t, store.Set(ctx, "c", []byte("3"), time.Hour))
+
+	uids, _ = store.Get(ctx, "b")
+	assert.Nil(t, uids, "b was the least recently used")
+	uids, _ = store.Get(ctx, "a")
+	assert.Equal(t, []byte("1"), uids)
+	uids, _ = store.Get(ctx, "c")
+	assert.Equal(t, []byte("3"), uids)
+
+	assert.NoError(t, store.Set(ctx, "a", []byte("4"), time.Hour))
+	uids, _ = store.Get(ctx, "a")
+	assert.Equal(t, []byte("4"), uids)
+
+	assert.NoError(t, store.Delete(ctx, "a"))
+	uids, _ = store.Get(ctx, "a")
+	assert.Nil(t, uids)
+	assert.Len(t, store.entries, 1)
+}
+
+func TestMemoryStoreExpiration(t *testing.T) {
+	clock := &fakeTime{time: time.Unix(1000, 0)}
+	store := NewMemoryStore(2)
+	store.time = clock
+	ctx := context.Background()
+
+	assert.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+
+	clock.time = clock.time.Add(59 * time.Second)
+	uids, _ := store.Get(ctx, "a")
+	assert.Equal(t, []byte("1"), uids)
+
+	clock.time = clock.time.Add(time.Second)
+	uids, _ = store.Get(ctx, "a")
+	assert.Nil(t, uids)
+	assert.Empty(t, store.entries, "expired entries are removed")
+}
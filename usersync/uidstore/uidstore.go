@@ -0,0 +1,20 @@
+// Package uidstore implements the backends of the server-side UID store.
+package uidstore
+
+import (
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests/backends/db_provider"
+	"github.com/prebid/prebid-server/usersync"
+)
+
+// NewUIDStore builds the UID store configured by the host, or returns nil if UIDs are kept in the cookie.
+func NewUIDStore(cfg config.UIDStore) usersync.UIDStore {
+	switch cfg.Type {
+	case config.UIDStoreTypeMemory:
+		return NewMemoryStore(cfg.Memory.MaxEntries)
+	case config.UIDStoreTypeDatabase:
+		provider := db_provider.NewDbProvider(config.DataType("UID Store"), cfg.Database.ConnectionInfo)
+		return NewDatabaseStore(provider, cfg.Database)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package usersync
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeUIDStore struct {
+	data   map[string][]byte
+	getErr error
+	setErr error
+}
+
+func (s *fakeUIDStore) Get(ctx context.Context, id string) ([]byte, error) {
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+	return s.data[id], nil
+}
+
+func (s *fakeUIDStore) Set(ctx context.Context, id string, uids []byte, ttl time.Duration) error {
+	if s.setErr != nil {
+		return s.setErr
+	}
+	s.data[id] = uids
+	return nil
+}
+
+func (s *fakeUIDStore) Delete(ctx context.Context, id string) error {
+	delete(s.data, id)
+	return nil
+}
+
+func TestCookieWithUIDStore(t *testing.T) {
+	store := &fakeUIDStore{data: make(map[string][]byte)}
+	cookies := newCookiesWithStore(store)
+
+	cookie := newSampleCookie()
+	w := httptest.NewRecorder()
+	cookies.SetCookieOnResponse(context.Background(), w, cookie, false, &config.HostCookie{}, 90*24*time.Hour)
+
+	writtenCookie := parseSetCookie(t, w)
+	if assert.Len(t, store.data, 1) {
+		assert.NotEmpty(t, writtenCookie.storeID)
+		assert.Len(t, writtenCookie.storeID, 22)
+		assert.Empty(t, writtenCookie.uids, "the UIDs must be left out of the cookie")
+		assert.Contains(t, store.data, writtenCookie.storeID)
+	}
+
+	received := readCookie(cookies, w)
+	ensureConsistency(t, received)
+	assert.Equal(t, cookie.storeID, received.storeID)
+
+	received.SetOptOut(true)
+	cookies.SetCookieOnResponse(context.Background(), httptest.NewRecorder(), received, false, &config.HostCookie{}, 90*24*time.Hour)
+	assert.Empty(t, store.data, "the UIDs must be deleted from the store on opt out")
+	assert.Empty(t, received.storeID)
+}
+
+func TestCookieWithFailingUIDStore(t *testing.T) {
+	store := &fakeUIDStore{data: make(map[string][]byte), setErr: errors.New("store down")}
+	cookies := newCookiesWithStore(store)
+
+	cookie := newSampleCookie()
+	w := httptest.NewRecorder()
+	cookies.SetCookieOnResponse(context.Background(), w, cookie, false, &config.HostCookie{}, 90*24*time.Hour)
+
+	ensureConsistency(t, parseSetCookie(t, w))
+}
+
+func TestCookieStoredUIDsKeptWhenTheyCantBeRead(t *testing.T) {
+	stored := []byte(`{"openx":{"uid":"789"}}`)
+	testCases := []struct {
+		description string
+		store       *fakeUIDStore
+	}{
+		{
+			description: "Store error",
+			store:       &fakeUIDStore{data: map[string][]byte{"id": stored}, getErr: errors.New("store down")},
+		},
+		{
+			description: "Malformed stored UIDs",
+			store:       &fakeUIDStore{data: map[string][]byte{"id": []byte(`malformed`)}},
+		},
+	}
+
+	for _, test := range testCases {
+		cookies := newCookiesWithStore(test.store)
+		savedUIDs := test.store.data["id"]
+
+		cookie := newSampleCookie()
+		cookie.storeID = "id"
+		request := httptest.NewRequest("GET", "http://www.prebid.com", nil)
+		request.AddCookie(cookie.ToHTTPCookie(time.Hour))
+
+		received := cookies.ParseCookieFromRequest(request, &config.HostCookie{})
+		w := httptest.NewRecorder()
+		cookies.SetCookieOnResponse(context.Background(), w, received, false, &config.HostCookie{}, 90*24*time.Hour)
+
+		assert.Equal(t, savedUIDs, test.store.data["id"], test.description+": the stored UIDs must not be overwritten")
+		written := parseSetCookie(t, w)
+		assert.Equal(t, "id", written.storeID, test.description+": the store ID must be kept")
+		assert.Equal(t, cookie.GetUIDs(), written.GetUIDs(), test.description+": the UIDs must be written in the cookie")
+	}
+}
+
+func TestCookieUIDsKeptWithUIDStore(t *testing.T) {
+	stored, _ := json.Marshal(map[string]uidWithExpiry{
+		"adnxs":    newTempId("stored", 10),
+		"openx":    newTempId("789", 10),
+		"facebook": newTempId("1", -10),
+	})
+	cookies := newCookiesWithStore(&fakeUIDStore{data: map[string][]byte{"id": stored}})
+
+	cookie := newSampleCookie()
+	cookie.storeID = "id"
+	request := httptest.NewRequest("GET", "http://www.prebid.com", nil)
+	request.AddCookie(cookie.ToHTTPCookie(time.Hour))
+
+	received := cookies.ParseCookieFromRequest(request, &config.HostCookie{})
+
+	assert.Equal(t, map[string]string{"adnxs": "123", "rubicon": "456", "openx": "789", "facebook": "1"}, received.GetUIDs())
+	assert.False(t, received.HasLiveSync("facebook"))
+}
+
+func parseSetCookie(t *testing.T, w *httptest.ResponseRecorder) *Cookie {
+	header := http.Header{}
+	header.Add("Cookie", w.Header().Get("Set-Cookie"))
+	request := http.Request{Header: header}
+	httpCookie, err := request.Cookie(uidCookieName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonValue, err := base64.URLEncoding.DecodeString(httpCookie.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cookie Cookie
+	if err := json.Unmarshal(jsonValue, &cookie); err != nil {
+		t.Fatal(err)
+	}
+	return &cookie
+}
+
+func readCookie(cookies *Cookies, w *httptest.ResponseRecorder) *Cookie {
+	header := http.Header{}
+	header.Add("Cookie", w.Header().Get("Set-Cookie"))
+	return cookies.ParseCookieFromRequest(&http.Request{Header: header}, &config.HostCookie{})
+}
+
+func newCookiesWithStore(store UIDStore) *Cookies {
+	cookies, _ := NewCookies(store, config.HostCookieEncryption{}, nil)
+	return cookies
+}
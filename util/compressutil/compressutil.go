@@ -0,0 +1,150 @@
+// Package compressutil reads and writes the HTTP content encodings supported by Prebid Server.
+package compressutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// The content encodings supported, as found in the Content-Encoding and Accept-Encoding headers.
+const (
+	Gzip     = "gzip"
+	Brotli   = "br"
+	Zstd     = "zstd"
+	Identity = "identity"
+)
+
+// Encodings returns the supported content encodings, besides identity.
+func Encodings() []string {
+	return []string{Gzip, Brotli, Zstd}
+}
+
+// IsSupported returns true if the content encoding can be read and written.
+func IsSupported(encoding string) bool {
+	switch normalize(encoding) {
+	case Gzip, Brotli, Zstd, Identity, "":
+		return true
+	}
+	return false
+}
+
+// UnsupportedEncodingError is returned for the content encodings which aren't supported.
+type UnsupportedEncodingError struct {
+	Encoding string
+}
+
+func (e UnsupportedEncodingError) Error() string {
+	return fmt.Sprintf("Unsupported Content-Encoding: %s", e.Encoding)
+}
+
+// NewReader returns a reader decoding r with the content encoding. An empty encoding is identity.
+func NewReader(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch normalize(encoding) {
+	case "", Identity:
+		return io.NopCloser(r), nil
+	case Gzip:
+		return gzip.NewReader(r)
+	case Brotli:
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case Zstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, UnsupportedEncodingError{Encoding: encoding}
+}
+
+// NewWriter returns a writer encoding to w with the content encoding. It must be closed to flush the
+// encoded data.
+func NewWriter(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch normalize(encoding) {
+	case "", Identity:
+		return nopWriteCloser{w}, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Brotli:
+		return brotli.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	}
+	return nil, UnsupportedEncodingError{Encoding: encoding}
+}
+
+// Compress encodes the data with the content encoding.
+func Compress(encoding string, data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w, err := NewWriter(encoding, &b)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Negotiate returns the first of the offered encodings accepted by the Accept-Encoding header, or an
+// empty string if none is.
+func Negotiate(acceptEncoding string, offered []string) string {
+	accepted := parseAcceptEncoding(acceptEncoding)
+	best := ""
+	bestQ := 0.0
+	for _, encoding := range offered {
+		q, ok := accepted[encoding]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > bestQ {
+			best = encoding
+			bestQ = q
+		}
+	}
+	return best
+}
+
+// parseAcceptEncoding returns the quality value of each encoding of the Accept-Encoding header.
+func parseAcceptEncoding(header string) map[string]float64 {
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		encoding := normalize(fields[0])
+		if encoding == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = value
+				}
+			}
+		}
+		accepted[encoding] = q
+	}
+	return accepted
+}
+
+func normalize(encoding string) string {
+	return strings.ToLower(strings.TrimSpace(encoding))
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
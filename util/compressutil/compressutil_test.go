@@ -0,0 +1,134 @@
+package compressutil
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	data := []byte(`{"id":"some-request-id","imp":[{"id":"some-impression-id"}]}`)
+
+	for _, encoding := range []string{Gzip, Brotli, Zstd, Identity, "", "GZIP", "Br"} {
+		compressed, err := Compress(encoding, data)
+		if !assert.NoError(t, err, encoding) {
+			continue
+		}
+
+		reader, err := NewReader(encoding, bytes.NewReader(compressed))
+		if !assert.NoError(t, err, encoding) {
+			continue
+		}
+		decompressed, err := io.ReadAll(reader)
+		reader.Close()
+
+		assert.NoError(t, err, encoding)
+		assert.Equal(t, data, decompressed, encoding)
+	}
+}
+
+func TestUnsupportedEncoding(t *testing.T) {
+	expectedErr := UnsupportedEncodingError{Encoding: "lz77"}
+
+	_, err := NewReader("lz77", bytes.NewReader(nil))
+	assert.Equal(t, expectedErr, err, "reader")
+
+	_, err = NewWriter("lz77", &bytes.Buffer{})
+	assert.Equal(t, expectedErr, err, "writer")
+
+	_, err = Compress("lz77", []byte("data"))
+	assert.Equal(t, expectedErr, err, "compress")
+
+	assert.EqualError(t, expectedErr, "Unsupported Content-Encoding: lz77")
+}
+
+func TestIsSupported(t *testing.T) {
+	testCases := []struct {
+		encoding string
+		expected bool
+	}{
+		{encoding: "", expected: true},
+		{encoding: "identity", expected: true},
+		{encoding: "gzip", expected: true},
+		{encoding: "br", expected: true},
+		{encoding: "ZSTD", expected: true},
+		{encoding: "deflate", expected: false},
+		{encoding: "lz77", expected: false},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, IsSupported(test.encoding), test.encoding)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	offered := []string{Zstd, Brotli, Gzip}
+
+	testCases := []struct {
+		description    string
+		acceptEncoding string
+		offered        []string
+		expected       string
+	}{
+		{
+			description:    "No Accept-Encoding",
+			acceptEncoding: "",
+			offered:        offered,
+			expected:       "",
+		},
+		{
+			description:    "Single Encoding",
+			acceptEncoding: "gzip",
+			offered:        offered,
+			expected:       Gzip,
+		},
+		{
+			description:    "First Offered Encoding Wins On Equal Quality",
+			acceptEncoding: "gzip, deflate, br",
+			offered:        offered,
+			expected:       Brotli,
+		},
+		{
+			description:    "Highest Quality Wins",
+			acceptEncoding: "br;q=0.5, gzip;q=0.8, zstd;q=0.1",
+			offered:        offered,
+			expected:       Gzip,
+		},
+		{
+			description:    "Zero Quality Refused",
+			acceptEncoding: "gzip;q=0",
+			offered:        offered,
+			expected:       "",
+		},
+		{
+			description:    "Wildcard",
+			acceptEncoding: "*",
+			offered:        offered,
+			expected:       Zstd,
+		},
+		{
+			description:    "Wildcard With Refused Encoding",
+			acceptEncoding: "zstd;q=0, *",
+			offered:        offered,
+			expected:       Brotli,
+		},
+		{
+			description:    "Not Offered",
+			acceptEncoding: "deflate",
+			offered:        offered,
+			expected:       "",
+		},
+		{
+			description:    "Nothing Offered",
+			acceptEncoding: "gzip",
+			offered:        nil,
+			expected:       "",
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, Negotiate(test.acceptEncoding, test.offered), test.description)
+	}
+}
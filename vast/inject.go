@@ -0,0 +1,167 @@
+package vast
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// Tracker is a tracking URL to be added to a VAST document.
+type Tracker struct {
+	Element config.VASTEventElement
+	// Event is the tracking event type, only used for tracking elements.
+	Event config.TrackingEventType
+	URL   string
+}
+
+// insertion identifies where new elements are written in the markup.
+type insertion struct {
+	offset int
+	// closes is the name of the self-closing element which needs to be opened to insert children.
+	closes string
+	// wrap is the name of a new element created around the inserted elements.
+	wrap string
+}
+
+// Inject returns the markup of the document with the trackers added to every ad. Trackers that
+// can't be placed, such as a click tracker for an ad without a linear creative, are skipped.
+func (d *Document) Inject(trackers []Tracker) string {
+	if len(trackers) == 0 {
+		return d.markup
+	}
+
+	var order []insertion
+	content := make(map[insertion]*strings.Builder)
+	add := func(at insertion, markup string) {
+		b, ok := content[at]
+		if !ok {
+			b = &strings.Builder{}
+			content[at] = b
+			order = append(order, at)
+		}
+		b.WriteString(markup)
+	}
+
+	for i := range d.Ads {
+		ad := &d.Ads[i]
+		for _, tracker := range trackers {
+			switch tracker.Element {
+			case config.ImpressionVASTElement:
+				add(ad.impressionPoint(), cdataElement("Impression", "", tracker.URL))
+			case config.ErrorVASTElement:
+				add(ad.errorPoint(), cdataElement("Error", "", tracker.URL))
+			case config.TrackingVASTElement:
+				for _, l := range ad.linears {
+					add(into(l.trackingEvents, l.element, "TrackingEvents"), cdataElement("Tracking", eventName(tracker.Event), tracker.URL))
+				}
+			case config.ClickTrackingVASTElement:
+				for _, l := range ad.linears {
+					add(into(l.videoClicks, l.element, "VideoClicks"), cdataElement("ClickTracking", "", tracker.URL))
+				}
+			case config.NonLinearClickTrackingVASTElement:
+				for _, n := range ad.nonLinears {
+					add(into(n, nil, ""), cdataElement("NonLinearClickTracking", "", tracker.URL))
+				}
+			case config.CompanionClickThroughVASTElement:
+				for _, c := range ad.companions {
+					add(into(c, nil, ""), cdataElement("CompanionClickTracking", "", tracker.URL))
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return order[i].offset < order[j].offset })
+
+	var b strings.Builder
+	b.Grow(len(d.markup) + 512)
+	last := 0
+	for _, at := range order {
+		if at.offset < 0 {
+			continue
+		}
+		if at.closes != "" {
+			// replace the "/>" of the self-closing element
+			b.WriteString(d.markup[last : at.offset-2])
+			b.WriteString(">")
+		} else {
+			b.WriteString(d.markup[last:at.offset])
+		}
+		if at.wrap != "" {
+			b.WriteString("<" + at.wrap + ">")
+		}
+		b.WriteString(content[at].String())
+		if at.wrap != "" {
+			b.WriteString("</" + at.wrap + ">")
+		}
+		if at.closes != "" {
+			b.WriteString("</" + at.closes + ">")
+		}
+		last = at.offset
+	}
+	b.WriteString(d.markup[last:])
+	return b.String()
+}
+
+// impressionPoint is after the last Impression of the ad, before its creatives if it has none.
+func (ad *Ad) impressionPoint() insertion {
+	if ad.impressionsEnd >= 0 {
+		return insertion{offset: ad.impressionsEnd}
+	}
+	if ad.creatives >= 0 {
+		return insertion{offset: ad.creatives}
+	}
+	return into(ad.root, nil, "")
+}
+
+// errorPoint is after the last Error of the ad, before its impressions or creatives if it has none.
+func (ad *Ad) errorPoint() insertion {
+	if ad.errorsEnd >= 0 {
+		return insertion{offset: ad.errorsEnd}
+	}
+	if ad.firstImpression >= 0 {
+		return insertion{offset: ad.firstImpression}
+	}
+	if ad.creatives >= 0 {
+		return insertion{offset: ad.creatives}
+	}
+	return into(ad.root, nil, "")
+}
+
+// into returns the insertion point for children of the element. When the element doesn't exist, it
+// is created with the given name inside parent, unless the parent is empty as well.
+func into(element, parent *container, name string) insertion {
+	if element == nil {
+		if parent == nil || parent.selfClosing {
+			return insertion{offset: -1}
+		}
+		at := into(parent, nil, "")
+		at.wrap = name
+		return at
+	}
+	at := insertion{offset: element.inner}
+	if element.selfClosing {
+		at.closes = element.name
+	}
+	return at
+}
+
+// eventName maps the configured tracking event type to the event attribute defined by VAST.
+func eventName(event config.TrackingEventType) string {
+	if event == config.MidPoint {
+		return "midpoint"
+	}
+	return string(event)
+}
+
+func cdataElement(name, event, url string) string {
+	var b strings.Builder
+	b.WriteString("<" + name)
+	if event != "" {
+		b.WriteString(` event="` + event + `"`)
+	}
+	b.WriteString("><![CDATA[")
+	b.WriteString(strings.ReplaceAll(url, "]]>", "]]]]><![CDATA[>"))
+	b.WriteString("]]></" + name + ">")
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package vast
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// Macros which can be used in the VAST event URLs configured for an account.
+const (
+	EventTypeMacro   = "##PBS-EVENTTYPE##"
+	VASTEventMacro   = "##PBS-VASTEVENT##"
+	BidIDMacro       = "##PBS-BIDID##"
+	AccountIDMacro   = "##PBS-ACCOUNTID##"
+	TimestampMacro   = "##PBS-TIMESTAMP##"
+	BidderMacro      = "##PBS-BIDDER##"
+	IntegrationMacro = "##PBS-INTEGRATION##"
+	MediaTypeMacro   = "##PBS-MEDIATYPE##"
+	ChannelMacro     = "##PBS-CHANNEL##"
+	AuctionIDMacro   = "##PBS-AUCTIONID##"
+	LineIDMacro      = "##PBS-LINEID##"
+	PriceMacro       = "##PBS-PRICE##"
+)
+
+// MacroValues holds the values substituted for the macros of the tracking URLs.
+type MacroValues struct {
+	BidID       string
+	AccountID   string
+	Bidder      string
+	AuctionID   string
+	Integration string
+	Channel     string
+	MediaType   string
+	LineID      string
+	Price       float64
+	Timestamp   int64
+}
+
+// Trackers builds the trackers configured by the events with their macros resolved.
+// The events default URL is added for every VAST event unless it is explicitly excluded.
+func Trackers(events config.Events, values MacroValues) []Tracker {
+	var trackers []Tracker
+	for _, event := range events.VASTEvents {
+		urls := event.URLs
+		if !event.ExcludeDefaultURL && events.DefaultURL != "" {
+			urls = append([]string{events.DefaultURL}, urls...)
+		}
+		for _, u := range urls {
+			trackers = append(trackers, Tracker{
+				Element: event.CreateElement,
+				Event:   event.Type,
+				URL:     values.replace(u, event),
+			})
+		}
+	}
+	return trackers
+}
+
+// Process validates the VAST markup and injects the trackers configured by the events.
+// Markup of an unsupported VAST version is returned unmodified. An error is returned if the
+// markup can't be parsed.
+func Process(markup string, events config.Events, values MacroValues) (string, error) {
+	doc, err := Parse(markup)
+	if err != nil {
+		var versionErr *UnsupportedVersionError
+		if errors.As(err, &versionErr) {
+			return markup, nil
+		}
+		return markup, err
+	}
+	return doc.Inject(Trackers(events, values)), nil
+}
+
+func (v MacroValues) replace(eventURL string, event config.VASTEvent) string {
+	if !strings.Contains(eventURL, "##PBS-") {
+		return eventURL
+	}
+	var price string
+	if v.Price != 0 {
+		price = strconv.FormatFloat(v.Price, 'f', -1, 64)
+	}
+	var timestamp string
+	if v.Timestamp != 0 {
+		timestamp = strconv.FormatInt(v.Timestamp, 10)
+	}
+	return strings.NewReplacer(
+		EventTypeMacro, url.QueryEscape(string(event.CreateElement)),
+		VASTEventMacro, url.QueryEscape(string(event.Type)),
+		BidIDMacro, url.QueryEscape(v.BidID),
+		AccountIDMacro, url.QueryEscape(v.AccountID),
+		TimestampMacro, timestamp,
+		BidderMacro, url.QueryEscape(v.Bidder),
+		IntegrationMacro, url.QueryEscape(v.Integration),
+		MediaTypeMacro, url.QueryEscape(v.MediaType),
+		ChannelMacro, url.QueryEscape(v.Channel),
+		AuctionIDMacro, url.QueryEscape(v.AuctionID),
+		LineIDMacro, url.QueryEscape(v.LineID),
+		PriceMacro, price,
+	).Replace(eventURL)
+}
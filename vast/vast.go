@@ -0,0 +1,243 @@
+// Package vast parses VAST creatives returned in video bids and injects tracking elements into them.
+package vast
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// supportedMajorVersions lists the major VAST versions which can be parsed and modified.
+var supportedMajorVersions = map[string]struct{}{
+	"2": {},
+	"3": {},
+	"4": {},
+}
+
+// UnsupportedVersionError is returned when the version of the VAST markup isn't supported. Such markup
+// is passed through unmodified.
+type UnsupportedVersionError struct {
+	Version string
+}
+
+func (err *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("VAST version %q is not supported", err.Version)
+}
+
+// isSupportedVersion returns true if the major version of a VAST version, such as 4 in 4.3, is supported.
+func isSupportedVersion(version string) bool {
+	major, _, _ := strings.Cut(strings.TrimSpace(version), ".")
+	_, ok := supportedMajorVersions[major]
+	return ok
+}
+
+// Document is a parsed VAST creative. It keeps the original markup together with the positions
+// where tracking elements can be inserted, so injection leaves the rest of the creative untouched.
+type Document struct {
+	Version string
+	Ads     []Ad
+	markup  string
+}
+
+// Ad is an Ad element of a VAST document.
+type Ad struct {
+	ID string
+	// Wrapper is true when the ad is a Wrapper pointing to another VAST document, false for an InLine ad.
+	Wrapper bool
+
+	root            *container
+	impressionsEnd  int
+	firstImpression int
+	errorsEnd       int
+	creatives       int
+	linears         []linear
+	nonLinears      []*container
+	companions      []*container
+
+	hasAdSystem   bool
+	adTagURI      string
+	creativeCount int
+}
+
+// container is an element into which child elements can be inserted.
+type container struct {
+	name string
+	// inner is the offset of the closing tag or, for a self-closing element, the offset right after it.
+	inner       int
+	selfClosing bool
+}
+
+type linear struct {
+	element        *container
+	trackingEvents *container
+	videoClicks    *container
+}
+
+// Parse parses and validates VAST markup. An error is returned if the markup is not well-formed XML,
+// is not a supported VAST version or is missing the elements required to serve the ad.
+func Parse(markup string) (*Document, error) {
+	if strings.TrimSpace(markup) == "" {
+		return nil, errors.New("VAST markup is empty")
+	}
+
+	doc := &Document{markup: markup}
+	decoder := xml.NewDecoder(strings.NewReader(markup))
+	decoder.Strict = true
+
+	var stack []string
+	var ad *Ad
+	var lin *linear
+	rootSeen := false
+
+	for {
+		start := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("VAST markup is not valid XML: %v", err)
+		}
+		end := int(decoder.InputOffset())
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			stack = append(stack, name)
+
+			switch {
+			case len(stack) == 1:
+				if rootSeen {
+					return nil, errors.New("VAST markup has more than one root element")
+				}
+				rootSeen = true
+				if name != "VAST" {
+					return nil, fmt.Errorf("VAST markup root element is <%s>", name)
+				}
+				doc.Version = attr(t, "version")
+				if !isSupportedVersion(doc.Version) {
+					return nil, &UnsupportedVersionError{Version: doc.Version}
+				}
+			case name == "Ad" && parent == "VAST":
+				doc.Ads = append(doc.Ads, Ad{ID: attr(t, "id"), impressionsEnd: -1, firstImpression: -1, errorsEnd: -1, creatives: -1})
+				ad = &doc.Ads[len(doc.Ads)-1]
+			case ad == nil:
+			case (name == "InLine" || name == "Wrapper") && parent == "Ad":
+				if ad.root != nil {
+					return nil, fmt.Errorf("VAST ad %q has more than one InLine or Wrapper element", ad.ID)
+				}
+				ad.Wrapper = name == "Wrapper"
+				ad.root = &container{name: name}
+			case isAdRoot(parent):
+				switch name {
+				case "AdSystem":
+					ad.hasAdSystem = true
+				case "Impression":
+					if ad.firstImpression < 0 {
+						ad.firstImpression = start
+					}
+				case "Creatives":
+					if ad.creatives < 0 {
+						ad.creatives = start
+					}
+				}
+			case name == "Creative" && parent == "Creatives":
+				ad.creativeCount++
+			case name == "Linear" && parent == "Creative":
+				ad.linears = append(ad.linears, linear{})
+				lin = &ad.linears[len(ad.linears)-1]
+			}
+
+		case xml.EndElement:
+			name := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			// the end of a self-closing element is reported without consuming any input
+			c := &container{name: name, inner: start, selfClosing: start == end}
+
+			switch {
+			case ad == nil:
+			case name == "Ad" && parent == "VAST":
+				if err := ad.validate(); err != nil {
+					return nil, err
+				}
+				ad, lin = nil, nil
+			case isAdRoot(name) && parent == "Ad":
+				ad.root = c
+			case isAdRoot(parent):
+				switch name {
+				case "Impression":
+					ad.impressionsEnd = end
+				case "Error":
+					ad.errorsEnd = end
+				}
+			case name == "Linear" && parent == "Creative" && lin != nil:
+				lin.element = c
+				lin = nil
+			case name == "TrackingEvents" && parent == "Linear" && lin != nil:
+				lin.trackingEvents = c
+			case name == "VideoClicks" && parent == "Linear" && lin != nil:
+				lin.videoClicks = c
+			case name == "NonLinear" && parent == "NonLinearAds":
+				ad.nonLinears = append(ad.nonLinears, c)
+			case name == "Companion" && parent == "CompanionAds":
+				ad.companions = append(ad.companions, c)
+			}
+
+		case xml.CharData:
+			if ad != nil && len(stack) > 1 && stack[len(stack)-1] == "VASTAdTagURI" && stack[len(stack)-2] == "Wrapper" {
+				ad.adTagURI += string(t)
+			}
+		}
+	}
+
+	if !rootSeen {
+		return nil, errors.New("VAST markup has no root element")
+	}
+	if len(doc.Ads) == 0 {
+		return nil, errors.New("VAST markup has no Ad elements")
+	}
+	return doc, nil
+}
+
+// validate verifies the ad has the elements required by the VAST specification to be served.
+func (ad *Ad) validate() error {
+	if ad.root == nil {
+		return fmt.Errorf("VAST ad %q has no InLine or Wrapper element", ad.ID)
+	}
+	if ad.Wrapper {
+		if strings.TrimSpace(ad.adTagURI) == "" {
+			return fmt.Errorf("VAST wrapper ad %q has no VASTAdTagURI", ad.ID)
+		}
+		return nil
+	}
+	if !ad.hasAdSystem {
+		return fmt.Errorf("VAST inline ad %q has no AdSystem", ad.ID)
+	}
+	if ad.creativeCount == 0 {
+		return fmt.Errorf("VAST inline ad %q has no Creative elements", ad.ID)
+	}
+	return nil
+}
+
+func isAdRoot(name string) bool {
+	return name == "InLine" || name == "Wrapper"
+}
+
+func attr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,200 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const inlineVAST = `<?xml version="1.0" encoding="UTF-8"?>` +
+	`<VAST version="3.0"><Ad id="1"><InLine>` +
+	`<AdSystem>prebid</AdSystem><Error><![CDATA[https://error.com]]></Error>` +
+	`<Impression><![CDATA[https://imp.com]]></Impression>` +
+	`<Creatives><Creative><Linear><Duration>00:00:30</Duration>` +
+	`<TrackingEvents><Tracking event="start"><![CDATA[https://start.com]]></Tracking></TrackingEvents>` +
+	`<MediaFiles><MediaFile><![CDATA[https://video.com/ad.mp4]]></MediaFile></MediaFiles>` +
+	`</Linear></Creative></Creatives></InLine></Ad></VAST>`
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		description     string
+		markup          string
+		expectedVersion string
+		expectedWrapper bool
+		expectedError   string
+	}{
+		{
+			description:     "InLine",
+			markup:          inlineVAST,
+			expectedVersion: "3.0",
+		},
+		{
+			description:     "Wrapper",
+			markup:          `<VAST version="4.2"><Ad><Wrapper><AdSystem>prebid</AdSystem><VASTAdTagURI><![CDATA[https://ad.com/vast]]></VASTAdTagURI></Wrapper></Ad></VAST>`,
+			expectedVersion: "4.2",
+			expectedWrapper: true,
+		},
+		{
+			description:   "Empty",
+			markup:        " ",
+			expectedError: "VAST markup is empty",
+		},
+		{
+			description:   "Malformed",
+			markup:        `<VAST version="3.0"><Ad></VAST>`,
+			expectedError: "VAST markup is not valid XML: XML syntax error on line 1: element <Ad> closed by </VAST>",
+		},
+		{
+			description:   "Not VAST",
+			markup:        `<html></html>`,
+			expectedError: "VAST markup root element is <html>",
+		},
+		{
+			description:   "Unsupported version",
+			markup:        `<VAST version="1.0"><Ad></Ad></VAST>`,
+			expectedError: `VAST version "1.0" is not supported`,
+		},
+		{
+			description:     "Minor Version Of A Supported Major Version",
+			markup:          `<VAST version="4.3"><Ad><Wrapper><VASTAdTagURI>https://ad.com</VASTAdTagURI></Wrapper></Ad></VAST>`,
+			expectedVersion: "4.3",
+			expectedWrapper: true,
+		},
+		{
+			description:   "Missing version",
+			markup:        `<VAST><Ad></Ad></VAST>`,
+			expectedError: `VAST version "" is not supported`,
+		},
+		{
+			description:   "No ads",
+			markup:        `<VAST version="3.0"/>`,
+			expectedError: "VAST markup has no Ad elements",
+		},
+		{
+			description:   "Multiple roots",
+			markup:        `<VAST version="3.0"><Ad id="1"><Wrapper><VASTAdTagURI>https://ad.com</VASTAdTagURI></Wrapper></Ad></VAST><VAST/>`,
+			expectedError: "VAST markup has more than one root element",
+		},
+		{
+			description:   "Ad without InLine or Wrapper",
+			markup:        `<VAST version="3.0"><Ad id="1"></Ad></VAST>`,
+			expectedError: `VAST ad "1" has no InLine or Wrapper element`,
+		},
+		{
+			description:   "Wrapper without ad tag",
+			markup:        `<VAST version="3.0"><Ad id="1"><Wrapper><VASTAdTagURI> </VASTAdTagURI></Wrapper></Ad></VAST>`,
+			expectedError: `VAST wrapper ad "1" has no VASTAdTagURI`,
+		},
+		{
+			description:   "InLine without AdSystem",
+			markup:        `<VAST version="3.0"><Ad id="1"><InLine><Creatives><Creative/></Creatives></InLine></Ad></VAST>`,
+			expectedError: `VAST inline ad "1" has no AdSystem`,
+		},
+		{
+			description:   "InLine without creatives",
+			markup:        `<VAST version="3.0"><Ad id="1"><InLine><AdSystem>prebid</AdSystem></InLine></Ad></VAST>`,
+			expectedError: `VAST inline ad "1" has no Creative elements`,
+		},
+	}
+
+	for _, test := range testCases {
+		doc, err := Parse(test.markup)
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+			continue
+		}
+		if assert.NoError(t, err, test.description) {
+			assert.Equal(t, test.expectedVersion, doc.Version, test.description)
+			if assert.Len(t, doc.Ads, 1, test.description) {
+				assert.Equal(t, test.expectedWrapper, doc.Ads[0].Wrapper, test.description)
+			}
+		}
+	}
+}
+
+func TestInject(t *testing.T) {
+	trackers := []Tracker{
+		{Element: config.ImpressionVASTElement, URL: "https://pbs.com/imp"},
+		{Element: config.ErrorVASTElement, URL: "https://pbs.com/error"},
+		{Element: config.TrackingVASTElement, Event: config.MidPoint, URL: "https://pbs.com/midpoint"},
+		{Element: config.ClickTrackingVASTElement, URL: "https://pbs.com/click?a=]]>"},
+	}
+
+	testCases := []struct {
+		description string
+		markup      string
+		expected    string
+	}{
+		{
+			description: "Existing elements",
+			markup:      inlineVAST,
+			expected: `<?xml version="1.0" encoding="UTF-8"?>` +
+				`<VAST version="3.0"><Ad id="1"><InLine>` +
+				`<AdSystem>prebid</AdSystem><Error><![CDATA[https://error.com]]></Error><Error><![CDATA[https://pbs.com/error]]></Error>` +
+				`<Impression><![CDATA[https://imp.com]]></Impression><Impression><![CDATA[https://pbs.com/imp]]></Impression>` +
+				`<Creatives><Creative><Linear><Duration>00:00:30</Duration>` +
+				`<TrackingEvents><Tracking event="start"><![CDATA[https://start.com]]></Tracking><Tracking event="midpoint"><![CDATA[https://pbs.com/midpoint]]></Tracking></TrackingEvents>` +
+				`<MediaFiles><MediaFile><![CDATA[https://video.com/ad.mp4]]></MediaFile></MediaFiles>` +
+				`<VideoClicks><ClickTracking><![CDATA[https://pbs.com/click?a=]]]]><![CDATA[>]]></ClickTracking></VideoClicks>` +
+				`</Linear></Creative></Creatives></InLine></Ad></VAST>`,
+		},
+		{
+			description: "Missing and self-closing elements",
+			markup: `<VAST version="2.0"><Ad><InLine><AdSystem>prebid</AdSystem>` +
+				`<Creatives><Creative><Linear><TrackingEvents/></Linear></Creative></Creatives></InLine></Ad></VAST>`,
+			expected: `<VAST version="2.0"><Ad><InLine><AdSystem>prebid</AdSystem>` +
+				`<Impression><![CDATA[https://pbs.com/imp]]></Impression><Error><![CDATA[https://pbs.com/error]]></Error>` +
+				`<Creatives><Creative><Linear><TrackingEvents><Tracking event="midpoint"><![CDATA[https://pbs.com/midpoint]]></Tracking></TrackingEvents>` +
+				`<VideoClicks><ClickTracking><![CDATA[https://pbs.com/click?a=]]]]><![CDATA[>]]></ClickTracking></VideoClicks>` +
+				`</Linear></Creative></Creatives></InLine></Ad></VAST>`,
+		},
+		{
+			description: "Wrapper without creatives",
+			markup:      `<VAST version="4.0"><Ad><Wrapper><VASTAdTagURI><![CDATA[https://ad.com]]></VASTAdTagURI></Wrapper></Ad></VAST>`,
+			expected: `<VAST version="4.0"><Ad><Wrapper><VASTAdTagURI><![CDATA[https://ad.com]]></VASTAdTagURI>` +
+				`<Impression><![CDATA[https://pbs.com/imp]]></Impression><Error><![CDATA[https://pbs.com/error]]></Error></Wrapper></Ad></VAST>`,
+		},
+	}
+
+	for _, test := range testCases {
+		doc, err := Parse(test.markup)
+		if assert.NoError(t, err, test.description) {
+			modified := doc.Inject(trackers)
+			assert.Equal(t, test.expected, modified, test.description)
+			_, err := Parse(modified)
+			assert.NoError(t, err, test.description+": the modified markup must be valid")
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	events := config.Events{
+		Enabled:    true,
+		DefaultURL: "https://pbs.com/event?t=##PBS-EVENTTYPE##&vtype=##PBS-VASTEVENT##&b=##PBS-BIDID##&a=##PBS-ACCOUNTID##&bidder=##PBS-BIDDER##&aid=##PBS-AUCTIONID##&p=##PBS-PRICE##",
+		VASTEvents: []config.VASTEvent{
+			{CreateElement: config.ImpressionVASTElement, URLs: []string{"https://other.com/imp?b=##PBS-BIDID##"}},
+			{CreateElement: config.TrackingVASTElement, Type: config.Complete, ExcludeDefaultURL: true, URLs: []string{"https://other.com/complete"}},
+		},
+	}
+	values := MacroValues{BidID: "bid 1", AccountID: "account", Bidder: "appnexus", AuctionID: "auction", Price: 1.25}
+	markup := `<VAST version="3.0"><Ad><Wrapper><VASTAdTagURI>https://ad.com</VASTAdTagURI><Impression/>` +
+		`<Creatives><Creative><Linear></Linear></Creative></Creatives></Wrapper></Ad></VAST>`
+
+	processed, err := Process(markup, events, values)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `<VAST version="3.0"><Ad><Wrapper><VASTAdTagURI>https://ad.com</VASTAdTagURI><Impression/>`+
+		`<Impression><![CDATA[https://pbs.com/event?t=impression&vtype=&b=bid+1&a=account&bidder=appnexus&aid=auction&p=1.25]]></Impression>`+
+		`<Impression><![CDATA[https://other.com/imp?b=bid+1]]></Impression>`+
+		`<Creatives><Creative><Linear><TrackingEvents><Tracking event="complete"><![CDATA[https://other.com/complete]]></Tracking></TrackingEvents></Linear></Creative></Creatives>`+
+		`</Wrapper></Ad></VAST>`, processed)
+
+	_, err = Process("<VAST", events, values)
+	assert.Error(t, err)
+
+	unsupported := `<VAST version="5.0"><Ad><Unknown/></Ad></VAST>`
+	processed, err = Process(unsupported, events, values)
+	assert.NoError(t, err, "unsupported version")
+	assert.Equal(t, unsupported, processed, "unsupported version")
+}